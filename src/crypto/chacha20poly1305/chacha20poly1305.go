@@ -0,0 +1,60 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chacha20poly1305 implements the ChaCha20-Poly1305 AEAD, as specified
+// in RFC 8439, and its extended nonce variant XChaCha20-Poly1305.
+//
+// ChaCha20-Poly1305 is fast in software on platforms without hardware support
+// for AES. XChaCha20-Poly1305 takes 192-bit nonces, which can be generated at
+// random for a practically unlimited number of messages under the same key.
+package chacha20poly1305
+
+import (
+	"crypto/cipher"
+	"crypto/internal/fips140only"
+	"errors"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// KeySize is the size of the key used by these AEADs, in bytes.
+	KeySize = 32
+
+	// NonceSize is the size of the nonce used with ChaCha20-Poly1305, in
+	// bytes.
+	//
+	// Note that this is too short to be safely generated at random if the same
+	// key is reused more than 2³² times.
+	NonceSize = 12
+
+	// NonceSizeX is the size of the nonce used with XChaCha20-Poly1305, in
+	// bytes.
+	NonceSizeX = 24
+
+	// Overhead is the size of the Poly1305 authentication tag, and the
+	// difference between a ciphertext length and its plaintext.
+	Overhead = 16
+)
+
+// New returns a ChaCha20-Poly1305 AEAD that uses the given 256-bit key.
+func New(key []byte) (cipher.AEAD, error) {
+	if fips140only.Enabled {
+		return nil, errors.New("crypto/chacha20poly1305: use of ChaCha20-Poly1305 is not allowed in FIPS 140-only mode")
+	}
+	return chacha20poly1305.New(key)
+}
+
+// NewX returns an XChaCha20-Poly1305 AEAD that uses the given 256-bit key.
+//
+// XChaCha20-Poly1305 derives a subkey from the first 16 bytes of each nonce
+// with HChaCha20, and uses it with the rest of the nonce for
+// ChaCha20-Poly1305. It should be preferred when nonces are generated at
+// random.
+func NewX(key []byte) (cipher.AEAD, error) {
+	if fips140only.Enabled {
+		return nil, errors.New("crypto/chacha20poly1305: use of XChaCha20-Poly1305 is not allowed in FIPS 140-only mode")
+	}
+	return chacha20poly1305.NewX(key)
+}
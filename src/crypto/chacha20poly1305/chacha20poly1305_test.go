@@ -0,0 +1,94 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chacha20poly1305
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/internal/cryptotest"
+	"encoding/hex"
+	"testing"
+)
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+const sunscreen = "Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it."
+
+var tests = []struct {
+	name                string
+	newAEAD             func([]byte) (cipher.AEAD, error)
+	key, nonce, ad, out string
+}{
+	{
+		// RFC 8439, Section 2.8.2.
+		"ChaCha20-Poly1305", New,
+		"808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f",
+		"070000004041424344454647",
+		"50515253c0c1c2c3c4c5c6c7",
+		"d31a8d34648e60db7b86afbc53ef7ec2a4aded51296e08fea9e2b5a736ee62d63dbea45e8ca9671282fafb69da92728b1a71de0a9e060b2905d6a5b67ecd3b3692ddbd7f2d778b8c9803aee328091b58fab324e4fad675945585808b4831d7bc3ff4def08e4b7a9de576d26586cec64b6116" +
+			"1ae10b594f09e26a7e902ecbd0600691",
+	},
+	{
+		// draft-irtf-cfrg-xchacha-03, Appendix A.3.1.
+		"XChaCha20-Poly1305", NewX,
+		"808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f",
+		"404142434445464748494a4b4c4d4e4f5051525354555657",
+		"50515253c0c1c2c3c4c5c6c7",
+		"bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b4522f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff921f9664c97637da9768812f615c68b13b52e" +
+			"c0875924c1c7987947deafd8780acf49",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aead, err := tt.newAEAD(fromHex(tt.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce, ad, want := fromHex(tt.nonce), fromHex(tt.ad), fromHex(tt.out)
+			if got := aead.Seal(nil, nonce, []byte(sunscreen), ad); !bytes.Equal(got, want) {
+				t.Errorf("Seal: got %x, want %x", got, want)
+			}
+			pt, err := aead.Open(nil, nonce, want, ad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(pt) != sunscreen {
+				t.Errorf("Open: got %q", pt)
+			}
+		})
+	}
+}
+
+func TestAEAD(t *testing.T) {
+	key := make([]byte, KeySize)
+	t.Run("ChaCha20-Poly1305", func(t *testing.T) {
+		cryptotest.TestAEAD(t, func() (cipher.AEAD, error) { return New(key) })
+	})
+	t.Run("XChaCha20-Poly1305", func(t *testing.T) {
+		cryptotest.TestAEAD(t, func() (cipher.AEAD, error) { return NewX(key) })
+	})
+}
+
+func TestSizes(t *testing.T) {
+	for _, newAEAD := range []func([]byte) (cipher.AEAD, error){New, NewX} {
+		if _, err := newAEAD(make([]byte, KeySize-1)); err == nil {
+			t.Errorf("accepted a short key")
+		}
+	}
+	a, _ := New(make([]byte, KeySize))
+	x, _ := NewX(make([]byte, KeySize))
+	if a.NonceSize() != NonceSize || x.NonceSize() != NonceSizeX ||
+		a.Overhead() != Overhead || x.Overhead() != Overhead {
+		t.Errorf("unexpected sizes")
+	}
+}
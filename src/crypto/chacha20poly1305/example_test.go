@@ -0,0 +1,36 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chacha20poly1305_test
+
+import (
+	"crypto/chacha20poly1305"
+	"crypto/rand"
+	"fmt"
+	"log"
+)
+
+func ExampleNewX() {
+	key := make([]byte, chacha20poly1305.KeySize)
+	rand.Read(key)
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// XChaCha20-Poly1305 nonces are long enough to be selected at random.
+	nonce := make([]byte, chacha20poly1305.NonceSizeX, chacha20poly1305.NonceSizeX+len("hello")+chacha20poly1305.Overhead)
+	rand.Read(nonce)
+	ciphertext := aead.Seal(nonce, nonce, []byte("hello"), nil)
+
+	// Split the nonce and the ciphertext when decrypting.
+	nonce, ciphertext = ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s\n", plaintext)
+	// Output: hello
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cipher
+
+import (
+	"crypto/internal/fips140/aes"
+	"crypto/internal/fips140/alias"
+	"crypto/internal/fips140only"
+	"crypto/subtle"
+	"errors"
+	"internal/byteorder"
+)
+
+const (
+	gcmSIVNonceSize = 12
+	gcmSIVTagSize   = 16
+
+	// gcmSIVMaxSize is the maximum length of plaintexts and additional data,
+	// 2³⁶ bytes, according to RFC 8452, Section 6.
+	gcmSIVMaxSize = 1 << 36
+)
+
+// NewGCMSIV returns an AES-GCM-SIV AEAD, as specified in RFC 8452, for key.
+// key must be 16 bytes for AEAD_AES_128_GCM_SIV or 32 bytes for
+// AEAD_AES_256_GCM_SIV.
+//
+// AES-GCM-SIV is resistant to nonce misuse: repeating a nonce only reveals
+// whether the same plaintext and additional data were encrypted twice, unlike
+// GCM, where it reveals the authentication key. It also derives fresh keys for
+// each nonce, so random 96-bit nonces can be used for up to 2⁶⁴ messages
+// under a single key. Encrypting requires two passes over the plaintext.
+func NewGCMSIV(key []byte) (AEAD, error) {
+	if fips140only.Enabled {
+		return nil, errors.New("crypto/cipher: use of AES-GCM-SIV is not allowed in FIPS 140-only mode")
+	}
+	if len(key) != 16 && len(key) != 32 {
+		return nil, errors.New("cipher: NewGCMSIV requires a 16 or 32-byte key")
+	}
+	kgk, err := aes.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmSIV{kgk: kgk, keySize: len(key)}, nil
+}
+
+type gcmSIV struct {
+	kgk     *aes.Block // key-generating key
+	keySize int
+}
+
+func (g *gcmSIV) NonceSize() int {
+	return gcmSIVNonceSize
+}
+
+func (g *gcmSIV) Overhead() int {
+	return gcmSIVTagSize
+}
+
+func (g *gcmSIV) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("crypto/cipher: incorrect nonce length given to GCM-SIV")
+	}
+	if uint64(len(plaintext)) > gcmSIVMaxSize {
+		panic("crypto/cipher: message too large for GCM-SIV")
+	}
+	if uint64(len(additionalData)) > gcmSIVMaxSize {
+		panic("crypto/cipher: additional data too large for GCM-SIV")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmSIVTagSize)
+	if alias.InexactOverlap(out, plaintext) {
+		panic("crypto/cipher: invalid buffer overlap of output and input")
+	}
+	if alias.AnyOverlap(out, additionalData) {
+		panic("crypto/cipher: invalid buffer overlap of output and additional data")
+	}
+
+	authKey, enc := g.deriveKeys(nonce)
+	tag := gcmSIVTag(&authKey, enc, nonce, plaintext, additionalData)
+	// The plaintext is read fully by POLYVAL before being overwritten, in
+	// case the output and input overlap exactly.
+	gcmSIVCTR(enc, out[:len(plaintext)], plaintext, &tag)
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+func (g *gcmSIV) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("crypto/cipher: incorrect nonce length given to GCM-SIV")
+	}
+	if len(ciphertext) < gcmSIVTagSize ||
+		uint64(len(ciphertext)) > gcmSIVMaxSize+gcmSIVTagSize ||
+		uint64(len(additionalData)) > gcmSIVMaxSize {
+		return nil, errOpen
+	}
+
+	var tag [gcmSIVTagSize]byte
+	copy(tag[:], ciphertext[len(ciphertext)-gcmSIVTagSize:])
+	ciphertext = ciphertext[:len(ciphertext)-gcmSIVTagSize]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if alias.InexactOverlap(out, ciphertext) {
+		panic("crypto/cipher: invalid buffer overlap of output and input")
+	}
+	if alias.AnyOverlap(out, additionalData) {
+		panic("crypto/cipher: invalid buffer overlap of output and additional data")
+	}
+
+	authKey, enc := g.deriveKeys(nonce)
+	gcmSIVCTR(enc, out, ciphertext, &tag)
+	expected := gcmSIVTag(&authKey, enc, nonce, out, additionalData)
+	if subtle.ConstantTimeCompare(expected[:], tag[:]) != 1 {
+		// The AESCTR output is unauthenticated, so clear it.
+		clear(out)
+		return nil, errOpen
+	}
+	return ret, nil
+}
+
+// deriveKeys derives the message-authentication key and the
+// message-encryption cipher for nonce, according to RFC 8452, Section 4.
+func (g *gcmSIV) deriveKeys(nonce []byte) (authKey [16]byte, enc *aes.Block) {
+	var keys [16 + 32]byte
+	var in, out [aes.BlockSize]byte
+	copy(in[4:], nonce)
+	// Each encryption contributes its first half to the derived keys.
+	for i := range (16 + g.keySize) / 8 {
+		byteorder.LEPutUint32(in[:4], uint32(i))
+		aes.EncryptBlockInternal(g.kgk, out[:], in[:])
+		copy(keys[i*8:], out[:8])
+	}
+	copy(authKey[:], keys[:16])
+	enc, err := aes.New(keys[16 : 16+g.keySize])
+	if err != nil {
+		panic("crypto/cipher: internal error: " + err.Error())
+	}
+	return authKey, enc
+}
+
+// gcmSIVTag computes the tag over plaintext and additionalData.
+func gcmSIVTag(authKey *[16]byte, enc *aes.Block, nonce, plaintext, additionalData []byte) [gcmSIVTagSize]byte {
+	s := polyval(authKey, additionalData, plaintext)
+	for i := range nonce {
+		s[i] ^= nonce[i]
+	}
+	s[15] &= 0x7f
+	var tag [gcmSIVTagSize]byte
+	aes.EncryptBlockInternal(enc, tag[:], s[:])
+	return tag
+}
+
+// gcmSIVCTR encrypts src into dst with AES-CTR, using tag with the most
+// significant bit set as the initial counter block, and a 32-bit
+// little-endian counter in its first four bytes.
+//
+// The counter layout is not compatible with [aes.CTR], which increments the
+// whole block as a big-endian integer, so counter blocks are instead encrypted
+// in batches, and the keystream is XORed into src a batch at a time.
+func gcmSIVCTR(enc *aes.Block, dst, src []byte, tag *[gcmSIVTagSize]byte) {
+	counter := *tag
+	counter[15] |= 0x80
+	ctr := byteorder.LEUint32(counter[:4])
+	var ks [8 * aes.BlockSize]byte
+	for len(src) > 0 {
+		n := min(len(src), len(ks))
+		for i := 0; i < n; i += aes.BlockSize {
+			byteorder.LEPutUint32(counter[:4], ctr)
+			aes.EncryptBlockInternal(enc, ks[i:i+aes.BlockSize], counter[:])
+			ctr++
+		}
+		subtle.XORBytes(dst, src[:n], ks[:n])
+		dst, src = dst[n:], src[n:]
+	}
+}
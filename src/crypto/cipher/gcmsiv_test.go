@@ -0,0 +1,148 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cipher_test
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/internal/cryptotest"
+	"encoding/hex"
+	"testing"
+)
+
+// aesGCMSIVTests are from RFC 8452, Appendix C.
+var aesGCMSIVTests = []struct {
+	key, nonce, plaintext, ad, result string
+}{
+	{
+		"01000000000000000000000000000000",
+		"030000000000000000000000",
+		"",
+		"",
+		"dc20e2d83f25705bb49e439eca56de25",
+	},
+	{
+		"01000000000000000000000000000000",
+		"030000000000000000000000",
+		"0100000000000000",
+		"",
+		"b5d839330ac7b786578782fff6013b815b287c22493a364c",
+	},
+	{
+		"01000000000000000000000000000000",
+		"030000000000000000000000",
+		"010000000000000000000000",
+		"",
+		"7323ea61d05932260047d942a4978db357391a0bc4fdec8b0d106639",
+	},
+	{
+		"01000000000000000000000000000000",
+		"030000000000000000000000",
+		"0200000000000000",
+		"01",
+		"1e6daba35669f4273b0a1a2560969cdf790d99759abd1508",
+	},
+	{
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"030000000000000000000000",
+		"",
+		"",
+		"07f5f4169bbf55a8400cd47ea6fd400f",
+	},
+	{
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"030000000000000000000000",
+		"0100000000000000",
+		"",
+		"c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+	},
+}
+
+func TestAESGCMSIV(t *testing.T) {
+	cryptotest.TestAllImplementations(t, "aes", testAESGCMSIV)
+}
+
+func testAESGCMSIV(t *testing.T) {
+	for i, tt := range aesGCMSIVTests {
+		key, _ := hex.DecodeString(tt.key)
+		nonce, _ := hex.DecodeString(tt.nonce)
+		plaintext, _ := hex.DecodeString(tt.plaintext)
+		ad, _ := hex.DecodeString(tt.ad)
+		result, _ := hex.DecodeString(tt.result)
+
+		aead, err := cipher.NewGCMSIV(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct := aead.Seal(nil, nonce, plaintext, ad)
+		if !bytes.Equal(ct, result) {
+			t.Errorf("#%d: got %x, want %x", i, ct, result)
+			continue
+		}
+		pt, err := aead.Open(nil, nonce, ct, ad)
+		if err != nil {
+			t.Errorf("#%d: Open failed: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(pt, plaintext) {
+			t.Errorf("#%d: got plaintext %x, want %x", i, pt, plaintext)
+		}
+
+		ct[0] ^= 0x80
+		dst := bytes.Repeat([]byte{0xff}, 64)
+		if _, err := aead.Open(dst[:0], nonce, ct, ad); err == nil {
+			t.Errorf("#%d: Open succeeded on a modified ciphertext", i)
+		}
+		if !bytes.Equal(dst[:len(plaintext)], make([]byte, len(plaintext))) {
+			t.Errorf("#%d: Open did not clear the output on failure: %x", i, dst[:len(plaintext)])
+		}
+	}
+}
+
+// TestAESGCMSIVImplementations checks that all AES implementations agree on
+// inputs that span multiple POLYVAL blocks and CTR batches.
+func TestAESGCMSIVImplementations(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	nonce := make([]byte, 12)
+	input := make([]byte, 2100)
+	for i := range input {
+		input[i] = byte(i)
+	}
+	lengths := []int{0, 1, 15, 16, 17, 127, 128, 129, 511, 512, 513, 1024, 1041, 2100}
+	want := make(map[int][]byte)
+	cryptotest.TestAllImplementations(t, "aes", func(t *testing.T) {
+		aead, err := cipher.NewGCMSIV(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range lengths {
+			ad := input[len(input)-n/2:]
+			ct := aead.Seal(nil, nonce, input[:n], ad)
+			if w, ok := want[n]; !ok {
+				want[n] = ct
+			} else if !bytes.Equal(ct, w) {
+				t.Errorf("length %d: got %x, want %x", n, ct, w)
+			}
+			if pt, err := aead.Open(nil, nonce, ct, ad); err != nil || !bytes.Equal(pt, input[:n]) {
+				t.Errorf("length %d: Open failed: %v", n, err)
+			}
+		}
+	})
+}
+
+func TestAESGCMSIVAEAD(t *testing.T) {
+	for _, keySize := range []int{16, 32} {
+		key := make([]byte, keySize)
+		cryptotest.TestAEAD(t, func() (cipher.AEAD, error) { return cipher.NewGCMSIV(key) })
+	}
+}
+
+func TestAESGCMSIVInvalid(t *testing.T) {
+	for _, keySize := range []int{0, 15, 24, 33} {
+		if _, err := cipher.NewGCMSIV(make([]byte, keySize)); err == nil {
+			t.Errorf("NewGCMSIV accepted a %d-byte key", keySize)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cipher
+
+import (
+	"internal/byteorder"
+	"math/bits"
+)
+
+// polyvalElement is an element of the POLYVAL field GF(2¹²⁸), defined by the
+// polynomial x¹²⁸ + x¹²⁷ + x¹²⁶ + x¹²¹ + 1, with the coefficient of xⁱ in bit
+// i of the little-endian 128-bit integer lo + hi·2⁶⁴, according to RFC 8452,
+// Section 3.
+type polyvalElement struct {
+	lo, hi uint64
+}
+
+// polyval computes the POLYVAL universal hash with key over additionalData
+// and plaintext, each zero-padded to a multiple of 16 bytes, followed by the
+// block encoding their bit lengths, according to RFC 8452, Section 4.
+//
+// It is implemented with constant-time carry-less multiplications, rather than
+// with the GHASH implementations of the FIPS 140 module, which AES-GCM-SIV is
+// not part of.
+func polyval(key *[16]byte, additionalData, plaintext []byte) [16]byte {
+	h := polyvalElement{byteorder.LEUint64(key[:8]), byteorder.LEUint64(key[8:])}
+	var s polyvalElement
+	update := func(block []byte) {
+		s.lo ^= byteorder.LEUint64(block[:8])
+		s.hi ^= byteorder.LEUint64(block[8:])
+		s = polyvalDot(s, h)
+	}
+	for _, in := range [][]byte{additionalData, plaintext} {
+		for len(in) >= 16 {
+			update(in[:16])
+			in = in[16:]
+		}
+		if len(in) > 0 {
+			var block [16]byte
+			copy(block[:], in)
+			update(block[:])
+		}
+	}
+	var lengths [16]byte
+	byteorder.LEPutUint64(lengths[:8], uint64(len(additionalData))*8)
+	byteorder.LEPutUint64(lengths[8:], uint64(len(plaintext))*8)
+	update(lengths[:])
+
+	var out [16]byte
+	byteorder.LEPutUint64(out[:8], s.lo)
+	byteorder.LEPutUint64(out[8:], s.hi)
+	return out
+}
+
+// polyvalDot returns a·b·x⁻¹²⁸, the dot operation of RFC 8452, Section 3.
+func polyvalDot(a, b polyvalElement) polyvalElement {
+	// Karatsuba multiplication of the 128-bit polynomials into v3:v2:v1:v0.
+	lo0, lo1 := clmul(a.lo, b.lo)
+	hi0, hi1 := clmul(a.hi, b.hi)
+	mid0, mid1 := clmul(a.lo^a.hi, b.lo^b.hi)
+	mid0 ^= lo0 ^ hi0
+	mid1 ^= lo1 ^ hi1
+	v0, v1, v2, v3 := lo0, lo1^mid0, hi0^mid1, hi1
+
+	// Montgomery reduction: adding vᵢ times the field polynomial clears the
+	// low word vᵢ, and dividing by x¹²⁸ drops the two low words.
+	v1 ^= v0<<63 ^ v0<<62 ^ v0<<57
+	v2 ^= v0 ^ v0>>1 ^ v0>>2 ^ v0>>7
+	v2 ^= v1<<63 ^ v1<<62 ^ v1<<57
+	v3 ^= v1 ^ v1>>1 ^ v1>>2 ^ v1>>7
+	return polyvalElement{v2, v3}
+}
+
+// clmul returns the 128-bit carry-less product of x and y as lo, hi.
+func clmul(x, y uint64) (lo, hi uint64) {
+	lo = bmul64(x, y)
+	hi = bits.Reverse64(bmul64(bits.Reverse64(x), bits.Reverse64(y))) >> 1
+	return lo, hi
+}
+
+// bmul64 returns the low 64 bits of the carry-less product of x and y, in
+// constant time. Integer multiplications are performed on operands with only
+// every fourth bit set, so that carries fall into the bits that are masked out.
+func bmul64(x, y uint64) uint64 {
+	const (
+		m0 = 0x1111111111111111
+		m1 = 0x2222222222222222
+		m2 = 0x4444444444444444
+		m3 = 0x8888888888888888
+	)
+	x0, x1, x2, x3 := x&m0, x&m1, x&m2, x&m3
+	y0, y1, y2, y3 := y&m0, y&m1, y&m2, y&m3
+	z0 := x0*y0 ^ x1*y3 ^ x2*y2 ^ x3*y1
+	z1 := x0*y1 ^ x1*y0 ^ x2*y3 ^ x3*y2
+	z2 := x0*y2 ^ x1*y1 ^ x2*y0 ^ x3*y3
+	z3 := x0*y3 ^ x1*y2 ^ x2*y1 ^ x3*y0
+	return z0&m0 | z1&m1 | z2&m2 | z3&m3
+}
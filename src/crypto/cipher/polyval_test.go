@@ -0,0 +1,81 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cipher
+
+import (
+	"encoding/hex"
+	"internal/byteorder"
+	"testing"
+)
+
+// TestPOLYVALDot checks the example of RFC 8452, Appendix A.
+func TestPOLYVALDot(t *testing.T) {
+	decode := func(s string) polyvalElement {
+		b, _ := hex.DecodeString(s)
+		return polyvalElement{byteorder.LEUint64(b[:8]), byteorder.LEUint64(b[8:])}
+	}
+	h := decode("25629347589242761d31f826ba4b757b")
+	x1 := decode("4f4f95668c83dfb6401762bb2d01a262")
+	x2 := decode("d1a24ddd2721d006bbe45f20d3c9f362")
+	s := polyvalDot(x1, h)
+	s = polyvalDot(polyvalElement{s.lo ^ x2.lo, s.hi ^ x2.hi}, h)
+	if want := decode("f7a3b47b846119fae5b7866cf5e5b77e"); s != want {
+		t.Errorf("POLYVAL = %016x%016x, want %016x%016x", s.hi, s.lo, want.hi, want.lo)
+	}
+}
+
+// polyvalTests were computed with a bitwise implementation of the field
+// arithmetic of RFC 8452, Section 3, and cover partial and multiple blocks.
+var polyvalTests = []struct {
+	key, additionalData, plaintext, result string
+}{
+	{
+		"4420823cfde6f1c26b30f90ec7dd01e4",
+		"",
+		"",
+		"00000000000000000000000000000000",
+	},
+	{
+		"887534a20f0b0d04c36ed80e71e0fd77",
+		"b0",
+		"7670eb940bd5335f973daad8619b91ffc9",
+		"928d28c40c9dad8566468de0179c9f62",
+	},
+	{
+		"11f57cced458bbbf2ce03753c9bdfa0f",
+		"f0169dc9575674066676cfb0b4eb8902c44269da1cf6ba66d3f8b6d4b100a9ea" +
+			"0e",
+		"755a5c2e8210242a08e7078f7f89385eb09423555182568b96e8a4fef23a0c9f" +
+			"c5afd7608437816bdd0a7309cb4a1252e4da70e6720fcaa4da1e98406c189c24" +
+			"279e9851d5814204136feb5713c166b13269dd63fc35c797ff08a6cd90095066" +
+			"a745addb",
+		"8bcef2e2278142617f38188469aac8fb",
+	},
+	{
+		"6d8831c2b0f87821142b4456556d89aa",
+		"82bcadae3a9578fa4535a414d025c24b",
+		"40ae3ac127722988ba973aea8d37179706072ed33a14607ad7523be6557b5134" +
+			"dec19681f4a1336aa2140d0597a3e6c8a0cc2020a2e939806ef0b6845d6a9d65" +
+			"7eb8298f2de52ead74c79d15a75fa29b7dab332f7d700a7ccd258924260b0594" +
+			"b7fcf04e33a727585b4c48a39c369640694810a1695b99dd50187e8120e4dc80" +
+			"e0e805caad5784f80cd5091fb5464046848dcbcd582d77f8035aa2e0737aa0fd" +
+			"f573d3ac8c701824bc51689f9899be54ed2b3fc15a4f80da6f1afdc9b2c45414" +
+			"2e8233882a4729e37bc3ddcb54a6e040f96c3ddcd13c978e7fc10261e00a0f7c" +
+			"856958914b668b9f80e456b6fbd73e6ac46891370c3c06974526bf9fdfb6a500",
+		"7c5dccae87b89bab1690c68345ce5059",
+	},
+}
+
+func TestPOLYVAL(t *testing.T) {
+	for i, tt := range polyvalTests {
+		key, _ := hex.DecodeString(tt.key)
+		ad, _ := hex.DecodeString(tt.additionalData)
+		pt, _ := hex.DecodeString(tt.plaintext)
+		got := polyval((*[16]byte)(key), ad, pt)
+		if hex.EncodeToString(got[:]) != tt.result {
+			t.Errorf("#%d: got %x, want %s", i, got, tt.result)
+		}
+	}
+}
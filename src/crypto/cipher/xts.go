@@ -0,0 +1,185 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cipher
+
+import (
+	"crypto/internal/fips140/aes"
+	"crypto/internal/fips140/alias"
+	"crypto/subtle"
+	"errors"
+	"internal/byteorder"
+)
+
+// xtsMaxDataUnitSize is the maximum length of a data unit, 2²⁰ blocks,
+// according to NIST SP 800-38E.
+const xtsMaxDataUnitSize = 1 << 20 * aes.BlockSize
+
+// XTS is an XTS-AES cipher, the XEX-based tweaked-codebook mode with
+// ciphertext stealing, as specified in IEEE Std 1619-2018 and NIST SP 800-38E.
+//
+// XTS is meant for encrypting storage, where each data unit (usually a disk
+// sector) must be encrypted in place, without room for a nonce or an
+// authentication tag. It provides confidentiality, but no integrity: a
+// modified ciphertext decrypts to unpredictable plaintext without an error.
+// Encrypting the same plaintext at the same sector always produces the same
+// ciphertext.
+//
+// An XTS is safe for concurrent use by multiple goroutines.
+type XTS struct {
+	k1, k2 *aes.Block
+}
+
+// NewXTS returns an XTS-AES cipher for key, the concatenation of the AES key
+// that encrypts data and the AES key that encrypts tweaks. key must be 32 bytes
+// for XTS-AES-128 or 64 bytes for XTS-AES-256.
+//
+// NewXTS returns an error if the two halves of key are the same, as required
+// by NIST SP 800-38E.
+func NewXTS(key []byte) (*XTS, error) {
+	if len(key) != 32 && len(key) != 64 {
+		return nil, errors.New("cipher: NewXTS requires a 32 or 64-byte key")
+	}
+	k1, k2 := key[:len(key)/2], key[len(key)/2:]
+	if subtle.ConstantTimeCompare(k1, k2) == 1 {
+		return nil, errors.New("cipher: NewXTS requires two different AES keys")
+	}
+	b1, err := aes.New(k1)
+	if err != nil {
+		return nil, err
+	}
+	b2, err := aes.New(k2)
+	if err != nil {
+		return nil, err
+	}
+	return &XTS{k1: b1, k2: b2}, nil
+}
+
+// Encrypt encrypts the data unit in src into dst, using sector as its data
+// unit number. dst and src must overlap entirely or not at all.
+//
+// src must be at least one block (16 bytes) long. If its length is not a
+// multiple of the block size, the final partial block is encrypted with
+// ciphertext stealing. If len(dst) < len(src), Encrypt panics.
+func (x *XTS) Encrypt(dst, src []byte, sector uint64) {
+	x.checkDataUnit(dst, src)
+	x.encrypt(dst, src, sector)
+}
+
+// Decrypt decrypts the data unit in src into dst, using sector as its data
+// unit number. dst and src must overlap entirely or not at all.
+//
+// src must be at least one block (16 bytes) long. If len(dst) < len(src),
+// Decrypt panics.
+func (x *XTS) Decrypt(dst, src []byte, sector uint64) {
+	x.checkDataUnit(dst, src)
+	x.decrypt(dst, src, sector)
+}
+
+func (x *XTS) checkDataUnit(dst, src []byte) {
+	if len(src) < aes.BlockSize {
+		panic("crypto/cipher: data unit shorter than one block given to XTS")
+	}
+	if len(src) > xtsMaxDataUnitSize {
+		panic("crypto/cipher: data unit too large for XTS")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	if alias.InexactOverlap(dst[:len(src)], src) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+}
+
+// tweak returns the initial tweak for sector, the encryption under k2 of the
+// data unit number as a little-endian 128-bit integer.
+func (x *XTS) tweak(sector uint64) [aes.BlockSize]byte {
+	var t [aes.BlockSize]byte
+	byteorder.LEPutUint64(t[:8], sector)
+	aes.EncryptBlockInternal(x.k2, t[:], t[:])
+	return t
+}
+
+func (x *XTS) encrypt(dst, src []byte, sector uint64) {
+	t := x.tweak(sector)
+	full := len(src) / aes.BlockSize
+	tail := len(src) % aes.BlockSize
+	if tail != 0 {
+		// The last full block is processed with ciphertext stealing.
+		full--
+	}
+	for range full {
+		xtsEncryptBlock(x.k1, dst[:aes.BlockSize], src[:aes.BlockSize], &t)
+		mulAlpha(&t)
+		dst, src = dst[aes.BlockSize:], src[aes.BlockSize:]
+	}
+	if tail == 0 {
+		return
+	}
+
+	// Encrypt the last full block, output the start of its ciphertext as the
+	// final partial block, and encrypt the partial plaintext padded with the
+	// rest of that ciphertext in its place, according to IEEE Std 1619-2018,
+	// Section 5.3.2.
+	var cc, pp [aes.BlockSize]byte
+	xtsEncryptBlock(x.k1, cc[:], src[:aes.BlockSize], &t)
+	mulAlpha(&t)
+	copy(pp[:], src[aes.BlockSize:])
+	copy(pp[tail:], cc[tail:])
+	copy(dst[aes.BlockSize:], cc[:tail])
+	xtsEncryptBlock(x.k1, dst[:aes.BlockSize], pp[:], &t)
+}
+
+func (x *XTS) decrypt(dst, src []byte, sector uint64) {
+	t := x.tweak(sector)
+	full := len(src) / aes.BlockSize
+	tail := len(src) % aes.BlockSize
+	if tail != 0 {
+		full--
+	}
+	for range full {
+		xtsDecryptBlock(x.k1, dst[:aes.BlockSize], src[:aes.BlockSize], &t)
+		mulAlpha(&t)
+		dst, src = dst[aes.BlockSize:], src[aes.BlockSize:]
+	}
+	if tail == 0 {
+		return
+	}
+
+	// The last full ciphertext block was encrypted with the tweak following
+	// the one of the partial block, according to IEEE Std 1619-2018,
+	// Section 5.4.2.
+	next := t
+	mulAlpha(&next)
+	var cc, pp [aes.BlockSize]byte
+	xtsDecryptBlock(x.k1, pp[:], src[:aes.BlockSize], &next)
+	copy(cc[:], src[aes.BlockSize:])
+	copy(cc[tail:], pp[tail:])
+	copy(dst[aes.BlockSize:], pp[:tail])
+	xtsDecryptBlock(x.k1, dst[:aes.BlockSize], cc[:], &t)
+}
+
+func xtsEncryptBlock(b *aes.Block, dst, src []byte, t *[aes.BlockSize]byte) {
+	subtle.XORBytes(dst, src, t[:])
+	aes.EncryptBlockInternal(b, dst, dst)
+	subtle.XORBytes(dst, dst, t[:])
+}
+
+func xtsDecryptBlock(b *aes.Block, dst, src []byte, t *[aes.BlockSize]byte) {
+	subtle.XORBytes(dst, src, t[:])
+	aes.DecryptBlockInternal(b, dst, dst)
+	subtle.XORBytes(dst, dst, t[:])
+}
+
+// mulAlpha multiplies the tweak by the primitive element α in GF(2¹²⁸), with
+// the little-endian representation of IEEE Std 1619-2018, Section 5.2.
+func mulAlpha(t *[aes.BlockSize]byte) {
+	lo := byteorder.LEUint64(t[:8])
+	hi := byteorder.LEUint64(t[8:])
+	carry := hi >> 63
+	hi = hi<<1 | lo>>63
+	lo = lo<<1 ^ carry*0x87
+	byteorder.LEPutUint64(t[:8], lo)
+	byteorder.LEPutUint64(t[8:], hi)
+}
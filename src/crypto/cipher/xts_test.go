@@ -0,0 +1,120 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cipher_test
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+// xtsTests start with a prefix of vector 4 of IEEE Std 1619-2018, Appendix B.
+// The others were generated with OpenSSL, to cover ciphertext stealing and
+// 256-bit keys. The plaintext is the bytes 0, 1, 2, ...
+var xtsTests = []struct {
+	key1, key2 string
+	sector     uint64
+	ciphertext string
+}{
+	{
+		"27182818284590452353602874713526",
+		"31415926535897932384626433832795",
+		0,
+		"27a7479befa1d476489f308cd4cfa6e2a96e4bbe3208ff25287dd3819616e89c",
+	},
+	{
+		"fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+		"bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+		0x9a78563412,
+		"641610679dcbf92e505c41333fb06c2a95",
+	},
+	{
+		"fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+		"bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+		0x9a78563412,
+		"c03f4c6088fcf14c308aa39f7938980995c871f6522469cc737109594ab0fe",
+	},
+	{
+		"2718281828459045235360287471352662497757247093699959574966967627",
+		"3141592653589793238462643383279502884197169399375105820974944592",
+		0xff,
+		"1c3b3a102f770386e4836c99e370cf9bea00803f5e482357a4ae12d414a3e63bd9407346a389cf3326fbef0b294be8995d31e276f8",
+	},
+	{
+		"2718281828459045235360287471352662497757247093699959574966967627",
+		"3141592653589793238462643383279502884197169399375105820974944592",
+		0xffffffffffffffff,
+		"6d4d5df16d900b3609d1138dfd0641e75ef7036175bcd4dc1260e2b3288035e8c10b3bf90fb8a59b9fb22f3d6e1635227efeaa79edf068cceeaaa9786ea22947",
+	},
+}
+
+func newXTS(t *testing.T, key1, key2 []byte) *cipher.XTS {
+	t.Helper()
+	x, err := cipher.NewXTS(append(bytes.Clone(key1), key2...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestXTS(t *testing.T) {
+	for i, tt := range xtsTests {
+		key1, _ := hex.DecodeString(tt.key1)
+		key2, _ := hex.DecodeString(tt.key2)
+		want, _ := hex.DecodeString(tt.ciphertext)
+		x := newXTS(t, key1, key2)
+
+		plaintext := make([]byte, len(want))
+		for j := range plaintext {
+			plaintext[j] = byte(j)
+		}
+		got := make([]byte, len(plaintext))
+		x.Encrypt(got, plaintext, tt.sector)
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: got %x, want %x", i, got, want)
+		}
+		x.Decrypt(got, got, tt.sector)
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("#%d: in-place decryption: got %x, want %x", i, got, plaintext)
+		}
+		x.Encrypt(got, got, tt.sector)
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: in-place encryption: got %x, want %x", i, got, want)
+		}
+	}
+}
+
+func TestXTSInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		key  []byte
+	}{
+		{"same keys", bytes.Repeat([]byte{1}, 32)},
+		{"AES-192", bytes.Repeat([]byte{1}, 48)},
+		{"short key", make([]byte, 16)},
+	} {
+		if _, err := cipher.NewXTS(tt.key); err == nil {
+			t.Errorf("%s: NewXTS succeeded", tt.name)
+		}
+	}
+
+	x := newXTS(t, make([]byte, 16), bytes.Repeat([]byte{1}, 16))
+	mustPanic(t, "short data unit", func() { x.Encrypt(make([]byte, 16), make([]byte, 15), 0) })
+	mustPanic(t, "short output", func() { x.Decrypt(make([]byte, 16), make([]byte, 17), 0) })
+	buf := make([]byte, 33)
+	mustPanic(t, "inexact overlap", func() { x.Encrypt(buf[1:], buf[:32], 0) })
+}
+
+func mustPanic(t *testing.T, msg string, f func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if recover() == nil {
+			t.Errorf("function did not panic for %q", msg)
+		}
+	}()
+	f()
+}
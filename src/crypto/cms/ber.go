@@ -0,0 +1,173 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// CMS producers, in particular when streaming S/MIME messages, often use the
+// BER indefinite-length encoding and split OCTET STRINGs into constructed
+// chunks. berToDER normalizes those, so that the rest of the package can use
+// the strict DER parser of cryptobyte. Other BER features are not supported.
+//
+// DER input is returned unchanged, so the DER-encoded signed attributes of a
+// SignedData, over which the signature is computed, are preserved.
+
+// maxBERDepth is the maximum nesting of BER elements.
+const maxBERDepth = 64
+
+var errMalformedBER = errors.New("cms: malformed BER encoding")
+
+func berToDER(ber []byte) ([]byte, error) {
+	der, rest, err := appendDERElement(nil, ber, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("cms: trailing data")
+	}
+	return der, nil
+}
+
+// appendDERElement appends the DER form of the first BER element of in to
+// out, and returns the rest of in.
+func appendDERElement(out, in []byte, depth int) ([]byte, []byte, error) {
+	if depth > maxBERDepth {
+		return nil, nil, errors.New("cms: BER nesting too deep")
+	}
+
+	// Identifier octets, which are copied as is.
+	if len(in) < 2 {
+		return nil, nil, errMalformedBER
+	}
+	idLen := 1
+	if in[0]&0x1f == 0x1f {
+		for in[idLen]&0x80 != 0 {
+			idLen++
+			if idLen >= len(in) || idLen > 4 {
+				return nil, nil, errMalformedBER
+			}
+		}
+		idLen++
+	}
+	if idLen >= len(in) {
+		return nil, nil, errMalformedBER
+	}
+	id := in[:idLen]
+	constructed := in[0]&0x20 != 0
+	in = in[idLen:]
+
+	// Length octets.
+	var content []byte
+	indefinite := false
+	switch l := in[0]; {
+	case l == 0x80:
+		if !constructed {
+			return nil, nil, errMalformedBER
+		}
+		indefinite = true
+		in = in[1:]
+	case l < 0x80:
+		in = in[1:]
+		if int(l) > len(in) {
+			return nil, nil, errMalformedBER
+		}
+		content, in = in[:l], in[l:]
+	default:
+		n := int(l & 0x7f)
+		if n > 4 || 1+n > len(in) {
+			return nil, nil, errMalformedBER
+		}
+		length := 0
+		for _, b := range in[1 : 1+n] {
+			length = length<<8 | int(b)
+		}
+		in = in[1+n:]
+		if length < 0 || length > len(in) {
+			return nil, nil, errMalformedBER
+		}
+		content, in = in[:length], in[length:]
+	}
+
+	if !constructed {
+		return appendElement(out, id, content), in, nil
+	}
+
+	// Convert the children of a constructed element.
+	var children []byte
+	for {
+		if indefinite {
+			if len(in) < 2 {
+				return nil, nil, errMalformedBER
+			}
+			if in[0] == 0 && in[1] == 0 {
+				in = in[2:]
+				break
+			}
+			var err error
+			children, in, err = appendDERElement(children, in, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			if len(content) == 0 {
+				break
+			}
+			var err error
+			children, content, err = appendDERElement(children, content, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// A constructed OCTET STRING is the concatenation of its chunks.
+	if id[0] == 0x24 {
+		octets, err := concatOctetStrings(children)
+		if err != nil {
+			return nil, nil, err
+		}
+		return appendElement(out, []byte{0x04}, octets), in, nil
+	}
+	return appendElement(out, id, children), in, nil
+}
+
+// concatOctetStrings returns the concatenated contents of a sequence of DER
+// OCTET STRINGs.
+func concatOctetStrings(der []byte) ([]byte, error) {
+	s := cryptobyte.String(der)
+	var out []byte
+	for !s.Empty() {
+		var chunk cryptobyte.String
+		if !s.ReadASN1(&chunk, cryptobyte_asn1.OCTET_STRING) {
+			return nil, errMalformedBER
+		}
+		out = append(out, chunk...)
+	}
+	return out, nil
+}
+
+// appendElement appends an element with the given identifier octets and
+// content to out, using the DER length encoding.
+func appendElement(out, id, content []byte) []byte {
+	out = append(out, id...)
+	switch n := len(content); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 1<<8:
+		out = append(out, 0x81, byte(n))
+	case n < 1<<16:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	case n < 1<<24:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, content...)
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cms implements the SignedData and EnvelopedData content types of
+// the Cryptographic Message Syntax (CMS), as specified in [RFC 5652].
+//
+// CMS, a superset of PKCS #7, is the format of S/MIME signed and encrypted
+// messages, and of many code signing and timestamping protocols.
+//
+// [Sign] creates a SignedData with either attached or detached content, and
+// [ParseSignedData] parses one, whose signatures can then be verified against
+// a set of trusted roots with [SignedData.Verify] or
+// [SignedData.VerifyDetached].
+//
+// [Encrypt] creates an EnvelopedData for a set of recipient certificates,
+// using RSA-OAEP key transport for RSA keys, and ephemeral-static ECDH key
+// agreement, as specified in [RFC 5753], for ECDSA keys. [ParseEnvelopedData]
+// parses one, whose content can then be decrypted with
+// [EnvelopedData.Decrypt].
+//
+// Messages are always produced in DER. Parsing also accepts the BER
+// indefinite-length encoding and constructed OCTET STRINGs, which are common
+// in streamed S/MIME messages.
+//
+// [RFC 5652]: https://www.rfc-editor.org/rfc/rfc5652.html
+// [RFC 5753]: https://www.rfc-editor.org/rfc/rfc5753.html
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Content types.
+var (
+	// OIDData is the id-data content type, of arbitrary octet strings.
+	OIDData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidEnvelopedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 3}
+)
+
+// Attribute types.
+var (
+	oidAttributeContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttributeMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttributeSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+)
+
+// Digest algorithms.
+var (
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidSHA224 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 4}
+)
+
+var hashOIDs = []struct {
+	hash crypto.Hash
+	oid  asn1.ObjectIdentifier
+}{
+	{crypto.SHA1, oidSHA1},
+	{crypto.SHA224, oidSHA224},
+	{crypto.SHA256, oidSHA256},
+	{crypto.SHA384, oidSHA384},
+	{crypto.SHA512, oidSHA512},
+}
+
+func hashFromOID(oid asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	for _, h := range hashOIDs {
+		if h.oid.Equal(oid) {
+			return h.hash, h.hash.Available()
+		}
+	}
+	return 0, false
+}
+
+func oidFromHash(hash crypto.Hash) (asn1.ObjectIdentifier, bool) {
+	for _, h := range hashOIDs {
+		if h.hash == hash {
+			return h.oid, true
+		}
+	}
+	return nil, false
+}
+
+// An algorithmIdentifier is an AlgorithmIdentifier, with the DER encoding of
+// its parameters, if present.
+type algorithmIdentifier struct {
+	algorithm  asn1.ObjectIdentifier
+	parameters cryptobyte.String
+}
+
+func readAlgorithmIdentifier(s *cryptobyte.String, ai *algorithmIdentifier) bool {
+	var seq cryptobyte.String
+	if !s.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) ||
+		!seq.ReadASN1ObjectIdentifier(&ai.algorithm) {
+		return false
+	}
+	ai.parameters = seq
+	if len(seq) == 0 {
+		return true
+	}
+	var params cryptobyte.String
+	var tag cryptobyte_asn1.Tag
+	return seq.ReadAnyASN1Element(&params, &tag) && seq.Empty()
+}
+
+// isAbsentOrNULL reports whether the parameters of ai are absent or NULL.
+func (ai *algorithmIdentifier) isAbsentOrNULL() bool {
+	return len(ai.parameters) == 0 || bytes.Equal(ai.parameters, []byte{0x05, 0x00})
+}
+
+func addAlgorithmIdentifier(b *cryptobyte.Builder, oid asn1.ObjectIdentifier, params []byte) {
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(oid)
+		b.AddBytes(params)
+	})
+}
+
+// parseContentInfo parses a BER or DER encoded ContentInfo, checks that its
+// content type is wantType, and returns its content.
+func parseContentInfo(ber []byte, wantType asn1.ObjectIdentifier) (cryptobyte.String, error) {
+	der, err := berToDER(ber)
+	if err != nil {
+		return nil, err
+	}
+	input := cryptobyte.String(der)
+	var contentType asn1.ObjectIdentifier
+	var content cryptobyte.String
+	if !input.ReadASN1(&input, cryptobyte_asn1.SEQUENCE) ||
+		!input.ReadASN1ObjectIdentifier(&contentType) ||
+		!input.ReadASN1(&content, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!input.Empty() {
+		return nil, errors.New("cms: malformed ContentInfo")
+	}
+	if !contentType.Equal(wantType) {
+		return nil, errors.New("cms: unexpected content type " + contentType.String())
+	}
+	return content, nil
+}
+
+func marshalContentInfo(contentType asn1.ObjectIdentifier, content []byte) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1ObjectIdentifier(contentType)
+		b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddBytes(content)
+		})
+	})
+	return b.Bytes()
+}
+
+// An identifier is a SignerIdentifier or RecipientIdentifier, which is
+// either an IssuerAndSerialNumber or a SubjectKeyIdentifier.
+type identifier struct {
+	issuer       []byte // DER encoded Name
+	serialNumber *big.Int
+	subjectKeyID []byte
+}
+
+// readIdentifier reads an identifier, in which the SubjectKeyIdentifier
+// alternative has the given tag.
+func readIdentifier(s *cryptobyte.String, id *identifier, skiTag cryptobyte_asn1.Tag) bool {
+	if s.PeekASN1Tag(skiTag) {
+		return s.ReadASN1Bytes(&id.subjectKeyID, skiTag)
+	}
+	return readIssuerAndSerialNumber(s, id)
+}
+
+func readIssuerAndSerialNumber(s *cryptobyte.String, id *identifier) bool {
+	var ias cryptobyte.String
+	id.serialNumber = new(big.Int)
+	return s.ReadASN1(&ias, cryptobyte_asn1.SEQUENCE) &&
+		ias.ReadASN1Element((*cryptobyte.String)(&id.issuer), cryptobyte_asn1.SEQUENCE) &&
+		ias.ReadASN1Integer(id.serialNumber) &&
+		ias.Empty()
+}
+
+// addIssuerAndSerialNumber adds the IssuerAndSerialNumber of cert.
+func addIssuerAndSerialNumber(b *cryptobyte.Builder, cert *x509.Certificate) {
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddBytes(cert.RawIssuer)
+		b.AddASN1BigInt(cert.SerialNumber)
+	})
+}
+
+// matches reports whether id identifies cert.
+func (id *identifier) matches(cert *x509.Certificate) bool {
+	if id.subjectKeyID != nil {
+		return len(cert.SubjectKeyId) > 0 && bytes.Equal(id.subjectKeyID, cert.SubjectKeyId)
+	}
+	return bytes.Equal(id.issuer, cert.RawIssuer) && id.serialNumber.Cmp(cert.SerialNumber) == 0
+}
+
+// readOctetString reads an OCTET STRING, or an implicitly tagged one with the
+// given tag, which may have been split into chunks by a BER encoder.
+func readOctetString(s *cryptobyte.String, out *[]byte, tag cryptobyte_asn1.Tag) bool {
+	if s.PeekASN1Tag(tag.Constructed()) {
+		var chunks cryptobyte.String
+		if !s.ReadASN1(&chunks, tag.Constructed()) {
+			return false
+		}
+		octets, err := concatOctetStrings(chunks)
+		if err != nil {
+			return false
+		}
+		*out = octets
+		return true
+	}
+	return s.ReadASN1Bytes(out, tag)
+}
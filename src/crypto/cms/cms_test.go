@@ -0,0 +1,261 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+// The files in testdata were generated with OpenSSL 3.0:
+//
+//	openssl req -x509 -new -key ca.key -subj '/CN=CMS Test CA' -days 36500 -out ca.pem
+//	openssl x509 -req -in rsa.csr -CA ca.pem -CAkey ca.key -days 36500 -extfile exts -out rsa.pem
+//	openssl cms -sign -binary -nodetach -in msg.txt -signer rsa.pem -inkey rsa.key -outform DER -out signed_rsa.der
+//	openssl cms -sign -binary -nodetach -stream -in msg.txt -signer rsa.pem -inkey rsa.key -outform DER -out signed_rsa_ber.der
+//	openssl cms -sign -binary -nodetach -in msg.txt -signer rsa.pem -inkey rsa.key -keyopt rsa_padding_mode:pss -keyopt rsa_pss_saltlen:digest -outform DER -out signed_pss.der
+//	openssl cms -sign -binary -in msg.txt -signer ec.pem -inkey ec.key -outform DER -out detached_ec.der
+//	openssl cms -sign -binary -nodetach -noattr -in msg.txt -signer ec.pem -inkey ec.key -md sha384 -outform DER -out noattr_ec.der
+//	openssl cms -encrypt -binary -in msg.txt -recip rsa.pem -keyopt rsa_padding_mode:oaep -keyopt rsa_oaep_md:sha256 -aes256 -outform DER -out env_rsa.der
+//	openssl cms -encrypt -binary -stream -in msg.txt -recip rsa.pem -keyopt rsa_padding_mode:oaep -keyopt rsa_oaep_md:sha256 -aes128 -outform DER -out env_rsa_ber.der
+//	openssl cms -encrypt -binary -in msg.txt -recip ec.pem -aes256 -outform DER -out env_ec.der
+//	openssl cms -encrypt -binary -in msg.txt -recip rsa.pem -keyopt rsa_padding_mode:oaep -recip ec.pem -aes192 -outform DER -out env_multi.der
+//
+// ca.key is a P-256 key, rsa.key an RSA 2048-bit key, and ec.key a P-384 key.
+// The leaf certificates have the emailProtection extended key usage.
+
+// testTime is within the validity period of the certificates in testdata.
+var testTime = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func readTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func readTestCertificate(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(readTestFile(t, name+".pem"))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func readTestKey(t *testing.T, name string) crypto.Signer {
+	t.Helper()
+	block, _ := pem.Decode(readTestFile(t, name+".key"))
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(crypto.Signer)
+}
+
+func testVerifyOptions(t *testing.T) x509.VerifyOptions {
+	roots := x509.NewCertPool()
+	roots.AddCert(readTestCertificate(t, "ca"))
+	return x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: testTime,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+	}
+}
+
+// testChain is a root, an intermediate, and a leaf certificate.
+type testChain struct {
+	root, intermediate, leaf *x509.Certificate
+}
+
+func newTestChain(t *testing.T, leafKey crypto.Signer) *testChain {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(cn string, isCA bool, pub crypto.PublicKey, parent *x509.Certificate, key crypto.Signer) *x509.Certificate {
+		t.Helper()
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: cn},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			BasicConstraintsValid: true,
+			IsCA:                  isCA,
+		}
+		if isCA {
+			template.KeyUsage = x509.KeyUsageCertSign
+		} else {
+			template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageKeyAgreement
+			template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection}
+		}
+		if parent == nil {
+			parent = template
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	c := &testChain{}
+	c.root = create("root", true, rootKey.Public(), nil, rootKey)
+	c.intermediate = create("intermediate", true, intKey.Public(), c.root, rootKey)
+	c.leaf = create("leaf", false, leafKey.Public(), c.intermediate, intKey)
+	return c
+}
+
+func (c *testChain) verifyOptions() x509.VerifyOptions {
+	roots := x509.NewCertPool()
+	roots.AddCert(c.root)
+	return x509.VerifyOptions{Roots: roots}
+}
+
+var testKeys = map[string]func() (crypto.Signer, error){
+	"RSA": func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+	"P256": func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	},
+	"P384": func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	},
+	"P521": func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	},
+	"Ed25519": func() (crypto.Signer, error) {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	},
+}
+
+func TestBERToDER(t *testing.T) {
+	tests := []struct {
+		name, ber, der string
+	}{
+		{"DER", "300302010a", "300302010a"},
+		{"indefinite", "30800201010000", "3003020101"},
+		{"nested indefinite", "3080308002010100000000", "30053003020101"},
+		{"constructed OCTET STRING", "2480040261620401630000", "0403616263"},
+		{"definite constructed OCTET STRING", "240704026162040163", "0403616263"},
+		{"tagged indefinite", "a0800401610000", "a003040161"},
+		{"long form length", "0481026162", "04026162"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ber, _ := hex.DecodeString(tt.ber)
+			der, err := berToDER(ber)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(der); got != tt.der {
+				t.Errorf("got %s, want %s", got, tt.der)
+			}
+		})
+	}
+
+	for _, ber := range []string{
+		"",
+		"30",
+		"3005020101",         // truncated content
+		"3080020101",         // missing end-of-contents
+		"0480610000",         // indefinite primitive
+		"300302010a00",       // trailing data
+		"2403020161",         // constructed OCTET STRING with non-OCTET STRING chunk
+		"3085010000000002",   // length too long
+		"1f8080808080010100", // tag number too long
+	} {
+		b, _ := hex.DecodeString(ber)
+		if _, err := berToDER(b); err == nil {
+			t.Errorf("berToDER(%s) succeeded, want error", ber)
+		}
+	}
+
+	deep := bytes.Repeat([]byte{0x30, 0x80}, maxBERDepth+2)
+	deep = append(deep, bytes.Repeat([]byte{0, 0}, maxBERDepth+2)...)
+	if _, err := berToDER(deep); err == nil {
+		t.Error("berToDER succeeded on deeply nested input")
+	}
+}
+
+func TestAESKeyWrap(t *testing.T) {
+	// Test vectors from RFC 3394, Section 4.
+	tests := []struct {
+		kek, key, wrapped string
+	}{
+		{
+			"000102030405060708090A0B0C0D0E0F",
+			"00112233445566778899AABBCCDDEEFF",
+			"1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+		},
+		{
+			"000102030405060708090A0B0C0D0E0F1011121314151617",
+			"00112233445566778899AABBCCDDEEFF",
+			"96778B25AE6CA435F92B5B97C050AED2468AB8A17AD84E5D",
+		},
+		{
+			"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			"00112233445566778899AABBCCDDEEFF",
+			"64E8C3F9CE0F5BA263E9777905818A2A93C8191E7D6E8AE7",
+		},
+		{
+			"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			"00112233445566778899AABBCCDDEEFF0001020304050607",
+			"A8F9BC1612C68B3FF6E6F4FBE30E71E4769C8B80A32CB8958CD5D17D6B254DA1",
+		},
+		{
+			"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			"00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+			"28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+		},
+	}
+	for _, tt := range tests {
+		kek, _ := hex.DecodeString(tt.kek)
+		key, _ := hex.DecodeString(tt.key)
+		want, _ := hex.DecodeString(tt.wrapped)
+		wrapped, err := aesKeyWrap(kek, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(wrapped, want) {
+			t.Errorf("aesKeyWrap = %X, want %X", wrapped, want)
+		}
+		unwrapped, err := aesKeyUnwrap(kek, wrapped)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(unwrapped, key) {
+			t.Errorf("aesKeyUnwrap = %X, want %X", unwrapped, key)
+		}
+		wrapped[len(wrapped)-1] ^= 1
+		if _, err := aesKeyUnwrap(kek, wrapped); err != errKeyUnwrap {
+			t.Errorf("aesKeyUnwrap of corrupted input: got %v, want %v", err, errKeyUnwrap)
+		}
+	}
+}
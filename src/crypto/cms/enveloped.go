@@ -0,0 +1,658 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"internal/byteorder"
+	"io"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Content encryption and key management algorithms.
+var (
+	oidAES128CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+
+	oidAES128Wrap = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 5}
+	oidAES192Wrap = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 25}
+	oidAES256Wrap = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 45}
+
+	oidRSAESOAEP  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 7}
+	oidPSpecified = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 9}
+
+	oidDHSinglePassSHA1KDF   = asn1.ObjectIdentifier{1, 3, 133, 16, 840, 63, 0, 2}
+	oidDHSinglePassSHA224KDF = asn1.ObjectIdentifier{1, 3, 132, 1, 11, 0}
+	oidDHSinglePassSHA256KDF = asn1.ObjectIdentifier{1, 3, 132, 1, 11, 1}
+	oidDHSinglePassSHA384KDF = asn1.ObjectIdentifier{1, 3, 132, 1, 11, 2}
+	oidDHSinglePassSHA512KDF = asn1.ObjectIdentifier{1, 3, 132, 1, 11, 3}
+)
+
+// contentKeySize returns the key size of an AES-CBC algorithm, or zero.
+func contentKeySize(oid asn1.ObjectIdentifier) int {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return 16
+	case oid.Equal(oidAES192CBC):
+		return 24
+	case oid.Equal(oidAES256CBC):
+		return 32
+	}
+	return 0
+}
+
+// wrapKeySize returns the key size of an AES key wrap algorithm, or zero.
+func wrapKeySize(oid asn1.ObjectIdentifier) int {
+	switch {
+	case oid.Equal(oidAES128Wrap):
+		return 16
+	case oid.Equal(oidAES192Wrap):
+		return 24
+	case oid.Equal(oidAES256Wrap):
+		return 32
+	}
+	return 0
+}
+
+var ecdhKDFs = []struct {
+	hash crypto.Hash
+	oid  asn1.ObjectIdentifier
+}{
+	{crypto.SHA1, oidDHSinglePassSHA1KDF},
+	{crypto.SHA224, oidDHSinglePassSHA224KDF},
+	{crypto.SHA256, oidDHSinglePassSHA256KDF},
+	{crypto.SHA384, oidDHSinglePassSHA384KDF},
+	{crypto.SHA512, oidDHSinglePassSHA512KDF},
+}
+
+// EnvelopedData is a parsed CMS EnvelopedData. See RFC 5652, Section 6.
+type EnvelopedData struct {
+	// ContentType is the type of the encrypted content, usually [OIDData].
+	ContentType asn1.ObjectIdentifier
+
+	recipients          []recipientInfo
+	contentEncryption   algorithmIdentifier
+	encryptedContent    []byte
+	hasEncryptedContent bool
+}
+
+// A recipientInfo is a KeyTransRecipientInfo, or one of the recipients of a
+// KeyAgreeRecipientInfo.
+type recipientInfo struct {
+	id            identifier
+	keyEncryption algorithmIdentifier
+	encryptedKey  []byte
+
+	// keyAgreement is true for a KeyAgreeRecipientInfo with an ephemeral
+	// originator key, for which originatorKey and ukm are set.
+	keyAgreement  bool
+	originatorKey algorithmIdentifier
+	originatorPub []byte
+	ukm           []byte
+}
+
+// ParseEnvelopedData parses a ContentInfo containing an EnvelopedData, in BER
+// or DER.
+func ParseEnvelopedData(ber []byte) (*EnvelopedData, error) {
+	input, err := parseContentInfo(ber, oidEnvelopedData)
+	if err != nil {
+		return nil, err
+	}
+	ed := &EnvelopedData{}
+
+	var version int
+	var recipientInfos, eci cryptobyte.String
+	if !input.ReadASN1(&input, cryptobyte_asn1.SEQUENCE) ||
+		!input.ReadASN1Integer(&version) ||
+		!input.SkipOptionalASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!input.ReadASN1(&recipientInfos, cryptobyte_asn1.SET) ||
+		!input.ReadASN1(&eci, cryptobyte_asn1.SEQUENCE) ||
+		!input.SkipOptionalASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!input.Empty() {
+		return nil, errors.New("cms: malformed EnvelopedData")
+	}
+	if version < 0 || version > 4 {
+		return nil, errors.New("cms: unsupported EnvelopedData version")
+	}
+
+	encryptedContentTag := cryptobyte_asn1.Tag(0).ContextSpecific()
+	if !eci.ReadASN1ObjectIdentifier(&ed.ContentType) ||
+		!readAlgorithmIdentifier(&eci, &ed.contentEncryption) {
+		return nil, errors.New("cms: malformed EncryptedContentInfo")
+	}
+	if eci.PeekASN1Tag(encryptedContentTag) || eci.PeekASN1Tag(encryptedContentTag.Constructed()) {
+		if !readOctetString(&eci, &ed.encryptedContent, encryptedContentTag) {
+			return nil, errors.New("cms: malformed EncryptedContentInfo")
+		}
+		ed.hasEncryptedContent = true
+	}
+	if !eci.Empty() {
+		return nil, errors.New("cms: malformed EncryptedContentInfo")
+	}
+
+	for !recipientInfos.Empty() {
+		var ri cryptobyte.String
+		var tag cryptobyte_asn1.Tag
+		if !recipientInfos.ReadAnyASN1(&ri, &tag) {
+			return nil, errors.New("cms: malformed RecipientInfo")
+		}
+		switch tag {
+		case cryptobyte_asn1.SEQUENCE:
+			r, err := parseKeyTransRecipientInfo(ri)
+			if err != nil {
+				return nil, err
+			}
+			ed.recipients = append(ed.recipients, r)
+		case cryptobyte_asn1.Tag(1).Constructed().ContextSpecific():
+			rs, err := parseKeyAgreeRecipientInfo(ri)
+			if err != nil {
+				return nil, err
+			}
+			ed.recipients = append(ed.recipients, rs...)
+		default:
+			// KEKRecipientInfo, PasswordRecipientInfo and
+			// OtherRecipientInfo are not supported.
+		}
+	}
+	return ed, nil
+}
+
+func parseKeyTransRecipientInfo(input cryptobyte.String) (recipientInfo, error) {
+	var r recipientInfo
+	var version int
+	if !input.ReadASN1Integer(&version) ||
+		!readIdentifier(&input, &r.id, cryptobyte_asn1.Tag(0).ContextSpecific()) ||
+		!readAlgorithmIdentifier(&input, &r.keyEncryption) ||
+		!input.ReadASN1Bytes(&r.encryptedKey, cryptobyte_asn1.OCTET_STRING) ||
+		!input.Empty() {
+		return r, errors.New("cms: malformed KeyTransRecipientInfo")
+	}
+	return r, nil
+}
+
+func parseKeyAgreeRecipientInfo(input cryptobyte.String) ([]recipientInfo, error) {
+	var version int
+	var originator, ukm, keys cryptobyte.String
+	var hasUKM bool
+	var keyEncryption algorithmIdentifier
+	if !input.ReadASN1Integer(&version) ||
+		!input.ReadASN1(&originator, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!input.ReadOptionalASN1(&ukm, &hasUKM, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!readAlgorithmIdentifier(&input, &keyEncryption) ||
+		!input.ReadASN1(&keys, cryptobyte_asn1.SEQUENCE) ||
+		!input.Empty() {
+		return nil, errors.New("cms: malformed KeyAgreeRecipientInfo")
+	}
+	if !originator.PeekASN1Tag(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) {
+		// Static-static key agreement is not supported.
+		return nil, nil
+	}
+	template := recipientInfo{keyEncryption: keyEncryption, keyAgreement: true}
+	var originatorKey cryptobyte.String
+	if !originator.ReadASN1(&originatorKey, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!originator.Empty() ||
+		!readAlgorithmIdentifier(&originatorKey, &template.originatorKey) ||
+		!originatorKey.ReadASN1BitStringAsBytes(&template.originatorPub) ||
+		!originatorKey.Empty() {
+		return nil, errors.New("cms: malformed KeyAgreeRecipientInfo originator")
+	}
+	if hasUKM && (!ukm.ReadASN1Bytes(&template.ukm, cryptobyte_asn1.OCTET_STRING) || !ukm.Empty()) {
+		return nil, errors.New("cms: malformed KeyAgreeRecipientInfo")
+	}
+
+	var recipients []recipientInfo
+	for !keys.Empty() {
+		r := template
+		var rek cryptobyte.String
+		if !keys.ReadASN1(&rek, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("cms: malformed RecipientEncryptedKey")
+		}
+		rKeyIDTag := cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()
+		if rek.PeekASN1Tag(rKeyIDTag) {
+			var rKeyID cryptobyte.String
+			if !rek.ReadASN1(&rKeyID, rKeyIDTag) ||
+				!rKeyID.ReadASN1Bytes(&r.id.subjectKeyID, cryptobyte_asn1.OCTET_STRING) {
+				return nil, errors.New("cms: malformed RecipientEncryptedKey")
+			}
+		} else if !readIssuerAndSerialNumber(&rek, &r.id) {
+			return nil, errors.New("cms: malformed RecipientEncryptedKey")
+		}
+		if !rek.ReadASN1Bytes(&r.encryptedKey, cryptobyte_asn1.OCTET_STRING) || !rek.Empty() {
+			return nil, errors.New("cms: malformed RecipientEncryptedKey")
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+// Decrypt decrypts the content of ed for the recipient with the given
+// certificate and private key.
+//
+// For RSA keys, key must implement [crypto.Decrypter], and the content key
+// must be encrypted with RSAES-OAEP. The PKCS #1 v1.5 encryption scheme is not
+// supported. For ECDH key agreement, key must be an [*ecdsa.PrivateKey] or an
+// [*ecdh.PrivateKey].
+func (ed *EnvelopedData) Decrypt(cert *x509.Certificate, key crypto.PrivateKey) ([]byte, error) {
+	if !ed.hasEncryptedContent {
+		return nil, errors.New("cms: EnvelopedData has detached content")
+	}
+	var err error = errors.New("cms: no recipient matches the certificate")
+	for _, r := range ed.recipients {
+		if !r.id.matches(cert) {
+			continue
+		}
+		var cek []byte
+		cek, err = r.decryptKey(key)
+		if err != nil {
+			continue
+		}
+		return decryptContent(&ed.contentEncryption, cek, ed.encryptedContent)
+	}
+	return nil, err
+}
+
+func (r *recipientInfo) decryptKey(key crypto.PrivateKey) ([]byte, error) {
+	if r.keyAgreement {
+		return r.agreeKey(key)
+	}
+	if !r.keyEncryption.algorithm.Equal(oidRSAESOAEP) {
+		return nil, errors.New("cms: unsupported key encryption algorithm " + r.keyEncryption.algorithm.String())
+	}
+	decrypter, ok := key.(crypto.Decrypter)
+	if !ok {
+		return nil, errors.New("cms: RSA key does not implement crypto.Decrypter")
+	}
+	if _, ok := decrypter.Public().(*rsa.PublicKey); !ok {
+		return nil, errors.New("cms: key does not match the key encryption algorithm")
+	}
+	opts, err := parseOAEPParameters(r.keyEncryption.parameters)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := decrypter.Decrypt(rand.Reader, r.encryptedKey, opts)
+	if err != nil {
+		return nil, errors.New("cms: decryption failed")
+	}
+	return cek, nil
+}
+
+// parseOAEPParameters parses RSAES-OAEP-params.
+func parseOAEPParameters(params cryptobyte.String) (*rsa.OAEPOptions, error) {
+	opts := &rsa.OAEPOptions{Hash: crypto.SHA1, MGFHash: crypto.SHA1}
+	if len(params) == 0 {
+		return opts, nil
+	}
+	var seq, hashField, mgfField, pSourceField cryptobyte.String
+	var hasHash, hasMGF, hasPSource bool
+	if !params.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) || !params.Empty() ||
+		!seq.ReadOptionalASN1(&hashField, &hasHash, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!seq.ReadOptionalASN1(&mgfField, &hasMGF, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!seq.ReadOptionalASN1(&pSourceField, &hasPSource, cryptobyte_asn1.Tag(2).Constructed().ContextSpecific()) ||
+		!seq.Empty() {
+		return nil, errors.New("cms: malformed RSAES-OAEP parameters")
+	}
+	if hasHash {
+		var ai algorithmIdentifier
+		h, ok := crypto.Hash(0), false
+		if readAlgorithmIdentifier(&hashField, &ai) && hashField.Empty() {
+			h, ok = hashFromOID(ai.algorithm)
+		}
+		if !ok {
+			return nil, errors.New("cms: unsupported RSAES-OAEP hash function")
+		}
+		opts.Hash = h
+	}
+	if hasMGF {
+		var ai, hashAI algorithmIdentifier
+		h, ok := crypto.Hash(0), false
+		if readAlgorithmIdentifier(&mgfField, &ai) && mgfField.Empty() && ai.algorithm.Equal(oidMGF1) &&
+			readAlgorithmIdentifier(&ai.parameters, &hashAI) {
+			h, ok = hashFromOID(hashAI.algorithm)
+		}
+		if !ok {
+			return nil, errors.New("cms: unsupported RSAES-OAEP mask generation function")
+		}
+		opts.MGFHash = h
+	}
+	if hasPSource {
+		var ai algorithmIdentifier
+		if !readAlgorithmIdentifier(&pSourceField, &ai) || !pSourceField.Empty() ||
+			!ai.algorithm.Equal(oidPSpecified) ||
+			!ai.parameters.ReadASN1Bytes(&opts.Label, cryptobyte_asn1.OCTET_STRING) {
+			return nil, errors.New("cms: unsupported RSAES-OAEP label source")
+		}
+	}
+	return opts, nil
+}
+
+// agreeKey unwraps the content key of a key agreement recipient.
+func (r *recipientInfo) agreeKey(key crypto.PrivateKey) ([]byte, error) {
+	var priv *ecdh.PrivateKey
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		var err error
+		if priv, err = k.ECDH(); err != nil {
+			return nil, err
+		}
+	case *ecdh.PrivateKey:
+		priv = k
+	default:
+		return nil, errors.New("cms: key does not match the key agreement algorithm")
+	}
+	if !r.originatorKey.algorithm.Equal(oidECPublicKey) || priv.Curve() == ecdh.X25519() {
+		return nil, errors.New("cms: unsupported originator key algorithm")
+	}
+	pub, err := priv.Curve().NewPublicKey(r.originatorPub)
+	if err != nil {
+		return nil, errors.New("cms: invalid originator public key")
+	}
+
+	var hash crypto.Hash
+	for _, kdf := range ecdhKDFs {
+		if kdf.oid.Equal(r.keyEncryption.algorithm) {
+			hash = kdf.hash
+		}
+	}
+	if hash == 0 {
+		return nil, errors.New("cms: unsupported key agreement algorithm " + r.keyEncryption.algorithm.String())
+	}
+	var wrap algorithmIdentifier
+	keyInfo := r.keyEncryption.parameters
+	params := keyInfo
+	if !readAlgorithmIdentifier(&params, &wrap) || !params.Empty() || !wrap.isAbsentOrNULL() {
+		return nil, errors.New("cms: malformed key wrap algorithm")
+	}
+	kekSize := wrapKeySize(wrap.algorithm)
+	if kekSize == 0 {
+		return nil, errors.New("cms: unsupported key wrap algorithm " + wrap.algorithm.String())
+	}
+
+	z, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	kek, err := deriveKEK(hash, z, keyInfo, r.ukm, kekSize)
+	if err != nil {
+		return nil, err
+	}
+	return aesKeyUnwrap(kek, r.encryptedKey)
+}
+
+// deriveKEK derives a key-encryption key from the shared secret z with the
+// ANSI X9.63 KDF, using as SharedInfo the DER encoding of the ECC-CMS-SharedInfo
+// for the key wrap algorithm keyInfo. See RFC 5753, Section 7.2.
+func deriveKEK(hash crypto.Hash, z, keyInfo, ukm []byte, size int) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddBytes(keyInfo)
+		if ukm != nil {
+			b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+				b.AddASN1OctetString(ukm)
+			})
+		}
+		b.AddASN1(cryptobyte_asn1.Tag(2).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1OctetString(byteorder.BEAppendUint32(nil, uint32(size*8)))
+		})
+	})
+	sharedInfo, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var kek []byte
+	for counter := uint32(1); len(kek) < size; counter++ {
+		h := hash.New()
+		h.Write(z)
+		h.Write(byteorder.BEAppendUint32(nil, counter))
+		h.Write(sharedInfo)
+		kek = h.Sum(kek)
+	}
+	return kek[:size], nil
+}
+
+var errDecryption = errors.New("cms: decryption failed")
+
+// decryptContent decrypts ciphertext with cek, using the AES-CBC algorithm
+// identified by ai, and removes the PKCS #7 padding.
+func decryptContent(ai *algorithmIdentifier, cek, ciphertext []byte) ([]byte, error) {
+	size := contentKeySize(ai.algorithm)
+	if size == 0 {
+		return nil, errors.New("cms: unsupported content encryption algorithm " + ai.algorithm.String())
+	}
+	var iv []byte
+	params := ai.parameters
+	if !params.ReadASN1Bytes(&iv, cryptobyte_asn1.OCTET_STRING) || !params.Empty() || len(iv) != aes.BlockSize {
+		return nil, errors.New("cms: malformed content encryption parameters")
+	}
+	if len(cek) != size || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errDecryption
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	good := subtle.ConstantTimeLessOrEq(1, padding) & subtle.ConstantTimeLessOrEq(padding, aes.BlockSize)
+	for i := 1; i <= aes.BlockSize; i++ {
+		inPadding := subtle.ConstantTimeLessOrEq(i, padding)
+		match := subtle.ConstantTimeByteEq(plaintext[len(plaintext)-i], byte(padding))
+		good &= subtle.ConstantTimeSelect(inPadding, match, 1)
+	}
+	if good != 1 {
+		return nil, errDecryption
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
+
+// EncryptOptions are the options of [Encrypt].
+type EncryptOptions struct {
+	// ContentType is the type of the content. If nil, [OIDData] is used.
+	ContentType asn1.ObjectIdentifier
+
+	// KeySize is the size in bytes of the AES-CBC content encryption key,
+	// which must be 16, 24, or 32. If zero, 32 is used.
+	KeySize int
+}
+
+// Encrypt returns a ContentInfo in DER containing an EnvelopedData with
+// content encrypted for recipients, using rand as a source of entropy. opts
+// may be nil.
+//
+// The content is encrypted with AES-CBC, with a random key which is
+// encrypted with RSAES-OAEP with SHA-256 for RSA recipients, and wrapped with
+// a key agreed with ephemeral-static ECDH for ECDSA recipients on the P-256,
+// P-384, and P-521 curves, which uses the ANSI X9.63 KDF with SHA-256,
+// SHA-384, or SHA-512 respectively, and AES key wrap.
+func Encrypt(rand io.Reader, content []byte, recipients []*x509.Certificate, opts *EncryptOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &EncryptOptions{}
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("cms: no recipients")
+	}
+	contentType := opts.ContentType
+	if contentType == nil {
+		contentType = OIDData
+	}
+	var contentOID, wrapOID asn1.ObjectIdentifier
+	switch opts.KeySize {
+	case 16:
+		contentOID, wrapOID = oidAES128CBC, oidAES128Wrap
+	case 24:
+		contentOID, wrapOID = oidAES192CBC, oidAES192Wrap
+	case 0, 32:
+		contentOID, wrapOID = oidAES256CBC, oidAES256Wrap
+	default:
+		return nil, errors.New("cms: invalid content encryption key size")
+	}
+	cek := make([]byte, contentKeySize(contentOID))
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand, cek); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand, iv); err != nil {
+		return nil, err
+	}
+
+	var recipientInfos [][]byte
+	version := int64(0)
+	for _, cert := range recipients {
+		var ri []byte
+		var err error
+		switch pub := cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			ri, err = marshalKeyTransRecipientInfo(rand, cert, pub, cek)
+		case *ecdsa.PublicKey:
+			ri, err = marshalKeyAgreeRecipientInfo(rand, cert, pub, cek, wrapOID)
+			version = 2
+		default:
+			err = errors.New("cms: unsupported recipient key type")
+		}
+		if err != nil {
+			return nil, err
+		}
+		recipientInfos = append(recipientInfos, ri)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(content)%aes.BlockSize
+	ciphertext := make([]byte, len(content)+padding)
+	copy(ciphertext, content)
+	for i := len(content); i < len(ciphertext); i++ {
+		ciphertext[i] = byte(padding)
+	}
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(version)
+		b.AddASN1(cryptobyte_asn1.SET, func(b *cryptobyte.Builder) {
+			for _, ri := range recipientInfos {
+				b.AddBytes(ri)
+			}
+		})
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(contentType)
+			b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1ObjectIdentifier(contentOID)
+				b.AddASN1OctetString(iv)
+			})
+			b.AddASN1(cryptobyte_asn1.Tag(0).ContextSpecific(), func(b *cryptobyte.Builder) {
+				b.AddBytes(ciphertext)
+			})
+		})
+	})
+	envelopedData, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return marshalContentInfo(oidEnvelopedData, envelopedData)
+}
+
+func marshalKeyTransRecipientInfo(rand io.Reader, cert *x509.Certificate, pub *rsa.PublicKey, cek []byte) ([]byte, error) {
+	encryptedKey, err := rsa.EncryptOAEP(crypto.SHA256.New(), rand, pub, cek, nil)
+	if err != nil {
+		return nil, err
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(0)
+		addIssuerAndSerialNumber(b, cert)
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(oidRSAESOAEP)
+			b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+					addAlgorithmIdentifier(b, oidSHA256, nil)
+				})
+				b.AddASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+					b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+						b.AddASN1ObjectIdentifier(oidMGF1)
+						addAlgorithmIdentifier(b, oidSHA256, nil)
+					})
+				})
+			})
+		})
+		b.AddASN1OctetString(encryptedKey)
+	})
+	return b.Bytes()
+}
+
+func marshalKeyAgreeRecipientInfo(rand io.Reader, cert *x509.Certificate, pub *ecdsa.PublicKey, cek []byte, wrapOID asn1.ObjectIdentifier) ([]byte, error) {
+	recipientKey, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	var kdfOID asn1.ObjectIdentifier
+	var hash crypto.Hash
+	switch recipientKey.Curve() {
+	case ecdh.P256():
+		kdfOID, hash = oidDHSinglePassSHA256KDF, crypto.SHA256
+	case ecdh.P384():
+		kdfOID, hash = oidDHSinglePassSHA384KDF, crypto.SHA384
+	case ecdh.P521():
+		kdfOID, hash = oidDHSinglePassSHA512KDF, crypto.SHA512
+	default:
+		return nil, errors.New("cms: unsupported elliptic curve")
+	}
+	ephemeral, err := recipientKey.Curve().GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	z, err := ephemeral.ECDH(recipientKey)
+	if err != nil {
+		return nil, err
+	}
+	var wrapAI cryptobyte.Builder
+	addAlgorithmIdentifier(&wrapAI, wrapOID, nil)
+	keyInfo := wrapAI.BytesOrPanic()
+	kek, err := deriveKEK(hash, z, keyInfo, nil, wrapKeySize(wrapOID))
+	if err != nil {
+		return nil, err
+	}
+	encryptedKey, err := aesKeyWrap(kek, cek)
+	if err != nil {
+		return nil, err
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(3)
+		b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+				addAlgorithmIdentifier(b, oidECPublicKey, nil)
+				b.AddASN1BitString(ephemeral.PublicKey().Bytes())
+			})
+		})
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(kdfOID)
+			b.AddBytes(keyInfo)
+		})
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				addIssuerAndSerialNumber(b, cert)
+				b.AddASN1OctetString(encryptedKey)
+			})
+		})
+	})
+	return b.Bytes()
+}
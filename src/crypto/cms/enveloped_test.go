@@ -0,0 +1,164 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+)
+
+func TestDecryptOpenSSL(t *testing.T) {
+	tests := []struct {
+		file       string
+		recipients []string
+	}{
+		{"env_rsa.der", []string{"rsa"}},
+		{"env_rsa_ber.der", []string{"rsa"}},
+		{"env_ec.der", []string{"ec"}},
+		{"env_multi.der", []string{"rsa", "ec"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			ed, err := ParseEnvelopedData(readTestFile(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ed.ContentType.Equal(OIDData) {
+				t.Errorf("ContentType = %v, want %v", ed.ContentType, OIDData)
+			}
+			for _, name := range []string{"rsa", "ec"} {
+				isRecipient := false
+				for _, r := range tt.recipients {
+					isRecipient = isRecipient || r == name
+				}
+				plaintext, err := ed.Decrypt(readTestCertificate(t, name), readTestKey(t, name))
+				if !isRecipient {
+					if err == nil {
+						t.Errorf("%s: Decrypt succeeded for a non-recipient", name)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if !bytes.Equal(plaintext, testMessage) {
+					t.Errorf("%s: got %q, want %q", name, plaintext, testMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	var certs []*x509.Certificate
+	var keys []crypto.Signer
+	for _, name := range []string{"RSA", "P256", "P384", "P521"} {
+		key, err := testKeys[name]()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+		certs = append(certs, newTestChain(t, key).leaf)
+	}
+	for _, keySize := range []int{0, 16, 24, 32} {
+		for _, content := range [][]byte{testMessage, {}, bytes.Repeat([]byte("A"), 64)} {
+			der, err := Encrypt(rand.Reader, content, certs, &EncryptOptions{KeySize: keySize})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ed, err := ParseEnvelopedData(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, cert := range certs {
+				plaintext, err := ed.Decrypt(cert, keys[i])
+				if err != nil {
+					t.Fatalf("key size %d, recipient %d: %v", keySize, i, err)
+				}
+				if !bytes.Equal(plaintext, content) {
+					t.Errorf("key size %d, recipient %d: got %q, want %q", keySize, i, plaintext, content)
+				}
+			}
+		}
+	}
+
+	// An *ecdh.PrivateKey is accepted too.
+	der, err := Encrypt(rand.Reader, testMessage, certs[1:2], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, err := ParseEnvelopedData(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdhKey, err := keys[1].(*ecdsa.PrivateKey).ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := ed.Decrypt(certs[1], ecdhKey); err != nil || !bytes.Equal(plaintext, testMessage) {
+		t.Errorf("Decrypt with *ecdh.PrivateKey = %q, %v", plaintext, err)
+	}
+}
+
+func TestEncryptErrors(t *testing.T) {
+	key, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestChain(t, key).leaf
+	if _, err := Encrypt(rand.Reader, testMessage, nil, nil); err == nil {
+		t.Error("Encrypt succeeded without recipients")
+	}
+	if _, err := Encrypt(rand.Reader, testMessage, []*x509.Certificate{cert}, &EncryptOptions{KeySize: 8}); err == nil {
+		t.Error("Encrypt succeeded with an invalid key size")
+	}
+	edKey, err := testKeys["Ed25519"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Encrypt(rand.Reader, testMessage, []*x509.Certificate{newTestChain(t, edKey).leaf}, nil); err == nil {
+		t.Error("Encrypt succeeded for an Ed25519 recipient")
+	}
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	key, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestChain(t, key).leaf
+	other, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := Encrypt(rand.Reader, testMessage, []*x509.Certificate{cert}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, err := ParseEnvelopedData(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ed.Decrypt(cert, other); err == nil {
+		t.Error("Decrypt succeeded with the wrong key")
+	}
+	if _, err := ed.Decrypt(readTestCertificate(t, "ec"), readTestKey(t, "ec")); err == nil {
+		t.Error("Decrypt succeeded for a non-recipient")
+	}
+
+	tampered := bytes.Clone(der)
+	tampered[len(tampered)-1] ^= 1
+	ed, err = ParseEnvelopedData(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := ed.Decrypt(cert, key); err == nil && bytes.Equal(plaintext, testMessage) {
+		t.Error("Decrypt of tampered ciphertext returned the original content")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms_test
+
+import (
+	"crypto/cms"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+func ExampleParseSignedData() {
+	signed, err := os.ReadFile("testdata/detached_ec.der")
+	if err != nil {
+		log.Fatal(err)
+	}
+	content, err := os.ReadFile("testdata/msg.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	caPEM, err := os.ReadFile("testdata/ca.pem")
+	if err != nil {
+		log.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		log.Fatal("failed to parse root certificate")
+	}
+
+	sd, err := cms.ParseSignedData(signed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = sd.VerifyDetached(content, x509.VerifyOptions{
+		Roots:       roots,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+		CurrentTime: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("signed by", sd.Signers[0].Certificate.Subject.CommonName)
+	// Output: signed by ec@example.com
+}
+
+func ExampleEnvelopedData_Decrypt() {
+	enveloped, err := os.ReadFile("testdata/env_ec.der")
+	if err != nil {
+		log.Fatal(err)
+	}
+	certPEM, err := os.ReadFile("testdata/ec.pem")
+	if err != nil {
+		log.Fatal(err)
+	}
+	keyPEM, err := os.ReadFile("testdata/ec.key")
+	if err != nil {
+		log.Fatal(err)
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	block, _ = pem.Decode(keyPEM)
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ed, err := cms.ParseEnvelopedData(enveloped)
+	if err != nil {
+		log.Fatal(err)
+	}
+	content, err := ed.Decrypt(cert, key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s", content)
+	// Output: Hello, CMS!
+}
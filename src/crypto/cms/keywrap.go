@@ -0,0 +1,77 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"errors"
+	"internal/byteorder"
+)
+
+// The AES Key Wrap algorithm is specified in RFC 3394.
+
+var keyWrapIV = [8]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// aesKeyWrap wraps key, whose length must be a multiple of 8 and at least 16
+// bytes, with kek.
+func aesKeyWrap(kek, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, errors.New("cms: invalid key length for AES key wrap")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(key) / 8
+	out := make([]byte, 8+len(key))
+	copy(out, keyWrapIV[:])
+	copy(out[8:], key)
+	var b [16]byte
+	for j := range 6 {
+		for i := 1; i <= n; i++ {
+			copy(b[:8], out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			block.Encrypt(b[:], b[:])
+			t := uint64(n*j + i)
+			byteorder.BEPutUint64(out[:8], byteorder.BEUint64(b[:8])^t)
+			copy(out[8*i:], b[8:])
+		}
+	}
+	return out, nil
+}
+
+var errKeyUnwrap = errors.New("cms: key unwrap failed")
+
+// aesKeyUnwrap unwraps wrapped with kek.
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errKeyUnwrap
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	var a [8]byte
+	copy(a[:], wrapped[:8])
+	out := make([]byte, len(wrapped)-8)
+	copy(out, wrapped[8:])
+	var b [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			byteorder.BEPutUint64(b[:8], byteorder.BEUint64(a[:])^t)
+			copy(b[8:], out[8*(i-1):8*i])
+			block.Decrypt(b[:], b[:])
+			copy(a[:], b[:8])
+			copy(out[8*(i-1):], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a[:], keyWrapIV[:]) != 1 {
+		return nil, errKeyUnwrap
+	}
+	return out, nil
+}
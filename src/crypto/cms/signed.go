@@ -0,0 +1,713 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Signature algorithms.
+var (
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA1WithRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 5}
+	oidRSASSAPSS       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+	oidSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidMGF1            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 8}
+	oidECPublicKey     = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidECDSAWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	oidEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+type signatureAlgorithmDetails struct {
+	algo       x509.SignatureAlgorithm
+	pubKeyAlgo x509.PublicKeyAlgorithm
+	hash       crypto.Hash
+	isPSS      bool
+	oid        asn1.ObjectIdentifier
+}
+
+// signatureAlgorithms are the signature algorithms supported for signing and
+// verification, with the digest algorithm of the signed content and the OID
+// used in the signatureAlgorithm field when signing.
+var signatureAlgorithms = []signatureAlgorithmDetails{
+	{x509.SHA1WithRSA, x509.RSA, crypto.SHA1, false, oidRSAEncryption},
+	{x509.SHA256WithRSA, x509.RSA, crypto.SHA256, false, oidRSAEncryption},
+	{x509.SHA384WithRSA, x509.RSA, crypto.SHA384, false, oidRSAEncryption},
+	{x509.SHA512WithRSA, x509.RSA, crypto.SHA512, false, oidRSAEncryption},
+	{x509.SHA256WithRSAPSS, x509.RSA, crypto.SHA256, true, oidRSASSAPSS},
+	{x509.SHA384WithRSAPSS, x509.RSA, crypto.SHA384, true, oidRSASSAPSS},
+	{x509.SHA512WithRSAPSS, x509.RSA, crypto.SHA512, true, oidRSASSAPSS},
+	{x509.ECDSAWithSHA1, x509.ECDSA, crypto.SHA1, false, oidECDSAWithSHA1},
+	{x509.ECDSAWithSHA256, x509.ECDSA, crypto.SHA256, false, oidECDSAWithSHA256},
+	{x509.ECDSAWithSHA384, x509.ECDSA, crypto.SHA384, false, oidECDSAWithSHA384},
+	{x509.ECDSAWithSHA512, x509.ECDSA, crypto.SHA512, false, oidECDSAWithSHA512},
+	// RFC 8419 requires SHA-512 as the digest algorithm for Ed25519, while
+	// the signature itself is computed over the signed attributes.
+	{x509.PureEd25519, x509.Ed25519, crypto.SHA512, false, oidEd25519},
+}
+
+// SignedData is a parsed CMS SignedData. See RFC 5652, Section 5.
+type SignedData struct {
+	// ContentType is the type of the signed content, usually [OIDData].
+	ContentType asn1.ObjectIdentifier
+
+	// Content is the signed content, or nil if the signatures are detached
+	// from the content.
+	//
+	// For compatibility with PKCS #7, if the content is not an OCTET STRING,
+	// Content is the content octets of its DER encoding, which is what its
+	// signatures are computed over.
+	Content []byte
+
+	// Certificates are the certificates included in the SignedData, which
+	// usually include the signer certificates and the intermediates needed
+	// to verify them. Other certificate formats are ignored.
+	Certificates []*x509.Certificate
+
+	// Signers are the signatures of the content.
+	Signers []*SignerInfo
+}
+
+// A SignerInfo is the signature of a signer of a [SignedData].
+type SignerInfo struct {
+	// Certificate is the certificate of the signer, from
+	// [SignedData.Certificates], or nil if it is not included.
+	Certificate *x509.Certificate
+
+	// DigestAlgorithm is the hash function used to digest the content, or
+	// zero if it is not supported.
+	DigestAlgorithm crypto.Hash
+
+	// SignatureAlgorithm is the algorithm of Signature, or
+	// [x509.UnknownSignatureAlgorithm] if it is not supported.
+	SignatureAlgorithm x509.SignatureAlgorithm
+
+	// SigningTime is the value of the signing-time attribute, or the zero
+	// time if it is absent. It is asserted by the signer, and is not
+	// checked by [SignedData.Verify].
+	SigningTime time.Time
+
+	Signature []byte
+
+	id            identifier
+	eContentType  asn1.ObjectIdentifier
+	signedAttrs   []byte // DER encoded SET OF Attribute, or nil
+	contentType   asn1.ObjectIdentifier
+	messageDigest []byte
+}
+
+// ParseSignedData parses a ContentInfo containing a SignedData, in BER or DER.
+//
+// The signatures are not checked, see [SignedData.Verify].
+func ParseSignedData(ber []byte) (*SignedData, error) {
+	input, err := parseContentInfo(ber, oidSignedData)
+	if err != nil {
+		return nil, err
+	}
+	sd := &SignedData{}
+
+	var version int
+	var digestAlgorithms, encap cryptobyte.String
+	if !input.ReadASN1(&input, cryptobyte_asn1.SEQUENCE) ||
+		!input.ReadASN1Integer(&version) ||
+		!input.ReadASN1(&digestAlgorithms, cryptobyte_asn1.SET) ||
+		!input.ReadASN1(&encap, cryptobyte_asn1.SEQUENCE) ||
+		!encap.ReadASN1ObjectIdentifier(&sd.ContentType) {
+		return nil, errors.New("cms: malformed SignedData")
+	}
+	if version < 1 || version > 5 {
+		return nil, errors.New("cms: unsupported SignedData version")
+	}
+
+	var eContent cryptobyte.String
+	var hasContent bool
+	if !encap.ReadOptionalASN1(&eContent, &hasContent, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!encap.Empty() {
+		return nil, errors.New("cms: malformed SignedData content")
+	}
+	if hasContent {
+		var tag cryptobyte_asn1.Tag
+		var content cryptobyte.String
+		if !eContent.ReadAnyASN1(&content, &tag) || !eContent.Empty() {
+			return nil, errors.New("cms: malformed SignedData content")
+		}
+		sd.Content = append([]byte{}, content...)
+	}
+
+	var certs cryptobyte.String
+	var hasCerts bool
+	if !input.ReadOptionalASN1(&certs, &hasCerts, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return nil, errors.New("cms: malformed SignedData certificates")
+	}
+	for !certs.Empty() {
+		var cert cryptobyte.String
+		var tag cryptobyte_asn1.Tag
+		if !certs.ReadAnyASN1Element(&cert, &tag) {
+			return nil, errors.New("cms: malformed SignedData certificates")
+		}
+		if tag != cryptobyte_asn1.SEQUENCE {
+			// Attribute certificates and other formats.
+			continue
+		}
+		c, err := x509.ParseCertificate(cert)
+		if err != nil {
+			return nil, err
+		}
+		sd.Certificates = append(sd.Certificates, c)
+	}
+
+	var signerInfos cryptobyte.String
+	if !input.SkipOptionalASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!input.ReadASN1(&signerInfos, cryptobyte_asn1.SET) ||
+		!input.Empty() {
+		return nil, errors.New("cms: malformed SignedData")
+	}
+	for !signerInfos.Empty() {
+		var signerInfo cryptobyte.String
+		if !signerInfos.ReadASN1(&signerInfo, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("cms: malformed SignerInfo")
+		}
+		si, err := parseSignerInfo(signerInfo)
+		if err != nil {
+			return nil, err
+		}
+		si.eContentType = sd.ContentType
+		for _, c := range sd.Certificates {
+			if si.id.matches(c) {
+				si.Certificate = c
+				break
+			}
+		}
+		sd.Signers = append(sd.Signers, si)
+	}
+	return sd, nil
+}
+
+func parseSignerInfo(input cryptobyte.String) (*SignerInfo, error) {
+	si := &SignerInfo{}
+	var version int
+	var digestAlgorithm, signatureAlgorithm algorithmIdentifier
+	var signedAttrs cryptobyte.String
+	var hasSignedAttrs bool
+	if !input.ReadASN1Integer(&version) ||
+		!readIdentifier(&input, &si.id, cryptobyte_asn1.Tag(0).ContextSpecific()) ||
+		!readAlgorithmIdentifier(&input, &digestAlgorithm) ||
+		!input.ReadOptionalASN1(&signedAttrs, &hasSignedAttrs, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!readAlgorithmIdentifier(&input, &signatureAlgorithm) ||
+		!input.ReadASN1Bytes(&si.Signature, cryptobyte_asn1.OCTET_STRING) ||
+		!input.SkipOptionalASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!input.Empty() {
+		return nil, errors.New("cms: malformed SignerInfo")
+	}
+	if version != 1 && version != 3 {
+		return nil, errors.New("cms: unsupported SignerInfo version")
+	}
+
+	si.DigestAlgorithm, _ = hashFromOID(digestAlgorithm.algorithm)
+	si.SignatureAlgorithm = parseSignatureAlgorithm(&signatureAlgorithm, si.DigestAlgorithm)
+
+	if hasSignedAttrs {
+		// The signature is computed over the DER encoding of the attributes
+		// with the SET OF tag, rather than the IMPLICIT [0] tag.
+		var b cryptobyte.Builder
+		b.AddASN1(cryptobyte_asn1.SET, func(b *cryptobyte.Builder) {
+			b.AddBytes(signedAttrs)
+		})
+		si.signedAttrs = b.BytesOrPanic()
+		if err := si.parseSignedAttributes(signedAttrs); err != nil {
+			return nil, err
+		}
+	}
+	return si, nil
+}
+
+func (si *SignerInfo) parseSignedAttributes(attrs cryptobyte.String) error {
+	seen := make(map[string]bool)
+	for !attrs.Empty() {
+		var attr, values cryptobyte.String
+		var attrType asn1.ObjectIdentifier
+		if !attrs.ReadASN1(&attr, cryptobyte_asn1.SEQUENCE) ||
+			!attr.ReadASN1ObjectIdentifier(&attrType) ||
+			!attr.ReadASN1(&values, cryptobyte_asn1.SET) ||
+			!attr.Empty() {
+			return errors.New("cms: malformed signed attribute")
+		}
+		if seen[attrType.String()] {
+			return errors.New("cms: duplicate signed attribute " + attrType.String())
+		}
+		seen[attrType.String()] = true
+
+		ok := true
+		switch {
+		case attrType.Equal(oidAttributeContentType):
+			ok = values.ReadASN1ObjectIdentifier(&si.contentType)
+		case attrType.Equal(oidAttributeMessageDigest):
+			ok = values.ReadASN1Bytes(&si.messageDigest, cryptobyte_asn1.OCTET_STRING)
+		case attrType.Equal(oidAttributeSigningTime):
+			if values.PeekASN1Tag(cryptobyte_asn1.UTCTime) {
+				ok = values.ReadASN1UTCTime(&si.SigningTime)
+			} else {
+				ok = values.ReadASN1GeneralizedTime(&si.SigningTime)
+			}
+		default:
+			continue
+		}
+		if !ok || !values.Empty() {
+			return errors.New("cms: malformed signed attribute " + attrType.String())
+		}
+	}
+	return nil
+}
+
+// parseSignatureAlgorithm returns the signature algorithm identified by ai,
+// used with content digested with hash.
+func parseSignatureAlgorithm(ai *algorithmIdentifier, hash crypto.Hash) x509.SignatureAlgorithm {
+	pkcs1 := func(h crypto.Hash) x509.SignatureAlgorithm {
+		for _, s := range signatureAlgorithms {
+			if s.pubKeyAlgo == x509.RSA && !s.isPSS && s.hash == h {
+				return s.algo
+			}
+		}
+		return x509.UnknownSignatureAlgorithm
+	}
+	switch algo := ai.algorithm; {
+	case algo.Equal(oidRSAEncryption):
+		// The hash is the digest algorithm, as in PKCS #7.
+		return pkcs1(hash)
+	case algo.Equal(oidSHA1WithRSA):
+		return pkcs1(crypto.SHA1)
+	case algo.Equal(oidSHA256WithRSA):
+		return pkcs1(crypto.SHA256)
+	case algo.Equal(oidSHA384WithRSA):
+		return pkcs1(crypto.SHA384)
+	case algo.Equal(oidSHA512WithRSA):
+		return pkcs1(crypto.SHA512)
+	case algo.Equal(oidRSASSAPSS):
+		return parsePSSParameters(ai.parameters)
+	case algo.Equal(oidECPublicKey):
+		switch hash {
+		case crypto.SHA1:
+			return x509.ECDSAWithSHA1
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512
+		}
+		return x509.UnknownSignatureAlgorithm
+	}
+	for _, s := range signatureAlgorithms {
+		if s.pubKeyAlgo != x509.RSA && s.oid.Equal(ai.algorithm) {
+			if s.algo == x509.PureEd25519 && len(ai.parameters) != 0 {
+				return x509.UnknownSignatureAlgorithm
+			}
+			return s.algo
+		}
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
+// parsePSSParameters returns the RSASSA-PSS signature algorithm with the given
+// RSASSA-PSS-params. As in crypto/x509, only parameters using the same hash
+// for MGF1, and a salt of the length of the hash, are supported.
+func parsePSSParameters(params cryptobyte.String) x509.SignatureAlgorithm {
+	var seq cryptobyte.String
+	if !params.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) || !params.Empty() {
+		return x509.UnknownSignatureAlgorithm
+	}
+	var hashAI, mgfAI, mgfHashAI algorithmIdentifier
+	var hashField, mgfField, saltField cryptobyte.String
+	var hasHash, hasMGF, hasSalt bool
+	saltLength, trailerField := 20, 1
+	if !seq.ReadOptionalASN1(&hashField, &hasHash, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!seq.ReadOptionalASN1(&mgfField, &hasMGF, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!seq.ReadOptionalASN1(&saltField, &hasSalt, cryptobyte_asn1.Tag(2).Constructed().ContextSpecific()) ||
+		!seq.ReadOptionalASN1Integer(&trailerField, cryptobyte_asn1.Tag(3).Constructed().ContextSpecific(), 1) ||
+		!seq.Empty() || trailerField != 1 {
+		return x509.UnknownSignatureAlgorithm
+	}
+	// The defaults are SHA-1, which is not supported.
+	if !hasHash || !hasMGF ||
+		!readAlgorithmIdentifier(&hashField, &hashAI) || !hashAI.isAbsentOrNULL() ||
+		!readAlgorithmIdentifier(&mgfField, &mgfAI) || !mgfAI.algorithm.Equal(oidMGF1) ||
+		!readAlgorithmIdentifier(&mgfAI.parameters, &mgfHashAI) || !mgfHashAI.isAbsentOrNULL() ||
+		!mgfHashAI.algorithm.Equal(hashAI.algorithm) {
+		return x509.UnknownSignatureAlgorithm
+	}
+	if hasSalt && (!saltField.ReadASN1Integer(&saltLength) || !saltField.Empty()) {
+		return x509.UnknownSignatureAlgorithm
+	}
+	hash, ok := hashFromOID(hashAI.algorithm)
+	if !ok || saltLength != hash.Size() {
+		return x509.UnknownSignatureAlgorithm
+	}
+	for _, s := range signatureAlgorithms {
+		if s.isPSS && s.hash == hash {
+			return s.algo
+		}
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
+// marshalPSSParameters returns the RSASSA-PSS-params for hash, with MGF1 with
+// the same hash and a salt of the length of the hash.
+func marshalPSSParameters(hash crypto.Hash) ([]byte, error) {
+	hashOID, ok := oidFromHash(hash)
+	if !ok {
+		return nil, errors.New("cms: unsupported hash function")
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			addAlgorithmIdentifier(b, hashOID, []byte{0x05, 0x00})
+		})
+		b.AddASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1ObjectIdentifier(oidMGF1)
+				addAlgorithmIdentifier(b, hashOID, []byte{0x05, 0x00})
+			})
+		})
+		b.AddASN1(cryptobyte_asn1.Tag(2).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1Int64(int64(hash.Size()))
+		})
+	})
+	return b.Bytes()
+}
+
+// Verify checks the signatures of sd over its content, and verifies the
+// certificates of the signers with opts. The certificates included in sd are
+// used as intermediates, in addition to opts.Intermediates.
+//
+// If opts.KeyUsages is empty, any extended key usage is accepted, rather than
+// only [x509.ExtKeyUsageServerAuth] as in [x509.Certificate.Verify].
+// Applications verifying S/MIME messages should usually require
+// [x509.ExtKeyUsageEmailProtection], and code signing applications
+// [x509.ExtKeyUsageCodeSigning].
+//
+// All signers must have a valid signature and certificate. If sd has no
+// signers, or its signatures are detached, Verify returns an error.
+func (sd *SignedData) Verify(opts x509.VerifyOptions) error {
+	if sd.Content == nil {
+		return errors.New("cms: SignedData has detached content")
+	}
+	return sd.verify(sd.Content, opts)
+}
+
+// VerifyDetached is like [SignedData.Verify], but checks the signatures over
+// content, for a SignedData with detached signatures.
+func (sd *SignedData) VerifyDetached(content []byte, opts x509.VerifyOptions) error {
+	if sd.Content != nil {
+		return errors.New("cms: SignedData has attached content")
+	}
+	return sd.verify(content, opts)
+}
+
+func (sd *SignedData) verify(content []byte, opts x509.VerifyOptions) error {
+	if len(sd.Signers) == 0 {
+		return errors.New("cms: SignedData has no signers")
+	}
+	if opts.Intermediates != nil {
+		opts.Intermediates = opts.Intermediates.Clone()
+	} else {
+		opts.Intermediates = x509.NewCertPool()
+	}
+	for _, c := range sd.Certificates {
+		opts.Intermediates.AddCert(c)
+	}
+	if len(opts.KeyUsages) == 0 {
+		opts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+	}
+	for _, si := range sd.Signers {
+		if err := si.CheckSignature(content); err != nil {
+			return err
+		}
+		if _, err := si.Certificate.Verify(opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckSignature checks that si is a valid signature of content by the key of
+// si.Certificate. It does not verify the certificate. Content digested with
+// SHA-1 is rejected.
+func (si *SignerInfo) CheckSignature(content []byte) error {
+	if si.Certificate == nil {
+		return errors.New("cms: signer certificate not found")
+	}
+	if si.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
+		return errors.New("cms: unsupported signature algorithm")
+	}
+	if si.signedAttrs == nil {
+		// Without signed attributes, the signature is computed directly
+		// over the content, which must be of type id-data.
+		if !si.eContentType.Equal(OIDData) {
+			return errors.New("cms: missing signed attributes")
+		}
+		return si.Certificate.CheckSignature(si.SignatureAlgorithm, content, si.Signature)
+	}
+
+	if si.DigestAlgorithm == 0 {
+		return errors.New("cms: unsupported digest algorithm")
+	}
+	if si.DigestAlgorithm == crypto.SHA1 {
+		return errors.New("cms: insecure digest algorithm SHA-1")
+	}
+	if si.contentType == nil || si.messageDigest == nil {
+		return errors.New("cms: missing content-type or message-digest signed attribute")
+	}
+	if !si.contentType.Equal(si.eContentType) {
+		return errors.New("cms: content-type attribute does not match the content type")
+	}
+	h := si.DigestAlgorithm.New()
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), si.messageDigest) {
+		return errors.New("cms: message digest mismatch")
+	}
+	return si.Certificate.CheckSignature(si.SignatureAlgorithm, si.signedAttrs, si.Signature)
+}
+
+// SignOptions are the options of [Sign].
+type SignOptions struct {
+	// SignatureAlgorithm is the signature algorithm. If zero, a default is
+	// chosen based on the key: SHA256WithRSA for RSA keys, ECDSAWithSHA256,
+	// ECDSAWithSHA384 or ECDSAWithSHA512 for ECDSA keys on the P-256,
+	// P-384 or P-521 curves, and PureEd25519 for Ed25519 keys.
+	//
+	// Algorithms based on SHA-1 are not supported.
+	SignatureAlgorithm x509.SignatureAlgorithm
+
+	// ContentType is the type of the content. If nil, [OIDData] is used.
+	ContentType asn1.ObjectIdentifier
+
+	// Detached, if true, omits the content from the SignedData, which must
+	// then be transmitted separately and passed to
+	// [SignedData.VerifyDetached].
+	Detached bool
+
+	// Certificates are additional certificates to include in the
+	// SignedData, usually the intermediates needed to verify the signer
+	// certificate, which is always included.
+	Certificates []*x509.Certificate
+
+	// SigningTime is the value of the signing-time attribute. If zero, the
+	// current time is used.
+	SigningTime time.Time
+}
+
+// Sign returns a ContentInfo in DER containing a SignedData with a signature
+// of content by key, whose certificate is cert, using rand as a source of
+// entropy. opts may be nil.
+//
+// The signature is computed over signed attributes, which include the
+// content type, the digest of the content, and the signing time.
+func Sign(rand io.Reader, content []byte, cert *x509.Certificate, key crypto.Signer, opts *SignOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &SignOptions{}
+	}
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(cert.PublicKey) {
+		return nil, errors.New("cms: key does not match the certificate")
+	}
+	algo := opts.SignatureAlgorithm
+	if algo == x509.UnknownSignatureAlgorithm {
+		var err error
+		if algo, err = defaultSignatureAlgorithm(key.Public()); err != nil {
+			return nil, err
+		}
+	}
+	i := slices.IndexFunc(signatureAlgorithms, func(s signatureAlgorithmDetails) bool {
+		return s.algo == algo
+	})
+	if i < 0 || signatureAlgorithms[i].hash == crypto.SHA1 {
+		return nil, errors.New("cms: unsupported signature algorithm " + algo.String())
+	}
+	s := signatureAlgorithms[i]
+	if s.pubKeyAlgo != cert.PublicKeyAlgorithm {
+		return nil, errors.New("cms: signature algorithm " + algo.String() + " does not match the key type")
+	}
+	var sigParams []byte
+	if s.isPSS {
+		var err error
+		if sigParams, err = marshalPSSParameters(s.hash); err != nil {
+			return nil, err
+		}
+	} else if s.pubKeyAlgo == x509.RSA {
+		sigParams = []byte{0x05, 0x00}
+	}
+	digestOID, _ := oidFromHash(s.hash)
+
+	contentType := opts.ContentType
+	if contentType == nil {
+		contentType = OIDData
+	}
+	signingTime := opts.SigningTime
+	if signingTime.IsZero() {
+		signingTime = time.Now()
+	}
+	h := s.hash.New()
+	h.Write(content)
+	signedAttrs, err := marshalSignedAttributes(contentType, h.Sum(nil), signingTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var signature []byte
+	switch {
+	case s.pubKeyAlgo == x509.Ed25519:
+		signature, err = key.Sign(rand, signedAttrs, crypto.Hash(0))
+	case s.isPSS:
+		h := s.hash.New()
+		h.Write(signedAttrs)
+		signature, err = key.Sign(rand, h.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: s.hash})
+	default:
+		h := s.hash.New()
+		h.Write(signedAttrs)
+		signature, err = key.Sign(rand, h.Sum(nil), s.hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Check the signature to ensure the crypto.Signer behaved correctly.
+	if err := cert.CheckSignature(algo, signedAttrs, signature); err != nil {
+		return nil, errors.New("cms: signature over signed attributes failed to verify: " + err.Error())
+	}
+
+	version := int64(1)
+	if !contentType.Equal(OIDData) {
+		version = 3
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Int64(version)
+		b.AddASN1(cryptobyte_asn1.SET, func(b *cryptobyte.Builder) {
+			addAlgorithmIdentifier(b, digestOID, nil)
+		})
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(contentType)
+			if !opts.Detached {
+				b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+					b.AddASN1OctetString(content)
+				})
+			}
+		})
+		b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddBytes(cert.Raw)
+			for _, c := range opts.Certificates {
+				b.AddBytes(c.Raw)
+			}
+		})
+		b.AddASN1(cryptobyte_asn1.SET, func(b *cryptobyte.Builder) {
+			b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1Int64(1)
+				addIssuerAndSerialNumber(b, cert)
+				addAlgorithmIdentifier(b, digestOID, nil)
+				b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+					// Strip the SET OF header.
+					attrs := cryptobyte.String(signedAttrs)
+					attrs.ReadASN1(&attrs, cryptobyte_asn1.SET)
+					b.AddBytes(attrs)
+				})
+				addAlgorithmIdentifier(b, s.oid, sigParams)
+				b.AddASN1OctetString(signature)
+			})
+		})
+	})
+	signedData, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return marshalContentInfo(oidSignedData, signedData)
+}
+
+func defaultSignatureAlgorithm(pub crypto.PublicKey) (x509.SignatureAlgorithm, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return x509.SHA256WithRSA, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return x509.ECDSAWithSHA256, nil
+		case elliptic.P384():
+			return x509.ECDSAWithSHA384, nil
+		case elliptic.P521():
+			return x509.ECDSAWithSHA512, nil
+		}
+		return 0, errors.New("cms: unsupported elliptic curve")
+	case ed25519.PublicKey:
+		return x509.PureEd25519, nil
+	}
+	return 0, errors.New("cms: unsupported key type")
+}
+
+// marshalSignedAttributes returns the DER encoding of the SET OF signed
+// attributes, over which the signature is computed.
+func marshalSignedAttributes(contentType asn1.ObjectIdentifier, digest []byte, signingTime time.Time) ([]byte, error) {
+	attr := func(attrType asn1.ObjectIdentifier, value func(*cryptobyte.Builder)) ([]byte, error) {
+		var b cryptobyte.Builder
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(attrType)
+			b.AddASN1(cryptobyte_asn1.SET, value)
+		})
+		return b.Bytes()
+	}
+	signingTime = signingTime.UTC().Truncate(time.Second)
+	var attrs [][]byte
+	for _, a := range []struct {
+		attrType asn1.ObjectIdentifier
+		value    func(*cryptobyte.Builder)
+	}{
+		{oidAttributeContentType, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(contentType)
+		}},
+		{oidAttributeMessageDigest, func(b *cryptobyte.Builder) {
+			b.AddASN1OctetString(digest)
+		}},
+		{oidAttributeSigningTime, func(b *cryptobyte.Builder) {
+			// RFC 5652, Section 11.3 requires UTCTime for dates between
+			// 1950 and 2049.
+			if y := signingTime.Year(); y >= 1950 && y < 2050 {
+				b.AddASN1UTCTime(signingTime)
+			} else {
+				b.AddASN1GeneralizedTime(signingTime)
+			}
+		}},
+	} {
+		der, err := attr(a.attrType, a.value)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, der)
+	}
+	// DER requires the elements of a SET OF to be sorted by their encoding.
+	slices.SortFunc(attrs, bytes.Compare)
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SET, func(b *cryptobyte.Builder) {
+		for _, a := range attrs {
+			b.AddBytes(a)
+		}
+	})
+	return b.Bytes()
+}
@@ -0,0 +1,296 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+	"time"
+)
+
+var testMessage = []byte("Hello, CMS!\n")
+
+func TestParseSignedDataOpenSSL(t *testing.T) {
+	tests := []struct {
+		file     string
+		detached bool
+		signer   string
+		algo     x509.SignatureAlgorithm
+		digest   crypto.Hash
+		noAttrs  bool
+	}{
+		{"signed_rsa.der", false, "rsa", x509.SHA256WithRSA, crypto.SHA256, false},
+		{"signed_rsa_ber.der", false, "rsa", x509.SHA256WithRSA, crypto.SHA256, false},
+		{"signed_pss.der", false, "rsa", x509.SHA256WithRSAPSS, crypto.SHA256, false},
+		{"detached_ec.der", true, "ec", x509.ECDSAWithSHA256, crypto.SHA256, false},
+		{"noattr_ec.der", false, "ec", x509.ECDSAWithSHA384, crypto.SHA384, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			sd, err := ParseSignedData(readTestFile(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sd.ContentType.Equal(OIDData) {
+				t.Errorf("ContentType = %v, want %v", sd.ContentType, OIDData)
+			}
+			if len(sd.Signers) != 1 {
+				t.Fatalf("got %d signers, want 1", len(sd.Signers))
+			}
+			si := sd.Signers[0]
+			if !si.Certificate.Equal(readTestCertificate(t, tt.signer)) {
+				t.Errorf("wrong signer certificate %v", si.Certificate.Subject)
+			}
+			if si.SignatureAlgorithm != tt.algo {
+				t.Errorf("SignatureAlgorithm = %v, want %v", si.SignatureAlgorithm, tt.algo)
+			}
+			if si.DigestAlgorithm != tt.digest {
+				t.Errorf("DigestAlgorithm = %v, want %v", si.DigestAlgorithm, tt.digest)
+			}
+			if si.SigningTime.IsZero() != tt.noAttrs {
+				t.Errorf("SigningTime = %v", si.SigningTime)
+			}
+
+			opts := testVerifyOptions(t)
+			if tt.detached {
+				if sd.Content != nil {
+					t.Errorf("Content = %q, want nil", sd.Content)
+				}
+				if err := sd.VerifyDetached(testMessage, opts); err != nil {
+					t.Error(err)
+				}
+				if err := sd.VerifyDetached([]byte("Hello, CMS?\n"), opts); err == nil {
+					t.Error("VerifyDetached succeeded with the wrong content")
+				}
+				if err := sd.Verify(opts); err == nil {
+					t.Error("Verify succeeded on detached SignedData")
+				}
+			} else {
+				if !bytes.Equal(sd.Content, testMessage) {
+					t.Errorf("Content = %q, want %q", sd.Content, testMessage)
+				}
+				if err := sd.Verify(opts); err != nil {
+					t.Error(err)
+				}
+				if err := sd.VerifyDetached(testMessage, opts); err == nil {
+					t.Error("VerifyDetached succeeded on attached SignedData")
+				}
+			}
+
+			opts.Roots = x509.NewCertPool()
+			if err := sd.verify(testMessage, opts); err == nil {
+				t.Error("verification succeeded without roots")
+			}
+			opts = testVerifyOptions(t)
+			opts.KeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+			if err := sd.verify(testMessage, opts); err == nil {
+				t.Error("verification succeeded with the wrong extended key usage")
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		key  string
+		algo x509.SignatureAlgorithm
+		want x509.SignatureAlgorithm
+	}{
+		{"RSA", 0, x509.SHA256WithRSA},
+		{"RSA", x509.SHA512WithRSA, x509.SHA512WithRSA},
+		{"RSA", x509.SHA256WithRSAPSS, x509.SHA256WithRSAPSS},
+		{"RSA", x509.SHA384WithRSAPSS, x509.SHA384WithRSAPSS},
+		{"P256", 0, x509.ECDSAWithSHA256},
+		{"P384", 0, x509.ECDSAWithSHA384},
+		{"P521", 0, x509.ECDSAWithSHA512},
+		{"P256", x509.ECDSAWithSHA512, x509.ECDSAWithSHA512},
+		{"Ed25519", 0, x509.PureEd25519},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"/"+tt.want.String(), func(t *testing.T) {
+			key, err := testKeys[tt.key]()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := newTestChain(t, key)
+			signingTime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+			for _, detached := range []bool{false, true} {
+				der, err := Sign(rand.Reader, testMessage, c.leaf, key, &SignOptions{
+					SignatureAlgorithm: tt.algo,
+					Detached:           detached,
+					Certificates:       []*x509.Certificate{c.intermediate},
+					SigningTime:        signingTime,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				sd, err := ParseSignedData(der)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(sd.Certificates) != 2 || len(sd.Signers) != 1 {
+					t.Fatalf("got %d certificates and %d signers", len(sd.Certificates), len(sd.Signers))
+				}
+				si := sd.Signers[0]
+				if si.SignatureAlgorithm != tt.want {
+					t.Errorf("SignatureAlgorithm = %v, want %v", si.SignatureAlgorithm, tt.want)
+				}
+				if !si.SigningTime.Equal(signingTime) {
+					t.Errorf("SigningTime = %v, want %v", si.SigningTime, signingTime)
+				}
+				if !si.Certificate.Equal(c.leaf) {
+					t.Error("wrong signer certificate")
+				}
+				if detached {
+					err = sd.VerifyDetached(testMessage, c.verifyOptions())
+				} else {
+					err = sd.Verify(c.verifyOptions())
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := si.CheckSignature([]byte("tampered")); err == nil {
+					t.Error("CheckSignature succeeded with the wrong content")
+				}
+
+				// Without the intermediate, the chain can't be built.
+				sd.Certificates = sd.Certificates[:1]
+				if err := sd.verify(testMessage, c.verifyOptions()); err == nil {
+					t.Error("verification succeeded without the intermediate")
+				}
+				opts := c.verifyOptions()
+				opts.Intermediates = x509.NewCertPool()
+				opts.Intermediates.AddCert(c.intermediate)
+				if err := sd.verify(testMessage, opts); err != nil {
+					t.Errorf("verification with the intermediate in the options: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSignTampered(t *testing.T) {
+	key, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestChain(t, key)
+	der, err := Sign(rand.Reader, testMessage, c.leaf, key, &SignOptions{Certificates: []*x509.Certificate{c.intermediate}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(der, testMessage)
+	if i < 0 {
+		t.Fatal("content not found in SignedData")
+	}
+	tampered := bytes.Clone(der)
+	tampered[i] ^= 1
+	sd, err := ParseSignedData(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sd.Verify(c.verifyOptions()); err == nil {
+		t.Error("Verify succeeded with tampered content")
+	}
+
+	sd, err = ParseSignedData(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd.Signers[0].Signature[0] ^= 1
+	if err := sd.Verify(c.verifyOptions()); err == nil {
+		t.Error("Verify succeeded with tampered signature")
+	}
+
+	if _, err := ParseSignedData(der[:len(der)-1]); err == nil {
+		t.Error("ParseSignedData succeeded with truncated input")
+	}
+}
+
+func TestSignContentType(t *testing.T) {
+	key, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestChain(t, key)
+	contentType := asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4} // id-ct-TSTInfo
+	der, err := Sign(rand.Reader, testMessage, c.leaf, key, &SignOptions{
+		ContentType:  contentType,
+		Certificates: []*x509.Certificate{c.intermediate},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := ParseSignedData(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sd.ContentType.Equal(contentType) {
+		t.Errorf("ContentType = %v, want %v", sd.ContentType, contentType)
+	}
+	if err := sd.Verify(c.verifyOptions()); err != nil {
+		t.Error(err)
+	}
+
+	// The content type is protected by the signed attributes.
+	sd.Signers[0].eContentType = OIDData
+	if err := sd.Verify(c.verifyOptions()); err == nil {
+		t.Error("Verify succeeded with a mismatched content type")
+	}
+}
+
+func TestCheckSignatureSHA1(t *testing.T) {
+	key, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestChain(t, key)
+	der, err := Sign(rand.Reader, testMessage, c.leaf, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := ParseSignedData(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	si := sd.Signers[0]
+	if err := si.CheckSignature(testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	// The signed attributes are unchanged, so only the digest algorithm
+	// causes the failure.
+	digest := sha1.Sum(testMessage)
+	si.DigestAlgorithm = crypto.SHA1
+	si.messageDigest = digest[:]
+	if err := si.CheckSignature(testMessage); err == nil {
+		t.Error("CheckSignature succeeded with a SHA-1 content digest")
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	key, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestChain(t, key)
+	other, err := testKeys["P256"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Sign(rand.Reader, testMessage, c.leaf, other, nil); err == nil {
+		t.Error("Sign succeeded with a key that does not match the certificate")
+	}
+	for _, algo := range []x509.SignatureAlgorithm{x509.ECDSAWithSHA1, x509.SHA256WithRSA, x509.PureEd25519, x509.MD5WithRSA} {
+		if _, err := Sign(rand.Reader, testMessage, c.leaf, key, &SignOptions{SignatureAlgorithm: algo}); err == nil {
+			t.Errorf("Sign succeeded with %v and a P-256 key", algo)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpke_test
+
+import (
+	"crypto/hpke"
+	"fmt"
+)
+
+func Example() {
+	// The recipient generates a key pair, and publishes the public key.
+	recipientKey, err := hpke.MLKEM768X25519.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	publicKeyBytes := recipientKey.PublicKey().Bytes()
+
+	// The sender parses the public key, and sets up a context to encrypt
+	// messages to it.
+	publicKey, err := hpke.MLKEM768X25519.NewPublicKey(publicKeyBytes)
+	if err != nil {
+		panic(err)
+	}
+	info := []byte("example application v1")
+	enc, sender, err := hpke.NewSender(publicKey, hpke.HKDFSHA256, hpke.AES256GCM, info, nil)
+	if err != nil {
+		panic(err)
+	}
+	ciphertext, err := sender.Seal([]byte("header"), []byte("hello, recipient"))
+	if err != nil {
+		panic(err)
+	}
+
+	// The recipient receives enc and the ciphertext, and sets up the
+	// matching context to decrypt it.
+	recipient, err := hpke.NewRecipient(enc, recipientKey, hpke.HKDFSHA256, hpke.AES256GCM, info, nil)
+	if err != nil {
+		panic(err)
+	}
+	plaintext, err := recipient.Open([]byte("header"), ciphertext)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", plaintext)
+	// Output: hello, recipient
+}
+
+func ExampleSeal() {
+	recipientKey, err := hpke.DHKEMX25519.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	message, err := hpke.Seal(recipientKey.PublicKey(), hpke.HKDFSHA256, hpke.ChaCha20Poly1305, nil, []byte("single-shot message"))
+	if err != nil {
+		panic(err)
+	}
+
+	plaintext, err := hpke.Open(recipientKey, hpke.HKDFSHA256, hpke.ChaCha20Poly1305, nil, message)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%s\n", plaintext)
+	// Output: single-shot message
+}
@@ -0,0 +1,371 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hpke implements Hybrid Public Key Encryption (HPKE), as specified in
+// [RFC 9180].
+//
+// An HPKE ciphersuite is the combination of a [KEM], a [KDF], and an [AEAD].
+// A sender sets up a [Sender] context for the public key of a recipient with
+// [NewSender], and transmits the returned encapsulated key to the recipient,
+// which sets up the matching [Recipient] context with [NewRecipient]. The
+// contexts can then be used to encrypt a sequence of messages from the sender
+// to the recipient, and to export secrets known to both.
+//
+// In addition to the DHKEMs of RFC 9180, the ML-KEM-768, ML-KEM-1024, and
+// MLKEM768-X25519 (also known as X-Wing) post-quantum KEMs specified in
+// [draft-ietf-hpke-pq-03] are supported. Most applications that need to
+// resist quantum computers should use [MLKEM768X25519].
+//
+// [RFC 9180]: https://www.rfc-editor.org/rfc/rfc9180.html
+// [draft-ietf-hpke-pq-03]: https://datatracker.ietf.org/doc/draft-ietf-hpke-pq/03/
+package hpke
+
+import (
+	"crypto/internal/hpke"
+	"errors"
+	"strconv"
+)
+
+// A KEM is a key encapsulation mechanism, identified by its HPKE KEM ID.
+type KEM uint16
+
+const (
+	DHKEMP256      KEM = hpke.DHKEM_P256_HKDF_SHA256   // DHKEM(P-256, HKDF-SHA256)
+	DHKEMP384      KEM = hpke.DHKEM_P384_HKDF_SHA384   // DHKEM(P-384, HKDF-SHA384)
+	DHKEMP521      KEM = hpke.DHKEM_P521_HKDF_SHA512   // DHKEM(P-521, HKDF-SHA512)
+	DHKEMX25519    KEM = hpke.DHKEM_X25519_HKDF_SHA256 // DHKEM(X25519, HKDF-SHA256)
+	MLKEM768       KEM = hpke.KEM_MLKEM768             // ML-KEM-768
+	MLKEM1024      KEM = hpke.KEM_MLKEM1024            // ML-KEM-1024
+	MLKEM768X25519 KEM = hpke.KEM_MLKEM768_X25519      // MLKEM768-X25519, also known as X-Wing
+)
+
+// A KDF is a key derivation function, identified by its HPKE KDF ID.
+type KDF uint16
+
+const (
+	HKDFSHA256 KDF = hpke.KDF_HKDF_SHA256 // HKDF-SHA256
+	HKDFSHA384 KDF = hpke.KDF_HKDF_SHA384 // HKDF-SHA384
+	HKDFSHA512 KDF = hpke.KDF_HKDF_SHA512 // HKDF-SHA512
+)
+
+// An AEAD is an authenticated encryption scheme, identified by its HPKE AEAD ID.
+type AEAD uint16
+
+const (
+	AES128GCM        AEAD = hpke.AEAD_AES_128_GCM      // AES-128-GCM
+	AES256GCM        AEAD = hpke.AEAD_AES_256_GCM      // AES-256-GCM
+	ChaCha20Poly1305 AEAD = hpke.AEAD_ChaCha20Poly1305 // ChaCha20Poly1305
+
+	// ExportOnly is the AEAD of contexts that can only be used to export
+	// secrets, with [Sender.Export] and [Recipient.Export].
+	ExportOnly AEAD = hpke.AEAD_ExportOnly
+)
+
+func (k KEM) String() string {
+	switch k {
+	case DHKEMP256:
+		return "DHKEM(P-256, HKDF-SHA256)"
+	case DHKEMP384:
+		return "DHKEM(P-384, HKDF-SHA384)"
+	case DHKEMP521:
+		return "DHKEM(P-521, HKDF-SHA512)"
+	case DHKEMX25519:
+		return "DHKEM(X25519, HKDF-SHA256)"
+	case MLKEM768:
+		return "ML-KEM-768"
+	case MLKEM1024:
+		return "ML-KEM-1024"
+	case MLKEM768X25519:
+		return "MLKEM768-X25519"
+	}
+	return "KEM(0x" + strconv.FormatUint(uint64(k), 16) + ")"
+}
+
+func (k KDF) String() string {
+	switch k {
+	case HKDFSHA256:
+		return "HKDF-SHA256"
+	case HKDFSHA384:
+		return "HKDF-SHA384"
+	case HKDFSHA512:
+		return "HKDF-SHA512"
+	}
+	return "KDF(0x" + strconv.FormatUint(uint64(k), 16) + ")"
+}
+
+func (a AEAD) String() string {
+	switch a {
+	case AES128GCM:
+		return "AES-128-GCM"
+	case AES256GCM:
+		return "AES-256-GCM"
+	case ChaCha20Poly1305:
+		return "ChaCha20Poly1305"
+	case ExportOnly:
+		return "Export-only"
+	}
+	return "AEAD(0x" + strconv.FormatUint(uint64(a), 16) + ")"
+}
+
+func (k KEM) kem() (hpke.KEM, error) {
+	kem, err := hpke.NewKEM(uint16(k))
+	if err != nil {
+		return nil, errors.New("hpke: unsupported KEM " + k.String())
+	}
+	return kem, nil
+}
+
+// GenerateKey generates a random key pair for the KEM.
+func (k KEM) GenerateKey() (*PrivateKey, error) {
+	kem, err := k.kem()
+	if err != nil {
+		return nil, err
+	}
+	sk, err := kem.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{sk}, nil
+}
+
+// DeriveKeyPair deterministically derives a key pair for the KEM from the
+// input keying material ikm, which must have at least as much entropy as the
+// private key.
+func (k KEM) DeriveKeyPair(ikm []byte) (*PrivateKey, error) {
+	kem, err := k.kem()
+	if err != nil {
+		return nil, err
+	}
+	sk, err := kem.DeriveKeyPair(ikm)
+	if err != nil {
+		return nil, errors.New("hpke: " + err.Error())
+	}
+	return &PrivateKey{sk}, nil
+}
+
+// NewPublicKey parses a public key of the KEM, in the encoding of
+// [PublicKey.Bytes].
+func (k KEM) NewPublicKey(b []byte) (*PublicKey, error) {
+	kem, err := k.kem()
+	if err != nil {
+		return nil, err
+	}
+	pk, err := kem.NewPublicKey(b)
+	if err != nil {
+		return nil, errors.New("hpke: invalid " + k.String() + " public key: " + err.Error())
+	}
+	return &PublicKey{pk}, nil
+}
+
+// NewPrivateKey parses a private key of the KEM, in the encoding of
+// [PrivateKey.Bytes].
+//
+// For the DHKEMs, that is the encoding of the corresponding crypto/ecdh
+// private key. For ML-KEM, it is the 64-byte "d || z" seed. For
+// MLKEM768-X25519, it is a 32-byte seed.
+func (k KEM) NewPrivateKey(b []byte) (*PrivateKey, error) {
+	kem, err := k.kem()
+	if err != nil {
+		return nil, err
+	}
+	sk, err := kem.NewPrivateKey(b)
+	if err != nil {
+		return nil, errors.New("hpke: invalid " + k.String() + " private key: " + err.Error())
+	}
+	return &PrivateKey{sk}, nil
+}
+
+// PublicKey is the public key of a recipient, or of an authenticated sender.
+type PublicKey struct {
+	pk hpke.PublicKey
+}
+
+// KEM returns the KEM of the key.
+func (pk *PublicKey) KEM() KEM {
+	return KEM(pk.pk.KEM().ID())
+}
+
+// Bytes returns the encoding of the key, as specified by SerializePublicKey.
+func (pk *PublicKey) Bytes() []byte {
+	return pk.pk.Bytes()
+}
+
+// PrivateKey is the private key of a recipient, or of an authenticated sender.
+type PrivateKey struct {
+	sk hpke.PrivateKey
+}
+
+// KEM returns the KEM of the key.
+func (sk *PrivateKey) KEM() KEM {
+	return KEM(sk.sk.KEM().ID())
+}
+
+// Bytes returns the encoding of the key, as specified by SerializePrivateKey.
+//
+// Note that DHKEM(X25519, HKDF-SHA256) private keys are clamped, so they
+// might not match the input of [KEM.NewPrivateKey], although they are
+// equivalent to it.
+func (sk *PrivateKey) Bytes() ([]byte, error) {
+	return sk.sk.Bytes()
+}
+
+// PublicKey returns the public key corresponding to sk.
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{sk.sk.PublicKey()}
+}
+
+// SenderOptions are optional parameters of [NewSender], which select the PSK,
+// Auth, or AuthPSK modes of RFC 9180, Section 5.1. The recipient must use the
+// matching [RecipientOptions].
+type SenderOptions struct {
+	// PSK and PSKID are a pre-shared key, which must be at least 32 bytes
+	// long, and its identifier. If set, the context is authenticated by the
+	// knowledge of PSK.
+	PSK, PSKID []byte
+
+	// SenderKey, if not nil, is the private key of the sender, which
+	// authenticates the context. It must be a key of the same KEM as the
+	// recipient public key, and only the DHKEMs support authentication.
+	SenderKey *PrivateKey
+}
+
+// RecipientOptions are optional parameters of [NewRecipient], matching the
+// [SenderOptions] used by the sender.
+type RecipientOptions struct {
+	// PSK and PSKID are the pre-shared key and its identifier.
+	PSK, PSKID []byte
+
+	// SenderKey, if not nil, is the public key of the sender, which must
+	// match the [SenderOptions.SenderKey] used by the sender.
+	SenderKey *PublicKey
+}
+
+// Sender is a sending HPKE context, which encrypts messages for a recipient.
+// It is not safe for concurrent use.
+type Sender struct {
+	s *hpke.Sender
+}
+
+// Recipient is a receiving HPKE context, which decrypts messages from a sender.
+// It is not safe for concurrent use.
+type Recipient struct {
+	r *hpke.Recipient
+}
+
+// NewSender sets up a sending context for the recipient public key pk, using
+// the given KDF and AEAD. info is application-supplied information that must
+// match between sender and recipient. opts may be nil, selecting the Base mode.
+//
+// The returned encapsulated key enc must be transmitted to the recipient.
+func NewSender(pk *PublicKey, kdf KDF, aead AEAD, info []byte, opts *SenderOptions) (enc []byte, s *Sender, err error) {
+	var skS hpke.PrivateKey
+	var psk, pskID []byte
+	if opts != nil {
+		if opts.SenderKey != nil {
+			skS = opts.SenderKey.sk
+		}
+		psk, pskID = opts.PSK, opts.PSKID
+	}
+	enc, sender, err := hpke.NewSender(pk.pk, skS, uint16(kdf), uint16(aead), info, psk, pskID)
+	if err != nil {
+		return nil, nil, errors.New("hpke: " + err.Error())
+	}
+	return enc, &Sender{sender}, nil
+}
+
+// NewRecipient sets up the receiving context matching a sending context set up
+// with the public key of sk, from the encapsulated key enc it returned. kdf,
+// aead, and info must match those of the sender. opts may be nil, selecting
+// the Base mode.
+func NewRecipient(enc []byte, sk *PrivateKey, kdf KDF, aead AEAD, info []byte, opts *RecipientOptions) (*Recipient, error) {
+	var pkS hpke.PublicKey
+	var psk, pskID []byte
+	if opts != nil {
+		if opts.SenderKey != nil {
+			pkS = opts.SenderKey.pk
+		}
+		psk, pskID = opts.PSK, opts.PSKID
+	}
+	r, err := hpke.NewRecipient(enc, sk.sk, pkS, uint16(kdf), uint16(aead), info, psk, pskID)
+	if err != nil {
+		return nil, errors.New("hpke: " + err.Error())
+	}
+	return &Recipient{r}, nil
+}
+
+// Seal encrypts and authenticates plaintext, and authenticates aad, returning
+// the ciphertext. Messages must be opened by the recipient in the order they
+// were sealed.
+func (s *Sender) Seal(aad, plaintext []byte) ([]byte, error) {
+	ciphertext, err := s.s.Seal(aad, plaintext)
+	if err != nil {
+		return nil, errors.New("hpke: " + err.Error())
+	}
+	return ciphertext, nil
+}
+
+// Export returns a secret of the given length derived from the context and
+// exporterContext. The recipient derives the same secret with
+// [Recipient.Export]. length must be at most 255 times the KDF output size.
+func (s *Sender) Export(exporterContext []byte, length int) ([]byte, error) {
+	secret, err := s.s.Export(exporterContext, length)
+	if err != nil {
+		return nil, errors.New("hpke: " + err.Error())
+	}
+	return secret, nil
+}
+
+// Open decrypts and authenticates ciphertext, and authenticates aad, returning
+// the plaintext. If authentication fails, the context can still be used to
+// open the next message.
+func (r *Recipient) Open(aad, ciphertext []byte) ([]byte, error) {
+	plaintext, err := r.r.Open(aad, ciphertext)
+	if err != nil {
+		return nil, errors.New("hpke: " + err.Error())
+	}
+	return plaintext, nil
+}
+
+// Export returns a secret of the given length derived from the context and
+// exporterContext, matching the one returned by [Sender.Export].
+func (r *Recipient) Export(exporterContext []byte, length int) ([]byte, error) {
+	secret, err := r.r.Export(exporterContext, length)
+	if err != nil {
+		return nil, errors.New("hpke: " + err.Error())
+	}
+	return secret, nil
+}
+
+// Seal encrypts a single message for the recipient public key pk, in the Base
+// mode, returning the encapsulated key followed by the ciphertext.
+//
+// This is the single-shot API of RFC 9180, Section 6.1.
+func Seal(pk *PublicKey, kdf KDF, aead AEAD, info, plaintext []byte) ([]byte, error) {
+	enc, s, err := NewSender(pk, kdf, aead, info, nil)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := s.Seal(nil, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(enc, ciphertext...), nil
+}
+
+// Open decrypts a single message sealed by [Seal] for the public key of sk.
+func Open(sk *PrivateKey, kdf KDF, aead AEAD, info, ciphertext []byte) ([]byte, error) {
+	kem, err := sk.KEM().kem()
+	if err != nil {
+		return nil, err
+	}
+	n := kem.EncapsulatedKeySize()
+	if len(ciphertext) < n {
+		return nil, errors.New("hpke: ciphertext too short")
+	}
+	r, err := NewRecipient(ciphertext[:n], sk, kdf, aead, info, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.Open(nil, ciphertext[n:])
+}
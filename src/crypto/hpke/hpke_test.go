@@ -0,0 +1,246 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpke
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	kems  = []KEM{DHKEMP256, DHKEMP384, DHKEMP521, DHKEMX25519, MLKEM768, MLKEM1024, MLKEM768X25519}
+	kdfs  = []KDF{HKDFSHA256, HKDFSHA384, HKDFSHA512}
+	aeads = []AEAD{AES128GCM, AES256GCM, ChaCha20Poly1305}
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, kem := range kems {
+		sk, err := kem.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sk.KEM() != kem || sk.PublicKey().KEM() != kem {
+			t.Errorf("%v: generated key has KEM %v", kem, sk.KEM())
+		}
+		skBytes, err := sk.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk2, err := kem.NewPrivateKey(skBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk, err := kem.NewPublicKey(sk.PublicKey().Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(pk.Bytes(), sk2.PublicKey().Bytes()) {
+			t.Errorf("%v: parsed private key has a different public key", kem)
+		}
+
+		for _, kdf := range kdfs {
+			for _, aead := range aeads {
+				t.Run(kem.String()+"/"+kdf.String()+"/"+aead.String(), func(t *testing.T) {
+					message, err := Seal(pk, kdf, aead, []byte("info"), []byte("plaintext"))
+					if err != nil {
+						t.Fatal(err)
+					}
+					plaintext, err := Open(sk2, kdf, aead, []byte("info"), message)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if string(plaintext) != "plaintext" {
+						t.Errorf("Open = %q, want %q", plaintext, "plaintext")
+					}
+					if _, err := Open(sk2, kdf, aead, []byte("other info"), message); err == nil {
+						t.Error("Open succeeded with the wrong info")
+					}
+					message[len(message)-1] ^= 1
+					if _, err := Open(sk2, kdf, aead, []byte("info"), message); err == nil {
+						t.Error("Open succeeded with a modified ciphertext")
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	sk, err := DHKEMP256.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, s, err := NewSender(sk.PublicKey(), HKDFSHA256, AES128GCM, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRecipient(enc, sk, HKDFSHA256, AES128GCM, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ciphertexts [][]byte
+	for i := range 3 {
+		ct, err := s.Seal([]byte{byte(i)}, []byte("message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertexts = append(ciphertexts, ct)
+	}
+	if bytes.Equal(ciphertexts[0], ciphertexts[1]) {
+		t.Error("sealing the same message twice produced the same ciphertext")
+	}
+	// Out of order messages fail to open, without breaking the context.
+	if _, err := r.Open([]byte{1}, ciphertexts[1]); err == nil {
+		t.Error("Open succeeded out of order")
+	}
+	for i, ct := range ciphertexts {
+		if _, err := r.Open([]byte{byte(i)}, ct); err != nil {
+			t.Errorf("message %d: %v", i, err)
+		}
+	}
+
+	secret, err := s.Export([]byte("context"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret2, err := r.Export([]byte("context"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 64 || !bytes.Equal(secret, secret2) {
+		t.Errorf("exported secrets %x and %x, want equal 64-byte secrets", secret, secret2)
+	}
+	if _, err := s.Export(nil, 255*32+1); err == nil {
+		t.Error("Export accepted an excessive length")
+	}
+}
+
+func TestExportOnly(t *testing.T) {
+	sk, err := MLKEM768.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, s, err := NewSender(sk.PublicKey(), HKDFSHA384, ExportOnly, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRecipient(enc, sk, HKDFSHA384, ExportOnly, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Seal(nil, nil); err == nil {
+		t.Error("Seal succeeded on an export-only context")
+	}
+	if _, err := r.Open(nil, nil); err == nil {
+		t.Error("Open succeeded on an export-only context")
+	}
+	a, err := s.Export([]byte("x"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := r.Export([]byte("x"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("exported secrets %x and %x differ", a, b)
+	}
+}
+
+func TestModes(t *testing.T) {
+	psk := bytes.Repeat([]byte{1}, 32)
+	skR, err := DHKEMX25519.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	skS, err := DHKEMX25519.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name string
+		s    *SenderOptions
+		r    *RecipientOptions
+		ok   bool
+	}{
+		{"PSK", &SenderOptions{PSK: psk, PSKID: []byte("id")}, &RecipientOptions{PSK: psk, PSKID: []byte("id")}, true},
+		{"Auth", &SenderOptions{SenderKey: skS}, &RecipientOptions{SenderKey: skS.PublicKey()}, true},
+		{"AuthPSK", &SenderOptions{SenderKey: skS, PSK: psk, PSKID: []byte("id")}, &RecipientOptions{SenderKey: skS.PublicKey(), PSK: psk, PSKID: []byte("id")}, true},
+		{"WrongPSKID", &SenderOptions{PSK: psk, PSKID: []byte("id")}, &RecipientOptions{PSK: psk, PSKID: []byte("other")}, false},
+		{"MissingPSK", &SenderOptions{PSK: psk, PSKID: []byte("id")}, nil, false},
+		{"WrongSender", &SenderOptions{SenderKey: skS}, &RecipientOptions{SenderKey: skR.PublicKey()}, false},
+		{"MissingAuth", &SenderOptions{SenderKey: skS}, nil, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			enc, s, err := NewSender(skR.PublicKey(), HKDFSHA256, AES128GCM, []byte("info"), test.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewRecipient(enc, skR, HKDFSHA256, AES128GCM, []byte("info"), test.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ct, err := s.Seal(nil, []byte("plaintext"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = r.Open(nil, ct)
+			if test.ok && err != nil {
+				t.Errorf("Open failed: %v", err)
+			} else if !test.ok && err == nil {
+				t.Errorf("Open succeeded with mismatched options")
+			}
+		})
+	}
+
+	if _, _, err := NewSender(skR.PublicKey(), HKDFSHA256, AES128GCM, nil, &SenderOptions{PSK: psk[:31], PSKID: []byte("id")}); err == nil {
+		t.Error("NewSender accepted a short PSK")
+	}
+	skX, err := MLKEM768X25519.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewSender(skX.PublicKey(), HKDFSHA256, AES128GCM, nil, &SenderOptions{SenderKey: skX}); err == nil {
+		t.Error("NewSender accepted the Auth mode with MLKEM768-X25519")
+	}
+}
+
+// TestDeriveKeyPair checks a key pair derivation from RFC 9180, Appendix A.1.
+func TestDeriveKeyPair(t *testing.T) {
+	ikm, _ := hex.DecodeString("6db9df30aa07dd42ee5e8181afdb977e538f5e1fec8a06223f33f7013e525037")
+	want, _ := hex.DecodeString("3948cfe0ad1ddb695d780e59077195da6c56506b027329794ab02bca80815c4d")
+	sk, err := DHKEMX25519.DeriveKeyPair(ikm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sk.PublicKey().Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("DeriveKeyPair public key = %x, want %x", got, want)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := KEM(0x0021).GenerateKey(); err == nil {
+		t.Error("GenerateKey succeeded for an unsupported KEM")
+	}
+	sk, err := DHKEMX25519.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewSender(sk.PublicKey(), KDF(0x0010), AES128GCM, nil, nil); err == nil {
+		t.Error("NewSender succeeded with an unsupported KDF")
+	}
+	if _, _, err := NewSender(sk.PublicKey(), HKDFSHA256, AEAD(0x0004), nil, nil); err == nil {
+		t.Error("NewSender succeeded with an unsupported AEAD")
+	}
+	if _, err := DHKEMP256.NewPublicKey(sk.PublicKey().Bytes()); err == nil {
+		t.Error("NewPublicKey accepted a key of a different KEM")
+	}
+	if _, err := Open(sk, HKDFSHA256, AES128GCM, nil, make([]byte, 16)); err == nil {
+		t.Error("Open accepted a truncated message")
+	}
+}
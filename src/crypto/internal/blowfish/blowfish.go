@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package blowfish implements the Blowfish block cipher, with the extensions
+// to its key schedule used by bcrypt, for crypto/password and bcrypt_pbkdf.
+// It is not exported because Blowfish is unsuitable for any new use.
+package blowfish
+
+// Cipher is a Blowfish cipher, operating on blocks made of two 32-bit halves.
+type Cipher struct {
+	p [18]uint32
+	s [4][256]uint32
+}
+
+// NewSalted returns a Blowfish cipher with the salted key schedule of
+// bcrypt's EksBlowfishSetup, before any further key expansion.
+func NewSalted(key, salt []byte) *Cipher {
+	c := &Cipher{
+		p: blowfishP,
+		s: [4][256]uint32{blowfishS0, blowfishS1, blowfishS2, blowfishS3},
+	}
+	c.ExpandKey(key, salt)
+	return c
+}
+
+// nextWord returns the big-endian uint32 at position *pos of b, treating b as
+// a circular buffer, and advances *pos.
+func nextWord(b []byte, pos *int) uint32 {
+	var w uint32
+	j := *pos
+	for range 4 {
+		w = w<<8 | uint32(b[j])
+		j++
+		if j >= len(b) {
+			j = 0
+		}
+	}
+	*pos = j
+	return w
+}
+
+// ExpandKey folds key into the subkeys and regenerates the subkeys and
+// S-boxes, mixing in salt as it goes. A nil salt is equivalent to the
+// standard Blowfish key schedule.
+func (c *Cipher) ExpandKey(key, salt []byte) {
+	j := 0
+	for i := range c.p {
+		c.p[i] ^= nextWord(key, &j)
+	}
+
+	j = 0
+	var l, r uint32
+	next := func() {
+		if salt != nil {
+			l ^= nextWord(salt, &j)
+			r ^= nextWord(salt, &j)
+		}
+		l, r = c.Encrypt(l, r)
+	}
+	for i := 0; i < len(c.p); i += 2 {
+		next()
+		c.p[i], c.p[i+1] = l, r
+	}
+	for k := range c.s {
+		for i := 0; i < len(c.s[k]); i += 2 {
+			next()
+			c.s[k][i], c.s[k][i+1] = l, r
+		}
+	}
+}
+
+func (c *Cipher) f(x uint32) uint32 {
+	return ((c.s[0][byte(x>>24)] + c.s[1][byte(x>>16)]) ^ c.s[2][byte(x>>8)]) + c.s[3][byte(x)]
+}
+
+// Encrypt encrypts the block made of the two halves l and r.
+func (c *Cipher) Encrypt(l, r uint32) (uint32, uint32) {
+	l ^= c.p[0]
+	for i := 1; i < 17; i += 2 {
+		r ^= c.f(l) ^ c.p[i]
+		l ^= c.f(r) ^ c.p[i+1]
+	}
+	r ^= c.p[17]
+	return r, l
+}
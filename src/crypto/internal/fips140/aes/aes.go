@@ -129,3 +129,10 @@ func (c *Block) Decrypt(dst, src []byte) {
 func EncryptBlockInternal(c *Block, dst, src []byte) {
 	encryptBlock(c, dst, src)
 }
+
+// DecryptBlockInternal applies the AES decryption function to one block.
+//
+// It is an internal function meant only for the crypto/cipher package.
+func DecryptBlockInternal(c *Block, dst, src []byte) {
+	decryptBlock(c, dst, src)
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mldsa
+
+import (
+	"bytes"
+	"crypto/internal/fips140"
+	_ "crypto/internal/fips140/check"
+	"crypto/internal/fips140/sha256"
+	"crypto/internal/fips140/sha3"
+	"errors"
+	"sync"
+)
+
+func fipsPCT(priv *PrivateKey) error {
+	return fips140.PCT("ML-DSA sign and verify PCT", func() error {
+		var mu [muSize]byte
+		sig := signInternal(priv, &mu, &[32]byte{})
+		return verifyInternal(priv.PublicKey(), &mu, sig)
+	})
+}
+
+var fipsSelfTest = sync.OnceFunc(func() {
+	fips140.CAST("ML-DSA-44 sign and verify", func() error {
+		// From the ML-DSA.Sign_internal rejection path KATs in
+		// https://pages.nist.gov/ACVP/draft-celi-acvp-ml-dsa.html#table-1.
+		// The results are compared as SHA2-256 hashes, to avoid embedding
+		// several kilobytes of test vectors.
+		seed := &[32]byte{
+			0x5c, 0x62, 0x4f, 0xcc, 0x18, 0x62, 0x45, 0x24,
+			0x52, 0xd0, 0xc6, 0x65, 0x84, 0x0d, 0x82, 0x37,
+			0xf4, 0x31, 0x08, 0xe5, 0x49, 0x9e, 0xdc, 0xdc,
+			0x10, 0x8f, 0xbc, 0x49, 0xd5, 0x96, 0xe4, 0xb7,
+		}
+		msg := []byte{
+			0x95, 0x1f, 0xdf, 0x54, 0x73, 0xa4, 0xcb, 0xa6,
+			0xd9, 0xe5, 0xb5, 0xdb, 0x7e, 0x79, 0xfb, 0x81,
+			0x73, 0x92, 0x1b, 0xa5, 0xb1, 0x3e, 0x92, 0x71,
+			0x40, 0x1b, 0x8f, 0x90, 0x7b, 0x8b, 0x7d, 0x5b,
+		}
+		keyHash := []byte{
+			0xac, 0x82, 0x5c, 0x59, 0xd8, 0xa4, 0xc4, 0x53,
+			0xa2, 0xc4, 0xef, 0xea, 0x83, 0x95, 0x74, 0x1c,
+			0xa4, 0x04, 0xf3, 0x00, 0x0e, 0x28, 0xd5, 0x6b,
+			0x25, 0xd0, 0x3b, 0xb4, 0x02, 0xe5, 0xcb, 0x2f,
+		}
+		sigHash := []byte{
+			0xdc, 0xc7, 0x1a, 0x42, 0x1b, 0xc6, 0xff, 0xaf,
+			0xb7, 0xdf, 0x0c, 0x7f, 0x6d, 0x01, 0x8a, 0x19,
+			0xad, 0xa1, 0x54, 0xd1, 0xe2, 0xee, 0x36, 0x0e,
+			0xd5, 0x33, 0xce, 0xcd, 0x5d, 0xc9, 0x80, 0xad,
+		}
+		priv := newPrivateKey(params44, seed)
+		h := sha256.New()
+		h.Write(priv.pub.raw)
+		h.Write(priv.ExpandedBytes())
+		if !bytes.Equal(h.Sum(nil), keyHash) {
+			return errors.New("unexpected key")
+		}
+		mu := computeMuInternal(&priv.pub, msg)
+		sig := signInternal(priv, mu, &[32]byte{})
+		h.Reset()
+		h.Write(sig)
+		if !bytes.Equal(h.Sum(nil), sigHash) {
+			return errors.New("unexpected signature")
+		}
+		return verifyInternal(&priv.pub, mu, sig)
+	})
+})
+
+// computeMuInternal computes μ = H(tr || M', 64) for a raw M', as used by
+// the ML-DSA.Sign_internal test vectors.
+func computeMuInternal(pub *PublicKey, mPrime []byte) *[muSize]byte {
+	H := sha3.NewShake256()
+	H.Write(pub.tr[:])
+	H.Write(mPrime)
+	var mu [muSize]byte
+	H.Read(mu[:])
+	return &mu
+}
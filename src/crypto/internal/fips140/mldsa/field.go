@@ -0,0 +1,482 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mldsa
+
+import (
+	"crypto/internal/fips140/sha3"
+	"errors"
+	"math/bits"
+)
+
+const (
+	n = 256
+	q = 8380417 // 2²³ - 2¹³ + 1
+	d = 13      // dropped bits from t
+
+	barrettMultiplier = 2201172575745 // ⌊2⁶⁴ / q⌋
+)
+
+// fieldElement is an integer modulo q, an element of ℤ_q. It is always reduced.
+type fieldElement uint32
+
+// fieldReduceOnce reduces a value a < 2q.
+func fieldReduceOnce(a uint32) fieldElement {
+	x := a - q
+	// If x underflowed, then x >= 2³² - q > 2³¹, so the top bit is set.
+	x += (x >> 31) * q
+	return fieldElement(x)
+}
+
+func fieldAdd(a, b fieldElement) fieldElement {
+	return fieldReduceOnce(uint32(a + b))
+}
+
+func fieldSub(a, b fieldElement) fieldElement {
+	return fieldReduceOnce(uint32(a - b + q))
+}
+
+// fieldReduce reduces a value a < 2⁶⁴ using Barrett reduction, to avoid
+// potentially variable-time division. The quotient estimate is at most one
+// less than the real quotient, so the remainder is less than 2q.
+func fieldReduce(a uint64) fieldElement {
+	quotient, _ := bits.Mul64(a, barrettMultiplier)
+	return fieldReduceOnce(uint32(a - quotient*q))
+}
+
+func fieldMul(a, b fieldElement) fieldElement {
+	return fieldReduce(uint64(a) * uint64(b))
+}
+
+// fieldFromInt32 returns a mod q, for -q < a < q.
+func fieldFromInt32(a int32) fieldElement {
+	x := uint32(a)
+	x += (x >> 31) * q
+	return fieldElement(x)
+}
+
+// fieldCentered returns the representative of a in (-(q-1)/2, (q-1)/2],
+// according to the mod± definition of FIPS 204, Section 2.3.
+func fieldCentered(a fieldElement) int32 {
+	x := int32(a)
+	// If x > (q-1)/2, then (q-1)/2 - x is negative and the mask is all ones.
+	x -= ((int32((q-1)/2) - x) >> 31) & q
+	return x
+}
+
+// fieldAbs returns |a mod± q|.
+func fieldAbs(a fieldElement) uint32 {
+	x := fieldCentered(a)
+	mask := x >> 31
+	return uint32((x ^ mask) - mask)
+}
+
+// ringElement is a polynomial, an element of R_q, represented as an array
+// according to FIPS 204, Section 2.3.
+type ringElement [n]fieldElement
+
+// nttElement is an NTT representation, an element of T_q, represented as an
+// array according to FIPS 204, Section 2.5.
+type nttElement [n]fieldElement
+
+// polyAdd adds two ringElements or nttElements.
+func polyAdd[T ~[n]fieldElement](a, b T) (s T) {
+	for i := range s {
+		s[i] = fieldAdd(a[i], b[i])
+	}
+	return s
+}
+
+// polySub subtracts two ringElements or nttElements.
+func polySub[T ~[n]fieldElement](a, b T) (s T) {
+	for i := range s {
+		s[i] = fieldSub(a[i], b[i])
+	}
+	return s
+}
+
+// polyNormExceeds reports whether the infinity norm of f, the largest
+// |f[i] mod± q|, is at least bound. It runs in constant time with respect to
+// the coefficients of f.
+func polyNormExceeds(f ringElement, bound uint32) bool {
+	var exceeds uint32
+	for i := range f {
+		// If |f[i]| < bound, the subtraction underflows and the top bit is set.
+		exceeds |= ^(fieldAbs(f[i]) - bound) >> 31
+	}
+	return exceeds == 1
+}
+
+// nttMul multiplies two nttElements.
+//
+// It implements MultiplyNTT, according to FIPS 204, Algorithm 45.
+func nttMul(f, g nttElement) nttElement {
+	var h nttElement
+	for i := range h {
+		h[i] = fieldMul(f[i], g[i])
+	}
+	return h
+}
+
+// zetas are the values ζ^BitRev₈(k) mod q for each index k, according to
+// FIPS 204, Appendix B.
+var zetas = [256]fieldElement{
+	1, 4808194, 3765607, 3761513, 5178923, 5496691, 5234739, 5178987,
+	7778734, 3542485, 2682288, 2129892, 3764867, 7375178, 557458, 7159240,
+	5010068, 4317364, 2663378, 6705802, 4855975, 7946292, 676590, 7044481,
+	5152541, 1714295, 2453983, 1460718, 7737789, 4795319, 2815639, 2283733,
+	3602218, 3182878, 2740543, 4793971, 5269599, 2101410, 3704823, 1159875,
+	394148, 928749, 1095468, 4874037, 2071829, 4361428, 3241972, 2156050,
+	3415069, 1759347, 7562881, 4805951, 3756790, 6444618, 6663429, 4430364,
+	5483103, 3192354, 556856, 3870317, 2917338, 1853806, 3345963, 1858416,
+	3073009, 1277625, 5744944, 3852015, 4183372, 5157610, 5258977, 8106357,
+	2508980, 2028118, 1937570, 4564692, 2811291, 5396636, 7270901, 4158088,
+	1528066, 482649, 1148858, 5418153, 7814814, 169688, 2462444, 5046034,
+	4213992, 4892034, 1987814, 5183169, 1736313, 235407, 5130263, 3258457,
+	5801164, 1787943, 5989328, 6125690, 3482206, 4197502, 7080401, 6018354,
+	7062739, 2461387, 3035980, 621164, 3901472, 7153756, 2925816, 3374250,
+	1356448, 5604662, 2683270, 5601629, 4912752, 2312838, 7727142, 7921254,
+	348812, 8052569, 1011223, 6026202, 4561790, 6458164, 6143691, 1744507,
+	1753, 6444997, 5720892, 6924527, 2660408, 6600190, 8321269, 2772600,
+	1182243, 87208, 636927, 4415111, 4423672, 6084020, 5095502, 4663471,
+	8352605, 822541, 1009365, 5926272, 6400920, 1596822, 4423473, 4620952,
+	6695264, 4969849, 2678278, 4611469, 4829411, 635956, 8129971, 5925040,
+	4234153, 6607829, 2192938, 6653329, 2387513, 4768667, 8111961, 5199961,
+	3747250, 2296099, 1239911, 4541938, 3195676, 2642980, 1254190, 8368000,
+	2998219, 141835, 8291116, 2513018, 7025525, 613238, 7070156, 6161950,
+	7921677, 6458423, 4040196, 4908348, 2039144, 6500539, 7561656, 6201452,
+	6757063, 2105286, 6006015, 6346610, 586241, 7200804, 527981, 5637006,
+	6903432, 1994046, 2491325, 6987258, 507927, 7192532, 7655613, 6545891,
+	5346675, 8041997, 2647994, 3009748, 5767564, 4148469, 749577, 4357667,
+	3980599, 2569011, 6764887, 1723229, 1665318, 2028038, 1163598, 5011144,
+	3994671, 8368538, 7009900, 3020393, 3363542, 214880, 545376, 7609976,
+	3105558, 7277073, 508145, 7826699, 860144, 3430436, 140244, 6866265,
+	6195333, 3123762, 2358373, 6187330, 5365997, 6663603, 2926054, 7987710,
+	8077412, 3531229, 4405932, 4606686, 1900052, 7598542, 1054478, 7648983,
+}
+
+// ntt maps a ringElement to its nttElement representation.
+//
+// It implements NTT, according to FIPS 204, Algorithm 41.
+func ntt(f ringElement) nttElement {
+	m := 0
+	for len := 128; len >= 1; len /= 2 {
+		for start := 0; start < n; start += 2 * len {
+			m++
+			zeta := zetas[m]
+			// Bounds check elimination hint.
+			f, flen := f[start:start+len], f[start+len:start+len+len]
+			for j := 0; j < len; j++ {
+				t := fieldMul(zeta, flen[j])
+				flen[j] = fieldSub(f[j], t)
+				f[j] = fieldAdd(f[j], t)
+			}
+		}
+	}
+	return nttElement(f)
+}
+
+// inverseNTT maps a nttElement back to the ringElement it represents.
+//
+// It implements NTT⁻¹, according to FIPS 204, Algorithm 42.
+func inverseNTT(f nttElement) ringElement {
+	m := n
+	for len := 1; len < n; len *= 2 {
+		for start := 0; start < n; start += 2 * len {
+			m--
+			zeta := q - zetas[m] // -ζ^BitRev₈(m)
+			// Bounds check elimination hint.
+			f, flen := f[start:start+len], f[start+len:start+len+len]
+			for j := 0; j < len; j++ {
+				t := f[j]
+				f[j] = fieldAdd(t, flen[j])
+				flen[j] = fieldMul(zeta, fieldSub(t, flen[j]))
+			}
+		}
+	}
+	for i := range f {
+		f[i] = fieldMul(f[i], 8347681) // 8347681 = 256⁻¹ mod q
+	}
+	return ringElement(f)
+}
+
+// power2Round splits r into r1 and r0 such that r = r1·2ᵈ + r0 and
+// -2ᵈ⁻¹ < r0 ≤ 2ᵈ⁻¹.
+//
+// It implements Power2Round, according to FIPS 204, Algorithm 35.
+func power2Round(r fieldElement) (r1 fieldElement, r0 fieldElement) {
+	x := uint32(r)
+	hi := (x + 1<<(d-1) - 1) >> d
+	lo := int32(x) - int32(hi<<d)
+	return fieldElement(hi), fieldFromInt32(lo)
+}
+
+// decompose splits r into r1 and r0 such that r = r1·(2γ₂) + r0 mod q and
+// -γ₂ < r0 ≤ γ₂, except that if r1·(2γ₂) would be q - 1, r1 is zero and r0
+// is reduced by one instead.
+//
+// It implements Decompose, according to FIPS 204, Algorithm 36, in constant
+// time for the two values of γ₂ used by ML-DSA.
+func decompose(r fieldElement, gamma2 uint32) (r1 uint32, r0 int32) {
+	x := int32(r)
+	hi := (x + 127) >> 7
+	switch gamma2 {
+	case (q - 1) / 32:
+		hi = (hi*1025 + 1<<21) >> 22
+		hi &= 15
+	case (q - 1) / 88:
+		hi = (hi*11275 + 1<<23) >> 24
+		// If hi is 44, (q-1)/(2γ₂), it wraps around to zero.
+		hi ^= ((43 - hi) >> 31) & hi
+	default:
+		panic("mldsa: internal error: unsupported γ₂")
+	}
+	lo := x - hi*2*int32(gamma2)
+	// If lo > (q-1)/2, it wrapped around and must be centered again.
+	lo -= ((int32((q-1)/2) - lo) >> 31) & q
+	return uint32(hi), lo
+}
+
+// highBits returns r1 from the output of Decompose(r).
+//
+// It implements HighBits, according to FIPS 204, Algorithm 37.
+func highBits(r fieldElement, gamma2 uint32) uint32 {
+	r1, _ := decompose(r, gamma2)
+	return r1
+}
+
+// makeHint returns 1 if adding z to r alters the high bits of r, and 0
+// otherwise.
+//
+// It implements MakeHint, according to FIPS 204, Algorithm 39.
+func makeHint(z, r fieldElement, gamma2 uint32) fieldElement {
+	r1 := highBits(r, gamma2)
+	v1 := highBits(fieldAdd(r, z), gamma2)
+	// r1 ^ v1 is non-zero if and only if they differ, in which case
+	// -(r1 ^ v1) has the top bit set.
+	return fieldElement(-(r1 ^ v1) >> 31)
+}
+
+// useHint returns the high bits of r adjusted according to hint h.
+//
+// It implements UseHint, according to FIPS 204, Algorithm 40. It is only used
+// on public values, so it doesn't need to run in constant time.
+func useHint(h, r fieldElement, gamma2 uint32) uint32 {
+	m := (q - 1) / (2 * gamma2)
+	r1, r0 := decompose(r, gamma2)
+	if h == 0 {
+		return r1
+	}
+	if r0 > 0 {
+		return (r1 + 1) % m
+	}
+	return (r1 + m - 1) % m
+}
+
+// rejNTTPoly samples an nttElement uniformly at random from the SHAKE128
+// stream seeded with ρ || s || r.
+//
+// It implements RejNTTPoly, according to FIPS 204, Algorithm 30.
+func rejNTTPoly(rho *[32]byte, s, r byte) nttElement {
+	G := sha3.NewShake128()
+	G.Write(rho[:])
+	G.Write([]byte{s, r})
+
+	var a nttElement
+	var j int
+	var buf [168]byte // one SHAKE128 block
+	off := len(buf)
+	for j < n {
+		if off >= len(buf) {
+			G.Read(buf[:])
+			off = 0
+		}
+		// CoeffFromThreeBytes, FIPS 204, Algorithm 14.
+		z := uint32(buf[off]) | uint32(buf[off+1])<<8 | uint32(buf[off+2]&0x7f)<<16
+		off += 3
+		if z < q {
+			a[j] = fieldElement(z)
+			j++
+		}
+	}
+	return a
+}
+
+// rejBoundedPoly samples a ringElement with coefficients in [-η, η] from the
+// SHAKE256 stream seeded with ρ' || IntegerToBytes(r, 2).
+//
+// It implements RejBoundedPoly, according to FIPS 204, Algorithm 31.
+func rejBoundedPoly(rhoPrime *[64]byte, r uint16, eta int) ringElement {
+	H := sha3.NewShake256()
+	H.Write(rhoPrime[:])
+	H.Write([]byte{byte(r), byte(r >> 8)})
+
+	var a ringElement
+	var j int
+	var buf [136]byte // one SHAKE256 block
+	off := len(buf)
+	for j < n {
+		if off >= len(buf) {
+			H.Read(buf[:])
+			off = 0
+		}
+		z := buf[off]
+		off++
+		for _, b := range [2]byte{z & 0x0f, z >> 4} {
+			if j >= n {
+				break
+			}
+			// CoeffFromHalfByte, FIPS 204, Algorithm 15.
+			switch {
+			case eta == 2 && b < 15:
+				a[j] = fieldFromInt32(2 - int32(b%5))
+				j++
+			case eta == 4 && b < 9:
+				a[j] = fieldFromInt32(4 - int32(b))
+				j++
+			}
+		}
+	}
+	return a
+}
+
+// expandMask derives the r-th polynomial of the masking vector y, with
+// coefficients in [-γ₁+1, γ₁], from ρ′′ and the counter κ + r.
+//
+// It implements one iteration of ExpandMask, according to FIPS 204,
+// Algorithm 34.
+func expandMask(rhoPrimePrime *[64]byte, kr uint16, gamma1Bits int) ringElement {
+	H := sha3.NewShake256()
+	H.Write(rhoPrimePrime[:])
+	H.Write([]byte{byte(kr), byte(kr >> 8)})
+	c := gamma1Bits + 1
+	v := make([]byte, 32*c)
+	H.Read(v)
+	f, _ := bitUnpack(v, 1<<gamma1Bits, c)
+	return f
+}
+
+// sampleInBall samples a polynomial with τ coefficients in {-1, 1} and the
+// rest zero, from the SHAKE256 stream seeded with ρ.
+//
+// It implements SampleInBall, according to FIPS 204, Algorithm 29. The
+// challenge is part of the signature, so this doesn't need to run in
+// constant time.
+func sampleInBall(rho []byte, tau int) ringElement {
+	H := sha3.NewShake256()
+	H.Write(rho)
+	var s [8]byte
+	H.Read(s[:])
+	signs := uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+		uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+
+	var c ringElement
+	var buf [1]byte
+	for i := n - tau; i < n; i++ {
+		H.Read(buf[:])
+		for int(buf[0]) > i {
+			H.Read(buf[:])
+		}
+		j := buf[0]
+		c[i] = c[j]
+		if signs&1 == 0 {
+			c[j] = 1
+		} else {
+			c[j] = q - 1
+		}
+		signs >>= 1
+	}
+	return c
+}
+
+// simpleBitPack appends the encoding of f to b, using bits bits for each
+// coefficient, which must be in [0, 2^bits).
+//
+// It implements SimpleBitPack, according to FIPS 204, Algorithm 16.
+func simpleBitPack(b []byte, f ringElement, bits int) []byte {
+	out, B := sliceForAppend(b, 32*bits)
+	var acc uint64
+	var accBits int
+	for _, x := range f {
+		acc |= uint64(x) << accBits
+		accBits += bits
+		for accBits >= 8 {
+			B[0] = byte(acc)
+			B = B[1:]
+			acc >>= 8
+			accBits -= 8
+		}
+	}
+	return out
+}
+
+// bitPack appends the encoding of f to b, using bits bits for each
+// coefficient, which must be in [bound - 2^bits + 1, bound].
+//
+// It implements BitPack, according to FIPS 204, Algorithm 17.
+func bitPack(b []byte, f ringElement, bound fieldElement, bits int) []byte {
+	for i := range f {
+		f[i] = fieldSub(bound, f[i])
+	}
+	return simpleBitPack(b, f, bits)
+}
+
+// simpleBitUnpack decodes a polynomial from 32·bits bytes, returning an error
+// if any coefficient is not less than max.
+//
+// It implements SimpleBitUnpack, according to FIPS 204, Algorithm 18.
+func simpleBitUnpack(b []byte, bits int, max uint32) (ringElement, error) {
+	if len(b) != 32*bits {
+		return ringElement{}, errors.New("mldsa: invalid encoding length")
+	}
+	var f ringElement
+	var acc uint64
+	var accBits int
+	mask := uint64(1)<<bits - 1
+	for i := range f {
+		for accBits < bits {
+			acc |= uint64(b[0]) << accBits
+			b = b[1:]
+			accBits += 8
+		}
+		x := uint32(acc & mask)
+		if x >= max {
+			return ringElement{}, errors.New("mldsa: invalid polynomial encoding")
+		}
+		f[i] = fieldElement(x)
+		acc >>= bits
+		accBits -= bits
+	}
+	return f, nil
+}
+
+// bitUnpack decodes a polynomial from 32·bits bytes, with coefficients in
+// [bound - 2^bits + 1, bound].
+//
+// It implements BitUnpack, according to FIPS 204, Algorithm 19.
+func bitUnpack(b []byte, bound fieldElement, bits int) (ringElement, error) {
+	f, err := simpleBitUnpack(b, bits, 1<<bits)
+	if err != nil {
+		return ringElement{}, err
+	}
+	for i := range f {
+		f[i] = fieldSub(bound, f[i])
+	}
+	return f, nil
+}
+
+// sliceForAppend takes a slice and a requested number of bytes. It returns a
+// slice with the contents of the given slice followed by that many bytes and a
+// second slice that aliases into it and contains only the extra bytes. If the
+// original slice has sufficient capacity then no allocation is performed.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
@@ -0,0 +1,532 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mldsa implements the quantum-resistant digital signature algorithm
+// ML-DSA (Module-Lattice-Based Digital Signature Algorithm) as specified in
+// [NIST FIPS 204].
+//
+// [NIST FIPS 204]: https://doi.org/10.6028/NIST.FIPS.204
+package mldsa
+
+import (
+	"bytes"
+	"crypto/internal/fips140"
+	"crypto/internal/fips140/drbg"
+	"crypto/internal/fips140/sha3"
+	"errors"
+)
+
+const (
+	// SeedSize is the size of the private key seed ξ.
+	SeedSize = 32
+
+	// muSize is the size of the message representative μ.
+	muSize = 64
+)
+
+// Parameters is an ML-DSA parameter set, according to FIPS 204, Section 4.
+type Parameters struct {
+	name       string
+	k, l       int    // dimensions of the matrix A
+	eta        int    // private key range
+	tau        int    // number of ±1s in the challenge polynomial
+	lambda     int    // collision strength of the commitment hash c̃
+	gamma1Bits int    // log₂ of the coefficient range of y
+	gamma2     uint32 // low-order rounding range
+	omega      int    // maximum number of 1s in the hint h
+}
+
+var (
+	params44 = &Parameters{name: "ML-DSA-44", k: 4, l: 4, eta: 2, tau: 39, lambda: 128, gamma1Bits: 17, gamma2: (q - 1) / 88, omega: 80}
+	params65 = &Parameters{name: "ML-DSA-65", k: 6, l: 5, eta: 4, tau: 49, lambda: 192, gamma1Bits: 19, gamma2: (q - 1) / 32, omega: 55}
+	params87 = &Parameters{name: "ML-DSA-87", k: 8, l: 7, eta: 2, tau: 60, lambda: 256, gamma1Bits: 19, gamma2: (q - 1) / 32, omega: 75}
+)
+
+// MLDSA44 returns the ML-DSA-44 parameter set.
+func MLDSA44() *Parameters { return params44 }
+
+// MLDSA65 returns the ML-DSA-65 parameter set.
+func MLDSA65() *Parameters { return params65 }
+
+// MLDSA87 returns the ML-DSA-87 parameter set.
+func MLDSA87() *Parameters { return params87 }
+
+// String returns the name of the parameter set, such as "ML-DSA-44".
+func (p *Parameters) String() string { return p.name }
+
+// PublicKeySize returns the size of encoded public keys.
+func (p *Parameters) PublicKeySize() int {
+	return 32 + 32*p.k*(23-d)
+}
+
+// SignatureSize returns the size of signatures.
+func (p *Parameters) SignatureSize() int {
+	return p.lambda/4 + 32*p.l*(p.gamma1Bits+1) + p.omega + p.k
+}
+
+// etaBits returns the size in bits of the encoding of s1 and s2 coefficients.
+func (p *Parameters) etaBits() int {
+	if p.eta == 2 {
+		return 3
+	}
+	return 4
+}
+
+// w1Bits returns the size in bits of the encoding of w1 coefficients.
+func (p *Parameters) w1Bits() int {
+	if p.gamma2 == (q-1)/88 {
+		return 6
+	}
+	return 4
+}
+
+// beta returns β = τ·η, the maximum coefficient of c·s1 and c·s2.
+func (p *Parameters) beta() uint32 {
+	return uint32(p.tau * p.eta)
+}
+
+// A PrivateKey is an ML-DSA private key, expanded from a seed.
+type PrivateKey struct {
+	seed [SeedSize]byte // ξ
+	pub  PublicKey
+	key  [32]byte     // K
+	s1   []nttElement // NTT(s1), l elements
+	s2   []nttElement // NTT(s2), k elements
+	t0   []nttElement // NTT(t0), k elements
+}
+
+// A PublicKey is an ML-DSA public key.
+type PublicKey struct {
+	p   *Parameters
+	raw []byte // pkEncode(ρ, t1)
+
+	tr [64]byte     // H(pk, 64)
+	a  []nttElement // Â = ExpandA(ρ), k × l elements in row-major order
+	t1 []nttElement // NTT(t1 · 2ᵈ), k elements
+}
+
+// Bytes returns the private key seed.
+func (priv *PrivateKey) Bytes() []byte {
+	b := priv.seed
+	return b[:]
+}
+
+// PublicKey returns the public key corresponding to priv.
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	return &priv.pub
+}
+
+// ExpandedBytes returns the private key in the skEncode format of FIPS 204,
+// Algorithm 24.
+func (priv *PrivateKey) ExpandedBytes() []byte {
+	p := priv.pub.p
+	b := make([]byte, 0, 128+32*((p.k+p.l)*p.etaBits()+d*p.k))
+	b = append(b, priv.pub.raw[:32]...) // ρ
+	b = append(b, priv.key[:]...)
+	b = append(b, priv.pub.tr[:]...)
+	for i := range priv.s1 {
+		b = bitPack(b, inverseNTT(priv.s1[i]), fieldElement(p.eta), p.etaBits())
+	}
+	for i := range priv.s2 {
+		b = bitPack(b, inverseNTT(priv.s2[i]), fieldElement(p.eta), p.etaBits())
+	}
+	for i := range priv.t0 {
+		b = bitPack(b, inverseNTT(priv.t0[i]), 1<<(d-1), d)
+	}
+	return b
+}
+
+// Parameters returns the parameter set of pub.
+func (pub *PublicKey) Parameters() *Parameters {
+	return pub.p
+}
+
+// Bytes returns the encoded public key.
+func (pub *PublicKey) Bytes() []byte {
+	return bytes.Clone(pub.raw)
+}
+
+// Equal reports whether pub and x are the same public key.
+func (pub *PublicKey) Equal(x *PublicKey) bool {
+	return pub.p == x.p && bytes.Equal(pub.raw, x.raw)
+}
+
+// GenerateKey generates a new private key, drawing random bytes from a DRBG.
+func GenerateKey(p *Parameters) (*PrivateKey, error) {
+	fips140.RecordApproved()
+	var seed [SeedSize]byte
+	drbg.Read(seed[:])
+	priv := newPrivateKey(p, &seed)
+	if err := fipsPCT(priv); err != nil {
+		// This clearly can't happen, but FIPS 140-3 requires us to check.
+		panic(err)
+	}
+	return priv, nil
+}
+
+// NewPrivateKey expands a private key from a 32-byte seed. The seed must be
+// uniformly random.
+func NewPrivateKey(p *Parameters, seed []byte) (*PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("mldsa: invalid seed length")
+	}
+	priv := newPrivateKey(p, (*[SeedSize]byte)(seed))
+	if err := fipsPCT(priv); err != nil {
+		// This clearly can't happen, but FIPS 140-3 requires us to check.
+		panic(err)
+	}
+	return priv, nil
+}
+
+// newPrivateKey expands a private key from seed ξ.
+//
+// It implements ML-DSA.KeyGen_internal, according to FIPS 204, Algorithm 6.
+func newPrivateKey(p *Parameters, seed *[SeedSize]byte) *PrivateKey {
+	priv := &PrivateKey{seed: *seed}
+
+	H := sha3.NewShake256()
+	H.Write(seed[:])
+	H.Write([]byte{byte(p.k), byte(p.l)})
+	var rho [32]byte
+	var rhoPrime [64]byte
+	H.Read(rho[:])
+	H.Read(rhoPrime[:])
+	H.Read(priv.key[:])
+
+	a := expandA(p, &rho)
+	priv.s1 = make([]nttElement, p.l)
+	for r := range priv.s1 {
+		priv.s1[r] = ntt(rejBoundedPoly(&rhoPrime, uint16(r), p.eta))
+	}
+	s2 := make([]ringElement, p.k)
+	for r := range s2 {
+		s2[r] = rejBoundedPoly(&rhoPrime, uint16(p.l+r), p.eta)
+	}
+
+	raw := make([]byte, 0, p.PublicKeySize())
+	raw = append(raw, rho[:]...)
+	priv.s2 = make([]nttElement, p.k)
+	priv.t0 = make([]nttElement, p.k)
+	t1 := make([]nttElement, p.k)
+	for i := range p.k {
+		var acc nttElement
+		for j := range p.l {
+			acc = polyAdd(acc, nttMul(a[i*p.l+j], priv.s1[j]))
+		}
+		t := polyAdd(inverseNTT(acc), s2[i])
+		var hi, lo ringElement
+		for c := range t {
+			hi[c], lo[c] = power2Round(t[c])
+		}
+		raw = simpleBitPack(raw, hi, 23-d)
+		for c := range hi {
+			hi[c] <<= d
+		}
+		t1[i] = ntt(hi)
+		priv.t0[i] = ntt(lo)
+		priv.s2[i] = ntt(s2[i])
+	}
+
+	priv.pub = PublicKey{p: p, raw: raw, a: a, t1: t1}
+	H.Reset()
+	H.Write(raw)
+	H.Read(priv.pub.tr[:])
+	return priv
+}
+
+// NewPublicKey parses an encoded public key.
+//
+// It implements pkDecode, according to FIPS 204, Algorithm 23.
+func NewPublicKey(p *Parameters, b []byte) (*PublicKey, error) {
+	if len(b) != p.PublicKeySize() {
+		return nil, errors.New("mldsa: invalid public key length")
+	}
+	pub := &PublicKey{p: p, raw: bytes.Clone(b)}
+	rho := (*[32]byte)(b[:32])
+	pub.a = expandA(p, rho)
+	pub.t1 = make([]nttElement, p.k)
+	const size = 32 * (23 - d)
+	for i := range pub.t1 {
+		t1, err := simpleBitUnpack(b[32+i*size:32+(i+1)*size], 23-d, 1<<(23-d))
+		if err != nil {
+			return nil, err
+		}
+		for c := range t1 {
+			t1[c] <<= d
+		}
+		pub.t1[i] = ntt(t1)
+	}
+	H := sha3.NewShake256()
+	H.Write(b)
+	H.Read(pub.tr[:])
+	return pub, nil
+}
+
+// expandA generates the matrix Â from seed ρ.
+//
+// It implements ExpandA, according to FIPS 204, Algorithm 32.
+func expandA(p *Parameters, rho *[32]byte) []nttElement {
+	a := make([]nttElement, p.k*p.l)
+	for r := range p.k {
+		for s := range p.l {
+			a[r*p.l+s] = rejNTTPoly(rho, byte(s), byte(r))
+		}
+	}
+	return a
+}
+
+// Sign signs msg with priv in the given context, which must be at most 255
+// bytes long, using the hedged variant of ML-DSA with randomness from a DRBG.
+//
+// It implements ML-DSA.Sign, according to FIPS 204, Algorithm 2.
+func Sign(priv *PrivateKey, msg []byte, context string) ([]byte, error) {
+	fipsSelfTest()
+	fips140.RecordApproved()
+	mu, err := computeMu(&priv.pub, msg, context)
+	if err != nil {
+		return nil, err
+	}
+	var rnd [32]byte
+	drbg.Read(rnd[:])
+	return signInternal(priv, mu, &rnd), nil
+}
+
+// SignDeterministic is like [Sign], but uses the deterministic variant of
+// ML-DSA, which produces the same signature for the same key and message.
+func SignDeterministic(priv *PrivateKey, msg []byte, context string) ([]byte, error) {
+	fipsSelfTest()
+	fips140.RecordApproved()
+	mu, err := computeMu(&priv.pub, msg, context)
+	if err != nil {
+		return nil, err
+	}
+	return signInternal(priv, mu, &[32]byte{}), nil
+}
+
+// Verify verifies that sig is a valid signature of msg in the given context
+// by pub.
+//
+// It implements ML-DSA.Verify, according to FIPS 204, Algorithm 3.
+func Verify(pub *PublicKey, msg, sig []byte, context string) error {
+	fipsSelfTest()
+	fips140.RecordApproved()
+	mu, err := computeMu(pub, msg, context)
+	if err != nil {
+		return err
+	}
+	return verifyInternal(pub, mu, sig)
+}
+
+// computeMu computes the message representative μ = H(tr || M', 64), where
+// M' = IntegerToBytes(0, 1) || IntegerToBytes(|ctx|, 1) || ctx || M.
+func computeMu(pub *PublicKey, msg []byte, context string) (*[muSize]byte, error) {
+	if len(context) > 255 {
+		return nil, errors.New("mldsa: context too long")
+	}
+	H := sha3.NewShake256()
+	H.Write(pub.tr[:])
+	H.Write([]byte{0, byte(len(context))})
+	H.Write([]byte(context))
+	H.Write(msg)
+	var mu [muSize]byte
+	H.Read(mu[:])
+	return &mu, nil
+}
+
+// signInternal signs the message representative μ with randomness rnd.
+//
+// It implements ML-DSA.Sign_internal, according to FIPS 204, Algorithm 7,
+// starting from step 7, with the private key already decoded and transformed.
+func signInternal(priv *PrivateKey, mu *[muSize]byte, rnd *[32]byte) []byte {
+	p := priv.pub.p
+	beta := p.beta()
+	gamma1 := uint32(1) << p.gamma1Bits
+
+	H := sha3.NewShake256()
+	H.Write(priv.key[:])
+	H.Write(rnd[:])
+	H.Write(mu[:])
+	var rhoPrimePrime [64]byte
+	H.Read(rhoPrimePrime[:])
+
+	y := make([]ringElement, p.l)
+	yHat := make([]nttElement, p.l)
+	w := make([]ringElement, p.k)
+	z := make([]ringElement, p.l)
+	h := make([]ringElement, p.k)
+	w1 := make([]byte, 0, 32*p.k*p.w1Bits())
+	cTilde := make([]byte, p.lambda/4)
+
+	for kappa := 0; ; kappa += p.l {
+		for r := range p.l {
+			y[r] = expandMask(&rhoPrimePrime, uint16(kappa+r), p.gamma1Bits)
+			yHat[r] = ntt(y[r])
+		}
+		w1 = w1[:0]
+		for i := range p.k {
+			var acc nttElement
+			for j := range p.l {
+				acc = polyAdd(acc, nttMul(priv.pub.a[i*p.l+j], yHat[j]))
+			}
+			w[i] = inverseNTT(acc)
+			var hi ringElement
+			for j := range hi {
+				hi[j] = fieldElement(highBits(w[i][j], p.gamma2))
+			}
+			w1 = simpleBitPack(w1, hi, p.w1Bits())
+		}
+
+		H.Reset()
+		H.Write(mu[:])
+		H.Write(w1)
+		H.Read(cTilde)
+		c := ntt(sampleInBall(cTilde, p.tau))
+
+		reject := false
+		for r := range p.l {
+			z[r] = polyAdd(y[r], inverseNTT(nttMul(c, priv.s1[r])))
+			reject = reject || polyNormExceeds(z[r], gamma1-beta)
+		}
+		if reject {
+			continue
+		}
+
+		ones := 0
+		for i := range p.k {
+			r := polySub(w[i], inverseNTT(nttMul(c, priv.s2[i])))
+			var r0 ringElement
+			for j := range r {
+				_, lo := decompose(r[j], p.gamma2)
+				r0[j] = fieldFromInt32(lo)
+			}
+			if polyNormExceeds(r0, p.gamma2-beta) {
+				reject = true
+				break
+			}
+			ct0 := inverseNTT(nttMul(c, priv.t0[i]))
+			if polyNormExceeds(ct0, p.gamma2) {
+				reject = true
+				break
+			}
+			for j := range h[i] {
+				h[i][j] = makeHint(fieldSub(0, ct0[j]), fieldAdd(r[j], ct0[j]), p.gamma2)
+				ones += int(h[i][j])
+			}
+		}
+		if reject || ones > p.omega {
+			continue
+		}
+
+		return sigEncode(p, cTilde, z, h)
+	}
+}
+
+// sigEncode encodes a signature.
+//
+// It implements sigEncode, according to FIPS 204, Algorithm 26.
+func sigEncode(p *Parameters, cTilde []byte, z, h []ringElement) []byte {
+	sig := make([]byte, 0, p.SignatureSize())
+	sig = append(sig, cTilde...)
+	for i := range z {
+		sig = bitPack(sig, z[i], 1<<p.gamma1Bits, p.gamma1Bits+1)
+	}
+	// HintBitPack, FIPS 204, Algorithm 20.
+	sig, y := sliceForAppend(sig, p.omega+p.k)
+	index := 0
+	for i := range h {
+		for j := range h[i] {
+			if h[i][j] != 0 {
+				y[index] = byte(j)
+				index++
+			}
+		}
+		y[p.omega+i] = byte(index)
+	}
+	return sig
+}
+
+// verifyInternal verifies sig over the message representative μ.
+//
+// It implements ML-DSA.Verify_internal, according to FIPS 204, Algorithm 8,
+// starting from step 6, with the public key already decoded and transformed.
+func verifyInternal(pub *PublicKey, mu *[muSize]byte, sig []byte) error {
+	p := pub.p
+	if len(sig) != p.SignatureSize() {
+		return errors.New("mldsa: invalid signature length")
+	}
+
+	// sigDecode, FIPS 204, Algorithm 27.
+	cTilde, sig := sig[:p.lambda/4], sig[p.lambda/4:]
+	zHat := make([]nttElement, p.l)
+	zSize := 32 * (p.gamma1Bits + 1)
+	for i := range zHat {
+		z, err := bitUnpack(sig[:zSize], 1<<p.gamma1Bits, p.gamma1Bits+1)
+		if err != nil {
+			return err
+		}
+		if polyNormExceeds(z, 1<<p.gamma1Bits-p.beta()) {
+			return errors.New("mldsa: invalid signature")
+		}
+		zHat[i] = ntt(z)
+		sig = sig[zSize:]
+	}
+	h, err := hintBitUnpack(p, sig)
+	if err != nil {
+		return err
+	}
+
+	c := ntt(sampleInBall(cTilde, p.tau))
+	w1 := make([]byte, 0, 32*p.k*p.w1Bits())
+	for i := range p.k {
+		var acc nttElement
+		for j := range p.l {
+			acc = polyAdd(acc, nttMul(pub.a[i*p.l+j], zHat[j]))
+		}
+		acc = polySub(acc, nttMul(c, pub.t1[i]))
+		wApprox := inverseNTT(acc)
+		var hi ringElement
+		for j := range hi {
+			hi[j] = fieldElement(useHint(h[i][j], wApprox[j], p.gamma2))
+		}
+		w1 = simpleBitPack(w1, hi, p.w1Bits())
+	}
+
+	H := sha3.NewShake256()
+	H.Write(mu[:])
+	H.Write(w1)
+	cTildePrime := make([]byte, len(cTilde))
+	H.Read(cTildePrime)
+	if !bytes.Equal(cTilde, cTildePrime) {
+		return errors.New("mldsa: invalid signature")
+	}
+	return nil
+}
+
+// hintBitUnpack decodes the hint vector h, rejecting malformed and
+// non-canonical encodings.
+//
+// It implements HintBitUnpack, according to FIPS 204, Algorithm 21.
+func hintBitUnpack(p *Parameters, y []byte) ([]ringElement, error) {
+	h := make([]ringElement, p.k)
+	index := 0
+	for i := range h {
+		limit := int(y[p.omega+i])
+		if limit < index || limit > p.omega {
+			return nil, errors.New("mldsa: invalid signature hint")
+		}
+		first := index
+		for ; index < limit; index++ {
+			if index > first && y[index-1] >= y[index] {
+				return nil, errors.New("mldsa: invalid signature hint")
+			}
+			h[i][y[index]] = 1
+		}
+	}
+	for _, b := range y[index:p.omega] {
+		if b != 0 {
+			return nil, errors.New("mldsa: invalid signature hint")
+		}
+	}
+	return h, nil
+}
@@ -55,6 +55,8 @@ func deriveSecret[H hash.Hash](hash func() H, secret []byte, label string, trans
 
 const (
 	resumptionBinderLabel         = "res binder"
+	externalBinderLabel           = "ext binder"
+	importedBinderLabel           = "imp binder"
 	clientEarlyTrafficLabel       = "c e traffic"
 	clientHandshakeTrafficLabel   = "c hs traffic"
 	serverHandshakeTrafficLabel   = "s hs traffic"
@@ -81,6 +83,17 @@ func (s *EarlySecret) ResumptionBinderKey() []byte {
 	return deriveSecret(s.hash, s.secret, resumptionBinderLabel, nil)
 }
 
+// ExternalBinderKey derives the binder_key for an external PSK.
+func (s *EarlySecret) ExternalBinderKey() []byte {
+	return deriveSecret(s.hash, s.secret, externalBinderLabel, nil)
+}
+
+// ImportedBinderKey derives the binder_key for a PSK imported as specified
+// in RFC 9258, Section 5.2.
+func (s *EarlySecret) ImportedBinderKey() []byte {
+	return deriveSecret(s.hash, s.secret, importedBinderLabel, nil)
+}
+
 // ClientEarlyTrafficSecret derives the client_early_traffic_secret from the
 // early secret and the transcript up to the ClientHello.
 func (s *EarlySecret) ClientEarlyTrafficSecret(transcript hash.Hash) []byte {
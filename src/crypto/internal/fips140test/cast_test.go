@@ -26,6 +26,7 @@ import (
 	"crypto/internal/fips140/ed25519"
 	_ "crypto/internal/fips140/hkdf"
 	_ "crypto/internal/fips140/hmac"
+	"crypto/internal/fips140/mldsa"
 	"crypto/internal/fips140/mlkem"
 	"crypto/internal/fips140/rsa"
 	"crypto/internal/fips140/sha256"
@@ -48,6 +49,8 @@ var allCASTs = []string{
 	"HKDF-SHA2-256",
 	"HMAC-SHA2-256",
 	"KAS-ECC-SSC P-256",
+	"ML-DSA sign and verify PCT",
+	"ML-DSA-44 sign and verify",
 	"ML-KEM PCT",
 	"ML-KEM PCT",
 	"ML-KEM PCT",
@@ -107,6 +110,11 @@ func TestAllCASTs(t *testing.T) {
 // TestConditionals causes the conditional CASTs and PCTs to be invoked.
 func TestConditionals(t *testing.T) {
 	mlkem.GenerateKey768()
+	kMLDSA, err := mldsa.GenerateKey(mldsa.MLDSA44())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mldsa.Sign(kMLDSA, make([]byte, 32), "")
 	k, err := ecdh.GenerateKey(ecdh.P256(), rand.Reader)
 	if err != nil {
 		t.Fatal(err)
@@ -26,6 +26,7 @@ import (
 	"crypto/internal/fips140/ed25519"
 	"crypto/internal/fips140/hkdf"
 	"crypto/internal/fips140/hmac"
+	"crypto/internal/fips140/mldsa"
 	"crypto/internal/fips140/mlkem"
 	"crypto/internal/fips140/pbkdf2"
 	"crypto/internal/fips140/rsa"
@@ -238,6 +239,19 @@ func TestFIPS140(t *testing.T) {
 		}
 	})
 
+	t.Run("ML-DSA KeyGen, Sign, Verify", func(t *testing.T) {
+		ensureServiceIndicator(t)
+		k, err := mldsa.GenerateKey(mldsa.MLDSA65())
+		fatalIfErr(t, err)
+
+		sig, err := mldsa.Sign(k, plaintext, "")
+		fatalIfErr(t, err)
+		t.Logf("ML-DSA signature: %x", sig)
+
+		err = mldsa.Verify(k.PublicKey(), plaintext, sig, "")
+		fatalIfErr(t, err)
+	})
+
 	var rsaKey *rsa.PrivateKey
 	t.Run("RSA KeyGen", func(t *testing.T) {
 		ensureServiceIndicator(t)
@@ -5,25 +5,24 @@
 package hpke
 
 import (
-	"crypto"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdh"
 	"crypto/hkdf"
-	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
 	"errors"
+	"hash"
 	"internal/byteorder"
 	"math/bits"
 
 	"golang.org/x/crypto/chacha20poly1305"
 )
 
-// testingOnlyGenerateKey is only used during testing, to provide
-// a fixed test key to use when checking the RFC 9180 vectors.
-var testingOnlyGenerateKey func() (*ecdh.PrivateKey, error)
-
 type hkdfKDF struct {
-	hash crypto.Hash
+	id   uint16
+	hash func() hash.Hash
+	size int // Nh
 }
 
 func (kdf *hkdfKDF) LabeledExtract(sid []byte, salt []byte, label string, inputKey []byte) ([]byte, error) {
@@ -32,7 +31,7 @@ func (kdf *hkdfKDF) LabeledExtract(sid []byte, salt []byte, label string, inputK
 	labeledIKM = append(labeledIKM, sid...)
 	labeledIKM = append(labeledIKM, label...)
 	labeledIKM = append(labeledIKM, inputKey...)
-	return hkdf.Extract(kdf.hash.New, labeledIKM, salt)
+	return hkdf.Extract(kdf.hash, labeledIKM, salt)
 }
 
 func (kdf *hkdfKDF) LabeledExpand(suiteID []byte, randomKey []byte, label string, info []byte, length uint16) ([]byte, error) {
@@ -42,97 +41,23 @@ func (kdf *hkdfKDF) LabeledExpand(suiteID []byte, randomKey []byte, label string
 	labeledInfo = append(labeledInfo, suiteID...)
 	labeledInfo = append(labeledInfo, label...)
 	labeledInfo = append(labeledInfo, info...)
-	return hkdf.Expand(kdf.hash.New, randomKey, string(labeledInfo), int(length))
-}
-
-// dhKEM implements the KEM specified in RFC 9180, Section 4.1.
-type dhKEM struct {
-	dh  ecdh.Curve
-	kdf hkdfKDF
-
-	suiteID []byte
-	nSecret uint16
-}
-
-type KemID uint16
-
-const DHKEM_X25519_HKDF_SHA256 = 0x0020
-
-var SupportedKEMs = map[uint16]struct {
-	curve   ecdh.Curve
-	hash    crypto.Hash
-	nSecret uint16
-}{
-	// RFC 9180 Section 7.1
-	DHKEM_X25519_HKDF_SHA256: {ecdh.X25519(), crypto.SHA256, 32},
-}
-
-func newDHKem(kemID uint16) (*dhKEM, error) {
-	suite, ok := SupportedKEMs[kemID]
-	if !ok {
-		return nil, errors.New("unsupported suite ID")
-	}
-	return &dhKEM{
-		dh:      suite.curve,
-		kdf:     hkdfKDF{suite.hash},
-		suiteID: byteorder.BEAppendUint16([]byte("KEM"), kemID),
-		nSecret: suite.nSecret,
-	}, nil
-}
-
-func (dh *dhKEM) ExtractAndExpand(dhKey, kemContext []byte) ([]byte, error) {
-	eaePRK, err := dh.kdf.LabeledExtract(dh.suiteID[:], nil, "eae_prk", dhKey)
-	if err != nil {
-		return nil, err
-	}
-	return dh.kdf.LabeledExpand(dh.suiteID[:], eaePRK, "shared_secret", kemContext, dh.nSecret)
-}
-
-func (dh *dhKEM) Encap(pubRecipient *ecdh.PublicKey) (sharedSecret []byte, encapPub []byte, err error) {
-	var privEph *ecdh.PrivateKey
-	if testingOnlyGenerateKey != nil {
-		privEph, err = testingOnlyGenerateKey()
-	} else {
-		privEph, err = dh.dh.GenerateKey(rand.Reader)
-	}
-	if err != nil {
-		return nil, nil, err
-	}
-	dhVal, err := privEph.ECDH(pubRecipient)
-	if err != nil {
-		return nil, nil, err
-	}
-	encPubEph := privEph.PublicKey().Bytes()
-
-	encPubRecip := pubRecipient.Bytes()
-	kemContext := append(encPubEph, encPubRecip...)
-	sharedSecret, err = dh.ExtractAndExpand(dhVal, kemContext)
-	if err != nil {
-		return nil, nil, err
-	}
-	return sharedSecret, encPubEph, nil
+	return hkdf.Expand(kdf.hash, randomKey, string(labeledInfo), int(length))
 }
 
-func (dh *dhKEM) Decap(encPubEph []byte, secRecipient *ecdh.PrivateKey) ([]byte, error) {
-	pubEph, err := dh.dh.NewPublicKey(encPubEph)
-	if err != nil {
-		return nil, err
-	}
-	dhVal, err := secRecipient.ECDH(pubEph)
-	if err != nil {
-		return nil, err
-	}
-	kemContext := append(encPubEph, secRecipient.PublicKey().Bytes()...)
-	return dh.ExtractAndExpand(dhVal, kemContext)
-}
+var (
+	hkdfSHA256 = &hkdfKDF{KDF_HKDF_SHA256, sha256.New, sha256.Size}
+	hkdfSHA384 = &hkdfKDF{KDF_HKDF_SHA384, sha512.New384, sha512.Size384}
+	hkdfSHA512 = &hkdfKDF{KDF_HKDF_SHA512, sha512.New, sha512.Size}
+)
 
 type context struct {
-	aead cipher.AEAD
+	aead cipher.AEAD // nil for export-only contexts
 
 	sharedSecret []byte
 
 	suiteID []byte
 
+	kdf            *hkdfKDF
 	key            []byte
 	baseNonce      []byte
 	exporterSecret []byte
@@ -162,6 +87,10 @@ const (
 	AEAD_AES_128_GCM      = 0x0001
 	AEAD_AES_256_GCM      = 0x0002
 	AEAD_ChaCha20Poly1305 = 0x0003
+
+	// AEAD_ExportOnly is the AEAD identifier of contexts that can only be
+	// used to export secrets. See RFC 9180, Section 5.3.
+	AEAD_ExportOnly = 0xFFFF
 )
 
 var SupportedAEADs = map[uint16]struct {
@@ -177,14 +106,32 @@ var SupportedAEADs = map[uint16]struct {
 
 type KDFID uint16
 
-const KDF_HKDF_SHA256 = 0x0001
+const (
+	KDF_HKDF_SHA256 = 0x0001
+	KDF_HKDF_SHA384 = 0x0002
+	KDF_HKDF_SHA512 = 0x0003
+)
 
 var SupportedKDFs = map[uint16]func() *hkdfKDF{
 	// RFC 9180, Section 7.2
-	KDF_HKDF_SHA256: func() *hkdfKDF { return &hkdfKDF{crypto.SHA256} },
+	KDF_HKDF_SHA256: func() *hkdfKDF { return hkdfSHA256 },
+	KDF_HKDF_SHA384: func() *hkdfKDF { return hkdfSHA384 },
+	KDF_HKDF_SHA512: func() *hkdfKDF { return hkdfSHA512 },
 }
 
-func newContext(sharedSecret []byte, kemID, kdfID, aeadID uint16, info []byte) (*context, error) {
+// HPKE modes, as specified in RFC 9180, Section 5.
+const (
+	modeBase    = 0x00
+	modePSK     = 0x01
+	modeAuth    = 0x02
+	modeAuthPSK = 0x03
+)
+
+// minPSKSize is the minimum size of a PSK. RFC 9180, Section 5.1.2 requires
+// PSKs to have at least 32 bytes of entropy.
+const minPSKSize = 32
+
+func newContext(mode uint8, sharedSecret []byte, kemID, kdfID, aeadID uint16, info, psk, pskID []byte) (*context, error) {
 	sid := suiteID(kemID, kdfID, aeadID)
 
 	kdfInit, ok := SupportedKDFs[kdfID]
@@ -194,11 +141,22 @@ func newContext(sharedSecret []byte, kemID, kdfID, aeadID uint16, info []byte) (
 	kdf := kdfInit()
 
 	aeadInfo, ok := SupportedAEADs[aeadID]
-	if !ok {
+	if !ok && aeadID != AEAD_ExportOnly {
 		return nil, errors.New("unsupported AEAD id")
 	}
 
-	pskIDHash, err := kdf.LabeledExtract(sid, nil, "psk_id_hash", nil)
+	// VerifyPSKInputs, RFC 9180, Section 5.1.
+	if (len(psk) == 0) != (len(pskID) == 0) {
+		return nil, errors.New("inconsistent PSK inputs")
+	}
+	if len(psk) != 0 && len(psk) < minPSKSize {
+		return nil, errors.New("PSK is too short")
+	}
+	if hasPSK := mode == modePSK || mode == modeAuthPSK; hasPSK != (len(psk) != 0) {
+		return nil, errors.New("PSK input provided when not needed, or missing when needed")
+	}
+
+	pskIDHash, err := kdf.LabeledExtract(sid, nil, "psk_id_hash", pskID)
 	if err != nil {
 		return nil, err
 	}
@@ -206,74 +164,137 @@ func newContext(sharedSecret []byte, kemID, kdfID, aeadID uint16, info []byte) (
 	if err != nil {
 		return nil, err
 	}
-	ksContext := append([]byte{0}, pskIDHash...)
+	ksContext := append([]byte{mode}, pskIDHash...)
 	ksContext = append(ksContext, infoHash...)
 
-	secret, err := kdf.LabeledExtract(sid, sharedSecret, "secret", nil)
+	secret, err := kdf.LabeledExtract(sid, sharedSecret, "secret", psk)
 	if err != nil {
 		return nil, err
 	}
-	key, err := kdf.LabeledExpand(sid, secret, "key", ksContext, uint16(aeadInfo.keySize) /* Nk - key size for AEAD */)
+	exporterSecret, err := kdf.LabeledExpand(sid, secret, "exp", ksContext, uint16(kdf.size) /* Nh - hash output size of the kdf*/)
 	if err != nil {
 		return nil, err
 	}
-	baseNonce, err := kdf.LabeledExpand(sid, secret, "base_nonce", ksContext, uint16(aeadInfo.nonceSize) /* Nn - nonce size for AEAD */)
+	ctx := &context{
+		sharedSecret:   sharedSecret,
+		suiteID:        sid,
+		kdf:            kdf,
+		exporterSecret: exporterSecret,
+	}
+	if aeadID == AEAD_ExportOnly {
+		return ctx, nil
+	}
+
+	ctx.key, err = kdf.LabeledExpand(sid, secret, "key", ksContext, uint16(aeadInfo.keySize) /* Nk - key size for AEAD */)
 	if err != nil {
 		return nil, err
 	}
-	exporterSecret, err := kdf.LabeledExpand(sid, secret, "exp", ksContext, uint16(kdf.hash.Size()) /* Nh - hash output size of the kdf*/)
+	ctx.baseNonce, err = kdf.LabeledExpand(sid, secret, "base_nonce", ksContext, uint16(aeadInfo.nonceSize) /* Nn - nonce size for AEAD */)
 	if err != nil {
 		return nil, err
 	}
-
-	aead, err := aeadInfo.aead(key)
+	ctx.aead, err = aeadInfo.aead(ctx.key)
 	if err != nil {
 		return nil, err
 	}
-
-	return &context{
-		aead:           aead,
-		sharedSecret:   sharedSecret,
-		suiteID:        sid,
-		key:            key,
-		baseNonce:      baseNonce,
-		exporterSecret: exporterSecret,
-	}, nil
+	return ctx, nil
 }
 
+// SetupSender sets up a base mode sending context for one of the
+// [SupportedKEMs].
 func SetupSender(kemID, kdfID, aeadID uint16, pub *ecdh.PublicKey, info []byte) ([]byte, *Sender, error) {
-	kem, err := newDHKem(kemID)
-	if err != nil {
-		return nil, nil, err
+	if _, ok := SupportedKEMs[kemID]; !ok {
+		return nil, nil, errors.New("unsupported suite ID")
 	}
-	sharedSecret, encapsulatedKey, err := kem.Encap(pub)
+	kem, err := NewKEM(kemID)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	context, err := newContext(sharedSecret, kemID, kdfID, aeadID, info)
+	pk, err := newDHKEMPublicKey(kem.(*dhKEM), pub)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	return encapsulatedKey, &Sender{context}, nil
+	return NewSender(pk, nil, kdfID, aeadID, info, nil, nil)
 }
 
+// SetupRecipient sets up a base mode receiving context for one of the
+// [SupportedKEMs].
 func SetupRecipient(kemID, kdfID, aeadID uint16, priv *ecdh.PrivateKey, info, encPubEph []byte) (*Recipient, error) {
-	kem, err := newDHKem(kemID)
+	if _, ok := SupportedKEMs[kemID]; !ok {
+		return nil, errors.New("unsupported suite ID")
+	}
+	kem, err := NewKEM(kemID)
 	if err != nil {
 		return nil, err
 	}
-	sharedSecret, err := kem.Decap(encPubEph, priv)
+	sk, err := newDHKEMPrivateKey(kem.(*dhKEM), priv)
 	if err != nil {
 		return nil, err
 	}
+	return NewRecipient(encPubEph, sk, nil, kdfID, aeadID, info, nil, nil)
+}
 
-	context, err := newContext(sharedSecret, kemID, kdfID, aeadID, info)
+// NewSender sets up a sending context for the recipient public key pk,
+// returning the encapsulated key to send to the recipient.
+//
+// If skS is not nil, the Auth mode is used, authenticating the context with
+// the sender private key skS. If psk and pskID are not empty, the PSK mode is
+// used. If both are provided, the AuthPSK mode is used. See RFC 9180, Section 5.
+func NewSender(pk PublicKey, skS PrivateKey, kdfID, aeadID uint16, info, psk, pskID []byte) (enc []byte, s *Sender, err error) {
+	mode := uint8(modeBase)
+	var sharedSecret []byte
+	if skS != nil {
+		mode = modeAuth
+		pk, ok := pk.(authPublicKey)
+		if !ok || skS.KEM() != pk.KEM() {
+			return nil, nil, errors.New("KEM does not support authentication")
+		}
+		sharedSecret, enc, err = pk.authEncap(skS)
+	} else {
+		sharedSecret, enc, err = pk.encap()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(psk) != 0 || len(pskID) != 0 {
+		mode |= modePSK
+	}
+
+	context, err := newContext(mode, sharedSecret, pk.KEM().ID(), kdfID, aeadID, info, psk, pskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, &Sender{context}, nil
+}
+
+// NewRecipient sets up the receiving context matching a sending context set up
+// by [NewSender] with the public key of sk, which returned enc. pkS, psk, and
+// pskID must match the inputs of NewSender.
+func NewRecipient(enc []byte, sk PrivateKey, pkS PublicKey, kdfID, aeadID uint16, info, psk, pskID []byte) (*Recipient, error) {
+	mode := uint8(modeBase)
+	var sharedSecret []byte
+	var err error
+	if pkS != nil {
+		mode = modeAuth
+		sk, ok := sk.(authPrivateKey)
+		if !ok || pkS.KEM() != sk.KEM() {
+			return nil, errors.New("KEM does not support authentication")
+		}
+		sharedSecret, err = sk.authDecap(enc, pkS)
+	} else {
+		sharedSecret, err = sk.decap(enc)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if len(psk) != 0 || len(pskID) != 0 {
+		mode |= modePSK
+	}
 
+	context, err := newContext(mode, sharedSecret, sk.KEM().ID(), kdfID, aeadID, info, psk, pskID)
+	if err != nil {
+		return nil, err
+	}
 	return &Recipient{context}, nil
 }
 
@@ -295,12 +316,18 @@ func (ctx *context) incrementNonce() {
 }
 
 func (s *Sender) Seal(aad, plaintext []byte) ([]byte, error) {
+	if s.aead == nil {
+		return nil, errors.New("export-only context")
+	}
 	ciphertext := s.aead.Seal(nil, s.nextNonce(), plaintext, aad)
 	s.incrementNonce()
 	return ciphertext, nil
 }
 
 func (r *Recipient) Open(aad, ciphertext []byte) ([]byte, error) {
+	if r.aead == nil {
+		return nil, errors.New("export-only context")
+	}
 	plaintext, err := r.aead.Open(nil, r.nextNonce(), ciphertext, aad)
 	if err != nil {
 		return nil, err
@@ -309,6 +336,15 @@ func (r *Recipient) Open(aad, ciphertext []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
+// Export derives a secret of the given length from the context and
+// exporterContext, as specified in RFC 9180, Section 5.3.
+func (ctx *context) Export(exporterContext []byte, length int) ([]byte, error) {
+	if length < 0 || length > 255*ctx.kdf.size {
+		return nil, errors.New("invalid export length")
+	}
+	return ctx.kdf.LabeledExpand(ctx.suiteID, ctx.exporterSecret, "sec", exporterContext, uint16(length))
+}
+
 func suiteID(kemID, kdfID, aeadID uint16) []byte {
 	suiteID := make([]byte, 0, 4+2+2+2)
 	suiteID = append(suiteID, []byte("HPKE")...)
@@ -6,16 +6,16 @@ package hpke
 
 import (
 	"bytes"
+	"crypto/ecdh"
+	"crypto/sha3"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
-
-	"crypto/ecdh"
-	_ "crypto/sha256"
-	_ "crypto/sha512"
 )
 
 func mustDecodeHex(t *testing.T, in string) []byte {
@@ -193,3 +193,330 @@ func TestRFC9180Vectors(t *testing.T) {
 		})
 	}
 }
+
+// TestVectors checks the base mode vectors of RFC 9180 for all supported
+// DHKEMs, KDFs, and AEADs, and those of draft-ietf-hpke-pq-03 for the ML-KEM
+// based KEMs. To keep the test data small, the RFC 9180 vectors were
+// regenerated to accumulate the results of 1000 random encryptions and exports
+// into a SHAKE128 hash, rather than listing them.
+func TestVectors(t *testing.T) {
+	t.Run("rfc9180", func(t *testing.T) {
+		testVectors(t, "testdata/rfc9180-accumulated.json")
+	})
+	t.Run("hpke-pq", func(t *testing.T) {
+		testVectors(t, "testdata/hpke-pq.json")
+	})
+}
+
+func testVectors(t *testing.T, file string) {
+	vectorsJSON, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []struct {
+		Mode        uint8  `json:"mode"`
+		KEM         uint16 `json:"kem_id"`
+		KDF         uint16 `json:"kdf_id"`
+		AEAD        uint16 `json:"aead_id"`
+		Info        string `json:"info"`
+		IkmE        string `json:"ikmE"`
+		IkmR        string `json:"ikmR"`
+		SkRm        string `json:"skRm"`
+		PkRm        string `json:"pkRm"`
+		Enc         string `json:"enc"`
+		Encryptions []struct {
+			Aad string `json:"aad"`
+			Ct  string `json:"ct"`
+			Pt  string `json:"pt"`
+		} `json:"encryptions"`
+		Exports []struct {
+			Context string `json:"exporter_context"`
+			L       int    `json:"L"`
+			Value   string `json:"exported_value"`
+		} `json:"exports"`
+		AccEncryptions string `json:"encryptions_accumulated"`
+		AccExports     string `json:"exports_accumulated"`
+	}
+	if err := json.Unmarshal(vectorsJSON, &vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vector := range vectors {
+		name := fmt.Sprintf("kem %04x kdf %04x aead %04x", vector.KEM, vector.KDF, vector.AEAD)
+		t.Run(name, func(t *testing.T) {
+			kem, err := NewKEM(vector.KEM)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pkR, err := kem.NewPublicKey(mustDecodeHex(t, vector.PkRm))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := pkR.Bytes(), mustDecodeHex(t, vector.PkRm); !bytes.Equal(got, want) {
+				t.Errorf("public key round-trip: got %x, want %x", got, want)
+			}
+			skR, err := kem.NewPrivateKey(mustDecodeHex(t, vector.SkRm))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := skR.PublicKey().Bytes(), pkR.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("private key has public key %x, want %x", got, want)
+			}
+			derived, err := kem.DeriveKeyPair(mustDecodeHex(t, vector.IkmR))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := derived.PublicKey().Bytes(), pkR.Bytes(); !bytes.Equal(got, want) {
+				t.Errorf("DeriveKeyPair public key = %x, want %x", got, want)
+			}
+			got, err := derived.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// SerializePrivateKey clamps X25519 keys, but the vectors don't.
+			if want := mustDecodeHex(t, vector.SkRm); !bytes.Equal(got, want) && vector.KEM != DHKEM_X25519_HKDF_SHA256 {
+				t.Errorf("DeriveKeyPair private key = %x, want %x", got, want)
+			}
+
+			setupDerandomizedEncap(t, vector.KEM, mustDecodeHex(t, vector.IkmE))
+
+			info := mustDecodeHex(t, vector.Info)
+			enc, sender, err := NewSender(pkR, nil, vector.KDF, vector.AEAD, info, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustDecodeHex(t, vector.Enc); !bytes.Equal(enc, want) {
+				t.Errorf("encapsulated key = %x, want %x", enc, want)
+			}
+			if len(enc) != kem.EncapsulatedKeySize() {
+				t.Errorf("encapsulated key size = %d, want %d", len(enc), kem.EncapsulatedKeySize())
+			}
+			recipient, err := NewRecipient(enc, skR, nil, vector.KDF, vector.AEAD, info, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if vector.AccEncryptions != "" && vector.AEAD != AEAD_ExportOnly {
+				source, sink := sha3.NewSHAKE128(), sha3.NewSHAKE128()
+				for range 1000 {
+					aad, plaintext := drawRandomInput(t, source), drawRandomInput(t, source)
+					ciphertext := testSealOpen(t, sender, recipient, aad, plaintext)
+					sink.Write(ciphertext)
+				}
+				if got, want := readAccumulator(sink), mustDecodeHex(t, vector.AccEncryptions); !bytes.Equal(got, want) {
+					t.Errorf("accumulated encryptions = %x, want %x", got, want)
+				}
+			} else if vector.AEAD == AEAD_ExportOnly {
+				if _, err := sender.Seal(nil, nil); err == nil {
+					t.Error("Seal succeeded on an export-only context")
+				}
+				if _, err := recipient.Open(nil, nil); err == nil {
+					t.Error("Open succeeded on an export-only context")
+				}
+			}
+			for _, e := range vector.Encryptions {
+				ciphertext := testSealOpen(t, sender, recipient, mustDecodeHex(t, e.Aad), mustDecodeHex(t, e.Pt))
+				if want := mustDecodeHex(t, e.Ct); !bytes.Equal(ciphertext, want) {
+					t.Errorf("ciphertext = %x, want %x", ciphertext, want)
+				}
+			}
+
+			if vector.AccExports != "" {
+				source, sink := sha3.NewSHAKE128(), sha3.NewSHAKE128()
+				for l := range 1000 {
+					context := drawRandomInput(t, source)
+					sink.Write(testExport(t, sender, recipient, context, l))
+				}
+				if got, want := readAccumulator(sink), mustDecodeHex(t, vector.AccExports); !bytes.Equal(got, want) {
+					t.Errorf("accumulated exports = %x, want %x", got, want)
+				}
+			}
+			for _, e := range vector.Exports {
+				value := testExport(t, sender, recipient, mustDecodeHex(t, e.Context), e.L)
+				if want := mustDecodeHex(t, e.Value); !bytes.Equal(value, want) {
+					t.Errorf("exported value = %x, want %x", value, want)
+				}
+			}
+		})
+	}
+}
+
+func setupDerandomizedEncap(t *testing.T, kemID uint16, ikmE []byte) {
+	t.Cleanup(func() {
+		testingOnlyGenerateKey = nil
+		testingOnlyEncapsulationRandomness = nil
+	})
+	switch kemID {
+	case KEM_MLKEM768, KEM_MLKEM1024:
+		testingOnlyEncapsulationRandomness = func() *[32]byte { return (*[32]byte)(ikmE) }
+	case KEM_MLKEM768_X25519:
+		priv, err := ecdh.X25519().NewPrivateKey(ikmE[32:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		testingOnlyGenerateKey = func() (*ecdh.PrivateKey, error) { return priv, nil }
+		testingOnlyEncapsulationRandomness = func() *[32]byte { return (*[32]byte)(ikmE[:32]) }
+	default:
+		kem, err := NewKEM(kemID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skE, err := kem.DeriveKeyPair(ikmE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testingOnlyGenerateKey = func() (*ecdh.PrivateKey, error) { return skE.(*dhKEMPrivateKey).priv, nil }
+	}
+}
+
+func drawRandomInput(t *testing.T, r io.Reader) []byte {
+	t.Helper()
+	l := make([]byte, 1)
+	if _, err := r.Read(l); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, int(l[0]))
+	if _, err := r.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func readAccumulator(r io.Reader) []byte {
+	b := make([]byte, 16)
+	r.Read(b)
+	return b
+}
+
+func testSealOpen(t *testing.T, s *Sender, r *Recipient, aad, plaintext []byte) []byte {
+	t.Helper()
+	ciphertext, err := s.Seal(aad, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Open(aad, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("Open = %x, want %x", got, plaintext)
+	}
+	return ciphertext
+}
+
+func testExport(t *testing.T, s *Sender, r *Recipient, context []byte, length int) []byte {
+	t.Helper()
+	value, err := s.Export(context, length)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Export(context, length)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, value) {
+		t.Fatalf("recipient exported %x, sender exported %x", got, value)
+	}
+	return value
+}
+
+func TestModes(t *testing.T) {
+	psk := bytes.Repeat([]byte{0x42}, 32)
+	pskID := []byte("psk id")
+
+	for _, kemID := range []uint16{DHKEM_P256_HKDF_SHA256, DHKEM_P384_HKDF_SHA384, DHKEM_P521_HKDF_SHA512, DHKEM_X25519_HKDF_SHA256} {
+		kem, err := NewKEM(kemID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skR, err := kem.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		skS, err := kem.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		otherS, err := kem.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, mode := range []struct {
+			name       string
+			auth       bool
+			psk, pskID []byte
+		}{
+			{"Base", false, nil, nil},
+			{"PSK", false, psk, pskID},
+			{"Auth", true, nil, nil},
+			{"AuthPSK", true, psk, pskID},
+		} {
+			t.Run(fmt.Sprintf("kem %04x %s", kemID, mode.name), func(t *testing.T) {
+				var sender PrivateKey
+				var senderPub PublicKey
+				if mode.auth {
+					sender, senderPub = skS, skS.PublicKey()
+				}
+				enc, s, err := NewSender(skR.PublicKey(), sender, KDF_HKDF_SHA256, AEAD_AES_128_GCM, []byte("info"), mode.psk, mode.pskID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r, err := NewRecipient(enc, skR, senderPub, KDF_HKDF_SHA256, AEAD_AES_128_GCM, []byte("info"), mode.psk, mode.pskID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ciphertext := testSealOpen(t, s, r, []byte("aad"), []byte("plaintext"))
+				testExport(t, s, r, []byte("context"), 42)
+
+				// A recipient in the wrong mode derives a different context.
+				var wrong *Recipient
+				switch {
+				case mode.auth:
+					wrong, err = NewRecipient(enc, skR, otherS.PublicKey(), KDF_HKDF_SHA256, AEAD_AES_128_GCM, []byte("info"), mode.psk, mode.pskID)
+				case mode.psk != nil:
+					wrong, err = NewRecipient(enc, skR, nil, KDF_HKDF_SHA256, AEAD_AES_128_GCM, []byte("info"), bytes.Repeat([]byte{0x43}, 32), mode.pskID)
+				default:
+					wrong, err = NewRecipient(enc, skR, nil, KDF_HKDF_SHA256, AEAD_AES_128_GCM, []byte("info"), psk, pskID)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := wrong.Open([]byte("aad"), ciphertext); err == nil {
+					t.Error("Open succeeded with the wrong mode inputs")
+				}
+			})
+		}
+	}
+
+	kem, err := NewKEM(DHKEM_X25519_HKDF_SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skR, err := kem.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewSender(skR.PublicKey(), nil, KDF_HKDF_SHA256, AEAD_AES_128_GCM, nil, psk, nil); err == nil {
+		t.Error("NewSender accepted a PSK without an identifier")
+	}
+	if _, _, err := NewSender(skR.PublicKey(), nil, KDF_HKDF_SHA256, AEAD_AES_128_GCM, nil, psk[:16], pskID); err == nil {
+		t.Error("NewSender accepted a short PSK")
+	}
+
+	xwing, err := NewKEM(KEM_MLKEM768_X25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skX, err := xwing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := NewSender(skX.PublicKey(), skX, KDF_HKDF_SHA256, AEAD_AES_128_GCM, nil, nil, nil); err == nil {
+		t.Error("NewSender accepted the Auth mode with a KEM that does not support it")
+	}
+	if _, _, err := NewSender(skR.PublicKey(), skX, KDF_HKDF_SHA256, AEAD_AES_128_GCM, nil, nil, nil); err == nil {
+		t.Error("NewSender accepted a sender key of a different KEM")
+	}
+}
@@ -0,0 +1,592 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hpke
+
+import (
+	"crypto/ecdh"
+	"crypto/internal/fips140/mlkem"
+	"crypto/rand"
+	"crypto/sha3"
+	"errors"
+	"internal/byteorder"
+)
+
+// A KEM is a key encapsulation mechanism, as specified in RFC 9180, Section 4.
+type KEM interface {
+	// ID returns the KEM identifier.
+	ID() uint16
+
+	// GenerateKey generates a random key pair.
+	GenerateKey() (PrivateKey, error)
+
+	// NewPublicKey implements DeserializePublicKey.
+	NewPublicKey([]byte) (PublicKey, error)
+
+	// NewPrivateKey implements DeserializePrivateKey.
+	NewPrivateKey([]byte) (PrivateKey, error)
+
+	// DeriveKeyPair deterministically derives a key pair from ikm.
+	DeriveKeyPair(ikm []byte) (PrivateKey, error)
+
+	// EncapsulatedKeySize returns Nenc, the size of encapsulated keys.
+	EncapsulatedKeySize() int
+}
+
+// A PublicKey is a KEM public key, which a sender encapsulates keys to.
+type PublicKey interface {
+	KEM() KEM
+
+	// Bytes implements SerializePublicKey.
+	Bytes() []byte
+
+	encap() (sharedSecret, enc []byte, err error)
+}
+
+// A PrivateKey is a KEM private key, which a recipient decapsulates keys with.
+type PrivateKey interface {
+	KEM() KEM
+
+	// Bytes implements SerializePrivateKey. It returns an error if the key
+	// can't be serialized.
+	Bytes() ([]byte, error)
+
+	PublicKey() PublicKey
+
+	decap(enc []byte) (sharedSecret []byte, err error)
+}
+
+// authPublicKey and authPrivateKey are implemented by the keys of KEMs that
+// support the Auth and AuthPSK modes.
+type authPublicKey interface {
+	PublicKey
+	authEncap(skS PrivateKey) (sharedSecret, enc []byte, err error)
+}
+
+type authPrivateKey interface {
+	PrivateKey
+	authDecap(enc []byte, pkS PublicKey) (sharedSecret []byte, err error)
+}
+
+// KEM identifiers, from RFC 9180, Section 7.1 and draft-ietf-hpke-pq-03.
+const (
+	DHKEM_P256_HKDF_SHA256   = 0x0010
+	DHKEM_P384_HKDF_SHA384   = 0x0011
+	DHKEM_P521_HKDF_SHA512   = 0x0012
+	DHKEM_X25519_HKDF_SHA256 = 0x0020
+	KEM_MLKEM768             = 0x0041
+	KEM_MLKEM1024            = 0x0042
+	KEM_MLKEM768_X25519      = 0x647a
+)
+
+// SupportedKEMs are the KEMs supported by [SetupSender], [SetupRecipient],
+// [ParseHPKEPublicKey], and [ParseHPKEPrivateKey].
+var SupportedKEMs = map[uint16]struct {
+	curve ecdh.Curve
+}{
+	// RFC 9180 Section 7.1
+	DHKEM_X25519_HKDF_SHA256: {ecdh.X25519()},
+}
+
+// NewKEM returns the KEM with the given identifier.
+func NewKEM(id uint16) (KEM, error) {
+	switch id {
+	case DHKEM_P256_HKDF_SHA256:
+		return dhKEMP256, nil
+	case DHKEM_P384_HKDF_SHA384:
+		return dhKEMP384, nil
+	case DHKEM_P521_HKDF_SHA512:
+		return dhKEMP521, nil
+	case DHKEM_X25519_HKDF_SHA256:
+		return dhKEMX25519, nil
+	case KEM_MLKEM768:
+		return mlkem768, nil
+	case KEM_MLKEM1024:
+		return mlkem1024, nil
+	case KEM_MLKEM768_X25519:
+		return mlkem768X25519, nil
+	default:
+		return nil, errors.New("unsupported KEM id")
+	}
+}
+
+// testingOnlyGenerateKey is only used during testing, to provide
+// a fixed test key to use when checking the RFC 9180 vectors.
+var testingOnlyGenerateKey func() (*ecdh.PrivateKey, error)
+
+// testingOnlyEncapsulationRandomness is only used during testing, to provide
+// the fixed ML-KEM encapsulation randomness of the test vectors.
+var testingOnlyEncapsulationRandomness func() *[32]byte
+
+func generateEphemeralKey(curve ecdh.Curve) (*ecdh.PrivateKey, error) {
+	if testingOnlyGenerateKey != nil {
+		return testingOnlyGenerateKey()
+	}
+	return curve.GenerateKey(rand.Reader)
+}
+
+// dhKEM implements the KEM specified in RFC 9180, Section 4.1.
+type dhKEM struct {
+	id    uint16
+	curve ecdh.Curve
+	kdf   *hkdfKDF
+
+	nSecret uint16
+	nSk     uint16
+	nEnc    int
+}
+
+var (
+	dhKEMP256   = &dhKEM{DHKEM_P256_HKDF_SHA256, ecdh.P256(), hkdfSHA256, 32, 32, 65}
+	dhKEMP384   = &dhKEM{DHKEM_P384_HKDF_SHA384, ecdh.P384(), hkdfSHA384, 48, 48, 97}
+	dhKEMP521   = &dhKEM{DHKEM_P521_HKDF_SHA512, ecdh.P521(), hkdfSHA512, 64, 66, 133}
+	dhKEMX25519 = &dhKEM{DHKEM_X25519_HKDF_SHA256, ecdh.X25519(), hkdfSHA256, 32, 32, 32}
+)
+
+func (kem *dhKEM) suiteID() []byte {
+	return byteorder.BEAppendUint16([]byte("KEM"), kem.id)
+}
+
+func (kem *dhKEM) ID() uint16 { return kem.id }
+
+func (kem *dhKEM) EncapsulatedKeySize() int { return kem.nEnc }
+
+func (kem *dhKEM) ExtractAndExpand(dhKey, kemContext []byte) ([]byte, error) {
+	eaePRK, err := kem.kdf.LabeledExtract(kem.suiteID(), nil, "eae_prk", dhKey)
+	if err != nil {
+		return nil, err
+	}
+	return kem.kdf.LabeledExpand(kem.suiteID(), eaePRK, "shared_secret", kemContext, kem.nSecret)
+}
+
+func (kem *dhKEM) GenerateKey() (PrivateKey, error) {
+	priv, err := kem.curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return newDHKEMPrivateKey(kem, priv)
+}
+
+func (kem *dhKEM) NewPublicKey(b []byte) (PublicKey, error) {
+	pub, err := kem.curve.NewPublicKey(b)
+	if err != nil {
+		return nil, err
+	}
+	return newDHKEMPublicKey(kem, pub)
+}
+
+func (kem *dhKEM) NewPrivateKey(b []byte) (PrivateKey, error) {
+	priv, err := kem.curve.NewPrivateKey(b)
+	if err != nil {
+		return nil, err
+	}
+	return newDHKEMPrivateKey(kem, priv)
+}
+
+// DeriveKeyPair implements RFC 9180, Section 7.1.3.
+func (kem *dhKEM) DeriveKeyPair(ikm []byte) (PrivateKey, error) {
+	dkpPRK, err := kem.kdf.LabeledExtract(kem.suiteID(), nil, "dkp_prk", ikm)
+	if err != nil {
+		return nil, err
+	}
+	if kem == dhKEMX25519 {
+		sk, err := kem.kdf.LabeledExpand(kem.suiteID(), dkpPRK, "sk", nil, kem.nSk)
+		if err != nil {
+			return nil, err
+		}
+		return kem.NewPrivateKey(sk)
+	}
+	for counter := 0; counter < 256; counter++ {
+		sk, err := kem.kdf.LabeledExpand(kem.suiteID(), dkpPRK, "candidate", []byte{uint8(counter)}, kem.nSk)
+		if err != nil {
+			return nil, err
+		}
+		if kem == dhKEMP521 {
+			sk[0] &= 0x01
+		}
+		// NewPrivateKey rejects zero and values larger than the order.
+		if k, err := kem.NewPrivateKey(sk); err == nil {
+			return k, nil
+		}
+	}
+	return nil, errors.New("DeriveKeyPair failed")
+}
+
+type dhKEMPublicKey struct {
+	kem *dhKEM
+	pub *ecdh.PublicKey
+}
+
+func newDHKEMPublicKey(kem *dhKEM, pub *ecdh.PublicKey) (*dhKEMPublicKey, error) {
+	if pub.Curve() != kem.curve {
+		return nil, errors.New("public key curve does not match KEM")
+	}
+	return &dhKEMPublicKey{kem, pub}, nil
+}
+
+func (pk *dhKEMPublicKey) KEM() KEM { return pk.kem }
+
+func (pk *dhKEMPublicKey) Bytes() []byte { return pk.pub.Bytes() }
+
+func (pk *dhKEMPublicKey) encap() (sharedSecret []byte, encapPub []byte, err error) {
+	return pk.authEncap(nil)
+}
+
+// authEncap implements AuthEncap, or Encap if skS is nil.
+func (pk *dhKEMPublicKey) authEncap(skS PrivateKey) (sharedSecret []byte, encapPub []byte, err error) {
+	privEph, err := generateEphemeralKey(pk.kem.curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	dh, err := privEph.ECDH(pk.pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	encPubEph := privEph.PublicKey().Bytes()
+	kemContext := append(encPubEph[:len(encPubEph):len(encPubEph)], pk.pub.Bytes()...)
+
+	if skS != nil {
+		skS := skS.(*dhKEMPrivateKey)
+		dhS, err := skS.priv.ECDH(pk.pub)
+		if err != nil {
+			return nil, nil, err
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, skS.priv.PublicKey().Bytes()...)
+	}
+
+	sharedSecret, err = pk.kem.ExtractAndExpand(dh, kemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sharedSecret, encPubEph, nil
+}
+
+type dhKEMPrivateKey struct {
+	kem  *dhKEM
+	priv *ecdh.PrivateKey
+}
+
+func newDHKEMPrivateKey(kem *dhKEM, priv *ecdh.PrivateKey) (*dhKEMPrivateKey, error) {
+	if priv.Curve() != kem.curve {
+		return nil, errors.New("private key curve does not match KEM")
+	}
+	return &dhKEMPrivateKey{kem, priv}, nil
+}
+
+func (sk *dhKEMPrivateKey) KEM() KEM { return sk.kem }
+
+func (sk *dhKEMPrivateKey) Bytes() ([]byte, error) {
+	b := sk.priv.Bytes()
+	if sk.kem == dhKEMX25519 {
+		// SerializePrivateKey clamps X25519 keys. See RFC 9180, Section 7.1.2.
+		b[0] &= 248
+		b[31] &= 127
+		b[31] |= 64
+	}
+	return b, nil
+}
+
+func (sk *dhKEMPrivateKey) PublicKey() PublicKey {
+	return &dhKEMPublicKey{sk.kem, sk.priv.PublicKey()}
+}
+
+func (sk *dhKEMPrivateKey) decap(encPubEph []byte) ([]byte, error) {
+	return sk.authDecap(encPubEph, nil)
+}
+
+// authDecap implements AuthDecap, or Decap if pkS is nil.
+func (sk *dhKEMPrivateKey) authDecap(encPubEph []byte, pkS PublicKey) ([]byte, error) {
+	pubEph, err := sk.kem.curve.NewPublicKey(encPubEph)
+	if err != nil {
+		return nil, err
+	}
+	dh, err := sk.priv.ECDH(pubEph)
+	if err != nil {
+		return nil, err
+	}
+	kemContext := append(pubEph.Bytes(), sk.priv.PublicKey().Bytes()...)
+
+	if pkS != nil {
+		pkS := pkS.(*dhKEMPublicKey)
+		dhS, err := sk.priv.ECDH(pkS.pub)
+		if err != nil {
+			return nil, err
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, pkS.pub.Bytes()...)
+	}
+
+	return sk.kem.ExtractAndExpand(dh, kemContext)
+}
+
+// shakeLabeledDerive implements LabeledDerive with SHAKE256, as specified in
+// draft-ietf-hpke-pq-03, Section 4.
+func shakeLabeledDerive(suiteID, ikm []byte, label string, context []byte, length uint16) []byte {
+	h := sha3.NewSHAKE256()
+	h.Write(ikm)
+	h.Write([]byte("HPKE-v1"))
+	h.Write(suiteID)
+	h.Write(byteorder.BEAppendUint16(nil, uint16(len(label))))
+	h.Write([]byte(label))
+	h.Write(byteorder.BEAppendUint16(nil, length))
+	h.Write(context)
+	out := make([]byte, length)
+	h.Read(out)
+	return out
+}
+
+type mlkemEncapsulationKey interface {
+	Bytes() []byte
+	Encapsulate() (sharedKey, ciphertext []byte)
+	EncapsulateInternal(m *[32]byte) (sharedKey, ciphertext []byte)
+}
+
+type mlkemDecapsulationKey interface {
+	Bytes() []byte
+	Decapsulate(ciphertext []byte) (sharedKey []byte, err error)
+}
+
+// mlkemKEM implements the ML-KEM KEMs specified in draft-ietf-hpke-pq-03,
+// Section 3.
+type mlkemKEM struct {
+	id             uint16
+	ciphertextSize int
+	newPublicKey   func([]byte) (mlkemEncapsulationKey, error)
+	newPrivateKey  func(seed []byte) (mlkemDecapsulationKey, mlkemEncapsulationKey, error)
+}
+
+var mlkem768 = &mlkemKEM{
+	id:             KEM_MLKEM768,
+	ciphertextSize: mlkem.CiphertextSize768,
+	newPublicKey: func(b []byte) (mlkemEncapsulationKey, error) {
+		return mlkem.NewEncapsulationKey768(b)
+	},
+	newPrivateKey: func(seed []byte) (mlkemDecapsulationKey, mlkemEncapsulationKey, error) {
+		dk, err := mlkem.NewDecapsulationKey768(seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dk, dk.EncapsulationKey(), nil
+	},
+}
+
+var mlkem1024 = &mlkemKEM{
+	id:             KEM_MLKEM1024,
+	ciphertextSize: mlkem.CiphertextSize1024,
+	newPublicKey: func(b []byte) (mlkemEncapsulationKey, error) {
+		return mlkem.NewEncapsulationKey1024(b)
+	},
+	newPrivateKey: func(seed []byte) (mlkemDecapsulationKey, mlkemEncapsulationKey, error) {
+		dk, err := mlkem.NewDecapsulationKey1024(seed)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dk, dk.EncapsulationKey(), nil
+	},
+}
+
+func (kem *mlkemKEM) ID() uint16 { return kem.id }
+
+func (kem *mlkemKEM) EncapsulatedKeySize() int { return kem.ciphertextSize }
+
+func (kem *mlkemKEM) GenerateKey() (PrivateKey, error) {
+	seed := make([]byte, mlkem.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return kem.NewPrivateKey(seed)
+}
+
+func (kem *mlkemKEM) NewPublicKey(b []byte) (PublicKey, error) {
+	ek, err := kem.newPublicKey(b)
+	if err != nil {
+		return nil, err
+	}
+	return &mlkemPublicKey{kem, ek}, nil
+}
+
+func encapsulate(ek mlkemEncapsulationKey) (sharedKey, ciphertext []byte) {
+	if testingOnlyEncapsulationRandomness != nil {
+		return ek.EncapsulateInternal(testingOnlyEncapsulationRandomness())
+	}
+	return ek.Encapsulate()
+}
+
+// NewPrivateKey accepts a 64-byte "d || z" seed.
+func (kem *mlkemKEM) NewPrivateKey(seed []byte) (PrivateKey, error) {
+	dk, ek, err := kem.newPrivateKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &mlkemPrivateKey{kem, dk, ek}, nil
+}
+
+func (kem *mlkemKEM) DeriveKeyPair(ikm []byte) (PrivateKey, error) {
+	suiteID := byteorder.BEAppendUint16([]byte("KEM"), kem.id)
+	return kem.NewPrivateKey(shakeLabeledDerive(suiteID, ikm, "DeriveKeyPair", nil, mlkem.SeedSize))
+}
+
+type mlkemPublicKey struct {
+	kem *mlkemKEM
+	ek  mlkemEncapsulationKey
+}
+
+func (pk *mlkemPublicKey) KEM() KEM { return pk.kem }
+
+func (pk *mlkemPublicKey) Bytes() []byte { return pk.ek.Bytes() }
+
+func (pk *mlkemPublicKey) encap() (sharedSecret, enc []byte, err error) {
+	sharedSecret, enc = encapsulate(pk.ek)
+	return sharedSecret, enc, nil
+}
+
+type mlkemPrivateKey struct {
+	kem *mlkemKEM
+	dk  mlkemDecapsulationKey
+	ek  mlkemEncapsulationKey
+}
+
+func (sk *mlkemPrivateKey) KEM() KEM { return sk.kem }
+
+func (sk *mlkemPrivateKey) Bytes() ([]byte, error) { return sk.dk.Bytes(), nil }
+
+func (sk *mlkemPrivateKey) PublicKey() PublicKey { return &mlkemPublicKey{sk.kem, sk.ek} }
+
+func (sk *mlkemPrivateKey) decap(enc []byte) ([]byte, error) {
+	return sk.dk.Decapsulate(enc)
+}
+
+// xwingKEM implements MLKEM768-X25519, also known as X-Wing, as specified in
+// draft-ietf-hpke-pq-03, Section 5.
+type xwingKEM struct{}
+
+var mlkem768X25519 = &xwingKEM{}
+
+// xwingLabel is the X-Wing domain separator, an ASCII-art wing.
+const xwingLabel = `\./` + `/^\`
+
+const xwingSeedSize = 32
+
+func (kem *xwingKEM) ID() uint16 { return KEM_MLKEM768_X25519 }
+
+func (kem *xwingKEM) EncapsulatedKeySize() int { return mlkem.CiphertextSize768 + 32 }
+
+func (kem *xwingKEM) GenerateKey() (PrivateKey, error) {
+	seed := make([]byte, xwingSeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return kem.NewPrivateKey(seed)
+}
+
+func (kem *xwingKEM) NewPublicKey(b []byte) (PublicKey, error) {
+	if len(b) != mlkem.EncapsulationKeySize768+32 {
+		return nil, errors.New("invalid X-Wing public key size")
+	}
+	ek, err := mlkem.NewEncapsulationKey768(b[:mlkem.EncapsulationKeySize768])
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ecdh.X25519().NewPublicKey(b[mlkem.EncapsulationKeySize768:])
+	if err != nil {
+		return nil, err
+	}
+	return &xwingPublicKey{ek, pub}, nil
+}
+
+// NewPrivateKey accepts a 32-byte seed, which is expanded into the ML-KEM-768
+// and X25519 private keys.
+func (kem *xwingKEM) NewPrivateKey(seed []byte) (PrivateKey, error) {
+	if len(seed) != xwingSeedSize {
+		return nil, errors.New("invalid X-Wing private key size")
+	}
+	expanded := sha3.SumSHAKE256(seed, mlkem.SeedSize+32)
+	dk, err := mlkem.NewDecapsulationKey768(expanded[:mlkem.SeedSize])
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ecdh.X25519().NewPrivateKey(expanded[mlkem.SeedSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &xwingPrivateKey{append([]byte(nil), seed...), dk, priv}, nil
+}
+
+func (kem *xwingKEM) DeriveKeyPair(ikm []byte) (PrivateKey, error) {
+	suiteID := byteorder.BEAppendUint16([]byte("KEM"), KEM_MLKEM768_X25519)
+	return kem.NewPrivateKey(shakeLabeledDerive(suiteID, ikm, "DeriveKeyPair", nil, xwingSeedSize))
+}
+
+// xwingCombiner returns the X-Wing shared secret.
+func xwingCombiner(ssPQ, ssT, ctT, pkT []byte) []byte {
+	h := sha3.New256()
+	h.Write(ssPQ)
+	h.Write(ssT)
+	h.Write(ctT)
+	h.Write(pkT)
+	h.Write([]byte(xwingLabel))
+	return h.Sum(nil)
+}
+
+type xwingPublicKey struct {
+	ek  *mlkem.EncapsulationKey768
+	pub *ecdh.PublicKey
+}
+
+func (pk *xwingPublicKey) KEM() KEM { return mlkem768X25519 }
+
+func (pk *xwingPublicKey) Bytes() []byte {
+	return append(pk.ek.Bytes(), pk.pub.Bytes()...)
+}
+
+func (pk *xwingPublicKey) encap() (sharedSecret, enc []byte, err error) {
+	privEph, err := generateEphemeralKey(ecdh.X25519())
+	if err != nil {
+		return nil, nil, err
+	}
+	ssT, err := privEph.ECDH(pk.pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctT := privEph.PublicKey().Bytes()
+	ssPQ, ctPQ := encapsulate(pk.ek)
+	return xwingCombiner(ssPQ, ssT, ctT, pk.pub.Bytes()), append(ctPQ, ctT...), nil
+}
+
+type xwingPrivateKey struct {
+	seed []byte
+	dk   *mlkem.DecapsulationKey768
+	priv *ecdh.PrivateKey
+}
+
+func (sk *xwingPrivateKey) KEM() KEM { return mlkem768X25519 }
+
+func (sk *xwingPrivateKey) Bytes() ([]byte, error) {
+	return append([]byte(nil), sk.seed...), nil
+}
+
+func (sk *xwingPrivateKey) PublicKey() PublicKey {
+	return &xwingPublicKey{sk.dk.EncapsulationKey(), sk.priv.PublicKey()}
+}
+
+func (sk *xwingPrivateKey) decap(enc []byte) ([]byte, error) {
+	if len(enc) != mlkem.CiphertextSize768+32 {
+		return nil, errors.New("invalid X-Wing ciphertext size")
+	}
+	ctPQ, ctT := enc[:mlkem.CiphertextSize768], enc[mlkem.CiphertextSize768:]
+	ssPQ, err := sk.dk.Decapsulate(ctPQ)
+	if err != nil {
+		return nil, err
+	}
+	pubEph, err := ecdh.X25519().NewPublicKey(ctT)
+	if err != nil {
+		return nil, err
+	}
+	ssT, err := sk.priv.ECDH(pubEph)
+	if err != nil {
+		return nil, err
+	}
+	return xwingCombiner(ssPQ, ssT, ctT, sk.priv.PublicKey().Bytes()), nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa
+
+import "internal/byteorder"
+
+// Address types, according to FIPS 205, Section 4.2.
+const (
+	addressWOTSHash  = 0
+	addressWOTSPK    = 1
+	addressTree      = 2
+	addressFORSTree  = 3
+	addressFORSRoots = 4
+	addressWOTSPRF   = 5
+	addressFORSPRF   = 6
+)
+
+// address is the 32-byte ADRS structure, according to FIPS 205, Section 4.2.
+//
+//	bytes  0..4   layer address
+//	bytes  4..16  tree address
+//	bytes 16..20  type
+//	bytes 20..24  key pair address
+//	bytes 24..28  chain address or tree height
+//	bytes 28..32  hash address or tree index
+type address [32]byte
+
+func (a *address) setLayerAddress(l uint32) {
+	byteorder.BEPutUint32(a[0:4], l)
+}
+
+// setTreeAddress sets the tree address. The top 32 bits of the 96-bit field
+// are always zero, as the tree index never exceeds 64 bits.
+func (a *address) setTreeAddress(t uint64) {
+	byteorder.BEPutUint32(a[4:8], 0)
+	byteorder.BEPutUint64(a[8:16], t)
+}
+
+// setTypeAndClear sets the type and zeroes the three following words.
+func (a *address) setTypeAndClear(typ uint32) {
+	byteorder.BEPutUint32(a[16:20], typ)
+	clear(a[20:32])
+}
+
+func (a *address) setKeyPairAddress(i uint32) {
+	byteorder.BEPutUint32(a[20:24], i)
+}
+
+func (a *address) keyPairAddress() uint32 {
+	return byteorder.BEUint32(a[20:24])
+}
+
+func (a *address) setChainAddress(i uint32) {
+	byteorder.BEPutUint32(a[24:28], i)
+}
+
+func (a *address) setTreeHeight(z uint32) {
+	byteorder.BEPutUint32(a[24:28], z)
+}
+
+func (a *address) setHashAddress(i uint32) {
+	byteorder.BEPutUint32(a[28:32], i)
+}
+
+func (a *address) setTreeIndex(i uint32) {
+	byteorder.BEPutUint32(a[28:32], i)
+}
+
+func (a *address) treeIndex() uint32 {
+	return byteorder.BEUint32(a[28:32])
+}
+
+// compressed returns the 22-byte ADRSc used by the SHA-2 parameter sets,
+// according to FIPS 205, Section 11.2.
+func (a *address) compressed() (c [22]byte) {
+	c[0] = a[3]
+	copy(c[1:9], a[8:16])
+	c[9] = a[19]
+	copy(c[10:22], a[20:32])
+	return c
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa
+
+// forsSKGen computes the FORS secret value with index idx, according to
+// FIPS 205, Algorithm 14.
+func (s *state) forsSKGen(out []byte, adrs *address, idx uint32) {
+	skADRS := *adrs
+	skADRS.setTypeAndClear(addressFORSPRF)
+	skADRS.setKeyPairAddress(adrs.keyPairAddress())
+	skADRS.setTreeIndex(idx)
+	s.hs.prf(out, &skADRS, s.skSeed)
+}
+
+// forsNode computes the root of the FORS subtree of height z with index i,
+// according to FIPS 205, Algorithm 15.
+func (s *state) forsNode(out []byte, i, z uint32, adrs *address) {
+	if z == 0 {
+		sk := make([]byte, s.n)
+		s.forsSKGen(sk, adrs, i)
+		adrs.setTreeHeight(0)
+		adrs.setTreeIndex(i)
+		s.hs.f(out, adrs, sk)
+		return
+	}
+	buf := make([]byte, 2*s.n)
+	s.forsNode(buf[:s.n], 2*i, z-1, adrs)
+	s.forsNode(buf[s.n:], 2*i+1, z-1, adrs)
+	adrs.setTreeHeight(z)
+	adrs.setTreeIndex(i)
+	s.hs.h(out, adrs, buf)
+}
+
+// forsSign generates a FORS signature, according to FIPS 205, Algorithm 16.
+func (s *state) forsSign(sig, md []byte, adrs *address) {
+	indices := make([]uint32, s.k)
+	base2b(indices, md, s.a)
+	treeSigLen := (s.a + 1) * s.n
+	for i, idx := range indices {
+		treeSig := sig[i*treeSigLen : (i+1)*treeSigLen]
+		s.forsSKGen(treeSig[:s.n], adrs, uint32(i)<<s.a+idx)
+		for j := range uint32(s.a) {
+			sib := (idx >> j) ^ 1
+			off := int(j+1) * s.n
+			s.forsNode(treeSig[off:off+s.n], uint32(i)<<(uint32(s.a)-j)+sib, j, adrs)
+		}
+	}
+}
+
+// forsPKFromSig computes a FORS public key from a signature, according to
+// FIPS 205, Algorithm 17.
+func (s *state) forsPKFromSig(out, sig, md []byte, adrs *address) {
+	indices := make([]uint32, s.k)
+	base2b(indices, md, s.a)
+	treeSigLen := (s.a + 1) * s.n
+	roots := make([]byte, s.k*s.n)
+	buf := make([]byte, 2*s.n)
+	for i, idx := range indices {
+		treeSig := sig[i*treeSigLen : (i+1)*treeSigLen]
+		adrs.setTreeHeight(0)
+		adrs.setTreeIndex(uint32(i)<<s.a + idx)
+		s.hs.f(buf[:s.n], adrs, treeSig[:s.n])
+		auth := treeSig[s.n:]
+		for j := range uint32(s.a) {
+			adrs.setTreeHeight(j + 1)
+			authJ := auth[int(j)*s.n : int(j+1)*s.n]
+			if (idx>>j)&1 == 0 {
+				adrs.setTreeIndex(adrs.treeIndex() / 2)
+				copy(buf[s.n:], authJ)
+			} else {
+				adrs.setTreeIndex((adrs.treeIndex() - 1) / 2)
+				copy(buf[s.n:], buf[:s.n])
+				copy(buf[:s.n], authJ)
+			}
+			s.hs.h(buf[:s.n], adrs, buf)
+		}
+		copy(roots[i*s.n:], buf[:s.n])
+	}
+	pkADRS := *adrs
+	pkADRS.setTypeAndClear(addressFORSRoots)
+	pkADRS.setKeyPairAddress(adrs.keyPairAddress())
+	s.hs.h(out, &pkADRS, roots)
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"hash"
+	"internal/byteorder"
+)
+
+// hashSuite implements the hash functions and pseudorandom functions of a
+// parameter set, keyed with PK.seed, according to FIPS 205, Section 11.
+type hashSuite interface {
+	// prf computes PRF(PK.seed, SK.seed, ADRS).
+	prf(out []byte, adrs *address, skSeed []byte)
+	// f computes F(PK.seed, ADRS, m).
+	f(out []byte, adrs *address, m []byte)
+	// h computes H(PK.seed, ADRS, m) or T_ℓ(PK.seed, ADRS, m), which only
+	// differ in the length of m.
+	h(out []byte, adrs *address, m []byte)
+	// prfMsg computes PRF_msg(SK.prf, opt_rand, prefix || msg).
+	prfMsg(out, skPRF, optRand, prefix, msg []byte)
+	// hMsg computes H_msg(R, PK.seed, PK.root, prefix || msg).
+	hMsg(out, r, pkRoot, prefix, msg []byte)
+}
+
+func newHashSuite(p *Parameters, pkSeed []byte) hashSuite {
+	if p.sha2 {
+		return newSHA2Suite(p.n, pkSeed)
+	}
+	return &shakeSuite{n: p.n, pkSeed: pkSeed, s: sha3.NewSHAKE256()}
+}
+
+// shakeSuite implements the SHAKE parameter sets, according to FIPS 205,
+// Section 11.1.
+type shakeSuite struct {
+	n      int
+	pkSeed []byte
+	s      hash.XOF
+}
+
+func (h *shakeSuite) thash(out []byte, adrs *address, m []byte) {
+	h.s.Reset()
+	h.s.Write(h.pkSeed)
+	h.s.Write(adrs[:])
+	h.s.Write(m)
+	h.s.Read(out[:h.n])
+}
+
+func (h *shakeSuite) prf(out []byte, adrs *address, skSeed []byte) { h.thash(out, adrs, skSeed) }
+func (h *shakeSuite) f(out []byte, adrs *address, m []byte)        { h.thash(out, adrs, m) }
+func (h *shakeSuite) h(out []byte, adrs *address, m []byte)        { h.thash(out, adrs, m) }
+
+func (h *shakeSuite) prfMsg(out, skPRF, optRand, prefix, msg []byte) {
+	h.s.Reset()
+	h.s.Write(skPRF)
+	h.s.Write(optRand)
+	h.s.Write(prefix)
+	h.s.Write(msg)
+	h.s.Read(out[:h.n])
+}
+
+func (h *shakeSuite) hMsg(out, r, pkRoot, prefix, msg []byte) {
+	h.s.Reset()
+	h.s.Write(r)
+	h.s.Write(h.pkSeed)
+	h.s.Write(pkRoot)
+	h.s.Write(prefix)
+	h.s.Write(msg)
+	h.s.Read(out)
+}
+
+// sha2Suite implements the SHA-2 parameter sets, according to FIPS 205,
+// Sections 11.2.1 and 11.2.2.
+//
+// F and PRF always use SHA-256, while H, T_ℓ, H_msg and PRF_msg use SHA-512
+// for security categories 3 and 5. The state after absorbing the padded
+// PK.seed block is saved, so that each call compresses a single block for
+// most inputs.
+type sha2Suite struct {
+	n      int
+	pkSeed []byte
+
+	small      hash.Hash
+	smallState []byte
+	big        hash.Hash
+	bigState   []byte
+	newBig     func() hash.Hash
+
+	sum [sha512.Size]byte
+}
+
+func newSHA2Suite(n int, pkSeed []byte) *sha2Suite {
+	h := &sha2Suite{n: n, pkSeed: pkSeed}
+	h.small, h.smallState = paddedSeedState(sha256.New(), pkSeed)
+	if n == 16 {
+		h.big, h.bigState = h.small, h.smallState
+		h.newBig = sha256.New
+	} else {
+		h.big, h.bigState = paddedSeedState(sha512.New(), pkSeed)
+		h.newBig = sha512.New
+	}
+	return h
+}
+
+// paddedSeedState absorbs PK.seed || toByte(0, blockSize - n) into h, and
+// returns h and its marshaled state.
+func paddedSeedState(h hash.Hash, pkSeed []byte) (hash.Hash, []byte) {
+	h.Write(pkSeed)
+	h.Write(make([]byte, h.BlockSize()-len(pkSeed)))
+	state, err := h.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+	if err != nil {
+		panic("slhdsa: internal error: " + err.Error())
+	}
+	return h, state
+}
+
+func (h *sha2Suite) thash(hh hash.Hash, state []byte, out []byte, adrs *address, m []byte) {
+	if err := hh.(interface{ UnmarshalBinary([]byte) error }).UnmarshalBinary(state); err != nil {
+		panic("slhdsa: internal error: " + err.Error())
+	}
+	adrsc := adrs.compressed()
+	hh.Write(adrsc[:])
+	hh.Write(m)
+	copy(out[:h.n], hh.Sum(h.sum[:0]))
+}
+
+func (h *sha2Suite) prf(out []byte, adrs *address, skSeed []byte) {
+	h.thash(h.small, h.smallState, out, adrs, skSeed)
+}
+
+func (h *sha2Suite) f(out []byte, adrs *address, m []byte) {
+	h.thash(h.small, h.smallState, out, adrs, m)
+}
+
+func (h *sha2Suite) h(out []byte, adrs *address, m []byte) {
+	h.thash(h.big, h.bigState, out, adrs, m)
+}
+
+func (h *sha2Suite) prfMsg(out, skPRF, optRand, prefix, msg []byte) {
+	mac := hmac.New(h.newBig, skPRF)
+	mac.Write(optRand)
+	mac.Write(prefix)
+	mac.Write(msg)
+	copy(out[:h.n], mac.Sum(h.sum[:0]))
+}
+
+// hMsg computes MGF1-SHA-X(R || PK.seed || SHA-X(R || PK.seed || PK.root || M), m).
+func (h *sha2Suite) hMsg(out, r, pkRoot, prefix, msg []byte) {
+	d := h.newBig()
+	d.Write(r)
+	d.Write(h.pkSeed)
+	d.Write(pkRoot)
+	d.Write(prefix)
+	d.Write(msg)
+	seed := make([]byte, 0, len(r)+len(h.pkSeed)+d.Size()+4)
+	seed = append(seed, r...)
+	seed = append(seed, h.pkSeed...)
+	seed = d.Sum(seed)
+	seed = seed[:len(seed)+4]
+
+	var counter uint32
+	for len(out) > 0 {
+		byteorder.BEPutUint32(seed[len(seed)-4:], counter)
+		d.Reset()
+		d.Write(seed)
+		n := copy(out, d.Sum(h.sum[:0]))
+		out = out[n:]
+		counter++
+	}
+}
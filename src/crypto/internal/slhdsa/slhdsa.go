@@ -0,0 +1,297 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package slhdsa implements the SLH-DSA algorithms of NIST FIPS 205 for
+// crypto/slhdsa, exposing the seeds and the signing randomness for testing.
+package slhdsa
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// Parameters is an SLH-DSA parameter set, according to FIPS 205, Table 2.
+type Parameters struct {
+	name string
+	sha2 bool
+	n    int // security parameter, in bytes
+	h    int // total height of the hypertree
+	d    int // number of hypertree layers
+	hp   int // height of each XMSS tree, h′ = h / d
+	a    int // height of each FORS tree
+	k    int // number of FORS trees
+	m    int // length of the message digest, in bytes
+}
+
+var (
+	sha2128s  = &Parameters{"SLH-DSA-SHA2-128s", true, 16, 63, 7, 9, 12, 14, 30}
+	shake128s = &Parameters{"SLH-DSA-SHAKE-128s", false, 16, 63, 7, 9, 12, 14, 30}
+	sha2128f  = &Parameters{"SLH-DSA-SHA2-128f", true, 16, 66, 22, 3, 6, 33, 34}
+	shake128f = &Parameters{"SLH-DSA-SHAKE-128f", false, 16, 66, 22, 3, 6, 33, 34}
+	sha2192s  = &Parameters{"SLH-DSA-SHA2-192s", true, 24, 63, 7, 9, 14, 17, 39}
+	shake192s = &Parameters{"SLH-DSA-SHAKE-192s", false, 24, 63, 7, 9, 14, 17, 39}
+	sha2192f  = &Parameters{"SLH-DSA-SHA2-192f", true, 24, 66, 22, 3, 8, 33, 42}
+	shake192f = &Parameters{"SLH-DSA-SHAKE-192f", false, 24, 66, 22, 3, 8, 33, 42}
+	sha2256s  = &Parameters{"SLH-DSA-SHA2-256s", true, 32, 64, 8, 8, 14, 22, 47}
+	shake256s = &Parameters{"SLH-DSA-SHAKE-256s", false, 32, 64, 8, 8, 14, 22, 47}
+	sha2256f  = &Parameters{"SLH-DSA-SHA2-256f", true, 32, 68, 17, 4, 9, 35, 49}
+	shake256f = &Parameters{"SLH-DSA-SHAKE-256f", false, 32, 68, 17, 4, 9, 35, 49}
+)
+
+// wotsLen returns the number of WOTS+ chains, len = len1 + len2.
+
+// SHA2128s returns the SLH-DSA-SHA2-128s parameter set.
+func SHA2128s() *Parameters { return sha2128s }
+
+// SHA2128f returns the SLH-DSA-SHA2-128f parameter set.
+func SHA2128f() *Parameters { return sha2128f }
+
+// SHA2192s returns the SLH-DSA-SHA2-192s parameter set.
+func SHA2192s() *Parameters { return sha2192s }
+
+// SHA2192f returns the SLH-DSA-SHA2-192f parameter set.
+func SHA2192f() *Parameters { return sha2192f }
+
+// SHA2256s returns the SLH-DSA-SHA2-256s parameter set.
+func SHA2256s() *Parameters { return sha2256s }
+
+// SHA2256f returns the SLH-DSA-SHA2-256f parameter set.
+func SHA2256f() *Parameters { return sha2256f }
+
+// SHAKE128s returns the SLH-DSA-SHAKE-128s parameter set.
+func SHAKE128s() *Parameters { return shake128s }
+
+// SHAKE128f returns the SLH-DSA-SHAKE-128f parameter set.
+func SHAKE128f() *Parameters { return shake128f }
+
+// SHAKE192s returns the SLH-DSA-SHAKE-192s parameter set.
+func SHAKE192s() *Parameters { return shake192s }
+
+// SHAKE192f returns the SLH-DSA-SHAKE-192f parameter set.
+func SHAKE192f() *Parameters { return shake192f }
+
+// SHAKE256s returns the SLH-DSA-SHAKE-256s parameter set.
+func SHAKE256s() *Parameters { return shake256s }
+
+// SHAKE256f returns the SLH-DSA-SHAKE-256f parameter set.
+func SHAKE256f() *Parameters { return shake256f }
+
+// String returns the name of the parameter set, such as "SLH-DSA-SHA2-128s".
+func (p *Parameters) String() string { return p.name }
+
+// wotsLen returns the number of WOTS+ chains, len = len1 + len2.
+func (p *Parameters) wotsLen() int { return 2*p.n + wotsLen2 }
+
+// forsSigLen returns the size of a FORS signature.
+func (p *Parameters) forsSigLen() int { return p.k * (p.a + 1) * p.n }
+
+// PublicKeySize returns the size of public keys, 2n.
+func (p *Parameters) PublicKeySize() int { return 2 * p.n }
+
+// PrivateKeySize returns the size of private keys, 4n.
+func (p *Parameters) PrivateKeySize() int { return 4 * p.n }
+
+// SeedSize returns the size of each of the SK.seed, SK.prf and PK.seed seeds,
+// and of the additional signing randomness, n.
+func (p *Parameters) SeedSize() int { return p.n }
+
+// SignatureSize returns the size of signatures.
+func (p *Parameters) SignatureSize() int {
+	return (1 + p.k*(1+p.a) + p.h + p.d*p.wotsLen()) * p.n
+}
+
+// PrivateKey is an SLH-DSA private key.
+type PrivateKey struct {
+	p *Parameters
+	b []byte // SK.seed || SK.prf || PK.seed || PK.root
+}
+
+// PublicKey is an SLH-DSA public key.
+type PublicKey struct {
+	p *Parameters
+	b []byte // PK.seed || PK.root
+}
+
+// NewPrivateKeyFromSeeds computes the key pair for the given seeds, each of
+// [Parameters.SeedSize] bytes, according to FIPS 205, Algorithm 18.
+func NewPrivateKeyFromSeeds(p *Parameters, skSeed, skPRF, pkSeed []byte) (*PrivateKey, error) {
+	n := p.n
+	if len(skSeed) != n || len(skPRF) != n || len(pkSeed) != n {
+		return nil, errors.New("slhdsa: invalid seed length")
+	}
+	b := make([]byte, 0, 4*n)
+	b = append(b, skSeed...)
+	b = append(b, skPRF...)
+	b = append(b, pkSeed...)
+	b = b[:4*n]
+	s := newState(p, b[:n], b[2*n:3*n])
+	var adrs address
+	adrs.setLayerAddress(uint32(p.d - 1))
+	s.xmssNode(b[3*n:], 0, uint32(p.hp), &adrs)
+	return &PrivateKey{p: p, b: b}, nil
+}
+
+// NewPrivateKey parses an encoded private key, recomputing and checking the
+// public key root it embeds.
+func NewPrivateKey(p *Parameters, b []byte) (*PrivateKey, error) {
+	if len(b) != p.PrivateKeySize() {
+		return nil, errors.New("slhdsa: invalid private key length")
+	}
+	n := p.n
+	priv, err := NewPrivateKeyFromSeeds(p, b[:n], b[n:2*n], b[2*n:3*n])
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(priv.b[3*n:], b[3*n:]) != 1 {
+		return nil, errors.New("slhdsa: inconsistent private key")
+	}
+	return priv, nil
+}
+
+// NewPublicKey parses an encoded public key.
+func NewPublicKey(p *Parameters, b []byte) (*PublicKey, error) {
+	if len(b) != p.PublicKeySize() {
+		return nil, errors.New("slhdsa: invalid public key length")
+	}
+	return &PublicKey{p: p, b: append([]byte(nil), b...)}, nil
+}
+
+// Bytes returns the encoded private key, SK.seed || SK.prf || PK.seed ||
+// PK.root.
+func (priv *PrivateKey) Bytes() []byte {
+	return append([]byte(nil), priv.b...)
+}
+
+// Parameters returns the parameter set of the private key.
+func (priv *PrivateKey) Parameters() *Parameters { return priv.p }
+
+// PublicKey returns the public key corresponding to priv.
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{p: priv.p, b: append([]byte(nil), priv.b[2*priv.p.n:]...)}
+}
+
+// Bytes returns the encoded public key, PK.seed || PK.root.
+func (pub *PublicKey) Bytes() []byte {
+	return append([]byte(nil), pub.b...)
+}
+
+// Parameters returns the parameter set of the public key.
+func (pub *PublicKey) Parameters() *Parameters { return pub.p }
+
+// Sign signs msg with the pure SLH-DSA external interface, according to FIPS
+// 205, Algorithm 22. addrnd must be [Parameters.SeedSize] bytes, or nil for
+// the deterministic variant, which uses PK.seed in its place.
+func Sign(priv *PrivateKey, msg []byte, context string, addrnd []byte) ([]byte, error) {
+	prefix, err := messagePrefix(context)
+	if err != nil {
+		return nil, err
+	}
+	n := priv.p.n
+	if addrnd == nil {
+		addrnd = priv.b[2*n : 3*n]
+	}
+	if len(addrnd) != n {
+		return nil, errors.New("slhdsa: invalid randomness length")
+	}
+	return priv.signInternal(prefix, msg, addrnd), nil
+}
+
+// Verify verifies a signature of msg with the pure SLH-DSA external interface,
+// according to FIPS 205, Algorithm 24.
+func Verify(pub *PublicKey, msg, sig []byte, context string) error {
+	prefix, err := messagePrefix(context)
+	if err != nil {
+		return err
+	}
+	if !pub.verifyInternal(prefix, msg, sig) {
+		return errors.New("slhdsa: invalid signature")
+	}
+	return nil
+}
+
+// messagePrefix returns the prefix that domain separates pure SLH-DSA
+// messages, 0 || len(ctx) || ctx, according to FIPS 205, Algorithm 22.
+func messagePrefix(context string) ([]byte, error) {
+	if len(context) > 255 {
+		return nil, errors.New("slhdsa: context too long")
+	}
+	prefix := make([]byte, 0, 2+len(context))
+	prefix = append(prefix, 0, byte(len(context)))
+	return append(prefix, context...), nil
+}
+
+// digestIndices splits the message digest into the FORS message and the
+// hypertree and leaf indices, according to FIPS 205, Algorithm 19, lines 7–12.
+func (p *Parameters) digestIndices(digest []byte) (md []byte, idxTree uint64, idxLeaf uint32) {
+	mdLen := (p.k*p.a + 7) / 8
+	treeLen := (p.h - p.hp + 7) / 8
+	leafLen := (p.hp + 7) / 8
+	md = digest[:mdLen]
+	for _, b := range digest[mdLen : mdLen+treeLen] {
+		idxTree = idxTree<<8 | uint64(b)
+	}
+	if p.h-p.hp < 64 {
+		idxTree &= 1<<(p.h-p.hp) - 1
+	}
+	for _, b := range digest[mdLen+treeLen : mdLen+treeLen+leafLen] {
+		idxLeaf = idxLeaf<<8 | uint32(b)
+	}
+	idxLeaf &= 1<<p.hp - 1
+	return md, idxTree, idxLeaf
+}
+
+// signInternal generates a signature of prefix || msg, according to FIPS 205,
+// Algorithm 19. addrnd must be n bytes.
+func (priv *PrivateKey) signInternal(prefix, msg, addrnd []byte) []byte {
+	p := priv.p
+	n := p.n
+	skSeed, skPRF, pkSeed, pkRoot := priv.b[:n], priv.b[n:2*n], priv.b[2*n:3*n], priv.b[3*n:]
+	s := newState(p, skSeed, pkSeed)
+	sig := make([]byte, p.SignatureSize())
+
+	r := sig[:n]
+	s.hs.prfMsg(r, skPRF, addrnd, prefix, msg)
+	digest := make([]byte, p.m)
+	s.hs.hMsg(digest, r, pkRoot, prefix, msg)
+	md, idxTree, idxLeaf := p.digestIndices(digest)
+
+	var adrs address
+	adrs.setTreeAddress(idxTree)
+	adrs.setTypeAndClear(addressFORSTree)
+	adrs.setKeyPairAddress(idxLeaf)
+	forsSig := sig[n : n+p.forsSigLen()]
+	s.forsSign(forsSig, md, &adrs)
+	pkFORS := make([]byte, n)
+	s.forsPKFromSig(pkFORS, forsSig, md, &adrs)
+
+	s.htSign(sig[n+p.forsSigLen():], pkFORS, idxTree, idxLeaf)
+	return sig
+}
+
+// verifyInternal verifies a signature of prefix || msg, according to FIPS 205,
+// Algorithm 20.
+func (pub *PublicKey) verifyInternal(prefix, msg, sig []byte) bool {
+	p := pub.p
+	n := p.n
+	if len(sig) != p.SignatureSize() {
+		return false
+	}
+	pkSeed, pkRoot := pub.b[:n], pub.b[n:]
+	s := newState(p, nil, pkSeed)
+
+	r := sig[:n]
+	digest := make([]byte, p.m)
+	s.hs.hMsg(digest, r, pkRoot, prefix, msg)
+	md, idxTree, idxLeaf := p.digestIndices(digest)
+
+	var adrs address
+	adrs.setTreeAddress(idxTree)
+	adrs.setTypeAndClear(addressFORSTree)
+	adrs.setKeyPairAddress(idxLeaf)
+	forsSig := sig[n : n+p.forsSigLen()]
+	pkFORS := make([]byte, n)
+	s.forsPKFromSig(pkFORS, forsSig, md, &adrs)
+
+	return s.htVerify(pkFORS, sig[n+p.forsSigLen():], idxTree, idxLeaf, pkRoot)
+}
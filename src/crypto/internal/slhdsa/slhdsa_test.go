@@ -0,0 +1,76 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// TestDeterministic checks that keys and signatures generated with fixed seeds
+// and randomness don't change. The expected values were generated by this
+// implementation, so they only catch regressions, not deviations from FIPS 205.
+func TestDeterministic(t *testing.T) {
+	tests := []struct {
+		params  *Parameters
+		pub     string
+		sigHash string
+	}{
+		{
+			SHA2128f(),
+			"202122232425262728292a2b2c2d2e2f3b56e816847f000386aeec2e2bb9e1b5",
+			"97aafe450483ed64acaa6743ca8d95ea41329f0cfe1dcf7998a6ecdbece3f22b",
+		},
+		{
+			SHAKE128f(),
+			"202122232425262728292a2b2c2d2e2fa90e4715b9a925c332801767fd786371",
+			"d37068b5f2114173273c0aa2bf7680df7df0872cedd5996e7bc8872520371b88",
+		},
+		{
+			SHA2192f(),
+			"303132333435363738393a3b3c3d3e3f40414243444546479236ccebbb3a90ac2452dd89de49dab1340ec02419a2870e",
+			"9ff70f97fe884f7e262d28d986b7ea59ca7bae6fc3d13bbe04bcbbad46040845",
+		},
+		{
+			SHA2256f(),
+			"404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f42cffe64ddbd6731063752684df77c8b58c225dc6b491208916b654ea1393176",
+			"4d8290055f4a1a610915cb6771cf5eac0cdedebe3686d630ef9c54a580431875",
+		},
+		{
+			SHAKE256f(),
+			"404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f818d7e76beef979b5bbf9161fdefa21bd0fe0bfe19157a5711a8de8a8f6878e6",
+			"20b5608813208e4b61aaab099cd4efb58af2028d255e3423db3c0e502f1ad1a0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.params.String(), func(t *testing.T) {
+			n := tt.params.SeedSize()
+			seeds := make([]byte, 3*n)
+			for i := range seeds {
+				seeds[i] = byte(i)
+			}
+			priv, err := NewPrivateKeyFromSeeds(tt.params, seeds[:n], seeds[n:2*n], seeds[2*n:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(priv.PublicKey().Bytes()); got != tt.pub {
+				t.Errorf("public key = %s, want %s", got, tt.pub)
+			}
+			sig, err := Sign(priv, []byte("message"), "ctx", bytes.Repeat([]byte{0xaa}, n))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := sha256.Sum256(sig)
+			if got := hex.EncodeToString(h[:]); got != tt.sigHash {
+				t.Errorf("SHA-256(signature) = %s, want %s", got, tt.sigHash)
+			}
+			if err := Verify(priv.PublicKey(), []byte("message"), sig, "ctx"); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa
+
+// The Winternitz parameter w is 16 (lg_w = 4) for all approved parameter sets,
+// so WOTS+ messages of n bytes are encoded as len1 = 2n base-16 digits,
+// followed by len2 = 3 checksum digits.
+const (
+	wotsW    = 16
+	wotsLogW = 4
+	wotsLen2 = 3
+)
+
+// state holds a parameter set and the seeds used by the signing and
+// verification algorithms. skSeed is nil when only verifying.
+type state struct {
+	*Parameters
+	hs     hashSuite
+	skSeed []byte
+	pkSeed []byte
+}
+
+func newState(p *Parameters, skSeed, pkSeed []byte) *state {
+	return &state{Parameters: p, hs: newHashSuite(p, pkSeed), skSeed: skSeed, pkSeed: pkSeed}
+}
+
+// base2b splits x into len(out) b-bit big-endian integers, according to
+// FIPS 205, Algorithm 4.
+func base2b(out []uint32, x []byte, b int) {
+	var in, bits int
+	var total uint32
+	for i := range out {
+		for bits < b {
+			total = total<<8 | uint32(x[in])
+			in++
+			bits += 8
+		}
+		bits -= b
+		out[i] = (total >> bits) & (1<<b - 1)
+	}
+}
+
+// wotsDigits returns the base-w encoding of m followed by its checksum,
+// according to FIPS 205, Algorithm 7, lines 2–7.
+func (s *state) wotsDigits(m []byte) []uint32 {
+	len1 := 2 * s.n
+	digits := make([]uint32, len1+wotsLen2)
+	base2b(digits[:len1], m, wotsLogW)
+	var csum uint32
+	for _, d := range digits[:len1] {
+		csum += wotsW - 1 - d
+	}
+	// len2 * lg_w = 12 bits, left-aligned in two bytes.
+	csum <<= 4
+	base2b(digits[len1:], []byte{byte(csum >> 8), byte(csum)}, wotsLogW)
+	return digits
+}
+
+// chain applies F s times to x starting at index i, according to FIPS 205,
+// Algorithm 5. out and x may alias.
+func (s *state) chain(out, x []byte, i, steps uint32, adrs *address) {
+	copy(out[:s.n], x[:s.n])
+	for j := i; j < i+steps; j++ {
+		adrs.setHashAddress(j)
+		s.hs.f(out, adrs, out[:s.n])
+	}
+}
+
+// wotsSecret computes the secret value of chain i of the key pair in adrs.
+func (s *state) wotsSecret(out []byte, adrs *address, i uint32) {
+	skADRS := *adrs
+	skADRS.setTypeAndClear(addressWOTSPRF)
+	skADRS.setKeyPairAddress(adrs.keyPairAddress())
+	skADRS.setChainAddress(i)
+	s.hs.prf(out, &skADRS, s.skSeed)
+}
+
+// wotsCompress compresses the chain ends in tmp into a WOTS+ public key.
+func (s *state) wotsCompress(out, tmp []byte, adrs *address) {
+	pkADRS := *adrs
+	pkADRS.setTypeAndClear(addressWOTSPK)
+	pkADRS.setKeyPairAddress(adrs.keyPairAddress())
+	s.hs.h(out, &pkADRS, tmp)
+}
+
+// wotsPKGen computes a WOTS+ public key, according to FIPS 205, Algorithm 6.
+func (s *state) wotsPKGen(out []byte, adrs *address) {
+	tmp := make([]byte, s.wotsLen()*s.n)
+	for i := range s.wotsLen() {
+		chunk := tmp[i*s.n : (i+1)*s.n]
+		s.wotsSecret(chunk, adrs, uint32(i))
+		adrs.setChainAddress(uint32(i))
+		s.chain(chunk, chunk, 0, wotsW-1, adrs)
+	}
+	s.wotsCompress(out, tmp, adrs)
+}
+
+// wotsSign generates a WOTS+ signature on the n-byte message m, according to
+// FIPS 205, Algorithm 7.
+func (s *state) wotsSign(sig, m []byte, adrs *address) {
+	for i, d := range s.wotsDigits(m) {
+		chunk := sig[i*s.n : (i+1)*s.n]
+		s.wotsSecret(chunk, adrs, uint32(i))
+		adrs.setChainAddress(uint32(i))
+		s.chain(chunk, chunk, 0, d, adrs)
+	}
+}
+
+// wotsPKFromSig computes a WOTS+ public key from a signature, according to
+// FIPS 205, Algorithm 8.
+func (s *state) wotsPKFromSig(out, sig, m []byte, adrs *address) {
+	tmp := make([]byte, s.wotsLen()*s.n)
+	for i, d := range s.wotsDigits(m) {
+		adrs.setChainAddress(uint32(i))
+		s.chain(tmp[i*s.n:], sig[i*s.n:], d, wotsW-1-d, adrs)
+	}
+	s.wotsCompress(out, tmp, adrs)
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa
+
+import "crypto/subtle"
+
+// xmssNode computes the root of the subtree of height z with index i,
+// according to FIPS 205, Algorithm 9.
+func (s *state) xmssNode(out []byte, i, z uint32, adrs *address) {
+	if z == 0 {
+		adrs.setTypeAndClear(addressWOTSHash)
+		adrs.setKeyPairAddress(i)
+		s.wotsPKGen(out, adrs)
+		return
+	}
+	buf := make([]byte, 2*s.n)
+	s.xmssNode(buf[:s.n], 2*i, z-1, adrs)
+	s.xmssNode(buf[s.n:], 2*i+1, z-1, adrs)
+	adrs.setTypeAndClear(addressTree)
+	adrs.setTreeHeight(z)
+	adrs.setTreeIndex(i)
+	s.hs.h(out, adrs, buf)
+}
+
+// xmssSign generates an XMSS signature, made of a WOTS+ signature and an
+// authentication path, according to FIPS 205, Algorithm 10.
+func (s *state) xmssSign(sig, m []byte, idx uint32, adrs *address) {
+	wotsSigLen := s.wotsLen() * s.n
+	for j := range uint32(s.hp) {
+		k := (idx >> j) ^ 1
+		off := wotsSigLen + int(j)*s.n
+		s.xmssNode(sig[off:off+s.n], k, j, adrs)
+	}
+	adrs.setTypeAndClear(addressWOTSHash)
+	adrs.setKeyPairAddress(idx)
+	s.wotsSign(sig[:wotsSigLen], m, adrs)
+}
+
+// xmssPKFromSig computes an XMSS root from a signature, according to FIPS
+// 205, Algorithm 11.
+func (s *state) xmssPKFromSig(out []byte, idx uint32, sig, m []byte, adrs *address) {
+	wotsSigLen := s.wotsLen() * s.n
+	adrs.setTypeAndClear(addressWOTSHash)
+	adrs.setKeyPairAddress(idx)
+	buf := make([]byte, 2*s.n)
+	s.wotsPKFromSig(buf[:s.n], sig[:wotsSigLen], m, adrs)
+
+	adrs.setTypeAndClear(addressTree)
+	adrs.setTreeIndex(idx)
+	auth := sig[wotsSigLen:]
+	for k := range uint32(s.hp) {
+		adrs.setTreeHeight(k + 1)
+		authK := auth[int(k)*s.n : int(k+1)*s.n]
+		if (idx>>k)&1 == 0 {
+			adrs.setTreeIndex(adrs.treeIndex() / 2)
+			copy(buf[s.n:], authK)
+		} else {
+			adrs.setTreeIndex((adrs.treeIndex() - 1) / 2)
+			copy(buf[s.n:], buf[:s.n])
+			copy(buf[:s.n], authK)
+		}
+		s.hs.h(buf[:s.n], adrs, buf)
+	}
+	copy(out[:s.n], buf[:s.n])
+}
+
+// htSign generates a hypertree signature, according to FIPS 205, Algorithm 12.
+func (s *state) htSign(sig, m []byte, idxTree uint64, idxLeaf uint32) {
+	xmssSigLen := (s.wotsLen() + s.hp) * s.n
+	var adrs address
+	adrs.setTreeAddress(idxTree)
+	s.xmssSign(sig[:xmssSigLen], m, idxLeaf, &adrs)
+	root := make([]byte, s.n)
+	s.xmssPKFromSig(root, idxLeaf, sig[:xmssSigLen], m, &adrs)
+	for j := 1; j < s.d; j++ {
+		idxLeaf = uint32(idxTree & (1<<s.hp - 1))
+		idxTree >>= s.hp
+		adrs.setLayerAddress(uint32(j))
+		adrs.setTreeAddress(idxTree)
+		layerSig := sig[j*xmssSigLen : (j+1)*xmssSigLen]
+		s.xmssSign(layerSig, root, idxLeaf, &adrs)
+		if j < s.d-1 {
+			s.xmssPKFromSig(root, idxLeaf, layerSig, root, &adrs)
+		}
+	}
+}
+
+// htVerify verifies a hypertree signature, according to FIPS 205, Algorithm 13.
+func (s *state) htVerify(m, sig []byte, idxTree uint64, idxLeaf uint32, pkRoot []byte) bool {
+	xmssSigLen := (s.wotsLen() + s.hp) * s.n
+	var adrs address
+	adrs.setTreeAddress(idxTree)
+	node := make([]byte, s.n)
+	s.xmssPKFromSig(node, idxLeaf, sig[:xmssSigLen], m, &adrs)
+	for j := 1; j < s.d; j++ {
+		idxLeaf = uint32(idxTree & (1<<s.hp - 1))
+		idxTree >>= s.hp
+		adrs.setLayerAddress(uint32(j))
+		adrs.setTreeAddress(idxTree)
+		s.xmssPKFromSig(node, idxLeaf, sig[j*xmssSigLen:(j+1)*xmssSigLen], node, &adrs)
+	}
+	return subtle.ConstantTimeCompare(node, pkRoot) == 1
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mldsa_test
+
+import (
+	"crypto/mldsa"
+	"fmt"
+	"log"
+)
+
+func Example() {
+	// Generate a private key and send the public key to the verifier.
+	priv, err := mldsa.GenerateKey(mldsa.MLDSA65())
+	if err != nil {
+		log.Fatal(err)
+	}
+	publicKeyBytes := priv.PublicKey().Bytes()
+
+	msg := []byte("hello, world")
+	opts := &mldsa.Options{Context: "example protocol v1"}
+	sig, err := priv.Sign(nil, msg, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The verifier parses the public key and checks the signature.
+	pub, err := mldsa.NewPublicKey(mldsa.MLDSA65(), publicKeyBytes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := mldsa.Verify(pub, msg, sig, opts); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("signature verified")
+	// Output: signature verified
+}
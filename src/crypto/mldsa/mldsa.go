@@ -0,0 +1,234 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mldsa implements the quantum-resistant digital signature algorithm
+// ML-DSA (formerly known as Dilithium), as specified in [NIST FIPS 204].
+//
+// Three parameter sets are provided, [MLDSA44], [MLDSA65] and [MLDSA87], in
+// increasing order of security and key and signature size. Most applications
+// should use ML-DSA-65.
+//
+// Only the "pure" variant of ML-DSA is implemented, which signs messages
+// directly, not HashML-DSA, which signs pre-hashed messages.
+//
+// [NIST FIPS 204]: https://doi.org/10.6028/NIST.FIPS.204
+package mldsa
+
+import (
+	"crypto"
+	"crypto/internal/fips140/mldsa"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+const (
+	// SeedSize is the size of a private key seed.
+	SeedSize = 32
+
+	// PublicKeySize44 is the size of an ML-DSA-44 public key.
+	PublicKeySize44 = 1312
+
+	// SignatureSize44 is the size of an ML-DSA-44 signature.
+	SignatureSize44 = 2420
+
+	// PublicKeySize65 is the size of an ML-DSA-65 public key.
+	PublicKeySize65 = 1952
+
+	// SignatureSize65 is the size of an ML-DSA-65 signature.
+	SignatureSize65 = 3309
+
+	// PublicKeySize87 is the size of an ML-DSA-87 public key.
+	PublicKeySize87 = 2592
+
+	// SignatureSize87 is the size of an ML-DSA-87 signature.
+	SignatureSize87 = 4627
+)
+
+// Parameters is an ML-DSA parameter set. The zero value is not a valid
+// parameter set.
+//
+// Parameters values are comparable.
+type Parameters struct {
+	p *mldsa.Parameters
+}
+
+// MLDSA44 returns the ML-DSA-44 parameter set, which targets NIST security
+// category 2.
+func MLDSA44() Parameters { return Parameters{mldsa.MLDSA44()} }
+
+// MLDSA65 returns the ML-DSA-65 parameter set, which targets NIST security
+// category 3.
+func MLDSA65() Parameters { return Parameters{mldsa.MLDSA65()} }
+
+// MLDSA87 returns the ML-DSA-87 parameter set, which targets NIST security
+// category 5.
+func MLDSA87() Parameters { return Parameters{mldsa.MLDSA87()} }
+
+// String returns the name of the parameter set, such as "ML-DSA-65".
+func (params Parameters) String() string {
+	if params.p == nil {
+		return "invalid ML-DSA parameters"
+	}
+	return params.p.String()
+}
+
+// PublicKeySize returns the size of public keys of the parameter set.
+func (params Parameters) PublicKeySize() int {
+	return params.p.PublicKeySize()
+}
+
+// SignatureSize returns the size of signatures of the parameter set.
+func (params Parameters) SignatureSize() int {
+	return params.p.SignatureSize()
+}
+
+// PrivateKey is an ML-DSA private key. It includes various precomputed values.
+//
+// PrivateKey implements [crypto.Signer] and [crypto.MessageSigner].
+type PrivateKey struct {
+	k *mldsa.PrivateKey
+}
+
+// PublicKey is an ML-DSA public key. It includes various precomputed values.
+type PublicKey struct {
+	k *mldsa.PublicKey
+}
+
+// GenerateKey generates a new private key, drawing random bytes from the
+// default crypto/rand source.
+func GenerateKey(params Parameters) (*PrivateKey, error) {
+	if params.p == nil {
+		return nil, errors.New("mldsa: invalid parameters")
+	}
+	k, err := mldsa.GenerateKey(params.p)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{k}, nil
+}
+
+// NewPrivateKey expands a private key from a 32-byte seed, as returned by
+// [PrivateKey.Bytes]. The seed must be uniformly random.
+func NewPrivateKey(params Parameters, seed []byte) (*PrivateKey, error) {
+	if params.p == nil {
+		return nil, errors.New("mldsa: invalid parameters")
+	}
+	k, err := mldsa.NewPrivateKey(params.p, seed)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{k}, nil
+}
+
+// NewPublicKey parses an encoded public key, as returned by
+// [PublicKey.Bytes].
+func NewPublicKey(params Parameters, b []byte) (*PublicKey, error) {
+	if params.p == nil {
+		return nil, errors.New("mldsa: invalid parameters")
+	}
+	k, err := mldsa.NewPublicKey(params.p, b)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKey{k}, nil
+}
+
+// Bytes returns the private key seed, from which the full private key can be
+// expanded with [NewPrivateKey].
+//
+// The private key must be kept secret.
+func (priv *PrivateKey) Bytes() []byte {
+	return priv.k.Bytes()
+}
+
+// Parameters returns the parameter set of the private key.
+func (priv *PrivateKey) Parameters() Parameters {
+	return Parameters{priv.k.PublicKey().Parameters()}
+}
+
+// Public returns the public key corresponding to priv, as a [*PublicKey].
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return priv.PublicKey()
+}
+
+// PublicKey returns the public key corresponding to priv.
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{priv.k.PublicKey()}
+}
+
+// Equal reports whether priv and x have the same value.
+func (priv *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey)
+	if !ok {
+		return false
+	}
+	return priv.k.PublicKey().Parameters() == xx.k.PublicKey().Parameters() &&
+		subtle.ConstantTimeCompare(priv.k.Bytes(), xx.k.Bytes()) == 1
+}
+
+// Sign signs message with priv. rand is ignored and can be nil: signatures
+// are randomized using the default crypto/rand source, as recommended by FIPS
+// 204 to protect against side-channel attacks.
+//
+// opts.HashFunc() must be zero, as ML-DSA signs messages directly. If opts is
+// an [*Options], its Context is used.
+func (priv *PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	if opts.HashFunc() != 0 {
+		return nil, errors.New("mldsa: cannot sign hashed message")
+	}
+	context := ""
+	if opts, ok := opts.(*Options); ok {
+		context = opts.Context
+	}
+	return mldsa.Sign(priv.k, message, context)
+}
+
+// SignMessage is equivalent to [PrivateKey.Sign].
+func (priv *PrivateKey) SignMessage(rand io.Reader, msg []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	return priv.Sign(rand, msg, opts)
+}
+
+// Bytes returns the encoded public key.
+func (pub *PublicKey) Bytes() []byte {
+	return pub.k.Bytes()
+}
+
+// Parameters returns the parameter set of the public key.
+func (pub *PublicKey) Parameters() Parameters {
+	return Parameters{pub.k.Parameters()}
+}
+
+// Equal reports whether pub and x have the same value.
+func (pub *PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return pub.k.Equal(xx.k)
+}
+
+// Options can be used with [PrivateKey.Sign] and [Verify] to provide a
+// context string, which binds signatures to an application or protocol.
+type Options struct {
+	// Context is the ML-DSA context string. It can be at most 255 bytes long.
+	Context string
+}
+
+// HashFunc returns zero, as ML-DSA signs messages directly.
+func (o *Options) HashFunc() crypto.Hash { return 0 }
+
+// Verify reports whether sig is a valid signature of message by pub, returning
+// an error if it isn't. opts may be nil, which is equivalent to an empty
+// context string.
+func Verify(pub *PublicKey, message, sig []byte, opts *Options) error {
+	context := ""
+	if opts != nil {
+		context = opts.Context
+	}
+	if err := mldsa.Verify(pub.k, message, sig, context); err != nil {
+		return errors.New("mldsa: invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mldsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"testing"
+)
+
+var allParameters = []struct {
+	params        Parameters
+	pubSize       int
+	signatureSize int
+}{
+	{MLDSA44(), PublicKeySize44, SignatureSize44},
+	{MLDSA65(), PublicKeySize65, SignatureSize65},
+	{MLDSA87(), PublicKeySize87, SignatureSize87},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range allParameters {
+		t.Run(tt.params.String(), func(t *testing.T) {
+			priv, err := GenerateKey(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if priv.Parameters() != tt.params || tt.params.PublicKeySize() != tt.pubSize || tt.params.SignatureSize() != tt.signatureSize {
+				t.Fatalf("unexpected parameters")
+			}
+			pub := priv.PublicKey()
+			if len(pub.Bytes()) != tt.pubSize {
+				t.Errorf("public key is %d bytes, want %d", len(pub.Bytes()), tt.pubSize)
+			}
+
+			msg := []byte("message")
+			var signer crypto.MessageSigner = priv
+			sig, err := signer.SignMessage(nil, msg, crypto.Hash(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sig) != tt.signatureSize {
+				t.Errorf("signature is %d bytes, want %d", len(sig), tt.signatureSize)
+			}
+			if err := Verify(pub, msg, sig, nil); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if err := Verify(pub, []byte("other message"), sig, nil); err == nil {
+				t.Errorf("Verify accepted a signature for a different message")
+			}
+			if err := Verify(pub, msg, sig, &Options{Context: "ctx"}); err == nil {
+				t.Errorf("Verify accepted a signature with a different context")
+			}
+
+			sig, err = priv.Sign(rand.Reader, msg, &Options{Context: "ctx"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(pub, msg, sig, &Options{Context: "ctx"}); err != nil {
+				t.Errorf("Verify with context: %v", err)
+			}
+			if _, err := priv.Sign(nil, msg, crypto.SHA256); err == nil {
+				t.Errorf("Sign accepted a hashed message")
+			}
+
+			priv2, err := NewPrivateKey(tt.params, priv.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !priv.Equal(priv2) || !priv2.PublicKey().Equal(pub) {
+				t.Errorf("private key from seed doesn't match")
+			}
+			pub2, err := NewPublicKey(tt.params, pub.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pub.Equal(pub2) || !bytes.Equal(pub.Bytes(), pub2.Bytes()) {
+				t.Errorf("parsed public key doesn't match")
+			}
+			if _, err := NewPublicKey(tt.params, pub.Bytes()[1:]); err == nil {
+				t.Errorf("NewPublicKey accepted a short key")
+			}
+		})
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	if _, err := GenerateKey(Parameters{}); err == nil {
+		t.Error("GenerateKey accepted zero Parameters")
+	}
+	if _, err := NewPrivateKey(Parameters{}, make([]byte, SeedSize)); err == nil {
+		t.Error("NewPrivateKey accepted zero Parameters")
+	}
+	if _, err := NewPrivateKey(MLDSA44(), make([]byte, SeedSize-1)); err == nil {
+		t.Error("NewPrivateKey accepted a short seed")
+	}
+	priv44, _ := NewPrivateKey(MLDSA44(), make([]byte, SeedSize))
+	priv65, _ := NewPrivateKey(MLDSA65(), make([]byte, SeedSize))
+	if priv44.Equal(priv65) || priv44.PublicKey().Equal(priv65.PublicKey()) {
+		t.Error("keys with different parameters are equal")
+	}
+}
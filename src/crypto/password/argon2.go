@@ -0,0 +1,305 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"crypto/internal/fips140only"
+	"errors"
+	"internal/byteorder"
+	"math/bits"
+	"sync"
+)
+
+const (
+	argon2Version    = 0x13
+	argon2idType     = 2
+	argon2SyncPoints = 4
+
+	// argon2AddressesPerBlock is the number of pseudo-random values produced
+	// by each address block in data-independent addressing.
+	argon2AddressesPerBlock = 128
+)
+
+// argon2Block is a 1 KiB Argon2 memory block, viewed as 128 64-bit words.
+type argon2Block [128]uint64
+
+// Argon2id derives a key from the password and salt using Argon2id, as
+// specified in RFC 9106, returning a []byte of length keyLength.
+//
+// time is the number of passes over the memory, memory is its size in KiB,
+// and threads is the number of lanes that can be computed in parallel. All
+// three parameters affect the derived key, and must be at least 1, 8*threads,
+// and 1 respectively. RFC 9106 recommends time=1, memory=2097152 (2 GiB) and
+// threads=4 when that much memory is available, and time=3, memory=65536
+// (64 MiB), threads=4 otherwise. OWASP recommends at least time=2,
+// memory=19456 (19 MiB) and threads=1.
+//
+// The salt must be at least 8 bytes long, and should be 16 random bytes.
+// keyLength must be at least 4.
+func Argon2id(password string, salt []byte, time, memory uint32, threads uint8, keyLength uint32) ([]byte, error) {
+	if fips140only.Enabled {
+		return nil, errors.New("crypto/password: use of Argon2id is not allowed in FIPS 140-only mode")
+	}
+	if time < 1 {
+		return nil, errors.New("crypto/password: Argon2id time must be at least 1")
+	}
+	if threads < 1 {
+		return nil, errors.New("crypto/password: Argon2id threads must be at least 1")
+	}
+	if memory < 8*uint32(threads) {
+		return nil, errors.New("crypto/password: Argon2id memory must be at least 8*threads KiB")
+	}
+	if len(salt) < 8 {
+		return nil, errors.New("crypto/password: Argon2id salt must be at least 8 bytes")
+	}
+	if keyLength < 4 {
+		return nil, errors.New("crypto/password: Argon2id key length must be at least 4")
+	}
+	return argon2id([]byte(password), salt, nil, nil, time, memory, threads, keyLength), nil
+}
+
+// argon2id implements Argon2id, with the optional secret and associated data
+// inputs, according to RFC 9106, Section 3.2.
+func argon2id(password, salt, secret, data []byte, time, memory uint32, threads uint8, keyLength uint32) []byte {
+	lanes := uint32(threads)
+	h0 := argon2H0(password, salt, secret, data, time, memory, lanes, keyLength)
+
+	// The memory is rounded down to a multiple of 4*lanes blocks, and each
+	// lane is split into four segments, one per synchronization point.
+	memory = memory / (argon2SyncPoints * lanes) * (argon2SyncPoints * lanes)
+	laneLength := memory / lanes
+	segmentLength := laneLength / argon2SyncPoints
+	B := make([]argon2Block, memory)
+
+	var buf [1024]byte
+	for lane := range lanes {
+		for i := range uint32(2) {
+			var suffix [8]byte
+			byteorder.LEPutUint32(suffix[0:4], i)
+			byteorder.LEPutUint32(suffix[4:8], lane)
+			blake2bLong(buf[:], h0[:], suffix[:])
+			b := &B[lane*laneLength+i]
+			for j := range b {
+				b[j] = byteorder.LEUint64(buf[j*8:])
+			}
+		}
+	}
+
+	inst := &argon2Instance{
+		B:             B,
+		passes:        time,
+		lanes:         lanes,
+		laneLength:    laneLength,
+		segmentLength: segmentLength,
+	}
+	for pass := range time {
+		for slice := range uint32(argon2SyncPoints) {
+			if lanes == 1 {
+				inst.fillSegment(pass, 0, slice)
+				continue
+			}
+			var wg sync.WaitGroup
+			for lane := range lanes {
+				wg.Go(func() { inst.fillSegment(pass, lane, slice) })
+			}
+			wg.Wait()
+		}
+	}
+
+	// The final block is the XOR of the last block of each lane.
+	var c argon2Block
+	for lane := range lanes {
+		last := &B[lane*laneLength+laneLength-1]
+		for j := range c {
+			c[j] ^= last[j]
+		}
+	}
+	for j, w := range c {
+		byteorder.LEPutUint64(buf[j*8:], w)
+	}
+	out := make([]byte, keyLength)
+	blake2bLong(out, buf[:])
+	return out
+}
+
+// argon2H0 computes the 64-byte pre-hashing digest H0.
+func argon2H0(password, salt, secret, data []byte, time, memory, lanes, keyLength uint32) [blake2bSize]byte {
+	d := newBLAKE2b(blake2bSize)
+	var w [4]byte
+	put := func(v uint32) {
+		byteorder.LEPutUint32(w[:], v)
+		d.Write(w[:])
+	}
+	put(lanes)
+	put(keyLength)
+	put(memory)
+	put(time)
+	put(argon2Version)
+	put(argon2idType)
+	for _, b := range [][]byte{password, salt, secret, data} {
+		put(uint32(len(b)))
+		d.Write(b)
+	}
+	var h0 [blake2bSize]byte
+	d.Sum(h0[:0])
+	return h0
+}
+
+type argon2Instance struct {
+	B             []argon2Block
+	passes        uint32
+	lanes         uint32
+	laneLength    uint32
+	segmentLength uint32
+}
+
+// fillSegment computes the blocks of one segment, according to RFC 9106,
+// Section 3.4. Argon2id uses data-independent addressing for the first half
+// of the first pass, and data-dependent addressing afterwards.
+func (inst *argon2Instance) fillSegment(pass, lane, slice uint32) {
+	dataIndependent := pass == 0 && slice < argon2SyncPoints/2
+
+	var input, addresses, zero argon2Block
+	if dataIndependent {
+		input[0] = uint64(pass)
+		input[1] = uint64(lane)
+		input[2] = uint64(slice)
+		input[3] = uint64(len(inst.B))
+		input[4] = uint64(inst.passes)
+		input[5] = argon2idType
+	}
+	nextAddresses := func() {
+		input[6]++
+		argon2Fill(&addresses, &zero, &input, false)
+		argon2Fill(&addresses, &zero, &addresses, false)
+	}
+
+	start := uint32(0)
+	if pass == 0 && slice == 0 {
+		// The first two blocks of each lane are already initialized.
+		start = 2
+		if dataIndependent {
+			nextAddresses()
+		}
+	}
+
+	laneStart := lane * inst.laneLength
+	for index := start; index < inst.segmentLength; index++ {
+		offset := slice*inst.segmentLength + index
+		prev := offset - 1
+		if offset == 0 {
+			prev = inst.laneLength - 1
+		}
+
+		var pseudoRand uint64
+		if dataIndependent {
+			if index%argon2AddressesPerBlock == 0 {
+				nextAddresses()
+			}
+			pseudoRand = addresses[index%argon2AddressesPerBlock]
+		} else {
+			pseudoRand = inst.B[laneStart+prev][0]
+		}
+
+		refLane := uint32(pseudoRand>>32) % inst.lanes
+		if pass == 0 && slice == 0 {
+			refLane = lane
+		}
+		refIndex := inst.referenceIndex(pass, slice, index, uint32(pseudoRand), refLane == lane)
+
+		argon2Fill(&inst.B[laneStart+offset],
+			&inst.B[laneStart+prev], &inst.B[refLane*inst.laneLength+refIndex], pass > 0)
+	}
+}
+
+// referenceIndex maps J1 to the index of the reference block within its lane,
+// according to RFC 9106, Section 3.4.2.
+func (inst *argon2Instance) referenceIndex(pass, slice, index, j1 uint32, sameLane bool) uint32 {
+	// The reference set includes all blocks computed so far in the lane, or
+	// the completed segments of other lanes, excluding the previous block.
+	var area uint32
+	switch {
+	case pass == 0 && slice == 0:
+		area = index - 1
+	case pass == 0 && sameLane:
+		area = slice*inst.segmentLength + index - 1
+	case pass == 0:
+		area = slice * inst.segmentLength
+		if index == 0 {
+			area--
+		}
+	case sameLane:
+		area = inst.laneLength - inst.segmentLength + index - 1
+	default:
+		area = inst.laneLength - inst.segmentLength
+		if index == 0 {
+			area--
+		}
+	}
+
+	x := uint64(j1) * uint64(j1) >> 32
+	y := uint64(area) * x >> 32
+	relative := uint64(area) - 1 - y
+
+	var start uint32
+	if pass != 0 && slice != argon2SyncPoints-1 {
+		start = (slice + 1) * inst.segmentLength
+	}
+	return uint32((uint64(start) + relative) % uint64(inst.laneLength))
+}
+
+// argon2Fill sets out to G(x, y), XORed with the previous contents of out if
+// xor is true, according to RFC 9106, Section 3.5.
+func argon2Fill(out, x, y *argon2Block, xor bool) {
+	var r, z argon2Block
+	for i := range r {
+		r[i] = x[i] ^ y[i]
+	}
+	z = r
+	for i := 0; i < 128; i += 16 {
+		blamka(&z[i], &z[i+1], &z[i+2], &z[i+3], &z[i+4], &z[i+5], &z[i+6], &z[i+7],
+			&z[i+8], &z[i+9], &z[i+10], &z[i+11], &z[i+12], &z[i+13], &z[i+14], &z[i+15])
+	}
+	for i := 0; i < 16; i += 2 {
+		blamka(&z[i], &z[i+1], &z[i+16], &z[i+17], &z[i+32], &z[i+33], &z[i+48], &z[i+49],
+			&z[i+64], &z[i+65], &z[i+80], &z[i+81], &z[i+96], &z[i+97], &z[i+112], &z[i+113])
+	}
+	if xor {
+		for i := range out {
+			out[i] ^= z[i] ^ r[i]
+		}
+	} else {
+		for i := range out {
+			out[i] = z[i] ^ r[i]
+		}
+	}
+}
+
+// blamka is the permutation P, applied to eight 16-byte registers.
+func blamka(v0, v1, v2, v3, v4, v5, v6, v7, v8, v9, v10, v11, v12, v13, v14, v15 *uint64) {
+	gb(v0, v4, v8, v12)
+	gb(v1, v5, v9, v13)
+	gb(v2, v6, v10, v14)
+	gb(v3, v7, v11, v15)
+	gb(v0, v5, v10, v15)
+	gb(v1, v6, v11, v12)
+	gb(v2, v7, v8, v13)
+	gb(v3, v4, v9, v14)
+}
+
+// gb is the BLAKE2b round function modified with 32-bit multiplications.
+func gb(a, b, c, d *uint64) {
+	fBlaMka := func(x, y uint64) uint64 {
+		return x + y + 2*uint64(uint32(x))*uint64(uint32(y))
+	}
+	*a = fBlaMka(*a, *b)
+	*d = bits.RotateLeft64(*d^*a, -32)
+	*c = fBlaMka(*c, *d)
+	*b = bits.RotateLeft64(*b^*c, -24)
+	*a = fBlaMka(*a, *b)
+	*d = bits.RotateLeft64(*d^*a, -16)
+	*c = fBlaMka(*c, *d)
+	*b = bits.RotateLeft64(*b^*c, -63)
+}
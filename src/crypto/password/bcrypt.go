@@ -0,0 +1,143 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"crypto/internal/blowfish"
+	"crypto/internal/fips140only"
+	"encoding/base64"
+	"errors"
+	"internal/byteorder"
+	"strconv"
+)
+
+// bcrypt, as implemented by OpenBSD. See "A Future-Adaptable Password Scheme"
+// by Niels Provos and David Mazières, and OpenBSD's lib/libc/crypt/bcrypt.c.
+
+const (
+	bcryptSaltLength  = 16
+	bcryptHashLength  = 23 // the last byte of the output is not encoded
+	bcryptMaxPassword = 72
+
+	minBcryptCost = 4
+	maxBcryptCost = 31
+)
+
+// bcryptEncoding is the base64 variant of bcrypt. It is not strict, as some
+// implementations produce salts with non-zero trailing bits.
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+var bcryptMagic = []byte("OrpheanBeholderScryDoubt")
+
+// bcrypt returns the 23 encoded bytes of the bcrypt hash of password, with a
+// 16-byte salt and 2^cost rounds.
+func bcrypt(password string, salt []byte, cost int) ([]byte, error) {
+	if fips140only.Enabled {
+		return nil, errors.New("crypto/password: use of bcrypt is not allowed in FIPS 140-only mode")
+	}
+	if cost < minBcryptCost || cost > maxBcryptCost {
+		return nil, errors.New("crypto/password: bcrypt cost out of range")
+	}
+	if len(salt) != bcryptSaltLength {
+		return nil, errors.New("crypto/password: bcrypt salt must be 16 bytes")
+	}
+	// Longer passwords would be silently truncated.
+	if len(password) > bcryptMaxPassword {
+		return nil, errors.New("crypto/password: bcrypt password longer than 72 bytes")
+	}
+	// The key includes the NUL terminator of the password.
+	key := append([]byte(password), 0)
+
+	c := blowfish.NewSalted(key, salt)
+	for range uint64(1) << cost {
+		c.ExpandKey(key, nil)
+		c.ExpandKey(salt, nil)
+	}
+	out := make([]byte, len(bcryptMagic))
+	for i := 0; i < len(bcryptMagic); i += 8 {
+		l := byteorder.BEUint32(bcryptMagic[i:])
+		r := byteorder.BEUint32(bcryptMagic[i+4:])
+		for range 64 {
+			l, r = c.Encrypt(l, r)
+		}
+		byteorder.BEPutUint32(out[i:], l)
+		byteorder.BEPutUint32(out[i+4:], r)
+	}
+	return out[:bcryptHashLength], nil
+}
+
+// BcryptParams are the parameters of bcrypt, which is supported for
+// compatibility with existing password databases. bcrypt only uses the first
+// 72 bytes of passwords, so [Hash] and [Verify] return an error for longer
+// ones.
+//
+// bcrypt hashes are encoded in the Modular Crypt Format, such as
+//
+//	$2b$10$<salt><hash>
+//
+// rather than as PHC strings. [Verify] accepts the $2a$, $2b$ and $2y$
+// variants, which are identical for passwords of at most 72 bytes.
+type BcryptParams struct {
+	// Cost is the base-2 logarithm of the number of rounds. It must be
+	// between 4 and 16, the limit of Verify.
+	Cost int
+}
+
+// DefaultBcryptParams returns the bcrypt parameters recommended by OWASP as
+// of 2025.
+func DefaultBcryptParams() *BcryptParams {
+	return &BcryptParams{Cost: 10}
+}
+
+func (p *BcryptParams) hash(password string, salt []byte) ([]byte, error) {
+	return bcrypt(password, salt, p.Cost)
+}
+
+func (p *BcryptParams) encode(salt, key []byte) string {
+	cost := strconv.Itoa(p.Cost)
+	if p.Cost < 10 {
+		cost = "0" + cost
+	}
+	return "$2b$" + cost + "$" + bcryptEncoding.EncodeToString(salt) + bcryptEncoding.EncodeToString(key)
+}
+
+func (p *BcryptParams) saltLen() int { return bcryptSaltLength }
+
+func (p *BcryptParams) checkLimits() error {
+	if p.Cost > maxVerifyBcryptCost {
+		return errors.New("crypto/password: bcrypt cost exceeds the limit of Verify")
+	}
+	return nil
+}
+
+func (p *BcryptParams) equal(x Params) bool {
+	xx, ok := x.(*BcryptParams)
+	return ok && p.Cost == xx.Cost
+}
+
+// parseBcrypt parses the fields of a bcrypt hash after the variant,
+// <cost>$<salt><hash>.
+func parseBcrypt(cost, saltAndHash string) (params Params, salt, key []byte, err error) {
+	if len(cost) != 2 || cost[0] < '0' || cost[0] > '9' || cost[1] < '0' || cost[1] > '9' {
+		return nil, nil, nil, errInvalidHash
+	}
+	const saltChars = 22
+	if len(saltAndHash) != saltChars+31 {
+		return nil, nil, nil, errInvalidHash
+	}
+	salt, err = bcryptEncoding.DecodeString(saltAndHash[:saltChars])
+	if err != nil {
+		return nil, nil, nil, errInvalidHash
+	}
+	key, err = bcryptEncoding.DecodeString(saltAndHash[saltChars:])
+	if err != nil {
+		return nil, nil, nil, errInvalidHash
+	}
+	c := int(cost[0]-'0')*10 + int(cost[1]-'0')
+	if c < minBcryptCost || c > maxBcryptCost {
+		return nil, nil, nil, errInvalidHash
+	}
+	return &BcryptParams{Cost: c}, salt, key, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"internal/byteorder"
+	"math/bits"
+)
+
+// blake2b is an unkeyed BLAKE2b hash with a variable output size of up to 64
+// bytes, as specified in RFC 7693. It only implements what Argon2 needs.
+type blake2b struct {
+	h    [8]uint64
+	t    uint64 // low word of the byte counter, Argon2 inputs are far smaller
+	buf  [blake2bBlockSize]byte
+	nbuf int
+	size int
+}
+
+const (
+	blake2bBlockSize = 128
+	blake2bSize      = 64
+)
+
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var blake2bSigma = [12][16]byte{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+}
+
+func newBLAKE2b(size int) *blake2b {
+	if size < 1 || size > blake2bSize {
+		panic("crypto/password: invalid BLAKE2b output size")
+	}
+	d := &blake2b{h: blake2bIV, size: size}
+	d.h[0] ^= 0x01010000 ^ uint64(size)
+	return d
+}
+
+func (d *blake2b) Write(p []byte) {
+	for len(p) > 0 {
+		// The last block must be processed with the final flag set, so only
+		// compress a full buffer when more input follows.
+		if d.nbuf == blake2bBlockSize {
+			d.t += blake2bBlockSize
+			d.compress(false)
+			d.nbuf = 0
+		}
+		n := copy(d.buf[d.nbuf:], p)
+		d.nbuf += n
+		p = p[n:]
+	}
+}
+
+// Sum appends the digest to b. The state must not be used afterwards.
+func (d *blake2b) Sum(b []byte) []byte {
+	d.t += uint64(d.nbuf)
+	clear(d.buf[d.nbuf:])
+	d.compress(true)
+	var out [blake2bSize]byte
+	for i, h := range d.h {
+		byteorder.LEPutUint64(out[i*8:], h)
+	}
+	return append(b, out[:d.size]...)
+}
+
+func (d *blake2b) compress(final bool) {
+	var m [16]uint64
+	for i := range m {
+		m[i] = byteorder.LEUint64(d.buf[i*8:])
+	}
+	var v [16]uint64
+	copy(v[:8], d.h[:])
+	copy(v[8:], blake2bIV[:])
+	v[12] ^= d.t
+	if final {
+		v[14] = ^v[14]
+	}
+	g := func(a, b, c, d int, x, y uint64) {
+		v[a] = v[a] + v[b] + x
+		v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+		v[c] = v[c] + v[d]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+		v[a] = v[a] + v[b] + y
+		v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+		v[c] = v[c] + v[d]
+		v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+	}
+	for _, s := range &blake2bSigma {
+		g(0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+	for i := range d.h {
+		d.h[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// blake2bLong computes the variable-length hash function H′ of Argon2, which
+// fills out with the hash of LE32(len(out)) || in, according to RFC 9106,
+// Section 3.3.
+func blake2bLong(out []byte, in ...[]byte) {
+	var outLen [4]byte
+	byteorder.LEPutUint32(outLen[:], uint32(len(out)))
+	if len(out) <= blake2bSize {
+		d := newBLAKE2b(len(out))
+		d.Write(outLen[:])
+		for _, b := range in {
+			d.Write(b)
+		}
+		d.Sum(out[:0])
+		return
+	}
+
+	d := newBLAKE2b(blake2bSize)
+	d.Write(outLen[:])
+	for _, b := range in {
+		d.Write(b)
+	}
+	var v [blake2bSize]byte
+	d.Sum(v[:0])
+	// Each intermediate hash contributes its first 32 bytes, and the last
+	// one is sized to fill the remainder.
+	for {
+		copy(out, v[:32])
+		out = out[32:]
+		if len(out) <= blake2bSize {
+			break
+		}
+		d = newBLAKE2b(blake2bSize)
+		d.Write(v[:])
+		d.Sum(v[:0])
+	}
+	d = newBLAKE2b(len(out))
+	d.Write(v[:])
+	d.Sum(out[:0])
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password_test
+
+import (
+	"crypto/password"
+	"errors"
+	"fmt"
+	"log"
+)
+
+func Example() {
+	// When the user sets their password, store the PHC string.
+	stored, err := password.Hash("correct horse battery staple", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// When the user logs in, check the password against the stored string.
+	err = password.Verify("correct horse battery staple", stored)
+	if errors.Is(err, password.ErrMismatchedHashAndPassword) {
+		fmt.Println("wrong password")
+		return
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("logged in")
+
+	// After a successful login, upgrade hashes made with old parameters.
+	if password.NeedsRehash(stored, nil) {
+		stored, err = password.Hash("correct horse battery staple", nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Output: logged in
+}
@@ -0,0 +1,324 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package password implements the memory-hard password hashing functions
+// Argon2id and scrypt, for storing and verifying passwords, and bcrypt, for
+// compatibility with existing password databases.
+//
+// [Hash] generates a random salt and returns a self-describing string in the
+// [PHC string format], or in the Modular Crypt Format for bcrypt, which
+// records the algorithm and cost parameters next to the salt and hash, and
+// [Verify] checks a password against such a string.
+// [NeedsRehash] reports whether a stored hash was generated with different
+// parameters, so that applications can upgrade it on the next successful
+// login.
+//
+// The lower-level [Argon2id] and [Scrypt] functions can also be used to derive
+// encryption keys from passwords.
+//
+// [PHC string format]: https://github.com/P-H-C/phc-string-format/blob/master/phc-sf-spec.md
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ErrMismatchedHashAndPassword is returned by [Verify] when the password
+// doesn't match the hash.
+var ErrMismatchedHashAndPassword = errors.New("crypto/password: hashed password does not match the given password")
+
+const (
+	defaultSaltLength = 16
+	defaultKeyLength  = 32
+)
+
+// The limits on the cost parameters accepted by Verify, which bound the work
+// done to verify a hash from an untrusted source.
+const (
+	maxVerifyArgon2idMemory = 1 << 21 // in KiB, 2 GiB
+	maxVerifyArgon2idTime   = 16
+	maxVerifyScryptMemory   = 1 << 31 // in bytes, 128*N*r
+	maxVerifyScryptP        = 16
+	maxVerifyBcryptCost     = 16
+)
+
+// Params are the parameters of a password hashing function. They are
+// implemented by [*Argon2idParams], [*ScryptParams] and [*BcryptParams].
+type Params interface {
+	// hash derives a key from password and salt.
+	hash(password string, salt []byte) ([]byte, error)
+	// encode returns the PHC string, or bcrypt hash, for salt and key.
+	encode(salt, key []byte) string
+	saltLen() int
+	// checkLimits returns an error if the cost parameters exceed the
+	// limits of Verify.
+	checkLimits() error
+	equal(Params) bool
+}
+
+// Argon2idParams are the parameters of Argon2id. See [Argon2id] for their
+// meaning.
+type Argon2idParams struct {
+	Time    uint32
+	Memory  uint32 // in KiB
+	Threads uint8
+
+	// SaltLength and KeyLength are the lengths of the random salt and of the
+	// hash, in bytes. If zero, 16 and 32 are used.
+	SaltLength int
+	KeyLength  int
+}
+
+// DefaultArgon2idParams returns the Argon2id parameters recommended by OWASP
+// as of 2025, requiring 19 MiB of memory.
+func DefaultArgon2idParams() *Argon2idParams {
+	return &Argon2idParams{Time: 2, Memory: 19 * 1024, Threads: 1}
+}
+
+func (p *Argon2idParams) hash(password string, salt []byte) ([]byte, error) {
+	return Argon2id(password, salt, p.Time, p.Memory, p.Threads, uint32(keyLength(p.KeyLength)))
+}
+
+func (p *Argon2idParams) encode(salt, key []byte) string {
+	return "$argon2id$v=19$m=" + strconv.FormatUint(uint64(p.Memory), 10) +
+		",t=" + strconv.FormatUint(uint64(p.Time), 10) +
+		",p=" + strconv.Itoa(int(p.Threads)) +
+		"$" + base64.RawStdEncoding.EncodeToString(salt) +
+		"$" + base64.RawStdEncoding.EncodeToString(key)
+}
+
+func (p *Argon2idParams) saltLen() int { return saltLength(p.SaltLength) }
+
+func (p *Argon2idParams) checkLimits() error {
+	if p.Memory > maxVerifyArgon2idMemory || p.Time > maxVerifyArgon2idTime {
+		return errors.New("crypto/password: Argon2id parameters exceed the limits of Verify")
+	}
+	return nil
+}
+
+func (p *Argon2idParams) equal(x Params) bool {
+	xx, ok := x.(*Argon2idParams)
+	return ok && p.Time == xx.Time && p.Memory == xx.Memory && p.Threads == xx.Threads &&
+		saltLength(p.SaltLength) == saltLength(xx.SaltLength) &&
+		keyLength(p.KeyLength) == keyLength(xx.KeyLength)
+}
+
+// ScryptParams are the parameters of scrypt. See [Scrypt] for their meaning.
+type ScryptParams struct {
+	N, R, P int
+
+	// SaltLength and KeyLength are the lengths of the random salt and of the
+	// hash, in bytes. If zero, 16 and 32 are used.
+	SaltLength int
+	KeyLength  int
+}
+
+// DefaultScryptParams returns the scrypt parameters recommended by OWASP as
+// of 2025, requiring 128 MiB of memory.
+func DefaultScryptParams() *ScryptParams {
+	return &ScryptParams{N: 1 << 17, R: 8, P: 1}
+}
+
+func (p *ScryptParams) hash(password string, salt []byte) ([]byte, error) {
+	return Scrypt(password, salt, p.N, p.R, p.P, keyLength(p.KeyLength))
+}
+
+func (p *ScryptParams) encode(salt, key []byte) string {
+	return "$scrypt$ln=" + strconv.Itoa(bits.TrailingZeros(uint(p.N))) +
+		",r=" + strconv.Itoa(p.R) +
+		",p=" + strconv.Itoa(p.P) +
+		"$" + base64.RawStdEncoding.EncodeToString(salt) +
+		"$" + base64.RawStdEncoding.EncodeToString(key)
+}
+
+func (p *ScryptParams) saltLen() int { return saltLength(p.SaltLength) }
+
+func (p *ScryptParams) checkLimits() error {
+	if p.N < 0 || p.R < 0 || uint64(p.N)*uint64(p.R) > maxVerifyScryptMemory/128 || p.P > maxVerifyScryptP {
+		return errors.New("crypto/password: scrypt parameters exceed the limits of Verify")
+	}
+	return nil
+}
+
+func (p *ScryptParams) equal(x Params) bool {
+	xx, ok := x.(*ScryptParams)
+	return ok && p.N == xx.N && p.R == xx.R && p.P == xx.P &&
+		saltLength(p.SaltLength) == saltLength(xx.SaltLength) &&
+		keyLength(p.KeyLength) == keyLength(xx.KeyLength)
+}
+
+func saltLength(n int) int {
+	if n == 0 {
+		return defaultSaltLength
+	}
+	return n
+}
+
+func keyLength(n int) int {
+	if n == 0 {
+		return defaultKeyLength
+	}
+	return n
+}
+
+// Hash hashes password with a random salt, drawn from the default
+// crypto/rand source, and returns the salt, parameters and hash encoded as a
+// PHC string, such as
+//
+//	$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+//
+// or in the format of bcrypt, see [BcryptParams].
+//
+// If params is nil, [DefaultArgon2idParams] is used. Hash returns an error if
+// the cost parameters exceed the limits of [Verify].
+func Hash(password string, params Params) (string, error) {
+	if params == nil {
+		params = DefaultArgon2idParams()
+	}
+	if err := params.checkLimits(); err != nil {
+		return "", err
+	}
+	salt := make([]byte, params.saltLen())
+	rand.Read(salt)
+	key, err := params.hash(password, salt)
+	if err != nil {
+		return "", err
+	}
+	return params.encode(salt, key), nil
+}
+
+// Verify reports whether password matches hash, a string as returned by
+// [Hash], returning [ErrMismatchedHashAndPassword] if it doesn't, or another
+// error if hash can't be parsed.
+//
+// The hash is recomputed with the parameters encoded in the string. To bound
+// the work this takes, Verify returns an error if they exceed the following
+// limits: for Argon2id, 2 GiB of memory and a time parameter of 16; for
+// scrypt, 2 GiB of memory (128*N*r bytes) and a parallelization parameter of
+// 16; and for bcrypt, a cost of 16.
+func Verify(password, hash string) error {
+	params, salt, key, err := parse(hash)
+	if err != nil {
+		return err
+	}
+	if err := params.checkLimits(); err != nil {
+		return err
+	}
+	got, err := params.hash(password, salt)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(got, key) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+	return nil
+}
+
+// NeedsRehash reports whether hash, a string as returned by [Hash], was
+// generated with an algorithm or parameters other than params, or can't be
+// parsed. If params is nil, [DefaultArgon2idParams] is used.
+func NeedsRehash(hash string, params Params) bool {
+	if params == nil {
+		params = DefaultArgon2idParams()
+	}
+	p, _, _, err := parse(hash)
+	return err != nil || !p.equal(params)
+}
+
+var errInvalidHash = errors.New("crypto/password: invalid PHC string")
+
+// parse parses a PHC string, or a bcrypt hash, for one of the supported
+// algorithms.
+func parse(hash string) (params Params, salt, key []byte, err error) {
+	fields := strings.Split(hash, "$")
+	if len(fields) < 2 || fields[0] != "" {
+		return nil, nil, nil, errInvalidHash
+	}
+	switch fields[1] {
+	case "2a", "2b", "2y":
+		// $2b$<cost>$<salt><hash>
+		if len(fields) != 4 {
+			return nil, nil, nil, errInvalidHash
+		}
+		return parseBcrypt(fields[2], fields[3])
+	case "argon2id":
+		// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+		if len(fields) != 6 {
+			return nil, nil, nil, errInvalidHash
+		}
+		if fields[2] != "v=19" {
+			return nil, nil, nil, errors.New("crypto/password: unsupported Argon2 version")
+		}
+		values, ok := parseParams(fields[3], "m", "t", "p")
+		if !ok || values[0] > 1<<32-1 || values[1] > 1<<32-1 || values[2] > 255 {
+			return nil, nil, nil, errInvalidHash
+		}
+		p := &Argon2idParams{Memory: uint32(values[0]), Time: uint32(values[1]), Threads: uint8(values[2])}
+		params = p
+		salt, key, ok = parseSaltAndKey(fields[4], fields[5])
+		if !ok {
+			return nil, nil, nil, errInvalidHash
+		}
+		p.SaltLength, p.KeyLength = len(salt), len(key)
+	case "scrypt":
+		// $scrypt$ln=<log2(N)>,r=<r>,p=<p>$<salt>$<hash>
+		if len(fields) != 5 {
+			return nil, nil, nil, errInvalidHash
+		}
+		values, ok := parseParams(fields[2], "ln", "r", "p")
+		if !ok || values[0] < 1 || values[0] > 62 || values[1] > 1<<30 || values[2] > 1<<30 {
+			return nil, nil, nil, errInvalidHash
+		}
+		p := &ScryptParams{N: 1 << values[0], R: int(values[1]), P: int(values[2])}
+		params = p
+		salt, key, ok = parseSaltAndKey(fields[3], fields[4])
+		if !ok {
+			return nil, nil, nil, errInvalidHash
+		}
+		p.SaltLength, p.KeyLength = len(salt), len(key)
+	default:
+		return nil, nil, nil, errors.New("crypto/password: unsupported algorithm " + strconv.Quote(fields[1]))
+	}
+	return params, salt, key, nil
+}
+
+// parseParams parses a comma-separated list of decimal name=value pairs,
+// which must appear in the given order.
+func parseParams(s string, names ...string) ([]uint64, bool) {
+	pairs := strings.Split(s, ",")
+	if len(pairs) != len(names) {
+		return nil, false
+	}
+	values := make([]uint64, len(names))
+	for i, pair := range pairs {
+		v, ok := strings.CutPrefix(pair, names[i]+"=")
+		if !ok || v == "" || (v[0] == '0' && len(v) > 1) {
+			return nil, false
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = n
+	}
+	return values, true
+}
+
+func parseSaltAndKey(s, k string) (salt, key []byte, ok bool) {
+	salt, err := base64.RawStdEncoding.Strict().DecodeString(s)
+	if err != nil || len(salt) == 0 {
+		return nil, nil, false
+	}
+	key, err = base64.RawStdEncoding.Strict().DecodeString(k)
+	if err != nil || len(key) == 0 {
+		return nil, nil, false
+	}
+	return salt, key, true
+}
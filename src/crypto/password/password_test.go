@@ -0,0 +1,283 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func fromHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestArgon2idRFC9106 checks the test vector from RFC 9106, Section 5.3,
+// which also exercises the secret and associated data inputs.
+func TestArgon2idRFC9106(t *testing.T) {
+	password := bytes.Repeat([]byte{0x01}, 32)
+	salt := bytes.Repeat([]byte{0x02}, 16)
+	secret := bytes.Repeat([]byte{0x03}, 8)
+	data := bytes.Repeat([]byte{0x04}, 12)
+	want := fromHex("0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659")
+	got := argon2id(password, salt, secret, data, 3, 32, 4, 32)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestArgon2id(t *testing.T) {
+	tests := []struct {
+		time, memory uint32
+		threads      uint8
+		want         string
+	}{
+		{1, 8, 1, "b1e08616247974fac13266445b0a554eecd35999b3b039140c0dba97995daa9d"},
+		{2, 64, 1, "8f51fafce814f1c94d0f7bc359851a5a"},
+		{3, 256, 4, "d1a42ae3657a853ebd15dc393477d0ed1434520d21b63fbd3b97d31d2cb13b93fe1d040541eb4b4891f8b9f0f1d96416eecd72ac4360c09c286926b7b7255480"},
+		{4, 33, 2, "bb2d5e8a"},
+	}
+	for _, tt := range tests {
+		want := fromHex(tt.want)
+		got, err := Argon2id("password", []byte("somesaltsalt"), tt.time, tt.memory, tt.threads, uint32(len(want)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Argon2id(t=%d, m=%d, p=%d) = %x, want %x", tt.time, tt.memory, tt.threads, got, want)
+		}
+	}
+
+	salt := []byte("somesaltsalt")
+	for _, bad := range []struct {
+		time, memory uint32
+		threads      uint8
+		salt         []byte
+		keyLength    uint32
+	}{
+		{0, 64, 1, salt, 32},
+		{1, 64, 0, salt, 32},
+		{1, 15, 2, salt, 32},
+		{1, 64, 1, salt[:7], 32},
+		{1, 64, 1, salt, 3},
+	} {
+		if _, err := Argon2id("password", bad.salt, bad.time, bad.memory, bad.threads, bad.keyLength); err == nil {
+			t.Errorf("Argon2id(t=%d, m=%d, p=%d, salt=%d, keyLength=%d) succeeded, want error",
+				bad.time, bad.memory, bad.threads, len(bad.salt), bad.keyLength)
+		}
+	}
+}
+
+// TestScrypt checks the test vectors from RFC 7914, Section 12.
+func TestScrypt(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		N, r, p        int
+		want           string
+	}{
+		{"", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+		{"pleaseletmein", "SodiumChloride", 16384, 8, 1, "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887"},
+	}
+	for _, tt := range tests {
+		got, err := Scrypt(tt.password, []byte(tt.salt), tt.N, tt.r, tt.p, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fromHex(tt.want); !bytes.Equal(got, want) {
+			t.Errorf("Scrypt(%q, %q, %d, %d, %d) = %x, want %x", tt.password, tt.salt, tt.N, tt.r, tt.p, got, want)
+		}
+	}
+
+	for _, bad := range []struct{ N, r, p, keyLength int }{
+		{0, 8, 1, 32},
+		{1, 8, 1, 32},
+		{1000, 8, 1, 32},
+		{1 << 16, 1, 1, 32},
+		{16, 0, 1, 32},
+		{16, 8, 0, 32},
+		{16, 1 << 15, 1 << 15, 32},
+		{16, 8, 1, 0},
+	} {
+		if _, err := Scrypt("password", []byte("salt"), bad.N, bad.r, bad.p, bad.keyLength); err == nil {
+			t.Errorf("Scrypt(N=%d, r=%d, p=%d, keyLength=%d) succeeded, want error", bad.N, bad.r, bad.p, bad.keyLength)
+		}
+	}
+}
+
+func TestHashAndVerify(t *testing.T) {
+	for _, params := range []Params{
+		&Argon2idParams{Time: 1, Memory: 64, Threads: 2},
+		&Argon2idParams{Time: 2, Memory: 32, Threads: 1, SaltLength: 8, KeyLength: 64},
+		&ScryptParams{N: 1 << 10, R: 8, P: 1},
+		&ScryptParams{N: 1 << 4, R: 2, P: 3, SaltLength: 32, KeyLength: 16},
+		&BcryptParams{Cost: 4},
+	} {
+		hash, err := Hash("correct horse battery staple", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify("correct horse battery staple", hash); err != nil {
+			t.Errorf("Verify(%q): %v", hash, err)
+		}
+		if err := Verify("Tr0ub4dor&3", hash); err != ErrMismatchedHashAndPassword {
+			t.Errorf("Verify(%q) with the wrong password = %v, want ErrMismatchedHashAndPassword", hash, err)
+		}
+		if NeedsRehash(hash, params) {
+			t.Errorf("NeedsRehash(%q) = true with the same parameters", hash)
+		}
+		if !NeedsRehash(hash, nil) {
+			t.Errorf("NeedsRehash(%q) = false with the default parameters", hash)
+		}
+		hash2, err := Hash("correct horse battery staple", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash == hash2 {
+			t.Errorf("Hash returned the same string twice: %q", hash)
+		}
+	}
+
+	if _, err := Hash("password", &ScryptParams{N: 1000, R: 8, P: 1}); err == nil {
+		t.Errorf("Hash accepted invalid scrypt parameters")
+	}
+	if _, err := Hash("password", &Argon2idParams{Time: 1, Memory: 64, Threads: 1, SaltLength: 4}); err == nil {
+		t.Errorf("Hash accepted a 4-byte Argon2id salt")
+	}
+	if _, err := Hash("password", &BcryptParams{Cost: 3}); err == nil {
+		t.Errorf("Hash accepted a bcrypt cost of 3")
+	}
+	if _, err := Hash(strings.Repeat("x", 73), &BcryptParams{Cost: 4}); err == nil {
+		t.Errorf("Hash accepted a 73-byte password with bcrypt")
+	}
+}
+
+func TestDefaultParams(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping expensive hashes in short mode")
+	}
+	for _, params := range []Params{nil, DefaultArgon2idParams(), DefaultScryptParams(), DefaultBcryptParams()} {
+		hash, err := Hash("password", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify("password", hash); err != nil {
+			t.Errorf("Verify(%q): %v", hash, err)
+		}
+		if NeedsRehash(hash, params) {
+			t.Errorf("NeedsRehash(%q) = true with the same parameters", hash)
+		}
+	}
+}
+
+func TestVerifyKnownHashes(t *testing.T) {
+	for _, hash := range []string{
+		"$argon2id$v=19$m=64,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$scrypt$ln=10,r=8,p=2$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm/lhM0EIYJ7TJ",
+	} {
+		if err := Verify("password", hash); err != nil {
+			t.Errorf("Verify(%q): %v", hash, err)
+		}
+		if err := Verify("Password", hash); err != ErrMismatchedHashAndPassword {
+			t.Errorf("Verify(%q) with the wrong password = %v, want ErrMismatchedHashAndPassword", hash, err)
+		}
+	}
+}
+
+// TestBcrypt checks hashes generated by libxcrypt, including one from the
+// Openwall test vectors.
+func TestBcrypt(t *testing.T) {
+	for _, tt := range []struct{ password, hash string }{
+		{"", "$2b$04$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq"},
+		{"password", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.aDV7CQarKHMuNfh2oJkFzsHZya4whFe"},
+		{"U*U", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"},
+		{"pässwörd", "$2y$06$0123456789ABCDEFGHIJKeoctlHEz2a6DIKFk4qJjrCgo0znj3X0."},
+		{strings.Repeat("x", 72), "$2b$04$abcdefghijklmnopqrstuubzadhGtS2zEF.gu0yd0opP6cVzb.e0i"},
+	} {
+		if err := Verify(tt.password, tt.hash); err != nil {
+			t.Errorf("Verify(%q, %q): %v", tt.password, tt.hash, err)
+		}
+		if err := Verify(tt.password+"!", tt.hash); err == nil {
+			t.Errorf("Verify(%q, %q) succeeded", tt.password+"!", tt.hash)
+		}
+		if NeedsRehash(tt.hash, &BcryptParams{Cost: int(tt.hash[5] - '0')}) {
+			t.Errorf("NeedsRehash(%q) = true with the same cost", tt.hash)
+		}
+	}
+}
+
+func TestVerifyLimits(t *testing.T) {
+	for _, hash := range []string{
+		"$argon2id$v=19$m=4194304,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=19$m=64,t=17,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$scrypt$ln=22,r=8,p=2$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm/lhM0EIYJ7TJ",
+		"$scrypt$ln=10,r=1048576,p=2$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm/lhM0EIYJ7TJ",
+		"$scrypt$ln=10,r=8,p=17$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm/lhM0EIYJ7TJ",
+		"$2b$17$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq",
+		"$2b$31$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq",
+	} {
+		err := Verify("password", hash)
+		if err == nil || !strings.Contains(err.Error(), "limit") {
+			t.Errorf("Verify(%q) = %v, want a limit error", hash, err)
+		}
+	}
+
+	for _, params := range []Params{
+		&Argon2idParams{Time: 1, Memory: 1<<21 + 1, Threads: 1},
+		&ScryptParams{N: 1 << 20, R: 32, P: 1},
+		&ScryptParams{N: 16, R: 1, P: 17},
+		&BcryptParams{Cost: 17},
+	} {
+		if _, err := Hash("password", params); err == nil || !strings.Contains(err.Error(), "limit") {
+			t.Errorf("Hash(%+v) = %v, want a limit error", params, err)
+		}
+	}
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"password",
+		"$",
+		"$bcrypt$2b$10$abcdefghijklmnopqrstuu",
+		"$2x$04$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq",
+		"$2b$4$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq",
+		"$2b$03$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq",
+		"$2b$32$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq",
+		"$2b$04$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxji",
+		"$2b$04$abcdefghijklmnopqrstuubyCG3zY1GIXMyxfivm.ClDiInHzxjiq$",
+		"$2b$04$abcdefghijklmnopqrstuu+yCG3zY1GIXMyxfivm.ClDiInHzxjiq",
+		"$argon2i$v=19$m=64,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=16$m=64,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$m=64,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=19$t=2,m=64,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=19$m=064,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=19$m=64,t=2,p=256$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=19$m=64,t=2,p=1$c29tZXNhbHRzYWx0=$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=19$m=64,t=2,p=1$$j1H6/OgU8clND3vDWYUaWg",
+		"$argon2id$v=19$m=64,t=2,p=1$c29tZXNhbHRzYWx0$",
+		"$argon2id$v=19$m=64,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg$",
+		"$argon2id$v=19$m=4,t=2,p=1$c29tZXNhbHRzYWx0$j1H6/OgU8clND3vDWYUaWg",
+		"$scrypt$ln=0,r=8,p=2$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm/lhM0EIYJ7TJ",
+		"$scrypt$ln=10,r=8$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm/lhM0EIYJ7TJ",
+		"$scrypt$ln=10,r=-8,p=2$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm/lhM0EIYJ7TJ",
+		"$scrypt$ln=10,r=8,p=2$c29tZXNhbHRzYWx0$WLqrrBgj5T+vpkOmGrjm_lhM0EIYJ7TJ",
+	} {
+		err := Verify("password", hash)
+		if err == nil || err == ErrMismatchedHashAndPassword {
+			t.Errorf("Verify(%q) = %v, want parsing error", hash, err)
+		} else if !strings.HasPrefix(err.Error(), "crypto/password: ") {
+			t.Errorf("Verify(%q) = %v, want error prefixed by package name", hash, err)
+		}
+		if !NeedsRehash(hash, nil) {
+			t.Errorf("NeedsRehash(%q) = false, want true", hash)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"crypto/internal/fips140only"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"errors"
+	"internal/byteorder"
+	"math/bits"
+)
+
+// Scrypt derives a key from the password and salt using scrypt, as specified
+// in RFC 7914, returning a []byte of length keyLength.
+//
+// N is the CPU/memory cost parameter, which must be a power of two greater
+// than 1. r and p are the block size and parallelization parameters, and must
+// satisfy r*p < 2³⁰. Computing the key requires 128*N*r bytes of memory. As
+// of 2025, OWASP recommends N=131072 (2¹⁷), r=8 and p=1.
+//
+// The salt should be 16 random bytes.
+func Scrypt(password string, salt []byte, N, r, p, keyLength int) ([]byte, error) {
+	if fips140only.Enabled {
+		return nil, errors.New("crypto/password: use of scrypt is not allowed in FIPS 140-only mode")
+	}
+	if N <= 1 || N&(N-1) != 0 {
+		return nil, errors.New("crypto/password: scrypt N must be a power of two greater than 1")
+	}
+	if r < 1 || p < 1 || uint64(r)*uint64(p) >= 1<<30 {
+		return nil, errors.New("crypto/password: scrypt parameters r and p are out of range")
+	}
+	// RFC 7914 requires N < 2^(128*r/8), and the memory for the N-entry
+	// table must be addressable.
+	if r < 8 && N >= 1<<(16*r) || uint64(N) > (1<<(bits.UintSize-2))/(128*uint64(r)) {
+		return nil, errors.New("crypto/password: scrypt parameter N is too large")
+	}
+	if keyLength < 1 {
+		return nil, errors.New("crypto/password: scrypt key length must be positive")
+	}
+
+	b, err := pbkdf2.Key(sha256.New, password, salt, 1, p*128*r)
+	if err != nil {
+		return nil, err
+	}
+	x := make([]uint32, 32*r)
+	v := make([]uint32, 32*r*N)
+	for i := range p {
+		scryptROMix(b[i*128*r:(i+1)*128*r], x, v, N, r)
+	}
+	return pbkdf2.Key(sha256.New, password, b, 1, keyLength)
+}
+
+// scryptROMix implements scryptROMix from RFC 7914, Section 5, on the 128*r
+// byte block b, using x and v as scratch space.
+func scryptROMix(b []byte, x, v []uint32, N, r int) {
+	for i := range x {
+		x[i] = byteorder.LEUint32(b[i*4:])
+	}
+	tmp := make([]uint32, len(x))
+	for i := range N {
+		copy(v[i*len(x):], x)
+		scryptBlockMix(x, tmp, r)
+	}
+	for range N {
+		// Integerify takes the first word of the last 64-byte block, and only
+		// its low bits matter since N is at most 2^(64-2).
+		j := int(uint64(x[len(x)-16])|uint64(x[len(x)-15])<<32) & (N - 1)
+		vj := v[j*len(x) : (j+1)*len(x)]
+		for k := range x {
+			x[k] ^= vj[k]
+		}
+		scryptBlockMix(x, tmp, r)
+	}
+	for i, w := range x {
+		byteorder.LEPutUint32(b[i*4:], w)
+	}
+}
+
+// scryptBlockMix implements scryptBlockMix from RFC 7914, Section 4, in place
+// on the 2*r 64-byte blocks in b, using tmp as scratch space.
+func scryptBlockMix(b, tmp []uint32, r int) {
+	var x [16]uint32
+	copy(x[:], b[(2*r-1)*16:])
+	for i := range 2 * r {
+		for j := range x {
+			x[j] ^= b[i*16+j]
+		}
+		salsa208(&x)
+		// Even blocks go to the first half of the output, odd blocks to the
+		// second half.
+		copy(tmp[(i/2+(i%2)*r)*16:], x[:])
+	}
+	copy(b, tmp)
+}
+
+// salsa208 applies the Salsa20/8 core to x, according to RFC 7914, Section 3.
+func salsa208(x *[16]uint32) {
+	w := *x
+	for range 4 {
+		w[4] ^= bits.RotateLeft32(w[0]+w[12], 7)
+		w[8] ^= bits.RotateLeft32(w[4]+w[0], 9)
+		w[12] ^= bits.RotateLeft32(w[8]+w[4], 13)
+		w[0] ^= bits.RotateLeft32(w[12]+w[8], 18)
+
+		w[9] ^= bits.RotateLeft32(w[5]+w[1], 7)
+		w[13] ^= bits.RotateLeft32(w[9]+w[5], 9)
+		w[1] ^= bits.RotateLeft32(w[13]+w[9], 13)
+		w[5] ^= bits.RotateLeft32(w[1]+w[13], 18)
+
+		w[14] ^= bits.RotateLeft32(w[10]+w[6], 7)
+		w[2] ^= bits.RotateLeft32(w[14]+w[10], 9)
+		w[6] ^= bits.RotateLeft32(w[2]+w[14], 13)
+		w[10] ^= bits.RotateLeft32(w[6]+w[2], 18)
+
+		w[3] ^= bits.RotateLeft32(w[15]+w[11], 7)
+		w[7] ^= bits.RotateLeft32(w[3]+w[15], 9)
+		w[11] ^= bits.RotateLeft32(w[7]+w[3], 13)
+		w[15] ^= bits.RotateLeft32(w[11]+w[7], 18)
+
+		w[1] ^= bits.RotateLeft32(w[0]+w[3], 7)
+		w[2] ^= bits.RotateLeft32(w[1]+w[0], 9)
+		w[3] ^= bits.RotateLeft32(w[2]+w[1], 13)
+		w[0] ^= bits.RotateLeft32(w[3]+w[2], 18)
+
+		w[6] ^= bits.RotateLeft32(w[5]+w[4], 7)
+		w[7] ^= bits.RotateLeft32(w[6]+w[5], 9)
+		w[4] ^= bits.RotateLeft32(w[7]+w[6], 13)
+		w[5] ^= bits.RotateLeft32(w[4]+w[7], 18)
+
+		w[11] ^= bits.RotateLeft32(w[10]+w[9], 7)
+		w[8] ^= bits.RotateLeft32(w[11]+w[10], 9)
+		w[9] ^= bits.RotateLeft32(w[8]+w[11], 13)
+		w[10] ^= bits.RotateLeft32(w[9]+w[8], 18)
+
+		w[12] ^= bits.RotateLeft32(w[15]+w[14], 7)
+		w[13] ^= bits.RotateLeft32(w[12]+w[15], 9)
+		w[14] ^= bits.RotateLeft32(w[13]+w[12], 13)
+		w[15] ^= bits.RotateLeft32(w[14]+w[13], 18)
+	}
+	for i := range x {
+		x[i] += w[i]
+	}
+}
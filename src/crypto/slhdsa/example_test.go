@@ -0,0 +1,38 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa_test
+
+import (
+	"crypto/slhdsa"
+	"fmt"
+	"log"
+)
+
+func Example() {
+	// Generate a private key and send the public key to the verifier.
+	priv, err := slhdsa.GenerateKey(slhdsa.SHAKE128f())
+	if err != nil {
+		log.Fatal(err)
+	}
+	publicKeyBytes := priv.PublicKey().Bytes()
+
+	msg := []byte("hello, world")
+	opts := &slhdsa.Options{Context: "example protocol v1"}
+	sig, err := priv.Sign(nil, msg, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The verifier parses the public key and checks the signature.
+	pub, err := slhdsa.NewPublicKey(slhdsa.SHAKE128f(), publicKeyBytes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := slhdsa.Verify(pub, msg, sig, opts); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("signature verified")
+	// Output: signature verified
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package slhdsa implements the quantum-resistant stateless hash-based
+// digital signature algorithm SLH-DSA (formerly known as SPHINCS+), as
+// specified in [NIST FIPS 205].
+//
+// The twelve approved parameter sets are provided, named after their hash
+// function family (SHA-2 or SHAKE), their security category (128, 192 or 256
+// bits), and whether they are optimized for small signatures ("s") or fast
+// signing ("f"). The security of SLH-DSA relies only on the properties of the
+// underlying hash function, at the cost of much larger and slower signatures
+// than [crypto/mldsa].
+//
+// Only the "pure" variant of SLH-DSA is implemented, which signs messages
+// directly, not HashSLH-DSA, which signs pre-hashed messages.
+//
+// [NIST FIPS 205]: https://doi.org/10.6028/NIST.FIPS.205
+package slhdsa
+
+import (
+	"crypto"
+	"crypto/internal/slhdsa"
+	cryptorand "crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+// Parameters is an SLH-DSA parameter set. The zero value is not a valid
+// parameter set.
+//
+// Parameters values are comparable.
+type Parameters struct {
+	p *slhdsa.Parameters
+}
+
+// SHA2128s returns the SLH-DSA-SHA2-128s parameter set, which targets NIST
+// security category 1.
+func SHA2128s() Parameters { return Parameters{slhdsa.SHA2128s()} }
+
+// SHA2128f returns the SLH-DSA-SHA2-128f parameter set, which targets NIST
+// security category 1.
+func SHA2128f() Parameters { return Parameters{slhdsa.SHA2128f()} }
+
+// SHA2192s returns the SLH-DSA-SHA2-192s parameter set, which targets NIST
+// security category 3.
+func SHA2192s() Parameters { return Parameters{slhdsa.SHA2192s()} }
+
+// SHA2192f returns the SLH-DSA-SHA2-192f parameter set, which targets NIST
+// security category 3.
+func SHA2192f() Parameters { return Parameters{slhdsa.SHA2192f()} }
+
+// SHA2256s returns the SLH-DSA-SHA2-256s parameter set, which targets NIST
+// security category 5.
+func SHA2256s() Parameters { return Parameters{slhdsa.SHA2256s()} }
+
+// SHA2256f returns the SLH-DSA-SHA2-256f parameter set, which targets NIST
+// security category 5.
+func SHA2256f() Parameters { return Parameters{slhdsa.SHA2256f()} }
+
+// SHAKE128s returns the SLH-DSA-SHAKE-128s parameter set, which targets NIST
+// security category 1.
+func SHAKE128s() Parameters { return Parameters{slhdsa.SHAKE128s()} }
+
+// SHAKE128f returns the SLH-DSA-SHAKE-128f parameter set, which targets NIST
+// security category 1.
+func SHAKE128f() Parameters { return Parameters{slhdsa.SHAKE128f()} }
+
+// SHAKE192s returns the SLH-DSA-SHAKE-192s parameter set, which targets NIST
+// security category 3.
+func SHAKE192s() Parameters { return Parameters{slhdsa.SHAKE192s()} }
+
+// SHAKE192f returns the SLH-DSA-SHAKE-192f parameter set, which targets NIST
+// security category 3.
+func SHAKE192f() Parameters { return Parameters{slhdsa.SHAKE192f()} }
+
+// SHAKE256s returns the SLH-DSA-SHAKE-256s parameter set, which targets NIST
+// security category 5.
+func SHAKE256s() Parameters { return Parameters{slhdsa.SHAKE256s()} }
+
+// SHAKE256f returns the SLH-DSA-SHAKE-256f parameter set, which targets NIST
+// security category 5.
+func SHAKE256f() Parameters { return Parameters{slhdsa.SHAKE256f()} }
+
+// String returns the name of the parameter set, such as "SLH-DSA-SHA2-128s".
+func (params Parameters) String() string {
+	if params.p == nil {
+		return "<invalid>"
+	}
+	return params.p.String()
+}
+
+// PublicKeySize returns the size of public keys of the parameter set.
+func (params Parameters) PublicKeySize() int {
+	return params.p.PublicKeySize()
+}
+
+// PrivateKeySize returns the size of private keys of the parameter set.
+func (params Parameters) PrivateKeySize() int {
+	return params.p.PrivateKeySize()
+}
+
+// SignatureSize returns the size of signatures of the parameter set.
+func (params Parameters) SignatureSize() int {
+	return params.p.SignatureSize()
+}
+
+// PrivateKey is an SLH-DSA private key.
+//
+// PrivateKey implements [crypto.Signer] and [crypto.MessageSigner].
+type PrivateKey struct {
+	k *slhdsa.PrivateKey
+}
+
+// PublicKey is an SLH-DSA public key.
+type PublicKey struct {
+	k *slhdsa.PublicKey
+}
+
+// GenerateKey generates a new private key, drawing random bytes from the
+// default crypto/rand source.
+func GenerateKey(params Parameters) (*PrivateKey, error) {
+	if params.p == nil {
+		return nil, errors.New("slhdsa: invalid parameters")
+	}
+	n := params.p.SeedSize()
+	seeds := make([]byte, 3*n)
+	cryptorand.Read(seeds)
+	k, err := slhdsa.NewPrivateKeyFromSeeds(params.p, seeds[:n], seeds[n:2*n], seeds[2*n:])
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{k}, nil
+}
+
+// NewPrivateKey parses an encoded private key, as returned by
+// [PrivateKey.Bytes].
+//
+// The public key root embedded in the private key is recomputed and checked,
+// which takes about as long as generating a new key.
+func NewPrivateKey(params Parameters, b []byte) (*PrivateKey, error) {
+	if params.p == nil {
+		return nil, errors.New("slhdsa: invalid parameters")
+	}
+	k, err := slhdsa.NewPrivateKey(params.p, b)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{k}, nil
+}
+
+// NewPublicKey parses an encoded public key, as returned by
+// [PublicKey.Bytes].
+func NewPublicKey(params Parameters, b []byte) (*PublicKey, error) {
+	if params.p == nil {
+		return nil, errors.New("slhdsa: invalid parameters")
+	}
+	k, err := slhdsa.NewPublicKey(params.p, b)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKey{k}, nil
+}
+
+// Bytes returns the encoded private key, SK.seed || SK.prf || PK.seed ||
+// PK.root.
+//
+// The private key must be kept secret.
+func (priv *PrivateKey) Bytes() []byte {
+	return priv.k.Bytes()
+}
+
+// Parameters returns the parameter set of the private key.
+func (priv *PrivateKey) Parameters() Parameters {
+	return Parameters{priv.k.Parameters()}
+}
+
+// Public returns the public key corresponding to priv, as a [*PublicKey].
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return priv.PublicKey()
+}
+
+// PublicKey returns the public key corresponding to priv.
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	return &PublicKey{priv.k.PublicKey()}
+}
+
+// Equal reports whether priv and x have the same value.
+func (priv *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey)
+	if !ok {
+		return false
+	}
+	return priv.k.Parameters() == xx.k.Parameters() &&
+		subtle.ConstantTimeCompare(priv.k.Bytes(), xx.k.Bytes()) == 1
+}
+
+// Sign signs message with priv. rand is ignored and can be nil: signatures
+// are randomized using the default crypto/rand source, as recommended by FIPS
+// 205 to protect against side-channel attacks.
+//
+// opts.HashFunc() must be zero, as SLH-DSA signs messages directly. If opts
+// is an [*Options], its Context is used.
+func (priv *PrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	if opts.HashFunc() != 0 {
+		return nil, errors.New("slhdsa: cannot sign hashed message")
+	}
+	context := ""
+	if opts, ok := opts.(*Options); ok {
+		context = opts.Context
+	}
+	addrnd := make([]byte, priv.k.Parameters().SeedSize())
+	cryptorand.Read(addrnd)
+	return slhdsa.Sign(priv.k, message, context, addrnd)
+}
+
+// SignMessage is equivalent to [PrivateKey.Sign].
+func (priv *PrivateKey) SignMessage(rand io.Reader, msg []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	return priv.Sign(rand, msg, opts)
+}
+
+// Bytes returns the encoded public key, PK.seed || PK.root.
+func (pub *PublicKey) Bytes() []byte {
+	return pub.k.Bytes()
+}
+
+// Parameters returns the parameter set of the public key.
+func (pub *PublicKey) Parameters() Parameters {
+	return Parameters{pub.k.Parameters()}
+}
+
+// Equal reports whether pub and x have the same value.
+func (pub *PublicKey) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return pub.k.Parameters() == xx.k.Parameters() &&
+		subtle.ConstantTimeCompare(pub.k.Bytes(), xx.k.Bytes()) == 1
+}
+
+// Options can be used with [PrivateKey.Sign] and [Verify] to provide a
+// context string, which binds signatures to an application or protocol.
+type Options struct {
+	// Context is the SLH-DSA context string. It can be at most 255 bytes long.
+	Context string
+}
+
+// HashFunc returns zero, as SLH-DSA signs messages directly.
+func (o *Options) HashFunc() crypto.Hash { return 0 }
+
+// Verify reports whether sig is a valid signature of message by pub, returning
+// an error if it isn't. opts may be nil, which is equivalent to an empty
+// context string.
+func Verify(pub *PublicKey, message, sig []byte, opts *Options) error {
+	context := ""
+	if opts != nil {
+		context = opts.Context
+	}
+	return slhdsa.Verify(pub.k, message, sig, context)
+}
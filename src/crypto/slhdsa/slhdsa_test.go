@@ -0,0 +1,144 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slhdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+var allParameters = []struct {
+	params        Parameters
+	pubSize       int
+	signatureSize int
+}{
+	{SHA2128s(), 32, 7856},
+	{SHAKE128s(), 32, 7856},
+	{SHA2128f(), 32, 17088},
+	{SHAKE128f(), 32, 17088},
+	{SHA2192s(), 48, 16224},
+	{SHAKE192s(), 48, 16224},
+	{SHA2192f(), 48, 35664},
+	{SHAKE192f(), 48, 35664},
+	{SHA2256s(), 64, 29792},
+	{SHAKE256s(), 64, 29792},
+	{SHA2256f(), 64, 49856},
+	{SHAKE256f(), 64, 49856},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range allParameters {
+		t.Run(tt.params.String(), func(t *testing.T) {
+			if testing.Short() && strings.HasSuffix(tt.params.String(), "s") {
+				t.Skip("skipping slow parameter set in short mode")
+			}
+			priv, err := GenerateKey(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if priv.Parameters() != tt.params || tt.params.PublicKeySize() != tt.pubSize ||
+				tt.params.PrivateKeySize() != 2*tt.pubSize || tt.params.SignatureSize() != tt.signatureSize {
+				t.Fatalf("unexpected parameters")
+			}
+			pub := priv.PublicKey()
+			if len(pub.Bytes()) != tt.pubSize {
+				t.Errorf("public key is %d bytes, want %d", len(pub.Bytes()), tt.pubSize)
+			}
+
+			msg := []byte("message")
+			var signer crypto.MessageSigner = priv
+			sig, err := signer.SignMessage(nil, msg, crypto.Hash(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sig) != tt.signatureSize {
+				t.Errorf("signature is %d bytes, want %d", len(sig), tt.signatureSize)
+			}
+			if err := Verify(pub, msg, sig, nil); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if err := Verify(pub, []byte("other message"), sig, nil); err == nil {
+				t.Errorf("Verify accepted a signature for a different message")
+			}
+			if err := Verify(pub, msg, sig, &Options{Context: "ctx"}); err == nil {
+				t.Errorf("Verify accepted a signature with a different context")
+			}
+			if err := Verify(pub, msg, sig[:len(sig)-1], nil); err == nil {
+				t.Errorf("Verify accepted a truncated signature")
+			}
+			for _, i := range []int{0, len(sig) / 2, len(sig) - 1} {
+				bad := bytes.Clone(sig)
+				bad[i] ^= 1
+				if err := Verify(pub, msg, bad, nil); err == nil {
+					t.Errorf("Verify accepted a signature modified at byte %d", i)
+				}
+			}
+
+			sig, err = priv.Sign(rand.Reader, msg, &Options{Context: "ctx"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(pub, msg, sig, &Options{Context: "ctx"}); err != nil {
+				t.Errorf("Verify with context: %v", err)
+			}
+			if _, err := priv.Sign(nil, msg, crypto.SHA256); err == nil {
+				t.Errorf("Sign accepted a hashed message")
+			}
+			longContext := &Options{Context: strings.Repeat("x", 256)}
+			if _, err := priv.Sign(nil, msg, longContext); err == nil {
+				t.Errorf("Sign accepted a 256-byte context")
+			}
+			if err := Verify(pub, msg, sig, longContext); err == nil {
+				t.Errorf("Verify accepted a 256-byte context")
+			}
+
+			priv2, err := NewPrivateKey(tt.params, priv.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !priv.Equal(priv2) || !priv2.PublicKey().Equal(pub) {
+				t.Errorf("parsed private key doesn't match")
+			}
+			badPriv := priv.Bytes()
+			badPriv[len(badPriv)-1] ^= 1
+			if _, err := NewPrivateKey(tt.params, badPriv); err == nil {
+				t.Errorf("NewPrivateKey accepted a key with the wrong PK.root")
+			}
+			pub2, err := NewPublicKey(tt.params, pub.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pub.Equal(pub2) || !bytes.Equal(pub.Bytes(), pub2.Bytes()) {
+				t.Errorf("parsed public key doesn't match")
+			}
+			if _, err := NewPublicKey(tt.params, pub.Bytes()[1:]); err == nil {
+				t.Errorf("NewPublicKey accepted a short key")
+			}
+		})
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	if _, err := GenerateKey(Parameters{}); err == nil {
+		t.Error("GenerateKey accepted zero Parameters")
+	}
+	if _, err := NewPrivateKey(Parameters{}, make([]byte, 64)); err == nil {
+		t.Error("NewPrivateKey accepted zero Parameters")
+	}
+	if _, err := NewPrivateKey(SHA2128f(), make([]byte, 63)); err == nil {
+		t.Error("NewPrivateKey accepted a short key")
+	}
+	if _, err := NewPublicKey(Parameters{}, make([]byte, 32)); err == nil {
+		t.Error("NewPublicKey accepted zero Parameters")
+	}
+	pubSHA2, _ := NewPublicKey(SHA2128f(), make([]byte, 32))
+	pubSHAKE, _ := NewPublicKey(SHAKE128f(), make([]byte, 32))
+	if pubSHA2.Equal(pubSHAKE) {
+		t.Error("keys with different parameters are equal")
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package agent
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/ssh"
+	"encoding/pem"
+	"internal/testenv"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"p256": p256, "p384": p384, "ed25519": ed, "rsa": rsaKey}
+}
+
+// startKeyring returns a client connected to a keyring served by ServeAgent.
+func startKeyring(t *testing.T) Agent {
+	t.Helper()
+	c1, c2 := net.Pipe()
+	t.Cleanup(func() { c1.Close() })
+	done := make(chan error, 1)
+	go func() {
+		done <- ServeAgent(NewKeyring(), c2)
+		c2.Close()
+	}()
+	t.Cleanup(func() {
+		c1.Close()
+		if err := <-done; err != nil {
+			t.Errorf("ServeAgent: %v", err)
+		}
+	})
+	return NewClient(c1)
+}
+
+func publicKey(t *testing.T, key crypto.Signer) ssh.PublicKey {
+	t.Helper()
+	pub, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub
+}
+
+func testAgent(t *testing.T, agent Agent, key crypto.Signer, cert *ssh.Certificate) {
+	pub := publicKey(t, key)
+	if cert != nil {
+		pub = cert
+	}
+	if err := agent.Add(AddedKey{PrivateKey: key, Certificate: cert, Comment: "test key"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	keys, err := agent.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !bytes.Equal(keys[0].Blob, pub.Marshal()) || keys[0].Comment != "test key" {
+		t.Fatalf("List returned %v", keys)
+	}
+	if keys[0].Format != pub.Type() {
+		t.Errorf("got key format %q, want %q", keys[0].Format, pub.Type())
+	}
+	if !strings.HasSuffix(keys[0].String(), " test key") {
+		t.Errorf("String() = %q", keys[0].String())
+	}
+
+	data := []byte("sign me")
+	sig, err := agent.Sign(pub, data, 0)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := pub.Verify(data, sig); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := keys[0].Verify(data, sig); err != nil {
+		t.Errorf("Key.Verify: %v", err)
+	}
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		for flags, alg := range map[SignatureFlags]string{
+			0:                      ssh.KeyAlgoRSA,
+			SignatureFlagRSASHA256: ssh.KeyAlgoRSASHA256,
+			SignatureFlagRSASHA512: ssh.KeyAlgoRSASHA512,
+		} {
+			sig, err := agent.Sign(pub, data, flags)
+			if err != nil {
+				t.Fatalf("Sign(%d): %v", flags, err)
+			}
+			if sig.Format != alg {
+				t.Errorf("Sign(%d) returned a %q signature, want %q", flags, sig.Format, alg)
+			}
+			if err := pub.Verify(data, sig); err != nil {
+				t.Errorf("Verify(%q): %v", alg, err)
+			}
+		}
+	}
+
+	signers, err := agent.Signers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signers) != 1 || !bytes.Equal(signers[0].PublicKey().Marshal(), pub.Marshal()) {
+		t.Fatalf("Signers returned %v", signers)
+	}
+	sig, err = signers[0].Sign(rand.Reader, data, "")
+	if err != nil {
+		t.Fatalf("Signer.Sign: %v", err)
+	}
+	if err := pub.Verify(data, sig); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	if err := agent.Remove(pub); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if keys, err := agent.List(); err != nil || len(keys) != 0 {
+		t.Fatalf("List after Remove returned %v, %v", keys, err)
+	}
+	if _, err := agent.Sign(pub, data, 0); err == nil {
+		t.Errorf("Sign succeeded after Remove")
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	for name, key := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			testAgent(t, NewKeyring(), key, nil)
+		})
+		t.Run(name+"/client", func(t *testing.T) {
+			testAgent(t, startKeyring(t), key, nil)
+		})
+	}
+}
+
+// testCert returns a user certificate for key, signed by a new authority.
+func testCert(t *testing.T, key crypto.Signer) *ssh.Certificate {
+	t.Helper()
+	_, caKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := ssh.NewSigner(caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &ssh.Certificate{
+		Key:             publicKey(t, key),
+		CertType:        ssh.UserCert,
+		KeyID:           "test",
+		ValidPrincipals: []string{"alice"},
+		ValidBefore:     ssh.CertTimeInfinity,
+		Extensions:      map[string]string{"permit-pty": ""},
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestKeyringCert(t *testing.T) {
+	for name, key := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			testAgent(t, startKeyring(t), key, testCert(t, key))
+		})
+	}
+}
+
+func TestKeyringLock(t *testing.T) {
+	agent := startKeyring(t)
+	key := testKeys(t)["ed25519"]
+	pub := publicKey(t, key)
+	if err := agent.Add(AddedKey{PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Unlock([]byte("secret")); err == nil {
+		t.Errorf("Unlock succeeded while unlocked")
+	}
+	if err := agent.Lock([]byte("secret")); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if keys, err := agent.List(); err != nil || len(keys) != 0 {
+		t.Errorf("List while locked returned %v, %v", keys, err)
+	}
+	if _, err := agent.Sign(pub, []byte("data"), 0); err == nil {
+		t.Errorf("Sign succeeded while locked")
+	}
+	if err := agent.Add(AddedKey{PrivateKey: testKeys(t)["p256"]}); err == nil {
+		t.Errorf("Add succeeded while locked")
+	}
+	if err := agent.RemoveAll(); err == nil {
+		t.Errorf("RemoveAll succeeded while locked")
+	}
+	if err := agent.Unlock([]byte("wrong")); err == nil {
+		t.Errorf("Unlock succeeded with the wrong passphrase")
+	}
+	if err := agent.Unlock([]byte("secret")); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if keys, err := agent.List(); err != nil || len(keys) != 1 {
+		t.Errorf("List after Unlock returned %v, %v", keys, err)
+	}
+	if err := agent.RemoveAll(); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if keys, err := agent.List(); err != nil || len(keys) != 0 {
+		t.Errorf("List after RemoveAll returned %v, %v", keys, err)
+	}
+}
+
+func TestKeyringConstraints(t *testing.T) {
+	agent := startKeyring(t)
+	keys := testKeys(t)
+	if err := agent.Add(AddedKey{PrivateKey: keys["p256"], ConfirmBeforeUse: true}); err == nil {
+		t.Errorf("Add succeeded with ConfirmBeforeUse")
+	}
+	if err := agent.Add(AddedKey{PrivateKey: keys["ed25519"], LifetimeSecs: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Add(AddedKey{PrivateKey: keys["p384"], LifetimeSecs: 3600}); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := agent.List(); err != nil || len(l) != 2 {
+		t.Fatalf("List returned %v, %v", l, err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	l, err := agent.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l) != 1 || !bytes.Equal(l[0].Blob, publicKey(t, keys["p384"]).Marshal()) {
+		t.Errorf("List after expiry returned %v", l)
+	}
+}
+
+// TestOpenSSHAgent checks interoperability with OpenSSH's ssh-agent and
+// ssh-add, if they are installed.
+func TestOpenSSHAgent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	testenv.MustHaveExecPath(t, "ssh-agent")
+	testenv.MustHaveExecPath(t, "ssh-add")
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "agent.sock")
+	cmd := testenv.Command(t, "ssh-agent", "-D", "-a", socket)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+	var (
+		conn net.Conn
+		err  error
+	)
+	for range 50 {
+		if conn, err = net.Dial("unix", socket); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for name, key := range testKeys(t) {
+		t.Run("client/"+name, func(t *testing.T) {
+			testAgent(t, NewClient(conn), key, nil)
+		})
+		t.Run("client/"+name+"-cert", func(t *testing.T) {
+			testAgent(t, NewClient(conn), key, testCert(t, key))
+		})
+	}
+
+	// Serve a keyring to ssh-add.
+	l, err := net.Listen("unix", filepath.Join(dir, "keyring.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	keyring := NewKeyring()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				ServeAgent(keyring, c)
+				c.Close()
+			}()
+		}
+	}()
+	key := testKeys(t)["rsa"]
+	block, err := ssh.MarshalPrivateKey(key, "ssh-add key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := publicKey(t, key)
+	keyFile := filepath.Join(dir, "id_rsa")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile+".pub", ssh.MarshalAuthorizedKey(pub), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := testenv.Command(t, "ssh-add", args...)
+		cmd.Env = append(os.Environ(), "SSH_AUTH_SOCK="+l.Addr().String())
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("ssh-add %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+	run("-t", "3600", keyFile)
+	want := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(pub)), "\n") + " ssh-add key\n"
+	if out := run("-L"); out != want {
+		t.Errorf("ssh-add -L returned %q, want %q", out, want)
+	}
+	sig, err := keyring.Sign(pub, []byte("data"), SignatureFlagRSASHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pub.Verify([]byte("data"), sig); err != nil {
+		t.Error(err)
+	}
+	run("-d", keyFile+".pub")
+	if keys, _ := keyring.List(); len(keys) != 0 {
+		t.Errorf("key not removed by ssh-add -d")
+	}
+}
@@ -0,0 +1,395 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package agent implements the SSH agent protocol, as used by ssh-agent(1)
+// and ssh-add(1), and specified in draft-ietf-sshm-ssh-agent.
+//
+// [NewClient] uses the keys of a running agent, usually reached over the Unix
+// socket named by the SSH_AUTH_SOCK environment variable. [ServeAgent] and
+// [NewKeyring] implement an agent.
+package agent
+
+import (
+	"crypto"
+	"crypto/ssh"
+	"crypto/ssh/internal/wire"
+	"encoding/base64"
+	"errors"
+	"internal/byteorder"
+	"io"
+	"sync"
+)
+
+// The message numbers of the agent protocol.
+const (
+	agentFailure = 5
+	agentSuccess = 6
+
+	agentRequestIdentities   = 11
+	agentIdentitiesAnswer    = 12
+	agentSignRequest         = 13
+	agentSignResponse        = 14
+	agentAddIdentity         = 17
+	agentRemoveIdentity      = 18
+	agentRemoveAllIdentities = 19
+	agentLock                = 22
+	agentUnlock              = 23
+	agentAddIDConstrained    = 25
+	agentExtension           = 27
+)
+
+// The key constraints of agentAddIDConstrained.
+const (
+	agentConstrainLifetime = 1
+	agentConstrainConfirm  = 2
+)
+
+// maxAgentMessageBytes is the maximum size of a message, the same as OpenSSH's.
+const maxAgentMessageBytes = 256 * 1024
+
+// SignatureFlags select the signature algorithm of RSA keys.
+type SignatureFlags uint32
+
+const (
+	// SignatureFlagRSASHA256 selects the rsa-sha2-256 algorithm.
+	SignatureFlagRSASHA256 SignatureFlags = 2
+	// SignatureFlagRSASHA512 selects the rsa-sha2-512 algorithm.
+	SignatureFlagRSASHA512 SignatureFlags = 4
+)
+
+// An Agent holds keys and signs with them on behalf of its clients.
+type Agent interface {
+	// List returns the keys held by the agent, or none if it is locked.
+	List() ([]*Key, error)
+
+	// Sign returns a signature of data by the given key, which may be a
+	// certificate. For RSA keys, flags select the signature algorithm,
+	// which is the SHA-1 based "ssh-rsa" if no flag is set.
+	Sign(key ssh.PublicKey, data []byte, flags SignatureFlags) (*ssh.Signature, error)
+
+	// Add adds a private key to the agent.
+	Add(key AddedKey) error
+
+	// Remove removes the key, or certificate, from the agent.
+	Remove(key ssh.PublicKey) error
+
+	// RemoveAll removes all keys from the agent.
+	RemoveAll() error
+
+	// Lock locks the agent with passphrase. A locked agent lists no keys
+	// and refuses to sign until it is unlocked.
+	Lock(passphrase []byte) error
+
+	// Unlock unlocks an agent locked with passphrase.
+	Unlock(passphrase []byte) error
+
+	// Signers returns signers for the keys held by the agent.
+	Signers() ([]ssh.Signer, error)
+}
+
+// An AddedKey is a private key to add to an agent.
+type AddedKey struct {
+	// PrivateKey is an *rsa.PrivateKey, an *ecdsa.PrivateKey, or an
+	// ed25519.PrivateKey.
+	PrivateKey crypto.Signer
+
+	// Certificate, if not nil, is a certificate of the public key of
+	// PrivateKey, which the agent presents instead of the public key.
+	Certificate *ssh.Certificate
+
+	// Comment is an optional comment, usually the file name of the key.
+	Comment string
+
+	// LifetimeSecs, if not zero, is the number of seconds after which the
+	// agent removes the key.
+	LifetimeSecs uint32
+
+	// ConfirmBeforeUse asks the agent to confirm each use of the key with
+	// the user.
+	ConfirmBeforeUse bool
+}
+
+// A Key is a public key, or certificate, held by an agent. It implements
+// [ssh.PublicKey], for any key type supported by the [crypto/ssh] package.
+type Key struct {
+	// Format is the type of the key, such as "ssh-ed25519".
+	Format string
+
+	// Blob is the key in the SSH wire format.
+	Blob []byte
+
+	// Comment is the comment provided when the key was added.
+	Comment string
+}
+
+// Type returns k.Format.
+func (k *Key) Type() string { return k.Format }
+
+// Marshal returns k.Blob.
+func (k *Key) Marshal() []byte { return k.Blob }
+
+// Verify checks that sig is a valid signature of data by the key.
+func (k *Key) Verify(data []byte, sig *ssh.Signature) error {
+	pub, err := ssh.ParsePublicKey(k.Blob)
+	if err != nil {
+		return err
+	}
+	return pub.Verify(data, sig)
+}
+
+// CryptoPublicKey returns the public key, or nil if its type is not
+// supported by the [crypto/ssh] package.
+func (k *Key) CryptoPublicKey() crypto.PublicKey {
+	pub, err := ssh.ParsePublicKey(k.Blob)
+	if err != nil {
+		return nil
+	}
+	return pub.CryptoPublicKey()
+}
+
+// String returns the key in the format of an authorized_keys line, without
+// trailing newline.
+func (k *Key) String() string {
+	s := k.Format + " " + base64.StdEncoding.EncodeToString(k.Blob)
+	if k.Comment != "" {
+		s += " " + k.Comment
+	}
+	return s
+}
+
+var errAgentFailure = errors.New("agent: request failed")
+
+type client struct {
+	mu   sync.Mutex
+	conn io.ReadWriter
+}
+
+// NewClient returns an Agent that talks to the agent at the other end of
+// conn, which is usually a [net.Conn]. The returned Agent is safe for
+// concurrent use by multiple goroutines.
+func NewClient(conn io.ReadWriter) Agent {
+	return &client{conn: conn}
+}
+
+// call sends a request and returns the response.
+func (c *client) call(req []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := writeMessage(c.conn, req); err != nil {
+		return nil, err
+	}
+	resp, err := readMessage(c.conn)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return resp, err
+}
+
+// simpleCall sends a request that expects success or failure.
+func (c *client) simpleCall(req []byte) error {
+	resp, err := c.call(req)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(resp) == 1 && resp[0] == agentSuccess:
+		return nil
+	case len(resp) == 1 && resp[0] == agentFailure:
+		return errAgentFailure
+	}
+	return errors.New("agent: unexpected response")
+}
+
+func writeMessage(w io.Writer, msg []byte) error {
+	if len(msg) > maxAgentMessageBytes {
+		return errors.New("agent: message too large")
+	}
+	_, err := w.Write(wire.AppendString(nil, msg))
+	return err
+}
+
+// readMessage reads a message, returning io.EOF only if the connection is
+// closed before its first byte.
+func readMessage(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	n := byteorder.BEUint32(length[:])
+	if n == 0 || n > maxAgentMessageBytes {
+		return nil, errors.New("agent: invalid message length")
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (c *client) List() ([]*Key, error) {
+	resp, err := c.call([]byte{agentRequestIdentities})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) == 1 && resp[0] == agentFailure {
+		return nil, errAgentFailure
+	}
+	if len(resp) == 0 || resp[0] != agentIdentitiesAnswer {
+		return nil, errors.New("agent: unexpected response")
+	}
+	n, rest, ok := wire.ParseUint32(resp[1:])
+	if !ok || n > uint32(len(rest)) {
+		return nil, errors.New("agent: invalid identities answer")
+	}
+	keys := make([]*Key, 0, n)
+	for range n {
+		var blob, comment []byte
+		if blob, rest, ok = wire.ParseString(rest); !ok {
+			return nil, errors.New("agent: invalid identities answer")
+		}
+		if comment, rest, ok = wire.ParseString(rest); !ok {
+			return nil, errors.New("agent: invalid identities answer")
+		}
+		keyType, _, ok := wire.ParseString(blob)
+		if !ok {
+			return nil, errors.New("agent: invalid identities answer")
+		}
+		keys = append(keys, &Key{Format: string(keyType), Blob: blob, Comment: string(comment)})
+	}
+	return keys, nil
+}
+
+func (c *client) Sign(key ssh.PublicKey, data []byte, flags SignatureFlags) (*ssh.Signature, error) {
+	req := []byte{agentSignRequest}
+	req = wire.AppendString(req, key.Marshal())
+	req = wire.AppendString(req, data)
+	req = wire.AppendUint32(req, uint32(flags))
+	resp, err := c.call(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) == 1 && resp[0] == agentFailure {
+		return nil, errAgentFailure
+	}
+	if len(resp) == 0 || resp[0] != agentSignResponse {
+		return nil, errors.New("agent: unexpected response")
+	}
+	sig, rest, ok := wire.ParseString(resp[1:])
+	if !ok || len(rest) != 0 {
+		return nil, errors.New("agent: invalid sign response")
+	}
+	return ssh.ParseSignature(sig)
+}
+
+func (c *client) Add(key AddedKey) error {
+	req, err := marshalAddedKey(key)
+	if err != nil {
+		return err
+	}
+	return c.simpleCall(req)
+}
+
+// marshalAddedKey returns an agentAddIdentity request for key, or an
+// agentAddIDConstrained one if it has constraints.
+func marshalAddedKey(key AddedKey) ([]byte, error) {
+	var constraints []byte
+	if key.LifetimeSecs != 0 {
+		constraints = append(constraints, agentConstrainLifetime)
+		constraints = wire.AppendUint32(constraints, key.LifetimeSecs)
+	}
+	if key.ConfirmBeforeUse {
+		constraints = append(constraints, agentConstrainConfirm)
+	}
+
+	req := []byte{agentAddIdentity}
+	if len(constraints) != 0 {
+		req[0] = agentAddIDConstrained
+	}
+	var err error
+	if key.Certificate != nil {
+		req = wire.AppendString(req, key.Certificate.Type())
+		req = wire.AppendString(req, key.Certificate.Marshal())
+		req, err = wire.AppendCertPrivateKey(req, key.PrivateKey)
+	} else {
+		req, err = wire.AppendPrivateKey(req, key.PrivateKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req = wire.AppendString(req, key.Comment)
+	return append(req, constraints...), nil
+}
+
+func (c *client) Remove(key ssh.PublicKey) error {
+	req := []byte{agentRemoveIdentity}
+	return c.simpleCall(wire.AppendString(req, key.Marshal()))
+}
+
+func (c *client) RemoveAll() error {
+	return c.simpleCall([]byte{agentRemoveAllIdentities})
+}
+
+func (c *client) Lock(passphrase []byte) error {
+	req := []byte{agentLock}
+	return c.simpleCall(wire.AppendString(req, passphrase))
+}
+
+func (c *client) Unlock(passphrase []byte) error {
+	req := []byte{agentUnlock}
+	return c.simpleCall(wire.AppendString(req, passphrase))
+}
+
+// Signers returns signers for the keys held by the agent whose type is
+// supported by the [crypto/ssh] package.
+func (c *client) Signers() ([]ssh.Signer, error) {
+	keys, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	var signers []ssh.Signer
+	for _, k := range keys {
+		pub, err := ssh.ParsePublicKey(k.Blob)
+		if err != nil {
+			continue
+		}
+		signers = append(signers, &agentSigner{agent: c, pub: pub})
+	}
+	return signers, nil
+}
+
+type agentSigner struct {
+	agent Agent
+	pub   ssh.PublicKey
+}
+
+func (s *agentSigner) PublicKey() ssh.PublicKey { return s.pub }
+
+func (s *agentSigner) Sign(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	keyType := s.pub.Type()
+	if cert, ok := s.pub.(*ssh.Certificate); ok {
+		keyType = cert.Key.Type()
+	}
+	var flags SignatureFlags
+	switch {
+	case keyType == ssh.KeyAlgoRSA && (algorithm == "" || algorithm == ssh.KeyAlgoRSASHA512):
+		algorithm, flags = ssh.KeyAlgoRSASHA512, SignatureFlagRSASHA512
+	case keyType == ssh.KeyAlgoRSA && algorithm == ssh.KeyAlgoRSASHA256:
+		flags = SignatureFlagRSASHA256
+	case algorithm == "":
+		algorithm = keyType
+	case algorithm != keyType:
+		return nil, errors.New("agent: unsupported signature algorithm " + algorithm + " for key type " + keyType)
+	}
+	sig, err := s.agent.Sign(s.pub, data, flags)
+	if err != nil {
+		return nil, err
+	}
+	if sig.Format != algorithm {
+		return nil, errors.New("agent: unexpected signature algorithm " + sig.Format)
+	}
+	return sig, nil
+}
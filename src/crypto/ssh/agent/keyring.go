@@ -0,0 +1,195 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package agent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/ssh"
+	"crypto/subtle"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+type keyringKey struct {
+	signer  ssh.Signer
+	comment string
+	expire  time.Time // zero if the key doesn't expire
+}
+
+type keyring struct {
+	mu         sync.Mutex
+	keys       []keyringKey
+	locked     bool
+	passphrase []byte
+}
+
+var errLocked = errors.New("agent: locked")
+
+// NewKeyring returns an Agent that holds keys in memory. It is safe for
+// concurrent use by multiple goroutines.
+//
+// The returned Agent doesn't support [AddedKey.ConfirmBeforeUse], since it
+// has no way to ask the user.
+func NewKeyring() Agent {
+	return new(keyring)
+}
+
+// expire removes expired keys. It must be called with r.mu held.
+func (r *keyring) expire() {
+	now := time.Now()
+	r.keys = slices.DeleteFunc(r.keys, func(k keyringKey) bool {
+		return !k.expire.IsZero() && !now.Before(k.expire)
+	})
+}
+
+// find returns the index of key, or -1. It must be called with r.mu held.
+func (r *keyring) find(key ssh.PublicKey) int {
+	blob := key.Marshal()
+	return slices.IndexFunc(r.keys, func(k keyringKey) bool {
+		return bytes.Equal(k.signer.PublicKey().Marshal(), blob)
+	})
+}
+
+func (r *keyring) List() ([]*Key, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked {
+		return nil, nil
+	}
+	r.expire()
+	keys := make([]*Key, 0, len(r.keys))
+	for _, k := range r.keys {
+		pub := k.signer.PublicKey()
+		keys = append(keys, &Key{Format: pub.Type(), Blob: pub.Marshal(), Comment: k.comment})
+	}
+	return keys, nil
+}
+
+func (r *keyring) Sign(key ssh.PublicKey, data []byte, flags SignatureFlags) (*ssh.Signature, error) {
+	r.mu.Lock()
+	if r.locked {
+		r.mu.Unlock()
+		return nil, errLocked
+	}
+	r.expire()
+	i := r.find(key)
+	if i < 0 {
+		r.mu.Unlock()
+		return nil, errors.New("agent: key not found")
+	}
+	signer := r.keys[i].signer
+	r.mu.Unlock()
+
+	algorithm := ""
+	if key.Type() == ssh.KeyAlgoRSA || key.Type() == ssh.CertAlgoRSAv01 {
+		switch {
+		case flags&SignatureFlagRSASHA512 != 0:
+			algorithm = ssh.KeyAlgoRSASHA512
+		case flags&SignatureFlagRSASHA256 != 0:
+			algorithm = ssh.KeyAlgoRSASHA256
+		default:
+			algorithm = ssh.KeyAlgoRSA
+		}
+	}
+	return signer.Sign(rand.Reader, data, algorithm)
+}
+
+func (r *keyring) Add(key AddedKey) error {
+	if key.ConfirmBeforeUse {
+		return errors.New("agent: confirmation before use is not supported")
+	}
+	signer, err := ssh.NewSigner(key.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if key.Certificate != nil {
+		if signer, err = ssh.NewCertSigner(key.Certificate, signer); err != nil {
+			return err
+		}
+	}
+	k := keyringKey{signer: signer, comment: key.Comment}
+	if key.LifetimeSecs != 0 {
+		k.expire = time.Now().Add(time.Duration(key.LifetimeSecs) * time.Second)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked {
+		return errLocked
+	}
+	// Adding a key again replaces its comment and constraints.
+	if i := r.find(signer.PublicKey()); i >= 0 {
+		r.keys[i] = k
+	} else {
+		r.keys = append(r.keys, k)
+	}
+	return nil
+}
+
+func (r *keyring) Remove(key ssh.PublicKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked {
+		return errLocked
+	}
+	i := r.find(key)
+	if i < 0 {
+		return errors.New("agent: key not found")
+	}
+	r.keys = slices.Delete(r.keys, i, i+1)
+	return nil
+}
+
+func (r *keyring) RemoveAll() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked {
+		return errLocked
+	}
+	r.keys = nil
+	return nil
+}
+
+func (r *keyring) Lock(passphrase []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked {
+		return errLocked
+	}
+	r.locked = true
+	r.passphrase = bytes.Clone(passphrase)
+	return nil
+}
+
+func (r *keyring) Unlock(passphrase []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.locked {
+		return errors.New("agent: not locked")
+	}
+	if subtle.ConstantTimeCompare(passphrase, r.passphrase) != 1 {
+		return errors.New("agent: incorrect passphrase")
+	}
+	r.locked = false
+	r.passphrase = nil
+	return nil
+}
+
+func (r *keyring) Signers() ([]ssh.Signer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked {
+		return nil, errLocked
+	}
+	r.expire()
+	signers := make([]ssh.Signer, len(r.keys))
+	for i, k := range r.keys {
+		signers[i] = k.signer
+	}
+	return signers, nil
+}
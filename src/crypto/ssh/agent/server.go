@@ -0,0 +1,177 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package agent
+
+import (
+	"crypto/ssh"
+	"crypto/ssh/internal/wire"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ServeAgent serves the agent protocol on conn, which is usually a
+// [net.Conn], handling requests with agent until conn is closed by the
+// client, in which case it returns nil.
+//
+// Requests that agent fails, and requests not supported by this package, such
+// as protocol extensions, are answered with a failure message.
+func ServeAgent(agent Agent, conn io.ReadWriter) error {
+	for {
+		req, err := readMessage(conn)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp, err := handleRequest(agent, req)
+		if err != nil {
+			resp = []byte{agentFailure}
+		}
+		if err := writeMessage(conn, resp); err != nil {
+			return err
+		}
+	}
+}
+
+var errInvalidRequest = errors.New("agent: invalid request")
+
+func handleRequest(agent Agent, req []byte) ([]byte, error) {
+	msg, body := req[0], req[1:]
+	switch msg {
+	case agentRequestIdentities:
+		keys, err := agent.List()
+		if err != nil {
+			return nil, err
+		}
+		resp := []byte{agentIdentitiesAnswer}
+		resp = wire.AppendUint32(resp, uint32(len(keys)))
+		for _, k := range keys {
+			resp = wire.AppendString(resp, k.Blob)
+			resp = wire.AppendString(resp, k.Comment)
+		}
+		return resp, nil
+
+	case agentSignRequest:
+		blob, rest, ok := wire.ParseString(body)
+		if !ok {
+			return nil, errInvalidRequest
+		}
+		data, rest, ok := wire.ParseString(rest)
+		if !ok {
+			return nil, errInvalidRequest
+		}
+		flags, rest, ok := wire.ParseUint32(rest)
+		if !ok || len(rest) != 0 {
+			return nil, errInvalidRequest
+		}
+		key, err := ssh.ParsePublicKey(blob)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := agent.Sign(key, data, SignatureFlags(flags))
+		if err != nil {
+			return nil, err
+		}
+		return wire.AppendString([]byte{agentSignResponse}, sig.Marshal()), nil
+
+	case agentAddIdentity, agentAddIDConstrained:
+		key, err := parseAddedKey(body, msg == agentAddIDConstrained)
+		if err != nil {
+			return nil, err
+		}
+		return success(agent.Add(key))
+
+	case agentRemoveIdentity:
+		blob, rest, ok := wire.ParseString(body)
+		if !ok || len(rest) != 0 {
+			return nil, errInvalidRequest
+		}
+		key, err := ssh.ParsePublicKey(blob)
+		if err != nil {
+			return nil, err
+		}
+		return success(agent.Remove(key))
+
+	case agentRemoveAllIdentities:
+		return success(agent.RemoveAll())
+
+	case agentLock, agentUnlock:
+		passphrase, rest, ok := wire.ParseString(body)
+		if !ok || len(rest) != 0 {
+			return nil, errInvalidRequest
+		}
+		if msg == agentLock {
+			return success(agent.Lock(passphrase))
+		}
+		return success(agent.Unlock(passphrase))
+	}
+	return nil, errors.New("agent: unsupported request")
+}
+
+func success(err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return []byte{agentSuccess}, nil
+}
+
+// parseAddedKey parses the body of a request encoded by marshalAddedKey.
+func parseAddedKey(in []byte, constrained bool) (AddedKey, error) {
+	var key AddedKey
+	keyType, rest, ok := wire.ParseString(in)
+	if !ok {
+		return key, errInvalidRequest
+	}
+	if strings.HasSuffix(string(keyType), "-cert-v01@openssh.com") {
+		blob, r, ok := wire.ParseString(rest)
+		if !ok {
+			return key, errInvalidRequest
+		}
+		pub, err := ssh.ParsePublicKey(blob)
+		if err != nil {
+			return key, err
+		}
+		cert, ok := pub.(*ssh.Certificate)
+		if !ok || cert.Type() != string(keyType) {
+			return key, errInvalidRequest
+		}
+		key.Certificate = cert
+		key.PrivateKey, rest, err = wire.ParseCertPrivateKey(cert.Key.CryptoPublicKey(), r)
+		if err != nil {
+			return key, err
+		}
+	} else {
+		var err error
+		key.PrivateKey, rest, err = wire.ParsePrivateKey(in)
+		if err != nil {
+			return key, err
+		}
+	}
+	comment, rest, ok := wire.ParseString(rest)
+	if !ok {
+		return key, errInvalidRequest
+	}
+	key.Comment = string(comment)
+
+	if !constrained && len(rest) != 0 {
+		return key, errInvalidRequest
+	}
+	for len(rest) > 0 {
+		switch rest[0] {
+		case agentConstrainLifetime:
+			if key.LifetimeSecs, rest, ok = wire.ParseUint32(rest[1:]); !ok {
+				return key, errInvalidRequest
+			}
+		case agentConstrainConfirm:
+			key.ConfirmBeforeUse = true
+			rest = rest[1:]
+		default:
+			return key, errors.New("agent: unsupported key constraint")
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,373 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"crypto"
+	"crypto/ssh/internal/wire"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The certificate types, as specified in the OpenSSH PROTOCOL.certkeys
+// document. Each is the type of certificates of keys of the corresponding
+// [KeyAlgoRSA], [KeyAlgoECDSA256], etc. type.
+const (
+	CertAlgoRSAv01      = "ssh-rsa-cert-v01@openssh.com"
+	CertAlgoECDSA256v01 = "ecdsa-sha2-nistp256-cert-v01@openssh.com"
+	CertAlgoECDSA384v01 = "ecdsa-sha2-nistp384-cert-v01@openssh.com"
+	CertAlgoECDSA521v01 = "ecdsa-sha2-nistp521-cert-v01@openssh.com"
+	CertAlgoED25519v01  = "ssh-ed25519-cert-v01@openssh.com"
+)
+
+// certSuffix is the suffix of a key type that makes it a certificate type.
+const certSuffix = "-cert-v01@openssh.com"
+
+// Values of [Certificate.CertType].
+const (
+	UserCert = 1
+	HostCert = 2
+)
+
+// CertTimeInfinity is the value of [Certificate.ValidBefore] for certificates
+// that do not expire.
+const CertTimeInfinity = 1<<64 - 1
+
+// A Certificate is an OpenSSH certificate, as specified in the OpenSSH
+// PROTOCOL.certkeys document. It certifies that a public key may be used to
+// authenticate as the listed principals, such as user names or host names.
+//
+// A Certificate is a [PublicKey] whose signatures are verified with the
+// certified key.
+type Certificate struct {
+	// Nonce is a random value that makes the signed data unpredictable.
+	// It is set by [Certificate.SignCert].
+	Nonce []byte
+
+	// Key is the certified public key. It must not be a certificate.
+	Key PublicKey
+
+	// Serial is an optional serial number, chosen by the authority.
+	Serial uint64
+
+	// CertType is either UserCert or HostCert.
+	CertType uint32
+
+	// KeyID is a free-form identifier, usually logged when the certificate
+	// is used.
+	KeyID string
+
+	// ValidPrincipals are the user names or host names for which the
+	// certificate is valid. If empty, the certificate is valid for any
+	// principal.
+	ValidPrincipals []string
+
+	// ValidAfter and ValidBefore are the bounds of the validity period, in
+	// seconds since the Unix epoch. A ValidBefore of CertTimeInfinity means
+	// the certificate never expires.
+	ValidAfter  uint64
+	ValidBefore uint64
+
+	// CriticalOptions are restrictions, such as "force-command" or
+	// "source-address", that a verifier must enforce or reject the
+	// certificate.
+	CriticalOptions map[string]string
+
+	// Extensions are optional features, such as "permit-pty", that a
+	// verifier may ignore.
+	Extensions map[string]string
+
+	// Reserved is currently unused and should be empty.
+	Reserved []byte
+
+	// SignatureKey is the public key of the certificate authority. It is set
+	// by [Certificate.SignCert].
+	SignatureKey PublicKey
+
+	// Signature is the signature of the authority over the certificate. It is
+	// set by [Certificate.SignCert].
+	Signature *Signature
+}
+
+// Type returns the certificate type corresponding to the type of c.Key, such
+// as [CertAlgoED25519v01].
+func (c *Certificate) Type() string {
+	return c.Key.Type() + certSuffix
+}
+
+// Verify checks that sig is a valid signature of data by the certified key.
+// It doesn't check the certificate itself, see [CertChecker.CheckCert].
+func (c *Certificate) Verify(data []byte, sig *Signature) error {
+	return c.Key.Verify(data, sig)
+}
+
+// CryptoPublicKey returns the certified key.
+func (c *Certificate) CryptoPublicKey() crypto.PublicKey {
+	return c.Key.CryptoPublicKey()
+}
+
+// Marshal returns the certificate in the SSH wire format.
+func (c *Certificate) Marshal() []byte {
+	b := c.marshalForSigning()
+	var sig []byte
+	if c.Signature != nil {
+		sig = c.Signature.Marshal()
+	}
+	return wire.AppendString(b, sig)
+}
+
+// marshalForSigning returns the fields of the certificate covered by the
+// signature, which are all of them but the signature.
+func (c *Certificate) marshalForSigning() []byte {
+	b := wire.AppendString(nil, c.Type())
+	b = wire.AppendString(b, c.Nonce)
+	// Skip the type of the certified key, since it is implied by ours.
+	_, keyFields, _ := wire.ParseString(c.Key.Marshal())
+	b = append(b, keyFields...)
+	b = wire.AppendUint64(b, c.Serial)
+	b = wire.AppendUint32(b, c.CertType)
+	b = wire.AppendString(b, c.KeyID)
+	b = wire.AppendStringList(b, c.ValidPrincipals)
+	b = wire.AppendUint64(b, c.ValidAfter)
+	b = wire.AppendUint64(b, c.ValidBefore)
+	b = appendOptions(b, c.CriticalOptions)
+	b = appendOptions(b, c.Extensions)
+	b = wire.AppendString(b, c.Reserved)
+	var signatureKey []byte
+	if c.SignatureKey != nil {
+		signatureKey = c.SignatureKey.Marshal()
+	}
+	return wire.AppendString(b, signatureKey)
+}
+
+// appendOptions appends critical options or extensions, sorted by name. A
+// non-empty value is itself encoded as a string.
+func appendOptions(b []byte, opts map[string]string) []byte {
+	names := make([]string, 0, len(opts))
+	for name := range opts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var inner []byte
+	for _, name := range names {
+		inner = wire.AppendString(inner, name)
+		if value := opts[name]; value != "" {
+			inner = wire.AppendUint32(inner, uint32(4+len(value)))
+			inner = wire.AppendString(inner, value)
+		} else {
+			inner = wire.AppendUint32(inner, 0)
+		}
+	}
+	return wire.AppendString(b, inner)
+}
+
+var errInvalidCert = errors.New("ssh: invalid certificate")
+
+func parseCert(certType string, in []byte) (*Certificate, []byte, error) {
+	c := new(Certificate)
+	var ok bool
+	if c.Nonce, in, ok = wire.ParseString(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	key, in, err := parsePublicKeyFields(strings.TrimSuffix(certType, certSuffix), in)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.Key = key
+	if c.Serial, in, ok = wire.ParseUint64(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.CertType, in, ok = wire.ParseUint32(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	var keyID, principals []byte
+	if keyID, in, ok = wire.ParseString(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	c.KeyID = string(keyID)
+	if principals, in, ok = wire.ParseString(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.ValidPrincipals, ok = wire.ParseStringList(principals); !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.ValidAfter, in, ok = wire.ParseUint64(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.ValidBefore, in, ok = wire.ParseUint64(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.CriticalOptions, in, ok = parseOptions(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.Extensions, in, ok = parseOptions(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.Reserved, in, ok = wire.ParseString(in); !ok {
+		return nil, nil, errInvalidCert
+	}
+	signatureKey, in, ok := wire.ParseString(in)
+	if !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.SignatureKey, err = ParsePublicKey(signatureKey); err != nil {
+		return nil, nil, err
+	}
+	if _, ok := c.SignatureKey.(*Certificate); ok {
+		return nil, nil, errors.New("ssh: certificate signed by a certificate")
+	}
+	sig, in, ok := wire.ParseString(in)
+	if !ok {
+		return nil, nil, errInvalidCert
+	}
+	if c.Signature, err = ParseSignature(sig); err != nil {
+		return nil, nil, err
+	}
+	return c, in, nil
+}
+
+// parseOptions parses critical options or extensions, whose names must be
+// unique and sorted.
+func parseOptions(in []byte) (map[string]string, []byte, bool) {
+	data, rest, ok := wire.ParseString(in)
+	if !ok {
+		return nil, nil, false
+	}
+	var opts map[string]string
+	prev := ""
+	for len(data) > 0 {
+		var name, value []byte
+		if name, data, ok = wire.ParseString(data); !ok {
+			return nil, nil, false
+		}
+		if value, data, ok = wire.ParseString(data); !ok {
+			return nil, nil, false
+		}
+		if opts != nil && string(name) <= prev {
+			return nil, nil, false
+		}
+		if len(value) > 0 {
+			v, r, ok := wire.ParseString(value)
+			if !ok || len(r) != 0 {
+				return nil, nil, false
+			}
+			value = v
+		}
+		if opts == nil {
+			opts = make(map[string]string)
+		}
+		opts[string(name)] = string(value)
+		prev = string(name)
+	}
+	return opts, rest, true
+}
+
+// SignCert sets c.Nonce to a random value and c.SignatureKey to the public
+// key of authority, and signs the certificate with authority, using the
+// default signature algorithm of [Signer.Sign].
+func (c *Certificate) SignCert(rand io.Reader, authority Signer) error {
+	if c.Key == nil {
+		return errors.New("ssh: certificate has no key")
+	}
+	if _, ok := c.Key.(*Certificate); ok {
+		return errors.New("ssh: cannot certify a certificate")
+	}
+	signatureKey := authority.PublicKey()
+	if _, ok := signatureKey.(*Certificate); ok {
+		return errors.New("ssh: cannot sign a certificate with a certificate")
+	}
+	c.Nonce = make([]byte, 32)
+	if _, err := io.ReadFull(rand, c.Nonce); err != nil {
+		return err
+	}
+	c.SignatureKey = signatureKey
+	sig, err := authority.Sign(rand, c.marshalForSigning(), "")
+	if err != nil {
+		return err
+	}
+	c.Signature = sig
+	return nil
+}
+
+// A CertChecker checks certificates against a set of trusted authorities and
+// a validation policy.
+type CertChecker struct {
+	// IsUserAuthority reports whether auth is trusted to sign user
+	// certificates. If nil, user certificates are rejected.
+	IsUserAuthority func(auth PublicKey) bool
+
+	// IsHostAuthority reports whether auth is trusted to sign host
+	// certificates for the host at address. If nil, host certificates are
+	// rejected.
+	IsHostAuthority func(auth PublicKey, address string) bool
+
+	// Clock returns the current time. If nil, time.Now is used.
+	Clock func() time.Time
+
+	// IsRevoked, if not nil, reports whether the certificate is revoked.
+	IsRevoked func(cert *Certificate) bool
+
+	// SupportedCriticalOptions are the critical options that the caller
+	// enforces. Certificates with other critical options are rejected.
+	SupportedCriticalOptions []string
+}
+
+// CheckCert checks that cert is signed by a trusted authority, is valid for
+// principal at the current time, has no unsupported critical options, and is
+// not revoked. For host certificates, principal is the host name, which is
+// also passed to IsHostAuthority.
+//
+// Certificates signed with the SHA-1 based [KeyAlgoRSA] signature algorithm
+// are rejected, as by OpenSSH since version 8.2.
+func (c *CertChecker) CheckCert(principal string, cert *Certificate) error {
+	switch cert.CertType {
+	case UserCert:
+		if c.IsUserAuthority == nil || !c.IsUserAuthority(cert.SignatureKey) {
+			return errors.New("ssh: certificate signed by unrecognized authority")
+		}
+	case HostCert:
+		if c.IsHostAuthority == nil || !c.IsHostAuthority(cert.SignatureKey, principal) {
+			return errors.New("ssh: certificate signed by unrecognized authority")
+		}
+	default:
+		return errors.New("ssh: unsupported certificate type " + strconv.FormatUint(uint64(cert.CertType), 10))
+	}
+	if cert.Signature == nil || cert.Signature.Format == KeyAlgoRSA {
+		return errors.New("ssh: unsupported certificate signature algorithm")
+	}
+	if err := cert.SignatureKey.Verify(cert.marshalForSigning(), cert.Signature); err != nil {
+		return err
+	}
+
+	if c.IsRevoked != nil && c.IsRevoked(cert) {
+		return errors.New("ssh: certificate serial " + strconv.FormatUint(cert.Serial, 10) + " revoked")
+	}
+	for opt := range cert.CriticalOptions {
+		if !slices.Contains(c.SupportedCriticalOptions, opt) {
+			return errors.New("ssh: unsupported critical option " + strconv.Quote(opt) + " in certificate")
+		}
+	}
+	if len(cert.ValidPrincipals) > 0 && !slices.Contains(cert.ValidPrincipals, principal) {
+		return errors.New("ssh: principal " + strconv.Quote(principal) + " not in the set of valid principals " +
+			strings.Join(cert.ValidPrincipals, ", "))
+	}
+
+	now := time.Now
+	if c.Clock != nil {
+		now = c.Clock
+	}
+	t := now().Unix()
+	if t < 0 || uint64(t) < cert.ValidAfter {
+		return errors.New("ssh: certificate is not yet valid")
+	}
+	if cert.ValidBefore != CertTimeInfinity && uint64(t) >= cert.ValidBefore {
+		return errors.New("ssh: certificate has expired")
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func readTestCert(t *testing.T, name string) *Certificate {
+	t.Helper()
+	pub, _ := readTestPublicKey(t, name)
+	cert, ok := pub.(*Certificate)
+	if !ok {
+		t.Fatalf("%s.pub: got %T, want *Certificate", name, pub)
+	}
+	return cert
+}
+
+func TestParseCert(t *testing.T) {
+	cert := readTestCert(t, "ed25519-cert")
+	key, _ := readTestPublicKey(t, "ed25519")
+	ca, _ := readTestPublicKey(t, "ca")
+
+	if cert.Type() != CertAlgoED25519v01 {
+		t.Errorf("Type() = %q, want %q", cert.Type(), CertAlgoED25519v01)
+	}
+	if !keysEqual(cert.Key, key) {
+		t.Errorf("certified key does not match ed25519.pub")
+	}
+	if !keysEqual(cert.SignatureKey, ca) {
+		t.Errorf("signature key does not match ca.pub")
+	}
+	if cert.Serial != 42 || cert.CertType != UserCert || cert.KeyID != "alice@example" {
+		t.Errorf("got serial %d, type %d, key ID %q", cert.Serial, cert.CertType, cert.KeyID)
+	}
+	if !slices.Equal(cert.ValidPrincipals, []string{"alice", "bob"}) {
+		t.Errorf("got principals %q", cert.ValidPrincipals)
+	}
+	validAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	validBefore := time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	if cert.ValidAfter != uint64(validAfter) || cert.ValidBefore != uint64(validBefore) {
+		t.Errorf("got validity [%d, %d), want [%d, %d)", cert.ValidAfter, cert.ValidBefore, validAfter, validBefore)
+	}
+	if want := map[string]string{"force-command": "/bin/true"}; !maps.Equal(cert.CriticalOptions, want) {
+		t.Errorf("got critical options %q, want %q", cert.CriticalOptions, want)
+	}
+	wantExtensions := map[string]string{
+		"permit-X11-forwarding":   "",
+		"permit-agent-forwarding": "",
+		"permit-port-forwarding":  "",
+		"permit-user-rc":          "",
+	}
+	if !maps.Equal(cert.Extensions, wantExtensions) {
+		t.Errorf("got extensions %q, want %q", cert.Extensions, wantExtensions)
+	}
+
+	// Marshal must reproduce the exact encoding, or the signature breaks.
+	again, err := ParsePublicKey(cert.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Marshal(), cert.Marshal()) {
+		t.Errorf("certificate did not round-trip")
+	}
+	line := readTestFile(t, "ed25519-cert.pub")
+	if got := MarshalAuthorizedKey(cert); !bytes.HasPrefix(line, bytes.TrimSuffix(got, []byte("\n"))) {
+		t.Errorf("MarshalAuthorizedKey = %q, want prefix of %q", got, line)
+	}
+	if err := cert.SignatureKey.Verify(cert.marshalForSigning(), cert.Signature); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
+
+func TestCheckCert(t *testing.T) {
+	cert := readTestCert(t, "ed25519-cert")
+	ca, _ := readTestPublicKey(t, "ca")
+	rsaKey, _ := readTestPublicKey(t, "rsa")
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	newChecker := func() *CertChecker {
+		return &CertChecker{
+			IsUserAuthority:          func(auth PublicKey) bool { return keysEqual(auth, ca) },
+			Clock:                    func() time.Time { return now },
+			SupportedCriticalOptions: []string{"force-command"},
+		}
+	}
+	if err := newChecker().CheckCert("alice", cert); err != nil {
+		t.Errorf("CheckCert(alice): %v", err)
+	}
+	if err := newChecker().CheckCert("bob", cert); err != nil {
+		t.Errorf("CheckCert(bob): %v", err)
+	}
+
+	for name, tt := range map[string]struct {
+		principal string
+		modify    func(*CertChecker)
+	}{
+		"unknown principal": {"carol", nil},
+		"unsupported critical option": {"alice", func(c *CertChecker) {
+			c.SupportedCriticalOptions = nil
+		}},
+		"expired": {"alice", func(c *CertChecker) {
+			c.Clock = func() time.Time { return time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC) }
+		}},
+		"not yet valid": {"alice", func(c *CertChecker) {
+			c.Clock = func() time.Time { return time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC) }
+		}},
+		"revoked": {"alice", func(c *CertChecker) {
+			c.IsRevoked = func(cert *Certificate) bool { return cert.Serial == 42 }
+		}},
+		"wrong authority": {"alice", func(c *CertChecker) {
+			c.IsUserAuthority = func(auth PublicKey) bool { return keysEqual(auth, rsaKey) }
+		}},
+		"no user authority": {"alice", func(c *CertChecker) {
+			c.IsUserAuthority = nil
+			c.IsHostAuthority = func(PublicKey, string) bool { return true }
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := newChecker()
+			if tt.modify != nil {
+				tt.modify(c)
+			}
+			if err := c.CheckCert(tt.principal, cert); err == nil {
+				t.Errorf("CheckCert succeeded")
+			}
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := *cert
+		tampered.ValidPrincipals = []string{"alice", "bob", "carol"}
+		if err := newChecker().CheckCert("carol", &tampered); err == nil {
+			t.Errorf("CheckCert succeeded")
+		}
+	})
+}
+
+func TestCheckCertRSAAuthority(t *testing.T) {
+	cert := readTestCert(t, "ecdsa384-cert")
+	rsaKey, _ := readTestPublicKey(t, "rsa")
+	if cert.Signature.Format != KeyAlgoRSASHA256 {
+		t.Errorf("got signature format %q, want %q", cert.Signature.Format, KeyAlgoRSASHA256)
+	}
+	if cert.ValidAfter != 0 || cert.ValidBefore != CertTimeInfinity {
+		t.Errorf("got validity [%d, %d), want forever", cert.ValidAfter, cert.ValidBefore)
+	}
+	checker := &CertChecker{
+		IsUserAuthority: func(auth PublicKey) bool { return keysEqual(auth, rsaKey) },
+	}
+	if err := checker.CheckCert("carol", cert); err != nil {
+		t.Errorf("CheckCert: %v", err)
+	}
+
+	// Re-sign the certificate with the SHA-1 based ssh-rsa algorithm, which
+	// must be rejected.
+	signer := readTestSigner(t, "rsa")
+	sig, err := signer.Sign(rand.Reader, cert.marshalForSigning(), KeyAlgoRSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sha1Cert := *cert
+	sha1Cert.Signature = sig
+	if err := checker.CheckCert("carol", &sha1Cert); err == nil {
+		t.Errorf("CheckCert accepted an ssh-rsa signature")
+	}
+}
+
+func TestSignCert(t *testing.T) {
+	key, _ := readTestPublicKey(t, "ecdsa256")
+	for name, authorityKey := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			authority, err := NewSigner(authorityKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cert := &Certificate{
+				Key:             key,
+				Serial:          1,
+				CertType:        HostCert,
+				KeyID:           "test",
+				ValidPrincipals: []string{"host.example"},
+				ValidBefore:     CertTimeInfinity,
+				CriticalOptions: map[string]string{},
+				Extensions:      map[string]string{},
+			}
+			if err := cert.SignCert(rand.Reader, authority); err != nil {
+				t.Fatal(err)
+			}
+			if name == "rsa" && cert.Signature.Format != KeyAlgoRSASHA512 {
+				t.Errorf("got signature format %q, want %q", cert.Signature.Format, KeyAlgoRSASHA512)
+			}
+
+			pub, err := ParsePublicKey(cert.Marshal())
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed := pub.(*Certificate)
+			checker := &CertChecker{
+				IsHostAuthority: func(auth PublicKey, address string) bool {
+					return keysEqual(auth, authority.PublicKey()) && address == "host.example"
+				},
+			}
+			if err := checker.CheckCert("host.example", parsed); err != nil {
+				t.Errorf("CheckCert: %v", err)
+			}
+			if err := checker.CheckCert("other.example", parsed); err == nil {
+				t.Errorf("CheckCert accepted the wrong host")
+			}
+			parsed.Serial++
+			if err := checker.CheckCert("host.example", parsed); err == nil {
+				t.Errorf("CheckCert accepted a modified certificate")
+			}
+
+			// The certificate can be used with the private key it certifies.
+			certSigner, err := NewCertSigner(cert, readTestSigner(t, "ecdsa256"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if certSigner.PublicKey() != PublicKey(cert) {
+				t.Errorf("PublicKey() is not the certificate")
+			}
+			testSignVerify(t, certSigner, cert, "")
+		})
+	}
+}
+
+func TestCertSignerMismatch(t *testing.T) {
+	cert := readTestCert(t, "ed25519-cert")
+	if _, err := NewCertSigner(cert, readTestSigner(t, "ecdsa256")); err == nil {
+		t.Errorf("NewCertSigner accepted a signer for a different key")
+	}
+	if _, err := NewCertSigner(cert, readTestSigner(t, "ed25519")); err != nil {
+		t.Errorf("NewCertSigner: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/ssh"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+func ExampleCertificate_SignCert() {
+	// Create a certificate authority and a user key.
+	_, caKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ca, err := ssh.NewSigner(caKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	userKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(userKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Issue a user certificate, which could be written to id_ed25519-cert.pub.
+	cert := &ssh.Certificate{
+		Key:             pub,
+		CertType:        ssh.UserCert,
+		KeyID:           "alice@example.com",
+		ValidPrincipals: []string{"alice"},
+		ValidBefore:     ssh.CertTimeInfinity,
+		Extensions:      map[string]string{"permit-pty": ""},
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(strings.Fields(string(ssh.MarshalAuthorizedKey(cert)))[0])
+
+	// A server that trusts the authority accepts the certificate for alice.
+	checker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return string(auth.Marshal()) == string(ca.PublicKey().Marshal())
+		},
+	}
+	fmt.Println(checker.CheckCert("alice", cert))
+	fmt.Println(checker.CheckCert("bob", cert) != nil)
+	// Output:
+	// ssh-ed25519-cert-v01@openssh.com
+	// <nil>
+	// true
+}
+
+func ExampleMarshalPrivateKey() {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "alice@example.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+	signer, err := ssh.NewSigner(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Write the key pair in the same format as ssh-keygen.
+	dir, err := os.MkdirTemp("", "ssh")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(dir+"/id_ed25519", pem.EncodeToMemory(block), 0600); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/id_ed25519.pub", ssh.MarshalAuthorizedKey(signer.PublicKey()), 0644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(block.Type)
+	// Output: OPENSSH PRIVATE KEY
+}
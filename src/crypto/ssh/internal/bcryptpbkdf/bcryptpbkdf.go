@@ -0,0 +1,87 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bcryptpbkdf implements bcrypt_pbkdf, the password-based key
+// derivation function used by OpenSSH to encrypt private keys.
+//
+// See https://flak.tedunangst.com/post/bcrypt-pbkdf and OpenBSD's
+// lib/libutil/bcrypt_pbkdf.c.
+package bcryptpbkdf
+
+import (
+	"crypto/internal/blowfish"
+	"crypto/sha512"
+	"errors"
+	"internal/byteorder"
+)
+
+const blockSize = 32
+
+// Key derives a key of keyLen bytes from password and salt, with the given
+// number of rounds.
+func Key(password, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if rounds < 1 {
+		return nil, errors.New("bcryptpbkdf: number of rounds is too small")
+	}
+	if len(password) == 0 {
+		return nil, errors.New("bcryptpbkdf: empty password")
+	}
+	if len(salt) == 0 || len(salt) > 1<<20 {
+		return nil, errors.New("bcryptpbkdf: bad salt length")
+	}
+	if keyLen < 1 || keyLen > 1024 {
+		return nil, errors.New("bcryptpbkdf: bad key length")
+	}
+
+	numBlocks := (keyLen + blockSize - 1) / blockSize
+	key := make([]byte, numBlocks*blockSize)
+
+	shapass := sha512.Sum512(password)
+	h := sha512.New()
+	var cnt [4]byte
+	var tmp, out [blockSize]byte
+	for block := 1; block <= numBlocks; block++ {
+		h.Reset()
+		h.Write(salt)
+		byteorder.BEPutUint32(cnt[:], uint32(block))
+		h.Write(cnt[:])
+		bcryptHash(&tmp, shapass[:], h.Sum(nil))
+		out = tmp
+		for i := 2; i <= rounds; i++ {
+			shasalt := sha512.Sum512(tmp[:])
+			bcryptHash(&tmp, shapass[:], shasalt[:])
+			for j := range out {
+				out[j] ^= tmp[j]
+			}
+		}
+
+		// The output blocks are interleaved, rather than concatenated.
+		for i, v := range out {
+			key[i*numBlocks+(block-1)] = v
+		}
+	}
+	return key[:keyLen], nil
+}
+
+var magic = []byte("OxychromaticBlowfishSwatDynamite")
+
+// bcryptHash is the core of bcrypt_pbkdf, a bcrypt variant without cost
+// parameter or encoding.
+func bcryptHash(out *[blockSize]byte, shapass, shasalt []byte) {
+	c := blowfish.NewSalted(shapass, shasalt)
+	for range 64 {
+		c.ExpandKey(shasalt, nil)
+		c.ExpandKey(shapass, nil)
+	}
+	for i := 0; i < blockSize; i += 8 {
+		l := byteorder.BEUint32(magic[i:])
+		r := byteorder.BEUint32(magic[i+4:])
+		for range 64 {
+			l, r = c.Encrypt(l, r)
+		}
+		// The words are output in little-endian order.
+		byteorder.LEPutUint32(out[i:], l)
+		byteorder.LEPutUint32(out[i+4:], r)
+	}
+}
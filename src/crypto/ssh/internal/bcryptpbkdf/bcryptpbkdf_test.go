@@ -0,0 +1,49 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bcryptpbkdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	// Generated by the OpenBSD reference implementation.
+	for _, tt := range []struct {
+		rounds         int
+		password, salt string
+		key            string
+	}{
+		{12, "password", "salt", "1ae42c05d487bc02f64921a4ebe4ea93bcacfe135fda99974c06b7b01fae149a"},
+		{3, "passwordy\x00PASSWORD\x00", "salty\x00SALT\x00", "7f310bd3e78c3280c59ce4595211a2928e8d4ec744c1ed2efc9f764e3388e0ad"},
+		{8, "секретное слово", "посолить немножко", "8df43fc6fe131fc47f0c9e39224bd94c70b6fcc8ee8135faddf61156e6cb2733" +
+			"ea765f315a3e1e4afc35bf8687d189254c1e05a6fe80c0617f9183d67260d6a1" +
+			"15c6c94e3603e2303fbb43a76a64523ffda686b1d4518543"},
+	} {
+		want, _ := hex.DecodeString(tt.key)
+		got, err := Key([]byte(tt.password), []byte(tt.salt), tt.rounds, len(want))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Key(%q, %q, %d): got %x, want %x", tt.password, tt.salt, tt.rounds, got, want)
+		}
+	}
+}
+
+func TestBcryptHash(t *testing.T) {
+	var pass, salt [64]byte
+	for i := range pass {
+		pass[i] = byte(i)
+		salt[i] = byte(i + 64)
+	}
+	var got [blockSize]byte
+	bcryptHash(&got, pass[:], salt[:])
+	want, _ := hex.DecodeString("87904870eef9deddf8e7611a140106e6aaf1a363d9a2c504db356443721eb555")
+	if !bytes.Equal(got[:], want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+)
+
+// The public key algorithms, which are also the key types.
+const (
+	KeyAlgoRSA      = "ssh-rsa"
+	KeyAlgoECDSA256 = "ecdsa-sha2-nistp256"
+	KeyAlgoECDSA384 = "ecdsa-sha2-nistp384"
+	KeyAlgoECDSA521 = "ecdsa-sha2-nistp521"
+	KeyAlgoED25519  = "ssh-ed25519"
+)
+
+// Curve returns the curve of an ECDSA key type and its identifier, as
+// specified in RFC 5656, Section 6.1, or nil if keyType is not an ECDSA key
+// type.
+func Curve(keyType string) (elliptic.Curve, string) {
+	switch keyType {
+	case KeyAlgoECDSA256:
+		return elliptic.P256(), "nistp256"
+	case KeyAlgoECDSA384:
+		return elliptic.P384(), "nistp384"
+	case KeyAlgoECDSA521:
+		return elliptic.P521(), "nistp521"
+	}
+	return nil, ""
+}
+
+// ECDSAKeyType returns the key type of ECDSA keys on curve, or "" if the
+// curve is not supported.
+func ECDSAKeyType(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return KeyAlgoECDSA256
+	case elliptic.P384():
+		return KeyAlgoECDSA384
+	case elliptic.P521():
+		return KeyAlgoECDSA521
+	}
+	return ""
+}
+
+// AppendPrivateKey appends key, starting with its type, in the format shared
+// by OpenSSH private key files and the SSH agent protocol. key must be an
+// *rsa.PrivateKey, an *ecdsa.PrivateKey, or an ed25519.PrivateKey.
+func AppendPrivateKey(b []byte, key crypto.PrivateKey) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, errors.New("ssh: multi-prime RSA keys are not supported")
+		}
+		b = AppendString(b, KeyAlgoRSA)
+		b = AppendMPInt(b, k.N)
+		b = AppendMPInt(b, big.NewInt(int64(k.E)))
+		return appendRSAPrivate(b, k), nil
+	case *ecdsa.PrivateKey:
+		keyType := ECDSAKeyType(k.Curve)
+		if keyType == "" {
+			return nil, errors.New("ssh: unsupported curve")
+		}
+		_, curveID := Curve(keyType)
+		q, err := k.PublicKey.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		b = AppendString(b, keyType)
+		b = AppendString(b, curveID)
+		b = AppendString(b, q)
+		return appendECDSAPrivate(b, k)
+	case ed25519.PrivateKey:
+		b = AppendString(b, KeyAlgoED25519)
+		return appendEd25519Private(b, k), nil
+	}
+	return nil, errors.New("ssh: unsupported private key type")
+}
+
+// AppendCertPrivateKey appends the private fields of key that follow the
+// certificate when adding a certificate to an SSH agent.
+func AppendCertPrivateKey(b []byte, key crypto.PrivateKey) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, errors.New("ssh: multi-prime RSA keys are not supported")
+		}
+		return appendRSAPrivate(b, k), nil
+	case *ecdsa.PrivateKey:
+		return appendECDSAPrivate(b, k)
+	case ed25519.PrivateKey:
+		return appendEd25519Private(b, k), nil
+	}
+	return nil, errors.New("ssh: unsupported private key type")
+}
+
+func appendRSAPrivate(b []byte, k *rsa.PrivateKey) []byte {
+	b = AppendMPInt(b, k.D)
+	b = AppendMPInt(b, new(big.Int).ModInverse(k.Primes[1], k.Primes[0]))
+	b = AppendMPInt(b, k.Primes[0])
+	return AppendMPInt(b, k.Primes[1])
+}
+
+func appendECDSAPrivate(b []byte, k *ecdsa.PrivateKey) ([]byte, error) {
+	d, err := k.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return AppendMPIntBytes(b, d), nil
+}
+
+func appendEd25519Private(b []byte, k ed25519.PrivateKey) []byte {
+	b = AppendString(b, k.Public().(ed25519.PublicKey))
+	return AppendString(b, k)
+}
+
+var errInvalidPrivateKey = errors.New("ssh: invalid private key")
+
+// ParsePrivateKey parses a private key encoded by [AppendPrivateKey].
+func ParsePrivateKey(in []byte) (key crypto.Signer, rest []byte, err error) {
+	keyType, in, ok := ParseString(in)
+	if !ok {
+		return nil, nil, errInvalidPrivateKey
+	}
+	switch string(keyType) {
+	case KeyAlgoRSA:
+		n, in, ok := ParseMPInt(in)
+		if !ok {
+			return nil, nil, errInvalidPrivateKey
+		}
+		e, in, ok := ParseMPInt(in)
+		if !ok {
+			return nil, nil, errInvalidPrivateKey
+		}
+		return parseRSAPrivate(n, e, in)
+	case KeyAlgoECDSA256, KeyAlgoECDSA384, KeyAlgoECDSA521:
+		curve, curveID := Curve(string(keyType))
+		id, in, ok := ParseString(in)
+		if !ok || string(id) != curveID {
+			return nil, nil, errInvalidPrivateKey
+		}
+		q, in, ok := ParseString(in)
+		if !ok {
+			return nil, nil, errInvalidPrivateKey
+		}
+		return parseECDSAPrivate(curve, q, in)
+	case KeyAlgoED25519:
+		return parseEd25519Private(nil, in)
+	}
+	return nil, nil, errors.New("ssh: unsupported private key type " + string(keyType))
+}
+
+// ParseCertPrivateKey parses the private fields encoded by
+// [AppendCertPrivateKey] for a certificate of the public key pub.
+func ParseCertPrivateKey(pub crypto.PublicKey, in []byte) (key crypto.Signer, rest []byte, err error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return parseRSAPrivate(pub.N, big.NewInt(int64(pub.E)), in)
+	case *ecdsa.PublicKey:
+		q, err := pub.Bytes()
+		if err != nil {
+			return nil, nil, err
+		}
+		return parseECDSAPrivate(pub.Curve, q, in)
+	case ed25519.PublicKey:
+		return parseEd25519Private(pub, in)
+	}
+	return nil, nil, errors.New("ssh: unsupported private key type")
+}
+
+func parseRSAPrivate(n, e *big.Int, in []byte) (crypto.Signer, []byte, error) {
+	d, in, ok := ParseMPInt(in)
+	if !ok {
+		return nil, nil, errInvalidPrivateKey
+	}
+	// The CRT coefficient is recomputed by Precompute.
+	_, in, ok = ParseMPInt(in)
+	if !ok {
+		return nil, nil, errInvalidPrivateKey
+	}
+	p, in, ok := ParseMPInt(in)
+	if !ok {
+		return nil, nil, errInvalidPrivateKey
+	}
+	q, in, ok := ParseMPInt(in)
+	if !ok {
+		return nil, nil, errInvalidPrivateKey
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, nil, errors.New("ssh: unsupported RSA public exponent")
+	}
+	k := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+		D:         d,
+		Primes:    []*big.Int{p, q},
+	}
+	if err := k.Validate(); err != nil {
+		return nil, nil, err
+	}
+	k.Precompute()
+	return k, in, nil
+}
+
+func parseECDSAPrivate(curve elliptic.Curve, q, in []byte) (crypto.Signer, []byte, error) {
+	d, in, ok := ParseMPInt(in)
+	if !ok {
+		return nil, nil, errInvalidPrivateKey
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if d.BitLen() > size*8 {
+		return nil, nil, errInvalidPrivateKey
+	}
+	k, err := ecdsa.ParseRawPrivateKey(curve, d.FillBytes(make([]byte, size)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if pub, err := k.PublicKey.Bytes(); err != nil || !bytes.Equal(pub, q) {
+		return nil, nil, errors.New("ssh: ECDSA private key does not match its public key")
+	}
+	return k, in, nil
+}
+
+// parseEd25519Private parses the public and private fields of an Ed25519
+// key. If want is not nil, the public key must be equal to it.
+func parseEd25519Private(want ed25519.PublicKey, in []byte) (crypto.Signer, []byte, error) {
+	pub, in, ok := ParseString(in)
+	if !ok || len(pub) != ed25519.PublicKeySize {
+		return nil, nil, errInvalidPrivateKey
+	}
+	priv, in, ok := ParseString(in)
+	if !ok || len(priv) != ed25519.PrivateKeySize {
+		return nil, nil, errInvalidPrivateKey
+	}
+	// The private key is the seed followed by the public key.
+	k := ed25519.NewKeyFromSeed(priv[:ed25519.SeedSize])
+	if !bytes.Equal(k[ed25519.SeedSize:], pub) || !bytes.Equal(priv[ed25519.SeedSize:], pub) ||
+		want != nil && !want.Equal(ed25519.PublicKey(pub)) {
+		return nil, nil, errors.New("ssh: Ed25519 private key does not match its public key")
+	}
+	return k, in, nil
+}
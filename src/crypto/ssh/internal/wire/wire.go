@@ -0,0 +1,112 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wire implements the encodings of the SSH data types, as specified
+// in RFC 4251, Section 5, and of private keys, shared by crypto/ssh and
+// crypto/ssh/agent.
+package wire
+
+import (
+	"internal/byteorder"
+	"math/big"
+)
+
+// ParseUint32 parses a big-endian uint32.
+func ParseUint32(in []byte) (uint32, []byte, bool) {
+	if len(in) < 4 {
+		return 0, nil, false
+	}
+	return byteorder.BEUint32(in), in[4:], true
+}
+
+// ParseUint64 parses a big-endian uint64.
+func ParseUint64(in []byte) (uint64, []byte, bool) {
+	if len(in) < 8 {
+		return 0, nil, false
+	}
+	return byteorder.BEUint64(in), in[8:], true
+}
+
+// ParseString parses a length-prefixed byte string.
+func ParseString(in []byte) (out, rest []byte, ok bool) {
+	n, in, ok := ParseUint32(in)
+	if !ok || uint32(len(in)) < n {
+		return nil, nil, false
+	}
+	return in[:n:n], in[n:], true
+}
+
+// ParseMPInt parses a non-negative multiple precision integer.
+func ParseMPInt(in []byte) (*big.Int, []byte, bool) {
+	b, rest, ok := ParseString(in)
+	if !ok {
+		return nil, nil, false
+	}
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		// Negative numbers are never valid in key material.
+		return nil, nil, false
+	}
+	return new(big.Int).SetBytes(b), rest, true
+}
+
+// ParseStringList parses a packed sequence of strings, such as the
+// principals of a certificate.
+func ParseStringList(in []byte) ([]string, bool) {
+	var list []string
+	for len(in) > 0 {
+		var s []byte
+		var ok bool
+		if s, in, ok = ParseString(in); !ok {
+			return nil, false
+		}
+		list = append(list, string(s))
+	}
+	return list, true
+}
+
+// AppendUint32 appends v in big-endian order.
+func AppendUint32(b []byte, v uint32) []byte {
+	return byteorder.BEAppendUint32(b, v)
+}
+
+// AppendUint64 appends v in big-endian order.
+func AppendUint64(b []byte, v uint64) []byte {
+	return byteorder.BEAppendUint64(b, v)
+}
+
+// AppendString appends s as a length-prefixed byte string.
+func AppendString[S ~string | ~[]byte](b []byte, s S) []byte {
+	b = AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// AppendMPInt appends n, which must be non-negative, as a multiple precision
+// integer.
+func AppendMPInt(b []byte, n *big.Int) []byte {
+	return AppendMPIntBytes(b, n.Bytes())
+}
+
+// AppendMPIntBytes appends the big-endian unsigned integer v, which may have
+// leading zeroes, as a multiple precision integer, with a leading zero byte
+// if its top bit is set.
+func AppendMPIntBytes(b []byte, v []byte) []byte {
+	for len(v) > 0 && v[0] == 0 {
+		v = v[1:]
+	}
+	if len(v) > 0 && v[0]&0x80 != 0 {
+		b = AppendUint32(b, uint32(len(v)+1))
+		b = append(b, 0)
+		return append(b, v...)
+	}
+	return AppendString(b, v)
+}
+
+// AppendStringList appends list as a string of packed strings.
+func AppendStringList(b []byte, list []string) []byte {
+	var inner []byte
+	for _, s := range list {
+		inner = AppendString(inner, s)
+	}
+	return AppendString(b, inner)
+}
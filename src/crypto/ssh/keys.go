@@ -0,0 +1,440 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ssh implements the key and certificate formats of the Secure Shell
+// (SSH) protocol, as used by OpenSSH.
+//
+// It parses and marshals public keys in the SSH wire format and in the
+// authorized_keys and known_hosts file formats, private keys in the OpenSSH
+// format, and OpenSSH certificates. It supports RSA, ECDSA (on P-256, P-384
+// and P-521) and Ed25519 keys, as specified in RFC 4253, RFC 5656, RFC 8332
+// and RFC 8709.
+//
+// The [crypto/ssh/agent] package implements the SSH agent protocol on top of
+// this package. The SSH transport, authentication, and connection protocols
+// are not implemented.
+package ssh
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/ssh/internal/wire"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// The public key algorithms. Each is also the type of the keys it applies to,
+// except for [KeyAlgoRSASHA256] and [KeyAlgoRSASHA512], which are signature
+// algorithms for [KeyAlgoRSA] keys, as specified in RFC 8332.
+const (
+	KeyAlgoRSA       = wire.KeyAlgoRSA
+	KeyAlgoRSASHA256 = "rsa-sha2-256"
+	KeyAlgoRSASHA512 = "rsa-sha2-512"
+	KeyAlgoECDSA256  = wire.KeyAlgoECDSA256
+	KeyAlgoECDSA384  = wire.KeyAlgoECDSA384
+	KeyAlgoECDSA521  = wire.KeyAlgoECDSA521
+	KeyAlgoED25519   = wire.KeyAlgoED25519
+)
+
+// A PublicKey is an SSH public key or certificate.
+type PublicKey interface {
+	// Type returns the type of the key, such as "ssh-ed25519", or of the
+	// certificate, such as "ssh-ed25519-cert-v01@openssh.com".
+	Type() string
+
+	// Marshal returns the key in the SSH wire format, as specified in
+	// RFC 4253, Section 6.6.
+	Marshal() []byte
+
+	// Verify returns nil if sig is a valid signature of data by the key, and
+	// an error otherwise.
+	Verify(data []byte, sig *Signature) error
+
+	// CryptoPublicKey returns the key as an *rsa.PublicKey, an
+	// *ecdsa.PublicKey, or an ed25519.PublicKey. For a certificate, it
+	// returns the certified key.
+	CryptoPublicKey() crypto.PublicKey
+}
+
+// A Signature is an SSH signature, as specified in RFC 4253, Section 6.6.
+type Signature struct {
+	// Format is the signature algorithm, such as "rsa-sha2-256".
+	Format string
+
+	// Blob is the algorithm-specific encoding of the signature.
+	Blob []byte
+}
+
+// Marshal returns the signature in the SSH wire format.
+func (s *Signature) Marshal() []byte {
+	b := wire.AppendString(nil, s.Format)
+	return wire.AppendString(b, s.Blob)
+}
+
+// ParseSignature parses a signature in the SSH wire format.
+func ParseSignature(in []byte) (*Signature, error) {
+	sig, rest, ok := parseSignature(in)
+	if !ok || len(rest) != 0 {
+		return nil, errors.New("ssh: invalid signature")
+	}
+	return sig, nil
+}
+
+func parseSignature(in []byte) (*Signature, []byte, bool) {
+	format, in, ok := wire.ParseString(in)
+	if !ok {
+		return nil, nil, false
+	}
+	blob, in, ok := wire.ParseString(in)
+	if !ok {
+		return nil, nil, false
+	}
+	return &Signature{Format: string(format), Blob: blob}, in, true
+}
+
+// NewPublicKey returns the SSH public key for key, which must be an
+// *rsa.PublicKey, an *ecdsa.PublicKey on P-256, P-384 or P-521, or an
+// ed25519.PublicKey.
+func NewPublicKey(key crypto.PublicKey) (PublicKey, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if err := checkRSAExponent(k.E); err != nil {
+			return nil, err
+		}
+		return (*rsaPublicKey)(k), nil
+	case *ecdsa.PublicKey:
+		keyType := wire.ECDSAKeyType(k.Curve)
+		if keyType == "" {
+			return nil, errors.New("ssh: unsupported curve")
+		}
+		if _, err := k.Bytes(); err != nil {
+			return nil, err
+		}
+		return (*ecdsaPublicKey)(k), nil
+	case ed25519.PublicKey:
+		if len(k) != ed25519.PublicKeySize {
+			return nil, errors.New("ssh: invalid Ed25519 public key")
+		}
+		return ed25519PublicKey(bytes.Clone(k)), nil
+	}
+	return nil, errors.New("ssh: unsupported public key type")
+}
+
+// ParsePublicKey parses a public key or certificate in the SSH wire format.
+func ParsePublicKey(in []byte) (PublicKey, error) {
+	keyType, rest, ok := wire.ParseString(in)
+	if !ok {
+		return nil, errors.New("ssh: invalid public key")
+	}
+	pub, rest, err := parsePublicKeyFields(string(keyType), rest)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("ssh: trailing data after public key")
+	}
+	return pub, nil
+}
+
+// parsePublicKeyFields parses the fields that follow the type of a public key
+// in the SSH wire format.
+func parsePublicKeyFields(keyType string, in []byte) (PublicKey, []byte, error) {
+	switch keyType {
+	case KeyAlgoRSA:
+		return parseRSA(in)
+	case KeyAlgoECDSA256, KeyAlgoECDSA384, KeyAlgoECDSA521:
+		return parseECDSA(keyType, in)
+	case KeyAlgoED25519:
+		return parseEd25519(in)
+	case CertAlgoRSAv01, CertAlgoECDSA256v01, CertAlgoECDSA384v01, CertAlgoECDSA521v01, CertAlgoED25519v01:
+		return parseCert(keyType, in)
+	}
+	return nil, nil, errors.New("ssh: unsupported public key type " + keyType)
+}
+
+var errInvalidPublicKey = errors.New("ssh: invalid public key")
+
+func checkRSAExponent(e int) error {
+	// OpenSSH and RFC 3447 require an odd exponent, and crypto/rsa rejects
+	// exponents that don't fit in 31 bits.
+	if e < 3 || e > 1<<31-1 || e%2 == 0 {
+		return errors.New("ssh: unsupported RSA public exponent")
+	}
+	return nil
+}
+
+type rsaPublicKey rsa.PublicKey
+
+func parseRSA(in []byte) (PublicKey, []byte, error) {
+	e, in, ok := wire.ParseMPInt(in)
+	if !ok {
+		return nil, nil, errInvalidPublicKey
+	}
+	n, in, ok := wire.ParseMPInt(in)
+	if !ok {
+		return nil, nil, errInvalidPublicKey
+	}
+	if !e.IsInt64() {
+		return nil, nil, errors.New("ssh: unsupported RSA public exponent")
+	}
+	if err := checkRSAExponent(int(e.Int64())); err != nil {
+		return nil, nil, err
+	}
+	return &rsaPublicKey{N: n, E: int(e.Int64())}, in, nil
+}
+
+func (k *rsaPublicKey) Type() string { return KeyAlgoRSA }
+
+func (k *rsaPublicKey) Marshal() []byte {
+	b := wire.AppendString(nil, KeyAlgoRSA)
+	b = wire.AppendMPInt(b, big.NewInt(int64(k.E)))
+	return wire.AppendMPInt(b, k.N)
+}
+
+func (k *rsaPublicKey) Verify(data []byte, sig *Signature) error {
+	var h crypto.Hash
+	switch sig.Format {
+	case KeyAlgoRSA:
+		h = crypto.SHA1
+	case KeyAlgoRSASHA256:
+		h = crypto.SHA256
+	case KeyAlgoRSASHA512:
+		h = crypto.SHA512
+	default:
+		return errors.New("ssh: signature type " + sig.Format + " for key type " + KeyAlgoRSA)
+	}
+	return rsa.VerifyPKCS1v15((*rsa.PublicKey)(k), h, hashData(h, data), sig.Blob)
+}
+
+func (k *rsaPublicKey) CryptoPublicKey() crypto.PublicKey { return (*rsa.PublicKey)(k) }
+
+type ecdsaPublicKey ecdsa.PublicKey
+
+func parseECDSA(keyType string, in []byte) (PublicKey, []byte, error) {
+	curve, curveID := wire.Curve(keyType)
+	id, in, ok := wire.ParseString(in)
+	if !ok || string(id) != curveID {
+		return nil, nil, errInvalidPublicKey
+	}
+	q, in, ok := wire.ParseString(in)
+	if !ok {
+		return nil, nil, errInvalidPublicKey
+	}
+	k, err := ecdsa.ParseUncompressedPublicKey(curve, q)
+	if err != nil {
+		return nil, nil, errInvalidPublicKey
+	}
+	return (*ecdsaPublicKey)(k), in, nil
+}
+
+func (k *ecdsaPublicKey) Type() string { return wire.ECDSAKeyType(k.Curve) }
+
+func (k *ecdsaPublicKey) Marshal() []byte {
+	keyType := k.Type()
+	_, curveID := wire.Curve(keyType)
+	// The point was validated when the key was created.
+	q, _ := (*ecdsa.PublicKey)(k).Bytes()
+	b := wire.AppendString(nil, keyType)
+	b = wire.AppendString(b, curveID)
+	return wire.AppendString(b, q)
+}
+
+func (k *ecdsaPublicKey) Verify(data []byte, sig *Signature) error {
+	if sig.Format != k.Type() {
+		return errors.New("ssh: signature type " + sig.Format + " for key type " + k.Type())
+	}
+	// The blob is the two integers r and s, as specified in RFC 5656,
+	// Section 3.1.2.
+	r, rest, ok := wire.ParseMPInt(sig.Blob)
+	if !ok {
+		return errors.New("ssh: invalid ECDSA signature")
+	}
+	s, rest, ok := wire.ParseMPInt(rest)
+	if !ok || len(rest) != 0 {
+		return errors.New("ssh: invalid ECDSA signature")
+	}
+	h := ecdsaHash(k.Curve)
+	if !ecdsa.Verify((*ecdsa.PublicKey)(k), hashData(h, data), r, s) {
+		return errors.New("ssh: ECDSA verification failure")
+	}
+	return nil
+}
+
+func (k *ecdsaPublicKey) CryptoPublicKey() crypto.PublicKey { return (*ecdsa.PublicKey)(k) }
+
+// ecdsaHash returns the hash used with ECDSA keys on curve, as specified in
+// RFC 5656, Section 6.2.1.
+func ecdsaHash(curve elliptic.Curve) crypto.Hash {
+	switch curve.Params().BitSize {
+	case 256:
+		return crypto.SHA256
+	case 384:
+		return crypto.SHA384
+	default:
+		return crypto.SHA512
+	}
+}
+
+type ed25519PublicKey ed25519.PublicKey
+
+func parseEd25519(in []byte) (PublicKey, []byte, error) {
+	k, in, ok := wire.ParseString(in)
+	if !ok || len(k) != ed25519.PublicKeySize {
+		return nil, nil, errInvalidPublicKey
+	}
+	return ed25519PublicKey(bytes.Clone(k)), in, nil
+}
+
+func (k ed25519PublicKey) Type() string { return KeyAlgoED25519 }
+
+func (k ed25519PublicKey) Marshal() []byte {
+	b := wire.AppendString(nil, KeyAlgoED25519)
+	return wire.AppendString(b, k)
+}
+
+func (k ed25519PublicKey) Verify(data []byte, sig *Signature) error {
+	if sig.Format != KeyAlgoED25519 {
+		return errors.New("ssh: signature type " + sig.Format + " for key type " + KeyAlgoED25519)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(k), data, sig.Blob) {
+		return errors.New("ssh: Ed25519 verification failure")
+	}
+	return nil
+}
+
+func (k ed25519PublicKey) CryptoPublicKey() crypto.PublicKey { return ed25519.PublicKey(k) }
+
+func hashData(h crypto.Hash, data []byte) []byte {
+	switch h {
+	case crypto.SHA1:
+		d := sha1.Sum(data)
+		return d[:]
+	case crypto.SHA256:
+		d := sha256.Sum256(data)
+		return d[:]
+	case crypto.SHA384:
+		d := sha512.Sum384(data)
+		return d[:]
+	case crypto.SHA512:
+		d := sha512.Sum512(data)
+		return d[:]
+	}
+	panic("ssh: unexpected hash")
+}
+
+// MarshalAuthorizedKey returns pub in the format of a line of an OpenSSH
+// authorized_keys file, without options or comment, and with a trailing
+// newline.
+func MarshalAuthorizedKey(pub PublicKey) []byte {
+	var b strings.Builder
+	b.WriteString(pub.Type())
+	b.WriteByte(' ')
+	b.WriteString(base64.StdEncoding.EncodeToString(pub.Marshal()))
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// ParseAuthorizedKey parses the first public key in in, which is in the format
+// of an OpenSSH authorized_keys file, as documented in sshd(8). Empty lines,
+// comment lines starting with '#', and lines that are not valid are skipped.
+//
+// It returns the key, its comment, its options (such as `from="*.example"`
+// or "no-pty"), and the rest of in after the line of the key. If in has no
+// valid key, it returns an error.
+func ParseAuthorizedKey(in []byte) (out PublicKey, comment string, options []string, rest []byte, err error) {
+	for len(in) > 0 {
+		var line []byte
+		line, in, _ = bytes.Cut(in, []byte{'\n'})
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		if out, comment, err = parseKeyWithComment(line); err == nil {
+			return out, comment, nil, in, nil
+		}
+
+		// The key is preceded by options, which may contain spaces in
+		// quoted values.
+		opts, keyLine, ok := parseAuthorizedKeyOptions(line)
+		if !ok {
+			continue
+		}
+		if out, comment, err = parseKeyWithComment(keyLine); err == nil {
+			return out, comment, opts, in, nil
+		}
+	}
+	return nil, "", nil, nil, errors.New("ssh: no key found")
+}
+
+// parseKeyWithComment parses a key type, a base64-encoded public key, and an
+// optional comment, separated by whitespace.
+func parseKeyWithComment(line []byte) (PublicKey, string, error) {
+	keyType, rest, ok := cutSpace(line)
+	if !ok {
+		return nil, "", errors.New("ssh: missing key")
+	}
+	encoded, comment, _ := cutSpace(rest)
+	der := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, err := base64.StdEncoding.Decode(der, encoded)
+	if err != nil {
+		return nil, "", err
+	}
+	pub, err := ParsePublicKey(der[:n])
+	if err != nil {
+		return nil, "", err
+	}
+	if pub.Type() != string(keyType) {
+		return nil, "", errors.New("ssh: key type " + string(keyType) + " does not match encoded key")
+	}
+	return pub, string(comment), nil
+}
+
+// cutSpace slices s around the first run of spaces or tabs, trimming
+// leading spaces and tabs from after.
+func cutSpace(s []byte) (before, after []byte, found bool) {
+	i := bytes.IndexAny(s, " \t")
+	if i < 0 {
+		return s, nil, false
+	}
+	return s[:i], bytes.TrimLeft(s[i:], " \t"), true
+}
+
+// parseAuthorizedKeyOptions parses the comma-separated options at the start
+// of line, which end at the first space or tab outside of double quotes.
+func parseAuthorizedKeyOptions(line []byte) (options []string, rest []byte, ok bool) {
+	start := 0
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && inQuote && i+1 < len(line) && line[i+1] == '"':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case c == ',' && !inQuote:
+			options = append(options, string(line[start:i]))
+			start = i + 1
+		case (c == ' ' || c == '\t') && !inQuote:
+			options = append(options, string(line[start:i]))
+			return options, bytes.TrimLeft(line[i:], " \t"), true
+		}
+	}
+	return nil, nil, false
+}
+
+// FingerprintSHA256 returns the SHA-256 fingerprint of pub, in the format
+// used by OpenSSH, such as "SHA256:uT/Rw0I2BLJ3fPjKMR0Sw9b+hTazNd+n5DPAxWfNoK0".
+func FingerprintSHA256(pub PublicKey) string {
+	h := sha256.Sum256(pub.Marshal())
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(h[:])
+}
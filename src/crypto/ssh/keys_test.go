@@ -0,0 +1,303 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The files in testdata were generated with OpenSSH 9.2:
+//
+//	ssh-keygen -t ed25519 -N '' -C 'ed25519 test key' -f ed25519
+//	ssh-keygen -t ecdsa -b 256 -N '' -C 'p256 test key' -f ecdsa256
+//	ssh-keygen -t rsa -b 2048 -N '' -C 'rsa test key' -f rsa
+//	ssh-keygen -t ed25519 -N hunter2 -C encrypted -f ed25519_encrypted
+//	ssh-keygen -t ecdsa -N hunter2 -Z aes256-gcm@openssh.com -C 'encrypted gcm' -f ecdsa256_encrypted_gcm
+//	ssh-keygen -s ca -I alice@example -n alice,bob -V 20250101:20350101 -O no-pty -O force-command=/bin/true -z 42 ed25519.pub
+//	ssh-keygen -s ca -I host.example -h -n host.example,10.0.0.1 -V always:forever ecdsa256.pub
+//	ssh-keygen -s rsa -t rsa-sha2-256 -I rsa-signed -n carol -V always:forever ecdsa384.pub
+//
+// and similarly for the other keys. rsa_pkcs1 is rsa converted with
+// "ssh-keygen -p -m PEM", and ecdsa521_pkcs8_encrypted is ecdsa521 converted
+// with "ssh-keygen -p -m PKCS8" and "openssl pkcs8 -topk8 -v2 aes-256-cbc".
+
+const testPassphrase = "hunter2"
+
+func readTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func readTestPublicKey(t *testing.T, name string) (PublicKey, string) {
+	t.Helper()
+	pub, comment, _, _, err := ParseAuthorizedKey(readTestFile(t, name+".pub"))
+	if err != nil {
+		t.Fatalf("%s.pub: %v", name, err)
+	}
+	return pub, comment
+}
+
+func readTestSigner(t *testing.T, name string) Signer {
+	t.Helper()
+	key, err := ParsePrivateKey(readTestFile(t, name))
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	signer, err := NewSigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestParseKeys(t *testing.T) {
+	for _, tt := range []struct {
+		name, pub, keyType, comment string
+	}{
+		{"ed25519", "ed25519", KeyAlgoED25519, "ed25519 test key"},
+		{"ecdsa256", "ecdsa256", KeyAlgoECDSA256, "p256 test key"},
+		{"ecdsa384", "ecdsa384", KeyAlgoECDSA384, "p384 test key"},
+		{"ecdsa521", "ecdsa521", KeyAlgoECDSA521, "p521 test key"},
+		{"rsa", "rsa", KeyAlgoRSA, "rsa test key"},
+		{"rsa_pkcs1", "rsa", KeyAlgoRSA, "rsa test key"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, comment := readTestPublicKey(t, tt.pub)
+			if pub.Type() != tt.keyType {
+				t.Errorf("got type %q, want %q", pub.Type(), tt.keyType)
+			}
+			if comment != tt.comment {
+				t.Errorf("got comment %q, want %q", comment, tt.comment)
+			}
+			line := readTestFile(t, tt.pub+".pub")
+			if got := MarshalAuthorizedKey(pub); !bytes.HasPrefix(line, bytes.TrimSuffix(got, []byte("\n"))) {
+				t.Errorf("MarshalAuthorizedKey = %q, want prefix of %q", got, line)
+			}
+			again, err := ParsePublicKey(pub.Marshal())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(again.Marshal(), pub.Marshal()) {
+				t.Errorf("public key did not round-trip")
+			}
+
+			signer := readTestSigner(t, tt.name)
+			if !bytes.Equal(signer.PublicKey().Marshal(), pub.Marshal()) {
+				t.Fatalf("private key does not match public key")
+			}
+			if !pub.CryptoPublicKey().(interface{ Equal(crypto.PublicKey) bool }).Equal(signer.PublicKey().CryptoPublicKey()) {
+				t.Errorf("CryptoPublicKey does not match")
+			}
+			algorithms := []string{""}
+			if tt.keyType == KeyAlgoRSA {
+				algorithms = append(algorithms, KeyAlgoRSASHA256, KeyAlgoRSASHA512)
+			}
+			for _, alg := range algorithms {
+				testSignVerify(t, signer, pub, alg)
+			}
+		})
+	}
+}
+
+func testSignVerify(t *testing.T, signer Signer, pub PublicKey, algorithm string) {
+	t.Helper()
+	data := []byte("sign me")
+	sig, err := signer.Sign(rand.Reader, data, algorithm)
+	if err != nil {
+		t.Fatalf("Sign(%q): %v", algorithm, err)
+	}
+	if algorithm != "" && sig.Format != algorithm {
+		t.Errorf("Sign(%q) returned a %q signature", algorithm, sig.Format)
+	}
+	if err := pub.Verify(data, sig); err != nil {
+		t.Errorf("Verify(%q): %v", algorithm, err)
+	}
+	if err := pub.Verify([]byte("sign me too"), sig); err == nil {
+		t.Errorf("Verify(%q) accepted the wrong data", algorithm)
+	}
+	sig2, err := ParseSignature(sig.Marshal())
+	if err != nil || sig2.Format != sig.Format || !bytes.Equal(sig2.Blob, sig.Blob) {
+		t.Errorf("signature did not round-trip: %v", err)
+	}
+}
+
+func TestEncryptedKeys(t *testing.T) {
+	for _, tt := range []struct {
+		name, pub string
+	}{
+		{"ed25519_encrypted", "ed25519_encrypted"},
+		{"ecdsa256_encrypted_gcm", "ecdsa256_encrypted_gcm"},
+		{"rsa_encrypted_cbc", "rsa_encrypted_cbc"},
+		{"ecdsa521_pkcs8_encrypted", "ecdsa521"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, _ := readTestPublicKey(t, tt.pub)
+			data := readTestFile(t, tt.name)
+
+			_, err := ParsePrivateKey(data)
+			var missing *PassphraseMissingError
+			if !errors.As(err, &missing) {
+				t.Fatalf("ParsePrivateKey: got %v, want PassphraseMissingError", err)
+			}
+			if missing.PublicKey != nil && !bytes.Equal(missing.PublicKey.Marshal(), pub.Marshal()) {
+				t.Errorf("PassphraseMissingError has the wrong public key")
+			}
+
+			key, err := ParsePrivateKeyWithPassphrase(data, []byte(testPassphrase))
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer, err := NewSigner(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(signer.PublicKey().Marshal(), pub.Marshal()) {
+				t.Errorf("private key does not match public key")
+			}
+
+			if _, err := ParsePrivateKeyWithPassphrase(data, []byte("hunter3")); !errors.Is(err, x509.IncorrectPasswordError) {
+				t.Errorf("wrong passphrase: got %v, want IncorrectPasswordError", err)
+			}
+		})
+	}
+}
+
+func testKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"p256": p256, "p521": p521, "ed25519": ed, "rsa": rsaKey}
+}
+
+func TestMarshalPrivateKey(t *testing.T) {
+	for name, key := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, passphrase := range []string{"", "pässphräse"} {
+				var block *pem.Block
+				var err error
+				if passphrase == "" {
+					block, err = MarshalPrivateKey(key, "comment "+name)
+				} else {
+					block, err = MarshalPrivateKeyWithPassphrase(key, "comment "+name, []byte(passphrase))
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				data := pem.EncodeToMemory(block)
+				var got crypto.Signer
+				if passphrase == "" {
+					got, err = ParsePrivateKey(data)
+				} else {
+					got, err = ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !got.(interface{ Equal(crypto.PrivateKey) bool }).Equal(key) {
+					t.Errorf("private key did not round-trip")
+				}
+			}
+		})
+	}
+}
+
+func TestParseAuthorizedKey(t *testing.T) {
+	ed, _ := readTestPublicKey(t, "ed25519")
+	ec, _ := readTestPublicKey(t, "ecdsa256")
+	edLine := strings.TrimSpace(string(MarshalAuthorizedKey(ed)))
+	ecLine := strings.TrimSpace(string(MarshalAuthorizedKey(ec)))
+	in := []byte("# comment\n\n" +
+		"ssh-ed25519 not-base64 invalid\n" +
+		`from="*.example,10.0.0.0/8",no-pty,command="echo \"a b\"" ` + edLine + " alice@laptop\n" +
+		"  " + ecLine + "\n")
+
+	pub, comment, options, rest, err := ParseAuthorizedKey(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub.Marshal(), ed.Marshal()) {
+		t.Errorf("got first key %s, want %s", MarshalAuthorizedKey(pub), edLine)
+	}
+	if comment != "alice@laptop" {
+		t.Errorf("got comment %q, want %q", comment, "alice@laptop")
+	}
+	wantOptions := []string{`from="*.example,10.0.0.0/8"`, "no-pty", `command="echo \"a b\""`}
+	if !slices.Equal(options, wantOptions) {
+		t.Errorf("got options %q, want %q", options, wantOptions)
+	}
+
+	pub, comment, options, rest, err = ParseAuthorizedKey(rest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pub.Marshal(), ec.Marshal()) || comment != "" || options != nil {
+		t.Errorf("got second key %s %q %q, want %s", MarshalAuthorizedKey(pub), comment, options, ecLine)
+	}
+	if _, _, _, _, err := ParseAuthorizedKey(rest); err == nil {
+		t.Errorf("found a third key")
+	}
+
+	// The key type must match the encoded key.
+	if _, _, _, _, err := ParseAuthorizedKey([]byte("ssh-rsa" + strings.TrimPrefix(edLine, "ssh-ed25519"))); err == nil {
+		t.Errorf("accepted mismatched key type")
+	}
+}
+
+func TestFingerprintSHA256(t *testing.T) {
+	// From ssh-keygen -l -f testdata/ed25519.pub.
+	pub, _ := readTestPublicKey(t, "ed25519")
+	if got, want := FingerprintSHA256(pub), "SHA256:QOEuKTkuf0xYlhkiA9dTxfpj10n65dQSNKcljPdDles"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestNewPublicKeyErrors(t *testing.T) {
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey := readTestSigner(t, "rsa").PublicKey().CryptoPublicKey().(*rsa.PublicKey)
+	for name, key := range map[string]crypto.PublicKey{
+		"P-224":         &p224.PublicKey,
+		"even exponent": &rsa.PublicKey{N: rsaKey.N, E: 65536},
+		"short Ed25519": ed25519.PublicKey(make([]byte, 31)),
+		"unsupported":   "key",
+	} {
+		if _, err := NewPublicKey(key); err == nil {
+			t.Errorf("%s: NewPublicKey succeeded", name)
+		}
+	}
+}
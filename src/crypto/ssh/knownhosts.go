@@ -0,0 +1,320 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// The format of known_hosts files is documented in sshd(8), in the
+// SSH_KNOWN_HOSTS FILE FORMAT section.
+
+// Markers of known_hosts lines.
+const (
+	markerCertAuthority = "cert-authority"
+	markerRevoked       = "revoked"
+)
+
+// ParseKnownHosts parses the first entry of in, which is in the format of an
+// OpenSSH known_hosts file. Empty lines and comment lines starting with '#'
+// are skipped.
+//
+// It returns the marker of the entry, which is "cert-authority", "revoked",
+// or empty; the host patterns, which may be hashed; the key; the comment; and
+// the rest of in after the line of the entry. If in has no entry, it returns
+// [io.EOF].
+func ParseKnownHosts(in []byte) (marker string, hosts []string, pubKey PublicKey, comment string, rest []byte, err error) {
+	for len(in) > 0 {
+		var line []byte
+		line, in, _ = bytes.Cut(in, []byte{'\n'})
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		marker, hosts, pubKey, comment, err = parseKnownHostsLine(line)
+		if err != nil {
+			return "", nil, nil, "", nil, err
+		}
+		return marker, hosts, pubKey, comment, in, nil
+	}
+	return "", nil, nil, "", nil, io.EOF
+}
+
+func parseKnownHostsLine(line []byte) (marker string, hosts []string, pubKey PublicKey, comment string, err error) {
+	if line[0] == '@' {
+		m, rest, _ := cutSpace(line[1:])
+		marker, line = string(m), rest
+		if marker != markerCertAuthority && marker != markerRevoked {
+			return "", nil, nil, "", errors.New("ssh: unknown known_hosts marker @" + marker)
+		}
+	}
+	h, keyLine, ok := cutSpace(line)
+	if !ok {
+		return "", nil, nil, "", errors.New("ssh: missing key in known_hosts entry")
+	}
+	pubKey, comment, err = parseKeyWithComment(keyLine)
+	if err != nil {
+		return "", nil, nil, "", err
+	}
+	return marker, strings.Split(string(h), ","), pubKey, comment, nil
+}
+
+// MarshalKnownHost returns a line of an OpenSSH known_hosts file, with a
+// trailing newline, for key and the hosts with the given addresses. An
+// address may be a host name, an IP address, or either with a port.
+func MarshalKnownHost(addresses []string, key PublicKey) []byte {
+	hosts := make([]string, len(addresses))
+	for i, a := range addresses {
+		hosts[i] = normalizeHost(a)
+	}
+	var b strings.Builder
+	b.WriteString(strings.Join(hosts, ","))
+	b.WriteByte(' ')
+	b.Write(MarshalAuthorizedKey(key))
+	return []byte(b.String())
+}
+
+// HashKnownHost returns the hashed form of the host pattern for address, as
+// written by "ssh-keygen -H", which hides the host from readers of the
+// known_hosts file.
+func HashKnownHost(address string) string {
+	salt := make([]byte, sha1.Size)
+	rand.Read(salt)
+	return encodeHashedHost(salt, hashHost(salt, normalizeHost(address)))
+}
+
+func hashHost(salt []byte, host string) []byte {
+	h := hmac.New(sha1.New, salt)
+	h.Write([]byte(host))
+	return h.Sum(nil)
+}
+
+func encodeHashedHost(salt, hash []byte) string {
+	return "|1|" + base64.StdEncoding.EncodeToString(salt) + "|" + base64.StdEncoding.EncodeToString(hash)
+}
+
+// normalizeHost returns the known_hosts host pattern for address, which is
+// the lowercase host name or IP address, enclosed in brackets and followed by
+// the port if it's not 22.
+func normalizeHost(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = strings.Trim(address, "[]"), "22"
+	}
+	host = strings.ToLower(host)
+	if port == "22" {
+		return host
+	}
+	return "[" + host + "]:" + port
+}
+
+// KnownHosts is a database of host keys and host certificate authorities,
+// parsed from OpenSSH known_hosts files.
+type KnownHosts struct {
+	entries []knownHostsEntry
+}
+
+type knownHostsEntry struct {
+	marker string
+	hosts  []string
+	key    PublicKey
+	line   int
+}
+
+// A KnownKey is a key of a [KnownHosts] database.
+type KnownKey struct {
+	Key PublicKey
+
+	// Line is the line number of the key in the known_hosts file, starting
+	// at 1.
+	Line int
+}
+
+// A KeyError is returned by [KnownHosts.Check] if the host key is not known.
+type KeyError struct {
+	// Want are the keys known for the host. If empty, the host is unknown;
+	// otherwise, the host presented a different key, which may indicate an
+	// attack.
+	Want []KnownKey
+}
+
+func (e *KeyError) Error() string {
+	if len(e.Want) == 0 {
+		return "ssh: host key is unknown"
+	}
+	return "ssh: host key mismatch"
+}
+
+// A RevokedError is returned by [KnownHosts.Check] if the host key, or the
+// authority of its certificate, is marked as revoked.
+type RevokedError struct {
+	Revoked KnownKey
+}
+
+func (e *RevokedError) Error() string {
+	return "ssh: key revoked on known_hosts line " + strconv.Itoa(e.Revoked.Line)
+}
+
+// NewKnownHosts parses a known_hosts file from r.
+func NewKnownHosts(r io.Reader) (*KnownHosts, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	db := new(KnownHosts)
+	for n := 1; len(data) > 0; n++ {
+		var line []byte
+		line, data, _ = bytes.Cut(data, []byte{'\n'})
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		marker, hosts, key, _, err := parseKnownHostsLine(line)
+		if err != nil {
+			return nil, errors.New("ssh: known_hosts line " + strconv.Itoa(n) + ": " + strings.TrimPrefix(err.Error(), "ssh: "))
+		}
+		db.entries = append(db.entries, knownHostsEntry{marker: marker, hosts: hosts, key: key, line: n})
+	}
+	return db, nil
+}
+
+// Check checks that key is a known host key for the host at address, which is
+// a host name or IP address, optionally followed by a port.
+//
+// If key is a [*Certificate], it must be a host certificate valid for the host
+// name, signed by a certificate authority for the host, marked as
+// "@cert-authority". Otherwise, key must be listed for the host.
+//
+// If the key is unknown, Check returns a [*KeyError]. If the key or its
+// authority is marked as "@revoked", it returns a [*RevokedError].
+func (db *KnownHosts) Check(address string, key PublicKey) error {
+	host := normalizeHost(address)
+	keys := []PublicKey{key}
+	cert, isCert := key.(*Certificate)
+	if isCert {
+		keys = append(keys, cert.Key, cert.SignatureKey)
+	}
+	for _, e := range db.entries {
+		if e.marker != markerRevoked {
+			continue
+		}
+		for _, k := range keys {
+			if k != nil && keysEqual(e.key, k) {
+				return &RevokedError{Revoked: KnownKey{Key: e.key, Line: e.line}}
+			}
+		}
+	}
+
+	if isCert {
+		hostname := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			hostname = h
+		}
+		if cert.CertType != HostCert {
+			return errors.New("ssh: certificate presented as host key is not a host certificate")
+		}
+		checker := &CertChecker{
+			IsHostAuthority: func(auth PublicKey, _ string) bool {
+				for _, e := range db.entries {
+					if e.marker == markerCertAuthority && keysEqual(e.key, auth) && matchHost(e.hosts, host) {
+						return true
+					}
+				}
+				return false
+			},
+		}
+		return checker.CheckCert(hostname, cert)
+	}
+
+	var want []KnownKey
+	for _, e := range db.entries {
+		if e.marker != "" || !matchHost(e.hosts, host) {
+			continue
+		}
+		if keysEqual(e.key, key) {
+			return nil
+		}
+		want = append(want, KnownKey{Key: e.key, Line: e.line})
+	}
+	return &KeyError{Want: want}
+}
+
+func keysEqual(a, b PublicKey) bool {
+	return bytes.Equal(a.Marshal(), b.Marshal())
+}
+
+// matchHost reports whether host matches the patterns of a known_hosts entry,
+// that is, whether it matches one of the patterns and none of the negated
+// patterns.
+func matchHost(patterns []string, host string) bool {
+	matched := false
+	for _, p := range patterns {
+		negated := strings.HasPrefix(p, "!")
+		if negated {
+			p = p[1:]
+		}
+		if !matchPattern(p, host) {
+			continue
+		}
+		if negated {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+func matchPattern(pattern, host string) bool {
+	if rest, ok := strings.CutPrefix(pattern, "|1|"); ok {
+		salt64, hash64, ok := strings.Cut(rest, "|")
+		if !ok {
+			return false
+		}
+		salt, err1 := base64.StdEncoding.DecodeString(salt64)
+		hash, err2 := base64.StdEncoding.DecodeString(hash64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		return hmac.Equal(hashHost(salt, host), hash)
+	}
+	return wildcardMatch(strings.ToLower(pattern), host)
+}
+
+// wildcardMatch reports whether s matches pattern, in which '*' matches any
+// sequence of characters and '?' matches any single character.
+func wildcardMatch(pattern, s string) bool {
+	// Backtrack to the last '*' on mismatch, which runs in O(len(pattern) *
+	// len(s)) time.
+	p, i := 0, 0
+	starP, starI := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			starP, starI = p, i
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case starP >= 0:
+			starI++
+			p, i = starP+1, starI
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
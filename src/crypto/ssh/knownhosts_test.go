@@ -0,0 +1,221 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"testing"
+)
+
+func readTestKnownHosts(t *testing.T) *KnownHosts {
+	t.Helper()
+	f, err := os.Open("testdata/known_hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	db, err := NewKnownHosts(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestKnownHostsCheck(t *testing.T) {
+	db := readTestKnownHosts(t)
+	for _, tt := range []struct {
+		address, key string
+		wantLines    []int // lines of KeyError.Want; nil if the check succeeds
+	}{
+		{"hashed.example", "ed25519", nil},
+		{"HASHED.example:22", "ed25519", nil},
+		{"192.0.2.1", "ed25519", nil},
+		{"hashed.example:2222", "ecdsa256", nil},
+		{"hashed.example:2222", "ed25519", []int{5}},
+		{"hashed.example", "rsa", []int{3}},
+		{"plain.example", "rsa", nil},
+		{"198.51.100.7:22", "rsa", nil},
+		{"plain.example:2222", "ecdsa521", nil},
+		{"plain.example:2222", "rsa", []int{8}},
+		{"plain.example:2200", "rsa", []int{}},
+		{"a.wild.example", "ecdsa384", nil},
+		{"a.b.wild.example:22", "ecdsa384", nil},
+		{"bad.wild.example", "ecdsa384", []int{}},
+		{"wild.example", "ecdsa384", []int{}},
+		{"unknown.example", "ed25519", []int{}},
+	} {
+		key, _ := readTestPublicKey(t, tt.key)
+		err := db.Check(tt.address, key)
+		if tt.wantLines == nil {
+			if err != nil {
+				t.Errorf("Check(%q, %s): %v", tt.address, tt.key, err)
+			}
+			continue
+		}
+		var keyErr *KeyError
+		if !errors.As(err, &keyErr) {
+			t.Errorf("Check(%q, %s) = %v, want *KeyError", tt.address, tt.key, err)
+			continue
+		}
+		var lines []int
+		for _, k := range keyErr.Want {
+			lines = append(lines, k.Line)
+		}
+		if !slices.Equal(lines, tt.wantLines) {
+			t.Errorf("Check(%q, %s): got Want lines %v, want %v", tt.address, tt.key, lines, tt.wantLines)
+		}
+	}
+}
+
+func TestKnownHostsRevoked(t *testing.T) {
+	db := readTestKnownHosts(t)
+	key, _ := readTestPublicKey(t, "ecdsa256_encrypted_gcm")
+	err := db.Check("plain.example", key)
+	var revokedErr *RevokedError
+	if !errors.As(err, &revokedErr) {
+		t.Fatalf("Check = %v, want *RevokedError", err)
+	}
+	if revokedErr.Revoked.Line != 11 || !keysEqual(revokedErr.Revoked.Key, key) {
+		t.Errorf("got revoked key on line %d", revokedErr.Revoked.Line)
+	}
+}
+
+func TestKnownHostsCert(t *testing.T) {
+	db := readTestKnownHosts(t)
+	cert := readTestCert(t, "ecdsa256-cert")
+	if err := db.Check("host.example:22", cert); err != nil {
+		t.Errorf("Check(host.example): %v", err)
+	}
+	// As in OpenSSH, patterns without a port only match port 22.
+	if err := db.Check("host.example:2222", cert); err == nil {
+		t.Errorf("Check(host.example:2222) succeeded")
+	}
+	// 10.0.0.1 is a principal of the certificate, but the authority is only
+	// trusted for *.example.
+	if err := db.Check("10.0.0.1", cert); err == nil {
+		t.Errorf("Check(10.0.0.1) succeeded")
+	}
+	// other.example is trusted, but not a principal.
+	if err := db.Check("other.example", cert); err == nil {
+		t.Errorf("Check(other.example) succeeded")
+	}
+	// A user certificate can't be used as a host key.
+	if err := db.Check("host.example", readTestCert(t, "ed25519-cert")); err == nil {
+		t.Errorf("Check accepted a user certificate")
+	}
+}
+
+func TestParseKnownHosts(t *testing.T) {
+	rest := readTestFile(t, "known_hosts")
+	type entry struct {
+		marker string
+		hosts  []string
+	}
+	var got []entry
+	for {
+		marker, hosts, _, _, r, err := ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, entry{marker, hosts})
+		rest = r
+	}
+	if len(got) != 8 {
+		t.Fatalf("got %d entries, want 8", len(got))
+	}
+	for i, want := range map[int]entry{
+		3: {"", []string{"plain.example", "198.51.100.7"}},
+		5: {"", []string{"*.wild.example", "!bad.wild.example"}},
+		6: {"cert-authority", []string{"*.example"}},
+		7: {"revoked", []string{"*"}},
+	} {
+		if got[i].marker != want.marker || !slices.Equal(got[i].hosts, want.hosts) {
+			t.Errorf("entry %d: got %q %q, want %q %q", i, got[i].marker, got[i].hosts, want.marker, want.hosts)
+		}
+	}
+
+	_, _, _, comment, _, err := ParseKnownHosts([]byte("\n# comment\nexample.com " + string(readTestFile(t, "rsa.pub"))))
+	if err != nil || comment != "rsa test key" {
+		t.Errorf("got comment %q, err %v", comment, err)
+	}
+	if _, _, _, _, _, err := ParseKnownHosts([]byte("@bogus example.com " + string(readTestFile(t, "rsa.pub")))); err == nil {
+		t.Errorf("unknown marker accepted")
+	}
+	if _, err := NewKnownHosts(bytes.NewReader([]byte("example.com\n"))); err == nil {
+		t.Errorf("entry without key accepted")
+	}
+}
+
+func TestMarshalKnownHost(t *testing.T) {
+	key, _ := readTestPublicKey(t, "ed25519")
+	line := MarshalKnownHost([]string{"Example.COM", "192.0.2.1:22", "[2001:db8::1]:2222"}, key)
+	want := "example.com,192.0.2.1,[2001:db8::1]:2222 " + string(MarshalAuthorizedKey(key))
+	if string(line) != want {
+		t.Errorf("MarshalKnownHost = %q, want %q", line, want)
+	}
+	hashed := HashKnownHost("Example.com:2222")
+	line = append([]byte(hashed+" "), MarshalAuthorizedKey(key)...)
+	db, err := NewKnownHosts(bytes.NewReader(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Check("example.com:2222", key); err != nil {
+		t.Errorf("Check(example.com:2222): %v", err)
+	}
+	if err := db.Check("example.com", key); err == nil {
+		t.Errorf("Check(example.com) succeeded")
+	}
+	if HashKnownHost("example.com") == HashKnownHost("example.com") {
+		t.Errorf("HashKnownHost is not salted")
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	for in, want := range map[string]string{
+		"example.com":        "example.com",
+		"Example.com:22":     "example.com",
+		"example.com:2222":   "[example.com]:2222",
+		"192.0.2.1":          "192.0.2.1",
+		"[192.0.2.1]:22":     "192.0.2.1",
+		"2001:db8::1":        "2001:db8::1",
+		"[2001:DB8::1]":      "2001:db8::1",
+		"[2001:db8::1]:2222": "[2001:db8::1]:2222",
+	} {
+		if got := normalizeHost(in); got != want {
+			t.Errorf("normalizeHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"*", "abc", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abcd", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*.example", "a.example", true},
+		{"*.example", "example", false},
+		{"*b*b*", "abcbd", true},
+		{"192.0.2.?", "192.0.2.10", false},
+		{"", "", true},
+		{"", "a", false},
+	} {
+		if got := wildcardMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
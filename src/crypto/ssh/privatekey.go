@@ -0,0 +1,339 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/ssh/internal/bcryptpbkdf"
+	"crypto/ssh/internal/wire"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"slices"
+)
+
+// The format of OpenSSH private keys is specified in the OpenSSH PROTOCOL.key
+// document.
+
+const opensshMagic = "openssh-key-v1\x00"
+
+// opensshDefaultRounds is the number of bcrypt_pbkdf rounds used by
+// ssh-keygen by default.
+const opensshDefaultRounds = 16
+
+// A PassphraseMissingError is returned by [ParsePrivateKey] for encrypted
+// private keys.
+type PassphraseMissingError struct {
+	// PublicKey is the public key of the encrypted private key, if it is
+	// stored unencrypted, as in the OpenSSH format. Otherwise, it is nil.
+	PublicKey PublicKey
+}
+
+func (*PassphraseMissingError) Error() string {
+	return "ssh: this private key is passphrase protected"
+}
+
+// ParsePrivateKey parses a PEM-encoded private key in the OpenSSH format, or
+// in one of the PKCS #1, PKCS #8 or SEC 1 formats also supported by OpenSSH.
+// It returns an *rsa.PrivateKey, an *ecdsa.PrivateKey, or an
+// ed25519.PrivateKey.
+//
+// If the key is encrypted, ParsePrivateKey returns a
+// [*PassphraseMissingError], and [ParsePrivateKeyWithPassphrase] must be used
+// instead.
+func ParsePrivateKey(pemBytes []byte) (crypto.Signer, error) {
+	return parsePrivateKey(pemBytes, nil)
+}
+
+// ParsePrivateKeyWithPassphrase is like [ParsePrivateKey], but decrypts the
+// private key with passphrase. Encrypted private keys in the OpenSSH format
+// and in the PKCS #8 format, as parsed by
+// [x509.ParsePKCS8EncryptedPrivateKey], are supported.
+//
+// If the passphrase is incorrect, it returns an error that wraps
+// [x509.IncorrectPasswordError].
+func ParsePrivateKeyWithPassphrase(pemBytes, passphrase []byte) (crypto.Signer, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("ssh: empty passphrase")
+	}
+	return parsePrivateKey(pemBytes, passphrase)
+}
+
+func parsePrivateKey(pemBytes, passphrase []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("ssh: no key found")
+	}
+	if _, ok := block.Headers["Proc-Type"]; ok {
+		return nil, errors.New("ssh: legacy PEM encryption is not supported")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		return parseOpenSSHPrivateKey(block.Bytes, passphrase)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "ENCRYPTED PRIVATE KEY":
+		if passphrase == nil {
+			return nil, &PassphraseMissingError{}
+		}
+		key, err = x509.ParsePKCS8EncryptedPrivateKey(block.Bytes, string(passphrase))
+	default:
+		return nil, errors.New("ssh: unsupported key type " + block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("ssh: unsupported private key type")
+	}
+	if _, err := NewPublicKey(signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// opensshCipher describes a cipher supported for encrypting OpenSSH private
+// keys.
+type opensshCipher struct {
+	keyLen, ivLen int
+	mode          string // "ctr", "cbc", or "gcm"
+}
+
+var opensshCiphers = map[string]opensshCipher{
+	"aes128-ctr":             {16, aes.BlockSize, "ctr"},
+	"aes192-ctr":             {24, aes.BlockSize, "ctr"},
+	"aes256-ctr":             {32, aes.BlockSize, "ctr"},
+	"aes128-cbc":             {16, aes.BlockSize, "cbc"},
+	"aes192-cbc":             {24, aes.BlockSize, "cbc"},
+	"aes256-cbc":             {32, aes.BlockSize, "cbc"},
+	"aes128-gcm@openssh.com": {16, 12, "gcm"},
+	"aes256-gcm@openssh.com": {32, 12, "gcm"},
+}
+
+var errInvalidOpenSSHKey = errors.New("ssh: invalid OpenSSH private key")
+
+func parseOpenSSHPrivateKey(data, passphrase []byte) (crypto.Signer, error) {
+	in, ok := bytes.CutPrefix(data, []byte(opensshMagic))
+	if !ok {
+		return nil, errors.New("ssh: invalid OpenSSH private key magic")
+	}
+	var cipherName, kdfName, kdfOptions, pubKey, encrypted []byte
+	var n uint32
+	if cipherName, in, ok = wire.ParseString(in); !ok {
+		return nil, errInvalidOpenSSHKey
+	}
+	if kdfName, in, ok = wire.ParseString(in); !ok {
+		return nil, errInvalidOpenSSHKey
+	}
+	if kdfOptions, in, ok = wire.ParseString(in); !ok {
+		return nil, errInvalidOpenSSHKey
+	}
+	if n, in, ok = wire.ParseUint32(in); !ok {
+		return nil, errInvalidOpenSSHKey
+	}
+	if n != 1 {
+		return nil, errors.New("ssh: multi-key files are not supported")
+	}
+	if pubKey, in, ok = wire.ParseString(in); !ok {
+		return nil, errInvalidOpenSSHKey
+	}
+	if encrypted, in, ok = wire.ParseString(in); !ok {
+		return nil, errInvalidOpenSSHKey
+	}
+	pub, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext []byte
+	blockSize := 8
+	if string(cipherName) == "none" {
+		if string(kdfName) != "none" || len(in) != 0 {
+			return nil, errInvalidOpenSSHKey
+		}
+		plaintext = encrypted
+	} else {
+		if passphrase == nil {
+			return nil, &PassphraseMissingError{PublicKey: pub}
+		}
+		c, ok := opensshCiphers[string(cipherName)]
+		if !ok {
+			return nil, errors.New("ssh: unsupported private key cipher " + string(cipherName))
+		}
+		if string(kdfName) != "bcrypt" {
+			return nil, errors.New("ssh: unsupported private key KDF " + string(kdfName))
+		}
+		salt, opts, ok := wire.ParseString(kdfOptions)
+		if !ok {
+			return nil, errInvalidOpenSSHKey
+		}
+		rounds, opts, ok := wire.ParseUint32(opts)
+		if !ok || len(opts) != 0 {
+			return nil, errInvalidOpenSSHKey
+		}
+		// Bound the work done for untrusted inputs. ssh-keygen uses 16
+		// rounds by default.
+		if rounds > 1<<16 {
+			return nil, errors.New("ssh: too many bcrypt_pbkdf rounds")
+		}
+		k, err := bcryptpbkdf.Key(passphrase, salt, int(rounds), c.keyLen+c.ivLen)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(k[:c.keyLen])
+		if err != nil {
+			return nil, err
+		}
+		iv := k[c.keyLen:]
+		blockSize = aes.BlockSize
+		switch c.mode {
+		case "ctr":
+			if len(in) != 0 {
+				return nil, errInvalidOpenSSHKey
+			}
+			plaintext = make([]byte, len(encrypted))
+			cipher.NewCTR(block, iv).XORKeyStream(plaintext, encrypted)
+		case "cbc":
+			if len(in) != 0 || len(encrypted)%aes.BlockSize != 0 {
+				return nil, errInvalidOpenSSHKey
+			}
+			plaintext = make([]byte, len(encrypted))
+			cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, encrypted)
+		case "gcm":
+			// The tag follows the encrypted section, outside of its length.
+			gcm, err := cipher.NewGCM(block)
+			if err != nil {
+				return nil, err
+			}
+			if len(in) != gcm.Overhead() {
+				return nil, errInvalidOpenSSHKey
+			}
+			plaintext, err = gcm.Open(nil, iv, append(encrypted, in...), nil)
+			if err != nil {
+				return nil, x509.IncorrectPasswordError
+			}
+		}
+	}
+
+	// The check values are equal if the decryption is successful.
+	check1, rest, ok := wire.ParseUint32(plaintext)
+	if !ok {
+		return nil, errInvalidOpenSSHKey
+	}
+	check2, rest, ok := wire.ParseUint32(rest)
+	if !ok || check1 != check2 {
+		return nil, x509.IncorrectPasswordError
+	}
+	key, rest, err := wire.ParsePrivateKey(rest)
+	if err != nil {
+		return nil, err
+	}
+	if _, rest, ok = wire.ParseString(rest); !ok { // comment
+		return nil, errInvalidOpenSSHKey
+	}
+	if len(rest) >= blockSize {
+		return nil, errInvalidOpenSSHKey
+	}
+	for i, b := range rest {
+		if b != byte(i+1) {
+			return nil, errors.New("ssh: invalid OpenSSH private key padding")
+		}
+	}
+
+	keyPub, err := NewPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(keyPub.Marshal(), pub.Marshal()) {
+		return nil, errors.New("ssh: OpenSSH private key does not match its public key")
+	}
+	return key, nil
+}
+
+// MarshalPrivateKey returns key, with the given comment, as an unencrypted
+// private key in the OpenSSH format, suitable for [pem.Encode]. key must be
+// an *rsa.PrivateKey, an *ecdsa.PrivateKey, or an ed25519.PrivateKey.
+func MarshalPrivateKey(key crypto.PrivateKey, comment string) (*pem.Block, error) {
+	return marshalOpenSSHPrivateKey(key, comment, nil)
+}
+
+// MarshalPrivateKeyWithPassphrase is like [MarshalPrivateKey], but encrypts
+// the private key with passphrase, using AES-256 in CTR mode and
+// bcrypt_pbkdf, as ssh-keygen does by default.
+func MarshalPrivateKeyWithPassphrase(key crypto.PrivateKey, comment string, passphrase []byte) (*pem.Block, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("ssh: empty passphrase")
+	}
+	return marshalOpenSSHPrivateKey(key, comment, passphrase)
+}
+
+func marshalOpenSSHPrivateKey(key crypto.PrivateKey, comment string, passphrase []byte) (*pem.Block, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("ssh: unsupported private key type")
+	}
+	pub, err := NewPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	var check [4]byte
+	rand.Read(check[:])
+	plaintext := slices.Concat(check[:], check[:])
+	plaintext, err = wire.AppendPrivateKey(plaintext, key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext = wire.AppendString(plaintext, comment)
+
+	cipherName, kdfName, kdfOptions := "none", "none", []byte(nil)
+	blockSize := 8
+	if passphrase != nil {
+		cipherName, kdfName = "aes256-ctr", "bcrypt"
+		blockSize = aes.BlockSize
+	}
+	for i := 1; len(plaintext)%blockSize != 0; i++ {
+		plaintext = append(plaintext, byte(i))
+	}
+
+	encrypted := plaintext
+	if passphrase != nil {
+		salt := make([]byte, 16)
+		rand.Read(salt)
+		kdfOptions = wire.AppendString(nil, salt)
+		kdfOptions = wire.AppendUint32(kdfOptions, opensshDefaultRounds)
+		c := opensshCiphers[cipherName]
+		k, err := bcryptpbkdf.Key(passphrase, salt, opensshDefaultRounds, c.keyLen+c.ivLen)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(k[:c.keyLen])
+		if err != nil {
+			return nil, err
+		}
+		cipher.NewCTR(block, k[c.keyLen:]).XORKeyStream(encrypted, plaintext)
+	}
+
+	b := []byte(opensshMagic)
+	b = wire.AppendString(b, cipherName)
+	b = wire.AppendString(b, kdfName)
+	b = wire.AppendString(b, kdfOptions)
+	b = wire.AppendUint32(b, 1)
+	b = wire.AppendString(b, pub.Marshal())
+	b = wire.AppendString(b, encrypted)
+	return &pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: b}, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ssh/internal/wire"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// A Signer can create SSH signatures with a private key.
+type Signer interface {
+	// PublicKey returns the public key, or certificate, of the signer.
+	PublicKey() PublicKey
+
+	// Sign returns a signature of data with the given signature algorithm,
+	// such as [KeyAlgoRSASHA256]. If algorithm is empty, the default
+	// algorithm for the key type is used, which for RSA keys is
+	// [KeyAlgoRSASHA512].
+	Sign(rand io.Reader, data []byte, algorithm string) (*Signature, error)
+}
+
+// NewSigner returns a Signer for key, whose public key must be an
+// *rsa.PublicKey, an *ecdsa.PublicKey on P-256, P-384 or P-521, or an
+// ed25519.PublicKey.
+//
+// key may be a hardware-backed or remote key, as long as it supports the
+// hashes used by SSH: SHA-1, SHA-256 and SHA-512 for RSA keys, SHA-256,
+// SHA-384 or SHA-512 for ECDSA keys depending on the curve, and none for
+// Ed25519 keys.
+func NewSigner(key crypto.Signer) (Signer, error) {
+	pub, err := NewPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &signer{key: key, pub: pub}, nil
+}
+
+type signer struct {
+	key crypto.Signer
+	pub PublicKey
+}
+
+func (s *signer) PublicKey() PublicKey { return s.pub }
+
+func (s *signer) Sign(rand io.Reader, data []byte, algorithm string) (*Signature, error) {
+	return sign(s.key, s.pub, rand, data, algorithm)
+}
+
+func sign(key crypto.Signer, pub PublicKey, rand io.Reader, data []byte, algorithm string) (*Signature, error) {
+	switch pub := pub.(type) {
+	case *rsaPublicKey:
+		var h crypto.Hash
+		switch algorithm {
+		case KeyAlgoRSA:
+			h = crypto.SHA1
+		case KeyAlgoRSASHA256:
+			h = crypto.SHA256
+		case "", KeyAlgoRSASHA512:
+			algorithm, h = KeyAlgoRSASHA512, crypto.SHA512
+		default:
+			return nil, errors.New("ssh: unsupported signature algorithm " + algorithm + " for key type " + KeyAlgoRSA)
+		}
+		sig, err := key.Sign(rand, hashData(h, data), h)
+		if err != nil {
+			return nil, err
+		}
+		return &Signature{Format: algorithm, Blob: sig}, nil
+
+	case *ecdsaPublicKey:
+		if algorithm != "" && algorithm != pub.Type() {
+			return nil, errors.New("ssh: unsupported signature algorithm " + algorithm + " for key type " + pub.Type())
+		}
+		h := ecdsaHash(pub.Curve)
+		der, err := key.Sign(rand, hashData(h, data), h)
+		if err != nil {
+			return nil, err
+		}
+		// Convert the ASN.1 signature to the two integers r and s.
+		var r, s []byte
+		input := cryptobyte.String(der)
+		var inner cryptobyte.String
+		if !input.ReadASN1(&inner, asn1.SEQUENCE) || !input.Empty() ||
+			!inner.ReadASN1Integer(&r) || !inner.ReadASN1Integer(&s) || !inner.Empty() {
+			return nil, errors.New("ssh: invalid ECDSA signature from crypto.Signer")
+		}
+		blob := wire.AppendMPIntBytes(nil, r)
+		blob = wire.AppendMPIntBytes(blob, s)
+		return &Signature{Format: pub.Type(), Blob: blob}, nil
+
+	case ed25519PublicKey:
+		if algorithm != "" && algorithm != KeyAlgoED25519 {
+			return nil, errors.New("ssh: unsupported signature algorithm " + algorithm + " for key type " + KeyAlgoED25519)
+		}
+		sig, err := key.Sign(rand, data, crypto.Hash(0))
+		if err != nil {
+			return nil, err
+		}
+		return &Signature{Format: KeyAlgoED25519, Blob: sig}, nil
+	}
+	return nil, errors.New("ssh: unsupported key type")
+}
+
+// NewCertSigner returns a Signer that signs with signer and presents cert as
+// its public key. cert must certify the public key of signer.
+func NewCertSigner(cert *Certificate, signer Signer) (Signer, error) {
+	if !bytes.Equal(cert.Key.Marshal(), signer.PublicKey().Marshal()) {
+		return nil, errors.New("ssh: certificate does not match the signer's public key")
+	}
+	return &certSigner{cert: cert, signer: signer}, nil
+}
+
+type certSigner struct {
+	cert   *Certificate
+	signer Signer
+}
+
+func (s *certSigner) PublicKey() PublicKey { return s.cert }
+
+func (s *certSigner) Sign(rand io.Reader, data []byte, algorithm string) (*Signature, error) {
+	return s.signer.Sign(rand, data, algorithm)
+}
@@ -10,6 +10,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/mldsa"
 	"crypto/rsa"
 	"errors"
 	"fmt"
@@ -38,6 +39,14 @@ func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc c
 		if !ed25519.Verify(pubKey, signed, sig) {
 			return errors.New("Ed25519 verification failure")
 		}
+	case signatureMLDSA:
+		pubKey, ok := pubkey.(*mldsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("expected an ML-DSA public key, got %T", pubkey)
+		}
+		if err := mldsa.Verify(pubKey, signed, sig, nil); err != nil {
+			return errors.New("ML-DSA verification failure")
+		}
 	case signaturePKCS1v15:
 		pubKey, ok := pubkey.(*rsa.PublicKey)
 		if !ok {
@@ -106,6 +115,8 @@ func typeAndHashFromSignatureScheme(signatureAlgorithm SignatureScheme) (sigType
 		sigType = signatureECDSA
 	case Ed25519:
 		sigType = signatureEd25519
+	case MLDSA44, MLDSA65, MLDSA87:
+		sigType = signatureMLDSA
 	default:
 		return 0, 0, fmt.Errorf("unsupported signature algorithm: %v", signatureAlgorithm)
 	}
@@ -118,7 +129,7 @@ func typeAndHashFromSignatureScheme(signatureAlgorithm SignatureScheme) (sigType
 		hash = crypto.SHA384
 	case PKCS1WithSHA512, PSSWithSHA512, ECDSAWithP521AndSHA512:
 		hash = crypto.SHA512
-	case Ed25519:
+	case Ed25519, MLDSA44, MLDSA65, MLDSA87:
 		hash = directSigning
 	default:
 		return 0, 0, fmt.Errorf("unsupported signature algorithm: %v", signatureAlgorithm)
@@ -126,6 +137,16 @@ func typeAndHashFromSignatureScheme(signatureAlgorithm SignatureScheme) (sigType
 	return sigType, hash, nil
 }
 
+// isTLS13OnlySignatureScheme reports whether sigAlg is only defined for
+// TLS 1.3, and must not be negotiated or accepted in TLS 1.2.
+func isTLS13OnlySignatureScheme(sigAlg SignatureScheme) bool {
+	switch sigAlg {
+	case MLDSA44, MLDSA65, MLDSA87:
+		return true
+	}
+	return false
+}
+
 // legacyTypeAndHashFromPublicKey returns the fixed signature type and crypto.Hash for
 // a given public key used with TLS 1.0 and 1.1, before the introduction of
 // signature algorithm negotiation.
@@ -141,6 +162,8 @@ func legacyTypeAndHashFromPublicKey(pub crypto.PublicKey) (sigType uint8, hash c
 		// full signature, and not even OpenSSL bothers with the
 		// complexity, so we can't even test it properly.
 		return 0, 0, fmt.Errorf("tls: Ed25519 public keys are not supported before TLS 1.2")
+	case *mldsa.PublicKey:
+		return 0, 0, fmt.Errorf("tls: ML-DSA public keys are not supported before TLS 1.3")
 	default:
 		return 0, 0, fmt.Errorf("tls: unsupported public key: %T", pub)
 	}
@@ -208,6 +231,22 @@ func signatureSchemesForCertificate(version uint16, cert *Certificate) []Signatu
 		}
 	case ed25519.PublicKey:
 		sigAlgs = []SignatureScheme{Ed25519}
+	case *mldsa.PublicKey:
+		// ML-DSA is only defined for TLS 1.3, and each signature scheme is
+		// bound to a single parameter set.
+		if version != VersionTLS13 {
+			return nil
+		}
+		switch pub.Parameters() {
+		case mldsa.MLDSA44():
+			sigAlgs = []SignatureScheme{MLDSA44}
+		case mldsa.MLDSA65():
+			sigAlgs = []SignatureScheme{MLDSA65}
+		case mldsa.MLDSA87():
+			sigAlgs = []SignatureScheme{MLDSA87}
+		default:
+			return nil
+		}
 	default:
 		return nil
 	}
@@ -284,7 +323,7 @@ func unsupportedCertificateError(cert *Certificate) error {
 		}
 	case *rsa.PublicKey:
 		return fmt.Errorf("tls: certificate RSA key size too small for supported signature algorithms")
-	case ed25519.PublicKey:
+	case ed25519.PublicKey, *mldsa.PublicKey:
 	default:
 		return fmt.Errorf("tls: unsupported certificate key (%T)", pub)
 	}
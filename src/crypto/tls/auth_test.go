@@ -181,7 +181,7 @@ func TestSupportedSignatureAlgorithms(t *testing.T) {
 		if sigType == 0 {
 			t.Errorf("%v: missing signature type", sigAlg)
 		}
-		if hash == 0 && sigAlg != Ed25519 {
+		if hash == 0 && sigAlg != Ed25519 && sigType != signatureMLDSA {
 			t.Errorf("%v: missing hash", sigAlg)
 		}
 	}
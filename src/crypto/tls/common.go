@@ -12,6 +12,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/mldsa"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha512"
@@ -114,6 +115,8 @@ const (
 	extensionSignatureAlgorithms     uint16 = 13
 	extensionALPN                    uint16 = 16
 	extensionSCT                     uint16 = 18
+	extensionClientCertificateType   uint16 = 19
+	extensionServerCertificateType   uint16 = 20
 	extensionExtendedMasterSecret    uint16 = 23
 	extensionSessionTicket           uint16 = 35
 	extensionPreSharedKey            uint16 = 41
@@ -203,11 +206,12 @@ const (
 	signatureRSAPSS
 	signatureECDSA
 	signatureEd25519
+	signatureMLDSA
 )
 
 // directSigning is a standard Hash value that signals that no pre-hashing
 // should be performed, and that the input should be signed directly. It is the
-// hash function associated with the Ed25519 signature scheme.
+// hash function associated with the Ed25519 and ML-DSA signature schemes.
 var directSigning crypto.Hash = 0
 
 // helloRetryRequestRandom is set as the Random value of a ServerHello
@@ -268,8 +272,9 @@ type ConnectionState struct {
 	// order in which they were sent. The first element is the leaf certificate
 	// that the connection is verified against.
 	//
-	// On the client side, it can't be empty. On the server side, it can be
-	// empty if Config.ClientAuth is not RequireAnyClientCert or
+	// On the client side, it can't be empty, unless the server authenticated
+	// with a raw public key or an external PSK was used. On the server side, it
+	// can be empty if Config.ClientAuth is not RequireAnyClientCert or
 	// RequireAndVerifyClientCert.
 	//
 	// PeerCertificates and its contents should not be modified.
@@ -286,6 +291,11 @@ type ConnectionState struct {
 	// VerifiedChains and its contents should not be modified.
 	VerifiedChains [][]*x509.Certificate
 
+	// PeerRawPublicKey is the public key sent by the peer in place of a
+	// certificate chain, if raw public keys were negotiated for the peer (see
+	// Config.ServerCertificateTypes). PeerCertificates is empty in that case.
+	PeerRawPublicKey crypto.PublicKey
+
 	// SignedCertificateTimestamps is a list of SCTs provided by the peer
 	// through the TLS handshake for the leaf certificate, if any.
 	SignedCertificateTimestamps [][]byte
@@ -304,6 +314,12 @@ type ConnectionState struct {
 	// client side.
 	ECHAccepted bool
 
+	// ExternalPSKIdentity is the identity of the external PSK that
+	// authenticated the connection, if any (see Config.ExternalPSKs). For
+	// imported PSKs, it is the identity of the external PSK, not the imported
+	// identity sent on the wire.
+	ExternalPSKIdentity []byte
+
 	// ekm is a closure exposed via ExportKeyingMaterial.
 	ekm func(label string, context []byte, length int) ([]byte, error)
 
@@ -407,6 +423,12 @@ const (
 	// EdDSA algorithms.
 	Ed25519 SignatureScheme = 0x0807
 
+	// ML-DSA algorithms, as specified in draft-ietf-tls-mldsa. Only supported
+	// in TLS 1.3.
+	MLDSA44 SignatureScheme = 0x0904
+	MLDSA65 SignatureScheme = 0x0905
+	MLDSA87 SignatureScheme = 0x0906
+
 	// Legacy signature and hash algorithms for TLS 1.2.
 	PKCS1WithSHA1 SignatureScheme = 0x0201
 	ECDSAWithSHA1 SignatureScheme = 0x0203
@@ -677,6 +699,67 @@ type Config struct {
 	// by the policy in ClientAuth.
 	ClientCAs *x509.CertPool
 
+	// Revocation, if not nil, enables revocation checking of the peer's
+	// certificate chains during normal certificate verification, using the
+	// CRLs, OCSP responses and OCSP responder it provides. An OCSP response
+	// stapled by the peer is checked in addition to Revocation.OCSPResponses.
+	// It has no effect if the peer's certificates are not verified.
+	Revocation *x509.RevocationOptions
+
+	// CertificateTransparency, if not nil, requires the peer's certificate
+	// to be accompanied by enough valid Signed Certificate Timestamps during
+	// normal certificate verification. SCTs embedded in the certificate,
+	// provided in the TLS extension, and included in a stapled OCSP response
+	// are all considered. It has no effect if the peer's certificates are
+	// not verified.
+	CertificateTransparency *x509.CTOptions
+
+	// ServerCertificateTypes is the list of formats in which the server may
+	// authenticate, in order of preference. On the client, it is the list of
+	// types accepted from the server; on the server, the list of types it is
+	// willing to send. If empty, only CertificateTypeX509 is used.
+	//
+	// CertificateTypeRawPublicKey can only be negotiated in TLS 1.3. With it,
+	// the server sends the public key of the PrivateKey of its selected
+	// Certificate, whose Certificate chain may then be empty, and the client
+	// authenticates it with VerifyRawPublicKey. See RFC 7250.
+	ServerCertificateTypes []CertificateType
+
+	// ClientCertificateTypes is like ServerCertificateTypes, but for client
+	// certificates. On the server, it is the list of types accepted from the
+	// client; on the client, the list of types it is willing to send.
+	ClientCertificateTypes []CertificateType
+
+	// VerifyRawPublicKey, if not nil, is called to authenticate a raw public
+	// key sent by the peer in place of a certificate chain. If it returns a
+	// non-nil error, the handshake is aborted and that error results.
+	//
+	// Raw public keys are not verified against RootCAs or ClientCAs, and
+	// VerifyPeerCertificate is not called for them. If VerifyRawPublicKey is
+	// nil, raw public keys are only accepted where certificates would not be
+	// verified, that is on clients with InsecureSkipVerify set and on servers
+	// with ClientAuth below VerifyClientCertIfGiven.
+	VerifyRawPublicKey func(key crypto.PublicKey) error
+
+	// ExternalPSKs are pre-shared keys provisioned out of band, used to
+	// authenticate TLS 1.3 connections instead of certificates. See RFC 9257.
+	//
+	// A client offers all of them and requires the server to select one,
+	// failing the handshake otherwise. A server selects the first PSK offered
+	// by the client that matches one of ExternalPSKs or is returned by
+	// GetExternalPSK, and otherwise falls back to certificates.
+	//
+	// When an external PSK is used, certificates are not exchanged in either
+	// direction, a fresh (EC)DHE key exchange is always performed, and the
+	// session can't be resumed.
+	ExternalPSKs []ExternalPSK
+
+	// GetExternalPSK, if not nil, is called by a server for PSK identities
+	// offered by the client that don't match ExternalPSKs. For imported PSKs,
+	// identity is the external identity the PSK was imported from. It must
+	// return nil if identity is unknown.
+	GetExternalPSK func(identity []byte) (*ExternalPSK, error)
+
 	// InsecureSkipVerify controls whether a client verifies the server's
 	// certificate chain and host name. If InsecureSkipVerify is true, crypto/tls
 	// accepts any certificate presented by the server and any host name in that
@@ -960,6 +1043,13 @@ func (c *Config) Clone() *Config {
 		ServerName:                          c.ServerName,
 		ClientAuth:                          c.ClientAuth,
 		ClientCAs:                           c.ClientCAs,
+		Revocation:                          c.Revocation,
+		CertificateTransparency:             c.CertificateTransparency,
+		ServerCertificateTypes:              c.ServerCertificateTypes,
+		ClientCertificateTypes:              c.ClientCertificateTypes,
+		VerifyRawPublicKey:                  c.VerifyRawPublicKey,
+		ExternalPSKs:                        c.ExternalPSKs,
+		GetExternalPSK:                      c.GetExternalPSK,
 		InsecureSkipVerify:                  c.InsecureSkipVerify,
 		CipherSuites:                        c.CipherSuites,
 		PreferServerCipherSuites:            c.PreferServerCipherSuites,
@@ -1125,6 +1215,31 @@ func (c *Config) time() time.Time {
 	return t()
 }
 
+// revocation returns the revocation options to verify a peer that stapled
+// ocspResponse with, or nil if revocation checking is disabled.
+func (c *Config) revocation(ocspResponse []byte) *x509.RevocationOptions {
+	if c.Revocation == nil || len(ocspResponse) == 0 {
+		return c.Revocation
+	}
+	opts := *c.Revocation
+	opts.OCSPResponses = append([][]byte{ocspResponse}, c.Revocation.OCSPResponses...)
+	return &opts
+}
+
+// certificateTransparency returns the CT options to verify a peer that sent
+// scts and stapled ocspResponse with, or nil if CT checking is disabled.
+func (c *Config) certificateTransparency(scts [][]byte, ocspResponse []byte) *x509.CTOptions {
+	if c.CertificateTransparency == nil {
+		return nil
+	}
+	opts := *c.CertificateTransparency
+	opts.SignedCertificateTimestamps = append(slices.Clip(scts), c.CertificateTransparency.SignedCertificateTimestamps...)
+	if len(ocspResponse) > 0 {
+		opts.OCSPResponses = append([][]byte{ocspResponse}, c.CertificateTransparency.OCSPResponses...)
+	}
+	return &opts
+}
+
 func (c *Config) cipherSuites(aesGCMPreferred bool) []uint16 {
 	var cipherSuites []uint16
 	if c.CipherSuites == nil {
@@ -1431,6 +1546,8 @@ func (chi *ClientHelloInfo) SupportsCertificate(c *Certificate) error {
 				return errors.New("connection doesn't support Ed25519")
 			}
 			ecdsaCipherSuite = true
+		case *mldsa.PublicKey:
+			return errors.New("connection doesn't support ML-DSA")
 		case *rsa.PublicKey:
 		default:
 			return supportsRSAFallback(unsupportedCertificateError(c))
@@ -1555,7 +1672,8 @@ var writerMutex sync.Mutex
 type Certificate struct {
 	Certificate [][]byte
 	// PrivateKey contains the private key corresponding to the public key in
-	// Leaf. This must implement crypto.Signer with an RSA, ECDSA or Ed25519 PublicKey.
+	// Leaf. This must implement crypto.Signer with an RSA, ECDSA, Ed25519 or
+	// ML-DSA PublicKey. ML-DSA keys are only supported in TLS 1.3.
 	// For a server up to TLS 1.2, it can also implement crypto.Decrypter with
 	// an RSA PublicKey.
 	PrivateKey crypto.PrivateKey
@@ -9,6 +9,7 @@ package tls
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/cipher"
 	"crypto/subtle"
 	"crypto/x509"
@@ -54,6 +55,12 @@ type Conn struct {
 	ocspResponse     []byte   // stapled OCSP response
 	scts             [][]byte // signed certificate timestamps from server
 	peerCertificates []*x509.Certificate
+	peerRawPublicKey crypto.PublicKey // RFC 7250 raw public key from the peer
+	// rawPublicKeys is true if a raw public key was negotiated for either
+	// peer, and externalPSKIdentity is set if an external PSK was used. Such
+	// connections don't send or accept session tickets.
+	rawPublicKeys       bool
+	externalPSKIdentity []byte
 	// verifiedChains contains the certificate chains that we built, as
 	// opposed to the ones presented by the server.
 	verifiedChains [][]*x509.Certificate
@@ -1636,6 +1643,7 @@ func (c *Conn) connectionStateLocked() ConnectionState {
 	state.CipherSuite = c.cipherSuite
 	state.PeerCertificates = c.peerCertificates
 	state.VerifiedChains = c.verifiedChains
+	state.PeerRawPublicKey = c.peerRawPublicKey
 	state.SignedCertificateTimestamps = c.scts
 	state.OCSPResponse = c.ocspResponse
 	if (!c.didResume || c.extMasterSecret) && c.vers != VersionTLS13 {
@@ -1659,6 +1667,7 @@ func (c *Conn) connectionStateLocked() ConnectionState {
 		state.ekm = c.ekm
 	}
 	state.ECHAccepted = c.echAccepted
+	state.ExternalPSKIdentity = c.externalPSKIdentity
 	return state
 }
 
@@ -26,13 +26,24 @@ func defaultCurvePreferences() []CurveID {
 
 var tlssha1 = godebug.New("tlssha1")
 
+var tlsmldsa = godebug.New("tlsmldsa")
+
+// mldsaSignatureAlgorithms returns the ML-DSA signature algorithms, which are
+// advertised with the lowest preference unless disabled with tlsmldsa=0.
+func mldsaSignatureAlgorithms() []SignatureScheme {
+	if tlsmldsa.Value() == "0" {
+		return nil
+	}
+	return []SignatureScheme{MLDSA44, MLDSA65, MLDSA87}
+}
+
 // defaultSupportedSignatureAlgorithms returns the signature and hash algorithms that
 // the code advertises and supports in a TLS 1.2+ ClientHello and in a TLS 1.2+
 // CertificateRequest. The two fields are merged to match with TLS 1.3.
 // Note that in TLS 1.2, the ECDSA algorithms are not constrained to P-256, etc.
 func defaultSupportedSignatureAlgorithms() []SignatureScheme {
 	if tlssha1.Value() == "1" {
-		return []SignatureScheme{
+		return append([]SignatureScheme{
 			PSSWithSHA256,
 			ECDSAWithP256AndSHA256,
 			Ed25519,
@@ -45,9 +56,9 @@ func defaultSupportedSignatureAlgorithms() []SignatureScheme {
 			ECDSAWithP521AndSHA512,
 			PKCS1WithSHA1,
 			ECDSAWithSHA1,
-		}
+		}, mldsaSignatureAlgorithms()...)
 	}
-	return []SignatureScheme{
+	return append([]SignatureScheme{
 		PSSWithSHA256,
 		ECDSAWithP256AndSHA256,
 		Ed25519,
@@ -58,7 +69,7 @@ func defaultSupportedSignatureAlgorithms() []SignatureScheme {
 		PKCS1WithSHA512,
 		ECDSAWithP384AndSHA384,
 		ECDSAWithP521AndSHA512,
-	}
+	}, mldsaSignatureAlgorithms()...)
 }
 
 // defaultSupportedSignatureAlgorithmsCert returns the signature algorithms that
@@ -71,7 +82,7 @@ func defaultSupportedSignatureAlgorithms() []SignatureScheme {
 // choosing not to send it. crypto/x509 will refuse to verify important SHA-1
 // signatures anyway.
 func defaultSupportedSignatureAlgorithmsCert() []SignatureScheme {
-	return []SignatureScheme{
+	return append([]SignatureScheme{
 		PSSWithSHA256,
 		ECDSAWithP256AndSHA256,
 		Ed25519,
@@ -84,7 +95,7 @@ func defaultSupportedSignatureAlgorithmsCert() []SignatureScheme {
 		ECDSAWithP521AndSHA512,
 		PKCS1WithSHA1,
 		ECDSAWithSHA1,
-	}
+	}, mldsaSignatureAlgorithms()...)
 }
 
 var tlsrsakex = godebug.New("tlsrsakex")
@@ -162,7 +162,7 @@ func isFIPSSignatureScheme(alg SignatureScheme) bool {
 		PSSWithSHA384,
 		PSSWithSHA512:
 		return true
-	case Ed25519:
+	case Ed25519, MLDSA44, MLDSA65, MLDSA87:
 		// Only for the native module.
 		return !boring.Enabled
 	case PKCS1WithSHA1, ECDSAWithSHA1:
@@ -261,6 +261,7 @@ func fipsHandshake(t *testing.T, clientConfig, serverConfig *Config) (clientErr,
 }
 
 func TestFIPSServerSignatureAndHash(t *testing.T) {
+	t.Setenv("GODEBUG", "tlsmldsa=1")
 	defer func() {
 		testingOnlyForceClientHelloSignatureAlgorithms = nil
 	}()
@@ -286,11 +287,17 @@ func TestFIPSServerSignatureAndHash(t *testing.T) {
 				serverConfig.CipherSuites = []uint16{TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
 				serverConfig.Certificates[0].Certificate = [][]byte{testECDSACertificate}
 				serverConfig.Certificates[0].PrivateKey = testECDSAPrivateKey
+			case signatureMLDSA:
+				serverConfig.Certificates[0] = testMLDSACertificate(t, sigHash)
 			}
 			serverConfig.BuildNameToCertificate()
 			// PKCS#1 v1.5 signature algorithms can't be used standalone in TLS
-			// 1.3, and the ECDSA ones bind to the curve used.
+			// 1.3, and the ECDSA ones bind to the curve used. ML-DSA is only
+			// defined for TLS 1.3.
 			serverConfig.MaxVersion = VersionTLS12
+			if sigType == signatureMLDSA {
+				serverConfig.MaxVersion = VersionTLS13
+			}
 
 			runWithFIPSDisabled(t, func(t *testing.T) {
 				clientErr, serverErr := fipsHandshake(t, testConfig, serverConfig)
@@ -13,6 +13,7 @@ import (
 	"crypto/internal/fips140/mlkem"
 	"crypto/internal/fips140/tls13"
 	"crypto/internal/hpke"
+	"crypto/mldsa"
 	"crypto/rsa"
 	"crypto/subtle"
 	"crypto/tls/internal/fips140tls"
@@ -46,7 +47,10 @@ var testingOnlyForceClientHelloSignatureAlgorithms []SignatureScheme
 
 func (c *Conn) makeClientHello() (*clientHelloMsg, *keySharePrivateKeys, *echClientContext, error) {
 	config := c.config
-	if len(config.ServerName) == 0 && !config.InsecureSkipVerify {
+	// Only X.509 certificate chains are verified against ServerName.
+	verifiesChains := len(config.ExternalPSKs) == 0 &&
+		acceptsCertificateType(config.ServerCertificateTypes, CertificateTypeX509)
+	if len(config.ServerName) == 0 && !config.InsecureSkipVerify && verifiesChains {
 		return nil, nil, nil, errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
 	}
 
@@ -186,6 +190,10 @@ func (c *Conn) makeClientHello() (*clientHelloMsg, *keySharePrivateKeys, *echCli
 			}
 			hello.keyShares = []keyShare{{group: curveID, data: keyShareKeys.ecdhe.PublicKey().Bytes()}}
 		}
+
+		// RFC 7250 certificate types are only supported in TLS 1.3.
+		hello.serverCertificateTypes = config.ServerCertificateTypes
+		hello.clientCertificateTypes = config.ClientCertificateTypes
 	}
 
 	if c.quic != nil {
@@ -275,6 +283,10 @@ func (c *Conn) clientHandshake(ctx context.Context) (err error) {
 	if err != nil {
 		return err
 	}
+	externalPSKs, err := c.offerExternalPSKs(hello)
+	if err != nil {
+		return err
+	}
 	if session != nil {
 		defer func() {
 			// If we got a handshake failure when resuming a session, throw away
@@ -368,11 +380,17 @@ func (c *Conn) clientHandshake(ctx context.Context) (err error) {
 			session:      session,
 			earlySecret:  earlySecret,
 			binderKey:    binderKey,
+			externalPSKs: externalPSKs,
 			echContext:   ech,
 		}
 		return hs.handshake()
 	}
 
+	if len(externalPSKs) > 0 {
+		c.sendAlert(alertHandshakeFailure)
+		return errors.New("tls: server did not select an external PSK")
+	}
+
 	hs := &clientHandshakeState{
 		c:           c,
 		ctx:         ctx,
@@ -389,6 +407,11 @@ func (c *Conn) loadSession(hello *clientHelloMsg) (
 		return nil, nil, nil, nil
 	}
 
+	// Connections authenticated by external PSKs are never resumed.
+	if len(c.config.ExternalPSKs) > 0 {
+		return nil, nil, nil, nil
+	}
+
 	echInner := bytes.Equal(hello.encryptedClientHello, []byte{1})
 
 	// ticketSupported is a TLS 1.2 extension (as TLS 1.3 replaced tickets with PSK
@@ -1115,6 +1138,11 @@ func checkKeySize(n int) (max int, ok bool) {
 // verifyServerCertificate parses and verifies the provided chain, setting
 // c.verifiedChains and c.peerCertificates or sending the appropriate alert.
 func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
+	if !acceptsCertificateType(c.config.ServerCertificateTypes, CertificateTypeX509) {
+		c.sendAlert(alertUnsupportedCertificate)
+		return errors.New("tls: server sent an X.509 certificate, which is not in Config.ServerCertificateTypes")
+	}
+
 	certs := make([]*x509.Certificate, len(certificates))
 	for i, asn1Data := range certificates {
 		cert, err := globalCertCache.newCert(asn1Data)
@@ -1164,10 +1192,12 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 		}
 	} else if !c.config.InsecureSkipVerify {
 		opts := x509.VerifyOptions{
-			Roots:         c.config.RootCAs,
-			CurrentTime:   c.config.time(),
-			DNSName:       c.config.ServerName,
-			Intermediates: x509.NewCertPool(),
+			Roots:                   c.config.RootCAs,
+			CurrentTime:             c.config.time(),
+			DNSName:                 c.config.ServerName,
+			Intermediates:           x509.NewCertPool(),
+			Revocation:              c.config.revocation(c.ocspResponse),
+			CertificateTransparency: c.config.certificateTransparency(c.scts, c.ocspResponse),
 		}
 
 		for _, cert := range certs[1:] {
@@ -1175,7 +1205,12 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 		}
 		chains, err := certs[0].Verify(opts)
 		if err != nil {
-			c.sendAlert(alertBadCertificate)
+			var errCertificateInvalid x509.CertificateInvalidError
+			if errors.As(err, &errCertificateInvalid) && errCertificateInvalid.Reason == x509.Revoked {
+				c.sendAlert(alertCertificateRevoked)
+			} else {
+				c.sendAlert(alertBadCertificate)
+			}
 			return &CertificateVerificationError{UnverifiedCertificates: certs, Err: err}
 		}
 
@@ -1187,7 +1222,7 @@ func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
 	}
 
 	switch certs[0].PublicKey.(type) {
-	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey, *mldsa.PublicKey:
 		break
 	default:
 		c.sendAlert(alertUnsupportedCertificate)
@@ -11,6 +11,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/tls/internal/fips140tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -32,6 +33,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/crypto/cryptobyte"
 )
 
 // Note: see comment in handshake_test.go for details of how the reference
@@ -2928,3 +2931,274 @@ func TestECHTLS12Server(t *testing.T) {
 		t.Fatalf("unexpected handshake error: got %q, want %q", err, expectedErr)
 	}
 }
+
+func TestRevocation(t *testing.T) {
+	now := testConfig.Time()
+	newCert := func(cn string, isCA bool, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber:          serial,
+			Subject:               pkix.Name{CommonName: cn},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(time.Hour),
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			BasicConstraintsValid: true,
+			IsCA:                  isCA,
+		}
+		if isCA {
+			tmpl.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		} else {
+			tmpl.DNSNames = []string{cn}
+		}
+		if issuer == nil {
+			issuer, issuerKey = tmpl, k
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, k.Public(), issuerKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert, k
+	}
+	root, rootKey := newCert("Root", true, nil, nil)
+	serverCert, serverKey := newCert("example.golang", false, root, rootKey)
+	clientCert, clientKey := newCert("client.golang", false, root, rootKey)
+
+	staple := func(status x509.OCSPCertStatus) []byte {
+		id, err := x509.NewOCSPCertID(serverCert, root, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sr := x509.OCSPSingleResponse{CertID: *id, Status: status, ThisUpdate: now.Add(-time.Minute), NextUpdate: now.Add(time.Hour)}
+		if status == x509.OCSPRevoked {
+			sr.RevokedAt = now.Add(-time.Minute)
+		}
+		der, err := x509.CreateOCSPResponse(rand.Reader, &x509.OCSPResponse{Responses: []x509.OCSPSingleResponse{sr}}, root, rootKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der
+	}
+	crlDER, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: now.Add(-time.Minute),
+		NextUpdate: now.Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: clientCert.SerialNumber, RevocationTime: now.Add(-time.Minute)},
+		},
+	}, root, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := x509.ParseRevocationList(crlDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name          string
+		staple        []byte
+		client        *x509.RevocationOptions
+		server        *x509.RevocationOptions
+		sendClientKey bool
+		wantErr       string
+	}{
+		{name: "no checking", staple: staple(x509.OCSPRevoked)},
+		{name: "good staple", staple: staple(x509.OCSPGood), client: &x509.RevocationOptions{Policy: x509.RevocationHardFail}},
+		{name: "revoked staple", staple: staple(x509.OCSPRevoked), client: &x509.RevocationOptions{},
+			wantErr: "certificate has been revoked"},
+		{name: "unknown staple, soft fail", staple: staple(x509.OCSPUnknown), client: &x509.RevocationOptions{}},
+		{name: "unknown staple, hard fail", staple: staple(x509.OCSPUnknown), client: &x509.RevocationOptions{Policy: x509.RevocationHardFail},
+			wantErr: "revocation status could not be determined"},
+		{name: "no staple, hard fail", client: &x509.RevocationOptions{Policy: x509.RevocationHardFail},
+			wantErr: "revocation status could not be determined"},
+		{name: "client certificate revoked", server: &x509.RevocationOptions{RevocationLists: []*x509.RevocationList{crl}}, sendClientKey: true,
+			wantErr: "certificate has been revoked"},
+	} {
+		for _, version := range []uint16{VersionTLS12, VersionTLS13} {
+			t.Run(fmt.Sprintf("%s/%x", tc.name, version), func(t *testing.T) {
+				if tc.wantErr != "" && tc.sendClientKey && version == VersionTLS13 {
+					// In TLS 1.3 the client finishes the handshake before the
+					// server verifies its certificate, which testHandshake
+					// reports as a read failure.
+					t.Skip("client certificate rejections are not observable by testHandshake in TLS 1.3")
+				}
+				serverConfig := testConfig.Clone()
+				serverConfig.MaxVersion = version
+				serverConfig.Certificates = []Certificate{{
+					Certificate: [][]byte{serverCert.Raw},
+					PrivateKey:  serverKey,
+					OCSPStaple:  tc.staple,
+				}}
+				serverConfig.ClientCAs = x509.NewCertPool()
+				serverConfig.ClientCAs.AddCert(root)
+				serverConfig.ClientAuth = VerifyClientCertIfGiven
+				serverConfig.Revocation = tc.server
+
+				clientConfig := testConfig.Clone()
+				clientConfig.MaxVersion = version
+				clientConfig.InsecureSkipVerify = false
+				clientConfig.ServerName = "example.golang"
+				clientConfig.RootCAs = x509.NewCertPool()
+				clientConfig.RootCAs.AddCert(root)
+				clientConfig.Revocation = tc.client
+				if tc.sendClientKey {
+					clientConfig.Certificates = []Certificate{{
+						Certificate: [][]byte{clientCert.Raw},
+						PrivateKey:  clientKey,
+					}}
+				}
+
+				_, _, err := testHandshake(t, clientConfig, serverConfig)
+				if tc.wantErr == "" {
+					if err != nil {
+						t.Fatalf("handshake failed: %v", err)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("handshake returned %v, want an error containing %q", err, tc.wantErr)
+				}
+				if !strings.Contains(err.Error(), "revoked certificate") && tc.wantErr == "certificate has been revoked" {
+					t.Errorf("the peer did not receive a certificate_revoked alert: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestCertificateTransparency(t *testing.T) {
+	now := testConfig.Time()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "example.golang"},
+		DNSNames:     []string{"example.golang"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, root, serverKey.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, root, root, rootKey.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root, err = x509.ParseCertificate(rootDER); err != nil {
+		t.Fatal(err)
+	}
+
+	// sign returns an SCT for the server certificate from a log with key.
+	sign := func(key *ecdsa.PrivateKey) []byte {
+		spki, err := x509.MarshalPKIXPublicKey(key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		logID := sha256.Sum256(spki)
+		timestamp := uint64(now.Add(-time.Minute).UnixMilli())
+		var signed cryptobyte.Builder
+		signed.AddUint8(0) // v1
+		signed.AddUint8(0) // certificate_timestamp
+		signed.AddUint64(timestamp)
+		signed.AddUint16(0) // x509_entry
+		signed.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(serverDER) })
+		signed.AddUint16(0) // no extensions
+		digest := sha256.Sum256(signed.BytesOrPanic())
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b cryptobyte.Builder
+		b.AddUint8(0)
+		b.AddBytes(logID[:])
+		b.AddUint64(timestamp)
+		b.AddUint16(0)
+		b.AddUint8(4) // sha256
+		b.AddUint8(3) // ecdsa
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sig) })
+		return b.BytesOrPanic()
+	}
+	var logs []*x509.CTLog
+	var scts [][]byte
+	for _, operator := range []string{"Operator A", "Operator B"} {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, &x509.CTLog{Operator: operator, PublicKey: key.Public()})
+		scts = append(scts, sign(key))
+	}
+
+	for _, tc := range []struct {
+		name    string
+		scts    [][]byte
+		opts    *x509.CTOptions
+		wantErr bool
+	}{
+		{name: "no checking"},
+		{name: "two operators", scts: scts, opts: &x509.CTOptions{Logs: logs}},
+		{name: "one operator", scts: scts[:1], opts: &x509.CTOptions{Logs: logs}, wantErr: true},
+		{name: "one operator required", scts: scts[:1], opts: &x509.CTOptions{Logs: logs, MinSCTs: 1, MinOperators: 1}},
+		{name: "unknown logs", scts: scts, opts: &x509.CTOptions{}, wantErr: true},
+	} {
+		for _, version := range []uint16{VersionTLS12, VersionTLS13} {
+			t.Run(fmt.Sprintf("%s/%x", tc.name, version), func(t *testing.T) {
+				serverConfig := testConfig.Clone()
+				serverConfig.MaxVersion = version
+				serverConfig.Certificates = []Certificate{{
+					Certificate:                 [][]byte{serverDER},
+					PrivateKey:                  serverKey,
+					SignedCertificateTimestamps: tc.scts,
+				}}
+
+				clientConfig := testConfig.Clone()
+				clientConfig.MaxVersion = version
+				clientConfig.InsecureSkipVerify = false
+				clientConfig.ServerName = "example.golang"
+				clientConfig.RootCAs = x509.NewCertPool()
+				clientConfig.RootCAs.AddCert(root)
+				clientConfig.CertificateTransparency = tc.opts
+
+				_, _, err := testHandshake(t, clientConfig, serverConfig)
+				if !tc.wantErr {
+					if err != nil {
+						t.Fatalf("handshake failed: %v", err)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), "Certificate Transparency policy") {
+					t.Fatalf("handshake returned %v, want a Certificate Transparency error", err)
+				}
+			})
+		}
+	}
+}
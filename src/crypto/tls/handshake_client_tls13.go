@@ -31,19 +31,24 @@ type clientHandshakeStateTLS13 struct {
 	earlySecret *tls13.EarlySecret
 	binderKey   []byte
 
-	certReq       *certificateRequestMsgTLS13
-	usingPSK      bool
-	sentDummyCCS  bool
-	suite         *cipherSuiteTLS13
-	transcript    hash.Hash
-	masterSecret  *tls13.MasterSecret
-	trafficSecret []byte // client_application_traffic_secret_0
+	externalPSKs []*externalPSK // offered external PSKs, if any
+
+	certReq        *certificateRequestMsgTLS13
+	serverCertType CertificateType
+	clientCertType CertificateType
+	usingPSK       bool
+	sentDummyCCS   bool
+	suite          *cipherSuiteTLS13
+	transcript     hash.Hash
+	masterSecret   *tls13.MasterSecret
+	trafficSecret  []byte // client_application_traffic_secret_0
 
 	echContext *echClientContext
 }
 
 // handshake requires hs.c, hs.hello, hs.serverHello, hs.keyShareKeys, and,
-// optionally, hs.session, hs.earlySecret and hs.binderKey to be set.
+// optionally, hs.session, hs.earlySecret and hs.binderKey, or hs.externalPSKs,
+// to be set.
 func (hs *clientHandshakeStateTLS13) handshake() error {
 	c := hs.c
 
@@ -338,7 +343,11 @@ func (hs *clientHandshakeStateTLS13) processHelloRetryRequest() error {
 		hello.keyShares = []keyShare{{group: curveID, data: key.PublicKey().Bytes()}}
 	}
 
-	if len(hello.pskIdentities) > 0 {
+	if len(hs.externalPSKs) > 0 {
+		if err := hs.updateExternalPSKsAfterHRR(hello, chHash); err != nil {
+			return err
+		}
+	} else if len(hello.pskIdentities) > 0 {
 		pskSuite := cipherSuiteTLS13ByID(hs.session.cipherSuite)
 		if pskSuite == nil {
 			return c.sendAlert(alertInternalError)
@@ -443,6 +452,10 @@ func (hs *clientHandshakeStateTLS13) processServerHello() error {
 	}
 
 	if !hs.serverHello.selectedIdentityPresent {
+		if len(hs.externalPSKs) > 0 {
+			c.sendAlert(alertHandshakeFailure)
+			return errors.New("tls: server did not select an external PSK")
+		}
 		return nil
 	}
 
@@ -451,6 +464,18 @@ func (hs *clientHandshakeStateTLS13) processServerHello() error {
 		return errors.New("tls: server selected an invalid PSK")
 	}
 
+	if len(hs.externalPSKs) > 0 {
+		psk := hs.externalPSKs[hs.serverHello.selectedIdentity]
+		if psk.hash != hs.suite.hash {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server selected an invalid PSK and cipher suite pair")
+		}
+		hs.usingPSK = true
+		hs.earlySecret = psk.earlySecret
+		c.externalPSKIdentity = psk.source.Identity
+		return nil
+	}
+
 	if len(hs.hello.pskIdentities) != 1 || hs.session == nil {
 		return c.sendAlert(alertInternalError)
 	}
@@ -607,6 +632,24 @@ func (hs *clientHandshakeStateTLS13) readServerParameters() error {
 		}
 	}
 
+	// See RFC 7250, Section 4.2.
+	hs.serverCertType = CertificateTypeX509
+	if encryptedExtensions.hasServerCertificateType {
+		if !slices.Contains(hs.hello.serverCertificateTypes, encryptedExtensions.serverCertificateType) {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server selected an unoffered server certificate type")
+		}
+		hs.serverCertType = encryptedExtensions.serverCertificateType
+	}
+	hs.clientCertType = CertificateTypeX509
+	if encryptedExtensions.hasClientCertificateType {
+		if !slices.Contains(hs.hello.clientCertificateTypes, encryptedExtensions.clientCertificateType) {
+			c.sendAlert(alertIllegalParameter)
+			return errors.New("tls: server selected an unoffered client certificate type")
+		}
+		hs.clientCertType = encryptedExtensions.clientCertificateType
+	}
+
 	return nil
 }
 
@@ -653,11 +696,24 @@ func (hs *clientHandshakeStateTLS13) readServerCertificate() error {
 		return errors.New("tls: received empty certificates message")
 	}
 
-	c.scts = certMsg.certificate.SignedCertificateTimestamps
-	c.ocspResponse = certMsg.certificate.OCSPStaple
+	if hs.serverCertType == CertificateTypeRawPublicKey {
+		c.rawPublicKeys = true
+		if err := c.processRawPublicKey(certMsg.certificate.Certificate, !c.config.InsecureSkipVerify); err != nil {
+			return err
+		}
+		if c.config.VerifyConnection != nil {
+			if err := c.config.VerifyConnection(c.connectionStateLocked()); err != nil {
+				c.sendAlert(alertBadCertificate)
+				return err
+			}
+		}
+	} else {
+		c.scts = certMsg.certificate.SignedCertificateTimestamps
+		c.ocspResponse = certMsg.certificate.OCSPStaple
 
-	if err := c.verifyServerCertificate(certMsg.certificate.Certificate); err != nil {
-		return err
+		if err := c.verifyServerCertificate(certMsg.certificate.Certificate); err != nil {
+			return err
+		}
 	}
 
 	// certificateVerifyMsg is included in the transcript, but not until
@@ -689,7 +745,7 @@ func (hs *clientHandshakeStateTLS13) readServerCertificate() error {
 		return c.sendAlert(alertInternalError)
 	}
 	signed := signedMessage(sigHash, serverSignatureContext, hs.transcript)
-	if err := verifyHandshakeSignature(sigType, c.peerCertificates[0].PublicKey,
+	if err := verifyHandshakeSignature(sigType, c.peerPublicKey(),
 		sigHash, signed, certVerify.signature); err != nil {
 		c.sendAlert(alertDecryptError)
 		return errors.New("tls: invalid signature by the server certificate: " + err.Error())
@@ -777,16 +833,28 @@ func (hs *clientHandshakeStateTLS13) sendClientCertificate() error {
 
 	certMsg := new(certificateMsgTLS13)
 
-	certMsg.certificate = *cert
-	certMsg.scts = hs.certReq.scts && len(cert.SignedCertificateTimestamps) > 0
-	certMsg.ocspStapling = hs.certReq.ocspStapling && len(cert.OCSPStaple) > 0
+	switch {
+	case hs.clientCertType == CertificateTypeRawPublicKey:
+		if cert.PrivateKey != nil {
+			c.rawPublicKeys = true
+			certMsg.certificate, err = rawPublicKeyCertificate(cert)
+			if err != nil {
+				c.sendAlert(alertInternalError)
+				return err
+			}
+		}
+	case acceptsCertificateType(c.config.ClientCertificateTypes, CertificateTypeX509):
+		certMsg.certificate = *cert
+		certMsg.scts = hs.certReq.scts && len(cert.SignedCertificateTimestamps) > 0
+		certMsg.ocspStapling = hs.certReq.ocspStapling && len(cert.OCSPStaple) > 0
+	}
 
 	if _, err := hs.c.writeHandshakeRecord(certMsg, hs.transcript); err != nil {
 		return err
 	}
 
 	// If we sent an empty certificate message, skip the CertificateVerify.
-	if len(cert.Certificate) == 0 {
+	if len(certMsg.certificate.Certificate) == 0 {
 		return nil
 	}
 
@@ -862,6 +930,12 @@ func (c *Conn) handleNewSessionTicket(msg *newSessionTicketMsgTLS13) error {
 		return nil
 	}
 
+	// Sessions authenticated by raw public keys or external PSKs are not
+	// resumed, as they carry no certificates to re-verify.
+	if c.rawPublicKeys || c.externalPSKIdentity != nil {
+		return nil
+	}
+
 	// See RFC 8446, Section 4.6.1.
 	if msg.lifetime == 0 {
 		return nil
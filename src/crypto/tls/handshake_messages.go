@@ -88,6 +88,8 @@ type clientHelloMsg struct {
 	extendedMasterSecret             bool
 	alpnProtocols                    []string
 	scts                             bool
+	clientCertificateTypes           []CertificateType
+	serverCertificateTypes           []CertificateType
 	supportedVersions                []uint16
 	cookie                           []byte
 	keyShares                        []keyShare
@@ -150,6 +152,28 @@ func (m *clientHelloMsg) marshalMsg(echInner bool) ([]byte, error) {
 		exts.AddUint16(extensionSCT)
 		exts.AddUint16(0) // empty extension_data
 	}
+	if len(m.clientCertificateTypes) > 0 {
+		// RFC 7250, Section 4.1
+		exts.AddUint16(extensionClientCertificateType)
+		exts.AddUint16LengthPrefixed(func(exts *cryptobyte.Builder) {
+			exts.AddUint8LengthPrefixed(func(exts *cryptobyte.Builder) {
+				for _, t := range m.clientCertificateTypes {
+					exts.AddUint8(uint8(t))
+				}
+			})
+		})
+	}
+	if len(m.serverCertificateTypes) > 0 {
+		// RFC 7250, Section 4.1
+		exts.AddUint16(extensionServerCertificateType)
+		exts.AddUint16LengthPrefixed(func(exts *cryptobyte.Builder) {
+			exts.AddUint8LengthPrefixed(func(exts *cryptobyte.Builder) {
+				for _, t := range m.serverCertificateTypes {
+					exts.AddUint8(uint8(t))
+				}
+			})
+		})
+	}
 	if m.earlyData {
 		// RFC 8446, Section 4.2.10
 		exts.AddUint16(extensionEarlyData)
@@ -585,6 +609,21 @@ func (m *clientHelloMsg) unmarshal(data []byte) bool {
 		case extensionSCT:
 			// RFC 6962, Section 3.3.1
 			m.scts = true
+		case extensionClientCertificateType, extensionServerCertificateType:
+			// RFC 7250, Section 4.1
+			var types []uint8
+			if !readUint8LengthPrefixed(&extData, &types) || len(types) == 0 {
+				return false
+			}
+			certTypes := make([]CertificateType, len(types))
+			for i, t := range types {
+				certTypes[i] = CertificateType(t)
+			}
+			if extension == extensionClientCertificateType {
+				m.clientCertificateTypes = certTypes
+			} else {
+				m.serverCertificateTypes = certTypes
+			}
 		case extensionSupportedVersions:
 			// RFC 8446, Section 4.2.1
 			var versList cryptobyte.String
@@ -704,6 +743,8 @@ func (m *clientHelloMsg) clone() *clientHelloMsg {
 		extendedMasterSecret:             m.extendedMasterSecret,
 		alpnProtocols:                    slices.Clone(m.alpnProtocols),
 		scts:                             m.scts,
+		clientCertificateTypes:           slices.Clone(m.clientCertificateTypes),
+		serverCertificateTypes:           slices.Clone(m.serverCertificateTypes),
 		supportedVersions:                slices.Clone(m.supportedVersions),
 		cookie:                           slices.Clone(m.cookie),
 		keyShares:                        slices.Clone(m.keyShares),
@@ -1001,10 +1042,14 @@ func (m *serverHelloMsg) originalBytes() []byte {
 }
 
 type encryptedExtensionsMsg struct {
-	alpnProtocol            string
-	quicTransportParameters []byte
-	earlyData               bool
-	echRetryConfigs         []byte
+	alpnProtocol             string
+	quicTransportParameters  []byte
+	earlyData                bool
+	echRetryConfigs          []byte
+	clientCertificateType    CertificateType
+	hasClientCertificateType bool
+	serverCertificateType    CertificateType
+	hasServerCertificateType bool
 }
 
 func (m *encryptedExtensionsMsg) marshal() ([]byte, error) {
@@ -1040,6 +1085,20 @@ func (m *encryptedExtensionsMsg) marshal() ([]byte, error) {
 					b.AddBytes(m.echRetryConfigs)
 				})
 			}
+			if m.hasClientCertificateType {
+				// RFC 7250, Section 4.2
+				b.AddUint16(extensionClientCertificateType)
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddUint8(uint8(m.clientCertificateType))
+				})
+			}
+			if m.hasServerCertificateType {
+				// RFC 7250, Section 4.2
+				b.AddUint16(extensionServerCertificateType)
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddUint8(uint8(m.serverCertificateType))
+				})
+			}
 		})
 	})
 
@@ -1095,6 +1154,18 @@ func (m *encryptedExtensionsMsg) unmarshal(data []byte) bool {
 			if !extData.CopyBytes(m.echRetryConfigs) {
 				return false
 			}
+		case extensionClientCertificateType:
+			// RFC 7250, Section 4.2
+			if !extData.ReadUint8((*uint8)(&m.clientCertificateType)) {
+				return false
+			}
+			m.hasClientCertificateType = true
+		case extensionServerCertificateType:
+			// RFC 7250, Section 4.2
+			if !extData.ReadUint8((*uint8)(&m.serverCertificateType)) {
+				return false
+			}
+			m.hasServerCertificateType = true
 		default:
 			// Ignore unknown extensions.
 			continue
@@ -231,6 +231,12 @@ func (*clientHelloMsg) Generate(rand *rand.Rand, size int) reflect.Value {
 	if rand.Intn(10) > 5 {
 		m.encryptedClientHello = randomBytes(rand.Intn(50)+1, rand)
 	}
+	if rand.Intn(10) > 5 {
+		m.clientCertificateTypes = []CertificateType{CertificateTypeRawPublicKey, CertificateTypeX509}
+	}
+	if rand.Intn(10) > 5 {
+		m.serverCertificateTypes = []CertificateType{CertificateTypeX509}
+	}
 
 	return reflect.ValueOf(m)
 }
@@ -302,6 +308,13 @@ func (*encryptedExtensionsMsg) Generate(rand *rand.Rand, size int) reflect.Value
 	if rand.Intn(10) > 5 {
 		m.earlyData = true
 	}
+	if rand.Intn(10) > 5 {
+		m.hasClientCertificateType = true
+		m.clientCertificateType = CertificateTypeRawPublicKey
+	}
+	if rand.Intn(10) > 5 {
+		m.hasServerCertificateType = true
+	}
 
 	return reflect.ValueOf(m)
 }
@@ -9,6 +9,7 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	"crypto/mldsa"
 	"crypto/rsa"
 	"crypto/subtle"
 	"crypto/tls/internal/fips140tls"
@@ -18,6 +19,7 @@ import (
 	"hash"
 	"internal/byteorder"
 	"io"
+	"slices"
 	"time"
 )
 
@@ -650,7 +652,7 @@ func (hs *serverHandshakeState) doFullHandshake() error {
 		}
 		if c.vers >= VersionTLS12 {
 			certReq.hasSignatureAlgorithm = true
-			certReq.supportedSignatureAlgorithms = supportedSignatureAlgorithms(c.vers)
+			certReq.supportedSignatureAlgorithms = slices.DeleteFunc(supportedSignatureAlgorithms(c.vers), isTLS13OnlySignatureScheme)
 		}
 
 		// An empty list of certificateAuthorities signals to
@@ -937,6 +939,11 @@ func (c *Conn) processCertsFromClient(certificate Certificate) error {
 		}
 	}
 
+	if len(certs) > 0 && !acceptsCertificateType(c.config.ClientCertificateTypes, CertificateTypeX509) {
+		c.sendAlert(alertUnsupportedCertificate)
+		return errors.New("tls: client sent an X.509 certificate, which is not in Config.ClientCertificateTypes")
+	}
+
 	if len(certs) == 0 && requiresClientCert(c.config.ClientAuth) {
 		if c.vers == VersionTLS13 {
 			c.sendAlert(alertCertificateRequired)
@@ -948,10 +955,12 @@ func (c *Conn) processCertsFromClient(certificate Certificate) error {
 
 	if c.config.ClientAuth >= VerifyClientCertIfGiven && len(certs) > 0 {
 		opts := x509.VerifyOptions{
-			Roots:         c.config.ClientCAs,
-			CurrentTime:   c.config.time(),
-			Intermediates: x509.NewCertPool(),
-			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			Roots:                   c.config.ClientCAs,
+			CurrentTime:             c.config.time(),
+			Intermediates:           x509.NewCertPool(),
+			KeyUsages:               []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			Revocation:              c.config.revocation(certificate.OCSPStaple),
+			CertificateTransparency: c.config.certificateTransparency(certificate.SignedCertificateTimestamps, certificate.OCSPStaple),
 		}
 
 		for _, cert := range certs[1:] {
@@ -965,6 +974,8 @@ func (c *Conn) processCertsFromClient(certificate Certificate) error {
 				c.sendAlert(alertUnknownCA)
 			} else if errors.As(err, &errCertificateInvalid) && errCertificateInvalid.Reason == x509.Expired {
 				c.sendAlert(alertCertificateExpired)
+			} else if errors.As(err, &errCertificateInvalid) && errCertificateInvalid.Reason == x509.Revoked {
+				c.sendAlert(alertCertificateRevoked)
 			} else {
 				c.sendAlert(alertBadCertificate)
 			}
@@ -984,7 +995,7 @@ func (c *Conn) processCertsFromClient(certificate Certificate) error {
 
 	if len(certs) > 0 {
 		switch certs[0].PublicKey.(type) {
-		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey, *mldsa.PublicKey:
 		default:
 			c.sendAlert(alertUnsupportedCertificate)
 			return fmt.Errorf("tls: client certificate contains an unsupported public key of type %T", certs[0].PublicKey)
@@ -53,6 +53,8 @@ type serverHandshakeStateTLS13 struct {
 	suite           *cipherSuiteTLS13
 	cert            *Certificate
 	sigAlg          SignatureScheme
+	serverCertType  CertificateType
+	clientCertType  CertificateType
 	earlySecret     *tls13.EarlySecret
 	sharedKey       []byte
 	handshakeSecret *tls13.HandshakeSecret
@@ -70,6 +72,9 @@ func (hs *serverHandshakeStateTLS13) handshake() error {
 	if err := hs.processClientHello(); err != nil {
 		return err
 	}
+	if err := hs.checkForExternalPSK(); err != nil {
+		return err
+	}
 	if err := hs.checkForResumption(); err != nil {
 		return err
 	}
@@ -331,7 +336,7 @@ func (hs *serverHandshakeStateTLS13) processClientHello() error {
 func (hs *serverHandshakeStateTLS13) checkForResumption() error {
 	c := hs.c
 
-	if c.config.SessionTicketsDisabled {
+	if c.config.SessionTicketsDisabled || hs.usingPSK {
 		return nil
 	}
 
@@ -509,6 +514,21 @@ func (hs *serverHandshakeStateTLS13) pickCertificate() error {
 		return c.sendAlert(alertMissingExtension)
 	}
 
+	// See RFC 7250, Section 4.2.
+	var ok bool
+	hs.serverCertType, ok = negotiateCertificateType(c.config.ServerCertificateTypes, hs.clientHello.serverCertificateTypes)
+	if !ok {
+		c.sendAlert(alertUnsupportedCertificate)
+		return errors.New("tls: no server certificate type supported by both client and server")
+	}
+	if hs.requestClientCert() {
+		hs.clientCertType, ok = negotiateCertificateType(c.config.ClientCertificateTypes, hs.clientHello.clientCertificateTypes)
+		if !ok {
+			c.sendAlert(alertUnsupportedCertificate)
+			return errors.New("tls: no client certificate type supported by both client and server")
+		}
+	}
+
 	certificate, err := c.config.getCertificate(clientHelloInfo(hs.ctx, c, hs.clientHello))
 	if err != nil {
 		if err == errNoCertificates {
@@ -723,6 +743,8 @@ func illegalClientHelloChange(ch, ch1 *clientHelloMsg) bool {
 		ch.secureRenegotiationSupported != ch1.secureRenegotiationSupported ||
 		!bytes.Equal(ch.secureRenegotiation, ch1.secureRenegotiation) ||
 		ch.scts != ch1.scts ||
+		!slices.Equal(ch.clientCertificateTypes, ch1.clientCertificateTypes) ||
+		!slices.Equal(ch.serverCertificateTypes, ch1.serverCertificateTypes) ||
 		!bytes.Equal(ch.cookie, ch1.cookie) ||
 		!bytes.Equal(ch.pskModes, ch1.pskModes)
 }
@@ -793,6 +815,15 @@ func (hs *serverHandshakeStateTLS13) sendServerParameters() error {
 	encryptedExtensions := new(encryptedExtensionsMsg)
 	encryptedExtensions.alpnProtocol = c.clientProtocol
 
+	if !hs.usingPSK && len(hs.clientHello.serverCertificateTypes) > 0 {
+		encryptedExtensions.hasServerCertificateType = true
+		encryptedExtensions.serverCertificateType = hs.serverCertType
+	}
+	if hs.requestClientCert() && len(hs.clientHello.clientCertificateTypes) > 0 {
+		encryptedExtensions.hasClientCertificateType = true
+		encryptedExtensions.clientCertificateType = hs.clientCertType
+	}
+
 	if c.quic != nil {
 		p, err := c.quicGetTransportParameters()
 		if err != nil {
@@ -846,7 +877,7 @@ func (hs *serverHandshakeStateTLS13) sendServerCertificate() error {
 		certReq.scts = true
 		certReq.supportedSignatureAlgorithms = supportedSignatureAlgorithms(c.vers)
 		certReq.supportedSignatureAlgorithmsCert = supportedSignatureAlgorithmsCert()
-		if c.config.ClientCAs != nil {
+		if c.config.ClientCAs != nil && hs.clientCertType == CertificateTypeX509 {
 			certReq.certificateAuthorities = c.config.ClientCAs.Subjects()
 		}
 
@@ -857,9 +888,19 @@ func (hs *serverHandshakeStateTLS13) sendServerCertificate() error {
 
 	certMsg := new(certificateMsgTLS13)
 
-	certMsg.certificate = *hs.cert
-	certMsg.scts = hs.clientHello.scts && len(hs.cert.SignedCertificateTimestamps) > 0
-	certMsg.ocspStapling = hs.clientHello.ocspStapling && len(hs.cert.OCSPStaple) > 0
+	if hs.serverCertType == CertificateTypeRawPublicKey {
+		c.rawPublicKeys = true
+		var err error
+		certMsg.certificate, err = rawPublicKeyCertificate(hs.cert)
+		if err != nil {
+			c.sendAlert(alertInternalError)
+			return err
+		}
+	} else {
+		certMsg.certificate = *hs.cert
+		certMsg.scts = hs.clientHello.scts && len(hs.cert.SignedCertificateTimestamps) > 0
+		certMsg.ocspStapling = hs.clientHello.ocspStapling && len(hs.cert.OCSPStaple) > 0
+	}
 
 	if _, err := hs.c.writeHandshakeRecord(certMsg, hs.transcript); err != nil {
 		return err
@@ -985,6 +1026,12 @@ func (hs *serverHandshakeStateTLS13) sendSessionTickets() error {
 }
 
 func (c *Conn) sendSessionTicket(earlyData bool, extra [][]byte) error {
+	// Sessions authenticated by raw public keys or external PSKs are not
+	// resumed, as they carry no certificates to re-verify.
+	if c.rawPublicKeys || c.externalPSKIdentity != nil {
+		return nil
+	}
+
 	suite := cipherSuiteTLS13ByID(c.cipherSuite)
 	if suite == nil {
 		return errors.New("tls: internal error: unknown cipher suite")
@@ -1069,7 +1116,14 @@ func (hs *serverHandshakeStateTLS13) readClientCertificate() error {
 		return unexpectedMessageError(certMsg, msg)
 	}
 
-	if err := c.processCertsFromClient(certMsg.certificate); err != nil {
+	if hs.clientCertType == CertificateTypeRawPublicKey {
+		if len(certMsg.certificate.Certificate) != 0 {
+			c.rawPublicKeys = true
+		}
+		if err := c.processRawPublicKeyFromClient(certMsg.certificate); err != nil {
+			return err
+		}
+	} else if err := c.processCertsFromClient(certMsg.certificate); err != nil {
 		return err
 	}
 
@@ -1110,7 +1164,7 @@ func (hs *serverHandshakeStateTLS13) readClientCertificate() error {
 			return c.sendAlert(alertInternalError)
 		}
 		signed := signedMessage(sigHash, clientSignatureContext, hs.transcript)
-		if err := verifyHandshakeSignature(sigType, c.peerCertificates[0].PublicKey,
+		if err := verifyHandshakeSignature(sigType, c.peerPublicKey(),
 			sigHash, signed, certVerify.signature); err != nil {
 			c.sendAlert(alertDecryptError)
 			return errors.New("tls: invalid signature by the client certificate: " + err.Error())
@@ -8,12 +8,15 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/ed25519"
+	"crypto/mldsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"os"
 	"os/exec"
@@ -427,6 +430,10 @@ func runMain(m *testing.M) int {
 	// the version without AES acceleration for test consistency.
 	hasAESGCMHardwareSupport = false
 
+	// The recorded handshakes in testdata predate ML-DSA. Don't advertise it
+	// unless a test opts back in with tlsmldsa=1.
+	os.Setenv("GODEBUG", os.Getenv("GODEBUG")+",tlsmldsa=0")
+
 	// Set up localPipe.
 	l, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -558,6 +565,35 @@ var testP256PrivateKey, _ = x509.ParseECPrivateKey(fromHex("30770201010420012f3b
 
 var testEd25519PrivateKey = ed25519.PrivateKey(fromHex("3a884965e76b3f55e5faf9615458a92354894234de3ec9f684d46d55cebf3dc63fe2152ee6e3ef3f4e854a7577a3649eede0bf842ccc92268ffa6f3483aaec8f"))
 
+// testMLDSACertificate returns a self-signed certificate for example.com with
+// an ML-DSA key matching the given signature scheme.
+func testMLDSACertificate(t testing.TB, sigAlg SignatureScheme) Certificate {
+	t.Helper()
+	params := map[SignatureScheme]mldsa.Parameters{
+		MLDSA44: mldsa.MLDSA44(),
+		MLDSA65: mldsa.MLDSA65(),
+		MLDSA87: mldsa.MLDSA87(),
+	}[sigAlg]
+	key, err := mldsa.NewPrivateKey(params, make([]byte, mldsa.SeedSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Acme Co"}},
+		NotBefore:    time.Unix(1000, 0),
+		NotAfter:     time.Unix(100000000000, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"example.com"},
+	}
+	der, err := x509.CreateCertificate(nil, tmpl, tmpl, key.PublicKey(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
 const clientCertificatePEM = `
 -----BEGIN CERTIFICATE-----
 MIIB7zCCAVigAwIBAgIQXBnBiWWDVW/cC8m5k5/pvDANBgkqhkiG9w0BAQsFADAS
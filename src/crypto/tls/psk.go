@@ -0,0 +1,370 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/internal/fips140/tls13"
+	"errors"
+	"hash"
+	"slices"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// ExternalPSK is a pre-shared key provisioned out of band, used in place of
+// certificates to authenticate TLS 1.3 connections. See [Config.ExternalPSKs].
+type ExternalPSK struct {
+	// Identity is the label the peers use to refer to Key. It must not be
+	// empty, and must be unique across the keys known to the server.
+	Identity []byte
+
+	// Key is the secret. It should be generated at random and should have at
+	// least 128 bits of entropy, as low-entropy keys are exposed to offline
+	// dictionary attacks. See RFC 9257, Section 6.
+	Key []byte
+
+	// Hash is the hash function associated with Key, either crypto.SHA256 or
+	// crypto.SHA384. If zero, crypto.SHA256 is used.
+	//
+	// Unless Import is set, the key can only be used with cipher suites whose
+	// hash function is Hash.
+	Hash crypto.Hash
+
+	// Import, if true, imports the key for TLS 1.3 as specified in RFC 9258,
+	// instead of using it directly. A distinct PSK is derived for each TLS 1.3
+	// cipher suite hash, bound to Identity and Context, and offered with an
+	// ImportedIdentity structure as its identity.
+	//
+	// Both peers must agree on whether a key is imported.
+	Import bool
+
+	// Context is the optional context of the ImportedIdentity when Import is
+	// set, for example to bind the key to a specific pair of peers. It is
+	// ignored otherwise.
+	Context []byte
+}
+
+// TLS KDF identifiers for the target_kdf field of an ImportedIdentity. See
+// RFC 9258, Section 10.
+const (
+	kdfHKDFSHA256 uint16 = 0x0001
+	kdfHKDFSHA384 uint16 = 0x0002
+)
+
+// externalPSK is an ExternalPSK, or a PSK imported from it, ready for use with
+// cipher suites of one hash function.
+type externalPSK struct {
+	source      *ExternalPSK
+	identity    []byte // the identity sent on the wire
+	hash        crypto.Hash
+	earlySecret *tls13.EarlySecret
+	binderKey   []byte
+}
+
+// binder returns the PSK binder over transcript. See RFC 8446, Section 4.2.11.2.
+func (psk *externalPSK) binder(transcript hash.Hash) []byte {
+	return (&cipherSuiteTLS13{hash: psk.hash}).finishedHash(psk.binderKey, transcript)
+}
+
+// tls13PSKs returns the PSKs derived from p, one for each hash function they
+// can be used with.
+func (p *ExternalPSK) tls13PSKs() ([]*externalPSK, error) {
+	h := p.Hash
+	if h == 0 {
+		h = crypto.SHA256
+	}
+	if h != crypto.SHA256 && h != crypto.SHA384 {
+		return nil, errors.New("tls: unsupported ExternalPSK hash function")
+	}
+	if len(p.Identity) == 0 || len(p.Identity) > 0xffff || len(p.Context) > 0xffff {
+		return nil, errors.New("tls: invalid ExternalPSK identity")
+	}
+	if len(p.Key) == 0 {
+		return nil, errors.New("tls: empty ExternalPSK key")
+	}
+
+	if !p.Import {
+		earlySecret := tls13.NewEarlySecret(h.New, p.Key)
+		return []*externalPSK{{
+			source:      p,
+			identity:    p.Identity,
+			hash:        h,
+			earlySecret: earlySecret,
+			binderKey:   earlySecret.ExternalBinderKey(),
+		}}, nil
+	}
+
+	// See RFC 9258, Section 5.1.
+	epskx, err := hkdf.Extract(h.New, p.Key, nil)
+	if err != nil {
+		return nil, err
+	}
+	var psks []*externalPSK
+	for _, target := range []crypto.Hash{crypto.SHA256, crypto.SHA384} {
+		kdf := kdfHKDFSHA256
+		if target == crypto.SHA384 {
+			kdf = kdfHKDFSHA384
+		}
+		var b cryptobyte.Builder
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(p.Identity)
+		})
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddBytes(p.Context)
+		})
+		b.AddUint16(VersionTLS13) // target_protocol
+		b.AddUint16(kdf)
+		identity, err := b.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		identityHash := h.New()
+		identityHash.Write(identity)
+		ipskx := tls13.ExpandLabel(h.New, epskx, "derived psk", identityHash.Sum(nil), target.Size())
+
+		earlySecret := tls13.NewEarlySecret(target.New, ipskx)
+		psks = append(psks, &externalPSK{
+			source:      p,
+			identity:    identity,
+			hash:        target,
+			earlySecret: earlySecret,
+			binderKey:   earlySecret.ImportedBinderKey(),
+		})
+	}
+	return psks, nil
+}
+
+// parseImportedIdentity parses a TLS 1.3 ImportedIdentity, as specified in
+// RFC 9258, Section 5.1.
+func parseImportedIdentity(identity []byte) (externalIdentity, context []byte, ok bool) {
+	s := cryptobyte.String(identity)
+	var protocol, kdf uint16
+	if !s.ReadUint16LengthPrefixed((*cryptobyte.String)(&externalIdentity)) ||
+		len(externalIdentity) == 0 ||
+		!s.ReadUint16LengthPrefixed((*cryptobyte.String)(&context)) ||
+		!s.ReadUint16(&protocol) || !s.ReadUint16(&kdf) || !s.Empty() {
+		return nil, nil, false
+	}
+	if protocol != VersionTLS13 || kdf != kdfHKDFSHA256 && kdf != kdfHKDFSHA384 {
+		return nil, nil, false
+	}
+	return externalIdentity, context, true
+}
+
+// offerExternalPSKs adds the pre_shared_key extension for the configured
+// external PSKs to hello, and returns the offered PSKs in the same order as
+// hello.pskIdentities.
+func (c *Conn) offerExternalPSKs(hello *clientHelloMsg) ([]*externalPSK, error) {
+	if len(c.config.ExternalPSKs) == 0 {
+		return nil, nil
+	}
+	if hello.supportedVersions[0] != VersionTLS13 {
+		return nil, errors.New("tls: ExternalPSKs require TLS 1.3")
+	}
+	if hello.encryptedClientHello != nil {
+		return nil, errors.New("tls: ExternalPSKs can't be used with EncryptedClientHelloConfigList")
+	}
+
+	var offered []*externalPSK
+	for i := range c.config.ExternalPSKs {
+		psks, err := c.config.ExternalPSKs[i].tls13PSKs()
+		if err != nil {
+			return nil, err
+		}
+		for _, psk := range psks {
+			if !slices.ContainsFunc(hello.cipherSuites, func(id uint16) bool {
+				suite := cipherSuiteTLS13ByID(id)
+				return suite != nil && suite.hash == psk.hash
+			}) {
+				continue
+			}
+			offered = append(offered, psk)
+		}
+	}
+	if len(offered) == 0 {
+		return nil, errors.New("tls: no cipher suite is compatible with ExternalPSKs")
+	}
+
+	// Always require (EC)DHE for forward secrecy. See RFC 8446, Section 4.2.9.
+	hello.pskModes = []uint8{pskModeDHE}
+	for _, psk := range offered {
+		// obfuscated_ticket_age is zero for external PSKs.
+		hello.pskIdentities = append(hello.pskIdentities, pskIdentity{label: psk.identity})
+		hello.pskBinders = append(hello.pskBinders, make([]byte, psk.hash.Size()))
+	}
+	if err := computeExternalPSKBinders(hello, offered, func(h crypto.Hash) (hash.Hash, error) {
+		return h.New(), nil
+	}); err != nil {
+		return nil, err
+	}
+	return offered, nil
+}
+
+// computeExternalPSKBinders sets the binders of m for psks, which must match
+// m.pskIdentities. newTranscript returns the transcript preceding m for the
+// given hash function.
+func computeExternalPSKBinders(m *clientHelloMsg, psks []*externalPSK, newTranscript func(crypto.Hash) (hash.Hash, error)) error {
+	helloBytes, err := m.marshalWithoutBinders()
+	if err != nil {
+		return err
+	}
+	binders := make([][]byte, len(psks))
+	for i, psk := range psks {
+		transcript, err := newTranscript(psk.hash)
+		if err != nil {
+			return err
+		}
+		transcript.Write(helloBytes)
+		binders[i] = psk.binder(transcript)
+	}
+	return m.updateBinders(binders)
+}
+
+// updateExternalPSKsAfterHRR drops the offered external PSKs that can't be
+// used with the cipher suite selected by a HelloRetryRequest, and recomputes
+// the binders of hello, the second ClientHello. chHash is the hash of the
+// first ClientHello.
+func (hs *clientHandshakeStateTLS13) updateExternalPSKsAfterHRR(hello *clientHelloMsg, chHash []byte) error {
+	c := hs.c
+
+	hs.externalPSKs = slices.DeleteFunc(hs.externalPSKs, func(psk *externalPSK) bool {
+		return psk.hash != hs.suite.hash
+	})
+	if len(hs.externalPSKs) == 0 {
+		c.sendAlert(alertHandshakeFailure)
+		return errors.New("tls: server selected a cipher suite incompatible with ExternalPSKs")
+	}
+	hello.pskIdentities = hello.pskIdentities[:0]
+	hello.pskBinders = hello.pskBinders[:0]
+	for _, psk := range hs.externalPSKs {
+		hello.pskIdentities = append(hello.pskIdentities, pskIdentity{label: psk.identity})
+		hello.pskBinders = append(hello.pskBinders, make([]byte, psk.hash.Size()))
+	}
+	return computeExternalPSKBinders(hello, hs.externalPSKs, func(h crypto.Hash) (hash.Hash, error) {
+		transcript := h.New()
+		transcript.Write([]byte{typeMessageHash, 0, 0, uint8(len(chHash))})
+		transcript.Write(chHash)
+		if err := transcriptMsg(hs.serverHello, transcript); err != nil {
+			return nil, err
+		}
+		return transcript, nil
+	})
+}
+
+// checkForExternalPSK selects the first external PSK offered by the client
+// that the server knows, if any. It must run before checkForResumption.
+func (hs *serverHandshakeStateTLS13) checkForExternalPSK() error {
+	c := hs.c
+
+	if len(c.config.ExternalPSKs) == 0 && c.config.GetExternalPSK == nil {
+		return nil
+	}
+	if !slices.Contains(hs.clientHello.pskModes, pskModeDHE) {
+		return nil
+	}
+	if len(hs.clientHello.pskIdentities) != len(hs.clientHello.pskBinders) {
+		c.sendAlert(alertIllegalParameter)
+		return errors.New("tls: invalid or missing PSK binders")
+	}
+
+	for i, identity := range hs.clientHello.pskIdentities {
+		if i >= maxClientPSKIdentities {
+			break
+		}
+
+		psk, err := hs.lookupExternalPSK(identity.label)
+		if err != nil {
+			c.sendAlert(alertInternalError)
+			return err
+		}
+		if psk == nil {
+			continue
+		}
+
+		// Clone the transcript in case a HelloRetryRequest was recorded.
+		transcript := cloneHash(hs.transcript, hs.suite.hash)
+		if transcript == nil {
+			c.sendAlert(alertInternalError)
+			return errors.New("tls: internal error: failed to clone hash")
+		}
+		clientHelloBytes, err := hs.clientHello.marshalWithoutBinders()
+		if err != nil {
+			c.sendAlert(alertInternalError)
+			return err
+		}
+		transcript.Write(clientHelloBytes)
+		if !hmac.Equal(hs.clientHello.pskBinders[i], psk.binder(transcript)) {
+			c.sendAlert(alertDecryptError)
+			return errors.New("tls: invalid PSK binder")
+		}
+
+		c.externalPSKIdentity = psk.source.Identity
+		hs.earlySecret = psk.earlySecret
+		hs.hello.selectedIdentityPresent = true
+		hs.hello.selectedIdentity = uint16(i)
+		hs.usingPSK = true
+		return nil
+	}
+
+	return nil
+}
+
+// lookupExternalPSK returns the external PSK with the given wire identity
+// that can be used with the selected cipher suite, or nil if there is none.
+func (hs *serverHandshakeStateTLS13) lookupExternalPSK(identity []byte) (*externalPSK, error) {
+	c := hs.c
+
+	externalIdentity, context, imported := parseImportedIdentity(identity)
+	match := func(p *ExternalPSK) (*externalPSK, error) {
+		if p == nil {
+			return nil, nil
+		}
+		if p.Import {
+			if !imported || !bytes.Equal(p.Identity, externalIdentity) || !bytes.Equal(p.Context, context) {
+				return nil, nil
+			}
+		} else if !bytes.Equal(p.Identity, identity) {
+			return nil, nil
+		}
+		psks, err := p.tls13PSKs()
+		if err != nil {
+			return nil, err
+		}
+		for _, psk := range psks {
+			if psk.hash == hs.suite.hash && bytes.Equal(psk.identity, identity) {
+				return psk, nil
+			}
+		}
+		return nil, nil
+	}
+
+	for i := range c.config.ExternalPSKs {
+		if psk, err := match(&c.config.ExternalPSKs[i]); psk != nil || err != nil {
+			return psk, err
+		}
+	}
+	if c.config.GetExternalPSK == nil {
+		return nil, nil
+	}
+	p, err := c.config.GetExternalPSK(identity)
+	if err != nil {
+		return nil, err
+	}
+	if psk, err := match(p); psk != nil || err != nil {
+		return psk, err
+	}
+	if !imported {
+		return nil, nil
+	}
+	p, err = c.config.GetExternalPSK(externalIdentity)
+	if err != nil {
+		return nil, err
+	}
+	return match(p)
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExternalPSK(t *testing.T) {
+	psk := ExternalPSK{
+		Identity: []byte("client1"),
+		Key:      bytes.Repeat([]byte{0x42}, 32),
+	}
+	sha384PSK := ExternalPSK{
+		Identity: []byte("client384"),
+		Key:      bytes.Repeat([]byte{0x43}, 48),
+		Hash:     crypto.SHA384,
+	}
+	importedPSK := ExternalPSK{
+		Identity: []byte("client1"),
+		Key:      bytes.Repeat([]byte{0x42}, 32),
+		Import:   true,
+		Context:  []byte("context"),
+	}
+
+	for _, test := range []struct {
+		name         string
+		client       func(*Config)
+		server       func(*Config)
+		wantErr      string
+		wantIdentity string
+	}{
+		{
+			name:         "Direct",
+			client:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk} },
+			server:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk} },
+			wantIdentity: "client1",
+		},
+		{
+			name:         "SHA384",
+			client:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk, sha384PSK} },
+			server:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk, sha384PSK} },
+			wantIdentity: "client1",
+		},
+		{
+			name: "SHA384Imported",
+			client: func(c *Config) {
+				p := sha384PSK
+				p.Import = true
+				c.ExternalPSKs = []ExternalPSK{p}
+			},
+			server: func(c *Config) {
+				p := sha384PSK
+				p.Import = true
+				c.ExternalPSKs = []ExternalPSK{p}
+			},
+			wantIdentity: "client384",
+		},
+		{
+			name:   "SHA384SuiteMismatch",
+			client: func(c *Config) { c.ExternalPSKs = []ExternalPSK{sha384PSK} },
+			server: func(c *Config) { c.ExternalPSKs = []ExternalPSK{sha384PSK} },
+			// Only SHA-256 cipher suites are preferred by the server, so the
+			// SHA-384 key can't be used directly.
+			wantErr: "server did not select an external PSK",
+		},
+		{
+			name:         "Imported",
+			client:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{importedPSK} },
+			server:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{importedPSK} },
+			wantIdentity: "client1",
+		},
+		{
+			name:   "Callback",
+			client: func(c *Config) { c.ExternalPSKs = []ExternalPSK{importedPSK} },
+			server: func(c *Config) {
+				c.GetExternalPSK = func(identity []byte) (*ExternalPSK, error) {
+					if string(identity) == "client1" {
+						return &importedPSK, nil
+					}
+					return nil, nil
+				}
+			},
+			wantIdentity: "client1",
+		},
+		{
+			name:   "CallbackError",
+			client: func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk} },
+			server: func(c *Config) {
+				c.GetExternalPSK = func(identity []byte) (*ExternalPSK, error) {
+					return nil, errors.New("lookup failed")
+				}
+			},
+			wantErr: "lookup failed",
+		},
+		{
+			name:         "HelloRetryRequest",
+			client:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk, sha384PSK} },
+			server:       func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk}; c.CurvePreferences = []CurveID{CurveP384} },
+			wantIdentity: "client1",
+		},
+		{
+			name:   "ImportMismatch",
+			client: func(c *Config) { c.ExternalPSKs = []ExternalPSK{importedPSK} },
+			server: func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk} },
+			// The server falls back to certificates, which the client refuses.
+			wantErr: "server did not select an external PSK",
+		},
+		{
+			name:   "WrongKey",
+			client: func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk} },
+			server: func(c *Config) {
+				c.ExternalPSKs = []ExternalPSK{{Identity: psk.Identity, Key: bytes.Repeat([]byte{0x41}, 32)}}
+			},
+			wantErr: "invalid PSK binder",
+		},
+		{
+			name: "TLS12Only",
+			client: func(c *Config) {
+				c.ExternalPSKs = []ExternalPSK{psk}
+				c.MinVersion = VersionTLS12
+				c.MaxVersion = VersionTLS12
+			},
+			server:  func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk} },
+			wantErr: "ExternalPSKs require TLS 1.3",
+		},
+		{
+			name:    "EmptyKey",
+			client:  func(c *Config) { c.ExternalPSKs = []ExternalPSK{{Identity: []byte("x")}} },
+			server:  func(c *Config) { c.ExternalPSKs = []ExternalPSK{psk} },
+			wantErr: "empty ExternalPSK key",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			clientConfig := &Config{
+				Time:       testConfig.Time,
+				MinVersion: VersionTLS13,
+				ServerName: "example.golang",
+			}
+			serverConfig := testConfig.Clone()
+			serverConfig.MinVersion = VersionTLS13
+			test.client(clientConfig)
+			test.server(serverConfig)
+
+			ss, cs, err := testHandshake(t, clientConfig, serverConfig)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("handshake error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			for _, st := range []ConnectionState{cs, ss} {
+				if string(st.ExternalPSKIdentity) != test.wantIdentity {
+					t.Errorf("ExternalPSKIdentity = %q, want %q", st.ExternalPSKIdentity, test.wantIdentity)
+				}
+				if len(st.PeerCertificates) != 0 {
+					t.Errorf("got %d peer certificates, want none", len(st.PeerCertificates))
+				}
+				if st.DidResume {
+					t.Errorf("DidResume = true, want false")
+				}
+			}
+		})
+	}
+}
+
+func TestImportedIdentity(t *testing.T) {
+	p := &ExternalPSK{
+		Identity: []byte("identity"),
+		Key:      []byte("key"),
+		Import:   true,
+		Context:  []byte("context"),
+	}
+	psks, err := p.tls13PSKs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(psks) != 2 {
+		t.Fatalf("got %d imported PSKs, want 2", len(psks))
+	}
+	for _, psk := range psks {
+		identity, context, ok := parseImportedIdentity(psk.identity)
+		if !ok || string(identity) != "identity" || string(context) != "context" {
+			t.Errorf("parseImportedIdentity(%x) = %q, %q, %v", psk.identity, identity, context, ok)
+		}
+	}
+	if bytes.Equal(psks[0].identity, psks[1].identity) {
+		t.Errorf("imported identities for different hashes are equal")
+	}
+	if _, _, ok := parseImportedIdentity([]byte("identity")); ok {
+		t.Errorf("parseImportedIdentity accepted a plain identity")
+	}
+}
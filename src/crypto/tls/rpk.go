@@ -0,0 +1,130 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/mldsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// CertificateType is a format in which a peer can present its credentials,
+// negotiated with the client_certificate_type and server_certificate_type
+// extensions. See RFC 7250.
+type CertificateType uint8
+
+const (
+	// CertificateTypeX509 is an X.509 certificate chain.
+	CertificateTypeX509 CertificateType = 0
+	// CertificateTypeRawPublicKey is a bare DER-encoded SubjectPublicKeyInfo.
+	CertificateTypeRawPublicKey CertificateType = 2
+)
+
+// acceptsCertificateType reports whether t is in types, where an empty list
+// stands for CertificateTypeX509 only.
+func acceptsCertificateType(types []CertificateType, t CertificateType) bool {
+	if len(types) == 0 {
+		return t == CertificateTypeX509
+	}
+	return slices.Contains(types, t)
+}
+
+// negotiateCertificateType returns the first of our preferred types that the
+// peer also supports. If the peer did not send the corresponding extension,
+// only CertificateTypeX509 can be used.
+func negotiateCertificateType(ours, peers []CertificateType) (CertificateType, bool) {
+	if len(peers) == 0 {
+		return CertificateTypeX509, acceptsCertificateType(ours, CertificateTypeX509)
+	}
+	if len(ours) == 0 {
+		ours = []CertificateType{CertificateTypeX509}
+	}
+	for _, t := range ours {
+		if slices.Contains(peers, t) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// rawPublicKeyCertificate returns the Certificate message contents that
+// present the public key of cert as a raw public key.
+func rawPublicKeyCertificate(cert *Certificate) (Certificate, error) {
+	priv, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return Certificate{}, fmt.Errorf("tls: certificate private key (%T) does not implement crypto.Signer", cert.PrivateKey)
+	}
+	spki, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		return Certificate{}, errors.New("tls: failed to marshal raw public key: " + err.Error())
+	}
+	return Certificate{Certificate: [][]byte{spki}}, nil
+}
+
+// processRawPublicKey parses and authenticates the raw public key sent by the
+// peer in a Certificate message. If verify is true, the key must be accepted
+// by Config.VerifyRawPublicKey.
+func (c *Conn) processRawPublicKey(certificates [][]byte, verify bool) error {
+	if len(certificates) != 1 {
+		c.sendAlert(alertDecodeError)
+		return errors.New("tls: peer sent an invalid raw public key certificate message")
+	}
+	pub, err := x509.ParsePKIXPublicKey(certificates[0])
+	if err != nil {
+		c.sendAlert(alertDecodeError)
+		return errors.New("tls: failed to parse raw public key: " + err.Error())
+	}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if max, ok := checkKeySize(pub.N.BitLen()); !ok {
+			c.sendAlert(alertBadCertificate)
+			return fmt.Errorf("tls: peer sent an RSA raw public key larger than %d bits", max)
+		}
+	case *ecdsa.PublicKey, ed25519.PublicKey, *mldsa.PublicKey:
+	default:
+		c.sendAlert(alertUnsupportedCertificate)
+		return fmt.Errorf("tls: peer sent a raw public key of unsupported type %T", pub)
+	}
+
+	if c.config.VerifyRawPublicKey != nil {
+		if err := c.config.VerifyRawPublicKey(pub); err != nil {
+			c.sendAlert(alertBadCertificate)
+			return err
+		}
+	} else if verify {
+		c.sendAlert(alertBadCertificate)
+		return errors.New("tls: peer sent a raw public key, but Config.VerifyRawPublicKey is nil")
+	}
+
+	c.peerRawPublicKey = pub
+	return nil
+}
+
+// processRawPublicKeyFromClient is the raw public key counterpart of
+// processCertsFromClient.
+func (c *Conn) processRawPublicKeyFromClient(certificate Certificate) error {
+	if len(certificate.Certificate) == 0 {
+		if requiresClientCert(c.config.ClientAuth) {
+			c.sendAlert(alertCertificateRequired)
+			return errors.New("tls: client didn't provide a certificate")
+		}
+		return nil
+	}
+	return c.processRawPublicKey(certificate.Certificate, c.config.ClientAuth >= VerifyClientCertIfGiven)
+}
+
+// peerPublicKey returns the public key the peer authenticated with.
+func (c *Conn) peerPublicKey() crypto.PublicKey {
+	if c.peerRawPublicKey != nil {
+		return c.peerRawPublicKey
+	}
+	return c.peerCertificates[0].PublicKey
+}
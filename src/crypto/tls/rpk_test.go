@@ -0,0 +1,219 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRawPublicKeys(t *testing.T) {
+	serverKey := testEd25519PrivateKey
+	clientKey := testECDSAPrivateKey
+	serverCert := Certificate{PrivateKey: serverKey}
+	clientCert := Certificate{PrivateKey: clientKey}
+
+	acceptKey := func(want crypto.PublicKey) func(crypto.PublicKey) error {
+		return func(pub crypto.PublicKey) error {
+			if k, ok := pub.(interface{ Equal(crypto.PublicKey) bool }); !ok || !k.Equal(want) {
+				return errors.New("unknown key")
+			}
+			return nil
+		}
+	}
+
+	for _, test := range []struct {
+		name       string
+		client     func(*Config)
+		server     func(*Config)
+		wantErr    string
+		wantServer bool // client sees the server raw public key
+		wantClient bool // server sees the client raw public key
+	}{
+		{
+			name: "Server",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = acceptKey(serverKey.Public())
+			},
+			server: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey, CertificateTypeX509}
+				c.Certificates = []Certificate{serverCert}
+			},
+			wantServer: true,
+		},
+		{
+			name: "ServerFallbackToX509",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey, CertificateTypeX509}
+				c.InsecureSkipVerify = true
+			},
+			server: func(c *Config) {},
+		},
+		{
+			name: "ServerRejected",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = acceptKey(clientKey.Public())
+			},
+			server: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.Certificates = []Certificate{serverCert}
+			},
+			wantErr: "unknown key",
+		},
+		{
+			name: "ServerNoVerifier",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+			},
+			server: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.Certificates = []Certificate{serverCert}
+			},
+			wantErr: "VerifyRawPublicKey is nil",
+		},
+		{
+			name: "ServerNoCommonType",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = acceptKey(serverKey.Public())
+			},
+			server:  func(c *Config) {},
+			wantErr: "no server certificate type supported",
+		},
+		{
+			name: "Mutual",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.ClientCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = acceptKey(serverKey.Public())
+				c.Certificates = []Certificate{clientCert}
+			},
+			server: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.ClientCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.Certificates = []Certificate{serverCert}
+				c.ClientAuth = RequireAnyClientCert
+				c.VerifyRawPublicKey = acceptKey(clientKey.Public())
+			},
+			wantServer: true,
+			wantClient: true,
+		},
+		{
+			name: "ClientMissing",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.ClientCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = acceptKey(serverKey.Public())
+			},
+			server: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.ClientCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.Certificates = []Certificate{serverCert}
+				c.ClientAuth = RequireAnyClientCert
+			},
+			wantErr: "client didn't provide a certificate",
+		},
+		{
+			name: "ClientRejected",
+			client: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.ClientCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.VerifyRawPublicKey = acceptKey(serverKey.Public())
+				c.Certificates = []Certificate{clientCert}
+			},
+			server: func(c *Config) {
+				c.ServerCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.ClientCertificateTypes = []CertificateType{CertificateTypeRawPublicKey}
+				c.Certificates = []Certificate{serverCert}
+				c.ClientAuth = RequireAndVerifyClientCert
+				c.VerifyRawPublicKey = acceptKey(serverKey.Public())
+			},
+			wantErr: "unknown key",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			clientConfig := &Config{
+				Time:       testConfig.Time,
+				MinVersion: VersionTLS13,
+				ServerName: "example.golang",
+			}
+			serverConfig := testConfig.Clone()
+			serverConfig.MinVersion = VersionTLS13
+			test.client(clientConfig)
+			test.server(serverConfig)
+
+			if test.wantErr != "" {
+				// In TLS 1.3 the client completes its side of the handshake
+				// before the server can reject its certificate, so check the
+				// error on both sides explicitly.
+				c, s := localPipe(t)
+				errChan := make(chan error, 1)
+				go func() {
+					cli := Client(c, clientConfig)
+					err := cli.Handshake()
+					if err == nil {
+						_, err = cli.Read(make([]byte, 1))
+					}
+					c.Close()
+					errChan <- err
+				}()
+				err := Server(s, serverConfig).Handshake()
+				s.Close()
+				err = errors.Join(err, <-errChan)
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("handshake error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			ss, cs, err := testHandshake(t, clientConfig, serverConfig)
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+			if test.wantServer {
+				if pub, ok := cs.PeerRawPublicKey.(ed25519.PublicKey); !ok || !pub.Equal(serverKey.Public()) {
+					t.Errorf("client PeerRawPublicKey = %v, want the server key", cs.PeerRawPublicKey)
+				}
+				if len(cs.PeerCertificates) != 0 {
+					t.Errorf("client got %d peer certificates, want none", len(cs.PeerCertificates))
+				}
+			} else if cs.PeerRawPublicKey != nil || len(cs.PeerCertificates) == 0 {
+				t.Errorf("client PeerRawPublicKey = %v with %d peer certificates, want X.509", cs.PeerRawPublicKey, len(cs.PeerCertificates))
+			}
+			if test.wantClient {
+				if ss.PeerRawPublicKey == nil || !clientKey.PublicKey.Equal(ss.PeerRawPublicKey) {
+					t.Errorf("server PeerRawPublicKey = %v, want the client key", ss.PeerRawPublicKey)
+				}
+			} else if ss.PeerRawPublicKey != nil {
+				t.Errorf("server PeerRawPublicKey = %v, want nil", ss.PeerRawPublicKey)
+			}
+		})
+	}
+}
+
+func TestNegotiateCertificateType(t *testing.T) {
+	x509, rpk := CertificateTypeX509, CertificateTypeRawPublicKey
+	for _, test := range []struct {
+		ours, peers []CertificateType
+		want        CertificateType
+		ok          bool
+	}{
+		{nil, nil, x509, true},
+		{[]CertificateType{rpk}, nil, x509, false},
+		{[]CertificateType{rpk, x509}, nil, x509, true},
+		{nil, []CertificateType{rpk, x509}, x509, true},
+		{nil, []CertificateType{rpk}, 0, false},
+		{[]CertificateType{rpk, x509}, []CertificateType{x509, rpk}, rpk, true},
+	} {
+		got, ok := negotiateCertificateType(test.ours, test.peers)
+		if got != test.want || ok != test.ok {
+			t.Errorf("negotiateCertificateType(%v, %v) = %v, %v; want %v, %v", test.ours, test.peers, got, ok, test.want, test.ok)
+		}
+	}
+}
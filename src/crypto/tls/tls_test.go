@@ -12,6 +12,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/internal/hpke"
+	"crypto/mldsa"
 	"crypto/rand"
 	"crypto/tls/internal/fips140tls"
 	"crypto/x509"
@@ -811,7 +812,7 @@ func TestWarningAlertFlood(t *testing.T) {
 }
 
 func TestCloneFuncFields(t *testing.T) {
-	const expectedCount = 10
+	const expectedCount = 12
 	called := 0
 
 	c1 := Config{
@@ -855,6 +856,14 @@ func TestCloneFuncFields(t *testing.T) {
 			called |= 1 << 9
 			return nil, nil
 		},
+		VerifyRawPublicKey: func(crypto.PublicKey) error {
+			called |= 1 << 10
+			return nil
+		},
+		GetExternalPSK: func([]byte) (*ExternalPSK, error) {
+			called |= 1 << 11
+			return nil, nil
+		},
 	}
 
 	c2 := c1.Clone()
@@ -869,6 +878,8 @@ func TestCloneFuncFields(t *testing.T) {
 	c2.WrapSession(ConnectionState{}, nil)
 	c2.EncryptedClientHelloRejectionVerify(ConnectionState{})
 	c2.GetEncryptedClientHelloKeys(nil)
+	c2.VerifyRawPublicKey(nil)
+	c2.GetExternalPSK(nil)
 
 	if called != (1<<expectedCount)-1 {
 		t.Fatalf("expected %d calls but saw calls %b", expectedCount, called)
@@ -887,7 +898,7 @@ func TestCloneNonFuncFields(t *testing.T) {
 		switch fn := typ.Field(i).Name; fn {
 		case "Rand":
 			f.Set(reflect.ValueOf(io.Reader(os.Stdin)))
-		case "Time", "GetCertificate", "GetConfigForClient", "VerifyPeerCertificate", "VerifyConnection", "GetClientCertificate", "WrapSession", "UnwrapSession", "EncryptedClientHelloRejectionVerify", "GetEncryptedClientHelloKeys":
+		case "Time", "GetCertificate", "GetConfigForClient", "VerifyPeerCertificate", "VerifyConnection", "GetClientCertificate", "WrapSession", "UnwrapSession", "EncryptedClientHelloRejectionVerify", "GetEncryptedClientHelloKeys", "VerifyRawPublicKey", "GetExternalPSK":
 			// DeepEqual can't compare functions. If you add a
 			// function field to this list, you must also change
 			// TestCloneFuncFields to ensure that the func field is
@@ -900,6 +911,14 @@ func TestCloneNonFuncFields(t *testing.T) {
 			f.Set(reflect.ValueOf(map[string]*Certificate{"a": nil}))
 		case "RootCAs", "ClientCAs":
 			f.Set(reflect.ValueOf(x509.NewCertPool()))
+		case "Revocation":
+			f.Set(reflect.ValueOf(&x509.RevocationOptions{Policy: x509.RevocationHardFail}))
+		case "CertificateTransparency":
+			f.Set(reflect.ValueOf(&x509.CTOptions{MinSCTs: 3}))
+		case "ServerCertificateTypes", "ClientCertificateTypes":
+			f.Set(reflect.ValueOf([]CertificateType{CertificateTypeRawPublicKey}))
+		case "ExternalPSKs":
+			f.Set(reflect.ValueOf([]ExternalPSK{{Identity: []byte("a"), Key: []byte("b")}}))
 		case "ClientSessionCache":
 			f.Set(reflect.ValueOf(NewLRUClientSessionCache(10)))
 		case "KeyLogWriter":
@@ -2110,6 +2129,51 @@ func TestHandshakeMLKEM(t *testing.T) {
 	}
 }
 
+func TestHandshakeMLDSA(t *testing.T) {
+	t.Setenv("GODEBUG", "tlsmldsa=1")
+
+	for _, sigAlg := range []SignatureScheme{MLDSA44, MLDSA65, MLDSA87} {
+		t.Run(sigAlg.String(), func(t *testing.T) {
+			cert := testMLDSACertificate(t, sigAlg)
+
+			serverConfig := testConfig.Clone()
+			serverConfig.Certificates = []Certificate{cert}
+			serverConfig.ClientAuth = RequireAnyClientCert
+			clientConfig := testConfig.Clone()
+			clientConfig.Certificates = []Certificate{cert}
+
+			ss, cs, err := testHandshake(t, clientConfig, serverConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cs.Version != VersionTLS13 {
+				t.Errorf("got version %x, expected TLS 1.3", cs.Version)
+			}
+			if _, ok := cs.PeerCertificates[0].PublicKey.(*mldsa.PublicKey); !ok {
+				t.Errorf("server certificate has key type %T", cs.PeerCertificates[0].PublicKey)
+			}
+			if _, ok := ss.PeerCertificates[0].PublicKey.(*mldsa.PublicKey); !ok {
+				t.Errorf("client certificate has key type %T", ss.PeerCertificates[0].PublicKey)
+			}
+
+			serverConfig.ClientAuth = NoClientCert
+			serverConfig.MaxVersion = VersionTLS12
+			if _, _, err := testHandshake(t, clientConfig, serverConfig); err == nil {
+				t.Error("ML-DSA certificate was used in TLS 1.2")
+			}
+		})
+	}
+
+	t.Run("GODEBUG", func(t *testing.T) {
+		t.Setenv("GODEBUG", "tlsmldsa=0")
+		serverConfig := testConfig.Clone()
+		serverConfig.Certificates = []Certificate{testMLDSACertificate(t, MLDSA65)}
+		if _, _, err := testHandshake(t, testConfig, serverConfig); err == nil {
+			t.Error("ML-DSA certificate was used with tlsmldsa=0")
+		}
+	})
+}
+
 func TestX509KeyPairPopulateCertificate(t *testing.T) {
 	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
@@ -0,0 +1,424 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// This file implements verification of Certificate Transparency Signed
+// Certificate Timestamps (SCTs), as specified in RFC 6962.
+
+var (
+	oidExtensionSCTList     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+	oidOCSPExtensionSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}
+)
+
+const (
+	sctVersionV1 = 0
+
+	sctSignatureTypeCertificateTimestamp = 0
+
+	sctEntryTypeX509    = 0
+	sctEntryTypePrecert = 1
+
+	// TLS HashAlgorithm and SignatureAlgorithm values, from RFC 5246,
+	// Section 7.4.1.4.1, as used by RFC 6962.
+	sctHashSHA256     = 4
+	sctSignatureRSA   = 1
+	sctSignatureECDSA = 3
+)
+
+// SignedCertificateTimestamp is a version 1 Signed Certificate Timestamp, as
+// specified in RFC 6962, Section 3.2.
+type SignedCertificateTimestamp struct {
+	// Raw contains the complete TLS encoding of the SCT.
+	Raw []byte
+
+	// LogID is the SHA-256 hash of the log's DER encoded public key.
+	LogID [32]byte
+	// Timestamp is the time at which the log issued the SCT, with
+	// millisecond precision.
+	Timestamp time.Time
+	// Extensions contains the opaque CtExtensions field.
+	Extensions []byte
+
+	// HashAlgorithm and SignatureAlgorithm are the TLS 1.2 algorithm
+	// identifiers of the signature, from RFC 5246, Section 7.4.1.4.1.
+	HashAlgorithm      uint8
+	SignatureAlgorithm uint8
+	// Signature is the log's signature.
+	Signature []byte
+}
+
+// ParseSignedCertificateTimestamp parses a single SCT in its TLS encoding, as
+// found in [crypto/tls.ConnectionState.SignedCertificateTimestamps].
+func ParseSignedCertificateTimestamp(b []byte) (*SignedCertificateTimestamp, error) {
+	s := cryptobyte.String(b)
+	sct, err := parseSCT(&s)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Empty() {
+		return nil, errors.New("x509: trailing data after SCT")
+	}
+	return sct, nil
+}
+
+func parseSCT(s *cryptobyte.String) (*SignedCertificateTimestamp, error) {
+	sct := &SignedCertificateTimestamp{Raw: *s}
+	var version uint8
+	var logID []byte
+	var timestamp uint64
+	var exts, sig cryptobyte.String
+	if !s.ReadUint8(&version) {
+		return nil, errors.New("x509: malformed SCT")
+	}
+	if version != sctVersionV1 {
+		return nil, fmt.Errorf("x509: unsupported SCT version %d", version)
+	}
+	if !s.ReadBytes(&logID, 32) ||
+		!s.ReadUint64(&timestamp) ||
+		!s.ReadUint16LengthPrefixed(&exts) ||
+		!s.ReadUint8(&sct.HashAlgorithm) ||
+		!s.ReadUint8(&sct.SignatureAlgorithm) ||
+		!s.ReadUint16LengthPrefixed(&sig) {
+		return nil, errors.New("x509: malformed SCT")
+	}
+	sct.Raw = sct.Raw[:len(sct.Raw)-len(*s)]
+	copy(sct.LogID[:], logID)
+	sct.Timestamp = time.UnixMilli(int64(timestamp))
+	sct.Extensions = exts
+	sct.Signature = sig
+	return sct, nil
+}
+
+// parseSCTList parses a SignedCertificateTimestampList, as found in the
+// embedded and OCSP SCT extensions.
+func parseSCTList(b []byte) ([]*SignedCertificateTimestamp, error) {
+	s := cryptobyte.String(b)
+	var list cryptobyte.String
+	if !s.ReadUint16LengthPrefixed(&list) || !s.Empty() {
+		return nil, errors.New("x509: malformed SCT list")
+	}
+	var scts []*SignedCertificateTimestamp
+	for !list.Empty() {
+		var raw cryptobyte.String
+		if !list.ReadUint16LengthPrefixed(&raw) {
+			return nil, errors.New("x509: malformed SCT list")
+		}
+		sct, err := ParseSignedCertificateTimestamp(raw)
+		if err != nil {
+			return nil, err
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
+
+// parseSCTListExtension parses the value of an SCT list extension, which is an
+// OCTET STRING wrapping a SignedCertificateTimestampList.
+func parseSCTListExtension(value []byte) ([]*SignedCertificateTimestamp, error) {
+	s := cryptobyte.String(value)
+	var list cryptobyte.String
+	if !s.ReadASN1(&list, cryptobyte_asn1.OCTET_STRING) || !s.Empty() {
+		return nil, errors.New("x509: malformed SCT list extension")
+	}
+	return parseSCTList(list)
+}
+
+// CTLog is a Certificate Transparency log trusted to issue SCTs.
+type CTLog struct {
+	// Description is a human readable name for the log.
+	Description string
+	// Operator identifies the organization running the log. Logs with the
+	// same non-empty Operator count as one operator towards
+	// CTOptions.MinOperators.
+	Operator string
+	// PublicKey is the log's public key, an *ecdsa.PublicKey on P-256 or an
+	// *rsa.PublicKey, as allowed by RFC 6962.
+	PublicKey crypto.PublicKey
+}
+
+// ID returns the log ID, the SHA-256 hash of the DER encoded PKIX public key.
+func (l *CTLog) ID() ([32]byte, error) {
+	der, err := MarshalPKIXPublicKey(l.PublicKey)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(der), nil
+}
+
+// CTOptions configures Certificate Transparency enforcement in
+// [Certificate.Verify].
+//
+// A chain satisfies the policy if the leaf certificate has at least MinSCTs
+// valid SCTs from distinct logs, run by at least MinOperators distinct
+// operators. An SCT is valid if it was issued by one of Logs, its signature
+// covers the leaf certificate, and its timestamp is not after the
+// verification time. SCTs may be embedded in the certificate, or delivered
+// out of band through the SignedCertificateTimestamps and OCSPResponses fields.
+type CTOptions struct {
+	// Logs is the list of trusted logs. SCTs from other logs are ignored.
+	Logs []*CTLog
+
+	// MinSCTs is the minimum number of valid SCTs. If zero, two are required.
+	MinSCTs int
+	// MinOperators is the minimum number of distinct log operators among the
+	// valid SCTs. If zero, two are required.
+	MinOperators int
+
+	// SignedCertificateTimestamps are SCTs in their TLS encoding delivered
+	// out of band, for example in the TLS signed_certificate_timestamp
+	// extension.
+	SignedCertificateTimestamps [][]byte
+	// OCSPResponses are DER encoded OCSP responses which may carry SCTs for
+	// the leaf in a singleExtensions SCT list. Their signature is not checked,
+	// as the SCTs themselves are signed by the logs.
+	OCSPResponses [][]byte
+}
+
+// ctChecker checks chains against CTOptions.
+type ctChecker struct {
+	opts *CTOptions
+	now  time.Time
+	logs map[[32]byte]*CTLog
+}
+
+func newCTChecker(opts *CTOptions, now time.Time) *ctChecker {
+	cc := &ctChecker{opts: opts, now: now, logs: make(map[[32]byte]*CTLog)}
+	for _, l := range opts.Logs {
+		if id, err := l.ID(); err == nil {
+			cc.logs[id] = l
+		}
+	}
+	return cc
+}
+
+func (cc *ctChecker) checkChain(chain []*Certificate) error {
+	leaf := chain[0]
+	var issuer *Certificate
+	if len(chain) > 1 {
+		issuer = chain[1]
+	}
+
+	minSCTs, minOperators := cc.opts.MinSCTs, cc.opts.MinOperators
+	if minSCTs == 0 {
+		minSCTs = 2
+	}
+	if minOperators == 0 {
+		minOperators = 2
+	}
+
+	validLogs := make(map[[32]byte]bool)
+	operators := make(map[string]bool)
+	accept := func(sct *SignedCertificateTimestamp, entry []byte) {
+		log, ok := cc.logs[sct.LogID]
+		if !ok || validLogs[sct.LogID] || sct.Timestamp.After(cc.now) {
+			return
+		}
+		if sct.verify(log, entry) != nil {
+			return
+		}
+		validLogs[sct.LogID] = true
+		operator := "log:" + string(sct.LogID[:])
+		if log.Operator != "" {
+			operator = "operator:" + log.Operator
+		}
+		operators[operator] = true
+	}
+
+	x509Entry := sctX509Entry(leaf)
+	for _, raw := range cc.opts.SignedCertificateTimestamps {
+		if sct, err := ParseSignedCertificateTimestamp(raw); err == nil {
+			accept(sct, x509Entry)
+		}
+	}
+	if issuer != nil {
+		for _, der := range cc.opts.OCSPResponses {
+			for _, sct := range ocspSCTs(der, leaf, issuer) {
+				accept(sct, x509Entry)
+			}
+		}
+		for _, ext := range leaf.Extensions {
+			if !ext.Id.Equal(oidExtensionSCTList) {
+				continue
+			}
+			scts, err := parseSCTListExtension(ext.Value)
+			if err != nil {
+				break
+			}
+			precertEntry, err := sctPrecertEntry(leaf, issuer)
+			if err != nil {
+				break
+			}
+			for _, sct := range scts {
+				accept(sct, precertEntry)
+			}
+		}
+	}
+
+	if len(validLogs) < minSCTs || len(operators) < minOperators {
+		return CertificateInvalidError{leaf, InsufficientSCTs,
+			fmt.Sprintf("%d valid SCTs from %d operators, want %d from %d", len(validLogs), len(operators), minSCTs, minOperators)}
+	}
+	return nil
+}
+
+// ocspSCTs returns the SCTs carried in the single response for leaf in the OCSP
+// response der, if any.
+func ocspSCTs(der []byte, leaf, issuer *Certificate) []*SignedCertificateTimestamp {
+	resp, err := ParseOCSPResponse(der)
+	if err != nil {
+		return nil
+	}
+	var scts []*SignedCertificateTimestamp
+	for _, sr := range resp.Responses {
+		if !sr.CertID.Matches(leaf, issuer) {
+			continue
+		}
+		for _, ext := range sr.Extensions {
+			if ext.Id.Equal(oidOCSPExtensionSCTList) {
+				list, err := parseSCTListExtension(ext.Value)
+				if err == nil {
+					scts = append(scts, list...)
+				}
+			}
+		}
+	}
+	return scts
+}
+
+// sctX509Entry returns the signed_entry of an SCT issued for a final
+// certificate, an x509_entry LogEntryType followed by the ASN.1Cert.
+func sctX509Entry(cert *Certificate) []byte {
+	var b cryptobyte.Builder
+	b.AddUint16(sctEntryTypeX509)
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(cert.Raw)
+	})
+	return b.BytesOrPanic()
+}
+
+// sctPrecertEntry returns the signed_entry of an SCT embedded in cert, a
+// precert_entry LogEntryType followed by the PreCert structure, which is
+// reconstructed by removing the SCT list extension from the TBSCertificate.
+func sctPrecertEntry(cert, issuer *Certificate) ([]byte, error) {
+	tbs, err := tbsWithoutExtension(cert.RawTBSCertificate, oidExtensionSCTList)
+	if err != nil {
+		return nil, err
+	}
+	issuerKeyHash := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+	var b cryptobyte.Builder
+	b.AddUint16(sctEntryTypePrecert)
+	b.AddBytes(issuerKeyHash[:])
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(tbs)
+	})
+	return b.Bytes()
+}
+
+// tbsWithoutExtension re-encodes the DER TBSCertificate tbs without the
+// extension identified by oid.
+func tbsWithoutExtension(tbs []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	input := cryptobyte.String(tbs)
+	if !input.ReadASN1(&input, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed tbs certificate")
+	}
+	extensionsTag := cryptobyte_asn1.Tag(3).Constructed().ContextSpecific()
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		for !input.Empty() {
+			var elem cryptobyte.String
+			var tag cryptobyte_asn1.Tag
+			if !input.ReadAnyASN1Element(&elem, &tag) {
+				b.SetError(errors.New("x509: malformed tbs certificate"))
+				return
+			}
+			if tag != extensionsTag {
+				b.AddBytes(elem)
+				continue
+			}
+			var exts cryptobyte.String
+			if !elem.ReadASN1(&exts, extensionsTag) || !exts.ReadASN1(&exts, cryptobyte_asn1.SEQUENCE) {
+				b.SetError(errors.New("x509: malformed extensions"))
+				return
+			}
+			b.AddASN1(extensionsTag, func(b *cryptobyte.Builder) {
+				b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					for !exts.Empty() {
+						var ext, extContents cryptobyte.String
+						var extID asn1.ObjectIdentifier
+						if !exts.ReadASN1Element(&ext, cryptobyte_asn1.SEQUENCE) {
+							b.SetError(errors.New("x509: malformed extension"))
+							return
+						}
+						extContents = ext
+						if !extContents.ReadASN1(&extContents, cryptobyte_asn1.SEQUENCE) ||
+							!extContents.ReadASN1ObjectIdentifier(&extID) {
+							b.SetError(errors.New("x509: malformed extension"))
+							return
+						}
+						if !extID.Equal(oid) {
+							b.AddBytes(ext)
+						}
+					}
+				})
+			})
+		}
+	})
+	return b.Bytes()
+}
+
+// signedData returns the data covered by the SCT signature, for the given
+// signed_entry.
+func (sct *SignedCertificateTimestamp) signedData(entry []byte) []byte {
+	var b cryptobyte.Builder
+	b.AddUint8(sctVersionV1)
+	b.AddUint8(sctSignatureTypeCertificateTimestamp)
+	b.AddUint64(uint64(sct.Timestamp.UnixMilli()))
+	b.AddBytes(entry)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(sct.Extensions)
+	})
+	return b.BytesOrPanic()
+}
+
+func (sct *SignedCertificateTimestamp) verify(log *CTLog, entry []byte) error {
+	if sct.HashAlgorithm != sctHashSHA256 {
+		return errors.New("x509: unsupported SCT hash algorithm")
+	}
+	digest := sha256.Sum256(sct.signedData(entry))
+	switch pub := log.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if sct.SignatureAlgorithm != sctSignatureECDSA {
+			return errors.New("x509: SCT signature algorithm does not match the log key")
+		}
+		if pub.Curve != elliptic.P256() {
+			return errors.New("x509: CT log ECDSA key is not on P-256")
+		}
+		if !ecdsa.VerifyASN1(pub, digest[:], sct.Signature) {
+			return errors.New("x509: invalid SCT signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		if sct.SignatureAlgorithm != sctSignatureRSA {
+			return errors.New("x509: SCT signature algorithm does not match the log key")
+		}
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sct.Signature)
+	}
+	return errors.New("x509: unsupported CT log public key type")
+}
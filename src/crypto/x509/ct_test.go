@@ -0,0 +1,263 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+var oidExtensionCTPoison = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
+
+type testCTLog struct {
+	*CTLog
+	key crypto.Signer
+}
+
+func newTestCTLog(t *testing.T, operator string, rsaKey bool) *testCTLog {
+	t.Helper()
+	var key crypto.Signer
+	var err error
+	if rsaKey {
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	} else {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCTLog{&CTLog{Operator: operator, PublicKey: key.Public()}, key}
+}
+
+// sign returns an SCT over entry, in its TLS encoding.
+func (l *testCTLog) sign(t *testing.T, entry []byte, timestamp time.Time) []byte {
+	t.Helper()
+	id, err := l.ID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sct := &SignedCertificateTimestamp{Timestamp: timestamp.Truncate(time.Millisecond)}
+	digest := sha256.Sum256(sct.signedData(entry))
+	sig, err := l.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigAlg := uint8(sctSignatureECDSA)
+	if _, ok := l.key.(*rsa.PrivateKey); ok {
+		sigAlg = sctSignatureRSA
+	}
+	var b cryptobyte.Builder
+	b.AddUint8(sctVersionV1)
+	b.AddBytes(id[:])
+	b.AddUint64(uint64(sct.Timestamp.UnixMilli()))
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {})
+	b.AddUint8(sctHashSHA256)
+	b.AddUint8(sigAlg)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sig) })
+	return b.BytesOrPanic()
+}
+
+func marshalSCTListExtension(t *testing.T, id asn1.ObjectIdentifier, scts ...[]byte) pkix.Extension {
+	t.Helper()
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, sct := range scts {
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sct) })
+		}
+	})
+	value, err := asn1.Marshal(b.BytesOrPanic())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkix.Extension{Id: id, Value: value}
+}
+
+// issueWithEmbeddedSCTs issues a precertificate, has it signed by logs, and
+// then issues the final certificate with the embedded SCTs.
+func issueWithEmbeddedSCTs(t *testing.T, pki *testPKI, now time.Time, logs ...*testCTLog) *Certificate {
+	t.Helper()
+	template := &Certificate{
+		SerialNumber: big.NewInt(1234),
+		Subject:      pkix.Name{CommonName: "ct.example.com"},
+		DNSNames:     []string{"ct.example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     KeyUsageDigitalSignature,
+		ExtKeyUsage:  []ExtKeyUsage{ExtKeyUsageServerAuth},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidExtensionCTPoison, Critical: true, Value: asn1.NullBytes},
+		},
+	}
+	pub := pki.leafKey.Public()
+	preDER, err := CreateCertificate(rand.Reader, template, pki.intermediate, pub, pki.intermediateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pre, err := ParseCertificate(preDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbs, err := tbsWithoutExtension(pre.RawTBSCertificate, oidExtensionCTPoison)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerKeyHash := sha256.Sum256(pki.intermediate.RawSubjectPublicKeyInfo)
+	var entry cryptobyte.Builder
+	entry.AddUint16(sctEntryTypePrecert)
+	entry.AddBytes(issuerKeyHash[:])
+	entry.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(tbs) })
+
+	var scts [][]byte
+	for _, l := range logs {
+		scts = append(scts, l.sign(t, entry.BytesOrPanic(), now.Add(-time.Minute)))
+	}
+	template.ExtraExtensions = []pkix.Extension{marshalSCTListExtension(t, oidExtensionSCTList, scts...)}
+	der, err := CreateCertificate(rand.Reader, template, pki.intermediate, pub, pki.intermediateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestParseSignedCertificateTimestamp(t *testing.T) {
+	log := newTestCTLog(t, "A", false)
+	now := time.Now()
+	raw := log.sign(t, []byte("entry"), now)
+	sct, err := ParseSignedCertificateTimestamp(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := log.ID()
+	if sct.LogID != id {
+		t.Errorf("unexpected LogID: got %x, want %x", sct.LogID, id)
+	}
+	if !sct.Timestamp.Equal(now.Truncate(time.Millisecond)) {
+		t.Errorf("unexpected Timestamp: got %v, want %v", sct.Timestamp, now)
+	}
+	if !bytes.Equal(sct.Raw, raw) {
+		t.Errorf("unexpected Raw")
+	}
+	if err := sct.verify(log.CTLog, []byte("entry")); err != nil {
+		t.Errorf("verify failed: %s", err)
+	}
+	if err := sct.verify(log.CTLog, []byte("other entry")); err == nil {
+		t.Errorf("verify succeeded for the wrong entry")
+	}
+
+	if _, err := ParseSignedCertificateTimestamp(append(raw, 0)); err == nil {
+		t.Errorf("ParseSignedCertificateTimestamp succeeded with trailing data")
+	}
+	if _, err := ParseSignedCertificateTimestamp(raw[:len(raw)-1]); err == nil {
+		t.Errorf("ParseSignedCertificateTimestamp succeeded with truncated data")
+	}
+	raw[0] = 1
+	if _, err := ParseSignedCertificateTimestamp(raw); err == nil {
+		t.Errorf("ParseSignedCertificateTimestamp succeeded with version 2")
+	}
+}
+
+func TestVerifyCertificateTransparency(t *testing.T) {
+	pki := newTestPKI(t)
+	now := time.Now()
+	logA1 := newTestCTLog(t, "Operator A", false)
+	logA2 := newTestCTLog(t, "Operator A", false)
+	logB := newTestCTLog(t, "Operator B", true)
+	untrusted := newTestCTLog(t, "Operator C", false)
+	logs := []*CTLog{logA1.CTLog, logA2.CTLog, logB.CTLog}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logP384 := &testCTLog{&CTLog{Operator: "Operator D", PublicKey: p384Key.Public()}, p384Key}
+
+	embedded := issueWithEmbeddedSCTs(t, pki, now, logA1, logB)
+	embeddedSameOperator := issueWithEmbeddedSCTs(t, pki, now, logA1, logA2)
+
+	x509Entry := sctX509Entry(pki.leaf)
+	sctA1 := logA1.sign(t, x509Entry, now.Add(-time.Minute))
+	sctB := logB.sign(t, x509Entry, now.Add(-time.Minute))
+	sctFuture := logB.sign(t, x509Entry, now.Add(time.Hour))
+	sctUntrusted := untrusted.sign(t, x509Entry, now.Add(-time.Minute))
+	sctOtherCert := logB.sign(t, sctX509Entry(pki.intermediate), now.Add(-time.Minute))
+	sctP384 := logP384.sign(t, x509Entry, now.Add(-time.Minute))
+
+	ocspWithSCT := func(scts ...[]byte) []byte {
+		id, err := NewOCSPCertID(pki.leaf, pki.intermediate, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := CreateOCSPResponse(rand.Reader, &OCSPResponse{Responses: []OCSPSingleResponse{{
+			CertID:          *id,
+			ThisUpdate:      now,
+			ExtraExtensions: []pkix.Extension{marshalSCTListExtension(t, oidOCSPExtensionSCTList, scts...)},
+		}}}, pki.intermediate, pki.intermediateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der
+	}
+
+	tests := []struct {
+		name    string
+		leaf    *Certificate
+		opts    *CTOptions
+		wantErr bool
+	}{
+		{name: "embedded", leaf: embedded, opts: &CTOptions{Logs: logs}},
+		{name: "embedded, same operator", leaf: embeddedSameOperator, opts: &CTOptions{Logs: logs}, wantErr: true},
+		{name: "embedded, same operator, one required", leaf: embeddedSameOperator, opts: &CTOptions{Logs: logs, MinOperators: 1}},
+		{name: "embedded, three required", leaf: embedded, opts: &CTOptions{Logs: logs, MinSCTs: 3}, wantErr: true},
+		{name: "embedded, log not trusted", leaf: embedded, opts: &CTOptions{Logs: []*CTLog{logA1.CTLog}, MinSCTs: 1, MinOperators: 1}},
+		{name: "embedded, no trusted logs", leaf: embedded, opts: &CTOptions{Logs: []*CTLog{logA2.CTLog}, MinSCTs: 1, MinOperators: 1}, wantErr: true},
+		{name: "no SCTs", leaf: pki.leaf, opts: &CTOptions{Logs: logs}, wantErr: true},
+		{name: "TLS extension", leaf: pki.leaf, opts: &CTOptions{Logs: logs, SignedCertificateTimestamps: [][]byte{sctA1, sctB}}},
+		{name: "TLS extension, duplicate", leaf: pki.leaf, opts: &CTOptions{Logs: logs, SignedCertificateTimestamps: [][]byte{sctB, sctB}}, wantErr: true},
+		{name: "TLS extension, future", leaf: pki.leaf, opts: &CTOptions{Logs: logs, SignedCertificateTimestamps: [][]byte{sctA1, sctFuture}}, wantErr: true},
+		{name: "TLS extension, untrusted", leaf: pki.leaf, opts: &CTOptions{Logs: logs, SignedCertificateTimestamps: [][]byte{sctA1, sctUntrusted}}, wantErr: true},
+		{name: "TLS extension, other certificate", leaf: pki.leaf, opts: &CTOptions{Logs: logs, SignedCertificateTimestamps: [][]byte{sctA1, sctOtherCert}}, wantErr: true},
+		{name: "TLS extension, P-384 log", leaf: pki.leaf, opts: &CTOptions{Logs: append(logs, logP384.CTLog), SignedCertificateTimestamps: [][]byte{sctA1, sctP384}}, wantErr: true},
+		{name: "TLS extension, garbage", leaf: pki.leaf, opts: &CTOptions{Logs: logs, SignedCertificateTimestamps: [][]byte{sctA1, {1, 2, 3}}}, wantErr: true},
+		{name: "OCSP", leaf: pki.leaf, opts: &CTOptions{Logs: logs, OCSPResponses: [][]byte{ocspWithSCT(sctA1, sctB)}}},
+		{name: "OCSP and TLS extension", leaf: pki.leaf, opts: &CTOptions{Logs: logs, OCSPResponses: [][]byte{ocspWithSCT(sctA1)}, SignedCertificateTimestamps: [][]byte{sctB}}},
+		{name: "embedded and TLS extension", leaf: embeddedSameOperator, opts: &CTOptions{Logs: logs, MinSCTs: 3, SignedCertificateTimestamps: [][]byte{
+			logB.sign(t, sctX509Entry(embeddedSameOperator), now.Add(-time.Minute)),
+		}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := pki.verifyOptions(nil)
+			opts.CertificateTransparency = tc.opts
+			_, err := tc.leaf.Verify(opts)
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("Verify failed: %s", err)
+				}
+				return
+			}
+			var invalidErr CertificateInvalidError
+			if !errors.As(err, &invalidErr) || invalidErr.Reason != InsufficientSCTs {
+				t.Fatalf("Verify returned %v, want an InsufficientSCTs error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,505 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"slices"
+	"time"
+)
+
+// An Issuer is a certificate authority that issues certificates for
+// certificate signing requests according to a [CertificateProfile], and
+// issues complete and delta CRLs.
+//
+// An Issuer is safe for concurrent use if its function fields are.
+type Issuer struct {
+	// Certificate is the certificate of the authority. Its subject becomes
+	// the issuer of certificates and CRLs, and its validity period, path
+	// length constraint and name constraints limit the certificates that can
+	// be issued.
+	Certificate *Certificate
+
+	// Signer is the private key corresponding to Certificate.
+	Signer crypto.Signer
+
+	// Rand is the source of entropy for signatures and random serial numbers.
+	// If nil, crypto/rand.Reader is used.
+	Rand io.Reader
+
+	// Time returns the current time. If nil, time.Now is used.
+	Time func() time.Time
+
+	// SerialNumber, if not nil, is called to allocate the serial number of
+	// each certificate. Serial numbers must be positive, unique for the
+	// issuer, and at most 20 octets long. If nil, a random 20 octet serial
+	// number is generated.
+	SerialNumber func() (*big.Int, error)
+
+	// CRLNumber, if not nil, is called to allocate the cRLNumber of each CRL.
+	// CRL numbers must increase monotonically. If nil, the issuance time in
+	// nanoseconds since the Unix epoch is used.
+	CRLNumber func() (*big.Int, error)
+
+	// Audit, if not nil, is called once for every issuance request, whether
+	// it succeeded or not. If it returns an error for a successful issuance,
+	// the certificate or CRL is withheld and the error is returned instead.
+	Audit func(*IssuanceRecord) error
+}
+
+// A CertificateProfile describes the certificates an [Issuer] may issue.
+//
+// Only the subject (if CopySubject is set), public key and subject alternative
+// names of a request are used. Any extensions it requests, such as key usages,
+// are ignored in favor of the profile.
+type CertificateProfile struct {
+	// Validity is the lifetime of issued certificates. It must be positive.
+	// Certificates never outlive the issuing certificate: if the issuer's
+	// NotAfter is earlier, it is used instead.
+	Validity time.Duration
+
+	// Backdate is subtracted from the issuance time to obtain the NotBefore
+	// of issued certificates, to accommodate clock skew.
+	Backdate time.Duration
+
+	// CopySubject causes the subject of requests to be copied to issued
+	// certificates. Otherwise, issued certificates have an empty subject and
+	// are identified by their subject alternative names only. The common
+	// names of a copied subject must be DNS names or IP addresses, and they
+	// and its email addresses are subject to the same restrictions as
+	// subject alternative names.
+	CopySubject bool
+
+	// AllowedDNSDomains, AllowedEmailDomains, AllowedIPRanges and
+	// AllowedURIDomains restrict the subject alternative names that may be
+	// requested. They have the same semantics as the permitted subtrees of
+	// name constraints, for example "example.com" matches example.com and
+	// all its subdomains, and "" matches any name. A request containing a
+	// name of a type whose list is empty is rejected.
+	AllowedDNSDomains   []string
+	AllowedEmailDomains []string
+	AllowedIPRanges     []*net.IPNet
+	AllowedURIDomains   []string
+
+	// PublicKeyAlgorithms, if not empty, restricts the public key algorithms
+	// that may be requested.
+	PublicKeyAlgorithms []PublicKeyAlgorithm
+
+	// MinRSAKeySize is the minimum size in bits of requested RSA keys. If
+	// zero, 2048 is used.
+	MinRSAKeySize int
+
+	// KeyUsage and ExtKeyUsage are set in issued certificates.
+	KeyUsage    KeyUsage
+	ExtKeyUsage []ExtKeyUsage
+
+	// IsCA causes issued certificates to be certificate authorities. The
+	// issuing certificate must itself allow a path length of at least one.
+	IsCA bool
+
+	// MaxPathLen and MaxPathLenZero have the same meaning as the fields of
+	// [Certificate] when IsCA is set. The path length is reduced if needed to
+	// satisfy the path length constraint of the issuing certificate.
+	MaxPathLen     int
+	MaxPathLenZero bool
+
+	// OCSPServer, IssuingCertificateURL and CRLDistributionPoints are set in
+	// issued certificates.
+	OCSPServer            []string
+	IssuingCertificateURL []string
+	CRLDistributionPoints []string
+
+	// SignatureAlgorithm is the algorithm used to sign certificates. If zero,
+	// the default for Signer is used.
+	SignatureAlgorithm SignatureAlgorithm
+}
+
+// An IssuanceRecord describes an issuance request handled by an [Issuer], for
+// auditing purposes.
+type IssuanceRecord struct {
+	// Time is the time of issuance.
+	Time time.Time
+
+	// Request and Profile are the certificate signing request and profile of
+	// a certificate issuance. They are nil for CRL issuance.
+	Request *CertificateRequest
+	Profile *CertificateProfile
+
+	// Certificate is the issued certificate, or nil if none was issued.
+	Certificate *Certificate
+
+	// RevocationList is the issued CRL, or nil if none was issued.
+	RevocationList *RevocationList
+
+	// Err is the reason the request was refused, or nil if it succeeded.
+	Err error
+}
+
+func (iss *Issuer) rand() io.Reader {
+	if iss.Rand != nil {
+		return iss.Rand
+	}
+	return rand.Reader
+}
+
+func (iss *Issuer) now() time.Time {
+	if iss.Time != nil {
+		return iss.Time()
+	}
+	return time.Now()
+}
+
+// audit records the outcome of a request and returns the error to report.
+func (iss *Issuer) audit(rec *IssuanceRecord) error {
+	if iss.Audit == nil {
+		return rec.Err
+	}
+	if err := iss.Audit(rec); err != nil && rec.Err == nil {
+		return err
+	}
+	return rec.Err
+}
+
+// IssueCertificate issues a certificate for csr according to profile.
+//
+// The request is refused if its signature is invalid, if it asks for a public
+// key or name not allowed by profile, or if one of its names is not permitted
+// by the name constraints of the issuing certificate.
+func (iss *Issuer) IssueCertificate(csr *CertificateRequest, profile *CertificateProfile) (*Certificate, error) {
+	now := iss.now()
+	rec := &IssuanceRecord{Time: now, Request: csr, Profile: profile}
+	rec.Certificate, rec.Err = iss.issueCertificate(csr, profile, now)
+	if err := iss.audit(rec); err != nil {
+		return nil, err
+	}
+	return rec.Certificate, nil
+}
+
+func (iss *Issuer) issueCertificate(csr *CertificateRequest, profile *CertificateProfile, now time.Time) (*Certificate, error) {
+	if csr == nil || profile == nil {
+		return nil, errors.New("x509: certificate request and profile can not be nil")
+	}
+	parent := iss.Certificate
+	if parent == nil || iss.Signer == nil {
+		return nil, errors.New("x509: issuer has no certificate or signer")
+	}
+	if parent.KeyUsage&KeyUsageCertSign == 0 {
+		return nil, errors.New("x509: issuer must have the keyCertSign key usage bit set")
+	}
+	if now.Before(parent.NotBefore) || now.After(parent.NotAfter) {
+		return nil, errors.New("x509: issuer certificate is not valid at the current time")
+	}
+	if profile.Validity <= 0 {
+		return nil, errors.New("x509: profile validity must be positive")
+	}
+
+	if err := csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+	if err := checkRequestedKey(csr, profile); err != nil {
+		return nil, err
+	}
+	if err := checkRequestedNames(csr, profile, parent); err != nil {
+		return nil, err
+	}
+
+	template := &Certificate{
+		NotBefore:             now.Add(-profile.Backdate),
+		NotAfter:              now.Add(profile.Validity),
+		KeyUsage:              profile.KeyUsage,
+		ExtKeyUsage:           profile.ExtKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  profile.IsCA,
+		DNSNames:              csr.DNSNames,
+		EmailAddresses:        csr.EmailAddresses,
+		IPAddresses:           csr.IPAddresses,
+		URIs:                  csr.URIs,
+		OCSPServer:            profile.OCSPServer,
+		IssuingCertificateURL: profile.IssuingCertificateURL,
+		CRLDistributionPoints: profile.CRLDistributionPoints,
+		SignatureAlgorithm:    profile.SignatureAlgorithm,
+	}
+	if template.NotBefore.Before(parent.NotBefore) {
+		template.NotBefore = parent.NotBefore
+	}
+	if template.NotAfter.After(parent.NotAfter) {
+		template.NotAfter = parent.NotAfter
+	}
+	if profile.CopySubject {
+		template.RawSubject = csr.RawSubject
+	}
+	if profile.IsCA {
+		pathLen, err := issuedPathLen(profile, parent)
+		if err != nil {
+			return nil, err
+		}
+		template.MaxPathLen = pathLen
+		template.MaxPathLenZero = pathLen == 0
+	}
+	if iss.SerialNumber != nil {
+		serial, err := iss.SerialNumber()
+		if err != nil {
+			return nil, err
+		}
+		template.SerialNumber = serial
+	}
+
+	der, err := CreateCertificate(iss.rand(), template, parent, csr.PublicKey, iss.Signer)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificate(der)
+}
+
+func checkRequestedKey(csr *CertificateRequest, profile *CertificateProfile) error {
+	if len(profile.PublicKeyAlgorithms) > 0 {
+		allowed := false
+		for _, algo := range profile.PublicKeyAlgorithms {
+			if csr.PublicKeyAlgorithm == algo {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("x509: public key algorithm %v not allowed by profile", csr.PublicKeyAlgorithm)
+		}
+	}
+	if pub, ok := csr.PublicKey.(*rsa.PublicKey); ok {
+		minSize := profile.MinRSAKeySize
+		if minSize == 0 {
+			minSize = 2048
+		}
+		if pub.N.BitLen() < minSize {
+			return fmt.Errorf("x509: %d-bit RSA key is smaller than the %d bits required by profile", pub.N.BitLen(), minSize)
+		}
+	}
+	return nil
+}
+
+var (
+	oidCommonName   = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidEmailAddress = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+)
+
+// checkRequestedNames checks the subject alternative names of csr against the
+// allowed names of profile and the name constraints of the issuer. If the
+// subject is copied, its common names and email addresses are checked too,
+// as some clients still match them against the names they expect.
+//
+// Only the name constraints of the issuer itself are checked, not those of
+// the CAs above it. Since directoryName and other constraint types are not
+// supported, issuers with such critical constraints are rejected.
+func checkRequestedNames(csr *CertificateRequest, profile *CertificateProfile, parent *Certificate) error {
+	for _, oid := range parent.UnhandledCriticalExtensions {
+		if oid.Equal(oidExtensionNameConstraints) {
+			return errors.New("x509: issuer has unsupported name constraints")
+		}
+	}
+
+	dnsNames := slices.Clip(csr.DNSNames)
+	emails := slices.Clip(csr.EmailAddresses)
+	ips := slices.Clip(csr.IPAddresses)
+	if profile.CopySubject {
+		for _, atv := range csr.Subject.Names {
+			value, ok := atv.Value.(string)
+			switch {
+			case atv.Type.Equal(oidCommonName):
+				if !ok {
+					return errors.New("x509: cannot parse subject common name")
+				}
+				if ip := net.ParseIP(value); ip != nil {
+					ips = append(ips, ip)
+				} else if _, ok := domainToReverseLabels(value); ok {
+					dnsNames = append(dnsNames, value)
+				} else {
+					return fmt.Errorf("x509: subject common name %q is not a DNS name or IP address", value)
+				}
+			case atv.Type.Equal(oidEmailAddress):
+				if !ok {
+					return errors.New("x509: cannot parse subject email address")
+				}
+				emails = append(emails, value)
+			}
+		}
+	}
+	if len(dnsNames)+len(emails)+len(ips)+len(csr.URIs) == 0 {
+		return errors.New("x509: certificate request contains no names")
+	}
+
+	for _, name := range dnsNames {
+		if _, ok := domainToReverseLabels(name); !ok {
+			return fmt.Errorf("x509: cannot parse dnsName %q", name)
+		}
+		if err := checkName("DNS name", name, name, profile.AllowedDNSDomains, parent.PermittedDNSDomains, parent.ExcludedDNSDomains, matchDomainConstraint); err != nil {
+			return err
+		}
+	}
+	for _, email := range emails {
+		mailbox, ok := parseRFC2821Mailbox(email)
+		if !ok {
+			return fmt.Errorf("x509: cannot parse rfc822Name %q", email)
+		}
+		if err := checkName("email address", email, mailbox, profile.AllowedEmailDomains, parent.PermittedEmailAddresses, parent.ExcludedEmailAddresses, matchEmailConstraint); err != nil {
+			return err
+		}
+	}
+	for _, ip := range ips {
+		if err := checkName("IP address", ip.String(), ip, profile.AllowedIPRanges, parent.PermittedIPRanges, parent.ExcludedIPRanges, matchIPNet); err != nil {
+			return err
+		}
+	}
+	for _, uri := range csr.URIs {
+		if err := checkName("URI", uri.String(), uri, profile.AllowedURIDomains, parent.PermittedURIDomains, parent.ExcludedURIDomains, matchURIConstraint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkName checks that name is matched by one of the allowed constraints of
+// a profile and by the name constraints of the issuer. display is the name as
+// reported in errors.
+func checkName[N any, C any](kind, display string, name N, allowed, permitted, excluded []C, match func(N, C) (bool, error)) error {
+	matchAny := func(constraints []C) (bool, error) {
+		for _, c := range constraints {
+			ok, err := match(name, c)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if ok, err := matchAny(allowed); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("x509: %s %q not allowed by profile", kind, display)
+	}
+	if ok, err := matchAny(excluded); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("x509: %s %q is excluded by the issuer's name constraints", kind, display)
+	}
+	if len(permitted) > 0 {
+		if ok, err := matchAny(permitted); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("x509: %s %q is not permitted by the issuer's name constraints", kind, display)
+		}
+	}
+	return nil
+}
+
+// matchIPNet is like matchIPConstraint, but uses [net.IPNet.Contains], which
+// matches IPv4 addresses and networks in either their 4 or 16-byte form, as IP
+// ranges in profiles are not normalized by parsing. The error result, always
+// nil, is only there to fit checkName.
+func matchIPNet(ip net.IP, constraint *net.IPNet) (bool, error) {
+	return constraint.Contains(ip), nil
+}
+
+// issuedPathLen returns the path length constraint for a CA certificate issued
+// with profile by parent, or -1 if it is unconstrained.
+func issuedPathLen(profile *CertificateProfile, parent *Certificate) (int, error) {
+	pathLen := -1
+	if profile.MaxPathLen > 0 || profile.MaxPathLen == 0 && profile.MaxPathLenZero {
+		pathLen = profile.MaxPathLen
+	}
+	if parent.BasicConstraintsValid && (parent.MaxPathLen > 0 || parent.MaxPathLen == 0 && parent.MaxPathLenZero) {
+		if parent.MaxPathLen == 0 {
+			return 0, errors.New("x509: issuer path length constraint does not allow issuing CA certificates")
+		}
+		if pathLen < 0 || pathLen > parent.MaxPathLen-1 {
+			pathLen = parent.MaxPathLen - 1
+		}
+	}
+	return pathLen, nil
+}
+
+// IssueRevocationList issues a complete CRL listing entries, valid for
+// validity from the current time.
+//
+// The issuing certificate must have the cRLSign key usage bit set and a subject
+// key identifier, as required by [CreateRevocationList].
+func (iss *Issuer) IssueRevocationList(entries []RevocationListEntry, validity time.Duration) (*RevocationList, error) {
+	return iss.issueRevocationList(nil, entries, validity)
+}
+
+// IssueDeltaRevocationList issues a delta CRL for base, a complete CRL
+// previously issued by iss, valid for validity from the current time.
+//
+// entries must list every certificate revoked or put on hold since base was
+// issued, as well as certificates listed as on hold in base that were since
+// released, with reason code removeFromCRL (8). Delta CRLs are cumulative:
+// each one supersedes the previous delta CRLs for the same base.
+func (iss *Issuer) IssueDeltaRevocationList(base *RevocationList, entries []RevocationListEntry, validity time.Duration) (*RevocationList, error) {
+	if base == nil {
+		return nil, errors.New("x509: base CRL can not be nil")
+	}
+	return iss.issueRevocationList(base, entries, validity)
+}
+
+func (iss *Issuer) issueRevocationList(base *RevocationList, entries []RevocationListEntry, validity time.Duration) (*RevocationList, error) {
+	now := iss.now()
+	rec := &IssuanceRecord{Time: now}
+	rec.RevocationList, rec.Err = iss.signRevocationList(base, entries, validity, now)
+	if err := iss.audit(rec); err != nil {
+		return nil, err
+	}
+	return rec.RevocationList, nil
+}
+
+func (iss *Issuer) signRevocationList(base *RevocationList, entries []RevocationListEntry, validity time.Duration, now time.Time) (*RevocationList, error) {
+	if iss.Certificate == nil || iss.Signer == nil {
+		return nil, errors.New("x509: issuer has no certificate or signer")
+	}
+	if validity <= 0 {
+		return nil, errors.New("x509: CRL validity must be positive")
+	}
+
+	template := &RevocationList{
+		RevokedCertificateEntries: entries,
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(validity),
+	}
+	if base != nil {
+		if base.BaseCRLNumber != nil {
+			return nil, errors.New("x509: base CRL is a delta CRL")
+		}
+		if base.Number == nil {
+			return nil, errors.New("x509: base CRL has no CRL number")
+		}
+		if err := base.CheckSignatureFrom(iss.Certificate); err != nil {
+			return nil, fmt.Errorf("x509: base CRL was not issued by this issuer: %w", err)
+		}
+		template.BaseCRLNumber = base.Number
+	}
+	if iss.CRLNumber != nil {
+		number, err := iss.CRLNumber()
+		if err != nil {
+			return nil, err
+		}
+		template.Number = number
+	} else {
+		template.Number = big.NewInt(now.UnixNano())
+	}
+
+	der, err := CreateRevocationList(iss.rand(), template, iss.Certificate, iss.Signer)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRevocationList(der)
+}
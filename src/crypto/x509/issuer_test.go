@@ -0,0 +1,355 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCSR(t *testing.T, key crypto.Signer, mutate func(*CertificateRequest)) *CertificateRequest {
+	t.Helper()
+	template := &CertificateRequest{Subject: pkix.Name{CommonName: "test"}}
+	if mutate != nil {
+		mutate(template)
+	}
+	der, err := CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr
+}
+
+func TestIssuerIssueCertificate(t *testing.T) {
+	root, rootKey := newRevocationTestCert(t, "Root", true, nil, nil, func(c *Certificate) {
+		c.PermittedDNSDomains = []string{"example.com"}
+		c.ExcludedDNSDomains = []string{"secret.example.com"}
+		c.MaxPathLen = 2
+	})
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ipRange, _ := net.ParseCIDR("10.0.0.0/8")
+	uri, _ := url.Parse("spiffe://example.com/workload")
+	profile := func(mutate func(*CertificateProfile)) *CertificateProfile {
+		p := &CertificateProfile{
+			Validity:            time.Hour,
+			KeyUsage:            KeyUsageDigitalSignature,
+			ExtKeyUsage:         []ExtKeyUsage{ExtKeyUsageServerAuth},
+			AllowedDNSDomains:   []string{"example.com"},
+			AllowedEmailDomains: []string{"example.com"},
+			AllowedIPRanges:     []*net.IPNet{ipRange},
+			AllowedURIDomains:   []string{"example.com"},
+		}
+		if mutate != nil {
+			mutate(p)
+		}
+		return p
+	}
+	badSignature := newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"a.example.com"} })
+	badSignature.Signature[len(badSignature.Signature)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		csr     *CertificateRequest
+		profile *CertificateProfile
+		wantErr string
+	}{
+		{name: "all names",
+			csr: newTestCSR(t, key, func(csr *CertificateRequest) {
+				csr.DNSNames = []string{"www.example.com"}
+				csr.EmailAddresses = []string{"admin@example.com"}
+				csr.IPAddresses = []net.IP{net.ParseIP("10.1.2.3")}
+				csr.URIs = []*url.URL{uri}
+			}),
+			profile: profile(nil)},
+		{name: "DNS name not allowed",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"www.example.com", "example.org"} }),
+			profile: profile(func(p *CertificateProfile) { p.AllowedDNSDomains = []string{""} }),
+			wantErr: "not permitted by the issuer"},
+		{name: "DNS name excluded",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"db.secret.example.com"} }),
+			profile: profile(nil),
+			wantErr: "excluded by the issuer"},
+		{name: "DNS name outside profile",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"www.example.com"} }),
+			profile: profile(func(p *CertificateProfile) { p.AllowedDNSDomains = []string{".internal.example.com"} }),
+			wantErr: "not allowed by profile"},
+		{name: "DNS names not allowed",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"www.example.com"} }),
+			profile: profile(func(p *CertificateProfile) { p.AllowedDNSDomains = nil }),
+			wantErr: "not allowed by profile"},
+		{name: "email outside profile",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.EmailAddresses = []string{"admin@example.org"} }),
+			profile: profile(nil),
+			wantErr: "not allowed by profile"},
+		{name: "IP in 16-byte profile range",
+			csr: newTestCSR(t, key, func(csr *CertificateRequest) { csr.IPAddresses = []net.IP{net.ParseIP("10.1.2.3")} }),
+			profile: profile(func(p *CertificateProfile) {
+				p.AllowedIPRanges = []*net.IPNet{{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(8, 32)}}
+			})},
+		{name: "IP outside profile",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.IPAddresses = []net.IP{net.ParseIP("192.168.1.1")} }),
+			profile: profile(nil),
+			wantErr: "not allowed by profile"},
+		{name: "no names",
+			csr:     newTestCSR(t, key, nil),
+			profile: profile(nil),
+			wantErr: "contains no names"},
+		{name: "subject only",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.Subject.CommonName = "www.example.com" }),
+			profile: profile(func(p *CertificateProfile) { p.CopySubject = true })},
+		{name: "subject IP address",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.Subject.CommonName = "10.1.2.3" }),
+			profile: profile(func(p *CertificateProfile) { p.CopySubject = true })},
+		{name: "subject common name outside profile",
+			csr: newTestCSR(t, key, func(csr *CertificateRequest) {
+				csr.Subject.CommonName = "www.example.org"
+				csr.DNSNames = []string{"www.example.com"}
+			}),
+			profile: profile(func(p *CertificateProfile) { p.CopySubject = true }),
+			wantErr: "not allowed by profile"},
+		{name: "subject common name excluded",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.Subject.CommonName = "db.secret.example.com" }),
+			profile: profile(func(p *CertificateProfile) { p.CopySubject = true }),
+			wantErr: "excluded by the issuer"},
+		{name: "subject common name not a name",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.Subject.CommonName = "Jane Doe" }),
+			profile: profile(func(p *CertificateProfile) { p.CopySubject = true }),
+			wantErr: "not a DNS name or IP address"},
+		{name: "subject email outside profile",
+			csr: newTestCSR(t, key, func(csr *CertificateRequest) {
+				csr.Subject.CommonName = "www.example.com"
+				csr.Subject.ExtraNames = []pkix.AttributeTypeAndValue{{Type: oidEmailAddress, Value: "admin@example.org"}}
+			}),
+			profile: profile(func(p *CertificateProfile) { p.CopySubject = true }),
+			wantErr: "not allowed by profile"},
+		{name: "subject without names",
+			csr: newTestCSR(t, key, func(csr *CertificateRequest) {
+				csr.Subject = pkix.Name{Organization: []string{"Example"}}
+			}),
+			profile: profile(func(p *CertificateProfile) { p.CopySubject = true }),
+			wantErr: "contains no names"},
+		{name: "bad signature",
+			csr:     badSignature,
+			profile: profile(nil),
+			wantErr: "verification failure"},
+		{name: "public key algorithm",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"www.example.com"} }),
+			profile: profile(func(p *CertificateProfile) { p.PublicKeyAlgorithms = []PublicKeyAlgorithm{Ed25519} }),
+			wantErr: "public key algorithm"},
+		{name: "small RSA key",
+			csr:     newTestCSR(t, smallRSAKey, func(csr *CertificateRequest) { csr.DNSNames = []string{"www.example.com"} }),
+			profile: profile(nil),
+			wantErr: "1024-bit RSA key"},
+		{name: "small RSA key allowed",
+			csr:     newTestCSR(t, smallRSAKey, func(csr *CertificateRequest) { csr.DNSNames = []string{"www.example.com"} }),
+			profile: profile(func(p *CertificateProfile) { p.MinRSAKeySize = 1024 })},
+		{name: "zero validity",
+			csr:     newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"www.example.com"} }),
+			profile: profile(func(p *CertificateProfile) { p.Validity = 0 }),
+			wantErr: "validity"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var records []*IssuanceRecord
+			iss := &Issuer{
+				Certificate: root,
+				Signer:      rootKey,
+				Audit: func(rec *IssuanceRecord) error {
+					records = append(records, rec)
+					return nil
+				},
+			}
+			cert, err := iss.IssueCertificate(tc.csr, tc.profile)
+			if len(records) != 1 {
+				t.Fatalf("Audit called %d times, want 1", len(records))
+			}
+			if records[0].Err != err || records[0].Certificate != cert || records[0].Request != tc.csr {
+				t.Errorf("unexpected audit record: %+v", records[0])
+			}
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("IssueCertificate returned %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IssueCertificate failed: %s", err)
+			}
+			if err := cert.CheckSignatureFrom(root); err != nil {
+				t.Errorf("certificate not signed by issuer: %s", err)
+			}
+			if cert.KeyUsage != tc.profile.KeyUsage || len(cert.ExtKeyUsage) != 1 || cert.IsCA {
+				t.Errorf("profile not applied: KeyUsage %v, ExtKeyUsage %v, IsCA %v", cert.KeyUsage, cert.ExtKeyUsage, cert.IsCA)
+			}
+			if got := cert.Subject.CommonName != ""; got != tc.profile.CopySubject {
+				t.Errorf("unexpected subject %q", cert.Subject)
+			}
+			if len(cert.DNSNames) != len(tc.csr.DNSNames) || len(cert.IPAddresses) != len(tc.csr.IPAddresses) ||
+				len(cert.EmailAddresses) != len(tc.csr.EmailAddresses) || len(cert.URIs) != len(tc.csr.URIs) {
+				t.Errorf("unexpected names in certificate")
+			}
+		})
+	}
+}
+
+func TestIssuerLimits(t *testing.T) {
+	root, rootKey := newRevocationTestCert(t, "Root", true, nil, nil, func(c *Certificate) {
+		c.MaxPathLen = 1
+	})
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr := newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"example.com"} })
+	serial := big.NewInt(42)
+	iss := &Issuer{
+		Certificate:  root,
+		Signer:       rootKey,
+		SerialNumber: func() (*big.Int, error) { return serial, nil },
+	}
+
+	intermediate, err := iss.IssueCertificate(csr, &CertificateProfile{
+		Validity:          365 * 24 * time.Hour,
+		Backdate:          time.Minute,
+		AllowedDNSDomains: []string{""},
+		KeyUsage:          KeyUsageCertSign,
+		IsCA:              true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if intermediate.SerialNumber.Cmp(serial) != 0 {
+		t.Errorf("unexpected serial number %v", intermediate.SerialNumber)
+	}
+	if !intermediate.NotAfter.Equal(root.NotAfter) {
+		t.Errorf("NotAfter is %v, want it clamped to %v", intermediate.NotAfter, root.NotAfter)
+	}
+	if !intermediate.IsCA || intermediate.MaxPathLen != 0 || !intermediate.MaxPathLenZero {
+		t.Errorf("unexpected basic constraints: IsCA %v, MaxPathLen %d", intermediate.IsCA, intermediate.MaxPathLen)
+	}
+
+	sub := &Issuer{Certificate: intermediate, Signer: rootKey}
+	_, err = sub.IssueCertificate(csr, &CertificateProfile{
+		Validity:          time.Hour,
+		AllowedDNSDomains: []string{""},
+		IsCA:              true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "path length") {
+		t.Errorf("issuing a CA certificate below a zero path length returned %v", err)
+	}
+
+	constrained := *root
+	constrained.UnhandledCriticalExtensions = []asn1.ObjectIdentifier{oidExtensionNameConstraints}
+	constrainedIss := &Issuer{Certificate: &constrained, Signer: rootKey}
+	_, err = constrainedIss.IssueCertificate(csr, &CertificateProfile{Validity: time.Hour, AllowedDNSDomains: []string{""}})
+	if err == nil || !strings.Contains(err.Error(), "name constraints") {
+		t.Errorf("issuing below unsupported name constraints returned %v", err)
+	}
+
+	auditErr := errors.New("audit log unavailable")
+	iss.Audit = func(*IssuanceRecord) error { return auditErr }
+	if cert, err := iss.IssueCertificate(csr, &CertificateProfile{Validity: time.Hour, AllowedDNSDomains: []string{""}}); err != auditErr || cert != nil {
+		t.Errorf("IssueCertificate with failing audit returned %v, %v", cert, err)
+	}
+}
+
+func TestIssuerDeltaRevocationList(t *testing.T) {
+	root, rootKey := newRevocationTestCert(t, "Root", true, nil, nil, nil)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	crlNumber := int64(0)
+	iss := &Issuer{
+		Certificate: root,
+		Signer:      rootKey,
+		Time:        func() time.Time { return now },
+		CRLNumber: func() (*big.Int, error) {
+			crlNumber++
+			return big.NewInt(crlNumber), nil
+		},
+	}
+	csr := newTestCSR(t, key, func(csr *CertificateRequest) { csr.DNSNames = []string{"leaf.example.com"} })
+	profile := &CertificateProfile{Validity: time.Hour, Backdate: time.Minute, AllowedDNSDomains: []string{""}}
+	leaf, err := iss.IssueCertificate(csr, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := iss.IssueCertificate(csr, profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := iss.IssueRevocationList([]RevocationListEntry{
+		{SerialNumber: leaf.SerialNumber, RevocationTime: now, ReasonCode: crlReasonCertificateHold},
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.BaseCRLNumber != nil || base.Number.Int64() != 1 {
+		t.Errorf("unexpected complete CRL: Number %v, BaseCRLNumber %v", base.Number, base.BaseCRLNumber)
+	}
+	release, err := iss.IssueDeltaRevocationList(base, []RevocationListEntry{
+		{SerialNumber: leaf.SerialNumber, RevocationTime: now, ReasonCode: crlReasonRemoveFromCRL},
+		{SerialNumber: other.SerialNumber, RevocationTime: now, ReasonCode: 1},
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.BaseCRLNumber == nil || release.BaseCRLNumber.Cmp(base.Number) != 0 || release.Number.Int64() != 2 {
+		t.Errorf("unexpected delta CRL: Number %v, BaseCRLNumber %v", release.Number, release.BaseCRLNumber)
+	}
+	if _, err := iss.IssueDeltaRevocationList(release, nil, time.Hour); err == nil {
+		t.Error("IssueDeltaRevocationList accepted a delta CRL as base")
+	}
+
+	verify := func(cert *Certificate, crls ...*RevocationList) error {
+		roots := NewCertPool()
+		roots.AddCert(root)
+		_, err := cert.Verify(VerifyOptions{
+			Roots:       roots,
+			CurrentTime: now,
+			Revocation:  &RevocationOptions{RevocationLists: crls, Policy: RevocationHardFail},
+		})
+		return err
+	}
+	if err := verify(leaf, base); err == nil {
+		t.Error("certificate on hold in complete CRL was accepted")
+	}
+	if err := verify(leaf, base, release); err != nil {
+		t.Errorf("certificate released by delta CRL was rejected: %s", err)
+	}
+	if err := verify(other, base, release); err == nil {
+		t.Error("certificate revoked by delta CRL was accepted")
+	}
+	if err := verify(other, release); err == nil {
+		t.Error("delta CRL without its base was sufficient")
+	}
+}
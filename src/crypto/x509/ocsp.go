@@ -0,0 +1,863 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// This file implements the Online Certificate Status Protocol (OCSP) request
+// and response formats specified in RFC 6960. Only the basic response type
+// (id-pkix-ocsp-basic) is supported.
+
+var (
+	oidOCSPBasicResponse = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+
+	oidHashSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidHashSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidHashSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidHashSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+var ocspHashOIDs = []struct {
+	hash crypto.Hash
+	oid  asn1.ObjectIdentifier
+}{
+	{crypto.SHA1, oidHashSHA1},
+	{crypto.SHA256, oidHashSHA256},
+	{crypto.SHA384, oidHashSHA384},
+	{crypto.SHA512, oidHashSHA512},
+}
+
+// OCSPResponseStatus is the outcome of an OCSP request, as reported in the
+// responseStatus field of an OCSP response. See RFC 6960, Section 4.2.1.
+type OCSPResponseStatus int
+
+const (
+	OCSPSuccessful       OCSPResponseStatus = 0
+	OCSPMalformedRequest OCSPResponseStatus = 1
+	OCSPInternalError    OCSPResponseStatus = 2
+	OCSPTryLater         OCSPResponseStatus = 3
+	// Status code 4 is not used.
+	OCSPSignatureRequired OCSPResponseStatus = 5
+	OCSPUnauthorized      OCSPResponseStatus = 6
+)
+
+func (s OCSPResponseStatus) String() string {
+	switch s {
+	case OCSPSuccessful:
+		return "successful"
+	case OCSPMalformedRequest:
+		return "malformed request"
+	case OCSPInternalError:
+		return "internal error"
+	case OCSPTryLater:
+		return "try later"
+	case OCSPSignatureRequired:
+		return "signature required"
+	case OCSPUnauthorized:
+		return "unauthorized"
+	}
+	return "unknown OCSP response status: " + strconv.Itoa(int(s))
+}
+
+// OCSPResponseError is returned by [ParseOCSPResponse] when the responder
+// did not return a successful response.
+type OCSPResponseError struct {
+	Status OCSPResponseStatus
+}
+
+func (e *OCSPResponseError) Error() string {
+	return "x509: OCSP response error: " + e.Status.String()
+}
+
+// OCSPCertStatus is the revocation status of a certificate, as reported in an
+// OCSP SingleResponse.
+type OCSPCertStatus int
+
+const (
+	OCSPGood OCSPCertStatus = iota
+	OCSPRevoked
+	OCSPUnknown
+)
+
+func (s OCSPCertStatus) String() string {
+	switch s {
+	case OCSPGood:
+		return "good"
+	case OCSPRevoked:
+		return "revoked"
+	case OCSPUnknown:
+		return "unknown"
+	}
+	return "OCSPCertStatus(" + strconv.Itoa(int(s)) + ")"
+}
+
+// OCSPCertID identifies a certificate in an OCSP request or response, as
+// specified in RFC 6960, Section 4.1.1.
+type OCSPCertID struct {
+	// HashAlgorithm is the hash used to compute IssuerNameHash and
+	// IssuerKeyHash. It must be one of crypto.SHA1, crypto.SHA256,
+	// crypto.SHA384 or crypto.SHA512.
+	HashAlgorithm crypto.Hash
+	// IssuerNameHash is the hash of the DER encoding of the issuer's
+	// distinguished name.
+	IssuerNameHash []byte
+	// IssuerKeyHash is the hash of the value of the BIT STRING
+	// subjectPublicKey field of the issuer's certificate.
+	IssuerKeyHash []byte
+	// SerialNumber is the serial number of the certificate.
+	SerialNumber *big.Int
+}
+
+// NewOCSPCertID returns the [OCSPCertID] identifying cert, which must have
+// been issued by issuer, using the hash function h. If h is zero,
+// crypto.SHA1 is used, as is customary for OCSP.
+func NewOCSPCertID(cert, issuer *Certificate, h crypto.Hash) (*OCSPCertID, error) {
+	if h == 0 {
+		h = crypto.SHA1
+	}
+	if _, err := ocspHashOID(h); err != nil {
+		return nil, err
+	}
+	if cert.SerialNumber == nil {
+		return nil, errors.New("x509: certificate has no serial number")
+	}
+	nameHash, keyHash, err := ocspIssuerHashes(issuer, h)
+	if err != nil {
+		return nil, err
+	}
+	return &OCSPCertID{
+		HashAlgorithm:  h,
+		IssuerNameHash: nameHash,
+		IssuerKeyHash:  keyHash,
+		SerialNumber:   new(big.Int).Set(cert.SerialNumber),
+	}, nil
+}
+
+// Matches reports whether id identifies cert as issued by issuer.
+func (id *OCSPCertID) Matches(cert, issuer *Certificate) bool {
+	if id.SerialNumber == nil || cert.SerialNumber == nil || id.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+		return false
+	}
+	if !id.HashAlgorithm.Available() {
+		return false
+	}
+	nameHash, keyHash, err := ocspIssuerHashes(issuer, id.HashAlgorithm)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(id.IssuerNameHash, nameHash) && bytes.Equal(id.IssuerKeyHash, keyHash)
+}
+
+func ocspIssuerHashes(issuer *Certificate, h crypto.Hash) (nameHash, keyHash []byte, err error) {
+	subject, err := subjectBytes(issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := subjectPublicKeyBytes(issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	hh := h.New()
+	hh.Write(subject)
+	nameHash = hh.Sum(nil)
+	hh.Reset()
+	hh.Write(key)
+	keyHash = hh.Sum(nil)
+	return nameHash, keyHash, nil
+}
+
+// subjectPublicKeyBytes returns the contents of the subjectPublicKey BIT
+// STRING of cert, which is what OCSP hashes to identify a key.
+func subjectPublicKeyBytes(cert *Certificate) ([]byte, error) {
+	spki := cryptobyte.String(cert.RawSubjectPublicKeyInfo)
+	if len(spki) == 0 {
+		pub, _, err := marshalPublicKey(cert.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return pub, nil
+	}
+	var key asn1.BitString
+	if !spki.ReadASN1(&spki, cryptobyte_asn1.SEQUENCE) ||
+		!spki.SkipASN1(cryptobyte_asn1.SEQUENCE) ||
+		!spki.ReadASN1BitString(&key) {
+		return nil, errors.New("x509: malformed subject public key info")
+	}
+	return key.Bytes, nil
+}
+
+func ocspHashOID(h crypto.Hash) (asn1.ObjectIdentifier, error) {
+	for _, e := range ocspHashOIDs {
+		if e.hash == h {
+			return e.oid, nil
+		}
+	}
+	return nil, fmt.Errorf("x509: unsupported OCSP hash function %v", h)
+}
+
+func (id *OCSPCertID) marshal(b *cryptobyte.Builder) {
+	oid, err := ocspHashOID(id.HashAlgorithm)
+	if err != nil {
+		b.SetError(err)
+		return
+	}
+	if id.SerialNumber == nil {
+		b.SetError(errors.New("x509: OCSP CertID contains nil SerialNumber field"))
+		return
+	}
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1ObjectIdentifier(oid)
+			b.AddASN1NULL()
+		})
+		b.AddASN1OctetString(id.IssuerNameHash)
+		b.AddASN1OctetString(id.IssuerKeyHash)
+		b.AddASN1BigInt(id.SerialNumber)
+	})
+}
+
+func parseOCSPCertID(der *cryptobyte.String) (OCSPCertID, error) {
+	var id OCSPCertID
+	var seq, aiSeq cryptobyte.String
+	if !der.ReadASN1(&seq, cryptobyte_asn1.SEQUENCE) ||
+		!seq.ReadASN1(&aiSeq, cryptobyte_asn1.SEQUENCE) {
+		return id, errors.New("x509: malformed OCSP CertID")
+	}
+	ai, err := parseAI(aiSeq)
+	if err != nil {
+		return id, err
+	}
+	for _, e := range ocspHashOIDs {
+		if ai.Algorithm.Equal(e.oid) {
+			id.HashAlgorithm = e.hash
+			break
+		}
+	}
+	id.SerialNumber = new(big.Int)
+	if !seq.ReadASN1Bytes(&id.IssuerNameHash, cryptobyte_asn1.OCTET_STRING) ||
+		!seq.ReadASN1Bytes(&id.IssuerKeyHash, cryptobyte_asn1.OCTET_STRING) ||
+		!seq.ReadASN1Integer(id.SerialNumber) || !seq.Empty() {
+		return id, errors.New("x509: malformed OCSP CertID")
+	}
+	return id, nil
+}
+
+// OCSPRequest represents an unsigned OCSP request, as specified in RFC 6960,
+// Section 4.1.
+type OCSPRequest struct {
+	// Raw contains the complete ASN.1 DER content of the request. It is set
+	// when parsing a request; it is ignored when creating a request.
+	Raw []byte
+
+	// CertIDs identifies the certificates whose status is requested. It must
+	// contain at least one element.
+	CertIDs []OCSPCertID
+
+	// Extensions contains raw requestExtensions. When creating a request,
+	// the Extensions field is ignored, see ExtraExtensions.
+	Extensions []pkix.Extension
+	// ExtraExtensions contains extensions to be copied, raw, into the
+	// requestExtensions of a created request.
+	ExtraExtensions []pkix.Extension
+}
+
+// CreateOCSPRequest creates a new OCSP request based on template. The request
+// is not signed, which is what all public OCSP responders expect.
+func CreateOCSPRequest(template *OCSPRequest) ([]byte, error) {
+	if template == nil {
+		return nil, errors.New("x509: template can not be nil")
+	}
+	if len(template.CertIDs) == 0 {
+		return nil, errors.New("x509: OCSP request must contain at least one CertID")
+	}
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				for i := range template.CertIDs {
+					b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+						template.CertIDs[i].marshal(b)
+					})
+				}
+			})
+			marshalOCSPExtensions(b, 2, template.ExtraExtensions)
+		})
+	})
+	return b.Bytes()
+}
+
+// ParseOCSPRequest parses an OCSP request from the given ASN.1 DER data. If
+// the request is signed, the signature is ignored.
+func ParseOCSPRequest(der []byte) (*OCSPRequest, error) {
+	req := &OCSPRequest{}
+	input := cryptobyte.String(der)
+	if !input.ReadASN1Element(&input, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed OCSP request")
+	}
+	req.Raw = input
+	var tbs, list cryptobyte.String
+	if !input.ReadASN1(&input, cryptobyte_asn1.SEQUENCE) ||
+		!input.ReadASN1(&tbs, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed OCSP request")
+	}
+	var version int
+	if !tbs.ReadOptionalASN1Integer(&version, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), 0) {
+		return nil, errors.New("x509: malformed OCSP request version")
+	}
+	if version != 0 {
+		return nil, fmt.Errorf("x509: unsupported OCSP request version: %d", version)
+	}
+	// requestorName is only meaningful for signed requests.
+	if !tbs.SkipOptionalASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+		!tbs.ReadASN1(&list, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed OCSP request")
+	}
+	for !list.Empty() {
+		var single cryptobyte.String
+		if !list.ReadASN1(&single, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("x509: malformed OCSP request")
+		}
+		id, err := parseOCSPCertID(&single)
+		if err != nil {
+			return nil, err
+		}
+		req.CertIDs = append(req.CertIDs, id)
+	}
+	if len(req.CertIDs) == 0 {
+		return nil, errors.New("x509: OCSP request contains no CertIDs")
+	}
+	exts, err := parseOCSPExtensions(&tbs, 2)
+	if err != nil {
+		return nil, err
+	}
+	req.Extensions = exts
+	return req, nil
+}
+
+// OCSPSingleResponse is the status of a single certificate in an
+// [OCSPResponse], as specified in RFC 6960, Section 4.2.1.
+type OCSPSingleResponse struct {
+	// CertID identifies the certificate this response applies to.
+	CertID OCSPCertID
+
+	// Status is the revocation status of the certificate.
+	Status OCSPCertStatus
+	// RevokedAt is the time at which the certificate was revoked. It is only
+	// meaningful if Status is OCSPRevoked, and then must not be zero.
+	RevokedAt time.Time
+	// RevocationReason is the reason for revocation, using the integer enum
+	// values specified in RFC 5280 Section 5.3.1. It is only meaningful if
+	// Status is OCSPRevoked. When creating a response, the zero value
+	// results in the revocationReason field being omitted.
+	RevocationReason int
+
+	// ThisUpdate is the most recent time at which the status is known by the
+	// responder to have been correct.
+	ThisUpdate time.Time
+	// NextUpdate is the time at or before which newer information will be
+	// available. If zero, newer information is available at any time.
+	NextUpdate time.Time
+
+	// Extensions contains raw singleExtensions. When creating a response,
+	// the Extensions field is ignored, see ExtraExtensions.
+	Extensions []pkix.Extension
+	// ExtraExtensions contains extensions to be copied, raw, into the
+	// singleExtensions of a created response.
+	ExtraExtensions []pkix.Extension
+}
+
+// OCSPResponse represents a successful OCSP response of the basic response
+// type, as specified in RFC 6960, Section 4.2.
+//
+// OCSP responses for which the responder did not report success are returned
+// by [ParseOCSPResponse] as an [*OCSPResponseError].
+type OCSPResponse struct {
+	// Raw contains the complete ASN.1 DER content of the OCSPResponse. It is
+	// set when parsing a response; it is ignored when creating a response.
+	Raw []byte
+	// RawResponseData contains just the signed tbsResponseData portion of the
+	// ASN.1 DER.
+	RawResponseData []byte
+
+	// RawResponderName contains the DER encoded name of the responder, if
+	// the responder was identified by name.
+	RawResponderName []byte
+	// ResponderKeyHash contains the SHA-1 hash of the responder's public key,
+	// if the responder was identified by key. It is populated from the
+	// responder certificate when creating a response.
+	ResponderKeyHash []byte
+
+	// ProducedAt is the time at which the response was signed.
+	ProducedAt time.Time
+
+	// Responses contains the status of each certificate covered by the
+	// response. It must contain at least one element.
+	Responses []OCSPSingleResponse
+
+	// Certificates contains certificates that help verify the signature,
+	// usually a delegated responder certificate issued by the CA. When
+	// creating a response they are included in the certs field.
+	Certificates []*Certificate
+
+	Signature []byte
+	// SignatureAlgorithm is used to determine the signature algorithm to be
+	// used when signing the response. If 0 the default algorithm for the
+	// signing key will be used.
+	SignatureAlgorithm SignatureAlgorithm
+
+	// Extensions contains raw responseExtensions. When creating a response,
+	// the Extensions field is ignored, see ExtraExtensions.
+	Extensions []pkix.Extension
+	// ExtraExtensions contains extensions to be copied, raw, into the
+	// responseExtensions of a created response.
+	ExtraExtensions []pkix.Extension
+}
+
+// ParseOCSPResponse parses an OCSP response from the given ASN.1 DER data.
+//
+// If the responder reported a status other than successful, the returned error
+// is an [*OCSPResponseError]. The signature of the response is not checked,
+// see [OCSPResponse.CheckSignatureFrom].
+func ParseOCSPResponse(der []byte) (*OCSPResponse, error) {
+	resp := &OCSPResponse{}
+
+	var input cryptobyte.String
+	outer := cryptobyte.String(der)
+	if !outer.ReadASN1Element(&input, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed OCSP response")
+	}
+	if !outer.Empty() {
+		return nil, errors.New("x509: trailing data after OCSP response")
+	}
+	resp.Raw = input
+	var status int
+	if !input.ReadASN1(&input, cryptobyte_asn1.SEQUENCE) ||
+		!input.ReadASN1Enum(&status) {
+		return nil, errors.New("x509: malformed OCSP response")
+	}
+	if OCSPResponseStatus(status) != OCSPSuccessful {
+		return nil, &OCSPResponseError{OCSPResponseStatus(status)}
+	}
+
+	var responseBytes cryptobyte.String
+	var responseType asn1.ObjectIdentifier
+	var basic cryptobyte.String
+	if !input.ReadASN1(&responseBytes, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!responseBytes.ReadASN1(&responseBytes, cryptobyte_asn1.SEQUENCE) ||
+		!responseBytes.ReadASN1ObjectIdentifier(&responseType) ||
+		!responseBytes.ReadASN1(&basic, cryptobyte_asn1.OCTET_STRING) {
+		return nil, errors.New("x509: malformed OCSP response")
+	}
+	if !responseType.Equal(oidOCSPBasicResponse) {
+		return nil, fmt.Errorf("x509: unsupported OCSP response type %v", responseType)
+	}
+
+	var tbs cryptobyte.String
+	if !basic.ReadASN1(&basic, cryptobyte_asn1.SEQUENCE) ||
+		!basic.ReadASN1Element(&tbs, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed OCSP basic response")
+	}
+	resp.RawResponseData = tbs
+
+	var sigAISeq cryptobyte.String
+	if !basic.ReadASN1(&sigAISeq, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed signature algorithm identifier")
+	}
+	sigAI, err := parseAI(sigAISeq)
+	if err != nil {
+		return nil, err
+	}
+	resp.SignatureAlgorithm = getSignatureAlgorithmFromAI(sigAI)
+
+	var signature asn1.BitString
+	if !basic.ReadASN1BitString(&signature) {
+		return nil, errors.New("x509: malformed signature")
+	}
+	resp.Signature = signature.RightAlign()
+
+	var certs cryptobyte.String
+	var hasCerts bool
+	if !basic.ReadOptionalASN1(&certs, &hasCerts, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return nil, errors.New("x509: malformed OCSP response certificates")
+	}
+	if hasCerts {
+		if !certs.ReadASN1(&certs, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("x509: malformed OCSP response certificates")
+		}
+		for !certs.Empty() {
+			var certDER cryptobyte.String
+			if !certs.ReadASN1Element(&certDER, cryptobyte_asn1.SEQUENCE) {
+				return nil, errors.New("x509: malformed OCSP response certificates")
+			}
+			cert, err := parseCertificate(certDER)
+			if err != nil {
+				return nil, err
+			}
+			resp.Certificates = append(resp.Certificates, cert)
+		}
+	}
+
+	if !tbs.ReadASN1(&tbs, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed OCSP response data")
+	}
+	var version int
+	if !tbs.ReadOptionalASN1Integer(&version, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), 0) {
+		return nil, errors.New("x509: malformed OCSP response version")
+	}
+	if version != 0 {
+		return nil, fmt.Errorf("x509: unsupported OCSP response version: %d", version)
+	}
+
+	var responderID cryptobyte.String
+	switch {
+	case tbs.PeekASN1Tag(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()):
+		if !tbs.ReadASN1(&responderID, cryptobyte_asn1.Tag(1).Constructed().ContextSpecific()) ||
+			!responderID.ReadASN1Element((*cryptobyte.String)(&resp.RawResponderName), cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("x509: malformed OCSP responder name")
+		}
+	case tbs.PeekASN1Tag(cryptobyte_asn1.Tag(2).Constructed().ContextSpecific()):
+		if !tbs.ReadASN1(&responderID, cryptobyte_asn1.Tag(2).Constructed().ContextSpecific()) ||
+			!responderID.ReadASN1Bytes(&resp.ResponderKeyHash, cryptobyte_asn1.OCTET_STRING) {
+			return nil, errors.New("x509: malformed OCSP responder key hash")
+		}
+	default:
+		return nil, errors.New("x509: malformed OCSP responder ID")
+	}
+
+	if !tbs.ReadASN1GeneralizedTime(&resp.ProducedAt) {
+		return nil, errors.New("x509: malformed OCSP producedAt time")
+	}
+
+	var responses cryptobyte.String
+	if !tbs.ReadASN1(&responses, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed OCSP responses")
+	}
+	for !responses.Empty() {
+		var single cryptobyte.String
+		if !responses.ReadASN1(&single, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("x509: malformed OCSP single response")
+		}
+		sr, err := parseOCSPSingleResponse(single)
+		if err != nil {
+			return nil, err
+		}
+		resp.Responses = append(resp.Responses, sr)
+	}
+	if len(resp.Responses) == 0 {
+		return nil, errors.New("x509: OCSP response contains no responses")
+	}
+
+	resp.Extensions, err = parseOCSPExtensions(&tbs, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func parseOCSPSingleResponse(der cryptobyte.String) (OCSPSingleResponse, error) {
+	var sr OCSPSingleResponse
+	var err error
+	sr.CertID, err = parseOCSPCertID(&der)
+	if err != nil {
+		return sr, err
+	}
+
+	var status cryptobyte.String
+	var tag cryptobyte_asn1.Tag
+	if !der.ReadAnyASN1(&status, &tag) {
+		return sr, errors.New("x509: malformed OCSP certificate status")
+	}
+	switch tag {
+	case cryptobyte_asn1.Tag(0).ContextSpecific():
+		sr.Status = OCSPGood
+	case cryptobyte_asn1.Tag(1).Constructed().ContextSpecific():
+		sr.Status = OCSPRevoked
+		if !status.ReadASN1GeneralizedTime(&sr.RevokedAt) {
+			return sr, errors.New("x509: malformed OCSP revocation time")
+		}
+		var reason cryptobyte.String
+		var hasReason bool
+		if !status.ReadOptionalASN1(&reason, &hasReason, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+			return sr, errors.New("x509: malformed OCSP revocation reason")
+		}
+		if hasReason && !reason.ReadASN1Enum(&sr.RevocationReason) {
+			return sr, errors.New("x509: malformed OCSP revocation reason")
+		}
+	case cryptobyte_asn1.Tag(2).ContextSpecific():
+		sr.Status = OCSPUnknown
+	default:
+		return sr, errors.New("x509: malformed OCSP certificate status")
+	}
+
+	if !der.ReadASN1GeneralizedTime(&sr.ThisUpdate) {
+		return sr, errors.New("x509: malformed OCSP thisUpdate time")
+	}
+	var nextUpdate cryptobyte.String
+	var hasNextUpdate bool
+	if !der.ReadOptionalASN1(&nextUpdate, &hasNextUpdate, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) {
+		return sr, errors.New("x509: malformed OCSP nextUpdate time")
+	}
+	if hasNextUpdate && !nextUpdate.ReadASN1GeneralizedTime(&sr.NextUpdate) {
+		return sr, errors.New("x509: malformed OCSP nextUpdate time")
+	}
+	sr.Extensions, err = parseOCSPExtensions(&der, 1)
+	if err != nil {
+		return sr, err
+	}
+	return sr, nil
+}
+
+// parseOCSPExtensions parses an optional Extensions field, explicitly tagged
+// with the context-specific tag number tag.
+func parseOCSPExtensions(der *cryptobyte.String, tag int) ([]pkix.Extension, error) {
+	var extensions cryptobyte.String
+	var present bool
+	if !der.ReadOptionalASN1(&extensions, &present, cryptobyte_asn1.Tag(tag).Constructed().ContextSpecific()) {
+		return nil, errors.New("x509: malformed extensions")
+	}
+	if !present {
+		return nil, nil
+	}
+	if !extensions.ReadASN1(&extensions, cryptobyte_asn1.SEQUENCE) {
+		return nil, errors.New("x509: malformed extensions")
+	}
+	var exts []pkix.Extension
+	for !extensions.Empty() {
+		var extension cryptobyte.String
+		if !extensions.ReadASN1(&extension, cryptobyte_asn1.SEQUENCE) {
+			return nil, errors.New("x509: malformed extension")
+		}
+		ext, err := parseExtension(extension)
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, ext)
+	}
+	return exts, nil
+}
+
+// marshalOCSPExtensions adds exts, if any, explicitly tagged with the
+// context-specific tag number tag.
+func marshalOCSPExtensions(b *cryptobyte.Builder, tag int, exts []pkix.Extension) {
+	if len(exts) == 0 {
+		return
+	}
+	der, err := asn1.Marshal(exts)
+	if err != nil {
+		b.SetError(err)
+		return
+	}
+	b.AddASN1(cryptobyte_asn1.Tag(tag).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+		b.AddBytes(der)
+	})
+}
+
+// CreateOCSPResponse creates a new successful OCSP response of the basic
+// response type, based on template.
+//
+// The response is signed by priv, which should be a crypto.Signer or
+// crypto.MessageSigner associated with the public key in the responder
+// certificate. The responder is either the issuer of the certificates in
+// template.Responses, or a delegated responder certificate issued by it with
+// the [ExtKeyUsageOCSPSigning] extended key usage, in which case it should
+// also be included in template.Certificates.
+//
+// The responderID field is populated with the hash of the responder's public
+// key, and producedAt with template.ProducedAt or, if zero, the current time.
+func CreateOCSPResponse(rand io.Reader, template *OCSPResponse, responder *Certificate, priv crypto.Signer) ([]byte, error) {
+	if template == nil {
+		return nil, errors.New("x509: template can not be nil")
+	}
+	if responder == nil {
+		return nil, errors.New("x509: responder can not be nil")
+	}
+	if len(template.Responses) == 0 {
+		return nil, errors.New("x509: OCSP response must contain at least one single response")
+	}
+
+	signatureAlgorithm, algorithmIdentifier, err := signingParamsForKey(priv, template.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	responderKey, err := subjectPublicKeyBytes(responder)
+	if err != nil {
+		return nil, err
+	}
+	responderKeyHash := crypto.SHA1.New()
+	responderKeyHash.Write(responderKey)
+
+	producedAt := template.ProducedAt
+	if producedAt.IsZero() {
+		producedAt = time.Now()
+	}
+
+	var tbs cryptobyte.Builder
+	tbs.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1(cryptobyte_asn1.Tag(2).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1OctetString(responderKeyHash.Sum(nil))
+		})
+		b.AddASN1GeneralizedTime(producedAt.UTC().Truncate(time.Second))
+		b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+			for i := range template.Responses {
+				marshalOCSPSingleResponse(b, &template.Responses[i])
+			}
+		})
+		marshalOCSPExtensions(b, 1, template.ExtraExtensions)
+	})
+	tbsDER, err := tbs.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signTBS(tbsDER, priv, signatureAlgorithm, rand)
+	if err != nil {
+		return nil, err
+	}
+	sigAI, err := asn1.Marshal(algorithmIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var basic cryptobyte.Builder
+	basic.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddBytes(tbsDER)
+		b.AddBytes(sigAI)
+		b.AddASN1BitString(signature)
+		if len(template.Certificates) > 0 {
+			b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+				b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					for _, c := range template.Certificates {
+						b.AddBytes(c.Raw)
+					}
+				})
+			})
+		}
+	})
+	basicDER, err := basic.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1Enum(int64(OCSPSuccessful))
+		b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+				b.AddASN1ObjectIdentifier(oidOCSPBasicResponse)
+				b.AddASN1OctetString(basicDER)
+			})
+		})
+	})
+	return b.Bytes()
+}
+
+func marshalOCSPSingleResponse(b *cryptobyte.Builder, sr *OCSPSingleResponse) {
+	if sr.ThisUpdate.IsZero() {
+		b.SetError(errors.New("x509: OCSP single response contains zero ThisUpdate field"))
+		return
+	}
+	if !sr.NextUpdate.IsZero() && sr.NextUpdate.Before(sr.ThisUpdate) {
+		b.SetError(errors.New("x509: OCSP single response ThisUpdate is after NextUpdate"))
+		return
+	}
+	b.AddASN1(cryptobyte_asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		sr.CertID.marshal(b)
+		switch sr.Status {
+		case OCSPGood:
+			b.AddASN1(cryptobyte_asn1.Tag(0).ContextSpecific(), func(b *cryptobyte.Builder) {})
+		case OCSPRevoked:
+			if sr.RevokedAt.IsZero() {
+				b.SetError(errors.New("x509: OCSP single response contains zero RevokedAt field"))
+				return
+			}
+			b.AddASN1(cryptobyte_asn1.Tag(1).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+				b.AddASN1GeneralizedTime(sr.RevokedAt.UTC().Truncate(time.Second))
+				if sr.RevocationReason != 0 {
+					b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+						b.AddASN1Enum(int64(sr.RevocationReason))
+					})
+				}
+			})
+		case OCSPUnknown:
+			b.AddASN1(cryptobyte_asn1.Tag(2).ContextSpecific(), func(b *cryptobyte.Builder) {})
+		default:
+			b.SetError(fmt.Errorf("x509: invalid OCSP certificate status %v", sr.Status))
+			return
+		}
+		b.AddASN1GeneralizedTime(sr.ThisUpdate.UTC().Truncate(time.Second))
+		if !sr.NextUpdate.IsZero() {
+			b.AddASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), func(b *cryptobyte.Builder) {
+				b.AddASN1GeneralizedTime(sr.NextUpdate.UTC().Truncate(time.Second))
+			})
+		}
+		marshalOCSPExtensions(b, 1, sr.ExtraExtensions)
+	})
+}
+
+// isResponder reports whether resp identifies cert as its responder.
+func (resp *OCSPResponse) isResponder(cert *Certificate) bool {
+	if len(resp.RawResponderName) > 0 {
+		subject, err := subjectBytes(cert)
+		return err == nil && bytes.Equal(resp.RawResponderName, subject)
+	}
+	key, err := subjectPublicKeyBytes(cert)
+	if err != nil {
+		return false
+	}
+	h := crypto.SHA1.New()
+	h.Write(key)
+	return bytes.Equal(resp.ResponderKeyHash, h.Sum(nil))
+}
+
+// CheckSignatureFrom verifies that the signature on resp is a valid signature
+// from issuer, or from a delegated responder certificate in resp.Certificates
+// that was issued by issuer and has the [ExtKeyUsageOCSPSigning] extended key
+// usage, as specified in RFC 6960, Section 4.2.2.2.
+//
+// The validity period of a delegated responder certificate is not checked.
+// [Certificate.Verify] checks it when [VerifyOptions.Revocation] is set.
+func (resp *OCSPResponse) CheckSignatureFrom(issuer *Certificate) error {
+	_, err := resp.signer(issuer)
+	return err
+}
+
+// signer verifies the signature on resp as described in CheckSignatureFrom,
+// and returns the certificate that produced it: either issuer, or a delegated
+// responder certificate.
+func (resp *OCSPResponse) signer(issuer *Certificate) (*Certificate, error) {
+	if resp.isResponder(issuer) {
+		return issuer, issuer.CheckSignature(resp.SignatureAlgorithm, resp.RawResponseData, resp.Signature)
+	}
+	for _, responder := range resp.Certificates {
+		if !resp.isResponder(responder) {
+			continue
+		}
+		if !slices.Contains(responder.ExtKeyUsage, ExtKeyUsageOCSPSigning) {
+			return nil, errors.New("x509: OCSP responder certificate is not authorized for OCSP signing")
+		}
+		if err := responder.CheckSignatureFrom(issuer); err != nil {
+			return nil, fmt.Errorf("x509: OCSP responder certificate was not issued by the issuer: %w", err)
+		}
+		return responder, responder.CheckSignature(resp.SignatureAlgorithm, resp.RawResponseData, resp.Signature)
+	}
+	return nil, errors.New("x509: OCSP response was not signed by the issuer or a delegated responder")
+}
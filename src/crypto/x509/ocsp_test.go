@@ -0,0 +1,264 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testPKI is a root CA, an intermediate CA and a leaf issued by it.
+type testPKI struct {
+	root, intermediate, leaf          *Certificate
+	rootKey, intermediateKey, leafKey crypto.Signer
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	var pki testPKI
+	pki.root, pki.rootKey = newRevocationTestCert(t, "Root", true, nil, nil, nil)
+	pki.intermediate, pki.intermediateKey = newRevocationTestCert(t, "Intermediate", true, pki.root, pki.rootKey, nil)
+	pki.leaf, pki.leafKey = newRevocationTestCert(t, "leaf.example.com", false, pki.intermediate, pki.intermediateKey, nil)
+	return &pki
+}
+
+func newRevocationTestCert(t *testing.T, cn string, isCA bool, issuer *Certificate, issuerKey crypto.Signer, mutate func(*Certificate)) (*Certificate, crypto.Signer) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              KeyUsageDigitalSignature,
+		ExtKeyUsage:           []ExtKeyUsage{ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= KeyUsageCertSign | KeyUsageCRLSign
+		template.ExtKeyUsage = nil
+	} else {
+		template.DNSNames = []string{cn}
+	}
+	if mutate != nil {
+		mutate(template)
+	}
+	if issuer == nil {
+		issuer, issuerKey = template, priv
+	}
+	der, err := CreateCertificate(rand.Reader, template, issuer, priv.Public(), issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, priv
+}
+
+func TestOCSPRequestRoundTrip(t *testing.T) {
+	pki := newTestPKI(t)
+	for _, h := range []crypto.Hash{0, crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		id, err := NewOCSPCertID(pki.leaf, pki.intermediate, h)
+		if err != nil {
+			t.Fatalf("NewOCSPCertID(%v) failed: %s", h, err)
+		}
+		if !id.Matches(pki.leaf, pki.intermediate) {
+			t.Errorf("OCSPCertID(%v) does not match the certificate it was created for", h)
+		}
+		if id.Matches(pki.intermediate, pki.root) || id.Matches(pki.leaf, pki.root) {
+			t.Errorf("OCSPCertID(%v) matches an unrelated certificate", h)
+		}
+
+		der, err := CreateOCSPRequest(&OCSPRequest{CertIDs: []OCSPCertID{*id}})
+		if err != nil {
+			t.Fatalf("CreateOCSPRequest failed: %s", err)
+		}
+		req, err := ParseOCSPRequest(der)
+		if err != nil {
+			t.Fatalf("ParseOCSPRequest failed: %s", err)
+		}
+		if len(req.CertIDs) != 1 || !reflect.DeepEqual(req.CertIDs[0], *id) {
+			t.Errorf("unexpected CertIDs: got %+v, want %+v", req.CertIDs, *id)
+		}
+	}
+
+	if _, err := NewOCSPCertID(pki.leaf, pki.intermediate, crypto.MD5); err == nil {
+		t.Error("NewOCSPCertID with MD5 succeeded")
+	}
+	if _, err := CreateOCSPRequest(&OCSPRequest{}); err == nil {
+		t.Error("CreateOCSPRequest without CertIDs succeeded")
+	}
+}
+
+func TestOCSPResponseRoundTrip(t *testing.T) {
+	pki := newTestPKI(t)
+	now := time.Now().Truncate(time.Second).UTC()
+	id, err := NewOCSPCertID(pki.leaf, pki.intermediate, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, status := range []OCSPCertStatus{OCSPGood, OCSPRevoked, OCSPUnknown} {
+		sr := OCSPSingleResponse{
+			CertID:     *id,
+			Status:     status,
+			ThisUpdate: now.Add(-time.Hour),
+			NextUpdate: now.Add(time.Hour),
+		}
+		if status == OCSPRevoked {
+			sr.RevokedAt = now.Add(-2 * time.Hour)
+			sr.RevocationReason = 1 // keyCompromise
+		}
+		template := &OCSPResponse{
+			ProducedAt: now,
+			Responses:  []OCSPSingleResponse{sr},
+			ExtraExtensions: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}, Value: []byte{4, 2, 1, 2}},
+			},
+		}
+		der, err := CreateOCSPResponse(rand.Reader, template, pki.intermediate, pki.intermediateKey)
+		if err != nil {
+			t.Fatalf("CreateOCSPResponse(%v) failed: %s", status, err)
+		}
+		resp, err := ParseOCSPResponse(der)
+		if err != nil {
+			t.Fatalf("ParseOCSPResponse(%v) failed: %s", status, err)
+		}
+		if err := resp.CheckSignatureFrom(pki.intermediate); err != nil {
+			t.Errorf("CheckSignatureFrom(%v) failed: %s", status, err)
+		}
+		if err := resp.CheckSignatureFrom(pki.root); err == nil {
+			t.Errorf("CheckSignatureFrom(%v) with the wrong issuer succeeded", status)
+		}
+		if !resp.ProducedAt.Equal(now) {
+			t.Errorf("unexpected ProducedAt: got %v, want %v", resp.ProducedAt, now)
+		}
+		if resp.SignatureAlgorithm != ECDSAWithSHA256 {
+			t.Errorf("unexpected SignatureAlgorithm: got %v", resp.SignatureAlgorithm)
+		}
+		if len(resp.ResponderKeyHash) == 0 || resp.RawResponderName != nil {
+			t.Errorf("responder was not identified by key")
+		}
+		if !reflect.DeepEqual(resp.Extensions, template.ExtraExtensions) {
+			t.Errorf("unexpected Extensions: got %v, want %v", resp.Extensions, template.ExtraExtensions)
+		}
+		if len(resp.Responses) != 1 {
+			t.Fatalf("unexpected number of responses: %d", len(resp.Responses))
+		}
+		got := resp.Responses[0]
+		if got.Status != status || !got.ThisUpdate.Equal(sr.ThisUpdate) || !got.NextUpdate.Equal(sr.NextUpdate) ||
+			!got.RevokedAt.Equal(sr.RevokedAt) || got.RevocationReason != sr.RevocationReason ||
+			!reflect.DeepEqual(got.CertID, sr.CertID) {
+			t.Errorf("unexpected single response: got %+v, want %+v", got, sr)
+		}
+	}
+}
+
+func TestOCSPResponseDelegatedResponder(t *testing.T) {
+	pki := newTestPKI(t)
+	now := time.Now()
+	id, err := NewOCSPCertID(pki.leaf, pki.intermediate, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &OCSPResponse{
+		Responses: []OCSPSingleResponse{{CertID: *id, Status: OCSPGood, ThisUpdate: now}},
+	}
+
+	responder, responderKey := newRevocationTestCert(t, "OCSP Responder", false, pki.intermediate, pki.intermediateKey, func(c *Certificate) {
+		c.ExtKeyUsage = []ExtKeyUsage{ExtKeyUsageOCSPSigning}
+	})
+	template.Certificates = []*Certificate{responder}
+	der, err := CreateOCSPResponse(rand.Reader, template, responder, responderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ParseOCSPResponse(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Certificates) != 1 || !resp.Certificates[0].Equal(responder) {
+		t.Fatalf("responder certificate was not included")
+	}
+	if err := resp.CheckSignatureFrom(pki.intermediate); err != nil {
+		t.Errorf("CheckSignatureFrom failed for a delegated responder: %s", err)
+	}
+
+	unauthorized, unauthorizedKey := newRevocationTestCert(t, "Not a Responder", false, pki.intermediate, pki.intermediateKey, nil)
+	template.Certificates = []*Certificate{unauthorized}
+	der, err = CreateOCSPResponse(rand.Reader, template, unauthorized, unauthorizedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = ParseOCSPResponse(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.CheckSignatureFrom(pki.intermediate); err == nil {
+		t.Errorf("CheckSignatureFrom succeeded for a responder without the OCSP signing EKU")
+	}
+
+	template.Certificates = nil
+	der, err = CreateOCSPResponse(rand.Reader, template, responder, responderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = ParseOCSPResponse(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.CheckSignatureFrom(pki.intermediate); err == nil {
+		t.Errorf("CheckSignatureFrom succeeded without the delegated responder certificate")
+	}
+}
+
+func TestParseOCSPResponseError(t *testing.T) {
+	// OCSPResponse { responseStatus tryLater }
+	der := []byte{0x30, 0x03, 0x0a, 0x01, 0x03}
+	_, err := ParseOCSPResponse(der)
+	var respErr *OCSPResponseError
+	if !errors.As(err, &respErr) || respErr.Status != OCSPTryLater {
+		t.Fatalf("ParseOCSPResponse returned %v, want an OCSPResponseError with status tryLater", err)
+	}
+
+	for _, der := range [][]byte{
+		nil,
+		{0x30, 0x00},
+		{0x30, 0x03, 0x0a, 0x01, 0x00},
+		{0x30, 0x03, 0x0a, 0x01, 0x00, 0x00},
+	} {
+		if _, err := ParseOCSPResponse(der); err == nil {
+			t.Errorf("ParseOCSPResponse(%x) succeeded", der)
+		}
+	}
+
+	pki := newTestPKI(t)
+	der = pki.ocspResponse(t, pki.leaf, pki.intermediate, pki.intermediateKey, OCSPGood, time.Now(), time.Time{})
+	if _, err := ParseOCSPResponse(der); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseOCSPResponse(append(der, 0)); err == nil {
+		t.Errorf("ParseOCSPResponse succeeded with trailing data")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/mldsa"
 	"crypto/rsa"
 	"crypto/x509/pkix"
 	"encoding/asn1"
@@ -319,6 +320,15 @@ func parsePublicKey(keyData *publicKeyInfo) (any, error) {
 			return nil, errors.New("x509: X25519 key encoded with illegal parameters")
 		}
 		return ecdh.X25519().NewPublicKey(der)
+	case oid.Equal(oidPublicKeyMLDSA44), oid.Equal(oidPublicKeyMLDSA65), oid.Equal(oidPublicKeyMLDSA87):
+		// RFC 9881, Section 2
+		// > The contents of the parameters component for each algorithm
+		// > MUST be absent.
+		if len(params.FullBytes) != 0 {
+			return nil, errors.New("x509: ML-DSA key encoded with illegal parameters")
+		}
+		mldsaParams, _ := mldsaParametersFromOID(oid)
+		return mldsa.NewPublicKey(mldsaParams, der)
 	case oid.Equal(oidPublicKeyDSA):
 		y := new(big.Int)
 		if !der.ReadASN1Integer(y) {
@@ -1310,6 +1320,12 @@ func ParseRevocationList(der []byte) (*RevocationList, error) {
 				if !value.ReadASN1Integer(rl.Number) {
 					return nil, errors.New("x509: malformed crl number")
 				}
+			} else if ext.Id.Equal(oidExtensionDeltaCRLIndicator) {
+				value := cryptobyte.String(ext.Value)
+				rl.BaseCRLNumber = new(big.Int)
+				if !value.ReadASN1Integer(rl.BaseCRLNumber) {
+					return nil, errors.New("x509: malformed delta crl indicator")
+				}
 			}
 			rl.Extensions = append(rl.Extensions, ext)
 		}
@@ -0,0 +1,558 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/internal/fips140only"
+	"crypto/password"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+	"unicode/utf16"
+)
+
+// Password-based encryption, as used by PKCS #12 and encrypted PKCS #8
+// private keys. PBES2 is specified in RFC 8018, its use of scrypt in RFC 7914,
+// of AES-GCM in RFC 5084, and the legacy PKCS #12 schemes in RFC 7292,
+// Appendix B and C.
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC  = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128GCM   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 6}
+	oidAES192GCM   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 26}
+	oidAES256GCM   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46}
+	oidScrypt      = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidPBMAC1      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 14}
+	oidSHA1        = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA224      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 4}
+	oidPBEWithSHA1 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1}
+)
+
+// Legacy PKCS #12 password-based encryption schemes, as suffixes of
+// oidPBEWithSHA1, from RFC 7292, Appendix C.
+const (
+	pbeWithSHAAnd3KeyTripleDESCBC = 3
+	pbeWithSHAAnd2KeyTripleDESCBC = 4
+	pbeWithSHAAnd128BitRC2CBC     = 5
+	pbeWithSHAAnd40BitRC2CBC      = 6
+)
+
+// pbeDefaultIterations is the default number of PBKDF2 iterations, which
+// matches OpenSSL. pbeMaxIterations bounds the work done to decrypt or
+// authenticate data with untrusted parameters, and is well above the counts
+// in common use.
+const (
+	pbeDefaultIterations = 2048
+	pbeMaxIterations     = 10_000_000
+)
+
+// The default scrypt parameters match OpenSSL. scryptMaxMemory and
+// scryptMaxP bound the memory and the work used to decrypt keys with untrusted
+// parameters.
+const (
+	scryptDefaultN  = 1 << 14
+	scryptDefaultR  = 8
+	scryptDefaultP  = 1
+	scryptMaxMemory = 1 << 30
+	scryptMaxP      = 16
+)
+
+const gcmStandardNonceSize = 12
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+type gcmParameters struct {
+	Nonce  []byte
+	ICVLen int `asn1:"optional,default:12"`
+}
+
+type pkcs12PBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// hmacHashForPRF returns the hash function of an HMAC PRF algorithm. The
+// default PRF of PBKDF2 is HMAC-SHA-1.
+func hmacHashForPRF(prf pkix.AlgorithmIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(prf.Algorithm) == 0, prf.Algorithm.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case prf.Algorithm.Equal(oidHMACWithSHA224):
+		return sha256.New224, nil
+	case prf.Algorithm.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case prf.Algorithm.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case prf.Algorithm.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, errors.New("x509: unsupported PBKDF2 PRF " + prf.Algorithm.String())
+}
+
+// deriveKeyPBKDF2 derives a key of keyLen bytes, or of the length in params
+// if keyLen is zero, from password with the PBKDF2 parameters in kdf.
+func deriveKeyPBKDF2(kdf pkix.AlgorithmIdentifier, password string, keyLen int) ([]byte, error) {
+	if !kdf.Algorithm.Equal(oidPBKDF2) {
+		return nil, errors.New("x509: unsupported key derivation function " + kdf.Algorithm.String())
+	}
+	var params pbkdf2Params
+	if rest, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+		return nil, errors.New("x509: invalid PBKDF2 parameters: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, errors.New("x509: trailing data after PBKDF2 parameters")
+	}
+	if params.IterationCount < 1 {
+		return nil, errors.New("x509: invalid PBKDF2 iteration count")
+	}
+	if params.IterationCount > pbeMaxIterations {
+		return nil, errors.New("x509: PBKDF2 iteration count is too large")
+	}
+	if keyLen == 0 {
+		keyLen = params.KeyLength
+	} else if params.KeyLength != 0 && params.KeyLength != keyLen {
+		return nil, errors.New("x509: PBKDF2 key length does not match the cipher")
+	}
+	if keyLen <= 0 {
+		return nil, errors.New("x509: missing PBKDF2 key length")
+	}
+	h, err := hmacHashForPRF(params.PRF)
+	if err != nil {
+		return nil, err
+	}
+	return pbkdf2.Key(h, password, params.Salt, params.IterationCount, keyLen)
+}
+
+// pbkdf2AlgorithmIdentifier returns the PBKDF2 algorithm identifier for
+// HMAC-SHA-256 with a random salt. keyLen is included in the parameters if it
+// is not zero.
+func pbkdf2AlgorithmIdentifier(iterations, keyLen int) (pkix.AlgorithmIdentifier, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	params, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: iterations,
+		KeyLength:      keyLen,
+		PRF: pkix.AlgorithmIdentifier{
+			Algorithm:  oidHMACWithSHA256,
+			Parameters: asn1.NullRawValue,
+		},
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  oidPBKDF2,
+		Parameters: asn1.RawValue{FullBytes: params},
+	}, nil
+}
+
+// pbeDecrypt decrypts ciphertext with the password-based encryption scheme
+// identified by algo. It returns IncorrectPasswordError if the padding of the
+// decrypted plaintext, or the GCM authentication tag, is invalid.
+func pbeDecrypt(algo pkix.AlgorithmIdentifier, password string, ciphertext []byte) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+	switch {
+	case algo.Algorithm.Equal(oidPBES2):
+		var params pbes2Params
+		if rest, err := asn1.Unmarshal(algo.Parameters.FullBytes, &params); err != nil {
+			return nil, errors.New("x509: invalid PBES2 parameters: " + err.Error())
+		} else if len(rest) != 0 {
+			return nil, errors.New("x509: trailing data after PBES2 parameters")
+		}
+		enc := params.EncryptionScheme
+		keyLen, isGCM, ok := pbes2Cipher(enc.Algorithm)
+		if !ok {
+			return nil, errors.New("x509: unsupported PBES2 encryption scheme " + enc.Algorithm.String())
+		}
+		key, err := deriveKeyPBES2(params.KeyDerivationFunc, password, keyLen)
+		if err != nil {
+			return nil, err
+		}
+		if isGCM {
+			return gcmOpen(enc, key, ciphertext)
+		}
+		if rest, err := asn1.Unmarshal(enc.Parameters.FullBytes, &iv); err != nil {
+			return nil, errors.New("x509: invalid PBES2 IV: " + err.Error())
+		} else if len(rest) != 0 {
+			return nil, errors.New("x509: trailing data after PBES2 IV")
+		}
+		if enc.Algorithm.Equal(oidDESEDE3CBC) {
+			if fips140only.Enabled {
+				return nil, errors.New("x509: use of 3DES is not allowed in FIPS 140-only mode")
+			}
+			block, err = des.NewTripleDESCipher(key)
+		} else {
+			block, err = aes.NewCipher(key)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+	case len(algo.Algorithm) == len(oidPBEWithSHA1)+1 && algo.Algorithm[:len(oidPBEWithSHA1)].Equal(oidPBEWithSHA1):
+		if fips140only.Enabled {
+			return nil, errors.New("x509: use of legacy PKCS #12 encryption is not allowed in FIPS 140-only mode")
+		}
+		var params pkcs12PBEParams
+		if rest, err := asn1.Unmarshal(algo.Parameters.FullBytes, &params); err != nil {
+			return nil, errors.New("x509: invalid PKCS #12 PBE parameters: " + err.Error())
+		} else if len(rest) != 0 {
+			return nil, errors.New("x509: trailing data after PKCS #12 PBE parameters")
+		}
+		if params.Iterations < 1 {
+			return nil, errors.New("x509: invalid PKCS #12 PBE iteration count")
+		}
+		if params.Iterations > pbeMaxIterations {
+			return nil, errors.New("x509: PKCS #12 PBE iteration count is too large")
+		}
+		pass, err := bmpString(password)
+		if err != nil {
+			return nil, err
+		}
+		derive := func(id byte, size int) []byte {
+			return pkcs12KDF(sha1.New, pass, params.Salt, id, params.Iterations, size)
+		}
+		switch algo.Algorithm[len(oidPBEWithSHA1)] {
+		case pbeWithSHAAnd3KeyTripleDESCBC:
+			block, err = des.NewTripleDESCipher(derive(1, 24))
+		case pbeWithSHAAnd2KeyTripleDESCBC:
+			key := derive(1, 16)
+			block, err = des.NewTripleDESCipher(append(key, key[:8]...))
+		case pbeWithSHAAnd128BitRC2CBC:
+			block = newRC2(derive(1, 16), 128)
+		case pbeWithSHAAnd40BitRC2CBC:
+			block = newRC2(derive(1, 5), 40)
+		default:
+			return nil, errors.New("x509: unsupported PKCS #12 encryption algorithm " + algo.Algorithm.String())
+		}
+		if err != nil {
+			return nil, err
+		}
+		iv = derive(2, block.BlockSize())
+
+	default:
+		return nil, errors.New("x509: unsupported encryption algorithm " + algo.Algorithm.String())
+	}
+
+	if len(iv) != block.BlockSize() {
+		return nil, errors.New("x509: invalid IV length")
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return nil, errors.New("x509: encrypted data is not a multiple of the block size")
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// Remove the PKCS #7 padding. A wrong password yields random padding.
+	n := int(plaintext[len(plaintext)-1])
+	if n == 0 || n > block.BlockSize() {
+		return nil, IncorrectPasswordError
+	}
+	if !bytes.Equal(plaintext[len(plaintext)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, IncorrectPasswordError
+	}
+	return plaintext[:len(plaintext)-n], nil
+}
+
+// pbes2Cipher returns the key length of a PBES2 encryption scheme, and
+// whether it is AES-GCM rather than a CBC mode.
+func pbes2Cipher(oid asn1.ObjectIdentifier) (keyLen int, isGCM, ok bool) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return 16, false, true
+	case oid.Equal(oidAES192CBC):
+		return 24, false, true
+	case oid.Equal(oidAES256CBC):
+		return 32, false, true
+	case oid.Equal(oidDESEDE3CBC):
+		return 24, false, true
+	case oid.Equal(oidAES128GCM):
+		return 16, true, true
+	case oid.Equal(oidAES192GCM):
+		return 24, true, true
+	case oid.Equal(oidAES256GCM):
+		return 32, true, true
+	}
+	return 0, false, false
+}
+
+// gcmOpen decrypts ciphertext with AES-GCM, with the parameters of enc as
+// specified in RFC 5084, Section 3.2.
+func gcmOpen(enc pkix.AlgorithmIdentifier, key, ciphertext []byte) ([]byte, error) {
+	var params gcmParameters
+	if rest, err := asn1.Unmarshal(enc.Parameters.FullBytes, &params); err != nil {
+		return nil, errors.New("x509: invalid AES-GCM parameters: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, errors.New("x509: trailing data after AES-GCM parameters")
+	}
+	if len(params.Nonce) != gcmStandardNonceSize {
+		return nil, errors.New("x509: unsupported AES-GCM nonce size")
+	}
+	if params.ICVLen < 12 || params.ICVLen > 16 {
+		return nil, errors.New("x509: unsupported AES-GCM tag size")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCMWithTagSize(block, params.ICVLen)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, params.Nonce, ciphertext, nil)
+	if err != nil {
+		return nil, IncorrectPasswordError
+	}
+	return plaintext, nil
+}
+
+// deriveKeyPBES2 derives a key of keyLen bytes from pass with the PBKDF2 or
+// scrypt parameters in kdf.
+func deriveKeyPBES2(kdf pkix.AlgorithmIdentifier, pass string, keyLen int) ([]byte, error) {
+	if !kdf.Algorithm.Equal(oidScrypt) {
+		return deriveKeyPBKDF2(kdf, pass, keyLen)
+	}
+	var params scryptParams
+	if rest, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+		return nil, errors.New("x509: invalid scrypt parameters: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, errors.New("x509: trailing data after scrypt parameters")
+	}
+	if params.KeyLength != 0 && params.KeyLength != keyLen {
+		return nil, errors.New("x509: scrypt key length does not match the cipher")
+	}
+	// Bound the memory an untrusted key can make the parser allocate.
+	if params.CostParameter < 0 || params.BlockSize < 0 ||
+		uint64(params.CostParameter)*uint64(params.BlockSize) > scryptMaxMemory/128 {
+		return nil, errors.New("x509: scrypt parameters require too much memory")
+	}
+	if params.ParallelizationParameter > scryptMaxP {
+		return nil, errors.New("x509: scrypt parallelization parameter is too large")
+	}
+	key, err := password.Scrypt(pass, params.Salt, params.CostParameter, params.BlockSize, params.ParallelizationParameter, keyLen)
+	if err != nil {
+		return nil, errors.New("x509: " + err.Error())
+	}
+	return key, nil
+}
+
+// pbes2Encrypt encrypts plaintext with PBES2 according to opts.
+func pbes2Encrypt(password string, plaintext []byte, opts *PKCS8EncryptionOptions) (pkix.AlgorithmIdentifier, []byte, error) {
+	var encOID asn1.ObjectIdentifier
+	switch opts.Cipher {
+	case PKCS8CipherAES256CBC:
+		encOID = oidAES256CBC
+	case PKCS8CipherAES128CBC:
+		encOID = oidAES128CBC
+	case PKCS8CipherAES256GCM:
+		encOID = oidAES256GCM
+	case PKCS8CipherAES128GCM:
+		encOID = oidAES128GCM
+	default:
+		return pkix.AlgorithmIdentifier{}, nil, errors.New("x509: unknown PKCS #8 cipher")
+	}
+	keyLen, isGCM, _ := pbes2Cipher(encOID)
+
+	var kdf pkix.AlgorithmIdentifier
+	var err error
+	switch opts.KDF {
+	case PKCS8KDFPBKDF2:
+		iterations := opts.Iterations
+		if iterations == 0 {
+			iterations = pbeDefaultIterations
+		}
+		if iterations < 0 || iterations > pbeMaxIterations {
+			return pkix.AlgorithmIdentifier{}, nil, errors.New("x509: invalid PBKDF2 iteration count")
+		}
+		kdf, err = pbkdf2AlgorithmIdentifier(iterations, 0)
+	case PKCS8KDFScrypt:
+		kdf, err = scryptAlgorithmIdentifier()
+	default:
+		return pkix.AlgorithmIdentifier{}, nil, errors.New("x509: unknown PKCS #8 key derivation function")
+	}
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	key, err := deriveKeyPBES2(kdf, password, keyLen)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+
+	var encParams, ciphertext []byte
+	if isGCM {
+		nonce := make([]byte, gcmStandardNonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		ciphertext = aead.Seal(nil, nonce, plaintext, nil)
+		encParams, err = asn1.Marshal(gcmParameters{Nonce: nonce, ICVLen: aead.Overhead()})
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+	} else {
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+		n := aes.BlockSize - len(plaintext)%aes.BlockSize
+		ciphertext = append(bytes.Clone(plaintext), bytes.Repeat([]byte{byte(n)}, n)...)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+		encParams, err = asn1.Marshal(iv)
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, nil, err
+		}
+	}
+
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: kdf,
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  encOID,
+			Parameters: asn1.RawValue{FullBytes: encParams},
+		},
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	algo := pkix.AlgorithmIdentifier{
+		Algorithm:  oidPBES2,
+		Parameters: asn1.RawValue{FullBytes: params},
+	}
+	return algo, ciphertext, nil
+}
+
+// scryptAlgorithmIdentifier returns the scrypt algorithm identifier for the
+// default parameters, with a random salt.
+func scryptAlgorithmIdentifier() (pkix.AlgorithmIdentifier, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	params, err := asn1.Marshal(scryptParams{
+		Salt:                     salt,
+		CostParameter:            scryptDefaultN,
+		BlockSize:                scryptDefaultR,
+		ParallelizationParameter: scryptDefaultP,
+	})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  oidScrypt,
+		Parameters: asn1.RawValue{FullBytes: params},
+	}, nil
+}
+
+// bmpString returns s encoded as a null-terminated, big-endian UCS-2 string,
+// as PKCS #12 passwords are encoded for the legacy key derivation function.
+func bmpString(s string) ([]byte, error) {
+	out := make([]byte, 0, 2*len(s)+2)
+	for _, r := range s {
+		if utf16.IsSurrogate(r) || r > 0xffff {
+			return nil, errors.New("x509: PKCS #12 password contains characters outside the Basic Multilingual Plane")
+		}
+		out = append(out, byte(r>>8), byte(r))
+	}
+	return append(out, 0, 0), nil
+}
+
+// pkcs12KDF derives size bytes of key material for the purpose id (1 for
+// encryption keys, 2 for IVs and 3 for MAC keys) from the BMPString-encoded
+// password, according to RFC 7292, Appendix B.2.
+func pkcs12KDF(h func() hash.Hash, password, salt []byte, id byte, iterations, size int) []byte {
+	d := h()
+	v := d.BlockSize()
+
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+	I := append(fill(salt), fill(password)...)
+	D := bytes.Repeat([]byte{id}, v)
+
+	var out []byte
+	for {
+		d.Reset()
+		d.Write(D)
+		d.Write(I)
+		A := d.Sum(nil)
+		for range iterations - 1 {
+			d.Reset()
+			d.Write(A)
+			A = d.Sum(A[:0])
+		}
+		out = append(out, A...)
+		if len(out) >= size {
+			return out[:size]
+		}
+
+		// Set each v-byte block of I to I_j + B + 1 mod 2^(8v), where B is A
+		// repeated to v bytes.
+		B := fill(A)[:v]
+		for j := 0; j < len(I); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				carry += int(I[j+k]) + int(B[k])
+				I[j+k] = byte(carry)
+				carry >>= 8
+			}
+		}
+	}
+}
@@ -0,0 +1,555 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/internal/fips140only"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+)
+
+// PKCS #12 is specified in RFC 7292, and PBMAC1 for PKCS #12 in RFC 9579.
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidCertTypeX509        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+
+	oidAttributeLocalKeyID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+)
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe pkcs12ContentInfo
+	MacData  pkcs12MacData `asn1:"optional"`
+}
+
+type pkcs12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type pkcs12EncryptedData struct {
+	Version              int
+	EncryptedContentInfo pkcs12EncryptedContentInfo
+}
+
+type pkcs12EncryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type pkcs12MacData struct {
+	Mac        pkcs12DigestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type pkcs12DigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type pbmac1Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	MessageAuthScheme pkix.AlgorithmIdentifier
+}
+
+type pkcs12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type pkcs12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+// ParsePKCS12 parses a PKCS #12 file (also known as PFX, and commonly stored
+// with a .p12 or .pfx extension), as specified in RFC 7292, and decrypts it
+// with password.
+//
+// It returns the private key, if the file contains one, and the certificates.
+// The key is of one of the types returned by [ParsePKCS8PrivateKey]. If a
+// certificate matches the private key, it is returned first, followed by the
+// other certificates in the order they appear in the file. An error is
+// returned if the file contains more than one private key.
+//
+// Both files encrypted with PBES2 and AES, as produced by modern software, and
+// the legacy encryption schemes based on RC2 and 3DES are supported. The
+// integrity of the file is verified with either the PKCS #12 MAC or PBMAC1, as
+// specified in RFC 9579. Files without a MAC are accepted, but their integrity
+// can't be verified. Files protected with public-key integrity or privacy
+// modes are not supported. Iteration counts above 10,000,000 are rejected, to
+// bound the work done to parse untrusted files.
+//
+// If the password is incorrect, [IncorrectPasswordError] is returned.
+func ParsePKCS12(data []byte, password string) (key any, certs []*Certificate, err error) {
+	var pfx pfxPDU
+	if rest, err := asn1.Unmarshal(data, &pfx); err != nil {
+		return nil, nil, errors.New("x509: failed to parse PKCS #12: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, nil, errors.New("x509: trailing data after PKCS #12")
+	}
+	if pfx.Version != 3 {
+		return nil, nil, errors.New("x509: unsupported PKCS #12 version")
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidDataContentType) {
+		return nil, nil, errors.New("x509: PKCS #12 files with public-key integrity are not supported")
+	}
+	var authSafe []byte
+	if err := unmarshalContent(pfx.AuthSafe, &authSafe); err != nil {
+		return nil, nil, err
+	}
+	if len(pfx.MacData.Mac.Algorithm.Algorithm) != 0 {
+		if err := verifyPKCS12MAC(&pfx.MacData, authSafe, password); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var contents []pkcs12ContentInfo
+	if err := unmarshalFull(authSafe, &contents); err != nil {
+		return nil, nil, errors.New("x509: failed to parse PKCS #12 authenticated safe: " + err.Error())
+	}
+	var keyID []byte
+	var certIDs [][]byte
+	for _, ci := range contents {
+		var safeContents []byte
+		switch {
+		case ci.ContentType.Equal(oidDataContentType):
+			if err := unmarshalContent(ci, &safeContents); err != nil {
+				return nil, nil, err
+			}
+		case ci.ContentType.Equal(oidEncryptedDataContentType):
+			var ed pkcs12EncryptedData
+			if err := unmarshalContent(ci, &ed); err != nil {
+				return nil, nil, err
+			}
+			eci := ed.EncryptedContentInfo
+			safeContents, err = pbeDecrypt(eci.ContentEncryptionAlgorithm, password, eci.EncryptedContent)
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, errors.New("x509: PKCS #12 files with public-key privacy are not supported")
+		}
+
+		var bags []pkcs12SafeBag
+		if err := unmarshalFull(safeContents, &bags); err != nil {
+			return nil, nil, errors.New("x509: failed to parse PKCS #12 safe contents: " + err.Error())
+		}
+		for _, bag := range bags {
+			var pkcs8 []byte
+			switch {
+			case bag.ID.Equal(oidKeyBag):
+				pkcs8 = bag.Value.Bytes
+			case bag.ID.Equal(oidPKCS8ShroudedKeyBag):
+				var epk encryptedPKCS8
+				if err := unmarshalFull(bag.Value.Bytes, &epk); err != nil {
+					return nil, nil, errors.New("x509: failed to parse PKCS #12 shrouded key bag: " + err.Error())
+				}
+				pkcs8, err = pbeDecrypt(epk.Algo, password, epk.EncryptedData)
+				if err != nil {
+					return nil, nil, err
+				}
+			case bag.ID.Equal(oidCertBag):
+				var cb pkcs12CertBag
+				if err := unmarshalFull(bag.Value.Bytes, &cb); err != nil {
+					return nil, nil, errors.New("x509: failed to parse PKCS #12 certificate bag: " + err.Error())
+				}
+				if !cb.ID.Equal(oidCertTypeX509) {
+					continue
+				}
+				cert, err := ParseCertificate(cb.Data)
+				if err != nil {
+					return nil, nil, err
+				}
+				certs = append(certs, cert)
+				certIDs = append(certIDs, localKeyID(bag.Attributes))
+				continue
+			default:
+				// CRL, secret, and nested safe contents bags are ignored.
+				continue
+			}
+
+			if key != nil {
+				return nil, nil, errors.New("x509: PKCS #12 file contains more than one private key")
+			}
+			key, err = ParsePKCS8PrivateKey(pkcs8)
+			if err != nil {
+				return nil, nil, err
+			}
+			keyID = localKeyID(bag.Attributes)
+		}
+	}
+
+	if key == nil {
+		return nil, certs, nil
+	}
+	// Move the certificate of the private key first, identifying it by its
+	// local key ID, or by its public key if the IDs are missing.
+	leaf := -1
+	for i, id := range certIDs {
+		if keyID != nil && bytes.Equal(id, keyID) {
+			leaf = i
+			break
+		}
+	}
+	if leaf < 0 {
+		for i, cert := range certs {
+			if publicKeyMatches(key, cert) {
+				leaf = i
+				break
+			}
+		}
+	}
+	if leaf > 0 {
+		certs = append(append([]*Certificate{certs[leaf]}, certs[:leaf]...), certs[leaf+1:]...)
+	}
+	return key, certs, nil
+}
+
+// unmarshalContent parses the [0] EXPLICIT content of ci into out.
+func unmarshalContent(ci pkcs12ContentInfo, out any) error {
+	if err := unmarshalFull(ci.Content.Bytes, out); err != nil {
+		return errors.New("x509: failed to parse PKCS #12 content: " + err.Error())
+	}
+	return nil
+}
+
+func unmarshalFull(b []byte, out any) error {
+	rest, err := asn1.Unmarshal(b, out)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("trailing data")
+	}
+	return nil
+}
+
+// localKeyID returns the value of the localKeyId attribute, or nil.
+func localKeyID(attrs []pkcs12Attribute) []byte {
+	for _, attr := range attrs {
+		if !attr.ID.Equal(oidAttributeLocalKeyID) {
+			continue
+		}
+		var id []byte
+		if _, err := asn1.Unmarshal(attr.Value.Bytes, &id); err == nil {
+			return id
+		}
+	}
+	return nil
+}
+
+func publicKeyMatches(key any, cert *Certificate) bool {
+	priv, ok := key.(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return false
+	}
+	pub, ok := priv.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && pub.Equal(cert.PublicKey)
+}
+
+// pkcs12MACHash returns the hash function of a PKCS #12 MAC digest algorithm.
+func pkcs12MACHash(algo asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case algo.Equal(oidSHA1):
+		return sha1.New, nil
+	case algo.Equal(oidSHA224):
+		return sha256.New224, nil
+	case algo.Equal(oidSHA256):
+		return sha256.New, nil
+	case algo.Equal(oidSHA384):
+		return sha512.New384, nil
+	case algo.Equal(oidSHA512):
+		return sha512.New, nil
+	}
+	return nil, errors.New("x509: unsupported PKCS #12 MAC algorithm " + algo.String())
+}
+
+// verifyPKCS12MAC checks the MAC over the authenticated safe.
+func verifyPKCS12MAC(md *pkcs12MacData, authSafe []byte, password string) error {
+	var h func() hash.Hash
+	var key []byte
+	algo := md.Mac.Algorithm
+	if algo.Algorithm.Equal(oidPBMAC1) {
+		var params pbmac1Params
+		if err := unmarshalFull(algo.Parameters.FullBytes, &params); err != nil {
+			return errors.New("x509: invalid PBMAC1 parameters: " + err.Error())
+		}
+		var err error
+		if h, err = hmacHashForPRF(params.MessageAuthScheme); err != nil {
+			return err
+		}
+		// The key length must be explicit in the PBKDF2 parameters.
+		if key, err = deriveKeyPBKDF2(params.KeyDerivationFunc, password, 0); err != nil {
+			return err
+		}
+	} else {
+		if fips140only.Enabled {
+			return errors.New("x509: use of the PKCS #12 MAC key derivation function is not allowed in FIPS 140-only mode")
+		}
+		var err error
+		if h, err = pkcs12MACHash(algo.Algorithm); err != nil {
+			return err
+		}
+		if md.Iterations < 1 {
+			return errors.New("x509: invalid PKCS #12 MAC iteration count")
+		}
+		if md.Iterations > pbeMaxIterations {
+			return errors.New("x509: PKCS #12 MAC iteration count is too large")
+		}
+		pass, err := bmpString(password)
+		if err != nil {
+			return err
+		}
+		key = pkcs12KDF(h, pass, md.MacSalt, 3, md.Iterations, h().Size())
+	}
+
+	mac := hmac.New(h, key)
+	mac.Write(authSafe)
+	if !hmac.Equal(mac.Sum(nil), md.Mac.Digest) {
+		return IncorrectPasswordError
+	}
+	return nil
+}
+
+// PKCS12Options contains options for [MarshalPKCS12].
+type PKCS12Options struct {
+	// Iterations is the number of PBKDF2 iterations used to derive the
+	// encryption and MAC keys from the password. If zero, 2048 is used, which
+	// matches OpenSSL. Higher values make brute-forcing the password harder,
+	// up to 10,000,000, the largest count accepted by [ParsePKCS12].
+	Iterations int
+
+	// PBMAC1 selects PBMAC1 with HMAC-SHA-256, as specified in RFC 9579, to
+	// protect the integrity of the file. Otherwise, the PKCS #12 MAC with
+	// HMAC-SHA-256 is used, which is supported by more software but relies on
+	// a key derivation function that is not approved by NIST. PBMAC1 is
+	// always used in FIPS 140-only mode.
+	PBMAC1 bool
+}
+
+// MarshalPKCS12 returns a PKCS #12 file, as specified in RFC 7292, that
+// contains key and certs, encrypted and authenticated with password.
+//
+// key may be nil, to store only certificates. Otherwise, it must be of one
+// of the types supported by [MarshalPKCS8PrivateKey], and certs[0] must be the
+// certificate for key. The other certificates are usually its chain.
+//
+// The private key and the certificates are encrypted with PBES2, using PBKDF2
+// with HMAC-SHA-256 and AES-256-CBC, which is supported by OpenSSL 1.1.1 and
+// later, and by Windows 10 1709 and later. opts may be nil, to use the
+// default options.
+func MarshalPKCS12(key any, certs []*Certificate, password string, opts *PKCS12Options) ([]byte, error) {
+	if opts == nil {
+		opts = &PKCS12Options{}
+	}
+	iterations := opts.Iterations
+	if iterations == 0 {
+		iterations = pbeDefaultIterations
+	}
+	if iterations < 0 || iterations > pbeMaxIterations {
+		return nil, errors.New("x509: invalid PKCS #12 iteration count")
+	}
+
+	var keyID []byte
+	if key != nil {
+		if len(certs) == 0 || !publicKeyMatches(key, certs[0]) {
+			return nil, errors.New("x509: the first certificate does not match the private key")
+		}
+		h := sha1.Sum(certs[0].Raw)
+		keyID = h[:]
+	}
+
+	var authSafe []pkcs12ContentInfo
+	if len(certs) > 0 {
+		var bags []pkcs12SafeBag
+		for i, cert := range certs {
+			cb, err := asn1.Marshal(pkcs12CertBag{ID: oidCertTypeX509, Data: cert.Raw})
+			if err != nil {
+				return nil, err
+			}
+			var id []byte
+			if i == 0 {
+				id = keyID
+			}
+			bag, err := newPKCS12SafeBag(oidCertBag, cb, id)
+			if err != nil {
+				return nil, err
+			}
+			bags = append(bags, bag)
+		}
+		safeContents, err := asn1.Marshal(bags)
+		if err != nil {
+			return nil, err
+		}
+		algo, encrypted, err := pbes2Encrypt(password, safeContents, &PKCS8EncryptionOptions{Iterations: iterations})
+		if err != nil {
+			return nil, err
+		}
+		ci, err := newPKCS12ContentInfo(oidEncryptedDataContentType, pkcs12EncryptedData{
+			Version: 0,
+			EncryptedContentInfo: pkcs12EncryptedContentInfo{
+				ContentType:                oidDataContentType,
+				ContentEncryptionAlgorithm: algo,
+				EncryptedContent:           encrypted,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		authSafe = append(authSafe, ci)
+	}
+
+	if key != nil {
+		pkcs8, err := MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		algo, encrypted, err := pbes2Encrypt(password, pkcs8, &PKCS8EncryptionOptions{Iterations: iterations})
+		if err != nil {
+			return nil, err
+		}
+		epk, err := asn1.Marshal(encryptedPKCS8{Algo: algo, EncryptedData: encrypted})
+		if err != nil {
+			return nil, err
+		}
+		bag, err := newPKCS12SafeBag(oidPKCS8ShroudedKeyBag, epk, keyID)
+		if err != nil {
+			return nil, err
+		}
+		safeContents, err := asn1.Marshal([]pkcs12SafeBag{bag})
+		if err != nil {
+			return nil, err
+		}
+		ci, err := newPKCS12ContentInfo(oidDataContentType, safeContents)
+		if err != nil {
+			return nil, err
+		}
+		authSafe = append(authSafe, ci)
+	}
+
+	authSafeBytes, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+	pfx := pfxPDU{Version: 3}
+	pfx.AuthSafe, err = newPKCS12ContentInfo(oidDataContentType, authSafeBytes)
+	if err != nil {
+		return nil, err
+	}
+	pfx.MacData, err = newPKCS12MAC(authSafeBytes, password, iterations, opts.PBMAC1 || fips140only.Enabled)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pfx)
+}
+
+// newPKCS12ContentInfo returns a ContentInfo with the DER encoding of content.
+func newPKCS12ContentInfo(contentType asn1.ObjectIdentifier, content any) (pkcs12ContentInfo, error) {
+	der, err := asn1.Marshal(content)
+	if err != nil {
+		return pkcs12ContentInfo{}, err
+	}
+	return pkcs12ContentInfo{
+		ContentType: contentType,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der},
+	}, nil
+}
+
+// newPKCS12SafeBag returns a SafeBag with the given DER value, and a
+// localKeyId attribute if keyID is not nil.
+func newPKCS12SafeBag(id asn1.ObjectIdentifier, value []byte, keyID []byte) (pkcs12SafeBag, error) {
+	bag := pkcs12SafeBag{
+		ID:    id,
+		Value: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value},
+	}
+	if keyID != nil {
+		v, err := asn1.Marshal(keyID)
+		if err != nil {
+			return pkcs12SafeBag{}, err
+		}
+		bag.Attributes = []pkcs12Attribute{{
+			ID:    oidAttributeLocalKeyID,
+			Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: v},
+		}}
+	}
+	return bag, nil
+}
+
+// newPKCS12MAC computes the MAC over the authenticated safe, with PBMAC1 or
+// the PKCS #12 MAC, using HMAC-SHA-256.
+func newPKCS12MAC(authSafe []byte, password string, iterations int, usePBMAC1 bool) (pkcs12MacData, error) {
+	var md pkcs12MacData
+	var key []byte
+	if usePBMAC1 {
+		kdf, err := pbkdf2AlgorithmIdentifier(iterations, sha256.Size)
+		if err != nil {
+			return md, err
+		}
+		if key, err = deriveKeyPBKDF2(kdf, password, 0); err != nil {
+			return md, err
+		}
+		params, err := asn1.Marshal(pbmac1Params{
+			KeyDerivationFunc: kdf,
+			MessageAuthScheme: pkix.AlgorithmIdentifier{
+				Algorithm:  oidHMACWithSHA256,
+				Parameters: asn1.NullRawValue,
+			},
+		})
+		if err != nil {
+			return md, err
+		}
+		md.Mac.Algorithm = pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBMAC1,
+			Parameters: asn1.RawValue{FullBytes: params},
+		}
+		// The salt and iteration count of MacData are ignored with PBMAC1,
+		// and set to the values of the RFC 9579 examples.
+		md.MacSalt = []byte("NOT USED")
+		md.Iterations = 1
+	} else {
+		md.MacSalt = make([]byte, 16)
+		if _, err := rand.Read(md.MacSalt); err != nil {
+			return md, err
+		}
+		md.Iterations = iterations
+		pass, err := bmpString(password)
+		if err != nil {
+			return md, err
+		}
+		key = pkcs12KDF(sha256.New, pass, md.MacSalt, 3, iterations, sha256.Size)
+		md.Mac.Algorithm = pkix.AlgorithmIdentifier{
+			Algorithm:  oidSHA256,
+			Parameters: asn1.NullRawValue,
+		}
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(authSafe)
+	md.Mac.Digest = mac.Sum(nil)
+	return md, nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"crypto/x509/pkix"
+)
+
+// The files in testdata/pkcs12 were generated with OpenSSL 3.0 from a P-256
+// leaf certificate and key, and its issuer:
+//
+//	openssl pkcs12 -export -legacy -passout env:P ... -out legacy.p12
+//	openssl pkcs12 -export -passout env:P ... -out modern.p12
+//	openssl pkcs12 -export -keypbe NONE -certpbe NONE -nomac ... -out plain.p12
+//
+// where P is "pässwörd". legacy.p12 uses 40-bit RC2 for the certificates,
+// 3DES for the key, and a SHA-1 MAC. modern.p12 uses PBES2 with AES-256-CBC
+// and a SHA-256 MAC.
+const pkcs12TestPassword = "pässwörd"
+
+const pkcs12TestKey = "f2df7564cb449fbe26c5a883ae07c1f72f17365d8134f7829e9ee11d09d751da"
+
+func TestParsePKCS12(t *testing.T) {
+	for _, tt := range []struct {
+		file     string
+		password string
+	}{
+		{"legacy.p12", pkcs12TestPassword},
+		{"modern.p12", pkcs12TestPassword},
+		{"plain.p12", ""},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile("testdata/pkcs12/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, certs, err := ParsePKCS12(data, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ecKey, ok := key.(*ecdsa.PrivateKey)
+			if !ok {
+				t.Fatalf("got key of type %T, want *ecdsa.PrivateKey", key)
+			}
+			if got := hex.EncodeToString(ecKey.D.Bytes()); got != pkcs12TestKey {
+				t.Errorf("got private key %s, want %s", got, pkcs12TestKey)
+			}
+			if len(certs) != 2 {
+				t.Fatalf("got %d certificates, want 2", len(certs))
+			}
+			if got := certs[0].Subject.CommonName; got != "leaf.example" {
+				t.Errorf("got first certificate %q, want leaf.example", got)
+			}
+			if got := certs[1].Subject.CommonName; got != "Test CA" {
+				t.Errorf("got second certificate %q, want Test CA", got)
+			}
+			if err := certs[0].CheckSignatureFrom(certs[1]); err != nil {
+				t.Errorf("leaf is not signed by the CA: %v", err)
+			}
+
+			if tt.password != "" {
+				if _, _, err := ParsePKCS12(data, "wrong"); !errors.Is(err, IncorrectPasswordError) {
+					t.Errorf("wrong password: got %v, want IncorrectPasswordError", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPKCS12RoundTrip(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	leafDER, err := CreateCertificate(rand.Reader, leafTemplate, ca, pub, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []*PKCS12Options{nil, {Iterations: 10}, {Iterations: 10, PBMAC1: true}} {
+		data, err := MarshalPKCS12(priv, []*Certificate{leaf, ca}, "secret", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, certs, err := ParsePKCS12(data, "secret")
+		if err != nil {
+			t.Fatalf("%+v: %v", opts, err)
+		}
+		if !priv.Equal(key) {
+			t.Errorf("%+v: private key did not round-trip", opts)
+		}
+		if len(certs) != 2 || !certs[0].Equal(leaf) || !certs[1].Equal(ca) {
+			t.Errorf("%+v: certificates did not round-trip", opts)
+		}
+		if _, _, err := ParsePKCS12(data, "wrong"); !errors.Is(err, IncorrectPasswordError) {
+			t.Errorf("%+v: wrong password: got %v, want IncorrectPasswordError", opts, err)
+		}
+	}
+
+	data, err := MarshalPKCS12(nil, []*Certificate{ca}, "", &PKCS12Options{Iterations: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, certs, err := ParsePKCS12(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != nil || len(certs) != 1 || !certs[0].Equal(ca) {
+		t.Errorf("certificate-only file did not round-trip")
+	}
+
+	if _, err := MarshalPKCS12(priv, []*Certificate{ca, leaf}, "secret", nil); err == nil {
+		t.Errorf("MarshalPKCS12 accepted a first certificate not matching the key")
+	}
+}
+
+func TestPKCS12IterationLimit(t *testing.T) {
+	md := &pkcs12MacData{
+		Mac:        pkcs12DigestInfo{Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256}},
+		MacSalt:    []byte("salt"),
+		Iterations: pbeMaxIterations + 1,
+	}
+	if err := verifyPKCS12MAC(md, nil, "secret"); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("verifyPKCS12MAC: got %v, want an iteration count error", err)
+	}
+
+	params, err := asn1.Marshal(pkcs12PBEParams{Salt: []byte("salt"), Iterations: pbeMaxIterations + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	algo := pkix.AlgorithmIdentifier{
+		Algorithm:  append(slices.Clip(oidPBEWithSHA1), pbeWithSHAAnd3KeyTripleDESCBC),
+		Parameters: asn1.RawValue{FullBytes: params},
+	}
+	if _, err := pbeDecrypt(algo, "secret", make([]byte, 8)); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("pbeDecrypt: got %v, want an iteration count error", err)
+	}
+
+	if _, err := MarshalPKCS12(nil, nil, "secret", &PKCS12Options{Iterations: pbeMaxIterations + 1}); err == nil {
+		t.Errorf("MarshalPKCS12 accepted an iteration count above the limit")
+	}
+}
+
+func TestPKCS12KDF(t *testing.T) {
+	// From golang.org/x/crypto/pkcs12.
+	password, err := bmpString("sesame")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := pkcs12KDF(sha1.New, password, bytes.Repeat([]byte{0xff}, 8), 1, 2048, 24)
+	want, _ := hex.DecodeString("7cd9fd3e2b3be7691a44e3bef0f9ea0fb9b897d4e325d9d1")
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+
+	// This input yields a block of I with a leading zero byte.
+	got = pkcs12KDF(sha1.New, []byte{0, 0}, []byte("\xf3\x7e\x05\xb5\x18\x32\x4b\x4b"), 1, 2048, 24)
+	want, _ = hex.DecodeString("00f759ff47d14dd03665d5943cb3c4a39a2555c02aed66e1")
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestRC2(t *testing.T) {
+	// From RFC 2268, Section 5.
+	for _, tt := range []struct {
+		key, plaintext, ciphertext string
+		bits                       int
+	}{
+		{"0000000000000000", "0000000000000000", "ebb773f993278eff", 63},
+		{"ffffffffffffffff", "ffffffffffffffff", "278b27e42e2f0d49", 64},
+		{"3000000000000000", "1000000000000001", "30649edf9be7d2c2", 64},
+		{"88", "0000000000000000", "61a8a244adacccf0", 64},
+		{"88bca90e90875a", "0000000000000000", "6ccf4308974c267f", 64},
+		{"88bca90e90875a7f0f79c384627bafb2", "0000000000000000", "1a807d272bbe5db1", 64},
+		{"88bca90e90875a7f0f79c384627bafb2", "0000000000000000", "2269552ab0f85ca6", 128},
+		{"88bca90e90875a7f0f79c384627bafb216f80a6f85920584c42fceb0be255daf1e", "0000000000000000", "5b78d3a43dfff1f1", 129},
+	} {
+		key, _ := hex.DecodeString(tt.key)
+		plaintext, _ := hex.DecodeString(tt.plaintext)
+		ciphertext, _ := hex.DecodeString(tt.ciphertext)
+		c := newRC2(key, tt.bits)
+		var out [8]byte
+		c.Encrypt(out[:], plaintext)
+		if !bytes.Equal(out[:], ciphertext) {
+			t.Errorf("Encrypt(%s): got %x, want %x", tt.key, out, ciphertext)
+		}
+		c.Decrypt(out[:], ciphertext)
+		if !bytes.Equal(out[:], plaintext) {
+			t.Errorf("Decrypt(%s): got %x, want %x", tt.key, out, plaintext)
+		}
+	}
+}
@@ -8,11 +8,17 @@ import (
 	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/ed25519"
+	fipsmldsa "crypto/internal/fips140/mldsa"
+	"crypto/mldsa"
 	"crypto/rsa"
+	"crypto/subtle"
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"errors"
 	"fmt"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
 )
 
 // pkcs8 reflects an ASN.1, PKCS #8 PrivateKey. See
@@ -28,8 +34,12 @@ type pkcs8 struct {
 // ParsePKCS8PrivateKey parses an unencrypted private key in PKCS #8, ASN.1 DER form.
 //
 // It returns a *[rsa.PrivateKey], an *[ecdsa.PrivateKey], an [ed25519.PrivateKey] (not
-// a pointer), or an *[ecdh.PrivateKey] (for X25519). More types might be supported
-// in the future.
+// a pointer), an *[ecdh.PrivateKey] (for X25519), or an *[mldsa.PrivateKey]. More
+// types might be supported in the future.
+//
+// ML-DSA private keys must include the 32-byte seed, optionally alongside the
+// expanded key, as specified in RFC 9881. Keys encoded only in expanded form
+// are rejected.
 //
 // This kind of key is commonly encoded in PEM blocks of type "PRIVATE KEY".
 //
@@ -89,6 +99,15 @@ func ParsePKCS8PrivateKey(der []byte) (key any, err error) {
 		}
 		return ecdh.X25519().NewPrivateKey(curvePrivateKey)
 
+	case privKey.Algo.Algorithm.Equal(oidPublicKeyMLDSA44),
+		privKey.Algo.Algorithm.Equal(oidPublicKeyMLDSA65),
+		privKey.Algo.Algorithm.Equal(oidPublicKeyMLDSA87):
+		if l := len(privKey.Algo.Parameters.FullBytes); l != 0 {
+			return nil, errors.New("x509: invalid ML-DSA private key parameters")
+		}
+		params, _ := mldsaParametersFromOID(privKey.Algo.Algorithm)
+		return parseMLDSAPrivateKey(params, privKey.PrivateKey)
+
 	default:
 		return nil, fmt.Errorf("x509: PKCS#8 wrapping contained private key with unknown algorithm: %v", privKey.Algo.Algorithm)
 	}
@@ -97,7 +116,8 @@ func ParsePKCS8PrivateKey(der []byte) (key any, err error) {
 // MarshalPKCS8PrivateKey converts a private key to PKCS #8, ASN.1 DER form.
 //
 // The following key types are currently supported: *[rsa.PrivateKey],
-// *[ecdsa.PrivateKey], [ed25519.PrivateKey] (not a pointer), and *[ecdh.PrivateKey].
+// *[ecdsa.PrivateKey], [ed25519.PrivateKey] (not a pointer), *[ecdh.PrivateKey],
+// and *[mldsa.PrivateKey]. ML-DSA keys are encoded in the seed-only form.
 // Unsupported key types result in an error.
 //
 // This kind of key is commonly encoded in PEM blocks of type "PRIVATE KEY".
@@ -176,9 +196,203 @@ func MarshalPKCS8PrivateKey(key any) ([]byte, error) {
 			}
 		}
 
+	case *mldsa.PrivateKey:
+		oid, ok := oidFromMLDSAParameters(k.Parameters())
+		if !ok {
+			return nil, errors.New("x509: unknown ML-DSA parameters while marshaling to PKCS#8")
+		}
+		privKey.Algo = pkix.AlgorithmIdentifier{
+			Algorithm: oid,
+		}
+		// RFC 9881, Section 6 recommends the seed-only form.
+		var b cryptobyte.Builder
+		b.AddASN1(cryptobyte_asn1.Tag(0).ContextSpecific(), func(b *cryptobyte.Builder) {
+			b.AddBytes(k.Bytes())
+		})
+		privKey.PrivateKey = b.BytesOrPanic()
+
 	default:
 		return nil, fmt.Errorf("x509: unknown key type while marshaling PKCS#8: %T", key)
 	}
 
 	return asn1.Marshal(privKey)
 }
+
+// parseMLDSAPrivateKey parses the ML-DSA private key CHOICE from RFC 9881,
+// Section 6.
+//
+//	ML-DSA-PrivateKey ::= CHOICE {
+//	  seed [0] OCTET STRING (SIZE (32)),
+//	  expandedKey OCTET STRING,
+//	  both SEQUENCE {
+//	    seed OCTET STRING (SIZE (32)),
+//	    expandedKey OCTET STRING
+//	  }
+//	}
+func parseMLDSAPrivateKey(params mldsa.Parameters, der []byte) (*mldsa.PrivateKey, error) {
+	input := cryptobyte.String(der)
+	var seed, expanded cryptobyte.String
+	switch {
+	case input.PeekASN1Tag(cryptobyte_asn1.Tag(0).ContextSpecific()):
+		if !input.ReadASN1(&seed, cryptobyte_asn1.Tag(0).ContextSpecific()) {
+			return nil, errors.New("x509: invalid ML-DSA private key")
+		}
+	case input.PeekASN1Tag(cryptobyte_asn1.OCTET_STRING):
+		return nil, errors.New("x509: ML-DSA private keys without a seed are not supported")
+	case input.PeekASN1Tag(cryptobyte_asn1.SEQUENCE):
+		var both cryptobyte.String
+		if !input.ReadASN1(&both, cryptobyte_asn1.SEQUENCE) ||
+			!both.ReadASN1(&seed, cryptobyte_asn1.OCTET_STRING) ||
+			!both.ReadASN1(&expanded, cryptobyte_asn1.OCTET_STRING) ||
+			!both.Empty() {
+			return nil, errors.New("x509: invalid ML-DSA private key")
+		}
+	default:
+		return nil, errors.New("x509: invalid ML-DSA private key")
+	}
+	if !input.Empty() {
+		return nil, errors.New("x509: trailing data after ML-DSA private key")
+	}
+	if len(seed) != mldsa.SeedSize {
+		return nil, fmt.Errorf("x509: invalid ML-DSA private key seed length: %d", len(seed))
+	}
+	key, err := mldsa.NewPrivateKey(params, seed)
+	if err != nil {
+		return nil, err
+	}
+	if expanded != nil {
+		// RFC 9881, Section 8 requires checking that the seed and the
+		// expanded key are consistent.
+		var fipsParams *fipsmldsa.Parameters
+		switch params {
+		case mldsa.MLDSA44():
+			fipsParams = fipsmldsa.MLDSA44()
+		case mldsa.MLDSA65():
+			fipsParams = fipsmldsa.MLDSA65()
+		case mldsa.MLDSA87():
+			fipsParams = fipsmldsa.MLDSA87()
+		}
+		k, err := fipsmldsa.NewPrivateKey(fipsParams, seed)
+		if err != nil {
+			return nil, err
+		}
+		if subtle.ConstantTimeCompare(k.ExpandedBytes(), expanded) != 1 {
+			return nil, errors.New("x509: ML-DSA private key seed and expanded key do not match")
+		}
+	}
+	return key, nil
+}
+
+// encryptedPKCS8 reflects an ASN.1, PKCS #8 EncryptedPrivateKeyInfo. See
+// RFC 5958, Section 3.
+type encryptedPKCS8 struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// ParsePKCS8EncryptedPrivateKey decrypts a private key in encrypted PKCS #8,
+// ASN.1 DER form, with password, and parses it like [ParsePKCS8PrivateKey].
+//
+// Keys encrypted with PBES2, as specified in RFC 8018, are supported, with
+// PBKDF2 or scrypt as the key derivation function, and AES-CBC, AES-GCM, or
+// 3DES-CBC as the cipher. Keys encrypted with the legacy PKCS #12 schemes are
+// also supported. The password is used as UTF-8 bytes by PBES2.
+//
+// To bound the work done to parse untrusted keys, iteration counts above
+// 10,000,000, and scrypt parameters that require more than 1 GiB of memory or
+// a parallelization parameter above 16, are rejected.
+//
+// If the password is incorrect, [IncorrectPasswordError] is returned.
+//
+// This kind of key is commonly encoded in PEM blocks of type "ENCRYPTED
+// PRIVATE KEY".
+func ParsePKCS8EncryptedPrivateKey(der []byte, password string) (key any, err error) {
+	var epk encryptedPKCS8
+	if rest, err := asn1.Unmarshal(der, &epk); err != nil {
+		return nil, errors.New("x509: failed to parse encrypted PKCS#8: " + err.Error())
+	} else if len(rest) != 0 {
+		return nil, errors.New("x509: trailing data after encrypted PKCS#8")
+	}
+	plaintext, err := pbeDecrypt(epk.Algo, password, epk.EncryptedData)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := asn1.Unmarshal(plaintext, &pkcs8{}); err != nil {
+		// With an incorrect password, the padding of CBC modes is valid by
+		// chance about once in 256 times.
+		return nil, IncorrectPasswordError
+	}
+	return ParsePKCS8PrivateKey(plaintext)
+}
+
+// PKCS8Cipher is a cipher for [MarshalPKCS8EncryptedPrivateKey].
+type PKCS8Cipher int
+
+const (
+	// PKCS8CipherAES256CBC is AES-256 in CBC mode, which is supported by
+	// OpenSSL and most other software.
+	PKCS8CipherAES256CBC PKCS8Cipher = iota
+
+	// PKCS8CipherAES128CBC is AES-128 in CBC mode.
+	PKCS8CipherAES128CBC
+
+	// PKCS8CipherAES256GCM is AES-256 in GCM mode, as specified in RFC 5084.
+	PKCS8CipherAES256GCM
+
+	// PKCS8CipherAES128GCM is AES-128 in GCM mode, as specified in RFC 5084.
+	PKCS8CipherAES128GCM
+)
+
+// PKCS8KDF is a password-based key derivation function for
+// [MarshalPKCS8EncryptedPrivateKey].
+type PKCS8KDF int
+
+const (
+	// PKCS8KDFPBKDF2 is PBKDF2 with HMAC-SHA-256.
+	PKCS8KDFPBKDF2 PKCS8KDF = iota
+
+	// PKCS8KDFScrypt is scrypt, as specified in RFC 7914, with N=16384, r=8,
+	// and p=1. It is not allowed in FIPS 140-only mode.
+	PKCS8KDFScrypt
+)
+
+// PKCS8EncryptionOptions contains options for
+// [MarshalPKCS8EncryptedPrivateKey].
+type PKCS8EncryptionOptions struct {
+	// Cipher is the cipher used to encrypt the key. The zero value is
+	// AES-256-CBC, which is the most widely supported. AES-GCM is not
+	// supported by OpenSSL.
+	Cipher PKCS8Cipher
+
+	// KDF is the function used to derive the encryption key from the
+	// password. The zero value is PBKDF2 with HMAC-SHA-256.
+	KDF PKCS8KDF
+
+	// Iterations is the number of PBKDF2 iterations. If zero, 2048 is used,
+	// which matches OpenSSL. Higher values make brute-forcing the password
+	// harder, up to 10,000,000, the largest count accepted by
+	// [ParsePKCS8EncryptedPrivateKey].
+	Iterations int
+}
+
+// MarshalPKCS8EncryptedPrivateKey converts a private key to PKCS #8, like
+// [MarshalPKCS8PrivateKey], and encrypts it with PBES2, as specified in RFC
+// 8018, using a key derived from password. opts may be nil, to use the
+// default options.
+//
+// This kind of key is commonly encoded in PEM blocks of type "ENCRYPTED
+// PRIVATE KEY".
+func MarshalPKCS8EncryptedPrivateKey(key any, password string, opts *PKCS8EncryptionOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &PKCS8EncryptionOptions{}
+	}
+	der, err := MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	algo, ciphertext, err := pbes2Encrypt(password, der, opts)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPKCS8{Algo: algo, EncryptedData: ciphertext})
+}
@@ -0,0 +1,269 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"time"
+)
+
+// RevocationPolicy controls how [Certificate.Verify] treats certificates
+// whose revocation status can't be determined.
+type RevocationPolicy int
+
+const (
+	// RevocationSoftFail rejects certificates that are known to be revoked,
+	// but accepts certificates whose status can't be determined from the
+	// configured sources, for example because a responder is unreachable.
+	RevocationSoftFail RevocationPolicy = iota
+	// RevocationHardFail rejects certificates that are known to be revoked,
+	// as well as certificates whose status can't be determined.
+	RevocationHardFail
+)
+
+// RevocationOptions configures revocation checking in [Certificate.Verify].
+//
+// Revocation status is checked for every certificate in a chain except the
+// root. A certificate is considered revoked if any of OCSPResponses and
+// RevocationLists reports it as such, even if another reports it as not
+// revoked. Otherwise, its status is known if one of them covers it and reports
+// it as not revoked. OCSPResponder is only consulted if neither determines the
+// status.
+type RevocationOptions struct {
+	// RevocationLists are CRLs to check certificates against. A CRL is only
+	// considered for a certificate if it was issued by, and is correctly
+	// signed by, the certificate's issuer and if the verification time is
+	// within its ThisUpdate and NextUpdate.
+	//
+	// CRLs with an issuing distribution point extension, which may cover only
+	// some certificates or revocation reasons, and CRLs with unrecognized
+	// critical extensions are ignored. Of the remaining CRLs of an issuer,
+	// only the most recent complete CRL is used, along with the most recent
+	// delta CRL that applies to it.
+	RevocationLists []*RevocationList
+
+	// OCSPResponses are DER encoded OCSP responses obtained ahead of time, for
+	// example stapled in a TLS handshake. Responses that fail to parse, don't
+	// match a certificate, aren't signed by an authorized responder, or are
+	// not current at the verification time are ignored.
+	OCSPResponses [][]byte
+
+	// OCSPResponder, if not nil, is called to obtain a DER encoded OCSP
+	// response for cert, issued by issuer, when its status was not determined
+	// by the other sources. An error is treated as an undetermined status.
+	// Responses are validated like those in OCSPResponses.
+	OCSPResponder func(cert, issuer *Certificate) ([]byte, error)
+
+	// Policy determines whether certificates with undetermined status are
+	// accepted. The zero value is RevocationSoftFail.
+	Policy RevocationPolicy
+}
+
+// revocationStatus is the outcome of checking one certificate. The values are
+// ordered so that the outcomes of several sources combine with max: a revoked
+// status wins over a good one, which wins over an unknown one.
+type revocationStatus int
+
+const (
+	statusUnknown revocationStatus = iota
+	statusGood
+	statusRevoked
+)
+
+// CRL reason codes for entries that temporarily suspend a certificate, and
+// for entries that undo a previous certificateHold in a delta CRL. See RFC 5280,
+// Section 5.3.1.
+const (
+	crlReasonCertificateHold = 6
+	crlReasonRemoveFromCRL   = 8
+)
+
+var oidExtensionIssuingDistributionPoint = asn1.ObjectIdentifier{2, 5, 29, 28}
+
+// revocationChecker checks chains against RevocationOptions, caching results
+// per certificate and issuer pair across the chains of one verification.
+type revocationChecker struct {
+	opts *RevocationOptions
+	now  time.Time
+
+	ocsp  []*OCSPResponse
+	cache map[[2]*Certificate]revocationStatus
+}
+
+func newRevocationChecker(opts *RevocationOptions, now time.Time) *revocationChecker {
+	rc := &revocationChecker{opts: opts, now: now, cache: make(map[[2]*Certificate]revocationStatus)}
+	for _, der := range opts.OCSPResponses {
+		if resp, err := ParseOCSPResponse(der); err == nil {
+			rc.ocsp = append(rc.ocsp, resp)
+		}
+	}
+	return rc
+}
+
+// checkChain returns an error if a certificate of chain is revoked or, with
+// RevocationHardFail, if its status could not be determined.
+func (rc *revocationChecker) checkChain(chain []*Certificate) error {
+	for i := 0; i < len(chain)-1; i++ {
+		cert, issuer := chain[i], chain[i+1]
+		switch rc.status(cert, issuer) {
+		case statusRevoked:
+			return CertificateInvalidError{cert, Revoked, ""}
+		case statusUnknown:
+			if rc.opts.Policy == RevocationHardFail {
+				return CertificateInvalidError{cert, RevocationStatusUnknown, ""}
+			}
+		}
+	}
+	return nil
+}
+
+func (rc *revocationChecker) status(cert, issuer *Certificate) revocationStatus {
+	key := [2]*Certificate{cert, issuer}
+	if s, ok := rc.cache[key]; ok {
+		return s
+	}
+	s := max(rc.ocspStatus(rc.ocsp, cert, issuer), rc.crlStatus(cert, issuer))
+	if s == statusUnknown && rc.opts.OCSPResponder != nil {
+		if der, err := rc.opts.OCSPResponder(cert, issuer); err == nil {
+			if resp, err := ParseOCSPResponse(der); err == nil {
+				s = rc.ocspStatus([]*OCSPResponse{resp}, cert, issuer)
+			}
+		}
+	}
+	rc.cache[key] = s
+	return s
+}
+
+func (rc *revocationChecker) ocspStatus(responses []*OCSPResponse, cert, issuer *Certificate) revocationStatus {
+	status := statusUnknown
+	for _, resp := range responses {
+		for _, sr := range resp.Responses {
+			if !sr.CertID.Matches(cert, issuer) {
+				continue
+			}
+			if rc.now.Before(sr.ThisUpdate) || !sr.NextUpdate.IsZero() && rc.now.After(sr.NextUpdate) {
+				continue
+			}
+			signer, err := resp.signer(issuer)
+			if err != nil {
+				continue
+			}
+			// A delegated responder certificate must be valid at the time
+			// of the check, like the certificates of the chain.
+			if signer != issuer && (rc.now.Before(signer.NotBefore) || rc.now.After(signer.NotAfter)) {
+				continue
+			}
+			switch sr.Status {
+			case OCSPGood:
+				status = max(status, statusGood)
+			case OCSPRevoked:
+				return statusRevoked
+			}
+		}
+	}
+	return status
+}
+
+func (rc *revocationChecker) crlStatus(cert, issuer *Certificate) revocationStatus {
+	var complete, delta *RevocationList
+	for _, crl := range rc.opts.RevocationLists {
+		if len(crl.RawIssuer) == 0 || !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+			continue
+		}
+		if rc.now.Before(crl.ThisUpdate) || !crl.NextUpdate.IsZero() && rc.now.After(crl.NextUpdate) {
+			continue
+		}
+		if !crlIsUsable(crl) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+		if crl.BaseCRLNumber != nil {
+			if crl.Number != nil && (delta == nil || crl.Number.Cmp(delta.Number) > 0) {
+				delta = crl
+			}
+		} else if complete == nil || crlIsNewer(crl, complete) {
+			complete = crl
+		}
+	}
+	if complete == nil {
+		return statusUnknown
+	}
+
+	// A more recent complete CRL supersedes the older ones, which may still
+	// list certificates that were taken off hold since.
+	held := false
+	if entry := findCRLEntry(complete, cert); entry != nil && entry.ReasonCode != crlReasonRemoveFromCRL {
+		if entry.ReasonCode != crlReasonCertificateHold {
+			return statusRevoked
+		}
+		held = true
+	}
+
+	// Delta CRLs list the changes since their base CRL, so only the most
+	// recent one is needed, and only if it is more recent than the complete
+	// CRL, which is itself at least as recent as its base. See RFC 5280,
+	// Section 5.2.4.
+	if delta != nil && complete.Number != nil &&
+		complete.Number.Cmp(delta.BaseCRLNumber) >= 0 && delta.Number.Cmp(complete.Number) > 0 {
+		if entry := findCRLEntry(delta, cert); entry != nil {
+			switch entry.ReasonCode {
+			case crlReasonRemoveFromCRL:
+				held = false
+			case crlReasonCertificateHold:
+				held = true
+			default:
+				return statusRevoked
+			}
+		}
+	}
+	if held {
+		return statusRevoked
+	}
+	return statusGood
+}
+
+// crlIsUsable reports whether crl can be used to determine the status of any
+// certificate of its issuer. A CRL with an issuing distribution point may
+// cover only some of them, and RFC 5280, Section 5.2 forbids using a CRL with
+// unrecognized critical CRL or entry extensions.
+func crlIsUsable(crl *RevocationList) bool {
+	for _, ext := range crl.Extensions {
+		if ext.Id.Equal(oidExtensionIssuingDistributionPoint) {
+			return false
+		}
+		if ext.Critical && !ext.Id.Equal(oidExtensionDeltaCRLIndicator) {
+			return false
+		}
+	}
+	// None of the entry extensions that are recognized, reasonCode and
+	// invalidityDate, is critical.
+	for _, entry := range crl.RevokedCertificateEntries {
+		for _, ext := range entry.Extensions {
+			if ext.Critical {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// crlIsNewer reports whether a was issued after b, according to their CRL
+// numbers or, if either has none, to their ThisUpdate.
+func crlIsNewer(a, b *RevocationList) bool {
+	if a.Number != nil && b.Number != nil {
+		return a.Number.Cmp(b.Number) > 0
+	}
+	return a.ThisUpdate.After(b.ThisUpdate)
+}
+
+// findCRLEntry returns the entry for cert in crl, or nil if there is none.
+func findCRLEntry(crl *RevocationList, cert *Certificate) *RevocationListEntry {
+	for i := range crl.RevokedCertificateEntries {
+		if crl.RevokedCertificateEntries[i].SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return &crl.RevokedCertificateEntries[i]
+		}
+	}
+	return nil
+}
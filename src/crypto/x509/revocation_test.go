@@ -0,0 +1,266 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func (pki *testPKI) verifyOptions(rev *RevocationOptions) VerifyOptions {
+	roots, intermediates := NewCertPool(), NewCertPool()
+	roots.AddCert(pki.root)
+	intermediates.AddCert(pki.intermediate)
+	return VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		Revocation:    rev,
+	}
+}
+
+func (pki *testPKI) crl(t *testing.T, issuer *Certificate, issuerKey crypto.Signer, thisUpdate, nextUpdate time.Time, revoked ...*Certificate) *RevocationList {
+	t.Helper()
+	template := &RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: thisUpdate,
+		NextUpdate: nextUpdate,
+	}
+	for _, c := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, RevocationListEntry{
+			SerialNumber:   c.SerialNumber,
+			RevocationTime: thisUpdate,
+		})
+	}
+	return pki.crlFromTemplate(t, template, issuer, issuerKey)
+}
+
+func (pki *testPKI) crlFromTemplate(t *testing.T, template *RevocationList, issuer *Certificate, issuerKey crypto.Signer) *RevocationList {
+	t.Helper()
+	der, err := CreateRevocationList(rand.Reader, template, issuer, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := ParseRevocationList(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crl
+}
+
+func (pki *testPKI) ocspResponse(t *testing.T, cert, issuer *Certificate, issuerKey crypto.Signer, status OCSPCertStatus, thisUpdate, nextUpdate time.Time) []byte {
+	t.Helper()
+	id, err := NewOCSPCertID(cert, issuer, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := OCSPSingleResponse{CertID: *id, Status: status, ThisUpdate: thisUpdate, NextUpdate: nextUpdate}
+	if status == OCSPRevoked {
+		sr.RevokedAt = thisUpdate
+	}
+	der, err := CreateOCSPResponse(rand.Reader, &OCSPResponse{Responses: []OCSPSingleResponse{sr}}, issuer, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// delegatedOCSPResponse returns an OCSP response for cert signed by a
+// delegated responder of issuer, which is valid until notAfter.
+func (pki *testPKI) delegatedOCSPResponse(t *testing.T, cert, issuer *Certificate, issuerKey crypto.Signer, status OCSPCertStatus, thisUpdate, nextUpdate, notAfter time.Time) []byte {
+	t.Helper()
+	responder, responderKey := newRevocationTestCert(t, "OCSP Responder", false, issuer, issuerKey, func(c *Certificate) {
+		c.ExtKeyUsage = []ExtKeyUsage{ExtKeyUsageOCSPSigning}
+		c.NotBefore = notAfter.Add(-24 * time.Hour)
+		c.NotAfter = notAfter
+	})
+	id, err := NewOCSPCertID(cert, issuer, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr := OCSPSingleResponse{CertID: *id, Status: status, ThisUpdate: thisUpdate, NextUpdate: nextUpdate}
+	if status == OCSPRevoked {
+		sr.RevokedAt = thisUpdate
+	}
+	template := &OCSPResponse{Responses: []OCSPSingleResponse{sr}, Certificates: []*Certificate{responder}}
+	der, err := CreateOCSPResponse(rand.Reader, template, responder, responderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestVerifyRevocation(t *testing.T) {
+	pki := newTestPKI(t)
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	leafRevoked := pki.crl(t, pki.intermediate, pki.intermediateKey, past, future, pki.leaf)
+	leafNotRevoked := pki.crl(t, pki.intermediate, pki.intermediateKey, past, future)
+	leafRevokedStale := pki.crl(t, pki.intermediate, pki.intermediateKey, past.Add(-time.Hour), past, pki.leaf)
+	intermediateRevoked := pki.crl(t, pki.root, pki.rootKey, past, future, pki.intermediate)
+	intermediateNotRevoked := pki.crl(t, pki.root, pki.rootKey, past, future)
+	// Signed by the root instead of the intermediate, with the intermediate's name.
+	forgedCRL := pki.crl(t, pki.root, pki.rootKey, past, future, pki.leaf)
+	forgedCRL.RawIssuer = leafRevoked.RawIssuer
+
+	leafEntry := RevocationListEntry{SerialNumber: pki.leaf.SerialNumber, RevocationTime: past}
+	// Only covers the certificates of a distribution point, which is not
+	// checked against the leaf.
+	leafRevokedIDP := pki.crlFromTemplate(t, &RevocationList{
+		Number: big.NewInt(1), ThisUpdate: past, NextUpdate: future,
+		RevokedCertificateEntries: []RevocationListEntry{leafEntry},
+		ExtraExtensions:           []pkix.Extension{{Id: oidExtensionIssuingDistributionPoint, Critical: true, Value: []byte{0x30, 0x00}}},
+	}, pki.intermediate, pki.intermediateKey)
+	leafRevokedUnknownCritical := pki.crlFromTemplate(t, &RevocationList{
+		Number: big.NewInt(1), ThisUpdate: past, NextUpdate: future,
+		RevokedCertificateEntries: []RevocationListEntry{leafEntry},
+		ExtraExtensions:           []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Critical: true, Value: []byte{0x05, 0x00}}},
+	}, pki.intermediate, pki.intermediateKey)
+	// A hold in a complete CRL, lifted by a more recent complete CRL.
+	heldEntry := leafEntry
+	heldEntry.ReasonCode = crlReasonCertificateHold
+	leafHeld := pki.crlFromTemplate(t, &RevocationList{
+		Number: big.NewInt(1), ThisUpdate: past, NextUpdate: future,
+		RevokedCertificateEntries: []RevocationListEntry{heldEntry},
+	}, pki.intermediate, pki.intermediateKey)
+	leafReleased := pki.crlFromTemplate(t, &RevocationList{
+		Number: big.NewInt(2), ThisUpdate: past, NextUpdate: future,
+	}, pki.intermediate, pki.intermediateKey)
+
+	ocspGood := pki.ocspResponse(t, pki.leaf, pki.intermediate, pki.intermediateKey, OCSPGood, past, future)
+	ocspRevoked := pki.ocspResponse(t, pki.leaf, pki.intermediate, pki.intermediateKey, OCSPRevoked, past, future)
+	ocspRevokedStale := pki.ocspResponse(t, pki.leaf, pki.intermediate, pki.intermediateKey, OCSPRevoked, past.Add(-time.Hour), past)
+	ocspRevokedWrongSigner := pki.ocspResponse(t, pki.leaf, pki.intermediate, pki.rootKey, OCSPRevoked, past, future)
+	ocspIntermediateGood := pki.ocspResponse(t, pki.intermediate, pki.root, pki.rootKey, OCSPGood, past, future)
+	ocspDelegatedRevoked := pki.delegatedOCSPResponse(t, pki.leaf, pki.intermediate, pki.intermediateKey, OCSPRevoked, past, future, future)
+	ocspDelegatedExpired := pki.delegatedOCSPResponse(t, pki.leaf, pki.intermediate, pki.intermediateKey, OCSPRevoked, past, future, past)
+
+	responder := func(responses ...[]byte) func(cert, issuer *Certificate) ([]byte, error) {
+		return func(cert, issuer *Certificate) ([]byte, error) {
+			if len(responses) == 0 {
+				return nil, errors.New("unreachable")
+			}
+			der := responses[0]
+			responses = responses[1:]
+			return der, nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		opts       *RevocationOptions
+		wantReason InvalidReason
+		wantCert   *Certificate
+		wantErr    bool
+	}{
+		{name: "empty options", opts: &RevocationOptions{}},
+		{name: "empty options, hard fail", opts: &RevocationOptions{Policy: RevocationHardFail},
+			wantErr: true, wantReason: RevocationStatusUnknown, wantCert: pki.leaf},
+
+		{name: "CRL revoked", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafRevoked}},
+			wantErr: true, wantReason: Revoked, wantCert: pki.leaf},
+		{name: "CRL not revoked", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafNotRevoked}}},
+		{name: "CRL stale", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafRevokedStale}}},
+		{name: "CRL stale, hard fail", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafRevokedStale, intermediateNotRevoked}, Policy: RevocationHardFail},
+			wantErr: true, wantReason: RevocationStatusUnknown, wantCert: pki.leaf},
+		{name: "CRL forged", opts: &RevocationOptions{RevocationLists: []*RevocationList{forgedCRL}}},
+		{name: "CRL intermediate revoked", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafNotRevoked, intermediateRevoked}},
+			wantErr: true, wantReason: Revoked, wantCert: pki.intermediate},
+		{name: "CRL with issuing distribution point, hard fail", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafRevokedIDP, intermediateNotRevoked}, Policy: RevocationHardFail},
+			wantErr: true, wantReason: RevocationStatusUnknown, wantCert: pki.leaf},
+		{name: "CRL with unknown critical extension, hard fail", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafRevokedUnknownCritical, intermediateNotRevoked}, Policy: RevocationHardFail},
+			wantErr: true, wantReason: RevocationStatusUnknown, wantCert: pki.leaf},
+		{name: "CRL hold", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafHeld}},
+			wantErr: true, wantReason: Revoked, wantCert: pki.leaf},
+		{name: "CRL hold superseded", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafReleased, leafHeld}}},
+		{name: "CRL all covered, hard fail", opts: &RevocationOptions{RevocationLists: []*RevocationList{leafNotRevoked, intermediateNotRevoked}, Policy: RevocationHardFail}},
+
+		{name: "OCSP revoked", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspRevoked}},
+			wantErr: true, wantReason: Revoked, wantCert: pki.leaf},
+		{name: "OCSP good", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspGood}}},
+		{name: "CRL revoked overrides OCSP good", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspGood}, RevocationLists: []*RevocationList{leafRevoked}},
+			wantErr: true, wantReason: Revoked, wantCert: pki.leaf},
+		{name: "OCSP revoked overrides OCSP good", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspGood, ocspRevoked}},
+			wantErr: true, wantReason: Revoked, wantCert: pki.leaf},
+		{name: "OCSP stale", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspRevokedStale}, RevocationLists: []*RevocationList{leafNotRevoked}}},
+		{name: "OCSP wrong signer", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspRevokedWrongSigner}}},
+		{name: "OCSP delegated responder", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspDelegatedRevoked}},
+			wantErr: true, wantReason: Revoked, wantCert: pki.leaf},
+		{name: "OCSP expired delegated responder", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspDelegatedExpired}}},
+		{name: "OCSP expired delegated responder, hard fail", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspDelegatedExpired, ocspIntermediateGood}, Policy: RevocationHardFail},
+			wantErr: true, wantReason: RevocationStatusUnknown, wantCert: pki.leaf},
+		{name: "OCSP garbage", opts: &RevocationOptions{OCSPResponses: [][]byte{{1, 2, 3}}}},
+		{name: "OCSP all covered, hard fail", opts: &RevocationOptions{OCSPResponses: [][]byte{ocspGood, ocspIntermediateGood}, Policy: RevocationHardFail}},
+
+		{name: "responder revoked", opts: &RevocationOptions{OCSPResponder: responder(ocspRevoked)},
+			wantErr: true, wantReason: Revoked, wantCert: pki.leaf},
+		{name: "responder good, hard fail", opts: &RevocationOptions{OCSPResponder: responder(ocspGood, ocspIntermediateGood), Policy: RevocationHardFail}},
+		{name: "responder unreachable", opts: &RevocationOptions{OCSPResponder: responder()}},
+		{name: "responder unreachable, hard fail", opts: &RevocationOptions{OCSPResponder: responder(), Policy: RevocationHardFail},
+			wantErr: true, wantReason: RevocationStatusUnknown, wantCert: pki.leaf},
+		{name: "responder after CRL", opts: &RevocationOptions{OCSPResponder: responder(ocspIntermediateGood), RevocationLists: []*RevocationList{leafNotRevoked}, Policy: RevocationHardFail}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			chains, err := pki.leaf.Verify(pki.verifyOptions(tc.opts))
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("Verify failed: %s", err)
+				}
+				if len(chains) != 1 || len(chains[0]) != 3 {
+					t.Fatalf("unexpected chains: %v", chains)
+				}
+				return
+			}
+			var invalidErr CertificateInvalidError
+			if !errors.As(err, &invalidErr) {
+				t.Fatalf("Verify returned %v, want a CertificateInvalidError", err)
+			}
+			if invalidErr.Reason != tc.wantReason {
+				t.Errorf("unexpected reason: got %v, want %v", invalidErr.Reason, tc.wantReason)
+			}
+			if !invalidErr.Cert.Equal(tc.wantCert) {
+				t.Errorf("unexpected certificate: got %q, want %q", invalidErr.Cert.Subject.CommonName, tc.wantCert.Subject.CommonName)
+			}
+		})
+	}
+}
+
+func TestVerifyRevocationAlternateChain(t *testing.T) {
+	// The leaf's issuer is also cross-signed by a second root. Revoking the
+	// cross-signed intermediate must leave the chain through the first root.
+	pki := newTestPKI(t)
+	now := time.Now()
+	otherRoot, otherRootKey := newRevocationTestCert(t, "Other Root", true, nil, nil, nil)
+	tmpl := *pki.intermediate
+	tmpl.SerialNumber = big.NewInt(42)
+	crossDER, err := CreateCertificate(rand.Reader, &tmpl, otherRoot, pki.intermediate.PublicKey, otherRootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cross, err := ParseCertificate(crossDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl := pki.crl(t, otherRoot, otherRootKey, now.Add(-time.Hour), now.Add(time.Hour), cross)
+
+	opts := pki.verifyOptions(&RevocationOptions{RevocationLists: []*RevocationList{crl}})
+	opts.Roots.AddCert(otherRoot)
+	opts.Intermediates.AddCert(cross)
+	chains, err := pki.leaf.Verify(opts)
+	if err != nil {
+		t.Fatalf("Verify failed: %s", err)
+	}
+	if len(chains) != 1 || !chains[0][2].Equal(pki.root) {
+		t.Fatalf("unexpected chains: %v", chains)
+	}
+}
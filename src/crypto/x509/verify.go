@@ -61,6 +61,17 @@ const (
 	CANotAuthorizedForExtKeyUsage
 	// NoValidChains results when there are no valid chains to return.
 	NoValidChains
+	// Revoked results when a certificate in the chain has been revoked,
+	// according to the sources in VerifyOptions.Revocation.
+	Revoked
+	// RevocationStatusUnknown results when the revocation status of a
+	// certificate in the chain could not be determined and
+	// VerifyOptions.Revocation.Policy is RevocationHardFail.
+	RevocationStatusUnknown
+	// InsufficientSCTs results when the leaf certificate does not have
+	// enough valid Signed Certificate Timestamps to satisfy
+	// VerifyOptions.CertificateTransparency.
+	InsufficientSCTs
 )
 
 // CertificateInvalidError results when an odd error occurs. Users of this
@@ -97,6 +108,12 @@ func (e CertificateInvalidError) Error() string {
 			s = fmt.Sprintf("%s: %s", s, e.Detail)
 		}
 		return s
+	case Revoked:
+		return "x509: certificate has been revoked"
+	case RevocationStatusUnknown:
+		return "x509: certificate revocation status could not be determined"
+	case InsufficientSCTs:
+		return "x509: certificate does not satisfy the Certificate Transparency policy: " + e.Detail
 	}
 	return "x509: unknown error"
 }
@@ -218,6 +235,16 @@ type VerifyOptions struct {
 	// field implies any valid policy is acceptable.
 	CertificatePolicies []OID
 
+	// CertificateTransparency, if not nil, requires the leaf certificate to
+	// have enough valid Signed Certificate Timestamps, as configured by its
+	// fields. Chains that don't satisfy it are discarded.
+	CertificateTransparency *CTOptions
+
+	// Revocation, if not nil, enables revocation checking of the chains
+	// built by Verify, including those returned by the platform verifier.
+	// Chains containing a revoked certificate are discarded.
+	Revocation *RevocationOptions
+
 	// The following policy fields are unexported, because we do not expect
 	// users to actually need to use them, but are useful for testing the
 	// policy validation code.
@@ -776,13 +803,29 @@ func (c *Certificate) isValid(certType int, currentChain []*Certificate, opts *V
 //
 // Certificates other than c in the returned chains should not be modified.
 //
-// WARNING: this function doesn't do any revocation checking.
+// Revocation checking is only performed if opts.Revocation is set, and is
+// limited to the CRLs and OCSP responses it provides. If every candidate chain
+// contains a revoked certificate, the returned error is a
+// [CertificateInvalidError] with reason [Revoked] or [RevocationStatusUnknown].
+// Similarly, Certificate Transparency is only enforced if
+// opts.CertificateTransparency is set, and failures are reported with reason
+// [InsufficientSCTs].
 func (c *Certificate) Verify(opts VerifyOptions) (chains [][]*Certificate, err error) {
 	// Platform-specific verification needs the ASN.1 contents so
 	// this makes the behavior consistent across platforms.
 	if len(c.Raw) == 0 {
 		return nil, errNotParsed
 	}
+	if opts.CertificateTransparency != nil || opts.Revocation != nil {
+		// Certificate Transparency and revocation are checked last, on
+		// whatever chains survived the other checks, regardless of which
+		// verifier built them.
+		defer func() {
+			if err == nil {
+				chains, err = filterChainsPostVerify(chains, &opts)
+			}
+		}()
+	}
 	for i := 0; i < opts.Intermediates.len(); i++ {
 		c, _, err := opts.Intermediates.cert(i)
 		if err != nil {
@@ -898,6 +941,49 @@ func (c *Certificate) Verify(opts VerifyOptions) (chains [][]*Certificate, err e
 	return chains, nil
 }
 
+// filterChainsPostVerify applies the Certificate Transparency and revocation
+// policies of opts to chains, which must not be empty.
+func filterChainsPostVerify(chains [][]*Certificate, opts *VerifyOptions) ([][]*Certificate, error) {
+	now := opts.CurrentTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var err error
+	if opts.CertificateTransparency != nil {
+		chains, err = filterChains(chains, newCTChecker(opts.CertificateTransparency, now).checkChain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.Revocation != nil {
+		chains, err = filterChains(chains, newRevocationChecker(opts.Revocation, now).checkChain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chains, nil
+}
+
+// filterChains returns the chains for which check returns nil. If no chain is
+// left, it returns the error of the first rejected chain.
+func filterChains(chains [][]*Certificate, check func([]*Certificate) error) ([][]*Certificate, error) {
+	var firstErr error
+	filtered := chains[:0]
+	for _, chain := range chains {
+		if err := check(chain); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		filtered = append(filtered, chain)
+	}
+	if len(filtered) == 0 {
+		return nil, firstErr
+	}
+	return filtered, nil
+}
+
 func appendToFreshChain(chain []*Certificate, cert *Certificate) []*Certificate {
 	n := make([]*Certificate, len(chain)+1)
 	copy(n, chain)
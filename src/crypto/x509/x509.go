@@ -27,6 +27,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/mldsa"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/sha256"
@@ -65,8 +66,8 @@ type pkixPublicKey struct {
 // public key is a SubjectPublicKeyInfo structure (see RFC 5280, Section 4.1).
 //
 // It returns a *[rsa.PublicKey], *[dsa.PublicKey], *[ecdsa.PublicKey],
-// [ed25519.PublicKey] (not a pointer), or *[ecdh.PublicKey] (for X25519).
-// More types might be supported in the future.
+// [ed25519.PublicKey] (not a pointer), *[ecdh.PublicKey] (for X25519), or
+// *[mldsa.PublicKey]. More types might be supported in the future.
 //
 // This kind of key is commonly encoded in PEM blocks of type "PUBLIC KEY".
 func ParsePKIXPublicKey(derBytes []byte) (pub any, err error) {
@@ -115,6 +116,13 @@ func marshalPublicKey(pub any) (publicKeyBytes []byte, publicKeyAlgorithm pkix.A
 	case ed25519.PublicKey:
 		publicKeyBytes = pub
 		publicKeyAlgorithm.Algorithm = oidPublicKeyEd25519
+	case *mldsa.PublicKey:
+		oid, ok := oidFromMLDSAParameters(pub.Parameters())
+		if !ok {
+			return nil, pkix.AlgorithmIdentifier{}, errors.New("x509: unsupported ML-DSA parameters")
+		}
+		publicKeyBytes = pub.Bytes()
+		publicKeyAlgorithm.Algorithm = oid
 	case *ecdh.PublicKey:
 		publicKeyBytes = pub.Bytes()
 		if pub.Curve() == ecdh.X25519() {
@@ -144,8 +152,8 @@ func marshalPublicKey(pub any) (publicKeyBytes []byte, publicKeyAlgorithm pkix.A
 // (see RFC 5280, Section 4.1).
 //
 // The following key types are currently supported: *[rsa.PublicKey],
-// *[ecdsa.PublicKey], [ed25519.PublicKey] (not a pointer), and *[ecdh.PublicKey].
-// Unsupported key types result in an error.
+// *[ecdsa.PublicKey], [ed25519.PublicKey] (not a pointer), *[ecdh.PublicKey],
+// and *[mldsa.PublicKey]. Unsupported key types result in an error.
 //
 // This kind of key is commonly encoded in PEM blocks of type "PUBLIC KEY".
 func MarshalPKIXPublicKey(pub any) ([]byte, error) {
@@ -231,6 +239,9 @@ const (
 	SHA384WithRSAPSS
 	SHA512WithRSAPSS
 	PureEd25519
+	MLDSA44
+	MLDSA65
+	MLDSA87
 )
 
 func (algo SignatureAlgorithm) isRSAPSS() bool {
@@ -268,6 +279,7 @@ const (
 	DSA // Only supported for parsing.
 	ECDSA
 	Ed25519
+	MLDSA
 )
 
 var publicKeyAlgoName = [...]string{
@@ -275,6 +287,7 @@ var publicKeyAlgoName = [...]string{
 	DSA:     "DSA",
 	ECDSA:   "ECDSA",
 	Ed25519: "Ed25519",
+	MLDSA:   "ML-DSA",
 }
 
 func (algo PublicKeyAlgorithm) String() string {
@@ -332,6 +345,14 @@ func (algo PublicKeyAlgorithm) String() string {
 // RFC 8410 3 Curve25519 and Curve448 Algorithm Identifiers
 //
 //	id-Ed25519   OBJECT IDENTIFIER ::= { 1 3 101 112 }
+//
+// RFC 9881 2 Algorithm Identifiers
+//
+//	sigAlgs OBJECT IDENTIFIER ::= { joint-iso-itu-t(2) country(16) us(840)
+//		organization(1) gov(101) csor(3) nistAlgorithm(4) 3 }
+//	id-ml-dsa-44 OBJECT IDENTIFIER ::= { sigAlgs 17 }
+//	id-ml-dsa-65 OBJECT IDENTIFIER ::= { sigAlgs 18 }
+//	id-ml-dsa-87 OBJECT IDENTIFIER ::= { sigAlgs 19 }
 var (
 	oidSignatureMD5WithRSA      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 4}
 	oidSignatureSHA1WithRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 5}
@@ -346,6 +367,9 @@ var (
 	oidSignatureECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
 	oidSignatureECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
 	oidSignatureEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
+	oidSignatureMLDSA44         = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 17}
+	oidSignatureMLDSA65         = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}
+	oidSignatureMLDSA87         = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 19}
 
 	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
 	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
@@ -384,6 +408,9 @@ var signatureAlgorithmDetails = []struct {
 	{ECDSAWithSHA384, "ECDSA-SHA384", oidSignatureECDSAWithSHA384, emptyRawValue, ECDSA, crypto.SHA384, false},
 	{ECDSAWithSHA512, "ECDSA-SHA512", oidSignatureECDSAWithSHA512, emptyRawValue, ECDSA, crypto.SHA512, false},
 	{PureEd25519, "Ed25519", oidSignatureEd25519, emptyRawValue, Ed25519, crypto.Hash(0) /* no pre-hashing */, false},
+	{MLDSA44, "ML-DSA-44", oidSignatureMLDSA44, emptyRawValue, MLDSA, crypto.Hash(0) /* no pre-hashing */, false},
+	{MLDSA65, "ML-DSA-65", oidSignatureMLDSA65, emptyRawValue, MLDSA, crypto.Hash(0) /* no pre-hashing */, false},
+	{MLDSA87, "ML-DSA-87", oidSignatureMLDSA87, emptyRawValue, MLDSA, crypto.Hash(0) /* no pre-hashing */, false},
 }
 
 var emptyRawValue = asn1.RawValue{}
@@ -421,6 +448,14 @@ func getSignatureAlgorithmFromAI(ai pkix.AlgorithmIdentifier) SignatureAlgorithm
 			return UnknownSignatureAlgorithm
 		}
 	}
+	if _, ok := mldsaParametersFromOID(ai.Algorithm); ok {
+		// RFC 9881, Section 2
+		// > The contents of the parameters component for each algorithm
+		// > MUST be absent.
+		if len(ai.Parameters.FullBytes) != 0 {
+			return UnknownSignatureAlgorithm
+		}
+	}
 
 	if !ai.Algorithm.Equal(oidSignatureRSAPSS) {
 		for _, details := range signatureAlgorithmDetails {
@@ -492,6 +527,12 @@ var (
 	//	id-Ed25519   OBJECT IDENTIFIER ::= { 1 3 101 112 }
 	oidPublicKeyX25519  = asn1.ObjectIdentifier{1, 3, 101, 110}
 	oidPublicKeyEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+	// RFC 9881, Section 2
+	//
+	// The same OIDs identify ML-DSA public keys and signatures.
+	oidPublicKeyMLDSA44 = oidSignatureMLDSA44
+	oidPublicKeyMLDSA65 = oidSignatureMLDSA65
+	oidPublicKeyMLDSA87 = oidSignatureMLDSA87
 )
 
 // getPublicKeyAlgorithmFromOID returns the exposed PublicKeyAlgorithm
@@ -507,10 +548,52 @@ func getPublicKeyAlgorithmFromOID(oid asn1.ObjectIdentifier) PublicKeyAlgorithm
 		return ECDSA
 	case oid.Equal(oidPublicKeyEd25519):
 		return Ed25519
+	case oid.Equal(oidPublicKeyMLDSA44), oid.Equal(oidPublicKeyMLDSA65), oid.Equal(oidPublicKeyMLDSA87):
+		return MLDSA
 	}
 	return UnknownPublicKeyAlgorithm
 }
 
+// mldsaParametersFromOID returns the ML-DSA parameter set identified by the
+// given public key or signature algorithm OID.
+func mldsaParametersFromOID(oid asn1.ObjectIdentifier) (mldsa.Parameters, bool) {
+	switch {
+	case oid.Equal(oidPublicKeyMLDSA44):
+		return mldsa.MLDSA44(), true
+	case oid.Equal(oidPublicKeyMLDSA65):
+		return mldsa.MLDSA65(), true
+	case oid.Equal(oidPublicKeyMLDSA87):
+		return mldsa.MLDSA87(), true
+	}
+	return mldsa.Parameters{}, false
+}
+
+func oidFromMLDSAParameters(params mldsa.Parameters) (asn1.ObjectIdentifier, bool) {
+	switch params {
+	case mldsa.MLDSA44():
+		return oidPublicKeyMLDSA44, true
+	case mldsa.MLDSA65():
+		return oidPublicKeyMLDSA65, true
+	case mldsa.MLDSA87():
+		return oidPublicKeyMLDSA87, true
+	}
+	return nil, false
+}
+
+// mldsaSignatureAlgorithm returns the only SignatureAlgorithm that can be
+// used with an ML-DSA key of the given parameter set.
+func mldsaSignatureAlgorithm(params mldsa.Parameters) SignatureAlgorithm {
+	switch params {
+	case mldsa.MLDSA44():
+		return MLDSA44
+	case mldsa.MLDSA65():
+		return MLDSA65
+	case mldsa.MLDSA87():
+		return MLDSA87
+	}
+	return UnknownSignatureAlgorithm
+}
+
 // RFC 5480, 2.1.1.1. Named Curve
 //
 //	secp224r1 OBJECT IDENTIFIER ::= {
@@ -974,7 +1057,7 @@ func checkSignature(algo SignatureAlgorithm, signed, signature []byte, publicKey
 
 	switch hashType {
 	case crypto.Hash(0):
-		if pubKeyAlgo != Ed25519 {
+		if pubKeyAlgo != Ed25519 && pubKeyAlgo != MLDSA {
 			return ErrUnsupportedAlgorithm
 		}
 	case crypto.MD5:
@@ -1020,6 +1103,17 @@ func checkSignature(algo SignatureAlgorithm, signed, signature []byte, publicKey
 			return errors.New("x509: Ed25519 verification failure")
 		}
 		return
+	case *mldsa.PublicKey:
+		if pubKeyAlgo != MLDSA {
+			return signaturePublicKeyAlgoMismatchError(pubKeyAlgo, pub)
+		}
+		if mldsaSignatureAlgorithm(pub.Parameters()) != algo {
+			return errors.New("x509: ML-DSA signature algorithm does not match public key parameters")
+		}
+		if err := mldsa.Verify(pub, signed, signature, nil); err != nil {
+			return errors.New("x509: ML-DSA verification failure")
+		}
+		return
 	}
 	return ErrUnsupportedAlgorithm
 }
@@ -1114,6 +1208,7 @@ var (
 	oidExtensionAuthorityInfoAccess   = []int{1, 3, 6, 1, 5, 5, 7, 1, 1}
 	oidExtensionCRLNumber             = []int{2, 5, 29, 20}
 	oidExtensionReasonCode            = []int{2, 5, 29, 21}
+	oidExtensionDeltaCRLIndicator     = []int{2, 5, 29, 27}
 )
 
 var (
@@ -1541,8 +1636,18 @@ func signingParamsForKey(key crypto.Signer, sigAlgo SignatureAlgorithm) (Signatu
 		pubType = Ed25519
 		defaultAlgo = PureEd25519
 
+	case *mldsa.PublicKey:
+		pubType = MLDSA
+		defaultAlgo = mldsaSignatureAlgorithm(pub.Parameters())
+		if defaultAlgo == UnknownSignatureAlgorithm {
+			return 0, ai, errors.New("x509: unsupported ML-DSA parameters")
+		}
+		if sigAlgo != 0 && sigAlgo != defaultAlgo {
+			return 0, ai, errors.New("x509: requested SignatureAlgorithm does not match ML-DSA key parameters")
+		}
+
 	default:
-		return 0, ai, errors.New("x509: only RSA, ECDSA and Ed25519 keys supported")
+		return 0, ai, errors.New("x509: only RSA, ECDSA, Ed25519, and ML-DSA keys supported")
 	}
 
 	if sigAlgo == 0 {
@@ -1639,8 +1744,8 @@ var emptyASN1Subject = []byte{0x30, 0}
 //
 // The returned slice is the certificate in DER encoding.
 //
-// The currently supported key types are *rsa.PublicKey, *ecdsa.PublicKey and
-// ed25519.PublicKey. pub must be a supported key type, and priv must be a
+// The currently supported key types are *rsa.PublicKey, *ecdsa.PublicKey,
+// ed25519.PublicKey and *mldsa.PublicKey. pub must be a supported key type, and priv must be a
 // crypto.Signer or crypto.MessageSigner with a supported public key.
 //
 // The AuthorityKeyId will be taken from the SubjectKeyId of parent, if any,
@@ -2043,9 +2148,10 @@ func parseCSRExtensions(rawAttributes []asn1.RawValue) ([]pkix.Extension, error)
 //
 // priv is the private key to sign the CSR with, and the corresponding public
 // key will be included in the CSR. It must implement crypto.Signer or
-// crypto.MessageSigner and its Public() method must return a *rsa.PublicKey or
-// a *ecdsa.PublicKey or a ed25519.PublicKey. (A *rsa.PrivateKey,
-// *ecdsa.PrivateKey or ed25519.PrivateKey satisfies this.)
+// crypto.MessageSigner and its Public() method must return a *rsa.PublicKey,
+// a *ecdsa.PublicKey, a ed25519.PublicKey or a *mldsa.PublicKey. (A
+// *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey or *mldsa.PrivateKey
+// satisfies this.)
 //
 // The returned slice is the certificate request in DER encoding.
 func CreateCertificateRequest(rand io.Reader, template *CertificateRequest, priv any) (csr []byte, err error) {
@@ -2344,6 +2450,13 @@ type RevocationList struct {
 	// extension when parsing a CRL.
 	Number *big.Int
 
+	// BaseCRLNumber, if not nil, marks the CRL as a delta CRL and is used to
+	// populate the critical deltaCRLIndicator extension, which holds the
+	// cRLNumber of the complete CRL the delta CRL updates. It must be less
+	// than Number. It is also populated from the deltaCRLIndicator extension
+	// when parsing a CRL. See RFC 5280, Section 5.2.4.
+	BaseCRLNumber *big.Int
+
 	// ThisUpdate is used to populate the thisUpdate field in the CRL, which
 	// indicates the issuance date of the CRL.
 	ThisUpdate time.Time
@@ -2492,6 +2605,16 @@ func CreateRevocationList(rand io.Reader, template *RevocationList, issuer *Cert
 	if err != nil {
 		return nil, err
 	}
+	var baseCRLNum []byte
+	if template.BaseCRLNumber != nil {
+		if template.BaseCRLNumber.Cmp(template.Number) >= 0 {
+			return nil, errors.New("x509: template.BaseCRLNumber is not less than template.Number")
+		}
+		baseCRLNum, err = asn1.Marshal(template.BaseCRLNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Correctly use the issuer's subject sequence if one is specified.
 	issuerSubject, err := subjectBytes(issuer)
@@ -2516,6 +2639,13 @@ func CreateRevocationList(rand io.Reader, template *RevocationList, issuer *Cert
 			},
 		},
 	}
+	if baseCRLNum != nil {
+		tbsCertList.Extensions = append(tbsCertList.Extensions, pkix.Extension{
+			Id:       oidExtensionDeltaCRLIndicator,
+			Critical: true,
+			Value:    baseCRLNum,
+		})
+	}
 	if len(revokedCerts) > 0 {
 		tbsCertList.RevokedCertificates = revokedCerts
 	}
@@ -12,6 +12,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
+	"crypto/mldsa"
 	"crypto/rand"
 	"crypto/rsa"
 	_ "crypto/sha256"
@@ -693,6 +694,11 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 		t.Fatalf("Failed to generate Ed25519 key: %s", err)
 	}
 
+	mldsaPriv, err := mldsa.GenerateKey(mldsa.MLDSA65())
+	if err != nil {
+		t.Fatalf("Failed to generate ML-DSA key: %s", err)
+	}
+
 	tests := []struct {
 		name      string
 		pub, priv any
@@ -707,6 +713,8 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 		{"ECDSA/RSAPSS", &ecdsaPriv.PublicKey, testPrivateKey, false, SHA256WithRSAPSS},
 		{"RSAPSS/ECDSA", &testPrivateKey.PublicKey, ecdsaPriv, false, ECDSAWithSHA384},
 		{"Ed25519", ed25519Pub, ed25519Priv, true, PureEd25519},
+		{"MLDSA", mldsaPriv.PublicKey(), mldsaPriv, true, MLDSA65},
+		{"MLDSA/ECDSA", mldsaPriv.PublicKey(), ecdsaPriv, false, ECDSAWithSHA256},
 	}
 
 	testExtKeyUsage := []ExtKeyUsage{ExtKeyUsageClientAuth, ExtKeyUsageServerAuth}
@@ -1495,6 +1503,11 @@ func TestCreateCertificateRequest(t *testing.T) {
 		t.Fatalf("Failed to generate Ed25519 key: %s", err)
 	}
 
+	mldsaPriv, err := mldsa.GenerateKey(mldsa.MLDSA44())
+	if err != nil {
+		t.Fatalf("Failed to generate ML-DSA key: %s", err)
+	}
+
 	tests := []struct {
 		name    string
 		priv    any
@@ -1506,6 +1519,7 @@ func TestCreateCertificateRequest(t *testing.T) {
 		{"ECDSA-384", ecdsa384Priv, ECDSAWithSHA256},
 		{"ECDSA-521", ecdsa521Priv, ECDSAWithSHA256},
 		{"Ed25519", ed25519Priv, PureEd25519},
+		{"ML-DSA-44", mldsaPriv, MLDSA44},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,300 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// A Batch is a sequence of statements executed together by
+// [DB.ExecBatch], [Conn.ExecBatch] or [Tx.ExecBatch].
+// The zero value is an empty batch.
+type Batch struct {
+	queries []batchQuery
+}
+
+type batchQuery struct {
+	query string
+	args  []any
+}
+
+// Queue appends a statement that doesn't return rows to the batch.
+// The args are for any placeholder parameters in the query.
+func (b *Batch) Queue(query string, args ...any) {
+	b.queries = append(b.queries, batchQuery{query, args})
+}
+
+// Len returns the number of statements in the batch.
+func (b *Batch) Len() int {
+	return len(b.queries)
+}
+
+// ExecBatch executes the statements of b in order on a single connection,
+// and returns their results.
+//
+// If the driver implements [driver.Batcher], the statements are sent to
+// the database together, saving a round trip per statement. Otherwise,
+// they are executed one at a time.
+//
+// ExecBatch stops at the first statement that fails, and returns the
+// results of the statements that preceded it along with the error.
+// The statements are not executed in a transaction: use [Tx.ExecBatch] to
+// undo all of them if one fails.
+func (db *DB) ExecBatch(ctx context.Context, b *Batch) ([]Result, error) {
+	if b.Len() == 0 {
+		return nil, nil
+	}
+	var results []Result
+	var err error
+
+	ctx, op := db.startOp(ctx, OpBatch, "", b.Len())
+	db.retry(op, func(strategy connReuseStrategy) error {
+		results, err = db.execBatch(ctx, b, strategy)
+		if len(results) > 0 {
+			// Do not execute the statements of the batch twice.
+			return nil
+		}
+		return err
+	})
+	op.endBatch(results, err)
+
+	return results, err
+}
+
+func (db *DB) execBatch(ctx context.Context, b *Batch, strategy connReuseStrategy) ([]Result, error) {
+	dc, err := db.conn(ctx, strategy)
+	if err != nil {
+		return nil, err
+	}
+	return db.execBatchDC(ctx, dc, dc.releaseConn, b)
+}
+
+// ExecBatch executes the statements of b in order on the connection.
+// See [DB.ExecBatch] for details.
+func (c *Conn) ExecBatch(ctx context.Context, b *Batch) (results []Result, err error) {
+	if b.Len() == 0 {
+		return nil, nil
+	}
+	ctx, op := c.db.startOp(ctx, OpBatch, "", b.Len())
+	defer func() { op.endBatch(results, err) }()
+	dc, release, err := c.grabConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.db.execBatchDC(ctx, dc, release, b)
+}
+
+// ExecBatch executes the statements of b in order within the transaction.
+// See [DB.ExecBatch] for details.
+func (tx *Tx) ExecBatch(ctx context.Context, b *Batch) (results []Result, err error) {
+	if b.Len() == 0 {
+		return nil, nil
+	}
+	ctx, op := tx.db.startOp(ctx, OpBatch, "", b.Len())
+	defer func() { op.endBatch(results, err) }()
+	dc, release, err := tx.grabConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx.db.execBatchDC(ctx, dc, release, b)
+}
+
+func (db *DB) execBatchDC(ctx context.Context, dc *driverConn, release func(error), b *Batch) (results []Result, err error) {
+	defer func() {
+		release(err)
+	}()
+	if batcher, ok := dc.ci.(driver.Batcher); ok {
+		var resi []driver.Result
+		withLock(dc, func() {
+			batch := make([]driver.BatchQuery, len(b.queries))
+			for i, q := range b.queries {
+				batch[i].Query = q.query
+				batch[i].Args, err = driverArgsConnLocked(dc.ci, nil, q.args)
+				if err != nil {
+					return
+				}
+			}
+			resi, err = batcher.ExecBatch(ctx, batch)
+		})
+		if err != driver.ErrSkip {
+			for _, r := range resi {
+				results = append(results, driverResult{dc, r})
+			}
+			return results, err
+		}
+	}
+
+	noRelease := func(error) {}
+	for _, q := range b.queries {
+		var res Result
+		res, err = db.execDC(ctx, dc, noRelease, q.query, q.args)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Copy describes the destination of the rows copied by [DB.CopyFrom].
+type Copy struct {
+	// Table and Columns are the table and the columns into which rows
+	// are copied. Each row has one value per column.
+	Table   string
+	Columns []string
+
+	// Insert is a statement that inserts a single row, with one
+	// placeholder parameter per column. It is executed for each row if
+	// the driver does not implement [driver.Copier]. If Insert is empty,
+	// copying rows with such a driver fails.
+	Insert string
+}
+
+// CopyFrom inserts the rows yielded by rows into the table described by
+// dst, on a single connection, and returns the number of rows inserted.
+// Each row holds a value for each of the columns of dst, which is
+// converted like a query argument.
+//
+// If the driver implements [driver.Copier], the rows are sent to the
+// database in bulk. Otherwise, the dst.Insert statement is prepared and
+// executed for each row.
+//
+// If an error occurs, CopyFrom stops consuming rows. Depending on the
+// driver, the rows that preceded the error may have been inserted: use
+// [Tx.CopyFrom] to undo the copy if it fails.
+//
+// rows is consumed while the connection is in use by the copy. It must not
+// use the [Conn] or [Tx] whose CopyFrom method was called, which would
+// deadlock, but it may, for example, read the rows of a query made on
+// another connection.
+func (db *DB) CopyFrom(ctx context.Context, dst Copy, rows iter.Seq[[]any]) (int64, error) {
+	var n int64
+	var err error
+
+	ctx, op := db.startOp(ctx, OpCopy, dst.Table, 0)
+	db.retry(op, func(strategy connReuseStrategy) error {
+		var started bool
+		n, err = db.copyFrom(ctx, dst, rows, &started, strategy)
+		if started {
+			// rows may not be iterated again.
+			return nil
+		}
+		return err
+	})
+	op.endCopy(n, err)
+
+	return n, err
+}
+
+func (db *DB) copyFrom(ctx context.Context, dst Copy, rows iter.Seq[[]any], started *bool, strategy connReuseStrategy) (int64, error) {
+	dc, err := db.conn(ctx, strategy)
+	if err != nil {
+		return 0, err
+	}
+	return db.copyDC(ctx, dc, dc.releaseConn, dst, rows, started)
+}
+
+// CopyFrom inserts rows into a table on the connection.
+// See [DB.CopyFrom] for details.
+func (c *Conn) CopyFrom(ctx context.Context, dst Copy, rows iter.Seq[[]any]) (n int64, err error) {
+	ctx, op := c.db.startOp(ctx, OpCopy, dst.Table, 0)
+	defer func() { op.endCopy(n, err) }()
+	dc, release, err := c.grabConn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return c.db.copyDC(ctx, dc, release, dst, rows, new(bool))
+}
+
+// CopyFrom inserts rows into a table within the transaction.
+// See [DB.CopyFrom] for details.
+func (tx *Tx) CopyFrom(ctx context.Context, dst Copy, rows iter.Seq[[]any]) (n int64, err error) {
+	ctx, op := tx.db.startOp(ctx, OpCopy, dst.Table, 0)
+	defer func() { op.endCopy(n, err) }()
+	dc, release, err := tx.grabConn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return tx.db.copyDC(ctx, dc, release, dst, rows, new(bool))
+}
+
+// copyDC copies rows on dc. It sets *started once a row has been
+// consumed from rows.
+func (db *DB) copyDC(ctx context.Context, dc *driverConn, release func(error), dst Copy, rows iter.Seq[[]any], started *bool) (n int64, err error) {
+	defer func() {
+		release(err)
+	}()
+	if copier, ok := dc.ci.(driver.Copier); ok {
+		// The driver pulls rows while the copy is in progress, so rows
+		// runs with the lock held, which is documented on DB.CopyFrom.
+		withLock(dc, func() {
+			n, err = copier.CopyFrom(ctx, dst.Table, dst.Columns, driverRows(dc.ci, dst, rows, started))
+		})
+		if err != driver.ErrSkip {
+			return n, err
+		}
+		if *started {
+			return 0, errors.New("sql: driver returned ErrSkip from CopyFrom after consuming rows")
+		}
+		n = 0
+	}
+
+	if dst.Insert == "" {
+		return 0, errors.New("sql: Copy.Insert is required when the driver does not implement driver.Copier")
+	}
+	var si driver.Stmt
+	withLock(dc, func() {
+		si, err = ctxDriverPrepare(ctx, dc.ci, dst.Insert)
+	})
+	if err != nil {
+		return 0, err
+	}
+	ds := &driverStmt{Locker: dc, si: si}
+	defer ds.Close()
+	for row := range rows {
+		*started = true
+		if err = checkCopyRow(dst, row); err != nil {
+			return n, err
+		}
+		if _, err = resultFromStatement(ctx, dc.ci, ds, row...); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// driverRows returns an iterator over rows converted to driver arguments
+// for ci, whose lock must be held while it is used.
+func driverRows(ci driver.Conn, dst Copy, rows iter.Seq[[]any], started *bool) iter.Seq2[[]driver.NamedValue, error] {
+	return func(yield func([]driver.NamedValue, error) bool) {
+		for row := range rows {
+			*started = true
+			err := checkCopyRow(dst, row)
+			var nvargs []driver.NamedValue
+			if err == nil {
+				nvargs, err = driverArgsConnLocked(ci, nil, row)
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(nvargs, nil) {
+				return
+			}
+		}
+	}
+}
+
+func checkCopyRow(dst Copy, row []any) error {
+	if len(row) != len(dst.Columns) {
+		return fmt.Errorf("sql: CopyFrom row has %d values, want %d", len(row), len(dst.Columns))
+	}
+	return nil
+}
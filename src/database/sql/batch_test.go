@@ -0,0 +1,253 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func peopleNames(t *testing.T, q interface {
+	QueryContext(context.Context, string, ...any) (*Rows, error)
+}) []string {
+	t.Helper()
+	rows, err := q.QueryContext(context.Background(), "SELECT|people|name|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name, err := range ScanAll[string](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// bulkConn returns a Conn of db on which the driver's ExecBatch and
+// CopyFrom are used if bulk is true, and skipped otherwise.
+func bulkConn(t *testing.T, db *DB, bulk bool) *Conn {
+	t.Helper()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Raw(func(raw any) error {
+		fc := raw.(*fakeConn)
+		fc.noBulk = !bulk
+		fc.skipDirtySession = true
+		return nil
+	})
+	return conn
+}
+
+func numBulk(conn *Conn) (n int) {
+	conn.Raw(func(raw any) error {
+		n = raw.(*fakeConn).numBulk
+		return nil
+	})
+	return n
+}
+
+func TestExecBatch(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	ctx := context.Background()
+
+	var b Batch
+	b.Queue("INSERT|people|name=Dave,age=4")
+	b.Queue("INSERT|people|name=?,age=?", "Eve", 5)
+	b.Queue("INSERT|people|name=?name,age=?age", Named("name", "Frank"), Named("age", 6))
+	results, err := db.ExecBatch(ctx, &b)
+	if err != nil {
+		t.Fatalf("ExecBatch: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for i, res := range results {
+		if n, err := res.RowsAffected(); n != 1 || err != nil {
+			t.Errorf("result %d: RowsAffected = %d, %v; want 1", i, n, err)
+		}
+	}
+	want := []string{"Alice", "Bob", "Chris", "Dave", "Eve", "Frank"}
+	if got := peopleNames(t, db); !slices.Equal(got, want) {
+		t.Errorf("got people %q, want %q", got, want)
+	}
+	if n := db.freeConn[0].ci.(*fakeConn).numBulk; n != 1 {
+		t.Errorf("driver ExecBatch called %d times, want 1", n)
+	}
+
+	if results, err := db.ExecBatch(ctx, new(Batch)); results != nil || err != nil {
+		t.Errorf("ExecBatch of an empty batch = %v, %v", results, err)
+	}
+}
+
+func TestExecBatchError(t *testing.T) {
+	for _, bulk := range []bool{true, false} {
+		db := newTestDB(t, "people")
+		conn := bulkConn(t, db, bulk)
+
+		var b Batch
+		b.Queue("INSERT|people|name=Dave,age=4")
+		b.Queue("INSERT|nosuchtable|name=Eve")
+		b.Queue("INSERT|people|name=Frank,age=6")
+		results, err := conn.ExecBatch(context.Background(), &b)
+		if err == nil || !strings.Contains(err.Error(), "nosuchtable") {
+			t.Errorf("bulk=%v: got error %v, want an error about nosuchtable", bulk, err)
+		}
+		if len(results) != 1 {
+			t.Errorf("bulk=%v: got %d results, want 1", bulk, len(results))
+		}
+		want := []string{"Alice", "Bob", "Chris", "Dave"}
+		if got := peopleNames(t, conn); !slices.Equal(got, want) {
+			t.Errorf("bulk=%v: got people %q, want %q", bulk, got, want)
+		}
+		if got, want := numBulk(conn), map[bool]int{true: 1, false: 0}[bulk]; got != want {
+			t.Errorf("bulk=%v: driver ExecBatch called %d times, want %d", bulk, got, want)
+		}
+		conn.Close()
+		closeDB(t, db)
+	}
+}
+
+func TestTxExecBatch(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b Batch
+	b.Queue("INSERT|people|name=?,age=?", "Dave", 4)
+	b.Queue("INSERT|people|name=?,age=?", "Eve", 5)
+	if _, err := tx.ExecBatch(ctx, &b); err != nil {
+		t.Fatalf("ExecBatch: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecBatch(ctx, &b); err != ErrTxDone {
+		t.Errorf("ExecBatch after Commit: got %v, want ErrTxDone", err)
+	}
+	want := []string{"Alice", "Bob", "Chris", "Dave", "Eve"}
+	if got := peopleNames(t, db); !slices.Equal(got, want) {
+		t.Errorf("got people %q, want %q", got, want)
+	}
+}
+
+func TestExecBatchRetry(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	db.mu.Lock()
+	for _, dc := range db.freeConn {
+		dc.ci.(*fakeConn).stickyBad = true
+	}
+	db.mu.Unlock()
+
+	var b Batch
+	b.Queue("INSERT|people|name=Dave,age=4")
+	if _, err := db.ExecBatch(context.Background(), &b); err != nil {
+		t.Fatalf("ExecBatch: %v", err)
+	}
+	want := []string{"Alice", "Bob", "Chris", "Dave"}
+	if got := peopleNames(t, db); !slices.Equal(got, want) {
+		t.Errorf("got people %q, want %q", got, want)
+	}
+}
+
+var copyPeople = Copy{
+	Table:   "people",
+	Columns: []string{"name", "age"},
+	Insert:  "INSERT|people|name=?,age=?",
+}
+
+func TestCopyFrom(t *testing.T) {
+	for _, bulk := range []bool{true, false} {
+		db := newTestDB(t, "people")
+		conn := bulkConn(t, db, bulk)
+
+		rows := [][]any{{"Dave", 4}, {"Eve", int64(5)}}
+		n, err := conn.CopyFrom(context.Background(), copyPeople, slices.Values(rows))
+		if n != 2 || err != nil {
+			t.Errorf("bulk=%v: CopyFrom = %d, %v; want 2 rows", bulk, n, err)
+		}
+		var age int
+		if err := conn.QueryRowContext(context.Background(), "SELECT|people|age|name=?", "Eve").Scan(&age); err != nil || age != 5 {
+			t.Errorf("bulk=%v: got age %d, %v; want 5", bulk, age, err)
+		}
+		if got, want := numBulk(conn), map[bool]int{true: 1, false: 0}[bulk]; got != want {
+			t.Errorf("bulk=%v: driver CopyFrom called %d times, want %d", bulk, got, want)
+		}
+
+		// Copying stops at an invalid row.
+		rows = [][]any{{"Frank", 6}, {"Gina"}, {"Hank", 8}}
+		n, err = conn.CopyFrom(context.Background(), copyPeople, slices.Values(rows))
+		if n != 1 || err == nil || !strings.Contains(err.Error(), "has 1 values, want 2") {
+			t.Errorf("bulk=%v: CopyFrom = %d, %v; want 1 row and an error", bulk, n, err)
+		}
+		want := []string{"Alice", "Bob", "Chris", "Dave", "Eve", "Frank"}
+		if got := peopleNames(t, conn); !slices.Equal(got, want) {
+			t.Errorf("bulk=%v: got people %q, want %q", bulk, got, want)
+		}
+
+		if !bulk {
+			dst := copyPeople
+			dst.Insert = ""
+			if _, err := conn.CopyFrom(context.Background(), dst, slices.Values(rows)); err == nil {
+				t.Error("CopyFrom without Insert succeeded")
+			}
+		}
+		conn.Close()
+		closeDB(t, db)
+	}
+}
+
+func TestCopyFromDB(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	ctx := context.Background()
+
+	// Make the idle connection bad: the copy is retried, since the driver
+	// fails before consuming rows.
+	db.mu.Lock()
+	for _, dc := range db.freeConn {
+		dc.ci.(*fakeConn).stickyBad = true
+	}
+	db.mu.Unlock()
+
+	rows := func(yield func([]any) bool) {
+		for _, name := range []string{"Dave", "Eve"} {
+			if !yield([]any{name, len(name)}) {
+				return
+			}
+		}
+	}
+	n, err := db.CopyFrom(ctx, copyPeople, rows)
+	if n != 2 || err != nil {
+		t.Fatalf("CopyFrom = %d, %v; want 2 rows", n, err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := tx.CopyFrom(ctx, copyPeople, rows); n != 2 || err != nil {
+		t.Fatalf("Tx.CopyFrom = %d, %v; want 2 rows", n, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Alice", "Bob", "Chris", "Dave", "Eve", "Dave", "Eve"}
+	if got := peopleNames(t, db); !slices.Equal(got, want) {
+		t.Errorf("got people %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,445 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultHealthCheckInterval = 5 * time.Second
+	healthCheckTimeout         = 5 * time.Second
+)
+
+// A Cluster is a handle to a replicated database, made of a primary server,
+// which accepts writes, and of replicas of it, which serve reads. It
+// maintains a [DB], with its own pool of connections, for each server, or
+// host, of the cluster.
+//
+// Statements are executed on the primary, unless they are marked with
+// [WithReplica] or run in a read-only transaction, in which case they are
+// routed to a replica, chosen in turn among the healthy ones. If there is
+// no healthy replica, the primary is used.
+//
+// The health of the hosts is checked periodically, see
+// [Cluster.SetHealthCheckInterval]. A host is healthy if it can be pinged
+// and, if its driver connections implement [driver.ReplicationStatuser],
+// if it reports its replication status. Replicas that lag behind the
+// primary by more than the limit set with [Cluster.SetMaxReplicaLag] are
+// ejected until they catch up.
+//
+// When a health check finds that the primary is unhealthy or has been
+// demoted, the Cluster fails over to the healthy host that reports itself
+// as the primary, if any. As only drivers that implement
+// [driver.ReplicationStatuser] report which host is the primary, the
+// Cluster never fails over otherwise. Statements that failed are not
+// retried.
+//
+// A health check is also started when a statement executed by a method of
+// the Cluster fails with [driver.ErrNotPrimary]. The errors of statements
+// executed through a [Tx], [Conn] or [Stmt] obtained from the Cluster are
+// not observed: they only affect the Cluster through the periodic health
+// checks, or through calls to [Cluster.CheckHealth].
+//
+// A Cluster is safe for concurrent use by multiple goroutines.
+type Cluster struct {
+	hosts []*clusterHost
+
+	mu        sync.Mutex // protects following fields and the state of hosts
+	primary   int        // index in hosts of the primary
+	failovers int64
+	interval  time.Duration
+	maxLag    time.Duration
+
+	next atomic.Uint64 // used to choose replicas in turn
+
+	wake  chan struct{} // signals a change of interval to healthChecker
+	check chan struct{} // requests a health check from healthChecker
+	stop  func()        // stops healthChecker
+	done  chan struct{} // closed when healthChecker returns
+}
+
+type clusterHost struct {
+	db *DB
+
+	// Result of the last health check, protected by Cluster.mu.
+	checked   time.Time
+	err       error
+	hasStatus bool // whether the driver reported status
+	status    driver.ReplicationStatus
+}
+
+// healthy reports whether the last health check of h succeeded.
+// Hosts are presumed healthy until they are checked.
+func (h *clusterHost) healthy() bool {
+	return h.err == nil
+}
+
+// OpenCluster opens a cluster made of the primary server that primary
+// connects to and of the replica servers that replicas connect to, which
+// are opened with [OpenDB]. The hosts of the cluster are numbered in that
+// order, starting with the primary at 0.
+//
+// Like OpenDB, OpenCluster may just validate its arguments without creating
+// connections to the database. It starts a goroutine that checks the
+// health of the hosts until the Cluster is closed.
+func OpenCluster(primary driver.Connector, replicas ...driver.Connector) *Cluster {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Cluster{
+		interval: defaultHealthCheckInterval,
+		wake:     make(chan struct{}, 1),
+		check:    make(chan struct{}, 1),
+		stop:     cancel,
+		done:     make(chan struct{}),
+	}
+	for _, connector := range append([]driver.Connector{primary}, replicas...) {
+		c.hosts = append(c.hosts, &clusterHost{db: OpenDB(connector)})
+	}
+	go c.healthChecker(ctx)
+	return c
+}
+
+// WithReplica returns a copy of ctx which marks the statements executed
+// with it by a [Cluster] as reads that may be served by a replica.
+//
+// Replicas may lag behind the primary, so such reads may not observe the
+// most recent writes.
+func WithReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+type replicaKey struct{}
+
+func isReplicaRead(ctx context.Context) bool {
+	v, _ := ctx.Value(replicaKey{}).(bool)
+	return v
+}
+
+// Hosts returns the DBs of the hosts of the cluster, in the order in which
+// they were passed to [OpenCluster]. They may be used to configure the
+// pools of connections of the hosts, for instance with [DB.SetMaxOpenConns].
+func (c *Cluster) Hosts() []*DB {
+	dbs := make([]*DB, len(c.hosts))
+	for i, h := range c.hosts {
+		dbs[i] = h.db
+	}
+	return dbs
+}
+
+// Primary returns the DB of the current primary.
+func (c *Cluster) Primary() *DB {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hosts[c.primary].db
+}
+
+// Replica returns the DB of a healthy replica, or of the primary if there
+// is none. Successive calls choose the healthy replicas in turn.
+func (c *Cluster) Replica() *DB {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for i := range c.hosts {
+		if c.isReplicaLocked(i) {
+			n++
+		}
+	}
+	if n == 0 {
+		return c.hosts[c.primary].db
+	}
+	k := int(c.next.Add(1) % uint64(n))
+	for i, h := range c.hosts {
+		if c.isReplicaLocked(i) {
+			if k == 0 {
+				return h.db
+			}
+			k--
+		}
+	}
+	panic("unreachable")
+}
+
+// isReplicaLocked reports whether the host i may serve reads as a replica.
+func (c *Cluster) isReplicaLocked(i int) bool {
+	h := c.hosts[i]
+	if i == c.primary || !h.healthy() {
+		return false
+	}
+	return c.maxLag <= 0 || h.status.Lag <= c.maxLag
+}
+
+// DB returns the DB that executes the statements run with ctx: a replica,
+// as returned by [Cluster.Replica], if ctx was marked with [WithReplica],
+// or the primary.
+func (c *Cluster) DB(ctx context.Context) *DB {
+	if isReplicaRead(ctx) {
+		return c.Replica()
+	}
+	return c.Primary()
+}
+
+// ExecContext executes a query without returning any rows on the primary.
+// The args are for any placeholder parameters in the query.
+func (c *Cluster) ExecContext(ctx context.Context, query string, args ...any) (Result, error) {
+	res, err := c.Primary().ExecContext(ctx, query, args...)
+	c.noteErr(err)
+	return res, err
+}
+
+// QueryContext executes a query that returns rows, typically a SELECT, on
+// the DB returned by [Cluster.DB]. The args are for any placeholder
+// parameters in the query.
+func (c *Cluster) QueryContext(ctx context.Context, query string, args ...any) (*Rows, error) {
+	rows, err := c.DB(ctx).QueryContext(ctx, query, args...)
+	c.noteErr(err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one
+// row on the DB returned by [Cluster.DB]. See [DB.QueryRowContext] for
+// details.
+func (c *Cluster) QueryRowContext(ctx context.Context, query string, args ...any) *Row {
+	row := c.DB(ctx).QueryRowContext(ctx, query, args...)
+	c.noteErr(row.err)
+	return row
+}
+
+// PrepareContext creates a prepared statement on the DB returned by
+// [Cluster.DB]. See [DB.PrepareContext] for details.
+func (c *Cluster) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	stmt, err := c.DB(ctx).PrepareContext(ctx, query)
+	c.noteErr(err)
+	return stmt, err
+}
+
+// BeginTx starts a transaction on a replica, as returned by
+// [Cluster.Replica], if opts is read-only or ctx was marked with
+// [WithReplica], or on the primary otherwise. See [DB.BeginTx] for details.
+func (c *Cluster) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	db := c.DB(ctx)
+	if opts != nil && opts.ReadOnly {
+		db = c.Replica()
+	}
+	tx, err := db.BeginTx(ctx, opts)
+	c.noteErr(err)
+	return tx, err
+}
+
+// Conn returns a single connection to the DB returned by [Cluster.DB].
+// See [DB.Conn] for details.
+func (c *Cluster) Conn(ctx context.Context) (*Conn, error) {
+	conn, err := c.DB(ctx).Conn(ctx)
+	c.noteErr(err)
+	return conn, err
+}
+
+// noteErr requests a health check if err reports that the primary has
+// been demoted.
+func (c *Cluster) noteErr(err error) {
+	if err != nil && errors.Is(err, driver.ErrNotPrimary) {
+		select {
+		case c.check <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// SetHealthCheckInterval sets the interval between the health checks of
+// the hosts. If d <= 0, the health is only checked when a statement fails
+// with [driver.ErrNotPrimary] or when [Cluster.CheckHealth] is called.
+//
+// The default interval is 5 seconds.
+func (c *Cluster) SetHealthCheckInterval(d time.Duration) {
+	c.mu.Lock()
+	c.interval = d
+	c.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SetMaxReplicaLag sets the maximum replication lag of the replicas that
+// serve reads, as reported by [driver.ReplicationStatuser]. If d <= 0,
+// replicas are not ejected because of their lag, which is the default.
+func (c *Cluster) SetMaxReplicaLag(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxLag = d
+}
+
+// healthChecker runs in a separate goroutine, and checks the health of the
+// hosts periodically or when requested.
+func (c *Cluster) healthChecker(ctx context.Context) {
+	defer close(c.done)
+	var timer *time.Timer
+	var tick <-chan time.Time
+	reset := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		c.mu.Lock()
+		d := c.interval
+		c.mu.Unlock()
+		tick = nil
+		if d > 0 {
+			timer = time.NewTimer(d)
+			tick = timer.C
+		}
+	}
+	reset()
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-c.wake:
+			reset()
+			continue
+		case <-c.check:
+		case <-tick:
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		c.CheckHealth(checkCtx)
+		cancel()
+		reset()
+	}
+}
+
+// CheckHealth checks the health of the hosts of the cluster now, and fails
+// over the primary if needed. It returns when the checks are done or when
+// ctx is done.
+func (c *Cluster) CheckHealth(ctx context.Context) {
+	results := make([]clusterHost, len(c.hosts))
+	var wg sync.WaitGroup
+	for i, h := range c.hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkHost(ctx, h.db)
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, h := range c.hosts {
+		h.checked = results[i].checked
+		h.err = results[i].err
+		h.hasStatus = results[i].hasStatus
+		h.status = results[i].status
+	}
+	c.failoverLocked()
+}
+
+// checkHost checks the health of db, and returns the result in the
+// corresponding fields of a clusterHost.
+func checkHost(ctx context.Context, db *DB) (res clusterHost) {
+	res.checked = nowFunc()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	defer conn.Close()
+	if err := conn.PingContext(ctx); err != nil {
+		res.err = err
+		return res
+	}
+	res.err = conn.Raw(func(driverConn any) error {
+		rs, ok := driverConn.(driver.ReplicationStatuser)
+		if !ok {
+			return nil
+		}
+		status, err := rs.ReplicationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		res.hasStatus = true
+		res.status = status
+		return nil
+	})
+	return res
+}
+
+// failoverLocked makes a healthy host that reports itself as the primary
+// the new primary, if the current one is unhealthy or was demoted. Hosts
+// whose driver does not implement driver.ReplicationStatuser never report
+// themselves as the primary, so without it, the primary never changes.
+func (c *Cluster) failoverLocked() {
+	cur := c.hosts[c.primary]
+	if cur.healthy() && (!cur.hasStatus || cur.status.Primary) {
+		return
+	}
+	for i, h := range c.hosts {
+		if i != c.primary && h.healthy() && h.hasStatus && h.status.Primary {
+			c.primary = i
+			c.failovers++
+			return
+		}
+	}
+}
+
+// ClusterStats contains statistics about a [Cluster].
+type ClusterStats struct {
+	Hosts     []HostStats // Statistics of each host, in the order of Cluster.Hosts.
+	Failovers int64       // The total number of times the primary changed.
+}
+
+// HostStats contains statistics about a host of a [Cluster].
+type HostStats struct {
+	DBStats
+
+	Primary   bool          // Whether the host is the current primary.
+	Healthy   bool          // Whether the host is used: see Cluster.Stats.
+	Lag       time.Duration // The replication lag reported by the last health check.
+	LastCheck time.Time     // The time of the last health check, if any.
+	Err       error         // The error of the last health check, if any.
+}
+
+// Stats returns statistics about the cluster and its hosts.
+//
+// A host is reported as healthy if its last health check succeeded, or if
+// it was not checked yet, and if, for a replica, its replication lag is
+// within the limit set with [Cluster.SetMaxReplicaLag].
+func (c *Cluster) Stats() ClusterStats {
+	stats := ClusterStats{Hosts: make([]HostStats, len(c.hosts))}
+	for i, h := range c.hosts {
+		stats.Hosts[i].DBStats = h.db.Stats()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats.Failovers = c.failovers
+	for i, h := range c.hosts {
+		hs := &stats.Hosts[i]
+		hs.Primary = i == c.primary
+		hs.Healthy = h.healthy() && (hs.Primary || c.isReplicaLocked(i))
+		hs.Lag = h.status.Lag
+		hs.LastCheck = h.checked
+		hs.Err = h.err
+	}
+	return stats
+}
+
+// Close stops the health checks and closes the DBs of the hosts.
+func (c *Cluster) Close() error {
+	c.stop()
+	<-c.done
+	var errs []error
+	for _, h := range c.hosts {
+		if err := h.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
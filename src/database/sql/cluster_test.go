@@ -0,0 +1,201 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestCluster returns a cluster of n hosts, each with a people table
+// holding a single row named after the host, which are all replicas but
+// the first one.
+func newTestCluster(t *testing.T, n int) (*Cluster, []*fakeDB) {
+	var connectors []driver.Connector
+	var fdbs []*fakeDB
+	for i := range n {
+		name := "cluster" + string(rune('0'+i))
+		connectors = append(connectors, &fakeConnector{name: name})
+		fdbs = append(fdbs, fdriver.(*fakeDriver).getDB(name))
+	}
+	c := OpenCluster(connectors[0], connectors[1:]...)
+	c.SetHealthCheckInterval(0)
+	for i, db := range c.Hosts() {
+		fdbs[i].replica.Store(false)
+		fdbs[i].lag.Store(0)
+		fdbs[i].down.Store(false)
+		exec(t, db, "WIPE")
+		exec(t, db, "CREATE|people|name=string,age=int32")
+		exec(t, db, "INSERT|people|name=?,age=?", fdbs[i].name, i)
+		fdbs[i].replica.Store(i > 0)
+	}
+	return c, fdbs
+}
+
+// servedBy returns the name of the host that serves a query run with ctx.
+func servedBy(t *testing.T, c *Cluster, ctx context.Context) string {
+	t.Helper()
+	var name string
+	if err := c.QueryRowContext(ctx, "SELECT|people|name|").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestClusterRouting(t *testing.T) {
+	c, _ := newTestCluster(t, 3)
+	defer c.Close()
+	ctx := context.Background()
+	replicaCtx := WithReplica(ctx)
+
+	if got := servedBy(t, c, ctx); got != "cluster0" {
+		t.Errorf("query served by %s, want cluster0", got)
+	}
+	seen := map[string]int{}
+	for range 4 {
+		seen[servedBy(t, c, replicaCtx)]++
+	}
+	if seen["cluster1"] != 2 || seen["cluster2"] != 2 {
+		t.Errorf("replica queries served by %v, want cluster1 and cluster2 in turn", seen)
+	}
+
+	if _, err := c.ExecContext(replicaCtx, "INSERT|people|name=Dave,age=4"); err != nil {
+		t.Errorf("ExecContext with a replica context: %v", err)
+	}
+
+	tx, err := c.BeginTx(replicaCtx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name string
+	if err := tx.QueryRowContext(ctx, "SELECT|people|name|").Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+	if name == "cluster0" {
+		t.Error("transaction with a replica context runs on the primary")
+	}
+
+	stmt, err := c.PrepareContext(ctx, "SELECT|people|name|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.QueryRow().Scan(&name); err != nil || name != "cluster0" {
+		t.Errorf("statement prepared on %s, %v; want cluster0", name, err)
+	}
+	stmt.Close()
+}
+
+func TestClusterEjection(t *testing.T) {
+	c, fdbs := newTestCluster(t, 3)
+	defer c.Close()
+	ctx := context.Background()
+	replicaCtx := WithReplica(ctx)
+
+	c.SetMaxReplicaLag(time.Second)
+	fdbs[1].lag.Store(int64(time.Minute))
+	c.CheckHealth(ctx)
+	for range 3 {
+		if got := servedBy(t, c, replicaCtx); got != "cluster2" {
+			t.Fatalf("replica query served by %s, want cluster2", got)
+		}
+	}
+	stats := c.Stats()
+	if h := stats.Hosts[1]; h.Healthy || h.Lag != time.Minute || h.Err != nil || h.LastCheck.IsZero() {
+		t.Errorf("stats of lagging replica = %+v", h)
+	}
+	if h := stats.Hosts[2]; !h.Healthy || h.Primary || h.OpenConnections == 0 {
+		t.Errorf("stats of healthy replica = %+v", h)
+	}
+
+	// With no healthy replica, reads go to the primary.
+	fdbs[2].down.Store(true)
+	c.CheckHealth(ctx)
+	if got := servedBy(t, c, replicaCtx); got != "cluster0" {
+		t.Errorf("replica query served by %s, want cluster0", got)
+	}
+	if h := c.Stats().Hosts[2]; h.Healthy || h.Err == nil {
+		t.Errorf("stats of down replica = %+v", h)
+	}
+
+	// Ejected replicas come back once they are healthy again.
+	fdbs[1].lag.Store(0)
+	fdbs[2].down.Store(false)
+	c.CheckHealth(ctx)
+	seen := map[string]bool{}
+	for range 2 {
+		seen[servedBy(t, c, replicaCtx)] = true
+	}
+	if !seen["cluster1"] || !seen["cluster2"] {
+		t.Errorf("replica queries served by %v, want cluster1 and cluster2", seen)
+	}
+}
+
+func TestClusterFailover(t *testing.T) {
+	c, fdbs := newTestCluster(t, 3)
+	defer c.Close()
+	ctx := context.Background()
+
+	// Promote cluster2.
+	fdbs[0].replica.Store(true)
+	fdbs[2].replica.Store(false)
+
+	_, err := c.ExecContext(ctx, "INSERT|people|name=Dave,age=4")
+	if !errors.Is(err, driver.ErrNotPrimary) {
+		t.Fatalf("ExecContext on demoted primary: got %v, want ErrNotPrimary", err)
+	}
+	if !waitCondition(t, func() bool { return c.Stats().Failovers == 1 }) {
+		t.Fatal("no failover after ErrNotPrimary")
+	}
+	if got := servedBy(t, c, ctx); got != "cluster2" {
+		t.Errorf("query served by %s after failover, want cluster2", got)
+	}
+	if _, err := c.ExecContext(ctx, "INSERT|people|name=Dave,age=4"); err != nil {
+		t.Errorf("ExecContext after failover: %v", err)
+	}
+	stats := c.Stats()
+	if !stats.Hosts[2].Primary || stats.Hosts[0].Primary || !stats.Hosts[0].Healthy {
+		t.Errorf("stats after failover = %+v", stats)
+	}
+
+	// Without another primary, the cluster keeps its primary.
+	fdbs[2].down.Store(true)
+	c.CheckHealth(ctx)
+	if c.Primary() != c.Hosts()[2] || c.Stats().Failovers != 1 {
+		t.Error("cluster failed over without a new primary")
+	}
+}
+
+func TestClusterQueryRowFailover(t *testing.T) {
+	c, fdbs := newTestCluster(t, 2)
+	defer c.Close()
+	ctx := context.Background()
+
+	fdbs[0].replica.Store(true)
+	fdbs[1].replica.Store(false)
+	var name string
+	err := c.QueryRowContext(ctx, "SELECT|primary|name|").Scan(&name)
+	if !errors.Is(err, driver.ErrNotPrimary) {
+		t.Fatalf("QueryRowContext on demoted primary: got %v, want ErrNotPrimary", err)
+	}
+	if !waitCondition(t, func() bool { return c.Stats().Failovers == 1 }) {
+		t.Fatal("no failover after ErrNotPrimary from QueryRowContext")
+	}
+}
+
+func TestClusterHealthCheckInterval(t *testing.T) {
+	c, fdbs := newTestCluster(t, 2)
+	defer c.Close()
+
+	fdbs[1].down.Store(true)
+	c.SetHealthCheckInterval(time.Millisecond)
+	if !waitCondition(t, func() bool { return !c.Stats().Hosts[1].Healthy }) {
+		t.Fatal("down replica not ejected by periodic health checks")
+	}
+	c.SetHealthCheckInterval(0)
+}
@@ -23,6 +23,9 @@
 // also allows queries to accept per-query options as a parameter by returning
 // [ErrRemoveArgument] from CheckNamedValue.
 //
+// If statements can be sent to the database in batches, or rows copied in
+// bulk, the driver's [Conn] should implement [Batcher] or [Copier].
+//
 // If multiple result sets are supported, [Rows] should implement [RowsNextResultSet].
 // If the driver knows how to describe the types present in the returned result
 // it should implement the following interfaces: [RowsColumnTypeScanType],
@@ -41,7 +44,9 @@ package driver
 import (
 	"context"
 	"errors"
+	"iter"
 	"reflect"
+	"time"
 )
 
 // Value is a value that drivers must be able to handle.
@@ -162,6 +167,33 @@ var ErrSkip = errors.New("driver: skip fast-path; continue as if unimplemented")
 // wrap ErrBadConn or implement the Is(error) bool method.
 var ErrBadConn = errors.New("driver: bad connection")
 
+// ErrNotPrimary should be returned, possibly wrapped, by a driver when a
+// statement fails because the database server it is connected to does not
+// accept writes, for instance because it is a replica or because it was
+// demoted. A [database/sql.Cluster] then looks for a new primary server.
+var ErrNotPrimary = errors.New("driver: database server is not the primary")
+
+// ReplicationStatus describes the role of the database server that a
+// [Conn] is connected to, in a replicated cluster.
+type ReplicationStatus struct {
+	// Primary reports whether the server is the primary, which accepts
+	// writes.
+	Primary bool
+
+	// Lag is how far behind the primary the server is, if it is a
+	// replica.
+	Lag time.Duration
+}
+
+// ReplicationStatuser is an optional interface that may be implemented by
+// a [Conn]. It is used by the health checks of a [database/sql.Cluster]
+// to detect which server is the primary and how far behind the replicas
+// are.
+type ReplicationStatuser interface {
+	// ReplicationStatus returns the replication status of the server.
+	ReplicationStatus(ctx context.Context) (ReplicationStatus, error)
+}
+
 // Pinger is an optional interface that may be implemented by a [Conn].
 //
 // If a [Conn] does not implement Pinger, the [database/sql.DB.Ping] and
@@ -227,6 +259,54 @@ type QueryerContext interface {
 	QueryContext(ctx context.Context, query string, args []NamedValue) (Rows, error)
 }
 
+// BatchQuery is a statement of a batch executed by a [Batcher].
+type BatchQuery struct {
+	Query string
+	Args  []NamedValue
+}
+
+// Batcher is an optional interface that may be implemented by a [Conn].
+//
+// If a [Conn] does not implement Batcher, [database/sql.DB.ExecBatch]
+// executes the statements of a batch one at a time.
+type Batcher interface {
+	// ExecBatch executes the statements of batch in order, sending them to
+	// the database together where possible, and returns one Result per
+	// statement.
+	//
+	// If a statement fails, the statements that follow it must not be
+	// executed, and ExecBatch returns the results of the statements that
+	// preceded it together with the error. ErrBadConn must only be
+	// returned if no statement was executed.
+	//
+	// ExecBatch may return [ErrSkip], before executing any statement.
+	//
+	// ExecBatch must honor the context timeout and return when the context is canceled.
+	ExecBatch(ctx context.Context, batch []BatchQuery) ([]Result, error)
+}
+
+// Copier is an optional interface that may be implemented by a [Conn]
+// to insert many rows at once, for instance with a COPY FROM statement.
+//
+// If a [Conn] does not implement Copier, [database/sql.DB.CopyFrom]
+// inserts the rows one at a time.
+type Copier interface {
+	// CopyFrom inserts rows into the given columns of table, and returns
+	// the number of rows inserted.
+	//
+	// Each row has one value per column, converted as the arguments of a
+	// query. If a row cannot be converted, rows yields it with a non-nil
+	// error and stops; CopyFrom must then abort the copy and return that
+	// error. rows must not be used after CopyFrom returns.
+	//
+	// ErrBadConn must only be returned if no row was consumed from rows.
+	//
+	// CopyFrom may return [ErrSkip], before consuming any row.
+	//
+	// CopyFrom must honor the context timeout and return when the context is canceled.
+	CopyFrom(ctx context.Context, table string, columns []string, rows iter.Seq2[[]NamedValue, error]) (int64, error)
+}
+
 // Conn is a connection to a database. It is not used concurrently
 // by multiple goroutines.
 //
@@ -521,6 +601,50 @@ type Tx interface {
 	Rollback() error
 }
 
+// Savepointer is an optional interface that may be implemented by a [Tx].
+//
+// If a [Tx] does not implement Savepointer, the savepoint methods of
+// [database/sql.Tx] execute the SAVEPOINT, ROLLBACK TO SAVEPOINT and
+// RELEASE SAVEPOINT statements instead.
+type Savepointer interface {
+	// Savepoint creates a savepoint with the given name in the transaction.
+	Savepoint(ctx context.Context, name string) error
+
+	// RollbackTo undoes the changes made in the transaction since the
+	// named savepoint was created, and releases the savepoints created
+	// after it. The savepoint itself is kept.
+	RollbackTo(ctx context.Context, name string) error
+
+	// Release releases the named savepoint and the savepoints created
+	// after it, keeping the changes made since they were created.
+	Release(ctx context.Context, name string) error
+}
+
+// ErrorClass is the class of an error returned by a driver, as reported
+// by an [ErrorClassifier].
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown is the class of the errors that are not classified.
+	ErrorClassUnknown ErrorClass = iota
+
+	// ErrorClassSerialization is the class of the errors reporting that a
+	// transaction conflicts with concurrent transactions, such as
+	// serialization failures and deadlocks. The transaction must be rolled
+	// back, and may succeed if retried.
+	ErrorClassSerialization
+)
+
+// ErrorClassifier is an optional interface that may be implemented by a
+// [Connector] or a [Driver] to classify the errors returned by its
+// connections. It is used by [database/sql.DB.RunInTx] to decide whether
+// to retry a transaction.
+type ErrorClassifier interface {
+	// ClassifyError returns the class of err, which may wrap an error
+	// returned by the driver.
+	ClassifyError(err error) ErrorClass
+}
+
 // RowsAffected implements [Result] for an INSERT or UPDATE operation
 // which mutates a number of rows.
 type RowsAffected int64
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"reflect"
 	"slices"
 	"strconv"
@@ -70,6 +71,16 @@ func (c *fakeConnector) Driver() driver.Driver {
 	return fdriver
 }
 
+// errFakeSerialization is classified as a serialization failure.
+var errFakeSerialization = errors.New("fakedb: serialization failure")
+
+func (c *fakeConnector) ClassifyError(err error) driver.ErrorClass {
+	if errors.Is(err, errFakeSerialization) {
+		return driver.ErrorClassSerialization
+	}
+	return driver.ErrorClassUnknown
+}
+
 func (c *fakeConnector) Close() error {
 	if c.closed {
 		return errors.New("fakedb: connector is closed")
@@ -93,6 +104,11 @@ type fakeDB struct {
 
 	useRawBytes atomic.Bool
 
+	// Replication status, for clusters.
+	replica atomic.Bool  // INSERT, and SELECT from table "primary", fail with driver.ErrNotPrimary
+	lag     atomic.Int64 // replication lag, in nanoseconds
+	down    atomic.Bool  // ReplicationStatus fails
+
 	mu       sync.Mutex
 	tables   map[string]*table
 	badConn  bool
@@ -146,6 +162,7 @@ type fakeConn struct {
 	stmtsMade   int
 	stmtsClosed int
 	numPrepare  int
+	numBulk     int // calls to ExecBatch and CopyFrom
 
 	// bad connection tests; see isBad()
 	bad       bool
@@ -153,6 +170,13 @@ type fakeConn struct {
 
 	skipDirtySession bool // tests that use Conn should set this to true.
 
+	// noBulk makes ExecBatch and CopyFrom return driver.ErrSkip.
+	noBulk bool
+
+	// savepoints are the savepoint statements executed, such as
+	// "SAVEPOINT sp".
+	savepoints []string
+
 	// dirtySession tests ResetSession, true if a query has executed
 	// until ResetSession is called.
 	dirtySession bool
@@ -492,6 +516,99 @@ func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver
 	return nil, driver.ErrSkip
 }
 
+var (
+	_ driver.Batcher = (*fakeConn)(nil)
+	_ driver.Copier  = (*fakeConn)(nil)
+)
+
+func (c *fakeConn) ExecBatch(ctx context.Context, batch []driver.BatchQuery) ([]driver.Result, error) {
+	if c.noBulk {
+		return nil, driver.ErrSkip
+	}
+	if c.stickyBad {
+		return nil, fakeError{Message: "ExecBatch: Sticky Bad", Wrapped: driver.ErrBadConn}
+	}
+	if c.isDirtyAndMark() {
+		return nil, errFakeConnSessionDirty
+	}
+	c.incrStat(&c.numBulk)
+
+	// The statements of the batch run in a single session.
+	skip := c.skipDirtySession
+	c.skipDirtySession = true
+	defer func() { c.skipDirtySession = skip }()
+
+	var results []driver.Result
+	for _, q := range batch {
+		res, err := c.execBulk(ctx, q.Query, q.Args)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (c *fakeConn) execBulk(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	si, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer si.Close()
+	if n := si.NumInput(); n != len(args) {
+		return nil, errf("got %d arguments for %q, want %d", len(args), query, n)
+	}
+	return si.(*fakeStmt).ExecContext(ctx, args)
+}
+
+// CopyFrom inserts the rows with INSERT|<table>|<col>=?,<col2>=?,...
+func (c *fakeConn) CopyFrom(ctx context.Context, table string, columns []string, rows iter.Seq2[[]driver.NamedValue, error]) (int64, error) {
+	if c.noBulk {
+		return 0, driver.ErrSkip
+	}
+	if c.stickyBad {
+		return 0, fakeError{Message: "CopyFrom: Sticky Bad", Wrapped: driver.ErrBadConn}
+	}
+	c.incrStat(&c.numBulk)
+
+	query := "INSERT|" + table + "|" + strings.Join(columns, "=?,") + "=?"
+	si, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer si.Close()
+	stmt := si.(*fakeStmt)
+	var n int64
+	for args, err := range rows {
+		if err != nil {
+			return n, err
+		}
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if err := checkSubsetTypes(c.db.allowAny, args); err != nil {
+			return n, err
+		}
+		if _, err := stmt.execInsert(args, true); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+var _ driver.ReplicationStatuser = (*fakeConn)(nil)
+
+func (c *fakeConn) ReplicationStatus(ctx context.Context) (driver.ReplicationStatus, error) {
+	if c.db.down.Load() {
+		return driver.ReplicationStatus{}, errf("database %q is down", c.db.name)
+	}
+	return driver.ReplicationStatus{
+		Primary: !c.db.replica.Load(),
+		Lag:     time.Duration(c.db.lag.Load()),
+	}, nil
+}
+
 func errf(msg string, args ...any) error {
 	return errors.New("fakedb: " + fmt.Sprintf(msg, args...))
 }
@@ -697,6 +814,10 @@ func (c *fakeConn) PrepareContext(ctx context.Context, query string) (driver.Stm
 			// Used for some of the concurrent tests.
 			stmt, err = c.prepareInsert(ctx, stmt, parts)
 		default:
+			if strings.Contains(cmd, "SAVEPOINT ") {
+				stmt.cmd = "SAVEPOINT"
+				break
+			}
 			stmt.Close()
 			return nil, errf("unsupported command type %q", cmd)
 		}
@@ -792,12 +913,18 @@ func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (d
 	case "USE_RAWBYTES":
 		s.c.db.useRawBytes.Store(true)
 		return driver.ResultNoRows, nil
+	case "SAVEPOINT":
+		s.c.savepoints = append(s.c.savepoints, s.q)
+		return driver.ResultNoRows, nil
 	case "CREATE":
 		if err := db.createTable(s.table, s.colName, s.colType); err != nil {
 			return nil, err
 		}
 		return driver.ResultNoRows, nil
 	case "INSERT":
+		if db.replica.Load() {
+			return nil, fakeError{Message: "INSERT on a replica", Wrapped: driver.ErrNotPrimary}
+		}
 		return s.execInsert(args, true)
 	case "NOSERT":
 		// Do all the prep-work like for an INSERT but don't actually insert the row.
@@ -901,6 +1028,9 @@ func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (
 	if len(args) != s.placeholders {
 		panic("error in pkg db; should only get here if size is correct")
 	}
+	if s.table == "primary" && db.replica.Load() {
+		return nil, fakeError{Message: "SELECT on a replica", Wrapped: driver.ErrNotPrimary}
+	}
 
 	setMRows := make([][]*row, 0, 1)
 	setColumns := make([][]string, 0, 1)
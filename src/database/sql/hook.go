@@ -0,0 +1,282 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// An Op is a kind of operation observed by a [Hook].
+type Op int
+
+const (
+	// OpConn is the acquisition of a connection from the pool, including
+	// waiting for a free connection and opening a new one.
+	OpConn Op = iota + 1
+
+	// OpPrepare is the preparation of a statement.
+	OpPrepare
+
+	// OpExec is the execution of a statement that returns no rows.
+	OpExec
+
+	// OpQuery is the execution of a query, until it returns its [Rows].
+	OpQuery
+
+	// OpRows is the iteration over the [Rows] of a query. It starts when
+	// the driver returns the rows, just before the OpQuery operation ends,
+	// and ends when the Rows are closed.
+	OpRows
+
+	// OpBegin is the start of a transaction.
+	OpBegin
+
+	// OpCommit is the commit of a transaction.
+	OpCommit
+
+	// OpRollback is the rollback of a transaction, including rollbacks
+	// caused by the cancellation of its context.
+	OpRollback
+
+	// OpBatch is the execution of a [Batch] of statements.
+	OpBatch
+
+	// OpCopy is the copy of rows into a table by CopyFrom.
+	OpCopy
+)
+
+var opNames = [...]string{
+	OpConn:     "Conn",
+	OpPrepare:  "Prepare",
+	OpExec:     "Exec",
+	OpQuery:    "Query",
+	OpRows:     "Rows",
+	OpBegin:    "Begin",
+	OpCommit:   "Commit",
+	OpRollback: "Rollback",
+	OpBatch:    "Batch",
+	OpCopy:     "Copy",
+}
+
+func (op Op) String() string {
+	if op > 0 && int(op) < len(opNames) {
+		return opNames[op]
+	}
+	return "Op(" + strconv.Itoa(int(op)) + ")"
+}
+
+// A HookEvent describes an operation observed by a [Hook]. The fields
+// marked as results are only set when [Hook.After] is called.
+type HookEvent struct {
+	// Op is the kind of operation.
+	Op Op
+
+	// Query is the SQL text of the statement, for OpPrepare, OpExec,
+	// OpQuery and OpRows, or the name of the table, for OpCopy.
+	Query string
+
+	// NumArgs is the number of arguments of the statement, for OpExec and
+	// OpQuery, or the number of statements, for OpBatch.
+	NumArgs int
+
+	// Start is the time at which the operation started.
+	Start time.Time
+
+	// Duration is the duration of the operation. It is a result.
+	Duration time.Duration
+
+	// Err is the error that the operation returned, if any. For OpRows,
+	// it is the error reported by [Rows.Err]. It is a result.
+	Err error
+
+	// ConnWait is the total time spent acquiring connections during the
+	// operation, which is included in Duration. It is a result.
+	ConnWait time.Duration
+
+	// Retries is the number of times the operation was retried because
+	// the driver reported a bad connection with
+	// [database/sql/driver.ErrBadConn]. It is a result.
+	Retries int
+
+	// RowsAffected is the number of rows affected by OpExec, the total
+	// number of rows affected by the executed statements of OpBatch, or
+	// the number of rows inserted by OpCopy. It is -1 if it is not known.
+	// It is a result.
+	RowsAffected int64
+
+	// RowsRead is the number of rows read by OpRows. It is a result.
+	RowsRead int64
+}
+
+// A Hook observes the operations of a [DB] and of the [Conn], [Tx], [Stmt]
+// and [Rows] created from it, for instance to log them with [log/slog] or
+// trace them with [runtime/trace].
+//
+// Hooks are installed with [DB.SetHook], or by passing a
+// [database/sql/driver.Connector] that also implements Hook to [OpenDB].
+//
+// Operations may be nested: OpConn operations occur during the operations
+// that acquire connections. Before and After are called on the goroutine
+// that runs the operation, except that After may be called on another
+// goroutine for an OpRows or OpRollback operation that ends because its
+// context is canceled. Hook methods may be called concurrently.
+type Hook interface {
+	// Before is called when an operation starts. It returns the context
+	// in which the operation runs, which is passed to the driver and to
+	// After, and may be ctx itself.
+	//
+	// The result fields of ev are not set yet. Before must not modify ev,
+	// nor retain it after After returns.
+	Before(ctx context.Context, ev *HookEvent) context.Context
+
+	// After is called when an operation ends, with the context returned
+	// by Before.
+	After(ctx context.Context, ev *HookEvent)
+}
+
+// SetHook sets the hook that observes the operations of db, replacing any
+// previous one. If h is nil, operations are not observed.
+//
+// Operations that have already started are reported to the previous hook.
+func (db *DB) SetHook(h Hook) {
+	if h == nil {
+		db.hook.Store(nil)
+		return
+	}
+	db.hook.Store(&h)
+}
+
+// A hookOp is an operation observed by a Hook. A nil *hookOp is an
+// operation that is not observed.
+type hookOp struct {
+	hook   Hook
+	parent context.Context // the context passed to Before
+	ctx    context.Context // the context returned by Before
+	ev     HookEvent
+}
+
+type hookOpKey struct{}
+
+// opFromContext returns the innermost observed operation of ctx, or nil.
+func opFromContext(ctx context.Context) *hookOp {
+	op, _ := ctx.Value(hookOpKey{}).(*hookOp)
+	return op
+}
+
+// startOp starts an operation, if db has a hook, and returns the context in
+// which it runs.
+func (db *DB) startOp(ctx context.Context, kind Op, query string, numArgs int) (context.Context, *hookOp) {
+	h := db.hook.Load()
+	if h == nil {
+		return ctx, nil
+	}
+	return startOp(*h, ctx, kind, query, numArgs)
+}
+
+func startOp(h Hook, ctx context.Context, kind Op, query string, numArgs int) (context.Context, *hookOp) {
+	op := &hookOp{
+		hook:   h,
+		parent: ctx,
+		ev: HookEvent{
+			Op:           kind,
+			Query:        query,
+			NumArgs:      numArgs,
+			Start:        nowFunc(),
+			RowsAffected: -1,
+		},
+	}
+	op.ctx = h.Before(ctx, &op.ev)
+	if op.ctx == nil {
+		op.ctx = ctx
+	}
+	return context.WithValue(op.ctx, hookOpKey{}, op), op
+}
+
+// end ends the operation with the result err.
+func (op *hookOp) end(err error) {
+	if op == nil {
+		return
+	}
+	op.ev.Duration = nowFunc().Sub(op.ev.Start)
+	op.ev.Err = err
+	op.hook.After(op.ctx, &op.ev)
+}
+
+// endExec ends an OpExec operation with its results.
+func (op *hookOp) endExec(res Result, err error) {
+	if op == nil {
+		return
+	}
+	if res != nil {
+		if n, err := res.RowsAffected(); err == nil {
+			op.ev.RowsAffected = n
+		}
+	}
+	op.end(err)
+}
+
+// endBatch ends an OpBatch operation with its results.
+func (op *hookOp) endBatch(results []Result, err error) {
+	if op == nil {
+		return
+	}
+	var total int64
+	for _, res := range results {
+		n, err := res.RowsAffected()
+		if err != nil {
+			total = -1
+			break
+		}
+		total += n
+	}
+	if len(results) > 0 {
+		op.ev.RowsAffected = total
+	}
+	op.end(err)
+}
+
+// endCopy ends an OpCopy operation with the number of rows inserted.
+func (op *hookOp) endCopy(n int64, err error) {
+	if op == nil {
+		return
+	}
+	op.ev.RowsAffected = n
+	op.end(err)
+}
+
+// noteRetry records that the operation is retried on a new connection.
+func (op *hookOp) noteRetry() {
+	if op != nil {
+		op.ev.Retries++
+	}
+}
+
+// startRows starts the OpRows operation following the OpQuery operation
+// that ctx runs in, if it is observed.
+func startRows(ctx context.Context, query string) *hookOp {
+	q := opFromContext(ctx)
+	if q == nil || q.ev.Op != OpQuery {
+		return nil
+	}
+	_, op := startOp(q.hook, q.parent, OpRows, query, 0)
+	return op
+}
+
+// conn is like getConn, but observes the acquisition of the connection.
+func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn, error) {
+	if db.hook.Load() == nil {
+		return db.getConn(ctx, strategy)
+	}
+	parent := opFromContext(ctx)
+	ctx, op := db.startOp(ctx, OpConn, "", 0)
+	dc, err := db.getConn(ctx, strategy)
+	op.end(err)
+	if parent != nil && op != nil {
+		parent.ev.ConnWait += op.ev.Duration
+	}
+	return dc, err
+}
@@ -0,0 +1,235 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type hookCtxKey struct{}
+
+// recordingHook records the operations it observes, and checks that the
+// context returned by Before is passed to After.
+type recordingHook struct {
+	t *testing.T
+
+	mu     sync.Mutex
+	depth  int
+	events []string
+	last   map[Op]HookEvent
+}
+
+func newRecordingHook(t *testing.T) *recordingHook {
+	return &recordingHook{t: t, last: make(map[Op]HookEvent)}
+}
+
+func (h *recordingHook) Before(ctx context.Context, ev *HookEvent) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, fmt.Sprintf("%s%s %q", strings.Repeat(">", h.depth), ev.Op, ev.Query))
+	if ev.Op != OpRows {
+		h.depth++
+	}
+	return context.WithValue(ctx, hookCtxKey{}, ev)
+}
+
+func (h *recordingHook) After(ctx context.Context, ev *HookEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ctx.Value(hookCtxKey{}) != ev {
+		h.t.Errorf("After(%v) called without the context returned by Before", ev.Op)
+	}
+	if ev.Op != OpRows {
+		h.depth--
+	}
+	h.last[ev.Op] = *ev
+}
+
+func (h *recordingHook) reset() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events := h.events
+	h.events = nil
+	return events
+}
+
+func (h *recordingHook) lastEvent(op Op) HookEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last[op]
+}
+
+func TestHook(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	h := newRecordingHook(t)
+	db.SetHook(h)
+	ctx := context.Background()
+
+	checkEvents := func(want ...string) {
+		t.Helper()
+		if got := h.reset(); !slices.Equal(got, want) {
+			t.Errorf("got events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+
+	const insert = "INSERT|people|name=Dave,age=?"
+	if _, err := db.ExecContext(ctx, insert, 4); err != nil {
+		t.Fatal(err)
+	}
+	checkEvents(`Exec "`+insert+`"`, `>Conn ""`)
+	ev := h.lastEvent(OpExec)
+	if ev.NumArgs != 1 || ev.RowsAffected != 1 || ev.Err != nil || ev.Retries != 0 {
+		t.Errorf("Exec event = %+v", ev)
+	}
+	if ev.Duration <= 0 || ev.ConnWait <= 0 || ev.ConnWait > ev.Duration {
+		t.Errorf("Exec event has Duration %v and ConnWait %v", ev.Duration, ev.ConnWait)
+	}
+
+	const query = "SELECT|people|name|"
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for rows.Next() {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	rows.Close()
+	checkEvents(`Query "`+query+`"`, `>Conn ""`, `>Rows "`+query+`"`)
+	if ev := h.lastEvent(OpRows); ev.RowsRead != 2 || ev.Err != nil {
+		t.Errorf("Rows event = %+v", ev)
+	}
+
+	if _, err := db.ExecContext(ctx, "INVALID"); err == nil {
+		t.Fatal("invalid statement succeeded")
+	}
+	h.reset()
+	if ev := h.lastEvent(OpExec); ev.Err == nil || ev.RowsAffected != -1 {
+		t.Errorf("failed Exec event = %+v", ev)
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	var name string
+	if err := stmt.QueryRowContext(ctx).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	checkEvents(`Prepare "`+query+`"`, `>Conn ""`, `Query "`+query+`"`, `>Conn ""`, `>Rows "`+query+`"`)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, insert, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	checkEvents(`Begin ""`, `>Conn ""`, `Exec "`+insert+`"`, `Commit ""`)
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != ErrTxDone {
+		t.Fatalf("Commit after Rollback: %v", err)
+	}
+	checkEvents(`Begin ""`, `>Conn ""`, `Rollback ""`, `Commit ""`)
+	if ev := h.lastEvent(OpCommit); ev.Err != ErrTxDone {
+		t.Errorf("Commit event = %+v", ev)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.QueryRowContext(ctx, query).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	checkEvents(`Conn ""`, `Query "`+query+`"`, `>Rows "`+query+`"`)
+
+	db.SetHook(nil)
+	if _, err := db.ExecContext(ctx, insert, 6); err != nil {
+		t.Fatal(err)
+	}
+	checkEvents()
+}
+
+func TestHookRetries(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	h := newRecordingHook(t)
+	db.SetHook(h)
+
+	// Make the idle connection bad, so that the statement is retried.
+	db.mu.Lock()
+	for _, dc := range db.freeConn {
+		dc.ci.(*fakeConn).stickyBad = true
+	}
+	db.mu.Unlock()
+
+	if _, err := db.Exec("INSERT|people|name=Eve,age=?", 7); err != nil {
+		t.Fatal(err)
+	}
+	if ev := h.lastEvent(OpExec); ev.Retries != 1 || ev.Err != nil {
+		t.Errorf("Exec event = %+v, want 1 retry", ev)
+	}
+}
+
+func TestHookRowsError(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	h := newRecordingHook(t)
+	db.SetHook(h)
+
+	rows, err := db.Query("SELECT|people|name|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fail := errors.New("fail")
+	r := rows.rowsi.(*rowsCursor)
+	r.errPos, r.err = 1, fail
+	for rows.Next() {
+	}
+	if ev := h.lastEvent(OpRows); ev.RowsRead != 1 || ev.Err != fail {
+		t.Errorf("Rows event = %+v, want 1 row and %v", ev, fail)
+	}
+}
+
+// hookConnector is a Connector that is also a Hook.
+type hookConnector struct {
+	*fakeConnector
+	*recordingHook
+}
+
+func TestHookConnector(t *testing.T) {
+	h := newRecordingHook(t)
+	db := OpenDB(hookConnector{&fakeConnector{name: fakeDBName}, h})
+	defer closeDB(t, db)
+	if _, err := db.Exec("WIPE"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := h.lastEvent(OpExec); ev.Query != "WIPE" {
+		t.Errorf("Exec event = %+v, want WIPE", ev)
+	}
+}
@@ -0,0 +1,835 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memdb
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A colType is the type of a column.
+type colType int
+
+const (
+	typeInteger colType = iota + 1
+	typeReal
+	typeText
+	typeBlob
+	typeBoolean
+	typeTimestamp
+)
+
+// typeNames maps the names of the supported SQL types to column types.
+var typeNames = map[string]colType{
+	"INTEGER": typeInteger, "INT": typeInteger, "BIGINT": typeInteger,
+	"SMALLINT": typeInteger, "TINYINT": typeInteger,
+	"REAL": typeReal, "FLOAT": typeReal, "DOUBLE": typeReal,
+	"NUMERIC": typeReal, "DECIMAL": typeReal,
+	"TEXT": typeText, "VARCHAR": typeText, "CHAR": typeText, "STRING": typeText,
+	"BLOB": typeBlob, "BYTEA": typeBlob, "BINARY": typeBlob, "VARBINARY": typeBlob,
+	"BOOLEAN": typeBoolean, "BOOL": typeBoolean,
+	"TIMESTAMP": typeTimestamp, "DATETIME": typeTimestamp, "DATE": typeTimestamp,
+}
+
+func (t colType) String() string {
+	switch t {
+	case typeInteger:
+		return "INTEGER"
+	case typeReal:
+		return "REAL"
+	case typeText:
+		return "TEXT"
+	case typeBlob:
+		return "BLOB"
+	case typeBoolean:
+		return "BOOLEAN"
+	case typeTimestamp:
+		return "TIMESTAMP"
+	}
+	return ""
+}
+
+func (t colType) scanType() reflect.Type {
+	switch t {
+	case typeInteger:
+		return reflect.TypeFor[int64]()
+	case typeReal:
+		return reflect.TypeFor[float64]()
+	case typeText:
+		return reflect.TypeFor[string]()
+	case typeBlob:
+		return reflect.TypeFor[[]byte]()
+	case typeBoolean:
+		return reflect.TypeFor[bool]()
+	case typeTimestamp:
+		return reflect.TypeFor[time.Time]()
+	}
+	return reflect.TypeFor[any]()
+}
+
+// timeLayouts are the layouts of the strings that can be converted to
+// timestamps.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// convert converts v to a value that can be stored in a column of type t.
+func (t colType) convert(v driver.Value) (driver.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		switch t {
+		case typeInteger:
+			return v, nil
+		case typeReal:
+			return float64(v), nil
+		case typeBoolean:
+			if v == 0 || v == 1 {
+				return v == 1, nil
+			}
+		}
+	case float64:
+		switch t {
+		case typeInteger:
+			if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+				return int64(v), nil
+			}
+		case typeReal:
+			return v, nil
+		}
+	case bool:
+		switch t {
+		case typeBoolean:
+			return v, nil
+		case typeInteger:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+	case string:
+		switch t {
+		case typeText:
+			return v, nil
+		case typeBlob:
+			return []byte(v), nil
+		case typeTimestamp:
+			if tm, ok := parseTime(v); ok {
+				return tm, nil
+			}
+		}
+	case []byte:
+		switch t {
+		case typeText:
+			return string(v), nil
+		case typeBlob:
+			return bytes.Clone(v), nil
+		}
+	case time.Time:
+		if t == typeTimestamp {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("memdb: cannot store %s in a %v column", describe(v), t)
+}
+
+// describe describes v for error messages.
+func describe(v driver.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return strconv.Quote(v)
+	case []byte:
+		return "blob"
+	case time.Time:
+		return "timestamp " + v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// A column is a column of a table.
+type column struct {
+	name       string
+	typ        colType
+	primaryKey bool
+	notNull    bool
+	def        expr // default value, or nil
+}
+
+func columnIndex(cols []column, name string) int {
+	for i, col := range cols {
+		if col.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// An env is the environment in which expressions are evaluated: a row
+// with its columns, the arguments of the query, and the results of
+// aggregate functions.
+type env struct {
+	cols []envCol
+	row  []driver.Value
+	args []driver.NamedValue
+	aggs map[*call]driver.Value
+}
+
+type envCol struct {
+	table string // alias of the table
+	name  string
+	typ   colType
+}
+
+// lookup returns the index of the column c in e.
+func (e *env) lookup(c *colRef) (int, error) {
+	found := -1
+	for i, col := range e.cols {
+		if col.name == c.name && (c.table == "" || c.table == col.table) {
+			if found >= 0 {
+				return 0, fmt.Errorf("memdb: ambiguous column name %s", c.name)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		if c.table != "" {
+			return 0, fmt.Errorf("memdb: no such column %s.%s", c.table, c.name)
+		}
+		return 0, fmt.Errorf("memdb: no such column %s", c.name)
+	}
+	return found, nil
+}
+
+func (e *env) arg(p *param) (driver.Value, error) {
+	for _, a := range e.args {
+		if p.name == "" && a.Ordinal == p.ordinal || p.name != "" && a.Name == p.name {
+			return a.Value, nil
+		}
+	}
+	if p.name != "" {
+		return nil, fmt.Errorf("memdb: missing argument for parameter %s", p.name)
+	}
+	return nil, fmt.Errorf("memdb: missing argument for parameter %d", p.ordinal)
+}
+
+// eval evaluates x. Unknown boolean values are represented by nil.
+func (e *env) eval(x expr) (driver.Value, error) {
+	switch x := x.(type) {
+	case *literal:
+		return x.v, nil
+	case *param:
+		return e.arg(x)
+	case *colRef:
+		i, err := e.lookup(x)
+		if err != nil {
+			return nil, err
+		}
+		if e.row == nil {
+			return nil, nil
+		}
+		return e.row[i], nil
+	case *unary:
+		v, err := e.eval(x.x)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		if x.op == "NOT" {
+			b, err := truth(v)
+			if err != nil {
+				return nil, err
+			}
+			return !b, nil
+		}
+		return arith("-", int64(0), v)
+	case *binary:
+		switch x.op {
+		case "AND", "OR":
+			return e.logical(x)
+		}
+		a, err := e.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		b, err := e.eval(x.y)
+		if err != nil || a == nil || b == nil {
+			return nil, err
+		}
+		switch x.op {
+		case "=", "!=", "<", "<=", ">", ">=":
+			c, err := compare(a, b)
+			if err != nil {
+				return nil, err
+			}
+			switch x.op {
+			case "=":
+				return c == 0, nil
+			case "!=":
+				return c != 0, nil
+			case "<":
+				return c < 0, nil
+			case "<=":
+				return c <= 0, nil
+			case ">":
+				return c > 0, nil
+			default:
+				return c >= 0, nil
+			}
+		case "||":
+			return toString(a) + toString(b), nil
+		case "LIKE":
+			s, ok1 := a.(string)
+			pattern, ok2 := b.(string)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("memdb: LIKE requires text operands")
+			}
+			return like(s, pattern), nil
+		}
+		return arith(x.op, a, b)
+	case *isNull:
+		v, err := e.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		return (v == nil) != x.not, nil
+	case *inList:
+		v, err := e.eval(x.x)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		var result driver.Value = false
+		for _, item := range x.list {
+			w, err := e.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			if w == nil {
+				result = nil
+				continue
+			}
+			c, err := compare(v, w)
+			if err != nil {
+				return nil, err
+			}
+			if c == 0 {
+				result = true
+				break
+			}
+		}
+		if result != nil && x.not {
+			return !result.(bool), nil
+		}
+		return result, nil
+	case *call:
+		if isAggregate(x.name) {
+			v, ok := e.aggs[x]
+			if !ok {
+				return nil, fmt.Errorf("memdb: misuse of aggregate function %s", x.name)
+			}
+			return v, nil
+		}
+		args := make([]driver.Value, len(x.args))
+		for i, arg := range x.args {
+			var err error
+			if args[i], err = e.eval(arg); err != nil {
+				return nil, err
+			}
+		}
+		return callFunc(x.name, args)
+	case *caseExpr:
+		var operand driver.Value
+		if x.operand != nil {
+			var err error
+			if operand, err = e.eval(x.operand); err != nil {
+				return nil, err
+			}
+		}
+		for _, w := range x.whens {
+			cond, err := e.eval(w.cond)
+			if err != nil {
+				return nil, err
+			}
+			var match bool
+			if x.operand != nil {
+				if operand != nil && cond != nil {
+					c, err := compare(operand, cond)
+					if err != nil {
+						return nil, err
+					}
+					match = c == 0
+				}
+			} else if cond != nil {
+				if match, err = truth(cond); err != nil {
+					return nil, err
+				}
+			}
+			if match {
+				return e.eval(w.result)
+			}
+		}
+		if x.els != nil {
+			return e.eval(x.els)
+		}
+		return nil, nil
+	}
+	panic(fmt.Sprintf("memdb: unexpected expression %T", x))
+}
+
+// logical evaluates AND and OR with three-valued logic.
+func (e *env) logical(x *binary) (driver.Value, error) {
+	short := x.op == "OR" // the value that decides the result
+	a, err := e.cond(x.x)
+	if err != nil {
+		return nil, err
+	}
+	if a != nil && a.(bool) == short {
+		return short, nil
+	}
+	b, err := e.cond(x.y)
+	if err != nil {
+		return nil, err
+	}
+	if b != nil && b.(bool) == short {
+		return short, nil
+	}
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	return !short, nil
+}
+
+// cond evaluates x as a boolean, which may be unknown.
+func (e *env) cond(x expr) (driver.Value, error) {
+	v, err := e.eval(x)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	b, err := truth(v)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// filter reports whether x is true, as required by WHERE clauses.
+func (e *env) filter(x expr) (bool, error) {
+	if x == nil {
+		return true, nil
+	}
+	v, err := e.cond(x)
+	if err != nil || v == nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
+
+func truth(v driver.Value) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case float64:
+		return v != 0, nil
+	}
+	return false, fmt.Errorf("memdb: %s is not a boolean", describe(v))
+}
+
+// compare compares two non-NULL values.
+func compare(a, b driver.Value) (int, error) {
+	if x, ok := a.(bool); ok {
+		a = boolToInt(x)
+	}
+	if x, ok := b.(bool); ok {
+		b = boolToInt(x)
+	}
+	if x, ok := a.([]byte); ok {
+		a = string(x)
+	}
+	if x, ok := b.([]byte); ok {
+		b = string(x)
+	}
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp3(x, y), nil
+		case float64:
+			return cmp3(float64(x), y), nil
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return cmp3(x, float64(y)), nil
+		case float64:
+			return cmp3(x, y), nil
+		}
+	case string:
+		switch y := b.(type) {
+		case string:
+			return strings.Compare(x, y), nil
+		case time.Time:
+			if t, ok := parseTime(x); ok {
+				return t.Compare(y), nil
+			}
+		}
+	case time.Time:
+		switch y := b.(type) {
+		case time.Time:
+			return x.Compare(y), nil
+		case string:
+			if t, ok := parseTime(y); ok {
+				return x.Compare(t), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("memdb: cannot compare %s and %s", describe(a), describe(b))
+}
+
+func cmp3[T int64 | float64](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var errOverflow = errors.New("memdb: integer overflow")
+
+// arith applies an arithmetic operator to two non-NULL values.
+func arith(op string, a, b driver.Value) (driver.Value, error) {
+	if x, ok := a.(bool); ok {
+		a = boolToInt(x)
+	}
+	if x, ok := b.(bool); ok {
+		b = boolToInt(x)
+	}
+	x, xok := a.(int64)
+	y, yok := b.(int64)
+	if xok && yok {
+		switch op {
+		case "+":
+			z := x + y
+			if (y > 0 && z < x) || (y < 0 && z > x) {
+				return nil, errOverflow
+			}
+			return z, nil
+		case "-":
+			z := x - y
+			if (y > 0 && z > x) || (y < 0 && z < x) {
+				return nil, errOverflow
+			}
+			return z, nil
+		case "*":
+			z := x * y
+			if x != 0 && (z/x != y || (x == -1 && y == math.MinInt64)) {
+				return nil, errOverflow
+			}
+			return z, nil
+		case "/", "%":
+			if y == 0 {
+				return nil, fmt.Errorf("memdb: division by zero")
+			}
+			if op == "/" {
+				if x == math.MinInt64 && y == -1 {
+					return nil, errOverflow
+				}
+				return x / y, nil
+			}
+			return x % y, nil
+		}
+	}
+	f, fok := toFloat(a)
+	g, gok := toFloat(b)
+	if !fok || !gok {
+		return nil, fmt.Errorf("memdb: invalid operands %s and %s for %s", describe(a), describe(b), op)
+	}
+	switch op {
+	case "+":
+		return f + g, nil
+	case "-":
+		return f - g, nil
+	case "*":
+		return f * g, nil
+	case "/":
+		if g == 0 {
+			return nil, fmt.Errorf("memdb: division by zero")
+		}
+		return f / g, nil
+	case "%":
+		if g == 0 {
+			return nil, fmt.Errorf("memdb: division by zero")
+		}
+		return math.Mod(f, g), nil
+	}
+	panic("memdb: unknown operator " + op)
+}
+
+func toFloat(v driver.Value) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func toString(v driver.Value) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// like reports whether s matches pattern, in which % matches any
+// sequence of characters and _ matches any single character.
+func like(s, pattern string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '%':
+			for len(pattern) > 0 && pattern[0] == '%' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if like(s[i:], pattern) {
+					return true
+				}
+				if i < len(s) {
+					_, n := utf8.DecodeRuneInString(s[i:])
+					i += n - 1
+				}
+			}
+			return false
+		case '_':
+			if s == "" {
+				return false
+			}
+			_, n := utf8.DecodeRuneInString(s)
+			s, pattern = s[n:], pattern[1:]
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+			s, pattern = s[1:], pattern[1:]
+		}
+	}
+	return s == ""
+}
+
+// callFunc calls the scalar function name.
+func callFunc(name string, args []driver.Value) (driver.Value, error) {
+	nargs := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("memdb: wrong number of arguments to function %s", name)
+		}
+		return nil
+	}
+	switch name {
+	case "COALESCE", "IFNULL":
+		if len(args) == 0 || name == "IFNULL" && len(args) != 2 {
+			return nil, fmt.Errorf("memdb: wrong number of arguments to function %s", name)
+		}
+		for _, v := range args {
+			if v != nil {
+				return v, nil
+			}
+		}
+		return nil, nil
+	case "NULLIF":
+		if err := nargs(2); err != nil {
+			return nil, err
+		}
+		if args[0] == nil || args[1] == nil {
+			return args[0], nil
+		}
+		if c, err := compare(args[0], args[1]); err != nil || c == 0 {
+			return nil, err
+		}
+		return args[0], nil
+	case "LOWER", "UPPER", "LENGTH", "ABS":
+		if err := nargs(1); err != nil {
+			return nil, err
+		}
+		v := args[0]
+		if v == nil {
+			return nil, nil
+		}
+		switch name {
+		case "LOWER":
+			return strings.ToLower(toString(v)), nil
+		case "UPPER":
+			return strings.ToUpper(toString(v)), nil
+		case "LENGTH":
+			if b, ok := v.([]byte); ok {
+				return int64(len(b)), nil
+			}
+			return int64(utf8.RuneCountInString(toString(v))), nil
+		case "ABS":
+			switch v := v.(type) {
+			case int64:
+				if v < 0 {
+					return -v, nil
+				}
+				return v, nil
+			case float64:
+				return math.Abs(v), nil
+			}
+			return nil, fmt.Errorf("memdb: ABS of %s", describe(v))
+		}
+	}
+	return nil, fmt.Errorf("memdb: unknown function %s", name)
+}
+
+func isAggregate(name string) bool {
+	switch name {
+	case "COUNT", "SUM", "AVG", "MIN", "MAX":
+		return true
+	}
+	return false
+}
+
+// An aggregate accumulates the values of an aggregate function call.
+type aggregate struct {
+	call  *call
+	count int64
+	sum   driver.Value // int64 or float64
+	val   driver.Value // for MIN and MAX
+	seen  map[string]bool
+}
+
+func (a *aggregate) add(e *env) error {
+	if a.call.star {
+		a.count++
+		return nil
+	}
+	if len(a.call.args) != 1 {
+		return fmt.Errorf("memdb: wrong number of arguments to function %s", a.call.name)
+	}
+	v, err := e.eval(a.call.args[0])
+	if err != nil || v == nil {
+		return err
+	}
+	if a.call.distinct {
+		k := key([]driver.Value{v})
+		if a.seen[k] {
+			return nil
+		}
+		if a.seen == nil {
+			a.seen = make(map[string]bool)
+		}
+		a.seen[k] = true
+	}
+	a.count++
+	switch a.call.name {
+	case "SUM", "AVG":
+		if a.sum == nil {
+			a.sum = int64(0)
+		}
+		a.sum, err = arith("+", a.sum, v)
+		return err
+	case "MIN", "MAX":
+		if a.val == nil {
+			a.val = v
+			return nil
+		}
+		c, err := compare(v, a.val)
+		if err != nil {
+			return err
+		}
+		if c < 0 && a.call.name == "MIN" || c > 0 && a.call.name == "MAX" {
+			a.val = v
+		}
+	}
+	return nil
+}
+
+func (a *aggregate) result() driver.Value {
+	switch a.call.name {
+	case "COUNT":
+		return a.count
+	case "SUM":
+		return a.sum
+	case "AVG":
+		if a.count == 0 {
+			return nil
+		}
+		f, _ := toFloat(a.sum)
+		return f / float64(a.count)
+	}
+	return a.val
+}
+
+// key returns a string that identifies the values vals, such that values
+// that compare equal have the same key.
+func key(vals []driver.Value) string {
+	var b strings.Builder
+	for _, v := range vals {
+		switch v := v.(type) {
+		case nil:
+			b.WriteString("n")
+		case bool:
+			fmt.Fprintf(&b, "i%d", boolToInt(v))
+		case int64:
+			fmt.Fprintf(&b, "i%d", v)
+		case float64:
+			if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+				fmt.Fprintf(&b, "i%d", int64(v))
+			} else {
+				fmt.Fprintf(&b, "f%x", math.Float64bits(v))
+			}
+		case string:
+			fmt.Fprintf(&b, "s%d:%s", len(v), v)
+		case []byte:
+			fmt.Fprintf(&b, "s%d:%s", len(v), v)
+		case time.Time:
+			fmt.Fprintf(&b, "t%s", v.UTC().Format(time.RFC3339Nano))
+		}
+		b.WriteByte(',')
+	}
+	return b.String()
+}
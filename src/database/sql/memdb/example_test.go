@@ -0,0 +1,49 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memdb_test
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	_ "database/sql/memdb"
+)
+
+func Example() {
+	db, err := sql.Open("memdb", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE gophers (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INT);
+		INSERT INTO gophers (name, age) VALUES ('Gordon', 12), ('Renee', 9), ('Rob', 15);
+	`)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT name, age FROM gophers WHERE age > :min ORDER BY age DESC", sql.Named("min", 10))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var age int
+		if err := rows.Scan(&name, &age); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(name, age)
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// Rob 15
+	// Gordon 12
+}
@@ -0,0 +1,691 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memdb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// A state is a version of the contents of a database. Committed states
+// are immutable: transactions that write modify a copy of the latest
+// committed state, which replaces it when they commit.
+type state struct {
+	version uint64
+	tables  map[string]*table
+}
+
+// clone returns a copy of s that can be modified. The tables themselves
+// are not copied: they are replaced when modified.
+func (s *state) clone() *state {
+	return &state{version: s.version, tables: maps.Clone(s.tables)}
+}
+
+func (s *state) table(name string) (*table, error) {
+	t, ok := s.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("memdb: no such table %s", name)
+	}
+	return t, nil
+}
+
+// A table is a version of a table. It is immutable once it is part of a
+// committed state.
+type table struct {
+	name    string
+	columns []column
+	rows    [][]driver.Value
+
+	// tip is shared by the versions of the table that share the backing
+	// array of rows, and holds the number of rows used in it by the most
+	// recent version. Rows may be appended in place to the version with
+	// that many rows.
+	tip *int
+
+	nextID int64 // the next value of an INTEGER PRIMARY KEY
+}
+
+// pk returns the index of the primary key column of t, or -1.
+func (t *table) pk() int {
+	for i, col := range t.columns {
+		if col.primaryKey {
+			return i
+		}
+	}
+	return -1
+}
+
+// withRows returns a new version of t with the given rows.
+func (t *table) withRows(rows [][]driver.Value) *table {
+	t2 := *t
+	t2.rows = rows
+	n := len(rows)
+	t2.tip = &n
+	return &t2
+}
+
+// appendRows returns a new version of t with rows appended to its rows.
+func (t *table) appendRows(rows [][]driver.Value) *table {
+	t2 := *t
+	if len(t.rows) == *t.tip {
+		t2.rows = append(t.rows, rows...)
+		*t2.tip = len(t2.rows)
+	} else {
+		t2.rows = append(slices.Clip(t.rows), rows...)
+		n := len(t2.rows)
+		t2.tip = &n
+	}
+	return &t2
+}
+
+// A resultSet is the result of a query.
+type resultSet struct {
+	columns []string
+	types   []colType
+	rows    [][]driver.Value
+}
+
+type execResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// execWrite executes the statement s, which modifies the database, on the
+// working state st.
+func execWrite(st *state, s statement, args []driver.NamedValue) (execResult, error) {
+	switch s := s.(type) {
+	case *createTableStmt:
+		if _, ok := st.tables[s.table]; ok {
+			if s.ifNotExists {
+				return execResult{}, nil
+			}
+			return execResult{}, fmt.Errorf("memdb: table %s already exists", s.table)
+		}
+		zero := 0
+		st.tables[s.table] = &table{name: s.table, columns: s.columns, tip: &zero, nextID: 1}
+		return execResult{}, nil
+	case *dropTableStmt:
+		if _, ok := st.tables[s.table]; !ok {
+			if s.ifExists {
+				return execResult{}, nil
+			}
+			return execResult{}, fmt.Errorf("memdb: no such table %s", s.table)
+		}
+		delete(st.tables, s.table)
+		return execResult{}, nil
+	case *insertStmt:
+		return execInsert(st, s, args)
+	case *updateStmt:
+		return execUpdate(st, s, args)
+	case *deleteStmt:
+		return execDelete(st, s, args)
+	}
+	panic(fmt.Sprintf("memdb: unexpected statement %T", s))
+}
+
+func execInsert(st *state, s *insertStmt, args []driver.NamedValue) (execResult, error) {
+	t, err := st.table(s.table)
+	if err != nil {
+		return execResult{}, err
+	}
+	indexes := make([]int, 0, len(t.columns))
+	if s.columns == nil {
+		for i := range t.columns {
+			indexes = append(indexes, i)
+		}
+	} else {
+		for _, name := range s.columns {
+			i := columnIndex(t.columns, name)
+			if i < 0 {
+				return execResult{}, fmt.Errorf("memdb: table %s has no column %s", t.name, name)
+			}
+			if slices.Contains(indexes, i) {
+				return execResult{}, fmt.Errorf("memdb: column %s specified more than once", name)
+			}
+			indexes = append(indexes, i)
+		}
+	}
+
+	e := &env{args: args}
+	pk := t.pk()
+	nextID := t.nextID
+	var res execResult
+	rows := make([][]driver.Value, 0, len(s.rows))
+	for _, exprs := range s.rows {
+		if len(exprs) != len(indexes) {
+			return execResult{}, fmt.Errorf("memdb: %d values for %d columns", len(exprs), len(indexes))
+		}
+		row := make([]driver.Value, len(t.columns))
+		for i, col := range t.columns {
+			if col.def != nil {
+				if row[i], err = storeValue(e, col, col.def); err != nil {
+					return execResult{}, err
+				}
+			}
+		}
+		for j, i := range indexes {
+			if row[i], err = storeValue(e, t.columns[i], exprs[j]); err != nil {
+				return execResult{}, err
+			}
+		}
+		if pk >= 0 && t.columns[pk].typ == typeInteger {
+			if row[pk] == nil {
+				row[pk] = nextID
+			}
+			id := row[pk].(int64)
+			nextID = max(nextID, id+1)
+			res.lastInsertID = id
+		}
+		if err := checkNotNull(t, row); err != nil {
+			return execResult{}, err
+		}
+		rows = append(rows, row)
+	}
+	if pk >= 0 {
+		if err := checkUnique(t, pk, t.rows, rows); err != nil {
+			return execResult{}, err
+		}
+	}
+	t = t.appendRows(rows)
+	t.nextID = nextID
+	st.tables[t.name] = t
+	res.rowsAffected = int64(len(rows))
+	return res, nil
+}
+
+// storeValue evaluates x and converts it for storage in col.
+func storeValue(e *env, col column, x expr) (driver.Value, error) {
+	v, err := e.eval(x)
+	if err != nil {
+		return nil, err
+	}
+	v, err = col.typ.convert(v)
+	if err != nil {
+		return nil, fmt.Errorf("%v (column %s)", err, col.name)
+	}
+	return v, nil
+}
+
+func checkNotNull(t *table, row []driver.Value) error {
+	for i, col := range t.columns {
+		if row[i] == nil && (col.notNull || col.primaryKey) {
+			return fmt.Errorf("memdb: NOT NULL constraint failed: %s.%s", t.name, col.name)
+		}
+	}
+	return nil
+}
+
+// checkUnique checks that the primary keys of rows are unique, given the
+// rows of old that are kept.
+func checkUnique(t *table, pk int, old, rows [][]driver.Value) error {
+	seen := make(map[string]bool, len(old)+len(rows))
+	for _, row := range old {
+		seen[key(row[pk:pk+1])] = true
+	}
+	for _, row := range rows {
+		k := key(row[pk : pk+1])
+		if seen[k] {
+			return fmt.Errorf("memdb: UNIQUE constraint failed: %s.%s = %s", t.name, t.columns[pk].name, describe(row[pk]))
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+func tableEnv(t *table, alias string, args []driver.NamedValue) *env {
+	e := &env{args: args}
+	for _, col := range t.columns {
+		e.cols = append(e.cols, envCol{alias, col.name, col.typ})
+	}
+	return e
+}
+
+func execUpdate(st *state, s *updateStmt, args []driver.NamedValue) (execResult, error) {
+	t, err := st.table(s.table)
+	if err != nil {
+		return execResult{}, err
+	}
+	indexes := make([]int, len(s.sets))
+	for j, set := range s.sets {
+		if indexes[j] = columnIndex(t.columns, set.column); indexes[j] < 0 {
+			return execResult{}, fmt.Errorf("memdb: table %s has no column %s", t.name, set.column)
+		}
+	}
+	e := tableEnv(t, t.name, args)
+	var res execResult
+	var kept, updated [][]driver.Value
+	rows := make([][]driver.Value, 0, len(t.rows))
+	for _, row := range t.rows {
+		e.row = row
+		ok, err := e.filter(s.where)
+		if err != nil {
+			return execResult{}, err
+		}
+		if !ok {
+			kept = append(kept, row)
+			rows = append(rows, row)
+			continue
+		}
+		row = slices.Clone(row)
+		for j, set := range s.sets {
+			// Values are computed from the old row.
+			if row[indexes[j]], err = storeValue(e, t.columns[indexes[j]], set.value); err != nil {
+				return execResult{}, err
+			}
+		}
+		if err := checkNotNull(t, row); err != nil {
+			return execResult{}, err
+		}
+		updated = append(updated, row)
+		rows = append(rows, row)
+		res.rowsAffected++
+	}
+	if pk := t.pk(); pk >= 0 && slices.Contains(indexes, pk) {
+		if err := checkUnique(t, pk, kept, updated); err != nil {
+			return execResult{}, err
+		}
+	}
+	if res.rowsAffected > 0 {
+		st.tables[t.name] = t.withRows(rows)
+	}
+	return res, nil
+}
+
+func execDelete(st *state, s *deleteStmt, args []driver.NamedValue) (execResult, error) {
+	t, err := st.table(s.table)
+	if err != nil {
+		return execResult{}, err
+	}
+	e := tableEnv(t, t.name, args)
+	rows := make([][]driver.Value, 0, len(t.rows))
+	for _, row := range t.rows {
+		e.row = row
+		ok, err := e.filter(s.where)
+		if err != nil {
+			return execResult{}, err
+		}
+		if !ok {
+			rows = append(rows, row)
+		}
+	}
+	n := len(t.rows) - len(rows)
+	if n > 0 {
+		st.tables[t.name] = t.withRows(rows)
+	}
+	return execResult{rowsAffected: int64(n)}, nil
+}
+
+// execSelect executes a query on the state st.
+func execSelect(st *state, s *selectStmt, args []driver.NamedValue) (*resultSet, error) {
+	// Build the rows of the FROM clause.
+	e := &env{args: args}
+	rows := [][]driver.Value{{}}
+	for _, from := range s.from {
+		t, err := st.table(from.table)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range e.cols {
+			if col.table == from.alias {
+				return nil, fmt.Errorf("memdb: duplicate table name %s in FROM clause", from.alias)
+			}
+		}
+		e.cols = append(e.cols, tableEnv(t, from.alias, nil).cols...)
+		var joined [][]driver.Value
+		for _, left := range rows {
+			matched := false
+			for _, right := range t.rows {
+				row := append(slices.Clip(left), right...)
+				e.row = row
+				ok, err := e.filter(from.on)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					joined = append(joined, row)
+					matched = true
+				}
+			}
+			if !matched && from.join == "LEFT" {
+				joined = append(joined, append(slices.Clip(left), make([]driver.Value, len(t.columns))...))
+			}
+		}
+		rows = joined
+	}
+
+	if s.where != nil {
+		var filtered [][]driver.Value
+		for _, row := range rows {
+			e.row = row
+			ok, err := e.filter(s.where)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				filtered = append(filtered, row)
+			}
+		}
+		rows = filtered
+	}
+
+	// Expand the select items.
+	type output struct {
+		expr  expr
+		alias string
+	}
+	var outputs []output
+	rs := new(resultSet)
+	for _, item := range s.items {
+		if item.star == "" {
+			outputs = append(outputs, output{item.expr, item.alias})
+			name, typ := item.alias, colType(0)
+			if c, ok := item.expr.(*colRef); ok {
+				if i, err := e.lookup(c); err == nil {
+					typ = e.cols[i].typ
+				}
+			}
+			if name == "" {
+				name = exprString(item.expr)
+			}
+			rs.columns = append(rs.columns, name)
+			rs.types = append(rs.types, typ)
+			continue
+		}
+		n := len(outputs)
+		for _, col := range e.cols {
+			if item.star == "*" || item.star == col.table {
+				outputs = append(outputs, output{&colRef{col.table, col.name}, ""})
+				rs.columns = append(rs.columns, col.name)
+				rs.types = append(rs.types, col.typ)
+			}
+		}
+		if n == len(outputs) && item.star != "*" {
+			return nil, fmt.Errorf("memdb: no such table %s", item.star)
+		}
+	}
+
+	// Group the rows if needed. Each group is evaluated in an env whose
+	// row is the first row of the group.
+	var calls []*call
+	for _, o := range outputs {
+		calls = appendAggregates(calls, o.expr)
+	}
+	calls = appendAggregates(calls, s.having)
+	for _, o := range s.orderBy {
+		calls = appendAggregates(calls, o.expr)
+	}
+	type group struct {
+		row  []driver.Value
+		aggs []*aggregate
+	}
+	var groups []*group
+	grouped := len(s.groupBy) > 0 || len(calls) > 0
+	if grouped {
+		byKey := make(map[string]*group)
+		if len(s.groupBy) == 0 {
+			// A single group, even if there are no rows.
+			groups = append(groups, &group{})
+			byKey[""] = groups[0]
+		}
+		for _, row := range rows {
+			e.row = row
+			vals := make([]driver.Value, len(s.groupBy))
+			for i, x := range s.groupBy {
+				var err error
+				if vals[i], err = e.eval(x); err != nil {
+					return nil, err
+				}
+			}
+			k := key(vals)
+			g := byKey[k]
+			if g == nil {
+				g = new(group)
+				groups = append(groups, g)
+				byKey[k] = g
+			}
+			if g.row == nil {
+				g.row = row
+			}
+			if g.aggs == nil {
+				for _, c := range calls {
+					g.aggs = append(g.aggs, &aggregate{call: c})
+				}
+			}
+			for _, a := range g.aggs {
+				if err := a.add(e); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(s.groupBy) == 0 && groups[0].aggs == nil {
+			for _, c := range calls {
+				groups[0].aggs = append(groups[0].aggs, &aggregate{call: c})
+			}
+		}
+	} else {
+		for _, row := range rows {
+			groups = append(groups, &group{row: row})
+		}
+	}
+
+	// Compute the output rows and their sort keys.
+	type outRow struct {
+		vals []driver.Value
+		keys []driver.Value
+	}
+	var out []outRow
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		e.row = g.row
+		e.aggs = nil
+		if grouped {
+			e.aggs = make(map[*call]driver.Value)
+			for _, a := range g.aggs {
+				e.aggs[a.call] = a.result()
+			}
+			ok, err := e.filter(s.having)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		vals := make([]driver.Value, len(outputs))
+		for i, o := range outputs {
+			var err error
+			if vals[i], err = e.eval(o.expr); err != nil {
+				return nil, err
+			}
+		}
+		if s.distinct {
+			k := key(vals)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		keys := make([]driver.Value, len(s.orderBy))
+		for i, o := range s.orderBy {
+			j := outputIndex(o.expr, len(outputs), func(name string) int {
+				return slices.IndexFunc(outputs, func(o output) bool { return o.alias == name })
+			})
+			if j >= 0 {
+				keys[i] = vals[j]
+				continue
+			}
+			if _, ok := o.expr.(*literal); ok {
+				return nil, fmt.Errorf("memdb: ORDER BY term out of range")
+			}
+			var err error
+			if keys[i], err = e.eval(o.expr); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, outRow{vals, keys})
+	}
+
+	if len(s.orderBy) > 0 {
+		var sortErr error
+		slices.SortStableFunc(out, func(a, b outRow) int {
+			for i, o := range s.orderBy {
+				x, y := a.keys[i], b.keys[i]
+				var c int
+				switch {
+				case x == nil && y == nil:
+				case x == nil:
+					c = -1
+				case y == nil:
+					c = 1
+				default:
+					var err error
+					c, err = compare(x, y)
+					if err != nil && sortErr == nil {
+						sortErr = err
+					}
+				}
+				if o.desc {
+					c = -c
+				}
+				if c != 0 {
+					return c
+				}
+			}
+			return 0
+		})
+		if sortErr != nil {
+			return nil, sortErr
+		}
+	}
+
+	e = &env{args: args}
+	if s.offset != nil {
+		n, err := evalCount(e, s.offset, "OFFSET")
+		if err != nil {
+			return nil, err
+		}
+		out = out[min(n, len(out)):]
+	}
+	if s.limit != nil {
+		n, err := evalCount(e, s.limit, "LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		out = out[:min(n, len(out))]
+	}
+	for _, r := range out {
+		rs.rows = append(rs.rows, r.vals)
+	}
+	return rs, nil
+}
+
+// outputIndex returns the index of the output column that an ORDER BY
+// term refers to, by position or by alias, or -1.
+func outputIndex(x expr, n int, byAlias func(string) int) int {
+	switch x := x.(type) {
+	case *literal:
+		if i, ok := x.v.(int64); ok && 1 <= i && i <= int64(n) {
+			return int(i - 1)
+		}
+	case *colRef:
+		if x.table == "" {
+			return byAlias(x.name)
+		}
+	}
+	return -1
+}
+
+func evalCount(e *env, x expr, what string) (int, error) {
+	v, err := e.eval(x)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("memdb: %s must be a non-negative integer, not %s", what, describe(v))
+	}
+	return int(min(n, int64(^uint(0)>>1))), nil
+}
+
+// appendAggregates appends the aggregate function calls in x to calls.
+func appendAggregates(calls []*call, x expr) []*call {
+	switch x := x.(type) {
+	case *unary:
+		return appendAggregates(calls, x.x)
+	case *binary:
+		return appendAggregates(appendAggregates(calls, x.x), x.y)
+	case *isNull:
+		return appendAggregates(calls, x.x)
+	case *inList:
+		calls = appendAggregates(calls, x.x)
+		for _, y := range x.list {
+			calls = appendAggregates(calls, y)
+		}
+	case *call:
+		if isAggregate(x.name) {
+			return append(calls, x)
+		}
+		for _, y := range x.args {
+			calls = appendAggregates(calls, y)
+		}
+	case *caseExpr:
+		calls = appendAggregates(calls, x.operand)
+		for _, w := range x.whens {
+			calls = appendAggregates(appendAggregates(calls, w.cond), w.result)
+		}
+		calls = appendAggregates(calls, x.els)
+	}
+	return calls
+}
+
+// exprString returns the name of an output column computed by x.
+func exprString(x expr) string {
+	switch x := x.(type) {
+	case *literal:
+		if s, ok := x.v.(string); ok {
+			return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+		}
+		if x.v == nil {
+			return "NULL"
+		}
+		return toString(x.v)
+	case *param:
+		if x.name != "" {
+			return ":" + x.name
+		}
+		return fmt.Sprintf("$%d", x.ordinal)
+	case *colRef:
+		return x.name
+	case *unary:
+		if x.op == "NOT" {
+			return "NOT " + exprString(x.x)
+		}
+		return x.op + exprString(x.x)
+	case *binary:
+		return exprString(x.x) + " " + x.op + " " + exprString(x.y)
+	case *isNull:
+		if x.not {
+			return exprString(x.x) + " IS NOT NULL"
+		}
+		return exprString(x.x) + " IS NULL"
+	case *call:
+		if x.star {
+			return strings.ToLower(x.name) + "(*)"
+		}
+		args := make([]string, len(x.args))
+		for i, y := range x.args {
+			args[i] = exprString(y)
+		}
+		prefix := ""
+		if x.distinct {
+			prefix = "DISTINCT "
+		}
+		return strings.ToLower(x.name) + "(" + prefix + strings.Join(args, ", ") + ")"
+	}
+	return "?column?"
+}
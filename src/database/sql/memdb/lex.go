@@ -0,0 +1,207 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memdb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokIdent            // identifier or keyword
+	tokQuoted           // "quoted identifier"
+	tokNumber           // 12, 1.5, 1e3
+	tokString           // 'string'
+	tokBlob             // X'0a1b'
+	tokParam            // ?, $1, :name, @name, $name
+	tokOp               // punctuation and operators
+)
+
+type token struct {
+	kind tokenKind
+	text string // for tokString and tokBlob, the decoded value
+	pos  int
+}
+
+// is reports whether t is the keyword or operator s.
+func (t token) is(s string) bool {
+	switch t.kind {
+	case tokIdent:
+		return strings.EqualFold(t.text, s)
+	case tokOp:
+		return t.text == s
+	}
+	return false
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of statement"
+	case tokString:
+		return "'" + strings.ReplaceAll(t.text, "'", "''") + "'"
+	case tokQuoted:
+		return `"` + t.text + `"`
+	case tokBlob:
+		return "X'" + hex.EncodeToString([]byte(t.text)) + "'"
+	}
+	return t.text
+}
+
+// lex splits query into tokens, ending with a tokEOF token.
+func lex(query string) ([]token, error) {
+	var toks []token
+	i := 0
+	for {
+		// Skip spaces and comments.
+		for i < len(query) {
+			if isSpace(query[i]) {
+				i++
+			} else if strings.HasPrefix(query[i:], "--") {
+				for i < len(query) && query[i] != '\n' {
+					i++
+				}
+			} else if strings.HasPrefix(query[i:], "/*") {
+				end := strings.Index(query[i+2:], "*/")
+				if end < 0 {
+					return nil, fmt.Errorf("memdb: unterminated comment at offset %d", i)
+				}
+				i += 2 + end + 2
+			} else {
+				break
+			}
+		}
+		if i == len(query) {
+			return append(toks, token{kind: tokEOF, pos: i}), nil
+		}
+
+		start := i
+		c := query[i]
+		switch {
+		case (c == 'x' || c == 'X') && i+1 < len(query) && query[i+1] == '\'':
+			s, n, err := lexString(query[i+1:], start)
+			if err != nil {
+				return nil, err
+			}
+			b, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("memdb: invalid blob literal at offset %d", start)
+			}
+			toks = append(toks, token{tokBlob, string(b), start})
+			i += 1 + n
+		case isIdentStart(c):
+			for i < len(query) && isIdentPart(query[i]) {
+				i++
+			}
+			toks = append(toks, token{tokIdent, query[start:i], start})
+		case isDigit(c) || c == '.' && i+1 < len(query) && isDigit(query[i+1]):
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+			if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+				j := i + 1
+				if j < len(query) && (query[j] == '+' || query[j] == '-') {
+					j++
+				}
+				if j < len(query) && isDigit(query[j]) {
+					i = j
+					for i < len(query) && isDigit(query[i]) {
+						i++
+					}
+				}
+			}
+			toks = append(toks, token{tokNumber, query[start:i], start})
+		case c == '\'':
+			s, n, err := lexString(query[i:], start)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{tokString, s, start})
+			i += n
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("memdb: unterminated quoted identifier at offset %d", start)
+			}
+			toks = append(toks, token{tokQuoted, query[i+1 : i+1+end], start})
+			i += end + 2
+		case c == '?':
+			i++
+			toks = append(toks, token{tokParam, "?", start})
+		case c == '$' || c == ':' || c == '@':
+			i++
+			for i < len(query) && isIdentPart(query[i]) {
+				i++
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf("memdb: invalid parameter at offset %d", start)
+			}
+			toks = append(toks, token{tokParam, query[start:i], start})
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(query[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("memdb: unexpected character %q at offset %d", c, start)
+			}
+			i += len(op)
+			switch op {
+			case "==":
+				op = "="
+			case "<>":
+				op = "!="
+			}
+			toks = append(toks, token{tokOp, op, start})
+		}
+	}
+}
+
+// operators are the operators and punctuation, longest first.
+var operators = []string{
+	"<=", ">=", "<>", "!=", "||", "==",
+	"(", ")", ",", ";", ".", "*", "+", "-", "/", "%", "=", "<", ">",
+}
+
+// lexString decodes the single-quoted string at the start of s, and
+// returns it and the length of its encoding.
+func lexString(s string, pos int) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\'' {
+			if i+1 < len(s) && s[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		}
+		b.WriteByte(s[i])
+	}
+	return "", 0, fmt.Errorf("memdb: unterminated string at offset %d", pos)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
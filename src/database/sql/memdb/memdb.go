@@ -0,0 +1,602 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package memdb provides an in-memory SQL database driver, intended for
+// testing code that uses [database/sql] without an external database.
+//
+// Importing the package registers the driver under the name "memdb":
+//
+//	db, err := sql.Open("memdb", "test")
+//
+// The data source name is the name of a database. All the [sql.DB] opened
+// with the same name share the same database, which is deleted when the
+// last of them is closed. An empty name opens a new private database.
+//
+// # SQL
+//
+// The driver supports a subset of SQL:
+//
+//   - CREATE TABLE [IF NOT EXISTS] and DROP TABLE [IF EXISTS]. Columns
+//     have one of the types INTEGER, REAL, TEXT, BLOB, BOOLEAN and
+//     TIMESTAMP (or a common alias, such as INT or VARCHAR), and may be
+//     declared PRIMARY KEY, NOT NULL or with a DEFAULT value. A table has
+//     at most one primary key column. An INTEGER PRIMARY KEY column is
+//     assigned the next value when no value is given, and the last such
+//     value is reported by [sql.Result.LastInsertId].
+//   - INSERT INTO ... VALUES, with any number of rows.
+//   - UPDATE ... SET ... [WHERE ...] and DELETE FROM ... [WHERE ...].
+//   - SELECT [DISTINCT] with inner, left and cross joins, WHERE,
+//     GROUP BY, HAVING, ORDER BY, LIMIT and OFFSET.
+//
+// Expressions support the usual arithmetic, comparison and logical
+// operators with three-valued logic, ||, LIKE, IN, BETWEEN, IS [NOT] NULL,
+// CASE, the aggregate functions COUNT, SUM, AVG, MIN and MAX, and the
+// functions COALESCE, IFNULL, NULLIF, LOWER, UPPER, LENGTH and ABS.
+//
+// Parameters are written ? for the next positional argument, $1 for the
+// first positional argument, and :name, @name or $name for the argument
+// named name, as created by [sql.Named].
+//
+// A query may consist of several statements separated by semicolons. The
+// rows of each SELECT statement in a query form one result set, reached
+// with [sql.Rows.NextResultSet]. Outside a transaction, a query that
+// modifies the database takes effect atomically.
+//
+// # Transactions
+//
+// Transactions that modify the database are serialized: a transaction
+// waits for the current writer to commit or roll back before its first
+// modification. Transactions never see the changes of uncommitted
+// transactions.
+//
+// With the isolation levels [sql.LevelRepeatableRead] and above, a
+// transaction reads a snapshot of the database taken when it began, and
+// its first modification fails with [ErrSerialization] if another
+// transaction has committed since then. With lower levels, each statement
+// reads the latest committed data. [sql.DB.RunInTx] retries transactions
+// that fail with ErrSerialization.
+//
+// Transactions support savepoints, with [sql.Tx.Savepoint],
+// [sql.Tx.RollbackTo], [sql.Tx.Release] and [sql.Tx.Nested].
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+func init() {
+	sql.Register("memdb", Driver{})
+}
+
+// ErrSerialization is returned when a transaction cannot modify the
+// database because another transaction committed changes to the data it
+// read. The transaction should be rolled back and retried.
+var ErrSerialization = errors.New("memdb: could not serialize access due to concurrent update")
+
+// Driver is the memdb driver.
+type Driver struct{}
+
+// Open returns a new connection to the named database. The database is
+// kept at least until the connection is closed.
+func (Driver) Open(name string) (driver.Conn, error) {
+	return &conn{db: acquire(name), release: true}, nil
+}
+
+// ClassifyError classifies [ErrSerialization] as a serialization failure.
+func (Driver) ClassifyError(err error) driver.ErrorClass {
+	if errors.Is(err, ErrSerialization) {
+		return driver.ErrorClassSerialization
+	}
+	return driver.ErrorClassUnknown
+}
+
+// OpenConnector returns a connector to the named database. The database
+// is kept at least until the connector is closed.
+func (Driver) OpenConnector(name string) (driver.Connector, error) {
+	return &connector{db: acquire(name)}, nil
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*database)
+)
+
+// A database is an in-memory database.
+type database struct {
+	name string
+	refs int // guarded by registryMu
+
+	mu        sync.Mutex
+	committed *state // the latest committed state
+
+	// writer holds a value while a transaction is modifying the database.
+	writer chan struct{}
+}
+
+// acquire returns the named database, creating it if needed, and adds a
+// reference to it.
+func acquire(name string) *database {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	db := registry[name]
+	if db == nil {
+		db = &database{
+			name:      name,
+			committed: &state{tables: make(map[string]*table)},
+			writer:    make(chan struct{}, 1),
+		}
+		if name != "" {
+			registry[name] = db
+		}
+	}
+	db.refs++
+	return db
+}
+
+// release removes a reference to db, deleting it with the last one.
+func (db *database) release() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	db.refs--
+	if db.refs == 0 && registry[db.name] == db {
+		delete(registry, db.name)
+	}
+}
+
+func (db *database) latest() *state {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.committed
+}
+
+// lock waits until no other transaction modifies db.
+func (db *database) lock(ctx context.Context) error {
+	select {
+	case db.writer <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (db *database) unlock() {
+	<-db.writer
+}
+
+// commit makes st the latest committed state. The caller holds the lock.
+func (db *database) commit(st *state) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	st.version = db.committed.version + 1
+	db.committed = st
+}
+
+type connector struct {
+	db        *database
+	closeOnce sync.Once
+}
+
+var _ io.Closer = (*connector)(nil)
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return Driver{}
+}
+
+// Close releases the database, which is deleted if it is not used anymore.
+func (c *connector) Close() error {
+	c.closeOnce.Do(c.db.release)
+	return nil
+}
+
+type conn struct {
+	db      *database
+	tx      *tx
+	release bool // release db when closed
+	closed  bool
+}
+
+var (
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+)
+
+var errClosed = errors.New("memdb: connection is closed")
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.closed {
+		return nil, driver.ErrBadConn
+	}
+	stmts, numInput, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{c: c, stmts: stmts, numInput: numInput}, nil
+}
+
+func (c *conn) Close() error {
+	if c.closed {
+		return errClosed
+	}
+	if c.tx != nil {
+		c.tx.Rollback()
+	}
+	c.closed = true
+	if c.release {
+		c.db.release()
+	}
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.closed {
+		return nil, driver.ErrBadConn
+	}
+	if c.tx != nil {
+		return nil, errors.New("memdb: transaction already in progress")
+	}
+	if opts.Isolation < 0 || opts.Isolation > driver.IsolationLevel(sql.LevelLinearizable) {
+		return nil, errors.New("memdb: unsupported isolation level")
+	}
+	c.tx = &tx{c: c, readOnly: opts.ReadOnly}
+	if opts.Isolation >= driver.IsolationLevel(sql.LevelRepeatableRead) {
+		c.tx.snapshot = c.db.latest()
+	}
+	return c.tx, nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if c.closed {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	stmts, _, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	_, res, err := c.run(ctx, stmts, args)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	stmts, _, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	sets, _, err := c.run(ctx, stmts, args)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(sets), nil
+}
+
+// run executes stmts, and returns the result sets of the queries and the
+// combined result of the other statements.
+func (c *conn) run(ctx context.Context, stmts []statement, args []driver.NamedValue) ([]*resultSet, execResult, error) {
+	if c.closed {
+		return nil, execResult{}, driver.ErrBadConn
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, execResult{}, err
+	}
+	write := slices.ContainsFunc(stmts, statement.isWrite)
+	var st *state
+	switch {
+	case c.tx != nil && write:
+		var err error
+		if st, err = c.tx.writable(ctx); err != nil {
+			return nil, execResult{}, err
+		}
+	case c.tx != nil:
+		st = c.tx.readable()
+	case write:
+		if err := c.db.lock(ctx); err != nil {
+			return nil, execResult{}, err
+		}
+		defer c.db.unlock()
+		st = c.db.latest().clone()
+	default:
+		st = c.db.latest()
+	}
+
+	var sets []*resultSet
+	var res execResult
+	for _, s := range stmts {
+		if s, ok := s.(*selectStmt); ok {
+			rs, err := execSelect(st, s, args)
+			if err != nil {
+				return nil, execResult{}, err
+			}
+			sets = append(sets, rs)
+			continue
+		}
+		r, err := execWrite(st, s, args)
+		if err != nil {
+			return nil, execResult{}, err
+		}
+		if r.lastInsertID != 0 {
+			res.lastInsertID = r.lastInsertID
+		}
+		res.rowsAffected += r.rowsAffected
+	}
+	if c.tx == nil && write {
+		c.db.commit(st)
+	}
+	return sets, res, nil
+}
+
+type tx struct {
+	c          *conn
+	readOnly   bool
+	snapshot   *state // the state read, for snapshot isolation
+	base       *state // the latest committed state when working was created
+	working    *state // the state modified by the transaction, or nil
+	savepoints []savepoint
+}
+
+var _ driver.Savepointer = (*tx)(nil)
+
+type savepoint struct {
+	name    string
+	working *state // a copy of the working state of the transaction, or nil
+}
+
+// readable returns the state read by the transaction.
+func (tx *tx) readable() *state {
+	switch {
+	case tx.working != nil:
+		return tx.working
+	case tx.snapshot != nil:
+		return tx.snapshot
+	}
+	return tx.c.db.latest()
+}
+
+// writable returns the state modified by the transaction, waiting for
+// other transactions to finish modifying the database first.
+func (tx *tx) writable(ctx context.Context) (*state, error) {
+	if tx.readOnly {
+		return nil, errors.New("memdb: cannot modify the database in a read-only transaction")
+	}
+	if tx.working != nil {
+		return tx.working, nil
+	}
+	db := tx.c.db
+	if err := db.lock(ctx); err != nil {
+		return nil, err
+	}
+	latest := db.latest()
+	if tx.snapshot != nil && tx.snapshot.version != latest.version {
+		db.unlock()
+		return nil, ErrSerialization
+	}
+	tx.base = latest
+	tx.working = latest.clone()
+	return tx.working, nil
+}
+
+func (tx *tx) Savepoint(ctx context.Context, name string) error {
+	if tx.c.tx != tx {
+		return driver.ErrBadConn
+	}
+	sp := savepoint{name: name}
+	if tx.working != nil {
+		sp.working = tx.working.clone()
+	}
+	tx.savepoints = append(tx.savepoints, sp)
+	return nil
+}
+
+func (tx *tx) RollbackTo(ctx context.Context, name string) error {
+	i, err := tx.findSavepoint(name)
+	if err != nil {
+		return err
+	}
+	switch sp := tx.savepoints[i]; {
+	case sp.working != nil:
+		tx.working = sp.working.clone()
+	case tx.working != nil:
+		// The savepoint was created before the first modification.
+		tx.working = tx.base.clone()
+	}
+	tx.savepoints = tx.savepoints[:i+1]
+	return nil
+}
+
+func (tx *tx) Release(ctx context.Context, name string) error {
+	i, err := tx.findSavepoint(name)
+	if err != nil {
+		return err
+	}
+	tx.savepoints = tx.savepoints[:i]
+	return nil
+}
+
+// findSavepoint returns the index of the most recent savepoint with the
+// given name.
+func (tx *tx) findSavepoint(name string) (int, error) {
+	if tx.c.tx != tx {
+		return 0, driver.ErrBadConn
+	}
+	for i := len(tx.savepoints) - 1; i >= 0; i-- {
+		if tx.savepoints[i].name == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("memdb: no such savepoint %s", name)
+}
+
+func (tx *tx) Commit() error {
+	if tx.c.tx != tx {
+		return driver.ErrBadConn
+	}
+	tx.c.tx = nil
+	if tx.working != nil {
+		tx.c.db.commit(tx.working)
+		tx.c.db.unlock()
+	}
+	return nil
+}
+
+func (tx *tx) Rollback() error {
+	if tx.c.tx != tx {
+		return driver.ErrBadConn
+	}
+	tx.c.tx = nil
+	if tx.working != nil {
+		tx.c.db.unlock()
+	}
+	return nil
+}
+
+type stmt struct {
+	c        *conn
+	stmts    []statement
+	numInput int
+}
+
+var (
+	_ driver.StmtExecContext  = (*stmt)(nil)
+	_ driver.StmtQueryContext = (*stmt)(nil)
+)
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return s.numInput
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// namedValues converts the positional arguments of the legacy Stmt methods.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	_, res, err := s.c.run(ctx, s.stmts, args)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	sets, _, err := s.c.run(ctx, s.stmts, args)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(sets), nil
+}
+
+func (r execResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r execResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+type rows struct {
+	sets []*resultSet
+	set  int // index of the current result set
+	pos  int // index of the next row in the current result set
+}
+
+var (
+	_ driver.RowsNextResultSet              = (*rows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*rows)(nil)
+)
+
+func newRows(sets []*resultSet) *rows {
+	if len(sets) == 0 {
+		sets = []*resultSet{{}}
+	}
+	return &rows{sets: sets}
+}
+
+func (r *rows) Columns() []string {
+	return r.sets[r.set].columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	rs := r.sets[r.set]
+	if r.pos >= len(rs.rows) {
+		return io.EOF
+	}
+	for i, v := range rs.rows[r.pos] {
+		if b, ok := v.([]byte); ok {
+			v = slices.Clone(b)
+		}
+		dest[i] = v
+	}
+	r.pos++
+	return nil
+}
+
+func (r *rows) HasNextResultSet() bool {
+	return r.set+1 < len(r.sets)
+}
+
+func (r *rows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.set++
+	r.pos = 0
+	return nil
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.sets[r.set].types[index].String()
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	return r.sets[r.set].types[index].scanType()
+}
@@ -0,0 +1,604 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memdb_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"database/sql/memdb"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openDB returns a new private database with the tables people and pets.
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("memdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mustExec(t, db, `
+		CREATE TABLE people (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			age INT,
+			city VARCHAR DEFAULT 'Paris'
+		);
+		CREATE TABLE pets (name TEXT, owner INTEGER, kind TEXT);
+		INSERT INTO people (name, age, city) VALUES
+			('Alice', 30, 'Paris'), ('Bob', 25, 'Rome'), ('Chris', NULL, 'Paris');
+		INSERT INTO people (name, age) VALUES ('Dana', 41);
+		INSERT INTO pets VALUES ('Rex', 1, 'dog'), ('Tom', 1, 'cat'), ('Nemo', 3, 'fish');
+	`)
+	return db
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...any) sql.Result {
+	t.Helper()
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		t.Fatalf("Exec(%q): %v", query, err)
+	}
+	return res
+}
+
+// queryString returns the rows of a query formatted as "a,b;c,d".
+func queryString(t *testing.T, q interface {
+	Query(string, ...any) (*sql.Rows, error)
+}, query string, args ...any) string {
+	t.Helper()
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		t.Fatalf("Query(%q): %v", query, err)
+	}
+	defer rows.Close()
+	return formatRows(t, rows)
+}
+
+func formatRows(t *testing.T, rows *sql.Rows) string {
+	t.Helper()
+	cols, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			t.Fatal(err)
+		}
+		s := make([]string, len(vals))
+		for i, v := range vals {
+			switch v := v.(type) {
+			case nil:
+				s[i] = "NULL"
+			case []byte:
+				s[i] = fmt.Sprintf("%x", v)
+			default:
+				s[i] = fmt.Sprint(v)
+			}
+		}
+		out = append(out, strings.Join(s, ","))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(out, ";")
+}
+
+func TestQuery(t *testing.T) {
+	db := openDB(t)
+	tests := []struct {
+		query string
+		args  []any
+		want  string
+	}{
+		{"SELECT name FROM people", nil, "Alice;Bob;Chris;Dana"},
+		{"SELECT * FROM people WHERE id = 4", nil, "4,Dana,41,Paris"},
+		{"SELECT name FROM people WHERE age > 26", nil, "Alice;Dana"},
+		{"SELECT name FROM people WHERE age IS NULL", nil, "Chris"},
+		{"SELECT name FROM people WHERE NOT age < 30", nil, "Alice;Dana"},
+		{"SELECT name FROM people WHERE age BETWEEN 25 AND 30 ORDER BY name DESC", nil, "Bob;Alice"},
+		{"SELECT name FROM people WHERE name IN ('Bob', 'Dana')", nil, "Bob;Dana"},
+		{"SELECT name FROM people WHERE name LIKE '%i%'", nil, "Alice;Chris"},
+		{"SELECT name FROM people ORDER BY age", nil, "Chris;Bob;Alice;Dana"},
+		{"SELECT name, age * 2 AS twice FROM people ORDER BY twice DESC LIMIT 2", nil, "Dana,82;Alice,60"},
+		{"SELECT name FROM people ORDER BY 1 LIMIT 2 OFFSET 1", nil, "Bob;Chris"},
+		{"SELECT DISTINCT city FROM people ORDER BY city", nil, "Paris;Rome"},
+		{"SELECT city, COUNT(*), AVG(age), MAX(name) FROM people GROUP BY city ORDER BY city", nil, "Paris,3,35.5,Dana;Rome,1,25,Bob"},
+		{"SELECT city FROM people GROUP BY city HAVING COUNT(age) > 1", nil, "Paris"},
+		{"SELECT COUNT(*), SUM(age), COUNT(DISTINCT city) FROM people", nil, "4,96,2"},
+		{"SELECT COUNT(*), SUM(age) FROM people WHERE age > 100", nil, "0,NULL"},
+		{"SELECT p.name, pets.name FROM people p JOIN pets ON pets.owner = p.id ORDER BY 2", nil, "Chris,Nemo;Alice,Rex;Alice,Tom"},
+		{"SELECT p.name, COUNT(pets.name) FROM people AS p LEFT JOIN pets ON owner = id GROUP BY p.name ORDER BY p.name", nil, "Alice,2;Bob,0;Chris,1;Dana,0"},
+		{"SELECT COUNT(*) FROM people, pets", nil, "12"},
+		{"SELECT pets.* FROM people, pets WHERE people.id = pets.owner AND people.name = 'Chris'", nil, "Nemo,3,fish"},
+		{"SELECT name || ' from ' || city FROM people WHERE id = 2", nil, "Bob from Rome"},
+		{"SELECT CASE WHEN age < 30 THEN 'young' WHEN age IS NULL THEN '?' ELSE 'old' END FROM people", nil, "old;young;?;old"},
+		{"SELECT COALESCE(age, -1), UPPER(name), LENGTH(name) FROM people WHERE id = 3", nil, "-1,CHRIS,5"},
+		{"SELECT 1 + 2 * 3, 7 / 2, 7.0 / 2, 7 % 3, -(2)", nil, "7,3,3.5,1,-2"},
+		{"SELECT NULL = NULL, NULL OR TRUE, NULL AND FALSE", nil, "NULL,true,false"},
+		{"SELECT name FROM people WHERE id = ?", []any{2}, "Bob"},
+		{"SELECT name FROM people WHERE age > $1 AND age < $2 ORDER BY $2 - age", []any{20, 35}, "Alice;Bob"},
+		{"SELECT name FROM people WHERE city = :city AND age > @age", []any{sql.Named("age", 28), sql.Named("city", "Paris")}, "Alice;Dana"},
+		{"SELECT name FROM people LIMIT ?", []any{1}, "Alice"},
+		{`SELECT "name" FROM people WHERE ID = 1 -- comment`, nil, "Alice"},
+	}
+	for _, tt := range tests {
+		if got := queryString(t, db, tt.query, tt.args...); got != tt.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	db := openDB(t)
+	tests := []struct {
+		query string
+		args  []any
+		err   string
+	}{
+		{"SELECT name FROM nobody", nil, "no such table nobody"},
+		{"SELECT nothing FROM people", nil, "no such column nothing"},
+		{"SELECT name FROM people, pets", nil, "ambiguous column name name"},
+		{"SELECT name FROM people WHERE", nil, "syntax error"},
+		{"SELECT name FROM people WHERE name = 'x", nil, "unterminated string"},
+		{"SELECT name + 1 FROM people", nil, "memdb:"},
+		{"SELECT 1 / 0", nil, "division by zero"},
+		{"SELECT 9223372036854775807 + 1", nil, "integer overflow"},
+		{"SELECT -9223372036854775807 - 2", nil, "integer overflow"},
+		{"SELECT 4294967296 * 4294967296", nil, "integer overflow"},
+		{"SELECT (-9223372036854775807 - 1) / -1", nil, "integer overflow"},
+		{"SELECT -(-9223372036854775807 - 1)", nil, "integer overflow"},
+		{"SELECT " + strings.Repeat("(", 5000) + "1" + strings.Repeat(")", 5000), nil, "nested too deeply"},
+		{"SELECT " + strings.Repeat("- ", 5000) + "1", nil, "nested too deeply"},
+		{"SELECT " + strings.Repeat("NOT ", 5000) + "TRUE", nil, "nested too deeply"},
+		{"SELECT FOO(1)", nil, "unknown function FOO"},
+		{"SELECT name FROM people ORDER BY 3", nil, "out of range"},
+		{"SELECT name FROM people WHERE id = :id", []any{sql.Named("other", 1)}, "missing argument"},
+		{"INSERT INTO people (id, name) VALUES (1, 'Eve')", nil, "UNIQUE constraint failed: people.id"},
+		{"INSERT INTO people (age) VALUES (1)", nil, "NOT NULL constraint failed: people.name"},
+		{"INSERT INTO people (name, age) VALUES ('Eve', 'old')", nil, "column age"},
+		{"INSERT INTO people (name, name) VALUES ('Eve', 'Eve')", nil, "more than once"},
+		{"UPDATE people SET id = 1", nil, "UNIQUE constraint failed"},
+		{"CREATE TABLE people (x INT)", nil, "table people already exists"},
+		{"CREATE TABLE t (x INT PRIMARY KEY, y INT PRIMARY KEY)", nil, "memdb:"},
+		{"CREATE TABLE t (x UNKNOWN)", nil, "memdb:"},
+	}
+	for _, tt := range tests {
+		_, err := db.Exec(tt.query, tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: got error %v, want %q", tt.query, err, tt.err)
+		}
+	}
+	// Failed statements have no effect.
+	if got := queryString(t, db, "SELECT COUNT(*) FROM people WHERE id = 1"); got != "1" {
+		t.Errorf("people with id 1: %s, want 1", got)
+	}
+}
+
+func TestExec(t *testing.T) {
+	db := openDB(t)
+	res := mustExec(t, db, "INSERT INTO people (name) VALUES ('Eve'), ('Fred')")
+	if id, _ := res.LastInsertId(); id != 6 {
+		t.Errorf("LastInsertId = %d, want 6", id)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("RowsAffected = %d, want 2", n)
+	}
+	res = mustExec(t, db, "UPDATE people SET age = age + 1, city = 'Oslo' WHERE age >= 30")
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("UPDATE RowsAffected = %d, want 2", n)
+	}
+	res = mustExec(t, db, "DELETE FROM people WHERE city = 'Paris'")
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Errorf("DELETE RowsAffected = %d, want 3", n)
+	}
+	if got, want := queryString(t, db, "SELECT * FROM people"), "1,Alice,31,Oslo;2,Bob,25,Rome;4,Dana,42,Oslo"; got != want {
+		t.Errorf("people = %s, want %s", got, want)
+	}
+	mustExec(t, db, "DROP TABLE pets; DROP TABLE IF EXISTS pets; CREATE TABLE IF NOT EXISTS people (x INT)")
+	if _, err := db.Exec("SELECT * FROM pets"); err == nil {
+		t.Error("pets table still exists after DROP TABLE")
+	}
+	// A failing statement cancels a whole query outside a transaction.
+	if _, err := db.Exec("DELETE FROM people; SELECT * FROM nothing"); err == nil {
+		t.Fatal("query with a failing statement succeeded")
+	}
+	if got := queryString(t, db, "SELECT COUNT(*) FROM people"); got != "3" {
+		t.Errorf("%s people after a failed query, want 3", got)
+	}
+}
+
+func TestTypes(t *testing.T) {
+	db := openDB(t)
+	mustExec(t, db, "CREATE TABLE v (i INTEGER, r REAL, s TEXT, b BLOB, ok BOOLEAN, ts TIMESTAMP)")
+	ts := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+	mustExec(t, db, "INSERT INTO v VALUES (?, ?, ?, ?, ?, ?)", 1, 2, "three", []byte{4}, true, ts)
+	mustExec(t, db, "INSERT INTO v VALUES (NULL, 1.5, 'x', X'0a0B', FALSE, '2024-03-01 08:00:00')")
+
+	var (
+		i  int64
+		r  float64
+		s  string
+		b  []byte
+		ok bool
+		tm time.Time
+	)
+	if err := db.QueryRow("SELECT * FROM v WHERE i = 1").Scan(&i, &r, &s, &b, &ok, &tm); err != nil {
+		t.Fatal(err)
+	}
+	if i != 1 || r != 2 || s != "three" || string(b) != "\x04" || !ok || !tm.Equal(ts) {
+		t.Errorf("got %v, %v, %q, %x, %v, %v", i, r, s, b, ok, tm)
+	}
+	if got, want := queryString(t, db, "SELECT b, ts > ? FROM v WHERE i IS NULL", ts), "0a0b,true"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	rows, err := db.Query("SELECT i, r, s, b, ok, ts, i + 1 AS n FROM v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, ct := range types {
+		names = append(names, ct.Name()+":"+ct.DatabaseTypeName()+":"+ct.ScanType().String())
+	}
+	want := []string{"i:INTEGER:int64", "r:REAL:float64", "s:TEXT:string", "b:BLOB:[]uint8", "ok:BOOLEAN:bool", "ts:TIMESTAMP:time.Time", "n::interface {}"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("column types = %v, want %v", names, want)
+	}
+}
+
+func TestMultipleResultSets(t *testing.T) {
+	db := openDB(t)
+	rows, err := db.Query("SELECT name FROM people WHERE id = 1; INSERT INTO pets VALUES ('Kit', 2, 'cat'); SELECT name, kind FROM pets WHERE owner = 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if got := formatRows(t, rows); got != "Alice" {
+		t.Errorf("first result set = %s, want Alice", got)
+	}
+	if !rows.NextResultSet() {
+		t.Fatal("no second result set")
+	}
+	if got := formatRows(t, rows); got != "Kit,cat" {
+		t.Errorf("second result set = %s, want Kit,cat", got)
+	}
+	if rows.NextResultSet() {
+		t.Error("unexpected third result set")
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	db := openDB(t)
+	if _, err := db.Prepare("SELECT FROM"); err == nil {
+		t.Error("Prepare of an invalid query succeeded")
+	}
+	stmt, err := db.Prepare("SELECT name FROM people WHERE id = ? OR name = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	var name string
+	if err := stmt.QueryRow(2, "").Scan(&name); err != nil || name != "Bob" {
+		t.Errorf("QueryRow = %q, %v; want Bob", name, err)
+	}
+	if _, err := stmt.Query(1); err == nil {
+		t.Error("Query with too few arguments succeeded")
+	}
+
+	named, err := db.Prepare("INSERT INTO pets VALUES (:name, :owner, 'dog')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer named.Close()
+	for _, pet := range []string{"A", "B"} {
+		if _, err := named.Exec(sql.Named("owner", 4), sql.Named("name", pet)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := queryString(t, db, "SELECT name FROM pets WHERE owner = 4"); got != "A;B" {
+		t.Errorf("pets of Dana = %s, want A;B", got)
+	}
+}
+
+func TestLegacyStmt(t *testing.T) {
+	c, err := openDB(t).Driver().Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for _, q := range []string{"CREATE TABLE t (x INT, y TEXT)", "INSERT INTO t VALUES (?, ?)", "SELECT y FROM t WHERE x = ?"} {
+		stmt, err := c.Prepare(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer stmt.Close()
+		switch {
+		case strings.HasPrefix(q, "CREATE"):
+			_, err = stmt.Exec(nil)
+		case strings.HasPrefix(q, "INSERT"):
+			_, err = stmt.Exec([]driver.Value{int64(1), "one"})
+		default:
+			var rows driver.Rows
+			rows, err = stmt.Query([]driver.Value{int64(1)})
+			if err != nil {
+				break
+			}
+			dest := make([]driver.Value, 1)
+			if err := rows.Next(dest); err != nil || dest[0] != "one" {
+				t.Errorf("Next = %v, %v; want one", dest[0], err)
+			}
+			rows.Close()
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("DELETE FROM people WHERE id > 1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := queryString(t, tx, "SELECT COUNT(*) FROM people"); got != "1" {
+		t.Errorf("transaction sees %s people, want 1", got)
+	}
+	if got := queryString(t, db, "SELECT COUNT(*) FROM people"); got != "4" {
+		t.Errorf("uncommitted delete visible outside the transaction: %s people", got)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := queryString(t, db, "SELECT COUNT(*) FROM people"); got != "4" {
+		t.Errorf("%s people after rollback, want 4", got)
+	}
+
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT INTO people (name) VALUES ('Eve')"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := queryString(t, db, "SELECT COUNT(*) FROM people"); got != "5" {
+		t.Errorf("%s people after commit, want 5", got)
+	}
+
+	tx, err = db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("DELETE FROM people"); err == nil {
+		t.Error("DELETE in a read-only transaction succeeded")
+	}
+	tx.Rollback()
+}
+
+func TestIsolation(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	count := func(tx *sql.Tx) string {
+		t.Helper()
+		return queryString(t, tx, "SELECT COUNT(*) FROM people")
+	}
+
+	committed, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer committed.Rollback()
+	snapshot, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Rollback()
+	if count(committed) != "4" || count(snapshot) != "4" {
+		t.Fatal("transactions do not see the initial rows")
+	}
+
+	mustExec(t, db, "DELETE FROM people WHERE id = 4")
+	if got := count(committed); got != "3" {
+		t.Errorf("read committed transaction sees %s people, want 3", got)
+	}
+	if got := count(snapshot); got != "4" {
+		t.Errorf("serializable transaction sees %s people, want 4", got)
+	}
+	_, err = snapshot.Exec("UPDATE people SET age = 0")
+	if !errors.Is(err, memdb.ErrSerialization) {
+		t.Errorf("write after a concurrent commit: got %v, want ErrSerialization", err)
+	}
+	if _, err := committed.Exec("UPDATE people SET age = 0"); err != nil {
+		t.Errorf("read committed write after a concurrent commit: %v", err)
+	}
+}
+
+func TestWriterWaits(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("UPDATE people SET age = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Readers are not blocked by the writer, but other writers are.
+	if got := queryString(t, db, "SELECT age FROM people WHERE id = 1"); got != "30" {
+		t.Errorf("age = %s while updated in a transaction, want 30", got)
+	}
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := db.ExecContext(shortCtx, "DELETE FROM people"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("concurrent write: got %v, want DeadlineExceeded", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := db.Exec("UPDATE people SET age = age + 1")
+		done <- err
+	}()
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := queryString(t, db, "SELECT DISTINCT age FROM people"); got != "2" {
+		t.Errorf("ages = %s, want 2", got)
+	}
+}
+
+func TestSharedDatabase(t *testing.T) {
+	db1, err := sql.Open("memdb", "TestSharedDatabase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db2, err := sql.Open("memdb", "TestSharedDatabase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db2.Close()
+	mustExec(t, db1, "CREATE TABLE t (x INT); INSERT INTO t VALUES (1)")
+	if got := queryString(t, db2, "SELECT x FROM t"); got != "1" {
+		t.Errorf("second DB sees %q, want 1", got)
+	}
+	db1.Close()
+	if got := queryString(t, db2, "SELECT x FROM t"); got != "1" {
+		t.Errorf("second DB sees %q after closing the first one, want 1", got)
+	}
+	db2.Close()
+
+	db3, err := sql.Open("memdb", "TestSharedDatabase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db3.Close()
+	if _, err := db3.Exec("SELECT x FROM t"); err == nil {
+		t.Error("database kept after all its users were closed")
+	}
+}
+
+func TestSavepoints(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	exec := func(query string) {
+		t.Helper()
+		if _, err := tx.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := func() string {
+		t.Helper()
+		return queryString(t, tx, "SELECT name FROM people ORDER BY id")
+	}
+
+	if err := tx.Savepoint(ctx, "start"); err != nil {
+		t.Fatal(err)
+	}
+	exec("DELETE FROM people WHERE id = 1")
+	if err := tx.Savepoint(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	exec("INSERT INTO people (name) VALUES ('Eve')")
+	if err := tx.RollbackTo(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(); got != "Bob;Chris;Dana" {
+		t.Errorf("after rollback to a: %s", got)
+	}
+	exec("INSERT INTO people (name) VALUES ('Fred')")
+	if err := tx.Release(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.RollbackTo(ctx, "a"); err == nil {
+		t.Error("RollbackTo of a released savepoint succeeded")
+	}
+
+	err = tx.Nested(ctx, func(tx *sql.Tx) error {
+		exec("DELETE FROM people")
+		return errors.New("fail")
+	})
+	if err == nil {
+		t.Error("Nested succeeded with a failing function")
+	}
+	if got := names(); got != "Bob;Chris;Dana;Fred" {
+		t.Errorf("after failed nested transaction: %s", got)
+	}
+
+	// The savepoint created before any change rolls back all changes.
+	if err := tx.RollbackTo(ctx, "start"); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(); got != "Alice;Bob;Chris;Dana" {
+		t.Errorf("after rollback to start: %s", got)
+	}
+	exec("DELETE FROM people WHERE id = 2")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := queryString(t, db, "SELECT name FROM people ORDER BY id"); got != "Alice;Chris;Dana" {
+		t.Errorf("after commit: %s", got)
+	}
+}
+
+func TestRunInTx(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	attempts := 0
+	err := db.RunInTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx *sql.Tx) error {
+		attempts++
+		var n int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM people").Scan(&n); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// A concurrent change makes the first attempt fail.
+			if _, err := db.Exec("DELETE FROM people WHERE id = 4"); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec("INSERT INTO pets VALUES ('count', ?, 'number')", n)
+		return err
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("RunInTx = %v after %d attempts; want success after 2", err, attempts)
+	}
+	if got := queryString(t, db, "SELECT owner FROM pets WHERE name = 'count'"); got != "3" {
+		t.Errorf("count = %s, want 3", got)
+	}
+}
@@ -0,0 +1,964 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memdb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A statement is a parsed SQL statement.
+type statement interface {
+	// isWrite reports whether the statement modifies the database.
+	isWrite() bool
+}
+
+type createTableStmt struct {
+	table       string
+	ifNotExists bool
+	columns     []column
+}
+
+type dropTableStmt struct {
+	table    string
+	ifExists bool
+}
+
+type insertStmt struct {
+	table   string
+	columns []string // nil for all columns
+	rows    [][]expr
+}
+
+type updateStmt struct {
+	table string
+	sets  []setClause
+	where expr
+}
+
+type setClause struct {
+	column string
+	value  expr
+}
+
+type deleteStmt struct {
+	table string
+	where expr
+}
+
+type selectStmt struct {
+	distinct bool
+	items    []selectItem
+	from     []fromItem
+	where    expr
+	groupBy  []expr
+	having   expr
+	orderBy  []orderItem
+	limit    expr
+	offset   expr
+}
+
+type selectItem struct {
+	star  string // table name for t.*, or "*" for *
+	expr  expr
+	alias string
+}
+
+type fromItem struct {
+	table string
+	alias string
+	join  string // "", "INNER" or "LEFT"
+	on    expr
+}
+
+type orderItem struct {
+	expr expr
+	desc bool
+}
+
+func (*createTableStmt) isWrite() bool { return true }
+func (*dropTableStmt) isWrite() bool   { return true }
+func (*insertStmt) isWrite() bool      { return true }
+func (*updateStmt) isWrite() bool      { return true }
+func (*deleteStmt) isWrite() bool      { return true }
+func (*selectStmt) isWrite() bool      { return false }
+
+// An expr is an expression.
+type expr interface{}
+
+type (
+	literal struct{ v driver.Value }
+	param   struct {
+		ordinal int    // 1-based, for positional parameters
+		name    string // for named parameters
+	}
+	colRef struct{ table, name string }
+	unary  struct {
+		op string // "-" or "NOT"
+		x  expr
+	}
+	binary struct {
+		op   string // "OR", "AND", "LIKE", or an operator
+		x, y expr
+	}
+	isNull struct {
+		x   expr
+		not bool
+	}
+	inList struct {
+		x    expr
+		list []expr
+		not  bool
+	}
+	call struct {
+		name     string // upper case
+		args     []expr
+		star     bool // COUNT(*)
+		distinct bool
+	}
+	caseExpr struct {
+		operand expr // nil for a searched CASE
+		whens   []whenClause
+		els     expr
+	}
+	whenClause struct{ cond, result expr }
+)
+
+// A parser parses the statements of a query.
+type parser struct {
+	toks []token
+	i    int
+
+	positional int  // number of ? parameters seen
+	maxOrdinal int  // largest ordinal of positional parameters
+	named      bool // whether named parameters were seen
+	depth      int  // nesting depth of the expression being parsed
+}
+
+// maxExprDepth is the maximum nesting depth of parenthesized expressions,
+// subqueries and unary operators, which bounds the recursion of the parser.
+const maxExprDepth = 1000
+
+// parse parses the statements of query, separated by semicolons. It also
+// returns the number of positional parameters, or -1 if query has named
+// parameters.
+func parse(query string) ([]statement, int, error) {
+	toks, err := lex(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	p := &parser{toks: toks}
+	var stmts []statement
+	for {
+		for p.accept(";") {
+		}
+		if p.peek().kind == tokEOF {
+			break
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, 0, err
+		}
+		stmts = append(stmts, s)
+		if !p.accept(";") && p.peek().kind != tokEOF {
+			return nil, 0, p.errorf("expected ; or end of statement, found %v", p.peek())
+		}
+	}
+	if len(stmts) == 0 {
+		return nil, 0, fmt.Errorf("memdb: empty query")
+	}
+	numInput := p.maxOrdinal
+	if p.named {
+		numInput = -1
+	}
+	return stmts, numInput, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the keyword or operator s.
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// acceptSeq consumes the next tokens if they are the keywords in seq.
+func (p *parser) acceptSeq(seq ...string) bool {
+	for j, s := range seq {
+		if p.i+j >= len(p.toks) || !p.toks[p.i+j].is(s) {
+			return false
+		}
+	}
+	p.i += len(seq)
+	return true
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %s, found %v", s, p.peek())
+	}
+	return nil
+}
+
+// nest records that the parser is entering a nested expression, and reports
+// an error if the expression is nested too deeply.
+func (p *parser) nest() error {
+	p.depth++
+	if p.depth > maxExprDepth {
+		return p.errorf("expression nested too deeply")
+	}
+	return nil
+}
+
+func (p *parser) unnest() {
+	p.depth--
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("memdb: syntax error at offset %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+// keywords are the reserved words, which cannot be used as unquoted
+// identifiers.
+var keywords = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true,
+	"BY": true, "CASE": true, "CREATE": true, "DELETE": true, "DESC": true,
+	"DISTINCT": true, "DROP": true, "ELSE": true, "END": true, "EXISTS": true,
+	"FALSE": true, "FROM": true, "GROUP": true, "HAVING": true, "IF": true,
+	"IN": true, "INNER": true, "INSERT": true, "INTO": true, "IS": true,
+	"JOIN": true, "KEY": true, "LEFT": true, "LIKE": true, "LIMIT": true,
+	"NOT": true, "NULL": true, "OFFSET": true, "ON": true, "OR": true,
+	"ORDER": true, "OUTER": true, "PRIMARY": true, "SELECT": true, "SET": true,
+	"TABLE": true, "THEN": true, "TRUE": true, "UPDATE": true, "VALUES": true,
+	"WHEN": true, "WHERE": true,
+}
+
+// ident parses an identifier. Unquoted identifiers are case-insensitive,
+// and are returned in lower case.
+func (p *parser) ident() (string, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokQuoted:
+		p.i++
+		return t.text, nil
+	case t.kind == tokIdent && !keywords[strings.ToUpper(t.text)]:
+		p.i++
+		return strings.ToLower(t.text), nil
+	}
+	return "", p.errorf("expected identifier, found %v", t)
+}
+
+func (p *parser) statement() (statement, error) {
+	t := p.peek()
+	switch {
+	case t.is("SELECT"):
+		return p.selectStmt()
+	case t.is("INSERT"):
+		return p.insertStmt()
+	case t.is("UPDATE"):
+		return p.updateStmt()
+	case t.is("DELETE"):
+		return p.deleteStmt()
+	case t.is("CREATE"):
+		return p.createTableStmt()
+	case t.is("DROP"):
+		return p.dropTableStmt()
+	}
+	return nil, p.errorf("unsupported statement %v", t)
+}
+
+func (p *parser) createTableStmt() (statement, error) {
+	p.next()
+	if err := p.expect("TABLE"); err != nil {
+		return nil, err
+	}
+	s := &createTableStmt{ifNotExists: p.acceptSeq("IF", "NOT", "EXISTS")}
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for {
+		if p.acceptSeq("PRIMARY", "KEY") {
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			i := columnIndex(s.columns, name)
+			if i < 0 {
+				return nil, fmt.Errorf("memdb: no column %q for primary key", name)
+			}
+			s.columns[i].primaryKey = true
+		} else {
+			col, err := p.columnDef()
+			if err != nil {
+				return nil, err
+			}
+			if columnIndex(s.columns, col.name) >= 0 {
+				return nil, fmt.Errorf("memdb: duplicate column %q", col.name)
+			}
+			s.columns = append(s.columns, col)
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, col := range s.columns {
+		if col.primaryKey {
+			n++
+		}
+	}
+	if n > 1 {
+		return nil, fmt.Errorf("memdb: table %q has more than one primary key column", s.table)
+	}
+	return s, nil
+}
+
+func (p *parser) columnDef() (column, error) {
+	var col column
+	var err error
+	if col.name, err = p.ident(); err != nil {
+		return col, err
+	}
+	t := p.next()
+	if t.kind != tokIdent {
+		return col, p.errorf("expected type of column %q, found %v", col.name, t)
+	}
+	typ, ok := typeNames[strings.ToUpper(t.text)]
+	if !ok {
+		return col, fmt.Errorf("memdb: unsupported type %s", t.text)
+	}
+	col.typ = typ
+	if strings.EqualFold(t.text, "DOUBLE") {
+		p.accept("PRECISION")
+	}
+	if p.accept("(") {
+		// Ignore lengths and precisions.
+		for !p.accept(")") {
+			if p.next().kind == tokEOF {
+				return col, p.errorf("expected )")
+			}
+		}
+	}
+	for {
+		switch {
+		case p.acceptSeq("PRIMARY", "KEY"):
+			col.primaryKey = true
+		case p.acceptSeq("NOT", "NULL"):
+			col.notNull = true
+		case p.accept("NULL"):
+		case p.accept("DEFAULT"):
+			if col.def, err = p.unary(); err != nil {
+				return col, err
+			}
+		default:
+			return col, nil
+		}
+	}
+}
+
+func (p *parser) dropTableStmt() (statement, error) {
+	p.next()
+	if err := p.expect("TABLE"); err != nil {
+		return nil, err
+	}
+	s := &dropTableStmt{ifExists: p.acceptSeq("IF", "EXISTS")}
+	var err error
+	s.table, err = p.ident()
+	return s, err
+}
+
+func (p *parser) insertStmt() (statement, error) {
+	p.next()
+	if err := p.expect("INTO"); err != nil {
+		return nil, err
+	}
+	s := new(insertStmt)
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if p.accept("(") {
+		s.columns = []string{}
+		for {
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			s.columns = append(s.columns, name)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("VALUES"); err != nil {
+		return nil, err
+	}
+	for {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		row, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		s.rows = append(s.rows, row)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) updateStmt() (statement, error) {
+	p.next()
+	s := new(updateStmt)
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("SET"); err != nil {
+		return nil, err
+	}
+	for {
+		var set setClause
+		if set.column, err = p.ident(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		if set.value, err = p.expr(); err != nil {
+			return nil, err
+		}
+		s.sets = append(s.sets, set)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if p.accept("WHERE") {
+		if s.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) deleteStmt() (statement, error) {
+	p.next()
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	s := new(deleteStmt)
+	var err error
+	if s.table, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if p.accept("WHERE") {
+		if s.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) selectStmt() (statement, error) {
+	p.next()
+	s := new(selectStmt)
+	if p.accept("DISTINCT") {
+		s.distinct = true
+	} else {
+		p.accept("ALL")
+	}
+	var err error
+	for {
+		var item selectItem
+		if p.accept("*") {
+			item.star = "*"
+		} else if t := p.peek(); (t.kind == tokIdent || t.kind == tokQuoted) && p.toks[p.i+1].is(".") && p.toks[p.i+2].is("*") {
+			if item.star, err = p.ident(); err != nil {
+				return nil, err
+			}
+			p.i += 2
+		} else {
+			if item.expr, err = p.expr(); err != nil {
+				return nil, err
+			}
+			if p.accept("AS") {
+				if item.alias, err = p.ident(); err != nil {
+					return nil, err
+				}
+			} else if t := p.peek(); t.kind == tokQuoted || t.kind == tokIdent && !keywords[strings.ToUpper(t.text)] {
+				item.alias, _ = p.ident()
+			}
+		}
+		s.items = append(s.items, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if p.accept("FROM") {
+		for {
+			var from fromItem
+			if len(s.from) > 0 {
+				switch {
+				case p.accept(","):
+					from.join = "INNER"
+				case p.acceptSeq("INNER", "JOIN"), p.accept("JOIN"):
+					from.join = "INNER"
+				case p.acceptSeq("LEFT", "OUTER", "JOIN"), p.acceptSeq("LEFT", "JOIN"):
+					from.join = "LEFT"
+				}
+				if from.join == "" {
+					break
+				}
+			}
+			if from.table, err = p.ident(); err != nil {
+				return nil, err
+			}
+			from.alias = from.table
+			if p.accept("AS") {
+				if from.alias, err = p.ident(); err != nil {
+					return nil, err
+				}
+			} else if t := p.peek(); t.kind == tokQuoted || t.kind == tokIdent && !keywords[strings.ToUpper(t.text)] {
+				from.alias, _ = p.ident()
+			}
+			if from.join != "" && p.accept("ON") {
+				if from.on, err = p.expr(); err != nil {
+					return nil, err
+				}
+			} else if from.join == "LEFT" {
+				return nil, p.errorf("expected ON")
+			}
+			s.from = append(s.from, from)
+		}
+	}
+
+	if p.accept("WHERE") {
+		if s.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptSeq("GROUP", "BY") {
+		if s.groupBy, err = p.exprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("HAVING") {
+		if s.having, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptSeq("ORDER", "BY") {
+		for {
+			var item orderItem
+			if item.expr, err = p.expr(); err != nil {
+				return nil, err
+			}
+			if p.accept("DESC") {
+				item.desc = true
+			} else {
+				p.accept("ASC")
+			}
+			s.orderBy = append(s.orderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.accept("LIMIT") {
+		if s.limit, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if p.accept("OFFSET") {
+			if s.offset, err = p.expr(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) exprList() ([]expr, error) {
+	var list []expr
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.accept(",") {
+			return list, nil
+		}
+	}
+}
+
+// expr parses an expression. In order of increasing precedence, the
+// operators are:
+//
+//	OR
+//	AND
+//	NOT
+//	= != < <= > >= IS IN LIKE BETWEEN
+//	+ - ||
+//	* / %
+//	unary -
+func (p *parser) expr() (expr, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+	x, err := p.andExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") {
+		y, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{"OR", x, y}
+	}
+	return x, nil
+}
+
+func (p *parser) andExpr() (expr, error) {
+	x, err := p.notExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		y, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{"AND", x, y}
+	}
+	return x, nil
+}
+
+func (p *parser) notExpr() (expr, error) {
+	if p.accept("NOT") {
+		if err := p.nest(); err != nil {
+			return nil, err
+		}
+		defer p.unnest()
+		x, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{"NOT", x}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (expr, error) {
+	x, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokOp && (t.text == "=" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">="):
+			p.next()
+			y, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			x = &binary{t.text, x, y}
+		case t.is("IS"):
+			p.next()
+			not := p.accept("NOT")
+			if err := p.expect("NULL"); err != nil {
+				return nil, err
+			}
+			x = &isNull{x, not}
+		default:
+			not := false
+			if t.is("NOT") {
+				next := p.toks[p.i+1]
+				if !next.is("IN") && !next.is("LIKE") && !next.is("BETWEEN") {
+					return x, nil
+				}
+				p.next()
+				not = true
+			}
+			switch {
+			case p.accept("IN"):
+				if err := p.expect("("); err != nil {
+					return nil, err
+				}
+				list, err := p.exprList()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+				x = &inList{x, list, not}
+			case p.accept("LIKE"):
+				y, err := p.additive()
+				if err != nil {
+					return nil, err
+				}
+				x = &binary{"LIKE", x, y}
+				if not {
+					x = &unary{"NOT", x}
+				}
+			case p.accept("BETWEEN"):
+				lo, err := p.additive()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect("AND"); err != nil {
+					return nil, err
+				}
+				hi, err := p.additive()
+				if err != nil {
+					return nil, err
+				}
+				x = &binary{"AND", &binary{">=", x, lo}, &binary{"<=", x, hi}}
+				if not {
+					x = &unary{"NOT", x}
+				}
+			default:
+				return x, nil
+			}
+		}
+	}
+}
+
+func (p *parser) additive() (expr, error) {
+	x, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !t.is("+") && !t.is("-") && !t.is("||") {
+			return x, nil
+		}
+		p.next()
+		y, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{t.text, x, y}
+	}
+}
+
+func (p *parser) multiplicative() (expr, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !t.is("*") && !t.is("/") && !t.is("%") {
+			return x, nil
+		}
+		p.next()
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{t.text, x, y}
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	if p.accept("-") {
+		if err := p.nest(); err != nil {
+			return nil, err
+		}
+		defer p.unnest()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if lit, ok := x.(*literal); ok {
+			switch v := lit.v.(type) {
+			case int64:
+				return &literal{-v}, nil
+			case float64:
+				return &literal{-v}, nil
+			}
+		}
+		return &unary{"-", x}, nil
+	}
+	p.accept("+")
+	return p.primary()
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		if !strings.ContainsAny(t.text, ".eE") {
+			if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+				return &literal{n}, nil
+			}
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("memdb: invalid number %s", t.text)
+		}
+		return &literal{f}, nil
+	case tokString:
+		p.next()
+		return &literal{t.text}, nil
+	case tokBlob:
+		p.next()
+		return &literal{[]byte(t.text)}, nil
+	case tokParam:
+		p.next()
+		return p.param(t)
+	case tokOp:
+		if p.accept("(") {
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case tokIdent:
+		switch {
+		case p.accept("NULL"):
+			return &literal{nil}, nil
+		case p.accept("TRUE"):
+			return &literal{true}, nil
+		case p.accept("FALSE"):
+			return &literal{false}, nil
+		case p.accept("CASE"):
+			return p.caseExpr()
+		case p.toks[p.i+1].is("("):
+			return p.call()
+		}
+	}
+	if t.kind != tokIdent && t.kind != tokQuoted {
+		return nil, p.errorf("unexpected %v", t)
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept(".") {
+		col, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		return &colRef{name, col}, nil
+	}
+	return &colRef{"", name}, nil
+}
+
+func (p *parser) param(t token) (expr, error) {
+	if t.text == "?" {
+		p.positional++
+		p.maxOrdinal = max(p.maxOrdinal, p.positional)
+		return &param{ordinal: p.positional}, nil
+	}
+	if t.text[0] == '$' {
+		if n, err := strconv.Atoi(t.text[1:]); err == nil {
+			if n < 1 {
+				return nil, fmt.Errorf("memdb: invalid parameter %s", t.text)
+			}
+			p.maxOrdinal = max(p.maxOrdinal, n)
+			return &param{ordinal: n}, nil
+		}
+	}
+	p.named = true
+	return &param{name: t.text[1:]}, nil
+}
+
+func (p *parser) call() (expr, error) {
+	c := &call{name: strings.ToUpper(p.next().text)}
+	p.next() // (
+	if c.name == "COUNT" && p.accept("*") {
+		c.star = true
+		return c, p.expect(")")
+	}
+	if p.accept(")") {
+		return c, nil
+	}
+	c.distinct = p.accept("DISTINCT")
+	var err error
+	if c.args, err = p.exprList(); err != nil {
+		return nil, err
+	}
+	if c.distinct && !isAggregate(c.name) {
+		return nil, fmt.Errorf("memdb: DISTINCT in call to non-aggregate function %s", c.name)
+	}
+	return c, p.expect(")")
+}
+
+func (p *parser) caseExpr() (expr, error) {
+	c := new(caseExpr)
+	var err error
+	if !p.peek().is("WHEN") {
+		if c.operand, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.accept("WHEN") {
+		var w whenClause
+		if w.cond, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("THEN"); err != nil {
+			return nil, err
+		}
+		if w.result, err = p.expr(); err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, w)
+	}
+	if len(c.whens) == 0 {
+		return nil, p.errorf("expected WHEN")
+	}
+	if p.accept("ELSE") {
+		if c.els, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	return c, p.expect("END")
+}
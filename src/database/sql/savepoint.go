@@ -0,0 +1,157 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Savepoint creates a savepoint with the given name in the transaction.
+// The changes made after it can be undone with [Tx.RollbackTo].
+//
+// The name must consist of ASCII letters, digits and underscores, and
+// must not start with a digit.
+//
+// If the driver's transaction does not implement [driver.Savepointer],
+// Savepoint executes the SAVEPOINT statement.
+func (tx *Tx) Savepoint(ctx context.Context, name string) error {
+	return tx.savepoint(ctx, name, "SAVEPOINT ", driver.Savepointer.Savepoint)
+}
+
+// RollbackTo undoes the changes made in the transaction since the named
+// savepoint was created, and releases the savepoints created after it.
+// The savepoint itself is kept, and may be rolled back to again.
+//
+// If the driver's transaction does not implement [driver.Savepointer],
+// RollbackTo executes the ROLLBACK TO SAVEPOINT statement.
+func (tx *Tx) RollbackTo(ctx context.Context, name string) error {
+	return tx.savepoint(ctx, name, "ROLLBACK TO SAVEPOINT ", driver.Savepointer.RollbackTo)
+}
+
+// Release releases the named savepoint and the savepoints created after
+// it. The changes made since they were created are kept, and are
+// committed or rolled back with the transaction.
+//
+// If the driver's transaction does not implement [driver.Savepointer],
+// Release executes the RELEASE SAVEPOINT statement.
+func (tx *Tx) Release(ctx context.Context, name string) error {
+	return tx.savepoint(ctx, name, "RELEASE SAVEPOINT ", driver.Savepointer.Release)
+}
+
+func (tx *Tx) savepoint(ctx context.Context, name, stmt string, f func(driver.Savepointer, context.Context, string) error) error {
+	if !validSavepointName(name) {
+		return fmt.Errorf("sql: invalid savepoint name %q", name)
+	}
+	dc, release, err := tx.grabConn(ctx)
+	if err != nil {
+		return err
+	}
+	if sp, ok := tx.txi.(driver.Savepointer); ok {
+		withLock(dc, func() {
+			err = f(sp, ctx, name)
+		})
+		release(err)
+		return err
+	}
+	_, err = tx.db.execDC(ctx, dc, release, stmt+name, nil)
+	return err
+}
+
+func validSavepointName(name string) bool {
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		return false
+	}
+	for _, c := range []byte(name) {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// Nested runs fn in a nested transaction of tx. It creates a savepoint,
+// and calls fn with tx. If fn returns nil, Nested releases the savepoint,
+// keeping the changes made by fn in the transaction. Otherwise, or if fn
+// panics, Nested rolls back the changes made by fn and returns its error.
+//
+// Nested lets functions that take a [*Tx] make changes that are undone
+// on failure without ending the transaction of their caller. Calls to
+// Nested may be nested.
+func (tx *Tx) Nested(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	name := "sql_nested_" + strconv.FormatUint(tx.nested.Add(1), 10)
+	if err := tx.Savepoint(ctx, name); err != nil {
+		return err
+	}
+	ok := false
+	defer func() {
+		if !ok {
+			err = errors.Join(err, tx.RollbackTo(ctx, name), tx.Release(ctx, name))
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	ok = true
+	return tx.Release(ctx, name)
+}
+
+// maxTxAttempts is the maximum number of times RunInTx runs a transaction.
+const maxTxAttempts = 10
+
+// RunInTx runs fn in a transaction started with [DB.BeginTx]. If fn
+// returns nil, RunInTx commits the transaction; otherwise, or if fn
+// panics, it rolls the transaction back. fn must not commit or roll back
+// the transaction itself.
+//
+// If fn or the commit fails with an error that the driver classifies as
+// [driver.ErrorClassSerialization] with a [driver.ErrorClassifier],
+// RunInTx retries the transaction, up to 10 times in total. fn may thus be
+// called several times, and should have no effect outside the transaction.
+//
+// RunInTx returns the error of the last attempt.
+func (db *DB) RunInTx(ctx context.Context, opts *TxOptions, fn func(tx *Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		tx, err := db.BeginTx(ctx, opts)
+		if err != nil {
+			return err
+		}
+		err = runTx(tx, fn)
+		if err == nil || attempt == maxTxAttempts || ctx.Err() != nil ||
+			db.classifyError(err) != driver.ErrorClassSerialization {
+			return err
+		}
+	}
+}
+
+func runTx(tx *Tx, fn func(tx *Tx) error) error {
+	panicking := true
+	defer func() {
+		if panicking {
+			tx.Rollback()
+		}
+	}()
+	err := fn(tx)
+	panicking = false
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// classifyError classifies err with the driver's ErrorClassifier.
+func (db *DB) classifyError(err error) driver.ErrorClass {
+	if c, ok := db.connector.(driver.ErrorClassifier); ok {
+		return c.ClassifyError(err)
+	}
+	if c, ok := db.connector.Driver().(driver.ErrorClassifier); ok {
+		return c.ClassifyError(err)
+	}
+	return driver.ErrorClassUnknown
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTxSavepointFallback(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := tx.Savepoint(ctx, "sp_1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.RollbackTo(ctx, "sp_1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Release(ctx, "sp_1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"", "1sp", "sp; DROP TABLE people", "sp-1"} {
+		if err := tx.Savepoint(ctx, name); err == nil {
+			t.Errorf("Savepoint(%q) succeeded", name)
+		}
+	}
+	got := tx.dc.ci.(*fakeConn).savepoints
+	want := []string{"SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %q; want %q", got, want)
+	}
+}
+
+func TestTxNested(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	fc := tx.dc.ci.(*fakeConn)
+
+	errFail := errors.New("fail")
+	err = tx.Nested(ctx, func(tx *Tx) error {
+		return tx.Nested(ctx, func(*Tx) error { return errFail })
+	})
+	if !errors.Is(err, errFail) {
+		t.Errorf("Nested = %v; want %v", err, errFail)
+	}
+	want := []string{
+		"SAVEPOINT sql_nested_1",
+		"SAVEPOINT sql_nested_2",
+		"ROLLBACK TO SAVEPOINT sql_nested_2",
+		"RELEASE SAVEPOINT sql_nested_2",
+		"ROLLBACK TO SAVEPOINT sql_nested_1",
+		"RELEASE SAVEPOINT sql_nested_1",
+	}
+	if !reflect.DeepEqual(fc.savepoints, want) {
+		t.Errorf("statements = %q; want %q", fc.savepoints, want)
+	}
+
+	fc.savepoints = nil
+	if err := tx.Nested(ctx, func(*Tx) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"SAVEPOINT sql_nested_3", "RELEASE SAVEPOINT sql_nested_3"}
+	if !reflect.DeepEqual(fc.savepoints, want) {
+		t.Errorf("statements = %q; want %q", fc.savepoints, want)
+	}
+
+	fc.savepoints = nil
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Nested did not propagate the panic")
+			}
+		}()
+		tx.Nested(ctx, func(*Tx) error { panic("boom") })
+	}()
+	want = []string{"SAVEPOINT sql_nested_4", "ROLLBACK TO SAVEPOINT sql_nested_4", "RELEASE SAVEPOINT sql_nested_4"}
+	if !reflect.DeepEqual(fc.savepoints, want) {
+		t.Errorf("statements = %q; want %q", fc.savepoints, want)
+	}
+}
+
+func TestRunInTx(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	ctx := context.Background()
+
+	attempts := 0
+	err := db.RunInTx(ctx, nil, func(tx *Tx) error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("attempt %d: %w", attempts, errFakeSerialization)
+		}
+		_, err := tx.Exec("INSERT|people|name=?,age=?", "Dave", attempts)
+		return err
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("RunInTx = %v after %d attempts; want success after 3", err, attempts)
+	}
+	var age int
+	if err := db.QueryRow("SELECT|people|age|name=?", "Dave").Scan(&age); err != nil || age != 3 {
+		t.Errorf("age of Dave = %d, %v; want 3", age, err)
+	}
+
+	errFail := errors.New("fail")
+	attempts = 0
+	err = db.RunInTx(ctx, nil, func(*Tx) error {
+		attempts++
+		return errFail
+	})
+	if err != errFail || attempts != 1 {
+		t.Errorf("RunInTx = %v after %d attempts; want %v after 1", err, attempts, errFail)
+	}
+
+	attempts = 0
+	err = db.RunInTx(ctx, nil, func(*Tx) error {
+		attempts++
+		return errFakeSerialization
+	})
+	if err != errFakeSerialization || attempts != maxTxAttempts {
+		t.Errorf("RunInTx = %v after %d attempts; want %v after %d", err, attempts, errFakeSerialization, maxTxAttempts)
+	}
+}
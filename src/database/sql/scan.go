@@ -0,0 +1,323 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ScanRow copies the columns in the current row into the fields of the
+// struct pointed at by dest, matching columns to fields by name.
+//
+// A field's column name is given by its "db" struct tag, or is the field
+// name if there is no tag. A tag of "-" excludes the field. Unexported
+// fields are ignored. Column names are matched to field names exactly,
+// or otherwise case-insensitively.
+//
+// The fields of embedded structs are treated as if they were fields of the
+// outer struct, unless the embedded field has a "db" tag or its type
+// implements [Scanner]. Like in encoding/json, a field at a shallower
+// depth hides a field of the same name at a deeper one, and a tagged field
+// hides an untagged one at the same depth; other conflicting fields are
+// ignored. Nil pointers to embedded structs are allocated as needed.
+//
+// Every column must have a matching field, but not every field needs to
+// have a matching column. Fields are converted from the column values as
+// by [Rows.Scan], so they may be of any type supported by Scan, including
+// [Null] and other implementations of [Scanner].
+func (rs *Rows) ScanRow(dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("sql: ScanRow destination must be a non-nil pointer to a struct, not %T", dest)
+	}
+	columns, err := rs.Columns()
+	if err != nil {
+		return err
+	}
+	fields := cachedStructFields(v.Type().Elem())
+	args := make([]any, len(columns))
+	matched := make([]*structField, len(columns))
+	for i, col := range columns {
+		f := fields.byColumn(col)
+		if f == nil {
+			return fmt.Errorf("sql: no field for column %q in %v", col, v.Type().Elem())
+		}
+		if j := slices.Index(matched[:i], f); j >= 0 {
+			return fmt.Errorf("sql: columns %q and %q both match field %q", columns[j], col, f.name)
+		}
+		matched[i] = f
+		fv, err := fieldByIndex(v.Elem(), f.index)
+		if err != nil {
+			return err
+		}
+		args[i] = fv.Addr().Interface()
+	}
+	return rs.Scan(args...)
+}
+
+// ScanRow copies the columns from the matched row into the fields of the
+// struct pointed at by dest. See the documentation on [Rows.ScanRow] for
+// details. If more than one row matches the query, ScanRow uses the first
+// row and discards the rest. If no row matches the query, ScanRow returns
+// [ErrNoRows].
+func (r *Row) ScanRow(dest any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	// As in Row.Scan, RawBytes would point to memory that is invalidated
+	// when the rows are closed.
+	if structContainsRawBytes(reflect.TypeOf(dest)) {
+		return errors.New("sql: RawBytes isn't allowed on Row.ScanRow")
+	}
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return ErrNoRows
+	}
+	if err := r.rows.ScanRow(dest); err != nil {
+		return err
+	}
+	// Make sure the query can be processed to completion with no errors.
+	return r.rows.Close()
+}
+
+// ScanAll returns an iterator over the rows of rs, each scanned into a
+// value of type T, which is closed when the iteration stops.
+//
+// If T is a struct type that does not implement [Scanner], each row is
+// scanned as by [Rows.ScanRow]. Otherwise, the rows must have a single
+// column, which is scanned as by [Rows.Scan].
+//
+// If scanning a row fails, or if an error occurs while iterating over the
+// rows, as reported by [Rows.Err], the iterator yields the zero value of T
+// and the error, and stops. Values of type [RawBytes], and values which
+// contain them, are only valid until the next iteration.
+//
+// For example:
+//
+//	rows, err := db.QueryContext(ctx, "SELECT id, name FROM users")
+//	if err != nil {
+//		return err
+//	}
+//	for user, err := range sql.ScanAll[User](rows) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func ScanAll[T any](rs *Rows) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rs.Close()
+		scanRow := isStructScan(reflect.TypeFor[T]())
+		for rs.Next() {
+			var v T
+			var err error
+			if scanRow {
+				err = rs.ScanRow(&v)
+			} else {
+				err = rs.Scan(&v)
+			}
+			if err != nil {
+				yield(v, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := rs.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+var scannerType = reflect.TypeFor[Scanner]()
+
+// isStructScan reports whether values of type t are scanned field by field.
+func isStructScan(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// A structField is a field of a struct, or of an embedded struct, which
+// can be scanned into.
+type structField struct {
+	name   string // column name
+	index  []int  // as for reflect.Value.FieldByIndex
+	tagged bool
+}
+
+// structFields are the scannable fields of a struct type.
+type structFields struct {
+	list   []structField
+	byName map[string]*structField
+}
+
+// byColumn returns the field matching the column name, or nil.
+func (fs *structFields) byColumn(name string) *structField {
+	if f := fs.byName[name]; f != nil {
+		return f
+	}
+	for i := range fs.list {
+		if strings.EqualFold(fs.list[i].name, name) {
+			return &fs.list[i]
+		}
+	}
+	return nil
+}
+
+var structFieldCache sync.Map // map[reflect.Type]*structFields
+
+func cachedStructFields(t reflect.Type) *structFields {
+	if fs, ok := structFieldCache.Load(t); ok {
+		return fs.(*structFields)
+	}
+	fs, _ := structFieldCache.LoadOrStore(t, typeStructFields(t))
+	return fs.(*structFields)
+}
+
+// typeStructFields returns the scannable fields of the struct type t,
+// following the rules described in [Rows.ScanRow].
+func typeStructFields(t reflect.Type) *structFields {
+	// Walk the embedded structs breadth first, so that fields are
+	// collected in order of increasing depth.
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	current := []embedded{}
+	next := []embedded{{typ: t}}
+	visited := map[reflect.Type]bool{}
+
+	var candidates []structField
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := range e.typ.NumField() {
+				sf := e.typ.Field(i)
+				tag := sf.Tag.Get("db")
+				if tag == "-" {
+					continue
+				}
+				index := append(slices.Clip(e.index), i)
+				if sf.Anonymous && tag == "" {
+					ft := sf.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+						if !sf.IsExported() {
+							// A nil pointer to an unexported embedded
+							// struct could not be allocated.
+							continue
+						}
+					}
+					if isStructScan(ft) {
+						next = append(next, embedded{ft, index})
+						continue
+					}
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				name := tag
+				if name == "" {
+					name = sf.Name
+				}
+				candidates = append(candidates, structField{
+					name:   name,
+					index:  index,
+					tagged: tag != "",
+				})
+			}
+		}
+	}
+
+	// Resolve conflicting names: the shallowest field wins, then a tagged
+	// one. Otherwise, the fields are ambiguous and all are dropped.
+	slices.SortStableFunc(candidates, func(a, b structField) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+		if c := len(a.index) - len(b.index); c != 0 {
+			return c
+		}
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+	fs := &structFields{}
+	for i := 0; i < len(candidates); {
+		j := i + 1
+		for j < len(candidates) && candidates[j].name == candidates[i].name {
+			j++
+		}
+		dominant := candidates[i]
+		if j-i > 1 {
+			second := candidates[i+1]
+			if len(second.index) == len(dominant.index) && second.tagged == dominant.tagged {
+				i = j
+				continue
+			}
+		}
+		fs.list = append(fs.list, dominant)
+		i = j
+	}
+	slices.SortFunc(fs.list, func(a, b structField) int {
+		return slices.Compare(a.index, b.index)
+	})
+	fs.byName = make(map[string]*structField, len(fs.list))
+	for i := range fs.list {
+		fs.byName[fs.list[i].name] = &fs.list[i]
+	}
+	return fs
+}
+
+// fieldByIndex is like v.FieldByIndex, but allocates nil pointers to
+// embedded structs.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("sql: cannot set embedded pointer to unexported struct %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// structContainsRawBytes reports whether t, a pointer to a struct, has a
+// scannable field of type RawBytes.
+func structContainsRawBytes(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	rawBytesType := reflect.TypeFor[RawBytes]()
+	for _, f := range cachedStructFields(t.Elem()).list {
+		if t.Elem().FieldByIndex(f.index).Type == rawBytesType {
+			return true
+		}
+	}
+	return false
+}
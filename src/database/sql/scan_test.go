@@ -0,0 +1,260 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scanPerson struct {
+	Name    string
+	Age     int    `db:"age"`
+	Ignored string `db:"-"`
+	ignored int
+}
+
+func TestRowsScanRow(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	rows, err := db.Query("SELECT|people|age,name|")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	defer rows.Close()
+	var got []scanPerson
+	for rows.Next() {
+		var p scanPerson
+		if err := rows.ScanRow(&p); err != nil {
+			t.Fatalf("ScanRow: %v", err)
+		}
+		got = append(got, p)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	want := []scanPerson{
+		{Name: "Alice", Age: 1},
+		{Name: "Bob", Age: 2},
+		{Name: "Chris", Age: 3},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("mismatch.\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+type scanBase struct {
+	Name string
+	Age  int
+}
+
+type scanDates struct {
+	BDate Null[time.Time] `db:"bdate"`
+}
+
+type scanEmbedded struct {
+	scanBase
+	*scanDates `db:"-"`
+	*ScanPhoto
+	Age int64 `db:"age"` // hides scanBase.Age
+}
+
+type ScanPhoto struct {
+	Photo []byte
+}
+
+func TestRowScanRowEmbedded(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	var p scanEmbedded
+	err := db.QueryRow("SELECT|people|age,name,photo|name=?", "Chris").ScanRow(&p)
+	if err != nil {
+		t.Fatalf("ScanRow: %v", err)
+	}
+	want := scanEmbedded{
+		scanBase:  scanBase{Name: "Chris"},
+		ScanPhoto: &ScanPhoto{Photo: []byte("CPHOTO")},
+		Age:       3,
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("mismatch.\n got: %#v\nwant: %#v", p, want)
+	}
+
+	var dates struct {
+		Name string
+		scanDates
+	}
+	err = db.QueryRow("SELECT|people|name,bdate|name=?", "Chris").ScanRow(&dates)
+	if err != nil {
+		t.Fatalf("ScanRow: %v", err)
+	}
+	if dates.Name != "Chris" || !dates.BDate.Valid || !dates.BDate.V.Equal(chrisBirthday) {
+		t.Errorf("got %+v, want Chris born %v", dates, chrisBirthday)
+	}
+	err = db.QueryRow("SELECT|people|name,bdate|name=?", "Alice").ScanRow(&dates)
+	if err != nil {
+		t.Fatalf("ScanRow: %v", err)
+	}
+	if dates.Name != "Alice" || dates.BDate.Valid {
+		t.Errorf("got %+v, want Alice with a NULL birth date", dates)
+	}
+
+	err = db.QueryRow("SELECT|people|name|name=?", "Nobody").ScanRow(&dates)
+	if err != ErrNoRows {
+		t.Errorf("ScanRow with no rows: got %v, want ErrNoRows", err)
+	}
+}
+
+func TestScanRowErrors(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	tests := []struct {
+		query string
+		dest  any
+		want  string
+	}{
+		{"SELECT|people|name|", scanPerson{}, "must be a non-nil pointer to a struct"},
+		{"SELECT|people|name|", (*scanPerson)(nil), "must be a non-nil pointer to a struct"},
+		{"SELECT|people|name|", new(string), "must be a non-nil pointer to a struct"},
+		{"SELECT|people|name,photo|", new(scanPerson), `no field for column "photo"`},
+		{"SELECT|people|name,name|", new(scanPerson), `columns "name" and "name" both match field "Name"`},
+		{"SELECT|people|name|", new(struct{ Name int }), `Scan error on column index 0, name "name"`},
+		{"SELECT|people|name|", new(struct{ Name RawBytes }), "RawBytes isn't allowed"},
+	}
+	for _, tt := range tests {
+		err := db.QueryRow(tt.query).ScanRow(tt.dest)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ScanRow(%T) for %q: got %v, want error containing %q", tt.dest, tt.query, err, tt.want)
+		}
+	}
+
+	// Ambiguous fields at the same depth are ignored.
+	type A struct{ Name string }
+	type B struct{ Name string }
+	var ambiguous struct {
+		A
+		B
+	}
+	err := db.QueryRow("SELECT|people|name|").ScanRow(&ambiguous)
+	if err == nil || !strings.Contains(err.Error(), `no field for column "name"`) {
+		t.Errorf("ScanRow with ambiguous fields: got %v", err)
+	}
+}
+
+func TestScanAll(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	rows, err := db.Query("SELECT|people|age,name|")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	var got []scanPerson
+	for p, err := range ScanAll[scanPerson](rows) {
+		if err != nil {
+			t.Fatalf("ScanAll: %v", err)
+		}
+		got = append(got, p)
+	}
+	want := []scanPerson{
+		{Name: "Alice", Age: 1},
+		{Name: "Bob", Age: 2},
+		{Name: "Chris", Age: 3},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("mismatch.\n got: %#v\nwant: %#v", got, want)
+	}
+
+	// Single columns are scanned directly, including into Scanners.
+	rows, err = db.Query("SELECT|people|bdate|")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	var valid []bool
+	for d, err := range ScanAll[Null[time.Time]](rows) {
+		if err != nil {
+			t.Fatalf("ScanAll: %v", err)
+		}
+		valid = append(valid, d.Valid)
+	}
+	if want := []bool{false, false, true}; !slices.Equal(valid, want) {
+		t.Errorf("got valid %v, want %v", valid, want)
+	}
+}
+
+func TestScanAllBreak(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	rows, err := db.Query("SELECT|people|name|")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	for name, err := range ScanAll[string](rows) {
+		if err != nil {
+			t.Fatalf("ScanAll: %v", err)
+		}
+		if name != "Alice" {
+			t.Errorf("got %q, want Alice", name)
+		}
+		break
+	}
+	if err := rows.Err(); err != nil {
+		t.Errorf("Err after break: %v", err)
+	}
+	if rows.Next() {
+		t.Error("rows not closed after break")
+	}
+	if n := db.numFreeConns(); n != 1 {
+		t.Errorf("free conns after break = %d; want 1", n)
+	}
+}
+
+func TestScanAllError(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+
+	rows, err := db.Query("SELECT|people|age,name|")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	n := 0
+	for _, err := range ScanAll[int](rows) {
+		n++
+		if err == nil || !strings.Contains(err.Error(), "expected 2 destination arguments") {
+			t.Errorf("got %v, want a Scan error", err)
+		}
+	}
+	if n != 1 {
+		t.Errorf("iterator yielded %d times after an error, want 1", n)
+	}
+
+	// Errors from Rows.Err are yielded last.
+	rows, err = db.Query("SELECT|people|name|")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	fail := errors.New("fail")
+	r := rows.rowsi.(*rowsCursor)
+	r.errPos, r.err = 2, fail
+	var names []string
+	var gotErr error
+	for name, err := range ScanAll[string](rows) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) != 2 || gotErr != fail {
+		t.Errorf("got %q and error %v, want 2 names and %v", names, gotErr, fail)
+	}
+}
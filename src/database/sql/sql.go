@@ -509,11 +509,19 @@ type DB struct {
 	waitDuration atomic.Int64
 
 	connector driver.Connector
+	hook      atomic.Pointer[Hook]
 	// numClosed is an atomic counter which represents a total number of
 	// closed connections. Stmt.openStmt checks it before cleaning closed
 	// connections in Stmt.css.
 	numClosed atomic.Uint64
 
+	// Statement cache settings and counters; see SetStmtCacheSize.
+	stmtCacheSize      atomic.Int64
+	stmtCacheUsed      atomic.Bool // whether statements have ever been cached
+	stmtCacheHits      atomic.Int64
+	stmtCacheMisses    atomic.Int64
+	stmtCacheEvictions atomic.Int64
+
 	mu           sync.Mutex    // protects following fields
 	freeConn     []*driverConn // free connections ordered by returnedAt oldest to newest
 	connRequests connRequestSet
@@ -566,6 +574,7 @@ type driverConn struct {
 	closed      bool
 	finalClosed bool // ci.Close has been called
 	openStmt    map[*driverStmt]bool
+	stmtCache   *stmtCache // statements cached by SetStmtCacheSize, or nil
 
 	// guarded by db.mu
 	inUse      bool
@@ -690,6 +699,7 @@ func (dc *driverConn) finalClose() error {
 	for _, ds := range openStmt {
 		ds.Close()
 	}
+	dc.closeStmtCache()
 	withLock(dc, func() {
 		dc.finalClosed = true
 		err = dc.ci.Close()
@@ -829,6 +839,8 @@ func (t dsnConnector) Driver() driver.Driver {
 // and maintains its own pool of idle connections. Thus, the OpenDB
 // function should be called just once. It is rarely necessary to
 // close a [DB].
+//
+// If c also implements [Hook], it is set as the hook of the returned [DB].
 func OpenDB(c driver.Connector) *DB {
 	ctx, cancel := context.WithCancel(context.Background())
 	db := &DB{
@@ -837,6 +849,9 @@ func OpenDB(c driver.Connector) *DB {
 		lastPut:   make(map[*driverConn]string),
 		stop:      cancel,
 	}
+	if h, ok := c.(Hook); ok {
+		db.SetHook(h)
+	}
 
 	go db.connectionOpener(ctx)
 
@@ -896,7 +911,7 @@ func (db *DB) PingContext(ctx context.Context) error {
 	var dc *driverConn
 	var err error
 
-	err = db.retry(func(strategy connReuseStrategy) error {
+	err = db.retry(nil, func(strategy connReuseStrategy) error {
 		dc, err = db.conn(ctx, strategy)
 		return err
 	})
@@ -1209,6 +1224,11 @@ type DBStats struct {
 	MaxIdleClosed     int64         // The total number of connections closed due to SetMaxIdleConns.
 	MaxIdleTimeClosed int64         // The total number of connections closed due to SetConnMaxIdleTime.
 	MaxLifetimeClosed int64         // The total number of connections closed due to SetConnMaxLifetime.
+
+	// Statement cache counters; see SetStmtCacheSize.
+	StmtCacheHits      int64 // The total number of queries run with a cached statement.
+	StmtCacheMisses    int64 // The total number of statements prepared for the cache.
+	StmtCacheEvictions int64 // The total number of statements evicted from the cache.
 }
 
 // Stats returns database statistics.
@@ -1230,6 +1250,10 @@ func (db *DB) Stats() DBStats {
 		MaxIdleClosed:     db.maxIdleClosed,
 		MaxIdleTimeClosed: db.maxIdleTimeClosed,
 		MaxLifetimeClosed: db.maxLifetimeClosed,
+
+		StmtCacheHits:      db.stmtCacheHits.Load(),
+		StmtCacheMisses:    db.stmtCacheMisses.Load(),
+		StmtCacheEvictions: db.stmtCacheEvictions.Load(),
 	}
 	return stats
 }
@@ -1312,8 +1336,8 @@ type connRequest struct {
 
 var errDBClosed = errors.New("sql: database is closed")
 
-// conn returns a newly-opened or cached *driverConn.
-func (db *DB) conn(ctx context.Context, strategy connReuseStrategy) (*driverConn, error) {
+// getConn returns a newly-opened or cached *driverConn.
+func (db *DB) getConn(ctx context.Context, strategy connReuseStrategy) (*driverConn, error) {
 	db.mu.Lock()
 	if db.closed {
 		db.mu.Unlock()
@@ -1571,13 +1595,14 @@ func (db *DB) putConnDBLocked(dc *driverConn, err error) bool {
 // connection to be opened.
 const maxBadConnRetries = 2
 
-func (db *DB) retry(fn func(strategy connReuseStrategy) error) error {
+func (db *DB) retry(op *hookOp, fn func(strategy connReuseStrategy) error) error {
 	for i := int64(0); i < maxBadConnRetries; i++ {
 		err := fn(cachedOrNewConn)
 		// retry if err is driver.ErrBadConn
 		if err == nil || !errors.Is(err, driver.ErrBadConn) {
 			return err
 		}
+		op.noteRetry()
 	}
 
 	return fn(alwaysNewConn)
@@ -1595,10 +1620,12 @@ func (db *DB) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
 	var stmt *Stmt
 	var err error
 
-	err = db.retry(func(strategy connReuseStrategy) error {
+	ctx, op := db.startOp(ctx, OpPrepare, query, 0)
+	err = db.retry(op, func(strategy connReuseStrategy) error {
 		stmt, err = db.prepare(ctx, query, strategy)
 		return err
 	})
+	op.end(err)
 
 	return stmt, err
 }
@@ -1668,10 +1695,12 @@ func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (Resul
 	var res Result
 	var err error
 
-	err = db.retry(func(strategy connReuseStrategy) error {
+	ctx, op := db.startOp(ctx, OpExec, query, len(args))
+	err = db.retry(op, func(strategy connReuseStrategy) error {
 		res, err = db.exec(ctx, query, args, strategy)
 		return err
 	})
+	op.endExec(res, err)
 
 	return res, err
 }
@@ -1697,6 +1726,14 @@ func (db *DB) execDC(ctx context.Context, dc *driverConn, release func(error), q
 	defer func() {
 		release(err)
 	}()
+	cs, err := db.cachedStmt(ctx, dc, query)
+	if err != nil {
+		return nil, err
+	}
+	if cs != nil {
+		defer dc.releaseCachedStmt(cs)
+		return resultFromStatement(ctx, dc.ci, cs.ds, args...)
+	}
 	execerCtx, ok := dc.ci.(driver.ExecerContext)
 	var execer driver.Execer
 	if !ok {
@@ -1738,10 +1775,12 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*Row
 	var rows *Rows
 	var err error
 
-	err = db.retry(func(strategy connReuseStrategy) error {
+	ctx, op := db.startOp(ctx, OpQuery, query, len(args))
+	err = db.retry(op, func(strategy connReuseStrategy) error {
 		rows, err = db.query(ctx, query, args, strategy)
 		return err
 	})
+	op.end(err)
 
 	return rows, err
 }
@@ -1769,6 +1808,31 @@ func (db *DB) query(ctx context.Context, query string, args []any, strategy conn
 // The ctx context is from a query method and the txctx context is from an
 // optional transaction context.
 func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn func(error), query string, args []any) (*Rows, error) {
+	cs, err := db.cachedStmt(ctx, dc, query)
+	if err != nil {
+		releaseConn(err)
+		return nil, err
+	}
+	if cs != nil {
+		rowsi, err := rowsiFromStatement(ctx, dc.ci, cs.ds, args...)
+		if err != nil {
+			dc.releaseCachedStmt(cs)
+			releaseConn(err)
+			return nil, err
+		}
+		rows := &Rows{
+			dc: dc,
+			releaseConn: func(err error) {
+				dc.releaseCachedStmt(cs)
+				releaseConn(err)
+			},
+			rowsi: rowsi,
+			op:    startRows(ctx, query),
+		}
+		rows.initContextClose(ctx, txctx)
+		return rows, nil
+	}
+
 	queryerCtx, ok := dc.ci.(driver.QueryerContext)
 	var queryer driver.Queryer
 	if !ok {
@@ -1796,6 +1860,7 @@ func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn fu
 				dc:          dc,
 				releaseConn: releaseConn,
 				rowsi:       rowsi,
+				op:          startRows(ctx, query),
 			}
 			rows.initContextClose(ctx, txctx)
 			return rows, nil
@@ -1803,7 +1868,6 @@ func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn fu
 	}
 
 	var si driver.Stmt
-	var err error
 	withLock(dc, func() {
 		si, err = ctxDriverPrepare(ctx, dc.ci, query)
 	})
@@ -1827,6 +1891,7 @@ func (db *DB) queryDC(ctx, txctx context.Context, dc *driverConn, releaseConn fu
 		releaseConn: releaseConn,
 		rowsi:       rowsi,
 		closeStmt:   ds,
+		op:          startRows(ctx, query),
 	}
 	rows.initContextClose(ctx, txctx)
 	return rows, nil
@@ -1870,10 +1935,12 @@ func (db *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
 	var tx *Tx
 	var err error
 
-	err = db.retry(func(strategy connReuseStrategy) error {
-		tx, err = db.begin(ctx, opts, strategy)
+	hctx, op := db.startOp(ctx, OpBegin, "", 0)
+	err = db.retry(op, func(strategy connReuseStrategy) error {
+		tx, err = db.begin(hctx, ctx, opts, strategy)
 		return err
 	})
+	op.end(err)
 
 	return tx, err
 }
@@ -1887,23 +1954,25 @@ func (db *DB) Begin() (*Tx, error) {
 	return db.BeginTx(context.Background(), nil)
 }
 
-func (db *DB) begin(ctx context.Context, opts *TxOptions, strategy connReuseStrategy) (tx *Tx, err error) {
-	dc, err := db.conn(ctx, strategy)
+func (db *DB) begin(hctx, ctx context.Context, opts *TxOptions, strategy connReuseStrategy) (tx *Tx, err error) {
+	dc, err := db.conn(hctx, strategy)
 	if err != nil {
 		return nil, err
 	}
-	return db.beginDC(ctx, dc, dc.releaseConn, opts)
+	return db.beginDC(hctx, ctx, dc, dc.releaseConn, opts)
 }
 
 // beginDC starts a transaction. The provided dc must be valid and ready to use.
-func (db *DB) beginDC(ctx context.Context, dc *driverConn, release func(error), opts *TxOptions) (tx *Tx, err error) {
+// The transaction is begun with hctx, which differs from the context of the
+// transaction, ctx, when the operation is observed by a Hook.
+func (db *DB) beginDC(hctx, ctx context.Context, dc *driverConn, release func(error), opts *TxOptions) (tx *Tx, err error) {
 	var txi driver.Tx
 	keepConnOnRollback := false
 	withLock(dc, func() {
 		_, hasSessionResetter := dc.ci.(driver.SessionResetter)
 		_, hasConnectionValidator := dc.ci.(driver.Validator)
 		keepConnOnRollback = hasSessionResetter && hasConnectionValidator
-		txi, err = ctxDriverBegin(ctx, opts, dc.ci)
+		txi, err = ctxDriverBegin(hctx, opts, dc.ci)
 	})
 	if err != nil {
 		release(err)
@@ -1946,7 +2015,7 @@ func (db *DB) Conn(ctx context.Context) (*Conn, error) {
 	var dc *driverConn
 	var err error
 
-	err = db.retry(func(strategy connReuseStrategy) error {
+	err = db.retry(nil, func(strategy connReuseStrategy) error {
 		dc, err = db.conn(ctx, strategy)
 		return err
 	})
@@ -2019,7 +2088,9 @@ func (c *Conn) PingContext(ctx context.Context) error {
 
 // ExecContext executes a query without returning any rows.
 // The args are for any placeholder parameters in the query.
-func (c *Conn) ExecContext(ctx context.Context, query string, args ...any) (Result, error) {
+func (c *Conn) ExecContext(ctx context.Context, query string, args ...any) (res Result, err error) {
+	ctx, op := c.db.startOp(ctx, OpExec, query, len(args))
+	defer func() { op.endExec(res, err) }()
 	dc, release, err := c.grabConn(ctx)
 	if err != nil {
 		return nil, err
@@ -2029,7 +2100,9 @@ func (c *Conn) ExecContext(ctx context.Context, query string, args ...any) (Resu
 
 // QueryContext executes a query that returns rows, typically a SELECT.
 // The args are for any placeholder parameters in the query.
-func (c *Conn) QueryContext(ctx context.Context, query string, args ...any) (*Rows, error) {
+func (c *Conn) QueryContext(ctx context.Context, query string, args ...any) (rows *Rows, err error) {
+	ctx, op := c.db.startOp(ctx, OpQuery, query, len(args))
+	defer func() { op.end(err) }()
 	dc, release, err := c.grabConn(ctx)
 	if err != nil {
 		return nil, err
@@ -2056,7 +2129,9 @@ func (c *Conn) QueryRowContext(ctx context.Context, query string, args ...any) *
 //
 // The provided context is used for the preparation of the statement, not for the
 // execution of the statement.
-func (c *Conn) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+func (c *Conn) PrepareContext(ctx context.Context, query string) (stmt *Stmt, err error) {
+	ctx, op := c.db.startOp(ctx, OpPrepare, query, 0)
+	defer func() { op.end(err) }()
 	dc, release, err := c.grabConn(ctx)
 	if err != nil {
 		return nil, err
@@ -2107,12 +2182,14 @@ func (c *Conn) Raw(f func(driverConn any) error) (err error) {
 // The provided [TxOptions] is optional and may be nil if defaults should be used.
 // If a non-default isolation level is used that the driver doesn't support,
 // an error will be returned.
-func (c *Conn) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
-	dc, release, err := c.grabConn(ctx)
+func (c *Conn) BeginTx(ctx context.Context, opts *TxOptions) (tx *Tx, err error) {
+	hctx, op := c.db.startOp(ctx, OpBegin, "", 0)
+	defer func() { op.end(err) }()
+	dc, release, err := c.grabConn(hctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.db.beginDC(ctx, dc, release, opts)
+	return c.db.beginDC(hctx, ctx, dc, release, opts)
 }
 
 // closemuRUnlockCondReleaseConn read unlocks closemu
@@ -2200,6 +2277,10 @@ type Tx struct {
 	// cancel is called after done transitions from 0 to 1.
 	cancel func()
 
+	// nested is the number of savepoints created by Nested, used to
+	// name them.
+	nested atomic.Uint64
+
 	// ctx lives for the life of the transaction.
 	ctx context.Context
 }
@@ -2284,7 +2365,10 @@ func (tx *Tx) closePrepared() {
 }
 
 // Commit commits the transaction.
-func (tx *Tx) Commit() error {
+func (tx *Tx) Commit() (err error) {
+	_, op := tx.db.startOp(tx.ctx, OpCommit, "", 0)
+	defer func() { op.end(err) }()
+
 	// Check context first to avoid transaction leak.
 	// If put it behind tx.done CompareAndSwap statement, we can't ensure
 	// the consistency between tx.done and the real COMMIT operation.
@@ -2308,7 +2392,6 @@ func (tx *Tx) Commit() error {
 	tx.closemu.Lock()
 	tx.closemu.Unlock()
 
-	var err error
 	withLock(tx.dc, func() {
 		err = tx.txi.Commit()
 	})
@@ -2323,10 +2406,12 @@ var rollbackHook func()
 
 // rollback aborts the transaction and optionally forces the pool to discard
 // the connection.
-func (tx *Tx) rollback(discardConn bool) error {
+func (tx *Tx) rollback(discardConn bool) (err error) {
 	if !tx.done.CompareAndSwap(false, true) {
 		return ErrTxDone
 	}
+	_, op := tx.db.startOp(tx.ctx, OpRollback, "", 0)
+	defer func() { op.end(err) }()
 
 	if rollbackHook != nil {
 		rollbackHook()
@@ -2340,7 +2425,6 @@ func (tx *Tx) rollback(discardConn bool) error {
 	tx.closemu.Lock()
 	tx.closemu.Unlock()
 
-	var err error
 	withLock(tx.dc, func() {
 		err = tx.txi.Rollback()
 	})
@@ -2369,13 +2453,15 @@ func (tx *Tx) Rollback() error {
 // The provided context will be used for the preparation of the context, not
 // for the execution of the returned statement. The returned statement
 // will run in the transaction context.
-func (tx *Tx) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (stmt *Stmt, err error) {
+	ctx, op := tx.db.startOp(ctx, OpPrepare, query, 0)
+	defer func() { op.end(err) }()
 	dc, release, err := tx.grabConn(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	stmt, err := tx.db.prepareDC(ctx, dc, release, tx, query)
+	stmt, err = tx.db.prepareDC(ctx, dc, release, tx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -2508,7 +2594,9 @@ func (tx *Tx) Stmt(stmt *Stmt) *Stmt {
 
 // ExecContext executes a query that doesn't return rows.
 // For example: an INSERT and UPDATE.
-func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (Result, error) {
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (res Result, err error) {
+	ctx, op := tx.db.startOp(ctx, OpExec, query, len(args))
+	defer func() { op.endExec(res, err) }()
 	dc, release, err := tx.grabConn(ctx)
 	if err != nil {
 		return nil, err
@@ -2526,7 +2614,9 @@ func (tx *Tx) Exec(query string, args ...any) (Result, error) {
 }
 
 // QueryContext executes a query that returns rows, typically a SELECT.
-func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*Rows, error) {
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (rows *Rows, err error) {
+	ctx, op := tx.db.startOp(ctx, OpQuery, query, len(args))
+	defer func() { op.end(err) }()
 	dc, release, err := tx.grabConn(ctx)
 	if err != nil {
 		return nil, err
@@ -2645,7 +2735,8 @@ func (s *Stmt) ExecContext(ctx context.Context, args ...any) (Result, error) {
 	defer s.closemu.RUnlock()
 
 	var res Result
-	err := s.db.retry(func(strategy connReuseStrategy) error {
+	ctx, op := s.db.startOp(ctx, OpExec, s.query, len(args))
+	err := s.db.retry(op, func(strategy connReuseStrategy) error {
 		dc, releaseConn, ds, err := s.connStmt(ctx, strategy)
 		if err != nil {
 			return err
@@ -2655,6 +2746,7 @@ func (s *Stmt) ExecContext(ctx context.Context, args ...any) (Result, error) {
 		releaseConn(err)
 		return err
 	})
+	op.endExec(res, err)
 
 	return res, err
 }
@@ -2789,7 +2881,8 @@ func (s *Stmt) QueryContext(ctx context.Context, args ...any) (*Rows, error) {
 	var rowsi driver.Rows
 	var rows *Rows
 
-	err := s.db.retry(func(strategy connReuseStrategy) error {
+	ctx, op := s.db.startOp(ctx, OpQuery, s.query, len(args))
+	err := s.db.retry(op, func(strategy connReuseStrategy) error {
 		dc, releaseConn, ds, err := s.connStmt(ctx, strategy)
 		if err != nil {
 			return err
@@ -2802,6 +2895,7 @@ func (s *Stmt) QueryContext(ctx context.Context, args ...any) (*Rows, error) {
 			rows = &Rows{
 				dc:    dc,
 				rowsi: rowsi,
+				op:    startRows(ctx, s.query),
 				// releaseConn set below
 			}
 			// addDep must be added before initContextClose or it could attempt
@@ -2825,6 +2919,7 @@ func (s *Stmt) QueryContext(ctx context.Context, args ...any) (*Rows, error) {
 		releaseConn(err)
 		return err
 	})
+	op.end(err)
 
 	return rows, err
 }
@@ -2932,6 +3027,7 @@ type Rows struct {
 	rowsi       driver.Rows
 	cancel      func()      // called when Rows is closed, may be nil.
 	closeStmt   *driverStmt // if non-nil, statement to Close on close
+	op          *hookOp     // if non-nil, the OpRows operation, ended on close
 
 	contextDone atomic.Pointer[error] // error that awaitDone saw; set before close attempt
 
@@ -3081,6 +3177,9 @@ func (rs *Rows) nextLocked() (doClose, ok bool) {
 		}
 		return doClose, false
 	}
+	if rs.op != nil {
+		rs.op.ev.RowsRead++
+	}
 	return false, true
 }
 
@@ -3470,6 +3569,13 @@ func (rs *Rows) close(err error) error {
 	rs.releaseConn(err)
 
 	rs.lasterr = rs.lasterrOrErrLocked(err)
+	if rs.op != nil {
+		rowsErr := rs.lasterr
+		if rowsErr == io.EOF {
+			rowsErr = nil
+		}
+		rs.op.end(rowsErr)
+	}
 	return err
 }
 
@@ -0,0 +1,140 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"container/list"
+	"context"
+	"database/sql/driver"
+)
+
+// SetStmtCacheSize sets the maximum number of prepared statements cached on
+// each connection.
+//
+// When n > 0, the queries run by the Exec and Query methods of [DB], [Conn]
+// and [Tx] are prepared on their connection and the prepared statements are
+// kept for reuse by later calls with the same query text, even if the
+// driver can execute queries without preparing them. When a connection has
+// more than n cached statements, the least recently used ones are closed.
+//
+// If n <= 0, which is the default, statements are not cached, and
+// statements already cached are closed as their connections are next used.
+// Statements created by [DB.Prepare] and the other Prepare methods are not
+// affected.
+func (db *DB) SetStmtCacheSize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	db.stmtCacheSize.Store(int64(n))
+	if n > 0 {
+		db.stmtCacheUsed.Store(true)
+	}
+}
+
+// A stmtCache is an LRU cache of the statements prepared on a connection.
+// It is guarded by the driverConn's lock.
+type stmtCache struct {
+	lru     list.List // of *cachedStmt, most recently used first
+	byQuery map[string]*list.Element
+}
+
+// A cachedStmt is a statement of a stmtCache.
+type cachedStmt struct {
+	query   string
+	ds      *driverStmt
+	refs    int  // number of running executions and open Rows
+	evicted bool // removed from the cache, to close once refs is 0
+}
+
+// cachedStmt returns the cached statement for query on dc, preparing it if
+// needed, or nil if statements are not cached. The caller must call
+// releaseCachedStmt when done with the statement.
+func (db *DB) cachedStmt(ctx context.Context, dc *driverConn, query string) (*cachedStmt, error) {
+	if !db.stmtCacheUsed.Load() {
+		return nil, nil
+	}
+	size := int(db.stmtCacheSize.Load())
+	var (
+		cs    *cachedStmt
+		err   error
+		close []*driverStmt
+	)
+	withLock(dc, func() {
+		c := dc.stmtCache
+		if c == nil {
+			if size == 0 {
+				return
+			}
+			c = &stmtCache{byQuery: make(map[string]*list.Element)}
+			dc.stmtCache = c
+		}
+		if size > 0 {
+			if e := c.byQuery[query]; e != nil {
+				c.lru.MoveToFront(e)
+				cs = e.Value.(*cachedStmt)
+				cs.refs++
+				db.stmtCacheHits.Add(1)
+				return
+			}
+			var si driver.Stmt
+			si, err = ctxDriverPrepare(ctx, dc.ci, query)
+			if err != nil {
+				return
+			}
+			db.stmtCacheMisses.Add(1)
+			cs = &cachedStmt{query: query, ds: &driverStmt{Locker: dc, si: si}, refs: 1}
+			c.byQuery[query] = c.lru.PushFront(cs)
+		}
+		for c.lru.Len() > size {
+			old := c.lru.Remove(c.lru.Back()).(*cachedStmt)
+			delete(c.byQuery, old.query)
+			db.stmtCacheEvictions.Add(1)
+			old.evicted = true
+			if old.refs == 0 {
+				close = append(close, old.ds)
+			}
+		}
+		if c.lru.Len() == 0 {
+			dc.stmtCache = nil
+		}
+	})
+	// driverStmt.Close acquires the lock of dc.
+	for _, ds := range close {
+		ds.Close()
+	}
+	return cs, err
+}
+
+// releaseCachedStmt releases a statement returned by cachedStmt, closing
+// it if it was evicted from the cache while in use.
+func (dc *driverConn) releaseCachedStmt(cs *cachedStmt) {
+	var closeStmt bool
+	withLock(dc, func() {
+		cs.refs--
+		if cs.evicted && cs.refs == 0 {
+			closeStmt = true
+		}
+	})
+	if closeStmt {
+		cs.ds.Close()
+	}
+}
+
+// closeStmtCache closes the cached statements of dc, which is being closed.
+func (dc *driverConn) closeStmtCache() {
+	var stmts []*driverStmt
+	withLock(dc, func() {
+		if dc.stmtCache == nil {
+			return
+		}
+		for e := dc.stmtCache.lru.Front(); e != nil; e = e.Next() {
+			stmts = append(stmts, e.Value.(*cachedStmt).ds)
+		}
+		dc.stmtCache = nil
+	})
+	for _, ds := range stmts {
+		ds.Close()
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"testing"
+)
+
+// openStmts returns the number of statements open on the single
+// connection of db.
+func openStmts(t *testing.T, db *DB) int {
+	t.Helper()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if n := len(db.freeConn); n != 1 {
+		t.Fatalf("free conns = %d; want 1", n)
+	}
+	fc := db.freeConn[0].ci.(*fakeConn)
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.stmtsMade - fc.stmtsClosed
+}
+
+func TestStmtCache(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	db.SetMaxOpenConns(1)
+	db.SetStmtCacheSize(2)
+
+	prepares0 := numPrepares(t, db)
+	for i := range 3 {
+		var name string
+		if err := db.QueryRow("SELECT|people|name|age=?", 2).Scan(&name); err != nil || name != "Bob" {
+			t.Fatalf("QueryRow = %q, %v; want Bob", name, err)
+		}
+		exec(t, db, "INSERT|people|name=?,age=?", "Dave", i)
+	}
+	if n := numPrepares(t, db) - prepares0; n != 2 {
+		t.Errorf("statements prepared = %d; want 2", n)
+	}
+	stats := db.Stats()
+	if stats.StmtCacheHits != 4 || stats.StmtCacheMisses != 2 || stats.StmtCacheEvictions != 0 {
+		t.Errorf("stats = %+v; want 4 hits, 2 misses, no eviction", stats)
+	}
+
+	// Statements are evicted by least recent use.
+	exec(t, db, "INSERT|people|name=?,age=?", "Eve", 5)
+	exec(t, db, "INSERT|people|name=Fred,age=6")
+	exec(t, db, "INSERT|people|name=?,age=?", "Gina", 7)
+	stats = db.Stats()
+	if stats.StmtCacheHits != 6 || stats.StmtCacheMisses != 3 || stats.StmtCacheEvictions != 1 {
+		t.Errorf("stats = %+v; want 6 hits, 3 misses, 1 eviction", stats)
+	}
+	if n := openStmts(t, db); n != 2 {
+		t.Errorf("open statements = %d; want 2", n)
+	}
+
+	// Disabling the cache closes the cached statements.
+	db.SetStmtCacheSize(0)
+	exec(t, db, "INSERT|people|name=Hal,age=8")
+	if n := openStmts(t, db); n != 0 {
+		t.Errorf("open statements after disabling the cache = %d; want 0", n)
+	}
+	if got := db.Stats().StmtCacheEvictions; got != 3 {
+		t.Errorf("evictions = %d; want 3", got)
+	}
+}
+
+func TestStmtCacheEvictInUse(t *testing.T) {
+	db := newTestDB(t, "people")
+	defer closeDB(t, db)
+	db.SetMaxOpenConns(1)
+	db.SetStmtCacheSize(1)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT|people|name|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT|people|name=Dave,age=4"); err != nil {
+		t.Fatal(err)
+	}
+	// The statement of rows is evicted, but closed only with rows.
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 {
+		t.Errorf("names = %v; want 3 names", names)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if n := openStmts(t, db); n != 1 {
+		t.Errorf("open statements = %d; want 1", n)
+	}
+	if got := db.Stats().StmtCacheEvictions; got != 1 {
+		t.Errorf("evictions = %d; want 1", got)
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Marshaler is implemented by types that can marshal themselves into
+// a single valid CBOR data item.
+//
+// It is recommended that types implement [MarshalerTo] unless the
+// implementation is trying to avoid a hard dependency on this package.
+type Marshaler interface {
+	MarshalCBOR() ([]byte, error)
+}
+
+// MarshalerTo is implemented by types that can marshal themselves by
+// writing exactly one data item to the provided [Encoder].
+type MarshalerTo interface {
+	MarshalCBORTo(*Encoder) error
+}
+
+// Unmarshaler is implemented by types that can unmarshal themselves from
+// a single valid CBOR data item. The input can be assumed to be valid
+// according to the options in effect. UnmarshalCBOR must copy the input
+// if it wishes to retain it after returning.
+//
+// It is recommended that types implement [UnmarshalerFrom] unless the
+// implementation is trying to avoid a hard dependency on this package.
+type Unmarshaler interface {
+	UnmarshalCBOR([]byte) error
+}
+
+// UnmarshalerFrom is implemented by types that can unmarshal themselves
+// by reading exactly one data item from the provided [Decoder].
+type UnmarshalerFrom interface {
+	UnmarshalCBORFrom(*Decoder) error
+}
+
+// Tag represents a tagged data item of RFC 8949, section 3.4.
+//
+// When unmarshaling into a Tag, the tag content is unmarshaled into
+// Content if it holds a non-nil pointer, and into a new Go value as for
+// an empty interface otherwise.
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// Simple represents a simple value of RFC 8949, section 3.3.
+// Simple values 24 to 31 are reserved and cannot be marshaled.
+type Simple uint8
+
+// Marshal serializes a Go value as a CBOR data item according to the
+// provided marshal options.
+//
+// Marshaling is based on the type of each Go value. In order of
+// precedence:
+//
+//   - Functions provided with [WithMarshalers] are used first.
+//   - A [Value] is written as is, after checking that it is valid.
+//     A [Tag] is marshaled as a tagged data item and a [Simple] as a
+//     simple value.
+//   - A [math/big.Int] is marshaled as an integer if it fits in 64 bits,
+//     and as a bignum (tag 2 or 3) otherwise.
+//   - A [time.Time] is marshaled as an epoch-based date/time (tag 1),
+//     with an integer number of seconds if it has no fractional seconds,
+//     and a floating-point number otherwise.
+//   - A type implementing [MarshalerTo], [Marshaler],
+//     [encoding.TextAppender] or [encoding.TextMarshaler] uses that
+//     method, in that order. Text is marshaled as a text string.
+//
+// Otherwise, Go values are marshaled as follows:
+//
+//   - A Go bool is marshaled as a CBOR boolean.
+//   - A Go integer is marshaled as a CBOR unsigned or negative integer.
+//   - A Go float is marshaled as a CBOR floating-point number in the
+//     shortest form that represents it exactly.
+//   - A Go string is marshaled as a CBOR text string.
+//   - A Go []byte or [N]byte is marshaled as a CBOR byte string.
+//   - Other Go slices and arrays are marshaled as CBOR arrays.
+//     A nil slice is marshaled as an empty array or byte string,
+//     unless [FormatNilSliceAsNull] is specified.
+//   - A Go map is marshaled as a CBOR map, which is sorted if
+//     [Deterministic] or [CoreDeterministic] is specified.
+//     A nil map is marshaled as an empty map,
+//     unless [FormatNilMapAsNull] is specified.
+//   - A Go struct is marshaled as a CBOR map from the names of its
+//     exported fields to their values, or as a CBOR array of the values
+//     of its fields if it has a field declared as
+//     "_ struct{} `cbor:",toarray"`".
+//   - A Go pointer or interface is marshaled as the value it refers to,
+//     or as CBOR null if it is nil.
+//   - Go channels, functions and complex numbers are not supported.
+//
+// The encoding of each struct field can be customized by the format string
+// stored under the "cbor" key in the struct field's tag.
+// The format string gives the name of the field, possibly followed by a
+// comma-separated list of options. The name may be empty to specify
+// options without overriding the Go field name. A field with the tag
+// "-" is ignored. The options are:
+//
+//   - omitzero: The field is omitted if it is the zero Go value,
+//     or if it implements an "IsZero() bool" method that reports true.
+//   - omitempty: The field is omitted if it is a nil pointer or
+//     interface, or an empty string, slice, map or array.
+//   - keyasint: The name is a decimal integer, which is used as the map
+//     key instead of a text string, as is common in protocols such as
+//     COSE (RFC 9052).
+//
+// Embedded structs without a name in their tag have their fields
+// promoted, following the same rules as encoding/json.
+func Marshal(in any, opts ...Options) (out []byte, err error) {
+	e := NewEncoder(nil, opts...)
+	if err := marshalEncode(e, in); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// MarshalWrite serializes a Go value into an [io.Writer] according to the
+// provided marshal and encode options. See [Marshal] for details about
+// the conversion of a Go value into CBOR.
+func MarshalWrite(out io.Writer, in any, opts ...Options) error {
+	return marshalEncode(NewEncoder(out, opts...), in)
+}
+
+// MarshalEncode serializes a Go value into an [Encoder] according to the
+// provided marshal options, which are combined with the options of the
+// encoder. See [Marshal] for details about the conversion of a Go value
+// into CBOR.
+func MarshalEncode(out *Encoder, in any, opts ...Options) error {
+	if len(opts) > 0 {
+		defer out.withOptions(opts)()
+	}
+	return marshalEncode(out, in)
+}
+
+// withOptions combines opts with the options of the encoder,
+// and returns a function restoring them.
+func (e *Encoder) withOptions(opts []Options) func() {
+	saved := e.opts
+	e.opts.join(opts...)
+	e.allowDup, e.trackOffsets = e.opts.get(optAllowDuplicateKeys), e.opts.sortKeys()
+	return func() {
+		e.opts = saved
+		e.allowDup, e.trackOffsets = saved.get(optAllowDuplicateKeys), saved.sortKeys()
+	}
+}
+
+func marshalEncode(e *Encoder, in any) error {
+	v := reflect.ValueOf(in)
+	if !v.IsValid() {
+		return e.WriteToken(Null)
+	}
+	// Make the value addressable, so that methods declared
+	// on the pointer receiver are used.
+	va := reflect.New(v.Type()).Elem()
+	va.Set(v)
+	return marshalValue(e, va)
+}
+
+// Unmarshal decodes a single CBOR data item from in into a Go value
+// according to the provided unmarshal and decode options. The output
+// must be a non-nil pointer. The input must be exactly one data item,
+// with no trailing data.
+//
+// Unmarshaling uses the inverse of the rules described in [Marshal],
+// with the following additions:
+//
+//   - CBOR null and undefined unmarshal as the zero Go value.
+//   - Tags are ignored when unmarshaling into Go types other than [Tag],
+//     [Value], [math/big.Int], [time.Time] and the empty interface; the
+//     tag content is unmarshaled instead. A [time.Time] can be
+//     unmarshaled from a standard date/time string (tag 0) or an
+//     epoch-based date/time (tag 1), which yields a time in UTC.
+//   - A CBOR map is unmarshaled into a Go struct by matching text string
+//     keys against field names and integer keys against "keyasint"
+//     fields. Unknown keys are skipped unless [RejectUnknownFields] is
+//     specified.
+//   - A CBOR array unmarshaled into a Go array must have the same length.
+//     Unmarshaling into a Go slice or map replaces its elements.
+//   - A CBOR data item is unmarshaled into an empty interface as a Go
+//     bool, uint64 for unsigned integers, int64 for negative integers
+//     ([*math/big.Int] if they do not fit, or for bignums), float64,
+//     []byte, string, []any, map[any]any, [Tag], [Simple], or nil for
+//     null and undefined. A map key that cannot be a Go map key, such
+//     as a byte string, is an error.
+//   - If a Go interface holds a non-nil pointer, the data item is
+//     unmarshaled into the value it points to.
+func Unmarshal(in []byte, out any, opts ...Options) error {
+	var o options
+	o.join(opts...)
+	return unmarshalFull(newBytesDecoder(in, &o), out)
+}
+
+// UnmarshalRead deserializes a Go value from an [io.Reader] according to
+// the provided unmarshal and decode options. The input must be exactly one
+// data item, followed by the end of the input. See [Unmarshal] for details
+// about the conversion of CBOR into a Go value.
+func UnmarshalRead(in io.Reader, out any, opts ...Options) error {
+	return unmarshalFull(NewDecoder(in, opts...), out)
+}
+
+func unmarshalFull(d *Decoder, out any) error {
+	switch err := unmarshalDecode(d, out); err {
+	case nil:
+		return d.checkEOF()
+	case io.EOF:
+		return d.syntaxError(io.ErrUnexpectedEOF, d.InputOffset())
+	default:
+		return err
+	}
+}
+
+// UnmarshalDecode deserializes a Go value from a [Decoder] according to
+// the provided unmarshal options, which are combined with the options of
+// the decoder. It reads exactly one data item, and returns [io.EOF] if
+// there are no more data items. See [Unmarshal] for details about the
+// conversion of CBOR into a Go value.
+func UnmarshalDecode(in *Decoder, out any, opts ...Options) error {
+	if len(opts) > 0 {
+		saved := in.opts
+		in.opts.join(opts...)
+		in.allowDup = in.opts.get(optAllowDuplicateKeys)
+		defer func() {
+			in.opts = saved
+			in.allowDup = saved.get(optAllowDuplicateKeys)
+		}()
+	}
+	return unmarshalDecode(in, out)
+}
+
+var errNonPointer = errors.New("output must be a non-nil pointer")
+
+func unmarshalDecode(d *Decoder, out any) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return &SemanticError{action: "unmarshal", ByteOffset: d.InputOffset(), GoType: reflect.TypeOf(out), Err: errNonPointer}
+	}
+	if _, _, err := d.peekToken(); err != nil {
+		return err
+	}
+	depth := len(d.stack)
+	err := unmarshalValue(d, v.Elem())
+	if _, ok := err.(*SemanticError); ok {
+		// Skip the rest of the data item, so that
+		// the decoder can be used for the next one.
+		for len(d.stack) > depth {
+			if _, _, err := d.next(); err != nil {
+				break
+			}
+		}
+	}
+	return err
+}
+
+// arshaler marshals and unmarshals Go values of a particular type.
+type arshaler struct {
+	marshal   func(*Encoder, reflect.Value) error
+	unmarshal func(*Decoder, reflect.Value) error
+
+	// needAddr reports whether the marshal methods of the type are
+	// declared on the pointer receiver.
+	needAddr bool
+}
+
+var arshalers sync.Map // map[reflect.Type]*arshaler
+
+func lookupArshaler(t reflect.Type) *arshaler {
+	if a, ok := arshalers.Load(t); ok {
+		return a.(*arshaler)
+	}
+	a := makeArshaler(t)
+	if a, loaded := arshalers.LoadOrStore(t, a); loaded {
+		return a.(*arshaler)
+	}
+	return a
+}
+
+// marshalValue marshals v, which is addressable if it is
+// an element of an addressable value.
+func marshalValue(e *Encoder, v reflect.Value) error {
+	if m := e.opts.marshalers; m != nil {
+		if err := m.marshal(e, v); err != SkipFunc {
+			return err
+		}
+	}
+	a := lookupArshaler(v.Type())
+	if a.needAddr && !v.CanAddr() {
+		va := reflect.New(v.Type()).Elem()
+		va.Set(v)
+		v = va
+	}
+	return a.marshal(e, v)
+}
+
+// unmarshalValue unmarshals into v, which must be addressable.
+func unmarshalValue(d *Decoder, v reflect.Value) error {
+	if u := d.opts.unmarshalers; u != nil {
+		if err := u.unmarshal(d, v); err != SkipFunc {
+			return err
+		}
+	}
+	return lookupArshaler(v.Type()).unmarshal(d, v)
+}
+
+// watchValue calls f, which is supposed to write or read exactly one
+// data item with s, and reports an error if it does not.
+func watchValue(s *state, f func() error) error {
+	depth, items := len(s.stack), s.items()
+	if err := f(); err != nil {
+		return err
+	}
+	switch {
+	case len(s.stack) > depth:
+		return errIncomplete
+	case len(s.stack) == depth && s.items() == items:
+		return errNoValue
+	case len(s.stack) == depth && s.items() > items+1:
+		return errMultipleValues
+	}
+	return nil
+}
+
+func newMarshalError(e *Encoder, t reflect.Type, k Kind, err error) error {
+	return &SemanticError{action: "marshal", ByteOffset: e.OutputOffset(), GoType: t, CBORKind: k, Err: err}
+}
+
+func newUnmarshalError(off int64, t reflect.Type, k Kind, err error) error {
+	return &SemanticError{action: "unmarshal", ByteOffset: off, GoType: t, CBORKind: k, Err: err}
+}
+
+// wrapError wraps an error returned by a method or function provided by
+// the user into a SemanticError, unless it is already an error of this
+// package.
+func wrapError(err error, action string, off int64, t reflect.Type) error {
+	switch err.(type) {
+	case nil, *SemanticError, *SyntacticError, *ioError:
+		return err
+	}
+	return &SemanticError{action: action, ByteOffset: off, GoType: t, Err: err}
+}
@@ -0,0 +1,1056 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+var (
+	valueType  = reflect.TypeFor[Value]()
+	tagType    = reflect.TypeFor[Tag]()
+	simpleType = reflect.TypeFor[Simple]()
+	bigIntType = reflect.TypeFor[big.Int]()
+	timeType   = reflect.TypeFor[time.Time]()
+
+	marshalerType       = reflect.TypeFor[Marshaler]()
+	marshalerToType     = reflect.TypeFor[MarshalerTo]()
+	unmarshalerType     = reflect.TypeFor[Unmarshaler]()
+	unmarshalerFromType = reflect.TypeFor[UnmarshalerFrom]()
+	textAppenderType    = reflect.TypeFor[encoding.TextAppender]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Tag numbers with a meaning defined by RFC 8949, section 3.4.
+const (
+	tagDateTimeString = 0
+	tagEpochDateTime  = 1
+	tagUnsignedBignum = 2
+	tagNegativeBignum = 3
+)
+
+var (
+	errOverflow       = errors.New("value out of range")
+	errUnsupported    = errors.New("unsupported type")
+	errArrayLength    = errors.New("mismatching array length")
+	errNonEmptyIface  = errors.New("cannot derive concrete type for non-empty interface")
+	errNonComparable  = errors.New("map key is not comparable")
+	errEmbeddedNilPtr = errors.New("cannot set embedded pointer to unexported struct")
+)
+
+func makeArshaler(t reflect.Type) *arshaler {
+	switch t {
+	case valueType:
+		return &arshaler{marshal: marshalRawValue, unmarshal: unmarshalRawValue}
+	case simpleType:
+		return &arshaler{marshal: marshalSimple, unmarshal: unmarshalSimple}
+	case tagType:
+		return &arshaler{marshal: marshalTag, unmarshal: nullable(unmarshalTag)}
+	case bigIntType:
+		return &arshaler{marshal: marshalBigInt, unmarshal: nullable(unmarshalBigInt), needAddr: true}
+	case timeType:
+		return &arshaler{marshal: marshalTime, unmarshal: nullable(unmarshalTime)}
+	}
+	a := makeDefaultArshaler(t)
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface {
+		return a
+	}
+	pt := reflect.PointerTo(t)
+
+	// Marshal methods, in order of precedence.
+	implements := func(it reflect.Type) bool {
+		if t.Implements(it) {
+			return true
+		}
+		if pt.Implements(it) {
+			a.needAddr = true
+			return true
+		}
+		return false
+	}
+	method := func(v reflect.Value) any {
+		if a.needAddr {
+			return v.Addr().Interface()
+		}
+		return v.Interface()
+	}
+	switch {
+	case implements(marshalerToType):
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			off := e.OutputOffset()
+			err := watchValue(&e.state, func() error {
+				return method(v).(MarshalerTo).MarshalCBORTo(e)
+			})
+			return wrapError(err, "marshal", off, t)
+		}
+	case implements(marshalerType):
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			b, err := method(v).(Marshaler).MarshalCBOR()
+			if err != nil {
+				return wrapError(err, "marshal", e.OutputOffset(), t)
+			}
+			return e.WriteValue(b)
+		}
+	case implements(textAppenderType):
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			b, err := method(v).(encoding.TextAppender).AppendText(nil)
+			if err != nil {
+				return wrapError(err, "marshal", e.OutputOffset(), t)
+			}
+			return e.WriteToken(String(string(b)))
+		}
+	case implements(textMarshalerType):
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			b, err := method(v).(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return wrapError(err, "marshal", e.OutputOffset(), t)
+			}
+			return e.WriteToken(String(string(b)))
+		}
+	}
+
+	// Unmarshal methods, in order of precedence.
+	// Unmarshaling always operates on addressable values.
+	switch {
+	case pt.Implements(unmarshalerFromType):
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			err := watchValue(&d.state, func() error {
+				return v.Addr().Interface().(UnmarshalerFrom).UnmarshalCBORFrom(d)
+			})
+			return wrapError(err, "unmarshal", off, t)
+		}
+	case pt.Implements(unmarshalerType):
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			b, err := d.ReadValue()
+			if err != nil {
+				return err
+			}
+			err = v.Addr().Interface().(Unmarshaler).UnmarshalCBOR(b)
+			return wrapError(err, "unmarshal", off, t)
+		}
+	case pt.Implements(textUnmarshalerType):
+		a.unmarshal = nullable(func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, s, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			if tok.kind != KindString {
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			if s, err = readString(d, tok, s); err != nil {
+				return err
+			}
+			err = v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(s)
+			return wrapError(err, "unmarshal", off, t)
+		})
+	}
+	return a
+}
+
+func makeDefaultArshaler(t reflect.Type) *arshaler {
+	var a arshaler
+	switch t.Kind() {
+	case reflect.Bool:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			return e.WriteToken(Bool(v.Bool()))
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, _, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			if tok.kind != KindBool {
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			v.SetBool(tok.arg != 0)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			return e.WriteToken(Int(v.Int()))
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, _, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			var n int64
+			switch tok.kind {
+			case KindUint:
+				n = int64(tok.arg)
+			case KindNegInt:
+				n = -1 - int64(tok.arg)
+			default:
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			if tok.arg > math.MaxInt64 || v.OverflowInt(n) {
+				return newUnmarshalError(off, t, tok.kind, errOverflow)
+			}
+			v.SetInt(n)
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			return e.WriteToken(Uint(v.Uint()))
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, _, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			switch {
+			case tok.kind == KindNegInt:
+				return newUnmarshalError(off, t, tok.kind, errOverflow)
+			case tok.kind != KindUint:
+				return newUnmarshalError(off, t, tok.kind, nil)
+			case v.OverflowUint(tok.arg):
+				return newUnmarshalError(off, t, tok.kind, errOverflow)
+			}
+			v.SetUint(tok.arg)
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			return e.WriteToken(Float(v.Float()))
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, _, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			switch tok.kind {
+			case KindFloat, KindUint, KindNegInt:
+			default:
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			f := tok.Float()
+			if v.OverflowFloat(f) {
+				return newUnmarshalError(off, t, tok.kind, errOverflow)
+			}
+			v.SetFloat(f)
+			return nil
+		}
+
+	case reflect.String:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			return e.WriteToken(String(v.String()))
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, s, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			if tok.kind != KindString {
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			if s, err = readString(d, tok, s); err != nil {
+				return err
+			}
+			v.SetString(string(s))
+			return nil
+		}
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !hasMethods(t.Elem()) {
+			a.marshal = func(e *Encoder, v reflect.Value) error {
+				if v.IsNil() && e.opts.get(optFormatNilSliceAsNull) {
+					return e.WriteToken(Null)
+				}
+				return e.WriteToken(Bytes(v.Bytes()))
+			}
+			a.unmarshal = func(d *Decoder, v reflect.Value) error {
+				off := d.InputOffset()
+				tok, s, err := readUntagged(d)
+				if err != nil {
+					return err
+				}
+				if tok.kind != KindBytes {
+					return newUnmarshalError(off, t, tok.kind, nil)
+				}
+				if s, err = readString(d, tok, s); err != nil {
+					return err
+				}
+				v.SetBytes(append([]byte{}, s...))
+				return nil
+			}
+			break
+		}
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			if v.IsNil() && e.opts.get(optFormatNilSliceAsNull) {
+				return e.WriteToken(Null)
+			}
+			if err := e.WriteToken(ArrayStart(v.Len())); err != nil {
+				return err
+			}
+			for i := range v.Len() {
+				if err := marshalValue(e, v.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, _, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			if tok.kind != KindArray {
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			if v.IsNil() {
+				v.Set(reflect.MakeSlice(t, 0, 0))
+			}
+			v.SetLen(0)
+			return readItems(d, tok, func(i int) error {
+				if i == v.Cap() {
+					v.Grow(1)
+				}
+				v.SetLen(i + 1)
+				elem := v.Index(i)
+				elem.SetZero()
+				return unmarshalValue(d, elem)
+			})
+		}
+
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && !hasMethods(t.Elem()) {
+			a.marshal = func(e *Encoder, v reflect.Value) error {
+				b := make([]byte, v.Len())
+				reflect.Copy(reflect.ValueOf(b), v)
+				return e.WriteToken(Bytes(b))
+			}
+			a.unmarshal = func(d *Decoder, v reflect.Value) error {
+				off := d.InputOffset()
+				tok, s, err := readUntagged(d)
+				if err != nil {
+					return err
+				}
+				if tok.kind != KindBytes {
+					return newUnmarshalError(off, t, tok.kind, nil)
+				}
+				if s, err = readString(d, tok, s); err != nil {
+					return err
+				}
+				if len(s) != v.Len() {
+					return newUnmarshalError(off, t, tok.kind, errArrayLength)
+				}
+				reflect.Copy(v, reflect.ValueOf(s))
+				return nil
+			}
+			break
+		}
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			if err := e.WriteToken(ArrayStart(v.Len())); err != nil {
+				return err
+			}
+			for i := range v.Len() {
+				if err := marshalValue(e, v.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, _, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			if tok.kind != KindArray {
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			n := 0
+			err = readItems(d, tok, func(i int) error {
+				if i >= v.Len() {
+					return newUnmarshalError(off, t, tok.kind, errArrayLength)
+				}
+				n++
+				elem := v.Index(i)
+				elem.SetZero()
+				return unmarshalValue(d, elem)
+			})
+			if err == nil && n != v.Len() {
+				err = newUnmarshalError(off, t, tok.kind, errArrayLength)
+			}
+			return err
+		}
+
+	case reflect.Map:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			if v.IsNil() && e.opts.get(optFormatNilMapAsNull) {
+				return e.WriteToken(Null)
+			}
+			if err := e.WriteToken(MapStart(v.Len())); err != nil {
+				return err
+			}
+			for iter := v.MapRange(); iter.Next(); {
+				if err := marshalValue(e, iter.Key()); err != nil {
+					return err
+				}
+				if err := marshalValue(e, iter.Value()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			off := d.InputOffset()
+			tok, _, err := readUntagged(d)
+			if err != nil {
+				return err
+			}
+			if tok.kind != KindMap {
+				return newUnmarshalError(off, t, tok.kind, nil)
+			}
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(t))
+			}
+			v.Clear()
+			return readEntries(d, tok, func() error {
+				keyOff := d.InputOffset()
+				key := reflect.New(t.Key()).Elem()
+				if err := unmarshalValue(d, key); err != nil {
+					return err
+				}
+				if !key.Comparable() {
+					return newUnmarshalError(keyOff, t, KindInvalid, errNonComparable)
+				}
+				val := reflect.New(t.Elem()).Elem()
+				if err := unmarshalValue(d, val); err != nil {
+					return err
+				}
+				v.SetMapIndex(key, val)
+				return nil
+			})
+		}
+
+	case reflect.Struct:
+		var fields *structFields
+		var fieldsErr error
+		init := func() {
+			if fields == nil && fieldsErr == nil {
+				fields, fieldsErr = makeStructFields(t)
+			}
+		}
+		// The fields are computed lazily, so that recursive types
+		// do not recursively construct their arshalers.
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			init()
+			if fieldsErr != nil {
+				return newMarshalError(e, t, KindInvalid, fieldsErr)
+			}
+			return marshalStruct(e, v, fields)
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			init()
+			if fieldsErr != nil {
+				return newUnmarshalError(d.InputOffset(), t, KindInvalid, fieldsErr)
+			}
+			return unmarshalStruct(d, v, fields)
+		}
+
+	case reflect.Pointer:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			if v.IsNil() {
+				return e.WriteToken(Null)
+			}
+			if e.ptrDepth++; e.ptrDepth > maxNestingDepth {
+				return newMarshalError(e, t, KindInvalid, errMaxDepth)
+			}
+			defer func() { e.ptrDepth-- }()
+			return marshalValue(e, v.Elem())
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			if v.IsNil() {
+				v.Set(reflect.New(t.Elem()))
+			}
+			return unmarshalValue(d, v.Elem())
+		}
+
+	case reflect.Interface:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			if v.IsNil() {
+				return e.WriteToken(Null)
+			}
+			if e.ptrDepth++; e.ptrDepth > maxNestingDepth {
+				return newMarshalError(e, t, KindInvalid, errMaxDepth)
+			}
+			defer func() { e.ptrDepth-- }()
+			return marshalValue(e, v.Elem())
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			if !v.IsNil() && v.Elem().Kind() == reflect.Pointer && !v.Elem().IsNil() {
+				return unmarshalValue(d, v.Elem().Elem())
+			}
+			if t.NumMethod() > 0 {
+				return newUnmarshalError(d.InputOffset(), t, d.PeekKind(), errNonEmptyIface)
+			}
+			x, err := unmarshalAny(d)
+			if err != nil {
+				return err
+			}
+			if x == nil {
+				v.SetZero()
+			} else {
+				v.Set(reflect.ValueOf(x))
+			}
+			return nil
+		}
+
+	default:
+		a.marshal = func(e *Encoder, v reflect.Value) error {
+			return newMarshalError(e, t, KindInvalid, errUnsupported)
+		}
+		a.unmarshal = func(d *Decoder, v reflect.Value) error {
+			return newUnmarshalError(d.InputOffset(), t, d.PeekKind(), errUnsupported)
+		}
+	}
+	if t.Kind() != reflect.Interface {
+		a.unmarshal = nullable(a.unmarshal)
+	}
+	return &a
+}
+
+// hasMethods reports whether t implements any of the marshal
+// or unmarshal interfaces, in which case a slice of t is not
+// treated as a byte string.
+func hasMethods(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	for _, it := range []reflect.Type{marshalerType, marshalerToType, textAppenderType, textMarshalerType} {
+		if t.Implements(it) {
+			return true
+		}
+	}
+	for _, it := range []reflect.Type{unmarshalerType, unmarshalerFromType, textUnmarshalerType} {
+		if pt.Implements(it) {
+			return true
+		}
+	}
+	return false
+}
+
+// nullable returns an unmarshal function that sets the Go value to zero
+// for CBOR null and undefined, and otherwise calls f.
+func nullable(f func(*Decoder, reflect.Value) error) func(*Decoder, reflect.Value) error {
+	return func(d *Decoder, v reflect.Value) error {
+		if k := d.PeekKind(); k == KindNull || k == KindUndefined {
+			if _, _, err := d.next(); err != nil {
+				return err
+			}
+			v.SetZero()
+			return nil
+		}
+		return f(d, v)
+	}
+}
+
+// readUntagged reads the next token, skipping any tags.
+func readUntagged(d *Decoder) (Token, []byte, error) {
+	for {
+		t, b, err := d.next()
+		if err != nil || t.kind != KindTag {
+			return t, b, err
+		}
+	}
+}
+
+// readString returns the content of a byte or text string that started
+// with t and s, concatenating the chunks of an indefinite-length string.
+// For a definite-length string, the result aliases the decoder buffer.
+func readString(d *Decoder, t Token, s []byte) ([]byte, error) {
+	if !t.indef {
+		return s, nil
+	}
+	var b []byte
+	for {
+		t, s, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == KindBreak {
+			if b == nil {
+				b = []byte{}
+			}
+			return b, nil
+		}
+		b = append(b, s...)
+	}
+}
+
+// readItems calls f for each element of the array that started with t.
+func readItems(d *Decoder, t Token, f func(i int) error) error {
+	for i := 0; t.indef || uint64(i) < t.arg; i++ {
+		if t.indef && d.PeekKind() == KindBreak {
+			_, _, err := d.next()
+			return err
+		}
+		if err := f(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readEntries calls f for each entry of the map that started with t.
+func readEntries(d *Decoder, t Token, f func() error) error {
+	return readItems(d, Token{indef: t.indef, arg: t.arg}, func(int) error { return f() })
+}
+
+func marshalStruct(e *Encoder, v reflect.Value, sf *structFields) error {
+	if sf.toArray {
+		if err := e.WriteToken(ArrayStart(len(sf.fields))); err != nil {
+			return err
+		}
+		for i := range sf.fields {
+			fv, ok := fieldByIndex(v, sf.fields[i].index)
+			if !ok {
+				if err := e.WriteToken(Null); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := marshalValue(e, fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	type entry struct {
+		f *structField
+		v reflect.Value
+	}
+	entries := make([]entry, 0, len(sf.fields))
+	for i := range sf.fields {
+		f := &sf.fields[i]
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || f.omitzero && isZero(fv) || f.omitempty && isEmpty(fv) {
+			continue
+		}
+		entries = append(entries, entry{f, fv})
+	}
+	if err := e.WriteToken(MapStart(len(entries))); err != nil {
+		return err
+	}
+	for _, x := range entries {
+		if err := e.WriteToken(x.f.keyToken()); err != nil {
+			return err
+		}
+		if err := marshalValue(e, x.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalStruct(d *Decoder, v reflect.Value, sf *structFields) error {
+	off := d.InputOffset()
+	t := v.Type()
+	tok, _, err := readUntagged(d)
+	if err != nil {
+		return err
+	}
+	if sf.toArray {
+		if tok.kind != KindArray {
+			return newUnmarshalError(off, t, tok.kind, nil)
+		}
+		n := 0
+		err := readItems(d, tok, func(i int) error {
+			if i >= len(sf.fields) {
+				return newUnmarshalError(off, t, tok.kind, errArrayLength)
+			}
+			n++
+			fv, err := fieldByIndexAlloc(v, sf.fields[i].index)
+			if err != nil {
+				return newUnmarshalError(off, t, tok.kind, err)
+			}
+			return unmarshalValue(d, fv)
+		})
+		if err == nil && n != len(sf.fields) {
+			err = newUnmarshalError(off, t, tok.kind, errArrayLength)
+		}
+		return err
+	}
+
+	if tok.kind != KindMap {
+		return newUnmarshalError(off, t, tok.kind, nil)
+	}
+	return readEntries(d, tok, func() error {
+		keyOff := d.InputOffset()
+		var f *structField
+		switch d.PeekKind() {
+		case KindString:
+			kt, s, err := d.next()
+			if err != nil {
+				return err
+			}
+			if s, err = readString(d, kt, s); err != nil {
+				return err
+			}
+			f = sf.byName[string(s)]
+		case KindUint, KindNegInt:
+			kt, _, err := d.next()
+			if err != nil {
+				return err
+			}
+			if kt.arg <= math.MaxInt64 {
+				f = sf.byInt[kt.Int()]
+			}
+		default:
+			if err := d.SkipValue(); err != nil {
+				return err
+			}
+		}
+		if f == nil {
+			if d.opts.get(optRejectUnknownFields) {
+				return newUnmarshalError(keyOff, t, KindInvalid, ErrUnknownField)
+			}
+			return d.SkipValue()
+		}
+		fv, err := fieldByIndexAlloc(v, f.index)
+		if err != nil {
+			return newUnmarshalError(keyOff, t, KindInvalid, err)
+		}
+		return unmarshalValue(d, fv)
+	})
+}
+
+// fieldByIndex returns the field of v with the given index sequence,
+// reporting false if it is within a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldByIndexAlloc is like fieldByIndex,
+// but allocates nil embedded pointers.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, errEmbeddedNilPtr
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeFor[isZeroer]()
+
+// isZero reports whether v is zero for the omitzero option.
+func isZero(v reflect.Value) bool {
+	switch t := v.Type(); {
+	case (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil():
+		return true
+	case t.Implements(isZeroerType):
+		return v.Interface().(isZeroer).IsZero()
+	case reflect.PointerTo(t).Implements(isZeroerType) && v.CanAddr():
+		return v.Addr().Interface().(isZeroer).IsZero()
+	}
+	return v.IsZero()
+}
+
+// isEmpty reports whether v is empty for the omitempty option.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func marshalRawValue(e *Encoder, v reflect.Value) error {
+	return e.WriteValue(v.Bytes())
+}
+
+func unmarshalRawValue(d *Decoder, v reflect.Value) error {
+	b, err := d.ReadValue()
+	if err != nil {
+		return err
+	}
+	v.SetBytes(bytes.Clone(b))
+	return nil
+}
+
+func marshalSimple(e *Encoder, v reflect.Value) error {
+	return e.WriteToken(SimpleValue(uint8(v.Uint())))
+}
+
+func unmarshalSimple(d *Decoder, v reflect.Value) error {
+	off := d.InputOffset()
+	t, _, err := readUntagged(d)
+	if err != nil {
+		return err
+	}
+	switch t.kind {
+	case KindSimple, KindBool, KindNull, KindUndefined:
+		v.SetUint(uint64(t.SimpleValue()))
+		return nil
+	}
+	return newUnmarshalError(off, v.Type(), t.kind, nil)
+}
+
+func marshalTag(e *Encoder, v reflect.Value) error {
+	tag := v.Interface().(Tag)
+	if err := e.WriteToken(TagNumber(tag.Number)); err != nil {
+		return err
+	}
+	if tag.Content == nil {
+		return e.WriteToken(Null)
+	}
+	return marshalValue(e, reflect.ValueOf(tag.Content))
+}
+
+func unmarshalTag(d *Decoder, v reflect.Value) error {
+	off := d.InputOffset()
+	t, _, err := d.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != KindTag {
+		return newUnmarshalError(off, v.Type(), t.kind, nil)
+	}
+	v.Field(0).SetUint(t.arg)
+	content := v.Field(1)
+	if c := content.Elem(); c.Kind() == reflect.Pointer && !c.IsNil() {
+		return unmarshalValue(d, c.Elem())
+	}
+	x, err := unmarshalAny(d)
+	if err != nil {
+		return err
+	}
+	if x == nil {
+		content.SetZero()
+	} else {
+		content.Set(reflect.ValueOf(x))
+	}
+	return nil
+}
+
+func marshalBigInt(e *Encoder, v reflect.Value) error {
+	x := v.Addr().Interface().(*big.Int)
+	tag := uint64(tagUnsignedBignum)
+	if x.Sign() < 0 {
+		// A negative integer or bignum encodes -1-x.
+		x = new(big.Int).Not(x)
+		tag = tagNegativeBignum
+	}
+	if x.IsUint64() {
+		if tag == tagNegativeBignum {
+			return e.WriteToken(NegInt(x.Uint64()))
+		}
+		return e.WriteToken(Uint(x.Uint64()))
+	}
+	if err := e.WriteToken(TagNumber(tag)); err != nil {
+		return err
+	}
+	return e.WriteToken(Bytes(x.Bytes()))
+}
+
+func unmarshalBigInt(d *Decoder, v reflect.Value) error {
+	x, err := readBigInt(d, v.Type())
+	if err != nil {
+		return err
+	}
+	v.Addr().Interface().(*big.Int).Set(x)
+	return nil
+}
+
+// readBigInt reads an integer or a bignum.
+func readBigInt(d *Decoder, t reflect.Type) (*big.Int, error) {
+	off := d.InputOffset()
+	tok, _, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.kind {
+	case KindUint:
+		return new(big.Int).SetUint64(tok.arg), nil
+	case KindNegInt:
+		x := new(big.Int).SetUint64(tok.arg)
+		return x.Not(x), nil
+	case KindTag:
+		if tok.arg == tagUnsignedBignum || tok.arg == tagNegativeBignum {
+			return readBignum(d, tok.arg == tagNegativeBignum, off, t)
+		}
+	}
+	return nil, newUnmarshalError(off, t, tok.kind, nil)
+}
+
+// readBignum reads the content of a bignum, following its tag at off.
+func readBignum(d *Decoder, neg bool, off int64, t reflect.Type) (*big.Int, error) {
+	ct, s, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	if ct.kind != KindBytes {
+		return nil, newUnmarshalError(off, t, ct.kind, nil)
+	}
+	if s, err = readString(d, ct, s); err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(s)
+	if neg {
+		x.Not(x)
+	}
+	return x, nil
+}
+
+func marshalTime(e *Encoder, v reflect.Value) error {
+	t := v.Interface().(time.Time)
+	if err := e.WriteToken(TagNumber(tagEpochDateTime)); err != nil {
+		return err
+	}
+	if t.Nanosecond() == 0 {
+		return e.WriteToken(Int(t.Unix()))
+	}
+	return e.WriteToken(Float(float64(t.Unix()) + float64(t.Nanosecond())/1e9))
+}
+
+func unmarshalTime(d *Decoder, v reflect.Value) error {
+	off := d.InputOffset()
+	t, s, err := d.next()
+	if err != nil {
+		return err
+	}
+	tag := uint64(math.MaxUint64)
+	if t.kind == KindTag {
+		tag = t.arg
+		if t, s, err = d.next(); err != nil {
+			return err
+		}
+	}
+	var tm time.Time
+	switch {
+	case t.kind == KindString && (tag == tagDateTimeString || tag == math.MaxUint64):
+		if s, err = readString(d, t, s); err != nil {
+			return err
+		}
+		if tm, err = time.Parse(time.RFC3339Nano, string(s)); err != nil {
+			return newUnmarshalError(off, v.Type(), t.kind, err)
+		}
+	case (t.kind == KindUint || t.kind == KindNegInt) && (tag == tagEpochDateTime || tag == math.MaxUint64):
+		if t.arg > math.MaxInt64 {
+			return newUnmarshalError(off, v.Type(), t.kind, errOverflow)
+		}
+		tm = time.Unix(t.Int(), 0).UTC()
+	case t.kind == KindFloat && (tag == tagEpochDateTime || tag == math.MaxUint64):
+		f := t.Float()
+		sec := math.Floor(f)
+		if !(math.MinInt64 <= sec && sec < math.MaxInt64) {
+			return newUnmarshalError(off, v.Type(), t.kind, errOverflow)
+		}
+		tm = time.Unix(int64(sec), int64(math.Round((f-sec)*1e9))).UTC()
+	default:
+		return newUnmarshalError(off, v.Type(), t.kind, nil)
+	}
+	v.Set(reflect.ValueOf(tm))
+	return nil
+}
+
+// unmarshalAny unmarshals the next data item as a value
+// of one of the Go types used for the empty interface.
+func unmarshalAny(d *Decoder) (any, error) {
+	off := d.InputOffset()
+	t, s, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case KindUint:
+		return t.arg, nil
+	case KindNegInt:
+		if t.arg > math.MaxInt64 {
+			x := new(big.Int).SetUint64(t.arg)
+			return x.Not(x), nil
+		}
+		return -1 - int64(t.arg), nil
+	case KindBytes:
+		if s, err = readString(d, t, s); err != nil {
+			return nil, err
+		}
+		return append([]byte{}, s...), nil
+	case KindString:
+		if s, err = readString(d, t, s); err != nil {
+			return nil, err
+		}
+		return string(s), nil
+	case KindArray:
+		a := []any{}
+		err := readItems(d, t, func(int) error {
+			x, err := unmarshalAny(d)
+			a = append(a, x)
+			return err
+		})
+		return a, err
+	case KindMap:
+		m := map[any]any{}
+		err := readEntries(d, t, func() error {
+			keyOff := d.InputOffset()
+			k, err := unmarshalAny(d)
+			if err != nil {
+				return err
+			}
+			if !reflect.ValueOf(k).Comparable() {
+				return newUnmarshalError(keyOff, reflect.TypeOf(k), KindInvalid, errNonComparable)
+			}
+			x, err := unmarshalAny(d)
+			m[k] = x
+			return err
+		})
+		return m, err
+	case KindTag:
+		if t.arg == tagUnsignedBignum || t.arg == tagNegativeBignum {
+			return readBignum(d, t.arg == tagNegativeBignum, off, bigIntType)
+		}
+		x, err := unmarshalAny(d)
+		return Tag{Number: t.arg, Content: x}, err
+	case KindBool:
+		return t.arg != 0, nil
+	case KindSimple:
+		return Simple(t.arg), nil
+	case KindFloat:
+		return t.Float(), nil
+	}
+	return nil, nil // null and undefined
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Marshalers is a list of functions that may override the marshal behavior
+// of specific types. Populate it with [MarshalFunc], [MarshalToFunc] or
+// [JoinMarshalers] and use it with [WithMarshalers].
+// A nil *Marshalers is equivalent to an empty list.
+//
+// The functions are tried in order for each Go value. A function applies
+// to a value of type T, or, if T is an interface type, to any value whose
+// type implements T. A function may return [SkipFunc] to defer to the
+// next applicable function, or to the default behavior.
+type Marshalers struct {
+	fns []typedFunc[Encoder]
+}
+
+// Unmarshalers is a list of functions that may override the unmarshal
+// behavior of specific types. Populate it with [UnmarshalFunc],
+// [UnmarshalFromFunc] or [JoinUnmarshalers] and use it with
+// [WithUnmarshalers]. A nil *Unmarshalers is equivalent to an empty list.
+//
+// The functions are tried in order for each Go value. A function for the
+// pointer type *T applies to values of type T, while a function for an
+// interface type applies to any value whose pointer implements it.
+// A function may return [SkipFunc] to defer to the next applicable
+// function, or to the default behavior.
+type Unmarshalers struct {
+	fns []typedFunc[Decoder]
+}
+
+type typedFunc[Coder any] struct {
+	typ reflect.Type
+	fn  func(*Coder, reflect.Value) error
+}
+
+// JoinMarshalers constructs a flattened list of marshal functions.
+// If multiple functions in the list are applicable for a value of a
+// given type, then those earlier in the list take precedence.
+func JoinMarshalers(ms ...*Marshalers) *Marshalers {
+	m := new(Marshalers)
+	for _, x := range ms {
+		if x != nil {
+			m.fns = append(m.fns, x.fns...)
+		}
+	}
+	return m
+}
+
+// JoinUnmarshalers constructs a flattened list of unmarshal functions.
+// If multiple functions in the list are applicable for a value of a
+// given type, then those earlier in the list take precedence.
+func JoinUnmarshalers(us ...*Unmarshalers) *Unmarshalers {
+	u := new(Unmarshalers)
+	for _, x := range us {
+		if x != nil {
+			u.fns = append(u.fns, x.fns...)
+		}
+	}
+	return u
+}
+
+// MarshalFunc constructs a type-specific marshaler that specifies how to
+// marshal values of type T. The function must return a single valid CBOR
+// data item.
+func MarshalFunc[T any](fn func(T) ([]byte, error)) *Marshalers {
+	t := reflect.TypeFor[T]()
+	return &Marshalers{fns: []typedFunc[Encoder]{{t, func(e *Encoder, v reflect.Value) error {
+		b, err := fn(v.Interface().(T))
+		if err != nil {
+			return err
+		}
+		return e.WriteValue(b)
+	}}}}
+}
+
+// MarshalToFunc constructs a type-specific marshaler that specifies how to
+// marshal values of type T. The function must write exactly one data item
+// to the provided [Encoder].
+func MarshalToFunc[T any](fn func(*Encoder, T) error) *Marshalers {
+	t := reflect.TypeFor[T]()
+	return &Marshalers{fns: []typedFunc[Encoder]{{t, func(e *Encoder, v reflect.Value) error {
+		return fn(e, v.Interface().(T))
+	}}}}
+}
+
+// UnmarshalFunc constructs a type-specific unmarshaler that specifies how
+// to unmarshal values of type T. T must be a pointer or interface type.
+// The function is called with a single valid CBOR data item, and must
+// copy it if it wishes to retain it after returning.
+func UnmarshalFunc[T any](fn func([]byte, T) error) *Unmarshalers {
+	t := reflect.TypeFor[T]()
+	mustBePointerOrInterface(t)
+	return &Unmarshalers{fns: []typedFunc[Decoder]{{t, func(d *Decoder, v reflect.Value) error {
+		b, err := d.ReadValue()
+		if err != nil {
+			return err
+		}
+		return fn(b, v.Addr().Interface().(T))
+	}}}}
+}
+
+// UnmarshalFromFunc constructs a type-specific unmarshaler that specifies
+// how to unmarshal values of type T. T must be a pointer or interface
+// type. The function must read exactly one data item from the provided
+// [Decoder].
+func UnmarshalFromFunc[T any](fn func(*Decoder, T) error) *Unmarshalers {
+	t := reflect.TypeFor[T]()
+	mustBePointerOrInterface(t)
+	return &Unmarshalers{fns: []typedFunc[Decoder]{{t, func(d *Decoder, v reflect.Value) error {
+		return fn(d, v.Addr().Interface().(T))
+	}}}}
+}
+
+func mustBePointerOrInterface(t reflect.Type) {
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface {
+		panic("cbor: invalid unmarshal function type " + t.String() + ": must be a pointer or interface")
+	}
+}
+
+var errSkipAfterWrite = errors.New("function returned SkipFunc after writing or reading data")
+
+// marshal calls the first applicable function that does not return
+// SkipFunc, and returns SkipFunc if there are none.
+func (m *Marshalers) marshal(e *Encoder, v reflect.Value) error {
+	t := v.Type()
+	for _, f := range m.fns {
+		if t != f.typ && (f.typ.Kind() != reflect.Interface || !t.Implements(f.typ)) {
+			continue
+		}
+		off := e.OutputOffset()
+		err := watchValue(&e.state, func() error { return f.fn(e, v) })
+		switch {
+		case err == SkipFunc && e.OutputOffset() != off:
+			return newMarshalError(e, t, KindInvalid, errSkipAfterWrite)
+		case err != SkipFunc:
+			return wrapError(err, "marshal", off, t)
+		}
+	}
+	return SkipFunc
+}
+
+// unmarshal calls the first applicable function that does not return
+// SkipFunc, and returns SkipFunc if there are none.
+func (u *Unmarshalers) unmarshal(d *Decoder, v reflect.Value) error {
+	pt := reflect.PointerTo(v.Type())
+	for _, f := range u.fns {
+		if pt != f.typ && (f.typ.Kind() != reflect.Interface || !pt.Implements(f.typ)) {
+			continue
+		}
+		off := d.InputOffset()
+		err := watchValue(&d.state, func() error { return f.fn(d, v) })
+		switch {
+		case err == SkipFunc && d.InputOffset() != off:
+			return newUnmarshalError(off, v.Type(), KindInvalid, errSkipAfterWrite)
+		case err != SkipFunc:
+			return wrapError(err, "unmarshal", off, v.Type())
+		}
+	}
+	return SkipFunc
+}
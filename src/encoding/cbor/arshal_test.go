@@ -0,0 +1,599 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type (
+	structPlain struct {
+		A int
+		B string `cbor:"b"`
+		C []byte `cbor:",omitempty"`
+		D *int   `cbor:",omitzero"`
+		E bool   `cbor:"-"`
+		f int
+	}
+	structKeyAsInt struct {
+		Alg int    `cbor:"1,keyasint"`
+		Kid []byte `cbor:"4,keyasint,omitempty"`
+		Neg string `cbor:"-1,keyasint"`
+	}
+	structToArray struct {
+		_ struct{} `cbor:",toarray"`
+		X int
+		Y string
+	}
+	structEmbedded struct {
+		structInner
+		*StructPtrInner
+		Outer int
+	}
+	structInner struct {
+		Inner int
+		Outer int // shadowed by structEmbedded.Outer
+	}
+	StructPtrInner struct {
+		PtrInner string
+	}
+	structOmitZero struct {
+		T time.Time `cbor:",omitzero"`
+		Z zeroer    `cbor:",omitzero"`
+		N int       `cbor:",omitzero"`
+	}
+	structRecursive struct {
+		Name string
+		Next *structRecursive `cbor:",omitempty"`
+	}
+	structBadOption struct {
+		A int `cbor:",unknown"`
+	}
+	structBadKey struct {
+		A int `cbor:"a,keyasint"`
+	}
+)
+
+type zeroer struct{ n int }
+
+func (z zeroer) IsZero() bool { return z.n <= 0 }
+
+// methodMarshaler marshals itself as a tagged text string.
+type methodMarshaler struct{ s string }
+
+func (m methodMarshaler) MarshalCBOR() ([]byte, error) {
+	return Marshal(Tag{Number: 100, Content: m.s})
+}
+
+func (m *methodMarshaler) UnmarshalCBOR(b []byte) error {
+	var t Tag
+	t.Content = &m.s
+	if err := Unmarshal(b, &t); err != nil {
+		return err
+	}
+	if t.Number != 100 {
+		return errors.New("unexpected tag " + strconv.FormatUint(t.Number, 10))
+	}
+	return nil
+}
+
+// methodMarshalerTo marshals itself as an array of its fields.
+type methodMarshalerTo struct {
+	a, b int
+}
+
+func (m methodMarshalerTo) MarshalCBORTo(e *Encoder) error {
+	if err := e.WriteToken(ArrayStart(2)); err != nil {
+		return err
+	}
+	if err := e.WriteToken(Int(int64(m.a))); err != nil {
+		return err
+	}
+	return e.WriteToken(Int(int64(m.b)))
+}
+
+func (m *methodMarshalerTo) UnmarshalCBORFrom(d *Decoder) error {
+	var v [2]int
+	if err := UnmarshalDecode(d, &v); err != nil {
+		return err
+	}
+	m.a, m.b = v[0], v[1]
+	return nil
+}
+
+// brokenMarshalerTo writes two values instead of one.
+type brokenMarshalerTo struct{}
+
+func (brokenMarshalerTo) MarshalCBORTo(e *Encoder) error {
+	e.WriteToken(Uint(1))
+	return e.WriteToken(Uint(2))
+}
+
+func addr[T any](v T) *T { return &v }
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		opts []Options
+		want string
+	}{
+		{name: "Bool", in: true, want: "f5"},
+		{name: "Int", in: -500, want: "3901f3"},
+		{name: "Int8", in: int8(-128), want: "387f"},
+		{name: "Uint64", in: uint64(math.MaxUint64), want: "1bffffffffffffffff"},
+		{name: "Float32", in: float32(1.5), want: "f93e00"},
+		{name: "Float64", in: 1.1, want: "fb3ff199999999999a"},
+		{name: "String", in: "IETF", want: "6449455446"},
+		{name: "Bytes", in: []byte{1, 2, 3, 4}, want: "4401020304"},
+		{name: "ByteArray", in: [2]byte{1, 2}, want: "420102"},
+		{name: "NilBytes", in: []byte(nil), want: "40"},
+		{name: "NilBytesAsNull", in: []byte(nil), opts: []Options{FormatNilSliceAsNull(true)}, want: "f6"},
+		{name: "Slice", in: []int{1, 2, 3}, want: "83010203"},
+		{name: "NilSlice", in: []int(nil), want: "80"},
+		{name: "NilSliceAsNull", in: []int(nil), opts: []Options{FormatNilSliceAsNull(true)}, want: "f6"},
+		{name: "Array", in: [2]string{"a", "b"}, want: "8261616162"},
+		{name: "Map", in: map[string]int{"b": 2, "a": 1, "aa": 3}, opts: []Options{Deterministic(true)}, want: "a3616101616202626161" + "03"},
+		{name: "MapIntKeys", in: map[int]bool{-1: true, 10: false, 1: true}, opts: []Options{Deterministic(true)}, want: "a301f50af420f5"},
+		{name: "NilMap", in: map[string]int(nil), want: "a0"},
+		{name: "NilMapAsNull", in: map[string]int(nil), opts: []Options{FormatNilMapAsNull(true)}, want: "f6"},
+		{name: "NilPointer", in: (*int)(nil), want: "f6"},
+		{name: "Pointer", in: addr(7), want: "07"},
+		{name: "NilInterface", in: []any{nil}, want: "81f6"},
+		{name: "Interface", in: []any{1, "a"}, want: "82016161"},
+		{name: "Value", in: Value{0x83, 0x01, 0x02, 0x03}, want: "83010203"},
+		{name: "Simple", in: Simple(16), want: "f0"},
+		{name: "SimpleTwoByte", in: Simple(255), want: "f8ff"},
+		{name: "Tag", in: Tag{Number: 32, Content: "http://a"}, want: "d8206868747470" + "3a2f2f61"},
+		{name: "NestedTag", in: Tag{Number: 1, Content: Tag{Number: 2, Content: []byte{}}}, want: "c1c240"},
+		{name: "BigIntSmall", in: big.NewInt(-1000), want: "3903e7"},
+		{name: "BigIntUint64", in: new(big.Int).SetUint64(math.MaxUint64), want: "1bffffffffffffffff"},
+		{name: "BigIntPositive", in: new(big.Int).Lsh(big.NewInt(1), 64), want: "c249010000000000000000"},
+		{name: "BigIntNegative", in: new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 64)), want: "3bffffffffffffffff"},
+		{name: "BigIntNegativeBignum", in: new(big.Int).Sub(big.NewInt(-1), new(big.Int).Lsh(big.NewInt(1), 64)), want: "c349010000000000000000"},
+		{name: "Time", in: time.Unix(1363896240, 0), want: "c11a514b67b0"},
+		{name: "TimeFraction", in: time.Unix(1363896240, 5e8), want: "c1fb41d452d9ec200000"},
+		{name: "TimeNegative", in: time.Unix(-1, 0), want: "c120"},
+		{name: "TextMarshaler", in: netip.MustParseAddr("::1"), want: "633a3a31"},
+		{name: "Marshaler", in: methodMarshaler{"x"}, want: "d8646178"},
+		{name: "MarshalerTo", in: methodMarshalerTo{1, -1}, want: "820120"},
+		{name: "MarshalerToPointer", in: &methodMarshalerTo{2, 3}, want: "820203"},
+		{
+			name: "Struct",
+			in:   structPlain{A: 1, B: "x", E: true, f: 5},
+			want: "a261410161626178",
+		},
+		{
+			name: "StructOmit",
+			in:   structPlain{C: []byte{0}, D: addr(0)},
+			want: "a4614100616260614341006144" + "00",
+		},
+		{
+			name: "StructKeyAsInt",
+			in:   structKeyAsInt{Alg: -7, Neg: "k"},
+			want: "a2012620616b",
+		},
+		{
+			name: "StructToArray",
+			in:   structToArray{X: 1, Y: "a"},
+			want: "82016161",
+		},
+		{
+			name: "StructEmbedded",
+			in:   structEmbedded{structInner: structInner{Inner: 1, Outer: 2}, Outer: 3},
+			want: "a265496e6e657201654f7574657203",
+		},
+		{
+			name: "StructEmbeddedPointer",
+			in:   structEmbedded{StructPtrInner: &StructPtrInner{"p"}},
+			want: "a365496e6e65720068507472496e6e65726170654f7574657200",
+		},
+		{
+			name: "StructOmitZero",
+			in:   structOmitZero{Z: zeroer{-1}},
+			want: "a0",
+		},
+		{
+			name: "StructOmitZeroNonZero",
+			in:   structOmitZero{Z: zeroer{1}, N: 1},
+			want: "a2615aa0614e01",
+		},
+		{
+			name: "StructRecursive",
+			in:   structRecursive{Name: "a", Next: &structRecursive{Name: "b"}},
+			want: "a2644e616d656161644e657874a1644e616d656162",
+		},
+		{
+			name: "MarshalFunc",
+			in:   []any{1, "a", 2},
+			opts: []Options{WithMarshalers(MarshalFunc(func(n int) ([]byte, error) {
+				return Marshal(strconv.Itoa(n))
+			}))},
+			want: "8361316161" + "6132",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.in, tt.opts...)
+			if err != nil {
+				t.Fatalf("Marshal error: %v", err)
+			}
+			if want := mustDecodeHex(t, tt.want); !bytes.Equal(got, want) {
+				t.Errorf("Marshal = %x (%v), want %x (%v)", got, Value(got), want, Value(want))
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []Options
+		into any
+		want any
+	}{
+		{name: "Bool", in: "f4", into: addr(true), want: addr(false)},
+		{name: "Int", in: "3901f3", into: new(int), want: addr(-500)},
+		{name: "IntFromTag", in: "d82a07", into: new(int), want: addr(7)},
+		{name: "Uint", in: "1bffffffffffffffff", into: new(uint64), want: addr(uint64(math.MaxUint64))},
+		{name: "FloatFromHalf", in: "f93e00", into: new(float64), want: addr(1.5)},
+		{name: "FloatFromInt", in: "20", into: new(float32), want: addr(float32(-1))},
+		{name: "String", in: "7f657374726561646d696e67ff", into: new(string), want: addr("streaming")},
+		{name: "Bytes", in: "5f42010243030405ff", into: new([]byte), want: addr([]byte{1, 2, 3, 4, 5})},
+		{name: "ByteArray", in: "420102", into: new([2]byte), want: addr([2]byte{1, 2})},
+		{name: "Slice", in: "9f0102ff", into: addr([]int{5, 6, 7}), want: addr([]int{1, 2})},
+		{name: "Array", in: "820102", into: new([2]int), want: addr([2]int{1, 2})},
+		{name: "Map", in: "bf616101616202ff", into: addr(map[string]int{"c": 3}), want: addr(map[string]int{"a": 1, "b": 2})},
+		{name: "MapIntKeys", in: "a2010220f5", into: new(map[int]any), want: addr(map[int]any{1: uint64(2), -1: true})},
+		{name: "Null", in: "f6", into: addr(5), want: addr(0)},
+		{name: "Undefined", in: "f7", into: addr("x"), want: addr("")},
+		{name: "NullPointer", in: "f6", into: addr(addr(5)), want: new(*int)},
+		{name: "Pointer", in: "07", into: new(*int), want: addr(addr(7))},
+		{name: "Value", in: "9f01ff", into: new(Value), want: addr(Value{0x9f, 0x01, 0xff})},
+		{name: "Simple", in: "f8ff", into: new(Simple), want: addr(Simple(255))},
+		{name: "SimpleFromBool", in: "f5", into: new(Simple), want: addr(Simple(21))},
+		{name: "Tag", in: "d8206161", into: new(Tag), want: &Tag{Number: 32, Content: "a"}},
+		{name: "TagIntoContent", in: "c1820102", into: &Tag{Content: new([]int8)}, want: &Tag{Number: 1, Content: addr([]int8{1, 2})}},
+		{name: "BigInt", in: "3903e7", into: new(big.Int), want: big.NewInt(-1000)},
+		{name: "BigIntBignum", in: "c249010000000000000000", into: new(big.Int), want: new(big.Int).Lsh(big.NewInt(1), 64)},
+		{name: "BigIntNegativeBignum", in: "c349010000000000000000", into: new(big.Int), want: new(big.Int).Sub(big.NewInt(-1), new(big.Int).Lsh(big.NewInt(1), 64))},
+		{name: "TimeString", in: "c074323031332d30332d32315432303a30343a30305a", into: new(time.Time), want: addr(time.Unix(1363896240, 0).UTC())},
+		{name: "TimeEpoch", in: "c11a514b67b0", into: new(time.Time), want: addr(time.Unix(1363896240, 0).UTC())},
+		{name: "TimeEpochFloat", in: "c1fb41d452d9ec200000", into: new(time.Time), want: addr(time.Unix(1363896240, 5e8).UTC())},
+		{name: "TimeUntagged", in: "1a514b67b0", into: new(time.Time), want: addr(time.Unix(1363896240, 0).UTC())},
+		{name: "TextUnmarshaler", in: "633a3a31", into: new(netip.Addr), want: addr(netip.MustParseAddr("::1"))},
+		{name: "Unmarshaler", in: "d8646178", into: new(methodMarshaler), want: &methodMarshaler{"x"}},
+		{name: "UnmarshalerFrom", in: "820120", into: new(methodMarshalerTo), want: &methodMarshalerTo{1, -1}},
+		{
+			name: "Any",
+			in:   "8a0120f5f6f7f0f93e00c2490100000000000000004161d8186161",
+			into: new(any),
+			want: addr(any([]any{
+				uint64(1), int64(-1), true, nil, nil, Simple(16), 1.5,
+				new(big.Int).Lsh(big.NewInt(1), 64), []byte("a"), Tag{Number: 24, Content: "a"},
+			})),
+		},
+		{name: "AnyMap", in: "a26161a001f4", into: new(any), want: addr(any(map[any]any{"a": map[any]any{}, uint64(1): false}))},
+		{name: "AnyLargeNegative", in: "3bffffffffffffffff", into: new(any), want: addr(any(new(big.Int).Sub(big.NewInt(-1), new(big.Int).SetUint64(math.MaxUint64))))},
+		{name: "AnyPointer", in: "6161", into: addr(any(new(string))), want: addr(any(addr("a")))},
+		{
+			name: "Struct",
+			in:   "a3614101616260614601",
+			into: &structPlain{A: 9, E: true, f: 5},
+			want: &structPlain{A: 1, E: true, f: 5},
+		},
+		{
+			name: "StructUnknownField",
+			in:   "a2614101617883010200",
+			into: new(structPlain),
+			want: &structPlain{A: 1},
+		},
+		{
+			name: "StructKeyAsInt",
+			in:   "a320616b01260444c0ffee00",
+			into: new(structKeyAsInt),
+			want: &structKeyAsInt{Alg: -7, Kid: []byte{0xc0, 0xff, 0xee, 0x00}, Neg: "k"},
+		},
+		{
+			name: "StructToArray",
+			in:   "82016161",
+			into: new(structToArray),
+			want: &structToArray{X: 1, Y: "a"},
+		},
+		{
+			name: "StructEmbedded",
+			in:   "a365496e6e65720168507472496e6e65726170654f7574657203",
+			into: new(structEmbedded),
+			want: &structEmbedded{structInner: structInner{Inner: 1}, StructPtrInner: &StructPtrInner{"p"}, Outer: 3},
+		},
+		{
+			name: "StructRecursive",
+			in:   "a2644e616d656161644e657874a1644e616d656162",
+			into: new(structRecursive),
+			want: &structRecursive{Name: "a", Next: &structRecursive{Name: "b"}},
+		},
+		{
+			name: "UnmarshalFunc",
+			in:   "8261316132",
+			opts: []Options{WithUnmarshalers(UnmarshalFunc(func(b []byte, n *int) error {
+				var s string
+				if err := Unmarshal(b, &s); err != nil {
+					return err
+				}
+				var err error
+				*n, err = strconv.Atoi(s)
+				return err
+			}))},
+			into: new([2]int),
+			want: addr([2]int{1, 2}),
+		},
+		{
+			name: "UnmarshalFromFunc",
+			in:   "826131f5",
+			opts: []Options{WithUnmarshalers(UnmarshalFromFunc(func(d *Decoder, s *string) error {
+				if d.PeekKind() != KindBool {
+					return SkipFunc
+				}
+				var b bool
+				if err := UnmarshalDecode(d, &b); err != nil {
+					return err
+				}
+				*s = strconv.FormatBool(b)
+				return nil
+			}))},
+			into: new([2]string),
+			want: addr([2]string{"1", "true"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(mustDecodeHex(t, tt.in), tt.into, tt.opts...); err != nil {
+				t.Fatalf("Unmarshal error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.into, tt.want) {
+				t.Errorf("Unmarshal = %#v, want %#v", tt.into, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      any
+		opts    []Options
+		wantErr error
+	}{
+		{name: "Chan", in: make(chan int), wantErr: errUnsupported},
+		{name: "Complex", in: []complex64{1}, wantErr: errUnsupported},
+		{name: "InvalidValue", in: Value{0x18}},
+		{name: "ReservedSimple", in: Simple(24)},
+		{name: "InvalidUTF8", in: "\xff", wantErr: ErrInvalidUTF8},
+		{name: "DuplicateKey", in: map[string]Value{"a": {0x01}, "b": {0x02}}, opts: []Options{WithMarshalers(MarshalFunc(func(string) ([]byte, error) {
+			return []byte{0x00}, nil
+		}))}, wantErr: ErrDuplicateKey},
+		{name: "BadStructOption", in: structBadOption{}},
+		{name: "BadStructKey", in: structBadKey{}},
+		{name: "MultipleValues", in: brokenMarshalerTo{}, wantErr: errMultipleValues},
+		{name: "SkipAfterWrite", in: 1, opts: []Options{WithMarshalers(MarshalToFunc(func(e *Encoder, n int) error {
+			e.WriteToken(Uint(1))
+			return SkipFunc
+		}))}, wantErr: errSkipAfterWrite},
+		{name: "FuncError", in: 1, opts: []Options{WithMarshalers(MarshalFunc(func(n int) ([]byte, error) {
+			return nil, errors.New("some error")
+		}))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.in, tt.opts...)
+			if err == nil {
+				t.Fatal("Marshal error is nil, want non-nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Marshal error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		into    any
+		opts    []Options
+		wantErr error
+		offset  int64
+	}{
+		{name: "KindMismatch", in: "6161", into: new(int), offset: 0},
+		{name: "IntOverflow", in: "190100", into: new(int8), wantErr: errOverflow},
+		{name: "NegativeUint", in: "20", into: new(uint), wantErr: errOverflow},
+		{name: "Float32Overflow", in: "fb7e37e43c8800759c", into: new(float32), wantErr: errOverflow},
+		{name: "ArrayTooShort", in: "8101", into: new([2]int), wantErr: errArrayLength},
+		{name: "ArrayTooLong", in: "83010203", into: new([2]int), wantErr: errArrayLength},
+		{name: "ByteArrayLength", in: "4101", into: new([2]byte), wantErr: errArrayLength},
+		{name: "NonComparableKey", in: "a18000", into: new(map[any]int), wantErr: errNonComparable},
+		{name: "NonEmptyInterface", in: "01", into: new(error), wantErr: errNonEmptyIface},
+		{name: "UnknownField", in: "a1617801", into: new(structPlain), opts: []Options{RejectUnknownFields(true)}, wantErr: ErrUnknownField, offset: 1},
+		{name: "DuplicateKey", in: "a2614101614102", into: new(map[string]int), wantErr: ErrDuplicateKey, offset: 4},
+		{name: "InvalidUTF8", in: "61ff", into: new(string), wantErr: ErrInvalidUTF8},
+		{name: "TrailingData", in: "0000", into: new(int), wantErr: errTrailingData, offset: 1},
+		{name: "Truncated", in: "8201", into: new([]int)},
+		{name: "StructToArrayLength", in: "8101", into: new(structToArray), wantErr: errArrayLength},
+		{name: "UnmarshalerFromError", in: "8101", into: new(methodMarshalerTo), wantErr: errArrayLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal(mustDecodeHex(t, tt.in), tt.into, tt.opts...)
+			if err == nil {
+				t.Fatal("Unmarshal error is nil, want non-nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Unmarshal error = %v, want %v", err, tt.wantErr)
+			}
+			var offset int64
+			var serr *SyntacticError
+			var merr *SemanticError
+			switch {
+			case errors.As(err, &serr):
+				offset = serr.ByteOffset
+			case errors.As(err, &merr):
+				offset = merr.ByteOffset
+			default:
+				if !errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Errorf("Unmarshal error = %T, want *SyntacticError or *SemanticError", err)
+				}
+				return
+			}
+			if offset != tt.offset && tt.offset != 0 {
+				t.Errorf("Unmarshal error offset = %d, want %d", offset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestSemanticErrorString(t *testing.T) {
+	err := Unmarshal(mustDecodeHex(t, "816161"), new([]int))
+	want := "cbor: cannot unmarshal CBOR text string into Go int at byte offset 1"
+	if err == nil || err.Error() != want {
+		t.Errorf("Unmarshal error = %v, want %v", err, want)
+	}
+	var merr *SemanticError
+	if !errors.As(err, &merr) || merr.CBORKind != KindString || merr.GoType != reflect.TypeFor[int]() {
+		t.Errorf("Unmarshal error = %#v, want SemanticError with CBORKind=KindString and GoType=int", err)
+	}
+}
+
+func TestMarshalDeterministic(t *testing.T) {
+	in := map[any]any{
+		"b":        1,
+		"a":        []any{map[string]int{"z": 1, "y": 2}},
+		10:         "ten",
+		-1:         "minus one",
+		100:        false,
+		[1]byte{0}: nil,
+		"aa":       Value(mustDecodeHex(t, "bf616201616101ff")), // {_ "b": 1, "a": 1}
+		Simple(16): true,
+	}
+	want := "a80a6374656e1864f420696d696e7573206f6e654100f6616181a2617902617a01616201626161a2616101616201f0f5"
+	got, err := Marshal(in, CoreDeterministic(true))
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if hex.EncodeToString(got) != want {
+		t.Errorf("Marshal = %x (%v)\nwant      %s", got, Value(got), want)
+	}
+
+	// Deterministic encoding is stable across repeated marshals,
+	// regardless of Go map iteration order.
+	for range 10 {
+		b, err := Marshal(in, CoreDeterministic(true))
+		if err != nil || !bytes.Equal(b, got) {
+			t.Fatalf("Marshal = %x, %v, want %x", b, err, got)
+		}
+	}
+}
+
+func TestMarshalUnmarshalRoundTrip(t *testing.T) {
+	type T struct {
+		Bool    bool
+		Int     int64
+		Uint    uint16
+		Float   float64
+		String  string
+		Bytes   []byte
+		Ints    []int
+		Map     map[string]*T
+		Time    time.Time
+		BigInt  *big.Int
+		Any     any
+		Skipped int `cbor:"-"`
+	}
+	in := T{
+		Bool:   true,
+		Int:    math.MinInt64,
+		Uint:   math.MaxUint16,
+		Float:  math.Inf(-1),
+		String: "hello, 世界",
+		Bytes:  []byte("bytes"),
+		Ints:   []int{},
+		Map:    map[string]*T{"nil": nil, "child": {String: "child", Ints: []int{}, Bytes: []byte{}, Map: map[string]*T{}}},
+		Time:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		BigInt: new(big.Int).Lsh(big.NewInt(-3), 100),
+		Any:    []any{uint64(1), "two", map[any]any{int64(-3): 4.5}},
+	}
+	b, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var out T
+	if err := Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", out, in)
+	}
+}
+
+func TestMarshalWriteUnmarshalRead(t *testing.T) {
+	var buf bytes.Buffer
+	for i := range 3 {
+		if err := MarshalWrite(&buf, []int{i}); err != nil {
+			t.Fatalf("MarshalWrite error: %v", err)
+		}
+	}
+	if got, want := hex.EncodeToString(buf.Bytes()), "810081018102"; got != want {
+		t.Fatalf("MarshalWrite = %s, want %s", got, want)
+	}
+
+	// UnmarshalRead requires a single top-level value.
+	var v []int
+	if err := UnmarshalRead(bytes.NewReader(buf.Bytes()), &v); !errors.Is(err, errTrailingData) {
+		t.Errorf("UnmarshalRead error = %v, want %v", err, errTrailingData)
+	}
+
+	// UnmarshalDecode reads a sequence of values.
+	d := NewDecoder(bytes.NewReader(buf.Bytes()))
+	for i := range 3 {
+		if err := UnmarshalDecode(d, &v); err != nil {
+			t.Fatalf("UnmarshalDecode error: %v", err)
+		}
+		if len(v) != 1 || v[0] != i {
+			t.Errorf("UnmarshalDecode = %v, want [%d]", v, i)
+		}
+	}
+	if err := UnmarshalDecode(d, &v); err != io.EOF {
+		t.Errorf("UnmarshalDecode error = %v, want io.EOF", err)
+	}
+}
+
+func TestUnmarshalNonPointer(t *testing.T) {
+	var n int
+	for _, out := range []any{nil, n, (*int)(nil)} {
+		if err := Unmarshal([]byte{0x01}, out); err == nil {
+			t.Errorf("Unmarshal(%T) error is nil, want non-nil", out)
+		}
+	}
+}
+
+func TestUnmarshalFuncPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("UnmarshalFunc[int] did not panic")
+		}
+	}()
+	UnmarshalFunc(func([]byte, int) error { return nil })
+}
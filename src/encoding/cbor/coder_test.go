@@ -0,0 +1,386 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCoderRoundTrip(t *testing.T) {
+	for _, tt := range rfcExamples {
+		in := mustDecodeHex(t, tt.hex)
+		d := NewDecoder(bytes.NewReader(in))
+		var out bytes.Buffer
+		e := NewEncoder(&out)
+		for {
+			tok, err := d.ReadToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: ReadToken error: %v", tt.hex, err)
+			}
+			if err := e.WriteToken(tok); err != nil {
+				t.Fatalf("%s: WriteToken(%v) error: %v", tt.hex, tok, err)
+			}
+		}
+		if !bytes.Equal(out.Bytes(), in) {
+			t.Errorf("token round trip of %s = %x", tt.hex, out.Bytes())
+		}
+		if e.StackDepth() != 0 || d.StackDepth() != 0 {
+			t.Errorf("%s: StackDepth = %d, %d, want 0", tt.hex, e.StackDepth(), d.StackDepth())
+		}
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	// Concatenate all examples into a CBOR sequence,
+	// and read it one byte at a time.
+	var seq []byte
+	var offsets []int64
+	for _, tt := range rfcExamples {
+		offsets = append(offsets, int64(len(seq)))
+		seq = append(seq, mustDecodeHex(t, tt.hex)...)
+	}
+	d := NewDecoder(iotest.OneByteReader(bytes.NewReader(seq)))
+	for i, tt := range rfcExamples {
+		if got := d.InputOffset(); got != offsets[i] {
+			t.Errorf("InputOffset() = %d, want %d", got, offsets[i])
+		}
+		v, err := d.ReadValue()
+		if err != nil {
+			t.Fatalf("ReadValue error: %v", err)
+		}
+		if got := hex.EncodeToString(v); got != tt.hex {
+			t.Errorf("ReadValue() = %s, want %s", got, tt.hex)
+		}
+	}
+	if k := d.PeekKind(); k != KindInvalid {
+		t.Errorf("PeekKind() at end = %v, want %v", k, KindInvalid)
+	}
+	if _, err := d.ReadValue(); err != io.EOF {
+		t.Errorf("ReadValue() at end error = %v, want io.EOF", err)
+	}
+	if _, err := d.ReadToken(); err != io.EOF {
+		t.Errorf("ReadToken() at end error = %v, want io.EOF", err)
+	}
+}
+
+func TestDecoderMixed(t *testing.T) {
+	in := mustDecodeHex(t, "a26161016162820203")
+	d := NewDecoder(bytes.NewReader(in))
+	if k := d.PeekKind(); k != KindMap {
+		t.Fatalf("PeekKind() = %v, want %v", k, KindMap)
+	}
+	tok, err := d.ReadToken()
+	if err != nil || tok.Kind() != KindMap || tok.Len() != 2 {
+		t.Fatalf("ReadToken() = %v, %v, want map of 2 entries", tok, err)
+	}
+	var got []string
+	for range 2 {
+		k, err := d.ReadToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := d.ReadValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, k.String()+"="+v.String())
+		if d.StackDepth() != 1 && len(got) < 2 {
+			t.Errorf("StackDepth() = %d, want 1", d.StackDepth())
+		}
+	}
+	if want := "a=1 b=[2, 3]"; strings.Join(got, " ") != want {
+		t.Errorf("entries = %q, want %q", strings.Join(got, " "), want)
+	}
+	if d.StackDepth() != 0 {
+		t.Errorf("StackDepth() = %d, want 0", d.StackDepth())
+	}
+}
+
+func TestDecoderDuplicateKeyLarge(t *testing.T) {
+	// Duplicate keys must be detected even when keys
+	// span several reads of the underlying reader.
+	key := strings.Repeat("k", 10000)
+	var e Encoder
+	e.Reset(nil, AllowDuplicateKeys(true))
+	e.WriteToken(MapStart(3))
+	e.WriteToken(String(key))
+	e.WriteToken(Uint(1))
+	e.WriteToken(String(key + "x"))
+	e.WriteToken(Uint(2))
+	e.WriteToken(String(key))
+	e.WriteToken(Uint(3))
+	d := NewDecoder(iotest.HalfReader(bytes.NewReader(e.buf)))
+	if _, err := d.ReadValue(); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("ReadValue() error = %v, want ErrDuplicateKey", err)
+	}
+	d = NewDecoder(iotest.HalfReader(bytes.NewReader(e.buf)), AllowDuplicateKeys(true))
+	if _, err := d.ReadValue(); err != nil {
+		t.Errorf("ReadValue() with AllowDuplicateKeys error = %v", err)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		hex     string
+		wantErr error
+		offset  int64
+	}{
+		{"8301", io.ErrUnexpectedEOF, 2},
+		{"8301ff", errUnexpectedBrk, 2},
+		{"a161610101", errTrailingData, 4},
+		{"9f5f6161ffff", errInvalidChunk, 2},
+		{"a2616101616101", ErrDuplicateKey, 4},
+	}
+	for _, tt := range tests {
+		var v any
+		err := Unmarshal(mustDecodeHex(t, tt.hex), &v)
+		var serr *SyntacticError
+		if !errors.As(err, &serr) || !errors.Is(err, tt.wantErr) {
+			t.Errorf("Unmarshal(%s) error = %v, want %v", tt.hex, err, tt.wantErr)
+			continue
+		}
+		if serr.ByteOffset != tt.offset {
+			t.Errorf("Unmarshal(%s) error offset = %d, want %d", tt.hex, serr.ByteOffset, tt.offset)
+		}
+	}
+
+	// An error from the reader is reported as is.
+	errRead := errors.New("read failed")
+	d := NewDecoder(io.MultiReader(bytes.NewReader([]byte{0x82, 0x01}), iotest.ErrReader(errRead)))
+	if _, err := d.ReadValue(); !errors.Is(err, errRead) {
+		t.Errorf("ReadValue() error = %v, want %v", err, errRead)
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Options
+		tokens  []Token
+		wantErr error
+	}{
+		{"InvalidToken", nil, []Token{{}}, errInvalidToken},
+		{"TopLevelBreak", nil, []Token{Break}, errUnexpectedBrk},
+		{"DefiniteBreak", nil, []Token{ArrayStart(1), Break}, errUnexpectedBrk},
+		{"MapValueBreak", nil, []Token{MapStart(-1), Uint(1), Break}, errUnexpectedBrk},
+		{"ChunkKind", nil, []Token{BytesStart(), String("a")}, errInvalidChunk},
+		{"NestedChunk", nil, []Token{StringStart(), StringStart()}, errInvalidChunk},
+		{"ChunkArray", nil, []Token{StringStart(), ArrayStart(0)}, errInvalidChunk},
+		{"ReservedSimple", nil, []Token{SimpleValue(24)}, errInvalidSimple},
+		{"InvalidUTF8", nil, []Token{String("\xff")}, ErrInvalidUTF8},
+		{"DuplicateKey", nil, []Token{MapStart(2), String("a"), Null, String("a")}, ErrDuplicateKey},
+		{"DuplicateArrayKey", nil, []Token{MapStart(-1), ArrayStart(1), Uint(1), Null, ArrayStart(1), Uint(1)}, ErrDuplicateKey},
+		{"CoreIndefinite", []Options{CoreDeterministic(true)}, []Token{ArrayStart(-1)}, errIndefDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEncoder(io.Discard, tt.opts...)
+			var err error
+			for _, tok := range tt.tokens {
+				if err = e.WriteToken(tok); err != nil {
+					break
+				}
+			}
+			var serr *SyntacticError
+			if !errors.As(err, &serr) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("WriteToken error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	e := NewEncoder(io.Discard, AllowInvalidUTF8(true), AllowDuplicateKeys(true))
+	for _, tok := range []Token{MapStart(2), String("\xff"), Null, String("\xff"), Null} {
+		if err := e.WriteToken(tok); err != nil {
+			t.Errorf("WriteToken(%v) error: %v", tok, err)
+		}
+	}
+
+	errWrite := errors.New("write failed")
+	e = NewEncoder(errWriter{errWrite})
+	if err := e.WriteToken(Uint(1)); !errors.Is(err, errWrite) {
+		t.Errorf("WriteToken error = %v, want %v", err, errWrite)
+	}
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestEncoderDeterministic(t *testing.T) {
+	tokens := []Token{
+		MapStart(4),
+		String("b"), Uint(1),
+		Uint(100), MapStart(-1), String("z"), True, String("y"), False, Break,
+		String("a"), Uint(2),
+		ArrayStart(1), Uint(1), Null,
+	}
+	tests := []struct {
+		opts []Options
+		want string
+	}{
+		{nil, `{"b": 1, 100: {_ "z": true, "y": false}, "a": 2, [1]: null}`},
+		{[]Options{Deterministic(true)}, `{100: {_ "y": false, "z": true}, "a": 2, "b": 1, [1]: null}`},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := NewEncoder(&out, tt.opts...)
+		for _, tok := range tokens {
+			if err := e.WriteToken(tok); err != nil {
+				t.Fatalf("WriteToken(%v) error: %v", tok, err)
+			}
+		}
+		if got := Value(out.Bytes()).String(); got != tt.want {
+			t.Errorf("output = %s, want %s", got, tt.want)
+		}
+	}
+
+	// Raw values are sorted or canonicalized as well.
+	in := mustDecodeHex(t, "bf61621901006161fb3ff0000000000000ff")
+	for _, tt := range []struct {
+		opts []Options
+		want string
+	}{
+		{nil, "bf61621901006161fb3ff0000000000000ff"},
+		{[]Options{Deterministic(true)}, "bf 6161 f93c00 6162 190100 ff"},
+		{[]Options{CoreDeterministic(true)}, "a2 6161 f93c00 6162 190100"},
+	} {
+		var out bytes.Buffer
+		e := NewEncoder(&out, tt.opts...)
+		if err := e.WriteValue(in); err != nil {
+			t.Fatalf("WriteValue error: %v", err)
+		}
+		if got, want := hex.EncodeToString(out.Bytes()), strings.ReplaceAll(tt.want, " ", ""); got != want {
+			t.Errorf("WriteValue(%x) with %v = %s, want %s", in, tt.opts, got, want)
+		}
+	}
+}
+
+func TestEncoderWriteValue(t *testing.T) {
+	var out bytes.Buffer
+	e := NewEncoder(&out)
+	for _, step := range []struct {
+		tok     Token
+		val     string
+		wantErr error
+	}{
+		{tok: ArrayStart(3)},
+		{val: "820102"},
+		{val: "ff", wantErr: errUnexpectedBrk},
+		{val: "8201", wantErr: io.ErrUnexpectedEOF},
+		{val: "0102", wantErr: errTrailingData},
+		{val: "a0"},
+		{tok: StringStart()},
+		{val: "4161", wantErr: errInvalidChunk},
+		{val: "6161"},
+		{tok: Break},
+	} {
+		var err error
+		if step.val != "" {
+			err = e.WriteValue(mustDecodeHex(t, step.val))
+		} else {
+			err = e.WriteToken(step.tok)
+		}
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("writing %v%s: error = %v, want %v", step.tok, step.val, err, step.wantErr)
+		}
+	}
+	if got, want := Value(out.Bytes()).String(), `[[1, 2], {}, (_ "a")]`; got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestToken(t *testing.T) {
+	tests := []struct {
+		tok  Token
+		kind Kind
+		hex  string
+		str  string
+	}{
+		{Int(-1), KindNegInt, "20", "-1"},
+		{Int(math.MinInt64), KindNegInt, "3b7fffffffffffffff", "-9223372036854775808"},
+		{NegInt(math.MaxUint64), KindNegInt, "3bffffffffffffffff", "-18446744073709551616"},
+		{Uint(500), KindUint, "1901f4", "500"},
+		{Float(1.0), KindFloat, "f93c00", "1.0"},
+		{Float(0.1), KindFloat, "fb3fb999999999999a", "0.1"},
+		{Float(float64(float32(0.1))), KindFloat, "fa3dcccccd", "0.10000000149011612"},
+		{Float(math.Inf(-1)), KindFloat, "f9fc00", "-Infinity"},
+		{Float(math.Float64frombits(0x7ff8000000000001)), KindFloat, "f97e00", "NaN"},
+		{Float(math.SmallestNonzeroFloat64), KindFloat, "fb0000000000000001", "5.0e-324"},
+		{Float(5.960464477539063e-8), KindFloat, "f90001", "5.960464477539063e-8"},
+		{Float(math.Ldexp(3, -24)), KindFloat, "f90003", "1.7881393432617188e-7"},
+		{Bytes([]byte{1, 2}), KindBytes, "420102", "h'0102'"},
+		{String("é"), KindString, "62c3a9", "é"},
+		{BytesStart(), KindBytes, "5f", "(_"},
+		{StringStart(), KindString, "7f", "(_"},
+		{ArrayStart(-1), KindArray, "9f", "[_"},
+		{ArrayStart(30), KindArray, "981e", "["},
+		{MapStart(-1), KindMap, "bf", "{_"},
+		{MapStart(1), KindMap, "a1", "{"},
+		{TagNumber(55799), KindTag, "d9d9f7", "55799("},
+		{SimpleValue(21), KindBool, "f5", "true"},
+		{SimpleValue(32), KindSimple, "f820", "simple(32)"},
+		{Undefined, KindUndefined, "f7", "undefined"},
+		{Break, KindBreak, "ff", "break"},
+	}
+	for _, tt := range tests {
+		if got := tt.tok.Kind(); got != tt.kind {
+			t.Errorf("%v.Kind() = %v, want %v", tt.tok, got, tt.kind)
+		}
+		if got := hex.EncodeToString(appendToken(nil, tt.tok)); got != tt.hex {
+			t.Errorf("appendToken(%v) = %s, want %s", tt.tok, got, tt.hex)
+		}
+		if got := tt.tok.String(); got != tt.str {
+			t.Errorf("Token.String() = %s, want %s", got, tt.str)
+		}
+	}
+
+	if got := NegInt(math.MaxUint64).Int(); got != math.MinInt64 {
+		t.Errorf("NegInt(MaxUint64).Int() = %d, want %d", got, int64(math.MinInt64))
+	}
+	if got := Uint(math.MaxUint64).Int(); got != math.MaxInt64 {
+		t.Errorf("Uint(MaxUint64).Int() = %d, want %d", got, int64(math.MaxInt64))
+	}
+	if got := Int(-5).Float(); got != -5 {
+		t.Errorf("Int(-5).Float() = %v, want -5", got)
+	}
+	if got := SimpleValue(22).SimpleValue(); got != 22 {
+		t.Errorf("SimpleValue(22).SimpleValue() = %d, want 22", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("String(\"\").Int() did not panic")
+		}
+	}()
+	String("").Int()
+}
+
+func TestFloat16(t *testing.T) {
+	// Every half-precision number must round trip.
+	for h := range uint32(1 << 16) {
+		f := float16to64(uint16(h))
+		if f != f {
+			continue
+		}
+		got, ok := float16(float32(f))
+		if !ok || got != uint16(h) {
+			t.Fatalf("float16(%v) = %#04x, %v, want %#04x, true", f, got, ok, h)
+		}
+	}
+	for _, f := range []float32{65520, 1e-8, 1 + 1.0/2048, 3.0517578e-05 * 1.5} {
+		if h, ok := float16(f); ok && float16to64(h) != float64(f) {
+			t.Errorf("float16(%v) = %#04x, true, which represents %v", f, h, float16to64(h))
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"io"
+	"math"
+	"unicode/utf8"
+)
+
+// Decoder is a streaming decoder for raw CBOR tokens and values.
+// It is used to read a stream of top-level CBOR data items,
+// also known as a CBOR sequence (RFC 8742).
+//
+// [Decoder.ReadToken] reads the next [Token], while [Decoder.ReadValue]
+// reads the next entire data item as a [Value]. The two may be mixed;
+// for example, the elements of an array may be read as values after
+// reading the start of the array with ReadToken.
+//
+// The decoder reports an error for data that is not well-formed, and,
+// unless otherwise specified by [AllowDuplicateKeys] and
+// [AllowInvalidUTF8], for maps with duplicate keys and text strings
+// that are not valid UTF-8.
+type Decoder struct {
+	r    io.Reader
+	buf  []byte
+	pos  int   // read position in buf
+	base int64 // offset of buf[0] in the input
+	err  error // sticky error from r
+
+	// fatal is a sticky syntactic error after which
+	// the state of the decoder is inconsistent.
+	fatal error
+
+	// retain is the offset of the start of the value being read
+	// by ReadValue, or -1. The data from that offset is kept in buf.
+	retain int64
+
+	state
+	opts options
+}
+
+// NewDecoder constructs a new streaming decoder reading from r.
+func NewDecoder(r io.Reader, opts ...Options) *Decoder {
+	d := new(Decoder)
+	d.Reset(r, opts...)
+	return d
+}
+
+// Reset resets a decoder such that it is reading afresh from r and
+// configured with the provided options.
+func (d *Decoder) Reset(r io.Reader, opts ...Options) {
+	d.reset(nil, r, opts...)
+}
+
+func (d *Decoder) reset(b []byte, r io.Reader, opts ...Options) {
+	if b == nil {
+		b = d.buf[:0]
+	}
+	*d = Decoder{r: r, buf: b, retain: -1, state: d.state}
+	d.opts.join(opts...)
+	d.state.reset(d.opts.get(optAllowDuplicateKeys), false)
+}
+
+// newBytesDecoder returns a decoder reading from b.
+func newBytesDecoder(b []byte, o *options) *Decoder {
+	d := &Decoder{buf: b, retain: -1, opts: *o}
+	d.state.reset(o.get(optAllowDuplicateKeys), false)
+	return d
+}
+
+// InputOffset returns the current input byte offset, which is the
+// offset of the next token or value to be read.
+func (d *Decoder) InputOffset() int64 {
+	return d.base + int64(d.pos)
+}
+
+// StackDepth returns the number of arrays, maps, tags and
+// indefinite-length strings that have been started but not completed.
+func (d *Decoder) StackDepth() int {
+	return len(d.stack)
+}
+
+// Options returns the options used to construct the decoder.
+func (d *Decoder) Options() Options {
+	o := d.opts
+	return &o
+}
+
+func (d *Decoder) bytesFrom(off int64) []byte {
+	return d.buf[off-d.base : d.pos]
+}
+
+func (d *Decoder) endMap(*frame) {}
+
+// fill ensures that at least n bytes are available at d.pos.
+func (d *Decoder) fill(n int) error {
+	for len(d.buf)-d.pos < n {
+		if d.err != nil {
+			return d.err
+		}
+		if d.r == nil {
+			d.err = io.EOF
+			continue
+		}
+
+		// Discard the data that is no longer needed.
+		keep := d.pos
+		if off := d.retainFrom(); off >= 0 {
+			keep = min(keep, int(off-d.base))
+		}
+		if d.retain >= 0 {
+			keep = min(keep, int(d.retain-d.base))
+		}
+		if keep > 0 {
+			d.buf = d.buf[:copy(d.buf, d.buf[keep:])]
+			d.pos -= keep
+			d.base += int64(keep)
+		}
+
+		// Grow the buffer geometrically rather than to the length of a
+		// string declared by the data, which might not be present.
+		if avail := cap(d.buf) - len(d.buf); avail < n-(len(d.buf)-d.pos) && avail < 4096 {
+			b := make([]byte, len(d.buf), max(2*cap(d.buf), 4096))
+			copy(b, d.buf)
+			d.buf = b
+		}
+		m, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+		d.buf = d.buf[:len(d.buf)+m]
+		if err != nil {
+			if err != io.EOF {
+				err = &ioError{"read", err}
+			}
+			d.err = err
+		}
+	}
+	return nil
+}
+
+// syntaxError returns a SyntacticError for err at the offset off.
+// The end of the input within a data item is reported as io.ErrUnexpectedEOF.
+func (d *Decoder) syntaxError(err error, off int64) error {
+	if _, ok := err.(*ioError); ok {
+		return err
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return &SyntacticError{ByteOffset: off, Err: err}
+}
+
+// peekToken parses the head of the next token without consuming it.
+// It returns the token and the length of its head.
+// At the end of the input between top-level items, it returns io.EOF.
+func (d *Decoder) peekToken() (Token, int, error) {
+	if d.fatal != nil {
+		return Token{}, 0, d.fatal
+	}
+	off := d.InputOffset()
+	if err := d.fill(1); err != nil {
+		if err == io.EOF && len(d.stack) == 0 && len(d.buf) == d.pos {
+			return Token{}, 0, io.EOF
+		}
+		return Token{}, 0, d.syntaxError(err, off)
+	}
+	n, err := headLen(d.buf[d.pos:])
+	if err != nil {
+		return Token{}, 0, d.syntaxError(err, off)
+	}
+	if err := d.fill(n); err != nil {
+		return Token{}, 0, d.syntaxError(err, off)
+	}
+	t, err := parseToken(d.buf[d.pos : d.pos+n])
+	if err != nil {
+		return Token{}, 0, d.syntaxError(err, off)
+	}
+	return t, n, nil
+}
+
+// next reads and validates the next token. The content of a
+// definite-length string is returned in b, which aliases the buffer
+// and is only valid until the next read.
+func (d *Decoder) next() (t Token, b []byte, err error) {
+	t, n, err := d.peekToken()
+	if err != nil {
+		return Token{}, nil, err
+	}
+	off := d.InputOffset()
+	if (t.kind == KindBytes || t.kind == KindString) && !t.indef {
+		if t.arg > math.MaxInt32 && t.arg > uint64(math.MaxInt-n) {
+			return Token{}, nil, d.syntaxError(errTooLong, off)
+		}
+		if err := d.fill(n + int(t.arg)); err != nil {
+			return Token{}, nil, d.syntaxError(err, off)
+		}
+		b = d.buf[d.pos+n : d.pos+n+int(t.arg)]
+		if t.kind == KindString && !d.opts.get(optAllowInvalidUTF8) && !utf8.Valid(b) {
+			return Token{}, nil, d.syntaxError(ErrInvalidUTF8, off)
+		}
+		n += len(b)
+	}
+	if err := d.begin(t.kind, t.indef, off); err != nil {
+		return Token{}, nil, d.syntaxError(err, off)
+	}
+	d.pos += n
+	if err := d.advance(t.kind, t.indef, t.arg, d); err != nil {
+		d.fatal = d.syntaxError(err, off)
+		return Token{}, nil, d.fatal
+	}
+	return t, b, nil
+}
+
+// PeekKind returns the kind of the next token without consuming it.
+// It returns [KindInvalid] if an error occurs; the error is reported by
+// the next call to [Decoder.ReadToken] or [Decoder.ReadValue].
+func (d *Decoder) PeekKind() Kind {
+	t, _, err := d.peekToken()
+	if err != nil {
+		return KindInvalid
+	}
+	return t.kind
+}
+
+// ReadToken reads the next [Token], advancing the read offset.
+// It returns [io.EOF] if there are no more tokens.
+func (d *Decoder) ReadToken() (Token, error) {
+	t, b, err := d.next()
+	if err != nil {
+		return Token{}, err
+	}
+	if t.kind == KindBytes || t.kind == KindString {
+		t.arg, t.str = 0, string(b)
+	}
+	return t, nil
+}
+
+// ReadValue reads the next data item in its entirety as a raw [Value],
+// advancing the read offset. The returned value aliases the internal
+// buffer of the decoder and is only valid until the next Peek, Read,
+// or Skip call. It returns [io.EOF] if there are no more values.
+//
+// Reading a "break" stop code is an error; it must be read with
+// [Decoder.ReadToken].
+func (d *Decoder) ReadValue() (Value, error) {
+	t, _, err := d.peekToken()
+	if err != nil {
+		return nil, err
+	}
+	start := d.InputOffset()
+	if t.kind == KindBreak {
+		return nil, d.syntaxError(errUnexpectedBrk, start)
+	}
+	d.retain = start
+	defer func() { d.retain = -1 }()
+	depth := len(d.stack)
+	for {
+		if _, _, err := d.next(); err != nil {
+			return nil, err
+		}
+		if len(d.stack) <= depth {
+			break
+		}
+	}
+	return Value(d.buf[start-d.base : d.pos]), nil
+}
+
+// SkipValue is semantically equivalent to calling [Decoder.ReadValue]
+// and discarding the result.
+func (d *Decoder) SkipValue() error {
+	_, err := d.ReadValue()
+	return err
+}
+
+// checkEOF reports an error unless the decoder is at the end of the input.
+func (d *Decoder) checkEOF() error {
+	switch _, _, err := d.peekToken(); {
+	case err == io.EOF:
+		return nil
+	case err != nil:
+		return err
+	}
+	return &SyntacticError{ByteOffset: d.InputOffset(), Err: errTrailingData}
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cbor implements encoding and decoding of the Concise Binary
+// Object Representation (CBOR) as specified in RFC 8949.
+// CBOR is a binary data format loosely based on the JSON data model,
+// used by protocols such as WebAuthn, COSE (RFC 9052) and CoAP.
+//
+// The package is organized like encoding/json/v2 and encoding/json/jsontext
+// combined: [Marshal] and [Unmarshal] convert between Go values and
+// CBOR data items, while the [Encoder] and [Decoder] types process
+// a stream of CBOR tokens or values.
+//
+// # Tokens and Values
+//
+// A CBOR data item consists of a head, encoding its major type and an
+// argument, possibly followed by content. A [Token] represents a head,
+// together with the content of a definite-length string: an integer, a
+// string, a floating-point number, a simple value, the start of an array
+// or map, a tag number, or the "break" stop code ending an
+// indefinite-length item. The elements of an array, the keys and values
+// of a map and the content of a tag follow as separate tokens.
+//
+// A [Value] is a []byte holding the encoding of a single, complete data
+// item. [Value.String] formats it in diagnostic notation, and
+// [Value.Canonicalize] converts it to the core deterministic encoding.
+//
+// # Well-formedness and validity
+//
+// The [Encoder] and [Decoder] accept only well-formed CBOR, and by
+// default also reject invalid data: text strings that are not valid
+// UTF-8 and maps with duplicate keys. Tag content is not validated
+// against the definition of the tag. Integers, lengths and
+// floating-point numbers are always encoded in their preferred
+// serialization, the shortest form that represents them.
+//
+// # Options
+//
+// Encoding, decoding, marshaling and unmarshaling are configured with
+// [Options], such as [Deterministic] or [RejectUnknownFields].
+// Options may be passed to any function taking options; those that do
+// not apply to an operation are ignored.
+package cbor
@@ -0,0 +1,214 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// flushThreshold is the size of the buffered output above which
+// the encoder writes it to the underlying io.Writer.
+const flushThreshold = 4096
+
+// Encoder is a streaming encoder from raw CBOR tokens and values.
+// It is used to write a stream of top-level CBOR data items,
+// also known as a CBOR sequence (RFC 8742).
+//
+// [Encoder.WriteToken] writes the next [Token], while [Encoder.WriteValue]
+// writes the next entire data item as a [Value]. The two may be mixed.
+// Integers, lengths and floating-point numbers are always written in
+// their preferred serialization (RFC 8949, section 4.1).
+//
+// The encoder reports an error for a sequence of tokens that does not
+// form well-formed data items, and, unless otherwise specified by
+// [AllowDuplicateKeys] and [AllowInvalidUTF8], for maps with duplicate
+// keys and text strings that are not valid UTF-8.
+// Output is buffered and written to the underlying io.Writer
+// when a top-level data item is complete, or when the buffer is large
+// and no map is being encoded.
+type Encoder struct {
+	w    io.Writer
+	buf  []byte
+	base int64 // offset of buf[0] in the output
+
+	// fatal is a sticky error after which
+	// the state of the encoder is inconsistent.
+	fatal error
+
+	// ptrDepth is the number of pointers and interfaces being marshaled,
+	// which bounds the recursion of cyclic Go values.
+	ptrDepth int
+
+	state
+	opts options
+}
+
+// NewEncoder constructs a new streaming encoder writing to w
+// configured with the provided options.
+func NewEncoder(w io.Writer, opts ...Options) *Encoder {
+	e := new(Encoder)
+	e.Reset(w, opts...)
+	return e
+}
+
+// Reset resets an encoder such that it is writing afresh to w and
+// configured with the provided options.
+func (e *Encoder) Reset(w io.Writer, opts ...Options) {
+	*e = Encoder{w: w, buf: e.buf[:0], state: e.state}
+	e.opts.join(opts...)
+	e.state.reset(e.opts.get(optAllowDuplicateKeys), e.opts.sortKeys())
+}
+
+// OutputOffset returns the current output byte offset, which is the
+// offset of the next token or value to be written.
+func (e *Encoder) OutputOffset() int64 {
+	return e.base + int64(len(e.buf))
+}
+
+// StackDepth returns the number of arrays, maps, tags and
+// indefinite-length strings that have been started but not completed.
+func (e *Encoder) StackDepth() int {
+	return len(e.stack)
+}
+
+// Options returns the options used to construct the encoder.
+func (e *Encoder) Options() Options {
+	o := e.opts
+	return &o
+}
+
+func (e *Encoder) bytesFrom(off int64) []byte {
+	return e.buf[off-e.base:]
+}
+
+// endMap sorts the entries of the map f if the encoder is deterministic.
+func (e *Encoder) endMap(f *frame) {
+	if !e.trackOffsets || len(f.offs) < 4 {
+		return
+	}
+	type entry struct{ key, entry []byte }
+	start, end := f.offs[0]-e.base, int64(len(e.buf))
+	if f.indef {
+		end-- // the break stop code
+	}
+	entries := make([]entry, len(f.offs)/2)
+	for i := range entries {
+		k, v, next := f.offs[2*i]-e.base, f.offs[2*i+1]-e.base, end
+		if 2*i+2 < len(f.offs) {
+			next = f.offs[2*i+2] - e.base
+		}
+		entries[i] = entry{e.buf[k:v], e.buf[k:next]}
+	}
+	slices.SortStableFunc(entries, func(x, y entry) int {
+		return bytes.Compare(x.key, y.key)
+	})
+	sorted := make([]byte, 0, end-start)
+	for _, x := range entries {
+		sorted = append(sorted, x.entry...)
+	}
+	copy(e.buf[start:end], sorted)
+}
+
+// syntaxError returns a SyntacticError for err at the offset off.
+func (e *Encoder) syntaxError(err error, off int64) error {
+	return &SyntacticError{ByteOffset: off, Err: err}
+}
+
+// WriteToken writes the next token and advances the internal write offset.
+//
+// The provided token must be consistent with the CBOR grammar.
+// For example, a "break" may only end an indefinite-length item, and
+// the content of an indefinite-length string may only consist of
+// definite-length strings of the same kind.
+func (e *Encoder) WriteToken(t Token) error {
+	if e.fatal != nil {
+		return e.fatal
+	}
+	off := e.OutputOffset()
+	switch {
+	case t.kind == KindInvalid:
+		return e.syntaxError(errInvalidToken, off)
+	case t.kind == KindSimple && 24 <= t.arg && t.arg < 32:
+		return e.syntaxError(errInvalidSimple, off)
+	case t.indef && e.opts.get(optCoreDeterministic):
+		return e.syntaxError(errIndefDisabled, off)
+	case t.kind == KindString && !t.indef && !e.opts.get(optAllowInvalidUTF8) && !utf8.ValidString(t.str):
+		return e.syntaxError(ErrInvalidUTF8, off)
+	}
+	if err := e.begin(t.kind, t.indef, off); err != nil {
+		return e.syntaxError(err, off)
+	}
+	e.buf = appendToken(e.buf, t)
+	return e.advanceItem(t.kind, t.indef, t.arg, off, false)
+}
+
+// WriteValue writes the next raw value and advances the internal write offset.
+//
+// The provided value must be a single well-formed data item.
+// It is written as is, unless the encoder is [Deterministic], in which
+// case its maps are sorted, or [CoreDeterministic], in which case it is
+// converted to its core deterministic encoding.
+func (e *Encoder) WriteValue(v Value) error {
+	if e.fatal != nil {
+		return e.fatal
+	}
+	off := e.OutputOffset()
+	if err := v.validate(&e.opts); err != nil {
+		return err
+	}
+	if e.opts.sortKeys() {
+		// Canonicalizing, which converts indefinite-length items to
+		// definite-length ones, also sorts every map as required.
+		cv := slices.Clone(v)
+		if err := cv.canonicalize(&e.opts, !e.opts.get(optCoreDeterministic)); err != nil {
+			return err
+		}
+		v = cv
+	}
+	n, _ := headLen(v)
+	t, _ := parseToken(v[:n])
+	if err := e.begin(t.kind, t.indef, off); err != nil {
+		return e.syntaxError(err, off)
+	}
+	e.buf = append(e.buf, v...)
+	return e.advanceItem(t.kind, t.indef, t.arg, off, true)
+}
+
+// advanceItem updates the state after a token, or an entire data item
+// if whole is set, was appended to the buffer, and flushes the buffer
+// when possible.
+func (e *Encoder) advanceItem(k Kind, indef bool, arg uint64, off int64, whole bool) error {
+	var err error
+	if whole {
+		err = e.end(e)
+	} else {
+		err = e.advance(k, indef, arg, e)
+	}
+	if err != nil {
+		e.fatal = e.syntaxError(err, off)
+		return e.fatal
+	}
+	if len(e.stack) == 0 || e.maps == 0 && len(e.buf) > flushThreshold {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *Encoder) flush() error {
+	if e.w == nil || len(e.buf) == 0 {
+		return nil
+	}
+	n, err := e.w.Write(e.buf)
+	e.base += int64(n)
+	e.buf = e.buf[:copy(e.buf, e.buf[n:])]
+	if err != nil {
+		e.fatal = &ioError{"write", err}
+		return e.fatal
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+const errorPrefix = "cbor: "
+
+var (
+	// ErrDuplicateKey indicates that a map contains a duplicate key.
+	// It is wrapped in a [SyntacticError].
+	ErrDuplicateKey = errors.New("duplicate map key")
+
+	// ErrInvalidUTF8 indicates that a text string contains invalid UTF-8.
+	// It is wrapped in a [SyntacticError].
+	ErrInvalidUTF8 = errors.New("invalid UTF-8 in text string")
+
+	// ErrUnknownField indicates that a map key does not match any field
+	// of the Go struct being unmarshaled into.
+	// It is wrapped in a [SemanticError] and only reported
+	// if [RejectUnknownFields] is specified.
+	ErrUnknownField = errors.New("unknown field")
+
+	// SkipFunc may be returned by [MarshalToFunc] and [UnmarshalFromFunc]
+	// functions to indicate that the value should be handled by the next
+	// applicable marshaler or unmarshaler. It is an error to return it
+	// after reading or writing any tokens.
+	SkipFunc = errors.New("cbor: skip function")
+)
+
+var (
+	errMaxDepth       = errors.New("exceeded max depth")
+	errTrailingData   = errors.New("unexpected data after top-level value")
+	errReserved       = errors.New("reserved additional information value")
+	errInvalidSimple  = errors.New("invalid two-byte encoding of simple value")
+	errUnexpectedBrk  = errors.New("unexpected break")
+	errInvalidIndef   = errors.New("invalid indefinite-length item")
+	errInvalidChunk   = errors.New("invalid chunk of indefinite-length string")
+	errIndefDisabled  = errors.New("indefinite-length item not allowed in core deterministic encoding")
+	errIncomplete     = errors.New("incomplete data item")
+	errInvalidToken   = errors.New("invalid token")
+	errTooLong        = errors.New("length too large")
+	errMultipleValues = errors.New("method wrote or read more than one value")
+	errNoValue        = errors.New("method did not write or read a value")
+)
+
+// SyntacticError is a description of a syntactic error that occurred when
+// encoding or decoding CBOR that is not well-formed or not valid.
+//
+// The contents of this error as produced by this package may change over time.
+type SyntacticError struct {
+	// ByteOffset indicates that an error occurred after this byte offset.
+	ByteOffset int64
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *SyntacticError) Error() string {
+	return errorPrefix + e.Err.Error() + " at byte offset " + strconv.FormatInt(e.ByteOffset, 10)
+}
+
+func (e *SyntacticError) Unwrap() error {
+	return e.Err
+}
+
+// SemanticError describes an error determining the meaning of CBOR data
+// as Go data or vice versa.
+//
+// The contents of this error as produced by this package may change over time.
+type SemanticError struct {
+	action string // either "marshal" or "unmarshal"
+
+	// ByteOffset indicates that an error occurred after this byte offset.
+	ByteOffset int64
+
+	// CBORKind is the kind of the CBOR data item, if known.
+	CBORKind Kind
+
+	// GoType is the Go type that could not be handled.
+	GoType reflect.Type
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *SemanticError) Error() string {
+	s := errorPrefix + "cannot " + e.action
+	if e.CBORKind != KindInvalid {
+		if e.action == "marshal" {
+			s += " into CBOR " + e.CBORKind.String()
+		} else {
+			s += " CBOR " + e.CBORKind.String()
+		}
+	}
+	if e.GoType != nil {
+		if e.action == "marshal" {
+			s += " from Go " + e.GoType.String()
+		} else {
+			s += " into Go " + e.GoType.String()
+		}
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s + " at byte offset " + strconv.FormatInt(e.ByteOffset, 10)
+}
+
+func (e *SemanticError) Unwrap() error {
+	return e.Err
+}
+
+// ioError wraps an error returned by the underlying io.Reader or io.Writer.
+type ioError struct {
+	action string // either "read" or "write"
+	err    error
+}
+
+func (e *ioError) Error() string {
+	return errorPrefix + e.action + " error: " + e.err.Error()
+}
+
+func (e *ioError) Unwrap() error {
+	return e.err
+}
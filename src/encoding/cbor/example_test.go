@@ -0,0 +1,138 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor_test
+
+import (
+	"bytes"
+	"encoding/cbor"
+	"fmt"
+	"io"
+	"log"
+)
+
+func Example() {
+	type Sensor struct {
+		ID       string    `cbor:"id"`
+		Readings []float64 `cbor:"readings"`
+		Unit     string    `cbor:"unit,omitempty"`
+	}
+
+	in := Sensor{ID: "t1", Readings: []float64{21.5, 22}}
+	b, err := cbor.Marshal(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%x\n", b)
+	fmt.Println(cbor.Value(b))
+
+	var out Sensor
+	if err := cbor.Unmarshal(b, &out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%+v\n", out)
+
+	// Output:
+	// a26269646274316872656164696e677382f94d60f94d80
+	// {"id": "t1", "readings": [21.5, 22.0]}
+	// {ID:t1 Readings:[21.5 22] Unit:}
+}
+
+// COSE and similar protocols use small integers as map keys and encode
+// structures as arrays to save space.
+func Example_compactStructs() {
+	type Header struct {
+		Alg int    `cbor:"1,keyasint"`
+		Kid []byte `cbor:"4,keyasint,omitempty"`
+	}
+	type Point struct {
+		_    struct{} `cbor:",toarray"`
+		X, Y int
+	}
+
+	b, err := cbor.Marshal([]any{Header{Alg: -7, Kid: []byte("k1")}, Point{X: 3, Y: -4}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(cbor.Value(b))
+
+	// Output:
+	// [{1: -7, 4: h'6b31'}, [3, -4]]
+}
+
+// Deterministic encoding produces the same bytes for the same data,
+// which is required when CBOR is signed or hashed.
+func ExampleCoreDeterministic() {
+	m := map[any]any{"b": 2, 10: "x", "a": 1, -1: true}
+	b, err := cbor.Marshal(m, cbor.CoreDeterministic(true))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(cbor.Value(b))
+
+	// Output:
+	// {10: "x", -1: true, "a": 1, "b": 2}
+}
+
+// A Decoder reads a sequence of data items token by token.
+// Definite-length arrays and maps end after their last item,
+// while indefinite-length ones end with a break token.
+func ExampleDecoder() {
+	in := []byte{
+		0x9f, 0x01, 0x82, 0x02, 0x03, 0xff, // [_ 1, [2, 3]]
+		0xc1, 0x1a, 0x51, 0x4b, 0x67, 0xb0, // 1(1363896240)
+	}
+	d := cbor.NewDecoder(bytes.NewReader(in))
+	for {
+		tok, err := d.ReadToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d: %v %v\n", d.StackDepth(), tok.Kind(), tok)
+	}
+
+	// Output:
+	// 1: array [_
+	// 1: unsigned integer 1
+	// 2: array [
+	// 2: unsigned integer 2
+	// 1: unsigned integer 3
+	// 0: break break
+	// 1: tag 1(
+	// 0: unsigned integer 1363896240
+}
+
+// Values can be converted to diagnostic notation for debugging.
+func ExampleValue_String() {
+	v := cbor.Value{0xbf, 0x63, 0x46, 0x75, 0x6e, 0xf5, 0x63, 0x41, 0x6d, 0x74, 0x21, 0xff}
+	fmt.Println(v)
+	if err := v.Canonicalize(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%x %v\n", []byte(v), v)
+
+	// Output:
+	// {_ "Fun": true, "Amt": -2}
+	// a263416d74216346756ef5 {"Amt": -2, "Fun": true}
+}
+
+// Custom marshalers can be provided for types from other packages.
+func ExampleWithMarshalers() {
+	type Celsius float64
+	m := cbor.MarshalFunc(func(c Celsius) ([]byte, error) {
+		// Use tag 1001 as an example application-specific tag.
+		return cbor.Marshal(cbor.Tag{Number: 1001, Content: float64(c)})
+	})
+	b, err := cbor.Marshal([]Celsius{-40, 21.5}, cbor.WithMarshalers(m))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(cbor.Value(b))
+
+	// Output:
+	// [1001(-40.0), 1001(21.5)]
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// structFields describes how a Go struct type maps to a CBOR map,
+// or to a CBOR array if toArray is set.
+type structFields struct {
+	fields  []structField
+	byName  map[string]*structField
+	byInt   map[int64]*structField
+	toArray bool
+}
+
+type structField struct {
+	index []int // index sequence for reflect.Value.FieldByIndex
+	typ   reflect.Type
+
+	name   string // text string key, unless intKey is set
+	intKey bool
+	key    int64 // integer key, if intKey is set
+
+	omitzero  bool
+	omitempty bool
+	tagged    bool // name was specified by a struct tag
+}
+
+// keyToken returns the token for the map key of f.
+func (f *structField) keyToken() Token {
+	if f.intKey {
+		return Int(f.key)
+	}
+	return String(f.name)
+}
+
+// makeStructFields returns the fields of the struct type t, following
+// the same rules as encoding/json for embedded structs: the fields of
+// an embedded struct without a name in its tag are promoted, and among
+// conflicting fields only the least nested one is kept, or the tagged
+// one among equally nested fields.
+func makeStructFields(t reflect.Type) (*structFields, error) {
+	sf := new(structFields)
+	var all []structField
+
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+	current, next := []queued{}, []queued{{t, nil}}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+			for i := range q.typ.NumField() {
+				f := q.typ.Field(i)
+				tag, hasTag := f.Tag.Lookup("cbor")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				if f.Name == "_" {
+					if q.index == nil && hasOption(opts, "toarray") {
+						sf.toArray = true
+					}
+					continue
+				}
+				if f.Anonymous {
+					ft := f.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if !f.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+					if name == "" && ft.Kind() == reflect.Struct {
+						if f.Type.Kind() == reflect.Pointer && !f.IsExported() {
+							// Cannot allocate an unexported embedded pointer.
+							continue
+						}
+						next = append(next, queued{ft, append(slices.Clip(q.index), i)})
+						continue
+					}
+				} else if !f.IsExported() {
+					continue
+				}
+				field := structField{
+					index:  append(slices.Clip(q.index), i),
+					typ:    f.Type,
+					name:   f.Name,
+					tagged: hasTag && name != "",
+				}
+				if name != "" {
+					field.name = name
+				}
+				for opts != "" {
+					var opt string
+					opt, opts, _ = strings.Cut(opts, ",")
+					switch opt {
+					case "omitzero":
+						field.omitzero = true
+					case "omitempty":
+						field.omitempty = true
+					case "keyasint":
+						n, err := strconv.ParseInt(name, 10, 64)
+						if err != nil {
+							return nil, errors.New("Go struct field " + f.Name + " has invalid integer key " + strconv.Quote(name))
+						}
+						field.intKey, field.key = true, n
+					case "toarray":
+						return nil, errors.New("Go struct field " + f.Name + ` has "toarray" option, which is only valid on a field named _`)
+					default:
+						return nil, errors.New("Go struct field " + f.Name + " has unknown option " + strconv.Quote(opt))
+					}
+				}
+				all = append(all, field)
+			}
+		}
+	}
+
+	// Keep the dominant field for each key, in the order of the struct.
+	slices.SortStableFunc(all, func(x, y structField) int {
+		if c := strings.Compare(x.keyString(), y.keyString()); c != 0 {
+			return c
+		}
+		if c := len(x.index) - len(y.index); c != 0 {
+			return c
+		}
+		if x.tagged != y.tagged {
+			if x.tagged {
+				return -1
+			}
+			return +1
+		}
+		return 0
+	})
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].keyString() == all[i].keyString() {
+			j++
+		}
+		dominant := j-i == 1 ||
+			len(all[i+1].index) > len(all[i].index) ||
+			all[i].tagged && !all[i+1].tagged
+		if dominant {
+			sf.fields = append(sf.fields, all[i])
+		}
+		i = j
+	}
+	slices.SortFunc(sf.fields, func(x, y structField) int {
+		return slices.Compare(x.index, y.index)
+	})
+
+	sf.byName = make(map[string]*structField)
+	sf.byInt = make(map[int64]*structField)
+	for i := range sf.fields {
+		f := &sf.fields[i]
+		if f.intKey {
+			sf.byInt[f.key] = f
+		} else {
+			sf.byName[f.name] = f
+		}
+	}
+	return sf, nil
+}
+
+// keyString distinguishes the keys of fields for conflict resolution.
+func (f *structField) keyString() string {
+	if f.intKey {
+		return "\x00" + strconv.FormatInt(f.key, 10)
+	}
+	return f.name
+}
+
+func hasOption(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+// Options configure [Marshal], [MarshalWrite], [MarshalEncode],
+// [Unmarshal], [UnmarshalRead], [UnmarshalDecode], [NewEncoder] and
+// [NewDecoder] with specific features. Each function takes a variadic
+// list of options, where properties set in later options override
+// the value of previously set properties.
+//
+// Options represent either a singular option or a set of options.
+// The constructors (e.g., [Deterministic]) return a singular option value,
+// while [JoinOptions] returns a set of options.
+//
+// Options that do not affect a particular operation are ignored.
+// For example, [RejectUnknownFields] has no effect when marshaling.
+type Options interface {
+	cborOptions()
+}
+
+// optionFlags is a bit set of boolean options.
+type optionFlags uint32
+
+const (
+	optDeterministic optionFlags = 1 << iota
+	optCoreDeterministic
+	optAllowDuplicateKeys
+	optAllowInvalidUTF8
+	optRejectUnknownFields
+	optFormatNilSliceAsNull
+	optFormatNilMapAsNull
+
+	// optMarshalers and optUnmarshalers are only used to report
+	// which of the non-boolean options are present.
+	optMarshalers
+	optUnmarshalers
+)
+
+// options is the set of options in effect, as well as the
+// representation of the options returned by [JoinOptions].
+type options struct {
+	present optionFlags // which options are set
+	values  optionFlags // values of the boolean options that are set
+
+	marshalers   *Marshalers
+	unmarshalers *Unmarshalers
+}
+
+func (*options) cborOptions() {}
+
+// boolOption is a singular boolean option.
+type boolOption struct {
+	flag  optionFlags
+	value bool
+}
+
+func (boolOption) cborOptions() {}
+
+// marshalersOption and unmarshalersOption are singular
+// options returned by [WithMarshalers] and [WithUnmarshalers].
+type (
+	marshalersOption   struct{ m *Marshalers }
+	unmarshalersOption struct{ u *Unmarshalers }
+)
+
+func (marshalersOption) cborOptions()   {}
+func (unmarshalersOption) cborOptions() {}
+
+// JoinOptions coalesces the provided list of options into a single Options.
+// Properties set in later options override the value of previously set
+// properties.
+func JoinOptions(srcs ...Options) Options {
+	o := new(options)
+	o.join(srcs...)
+	return o
+}
+
+// GetOption returns the value stored in opts with the provided setter,
+// reporting whether the value is present.
+//
+// Example usage:
+//
+//	v, ok := cbor.GetOption(opts, cbor.Deterministic)
+func GetOption[T any](opts Options, setter func(T) Options) (T, bool) {
+	var o options
+	o.join(opts)
+	var zero T
+	switch s := setter(zero).(type) {
+	case boolOption:
+		if o.present&s.flag == 0 {
+			return zero, false
+		}
+		v := o.values&s.flag != 0
+		return any(v).(T), true
+	case marshalersOption:
+		if o.present&optMarshalers == 0 {
+			return zero, false
+		}
+		return any(o.marshalers).(T), true
+	case unmarshalersOption:
+		if o.present&optUnmarshalers == 0 {
+			return zero, false
+		}
+		return any(o.unmarshalers).(T), true
+	}
+	return zero, false
+}
+
+// join merges srcs into o.
+func (o *options) join(srcs ...Options) {
+	for _, src := range srcs {
+		switch src := src.(type) {
+		case nil:
+		case boolOption:
+			o.set(src.flag, src.value)
+		case marshalersOption:
+			o.present |= optMarshalers
+			o.marshalers = src.m
+		case unmarshalersOption:
+			o.present |= optUnmarshalers
+			o.unmarshalers = src.u
+		case *options:
+			if src == nil {
+				continue
+			}
+			o.present |= src.present
+			o.values = o.values&^src.present | src.values&src.present
+			if src.present&optMarshalers != 0 {
+				o.marshalers = src.marshalers
+			}
+			if src.present&optUnmarshalers != 0 {
+				o.unmarshalers = src.unmarshalers
+			}
+		}
+	}
+}
+
+func (o *options) set(flag optionFlags, v bool) {
+	o.present |= flag
+	if v {
+		o.values |= flag
+	} else {
+		o.values &^= flag
+	}
+}
+
+// get reports whether the boolean option flag is set to true.
+func (o *options) get(flag optionFlags) bool {
+	return o.values&flag != 0
+}
+
+// sortKeys reports whether map keys must be sorted.
+func (o *options) sortKeys() bool {
+	return o.values&(optDeterministic|optCoreDeterministic) != 0
+}
+
+// Deterministic specifies that the encoder sorts the key-value pairs of
+// every map in the bytewise lexicographic order of their encoded keys,
+// so that equal Go values produce identical output.
+// Unlike [CoreDeterministic], indefinite-length items are permitted.
+//
+// This affects encoding only.
+func Deterministic(v bool) Options {
+	return boolOption{optDeterministic, v}
+}
+
+// CoreDeterministic specifies that the encoder produces the core
+// deterministic encoding of RFC 8949, section 4.2.1:
+// preferred serialization, definite-length items only, and map keys
+// sorted in the bytewise lexicographic order of their encodings.
+// Writing the start of an indefinite-length item is an error,
+// while raw values written with [Encoder.WriteValue] are converted
+// to their core deterministic encoding.
+//
+// This affects encoding only.
+func CoreDeterministic(v bool) Options {
+	return boolOption{optCoreDeterministic, v}
+}
+
+// AllowDuplicateKeys specifies that maps may contain duplicate keys.
+// RFC 8949, section 5.6 considers such maps invalid, so by default the
+// encoder and decoder report an error wrapping [ErrDuplicateKey].
+// Keys are compared by their encodings, after reducing integer, float
+// and simple value keys to their preferred serialization.
+//
+// This affects either encoding or decoding.
+func AllowDuplicateKeys(v bool) Options {
+	return boolOption{optAllowDuplicateKeys, v}
+}
+
+// AllowInvalidUTF8 specifies that text strings may contain invalid UTF-8.
+// By default, the encoder and decoder report an error wrapping
+// [ErrInvalidUTF8].
+//
+// This affects either encoding or decoding.
+func AllowInvalidUTF8(v bool) Options {
+	return boolOption{optAllowInvalidUTF8, v}
+}
+
+// RejectUnknownFields specifies that unmarshaling a map into a Go struct
+// reports an error wrapping [ErrUnknownField] for a key that does not
+// match any field. By default, unknown keys are skipped.
+//
+// This affects unmarshaling only.
+func RejectUnknownFields(v bool) Options {
+	return boolOption{optRejectUnknownFields, v}
+}
+
+// FormatNilSliceAsNull specifies that a nil Go slice is marshaled as
+// CBOR null instead of an empty array or byte string.
+//
+// This affects marshaling only.
+func FormatNilSliceAsNull(v bool) Options {
+	return boolOption{optFormatNilSliceAsNull, v}
+}
+
+// FormatNilMapAsNull specifies that a nil Go map is marshaled as
+// CBOR null instead of an empty map.
+//
+// This affects marshaling only.
+func FormatNilMapAsNull(v bool) Options {
+	return boolOption{optFormatNilMapAsNull, v}
+}
+
+// WithMarshalers specifies a list of type-specific marshalers to use,
+// which can be used to override the default marshal behavior for values
+// of particular types.
+//
+// This affects marshaling only.
+func WithMarshalers(v *Marshalers) Options {
+	return marshalersOption{v}
+}
+
+// WithUnmarshalers specifies a list of type-specific unmarshalers to use,
+// which can be used to override the default unmarshal behavior for values
+// of particular types.
+//
+// This affects unmarshaling only.
+func WithUnmarshalers(v *Unmarshalers) Options {
+	return unmarshalersOption{v}
+}
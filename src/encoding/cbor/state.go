@@ -0,0 +1,301 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// maxNestingDepth is the maximum number of nested arrays, maps, tags and
+// indefinite-length strings. It bounds the recursion of this package.
+const maxNestingDepth = 10000
+
+// frame is an array, map, tag or indefinite-length string
+// whose content is being encoded or decoded.
+type frame struct {
+	kind  Kind // KindArray, KindMap, KindTag, KindBytes or KindString
+	indef bool
+	n     uint64 // number of items of a definite-length item; two per map entry
+	count uint64 // number of items completed so far
+
+	keyStart int64           // offset of the current map key
+	keys     map[string]bool // keys seen so far, by canonicalKey
+	offs     []int64         // offsets of every map key and value, for sorting
+}
+
+// isString reports whether f is an indefinite-length string,
+// whose content is a sequence of definite-length strings.
+func (f *frame) isString() bool {
+	return f.kind == KindBytes || f.kind == KindString
+}
+
+// stateHooks provides access to the encoded data to the state machine.
+type stateHooks interface {
+	// bytesFrom returns the data from the offset off to the end of the
+	// last token, which are retained while a map key is being processed.
+	bytesFrom(off int64) []byte
+
+	// endMap is called when the last entry of a map is complete.
+	endMap(f *frame)
+}
+
+// state is the state machine tracking the nesting of data items,
+// shared by the [Encoder] and the [Decoder]. It validates the sequence
+// of tokens and detects duplicate map keys.
+type state struct {
+	stack []frame
+	maps  int    // number of maps in stack
+	total uint64 // number of top-level items completed
+
+	allowDup     bool // do not detect duplicate map keys
+	trackOffsets bool // record the offsets of map keys and values
+}
+
+func (s *state) reset(allowDup, trackOffsets bool) {
+	clear(s.stack)
+	*s = state{stack: s.stack[:0], allowDup: allowDup, trackOffsets: trackOffsets}
+}
+
+func (s *state) top() *frame {
+	if len(s.stack) == 0 {
+		return nil
+	}
+	return &s.stack[len(s.stack)-1]
+}
+
+// items returns the number of items completed in the innermost frame.
+func (s *state) items() uint64 {
+	if f := s.top(); f != nil {
+		return f.count
+	}
+	return s.total
+}
+
+// begin validates a token of kind k starting at offset off,
+// before it is added to the data.
+func (s *state) begin(k Kind, indef bool, off int64) error {
+	f := s.top()
+	switch {
+	case f == nil:
+		if k == KindBreak {
+			return errUnexpectedBrk
+		}
+	case f.isString():
+		if k != KindBreak && (k != f.kind || indef) {
+			return errInvalidChunk
+		}
+	case k == KindBreak:
+		if !f.indef || f.kind == KindMap && f.count%2 != 0 {
+			return errUnexpectedBrk
+		}
+	case f.kind == KindMap:
+		if f.count%2 == 0 {
+			f.keyStart = off
+		}
+		if s.trackOffsets {
+			f.offs = append(f.offs, off)
+		}
+	}
+	return nil
+}
+
+// advance updates the state after a token of kind k with the argument arg
+// has been added to the data.
+func (s *state) advance(k Kind, indef bool, arg uint64, h stateHooks) error {
+	switch k {
+	case KindBreak:
+		s.pop(h)
+		return s.end(h)
+	case KindArray, KindMap:
+		if !indef && arg == 0 {
+			return s.end(h)
+		}
+		if k == KindMap {
+			if arg > math.MaxUint64/2 {
+				return errTooLong
+			}
+			arg *= 2
+		}
+		return s.push(frame{kind: k, indef: indef, n: arg})
+	case KindTag:
+		return s.push(frame{kind: k, n: 1})
+	case KindBytes, KindString:
+		if indef {
+			return s.push(frame{kind: k, indef: true})
+		}
+	}
+	return s.end(h)
+}
+
+func (s *state) push(f frame) error {
+	if len(s.stack) >= maxNestingDepth {
+		return errMaxDepth
+	}
+	if f.kind == KindMap {
+		s.maps++
+	}
+	if len(s.stack) < cap(s.stack) {
+		// Reuse the memory of the frame previously at this depth.
+		old := s.stack[:len(s.stack)+1][len(s.stack)]
+		clear(old.keys)
+		f.keys, f.offs = old.keys, old.offs[:0]
+	}
+	s.stack = append(s.stack, f)
+	return nil
+}
+
+func (s *state) pop(h stateHooks) {
+	f := s.top()
+	if f.kind == KindMap {
+		s.maps--
+		h.endMap(f)
+	}
+	s.stack = s.stack[:len(s.stack)-1]
+}
+
+// end records the completion of a data item, which may complete
+// the enclosing items.
+func (s *state) end(h stateHooks) error {
+	for f := s.top(); f != nil; f = s.top() {
+		if f.isString() {
+			return nil // a chunk of an indefinite-length string
+		}
+		f.count++
+		if f.kind == KindMap && f.count%2 != 0 && !s.allowDup {
+			key := canonicalKey(h.bytesFrom(f.keyStart))
+			if f.keys == nil {
+				f.keys = make(map[string]bool)
+			}
+			if f.keys[key] {
+				return ErrDuplicateKey
+			}
+			f.keys[key] = true
+		}
+		if f.indef || f.count < f.n {
+			return nil
+		}
+		s.pop(h)
+	}
+	s.total++
+	return nil
+}
+
+// retainFrom returns the offset of the earliest map key being processed,
+// or -1 if none. The data from that offset must remain available.
+func (s *state) retainFrom() int64 {
+	if s.allowDup || s.maps == 0 {
+		return -1
+	}
+	for i := range s.stack {
+		if f := &s.stack[i]; f.kind == KindMap && f.count%2 == 0 {
+			return f.keyStart
+		}
+	}
+	return -1
+}
+
+// canonicalKey returns the encoding of a map key used to detect duplicate
+// keys. Integers, floats, simple values and definite-length strings are
+// reduced to their preferred serialization; other keys are compared as is.
+func canonicalKey(b []byte) string {
+	n, err := headLen(b)
+	if err != nil || n > len(b) {
+		return string(b)
+	}
+	t, err := parseToken(b[:n])
+	if err != nil || t.indef {
+		return string(b)
+	}
+	switch t.kind {
+	case KindBytes, KindString:
+		if uint64(len(b)-n) != t.arg {
+			return string(b)
+		}
+		t.str = string(b[n:])
+	case KindArray, KindMap, KindTag:
+		if t.kind != KindTag && t.arg == 0 && n == len(b) {
+			break
+		}
+		return string(b)
+	default:
+		if n != len(b) {
+			return string(b)
+		}
+	}
+	return string(appendToken(nil, t))
+}
+
+// headLen returns the length of the head whose initial byte is b[0].
+func headLen(b []byte) (int, error) {
+	switch ai := b[0] & 0x1f; {
+	case ai < 24 || ai == aiIndefinite:
+		return 1, nil
+	case ai < 28:
+		return 1 + 1<<(ai-24), nil
+	}
+	return 0, errReserved
+}
+
+// parseToken parses the head b, whose length has been checked with headLen,
+// into a token without the content of a string. The arg field of a string
+// token is the length of its content.
+func parseToken(b []byte) (Token, error) {
+	major, ai := b[0]&0xe0, b[0]&0x1f
+	var arg uint64
+	switch len(b) {
+	case 1:
+		arg = uint64(ai)
+	case 2:
+		arg = uint64(b[1])
+	case 3:
+		arg = uint64(binary.BigEndian.Uint16(b[1:]))
+	case 5:
+		arg = uint64(binary.BigEndian.Uint32(b[1:]))
+	case 9:
+		arg = binary.BigEndian.Uint64(b[1:])
+	}
+	indef := ai == aiIndefinite
+	if indef {
+		arg = 0
+	}
+	switch major {
+	case majorUint, majorNegInt, majorTag:
+		if indef {
+			return Token{}, errInvalidIndef
+		}
+		return Token{kind: [...]Kind{KindUint, KindNegInt, 6: KindTag}[major>>5], arg: arg}, nil
+	case majorBytes:
+		return Token{kind: KindBytes, indef: indef, arg: arg}, nil
+	case majorString:
+		return Token{kind: KindString, indef: indef, arg: arg}, nil
+	case majorArray:
+		return Token{kind: KindArray, indef: indef, arg: arg}, nil
+	case majorMap:
+		return Token{kind: KindMap, indef: indef, arg: arg}, nil
+	}
+	switch ai {
+	case 20, 21:
+		return Token{kind: KindBool, arg: arg - 20}, nil
+	case 22:
+		return Null, nil
+	case 23:
+		return Undefined, nil
+	case 24:
+		if arg < 32 {
+			return Token{}, errInvalidSimple
+		}
+		return Token{kind: KindSimple, arg: arg}, nil
+	case 25:
+		return Token{kind: KindFloat, arg: math.Float64bits(float16to64(uint16(arg)))}, nil
+	case 26:
+		return Token{kind: KindFloat, arg: math.Float64bits(float64(math.Float32frombits(uint32(arg))))}, nil
+	case 27:
+		return Token{kind: KindFloat, arg: arg}, nil
+	case aiIndefinite:
+		return Break, nil
+	}
+	return Token{kind: KindSimple, arg: arg}, nil
+}
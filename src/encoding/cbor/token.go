@@ -0,0 +1,459 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// Kind represents the kind of a CBOR data item or [Token].
+type Kind byte
+
+const (
+	KindInvalid   Kind = iota
+	KindUint           // unsigned integer (major type 0)
+	KindNegInt         // negative integer (major type 1)
+	KindBytes          // byte string (major type 2)
+	KindString         // text string (major type 3)
+	KindArray          // array (major type 4)
+	KindMap            // map (major type 5)
+	KindTag            // tag (major type 6)
+	KindBool           // false or true
+	KindNull           // null
+	KindUndefined      // undefined
+	KindSimple         // other simple value
+	KindFloat          // floating-point number
+	KindBreak          // "break" stop code of an indefinite-length item
+)
+
+var kindNames = [...]string{
+	KindInvalid:   "invalid",
+	KindUint:      "unsigned integer",
+	KindNegInt:    "negative integer",
+	KindBytes:     "byte string",
+	KindString:    "text string",
+	KindArray:     "array",
+	KindMap:       "map",
+	KindTag:       "tag",
+	KindBool:      "boolean",
+	KindNull:      "null",
+	KindUndefined: "undefined",
+	KindSimple:    "simple value",
+	KindFloat:     "floating-point number",
+	KindBreak:     "break",
+}
+
+// String returns a description of the kind, such as "text string".
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Token represents a CBOR token: the head of a data item, with the
+// content of a definite-length string, or a "break" stop code.
+// Tokens do not represent entire arrays, maps or tagged data items;
+// the head of such an item is followed by the tokens of its elements
+// or content. A [Value] represents an entire data item.
+//
+// An indefinite-length byte or text string starts with the token returned
+// by [BytesStart] or [StringStart], followed by definite-length strings
+// of the same kind and a [Break].
+//
+// The zero Token is invalid.
+type Token struct {
+	kind  Kind
+	indef bool   // indefinite-length string, array or map
+	arg   uint64 // argument of an integer, array, map, tag or simple value, or float64 bits
+	str   string // content of a definite-length string
+}
+
+// Tokens of the simple values.
+var (
+	False     = Token{kind: KindBool}
+	True      = Token{kind: KindBool, arg: 1}
+	Null      = Token{kind: KindNull}
+	Undefined = Token{kind: KindUndefined}
+	Break     = Token{kind: KindBreak}
+)
+
+// Bool returns a token for a boolean.
+func Bool(b bool) Token {
+	if b {
+		return True
+	}
+	return False
+}
+
+// Uint returns a token for an unsigned integer.
+func Uint(n uint64) Token {
+	return Token{kind: KindUint, arg: n}
+}
+
+// Int returns a token for an integer.
+func Int(n int64) Token {
+	if n < 0 {
+		return Token{kind: KindNegInt, arg: uint64(^n)}
+	}
+	return Token{kind: KindUint, arg: uint64(n)}
+}
+
+// NegInt returns a token for the negative integer -1-n.
+// It represents the negative integers that do not fit in an int64.
+func NegInt(n uint64) Token {
+	return Token{kind: KindNegInt, arg: n}
+}
+
+// Float returns a token for a floating-point number.
+// It is encoded in the shortest of the half, single and double precision
+// formats that represents it exactly.
+func Float(f float64) Token {
+	return Token{kind: KindFloat, arg: math.Float64bits(f)}
+}
+
+// Bytes returns a token for a definite-length byte string.
+func Bytes(b []byte) Token {
+	return Token{kind: KindBytes, str: string(b)}
+}
+
+// String returns a token for a definite-length text string.
+func String(s string) Token {
+	return Token{kind: KindString, str: s}
+}
+
+// BytesStart returns the start token of an indefinite-length byte string.
+func BytesStart() Token {
+	return Token{kind: KindBytes, indef: true}
+}
+
+// StringStart returns the start token of an indefinite-length text string.
+func StringStart() Token {
+	return Token{kind: KindString, indef: true}
+}
+
+// ArrayStart returns the start token of an array of n elements,
+// or of an indefinite-length array if n is negative.
+func ArrayStart(n int) Token {
+	if n < 0 {
+		return Token{kind: KindArray, indef: true}
+	}
+	return Token{kind: KindArray, arg: uint64(n)}
+}
+
+// MapStart returns the start token of a map of n key-value pairs,
+// or of an indefinite-length map if n is negative.
+func MapStart(n int) Token {
+	if n < 0 {
+		return Token{kind: KindMap, indef: true}
+	}
+	return Token{kind: KindMap, arg: uint64(n)}
+}
+
+// TagNumber returns the head token of a data item with tag number n.
+// It must be followed by the tag content.
+func TagNumber(n uint64) Token {
+	return Token{kind: KindTag, arg: n}
+}
+
+// SimpleValue returns a token for the simple value n.
+// Simple values 20 to 23 are returned as [False], [True], [Null] and
+// [Undefined]; simple values 24 to 31 are reserved and cannot be encoded.
+func SimpleValue(n uint8) Token {
+	switch n {
+	case 20:
+		return False
+	case 21:
+		return True
+	case 22:
+		return Null
+	case 23:
+		return Undefined
+	}
+	return Token{kind: KindSimple, arg: uint64(n)}
+}
+
+// Kind returns the kind of the token.
+func (t Token) Kind() Kind {
+	return t.kind
+}
+
+func (t Token) mustBe(kinds ...Kind) {
+	for _, k := range kinds {
+		if t.kind == k {
+			return
+		}
+	}
+	panic("cbor: Token of kind " + t.kind.String() + " used as " + kinds[0].String())
+}
+
+// Bool returns the value of a boolean token.
+// It panics if the token is not a boolean.
+func (t Token) Bool() bool {
+	t.mustBe(KindBool)
+	return t.arg != 0
+}
+
+// Uint returns the value of an unsigned integer token, or the argument n
+// of a negative integer token representing -1-n.
+// It panics if the token is not an integer.
+func (t Token) Uint() uint64 {
+	t.mustBe(KindUint, KindNegInt)
+	return t.arg
+}
+
+// Int returns the value of an integer token, clamped to the range of
+// int64. It panics if the token is not an integer.
+func (t Token) Int() int64 {
+	t.mustBe(KindUint, KindNegInt)
+	if t.arg > math.MaxInt64 {
+		if t.kind == KindNegInt {
+			return math.MinInt64
+		}
+		return math.MaxInt64
+	}
+	if t.kind == KindNegInt {
+		return -1 - int64(t.arg)
+	}
+	return int64(t.arg)
+}
+
+// Float returns the value of a floating-point number token, or the
+// nearest floating-point number to the value of an integer token.
+// It panics if the token is not a number.
+func (t Token) Float() float64 {
+	t.mustBe(KindFloat, KindUint, KindNegInt)
+	switch t.kind {
+	case KindUint:
+		return float64(t.arg)
+	case KindNegInt:
+		return -1 - float64(t.arg)
+	}
+	return math.Float64frombits(t.arg)
+}
+
+// Bytes returns the content of a definite-length byte or text string
+// token, or nil for the start of an indefinite-length string.
+// It panics if the token is not a string.
+func (t Token) Bytes() []byte {
+	t.mustBe(KindBytes, KindString)
+	if t.indef {
+		return nil
+	}
+	return []byte(t.str)
+}
+
+// String returns the content of a text string token. For other kinds of
+// tokens, it returns the token in diagnostic notation, as described in
+// RFC 8949, section 8.
+func (t Token) String() string {
+	switch t.kind {
+	case KindInvalid:
+		return "<invalid cbor.Token>"
+	case KindString:
+		if !t.indef {
+			return t.str
+		}
+	case KindArray:
+		if t.indef {
+			return "[_"
+		}
+		return "["
+	case KindMap:
+		if t.indef {
+			return "{_"
+		}
+		return "{"
+	case KindTag:
+		return strconv.FormatUint(t.arg, 10) + "("
+	case KindBreak:
+		return "break"
+	}
+	if t.indef {
+		return "(_"
+	}
+	return Value(appendToken(nil, t)).String()
+}
+
+// Len returns the number of bytes of a byte or text string token, the
+// number of elements of an array start token, or the number of key-value
+// pairs of a map start token. It returns -1 for indefinite-length items.
+// It panics for other kinds of tokens.
+func (t Token) Len() int {
+	t.mustBe(KindBytes, KindString, KindArray, KindMap)
+	switch {
+	case t.indef:
+		return -1
+	case t.kind == KindBytes || t.kind == KindString:
+		return len(t.str)
+	}
+	return int(t.arg)
+}
+
+// TagNumber returns the tag number of a tag token.
+// It panics if the token is not a tag.
+func (t Token) TagNumber() uint64 {
+	t.mustBe(KindTag)
+	return t.arg
+}
+
+// SimpleValue returns the simple value of a token of kind [KindSimple],
+// [KindBool], [KindNull] or [KindUndefined].
+// It panics for other kinds of tokens.
+func (t Token) SimpleValue() uint8 {
+	t.mustBe(KindSimple, KindBool, KindNull, KindUndefined)
+	switch t.kind {
+	case KindBool:
+		return 20 + uint8(t.arg)
+	case KindNull:
+		return 22
+	case KindUndefined:
+		return 23
+	}
+	return uint8(t.arg)
+}
+
+// Major types, in the high 3 bits of the initial byte of a data item.
+const (
+	majorUint   = 0 << 5
+	majorNegInt = 1 << 5
+	majorBytes  = 2 << 5
+	majorString = 3 << 5
+	majorArray  = 4 << 5
+	majorMap    = 5 << 5
+	majorTag    = 6 << 5
+	majorSimple = 7 << 5
+
+	aiIndefinite = 31
+)
+
+// appendHead appends the head of a data item of the given major type
+// with the given argument, in its shortest form.
+func appendHead(b []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(b, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+}
+
+// appendToken appends the encoding of t, which must be valid.
+func appendToken(b []byte, t Token) []byte {
+	switch t.kind {
+	case KindUint:
+		return appendHead(b, majorUint, t.arg)
+	case KindNegInt:
+		return appendHead(b, majorNegInt, t.arg)
+	case KindBytes, KindString:
+		major := byte(majorBytes)
+		if t.kind == KindString {
+			major = majorString
+		}
+		if t.indef {
+			return append(b, major|aiIndefinite)
+		}
+		return append(appendHead(b, major, uint64(len(t.str))), t.str...)
+	case KindArray, KindMap:
+		major := byte(majorArray)
+		if t.kind == KindMap {
+			major = majorMap
+		}
+		if t.indef {
+			return append(b, major|aiIndefinite)
+		}
+		return appendHead(b, major, t.arg)
+	case KindTag:
+		return appendHead(b, majorTag, t.arg)
+	case KindBool:
+		return append(b, majorSimple|20|byte(t.arg))
+	case KindNull:
+		return append(b, majorSimple|22)
+	case KindUndefined:
+		return append(b, majorSimple|23)
+	case KindSimple:
+		return appendHead(b, majorSimple, t.arg)
+	case KindFloat:
+		return appendFloat(b, math.Float64frombits(t.arg))
+	case KindBreak:
+		return append(b, majorSimple|aiIndefinite)
+	}
+	panic("cbor: invalid token")
+}
+
+// appendFloat appends f in the shortest floating-point format that
+// represents it exactly, which is the preferred serialization of
+// RFC 8949, section 4.1. NaNs are encoded as the half-precision quiet NaN.
+func appendFloat(b []byte, f float64) []byte {
+	if f != f {
+		return append(b, majorSimple|25, 0x7e, 0x00)
+	}
+	f32 := float32(f)
+	if float64(f32) != f {
+		return binary.BigEndian.AppendUint64(append(b, majorSimple|27), math.Float64bits(f))
+	}
+	if h, ok := float16(f32); ok {
+		return binary.BigEndian.AppendUint16(append(b, majorSimple|25), h)
+	}
+	return binary.BigEndian.AppendUint32(append(b, majorSimple|26), math.Float32bits(f32))
+}
+
+// float16 returns the IEEE 754 half-precision encoding of f, and reports
+// whether it represents f exactly. f must not be a NaN.
+func float16(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127
+	mant := bits & 0x7fffff
+	switch {
+	case exp == 128: // infinity
+		return sign | 0x7c00, true
+	case exp == -127 && mant == 0: // zero
+		return sign, true
+	case -14 <= exp && exp <= 15: // normal
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mant>>13), true
+	case -24 <= exp && exp < -14: // subnormal
+		m := 1<<23 | mant
+		shift := uint(-exp - 1)
+		if m&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(m>>shift), true
+	}
+	return 0, false
+}
+
+// float16to64 converts an IEEE 754 half-precision number to a float64.
+func float16to64(h uint16) float64 {
+	exp := int(h >> 10 & 0x1f)
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			f = math.NaN()
+		} else {
+			f = math.Inf(1)
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
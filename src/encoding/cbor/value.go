@@ -0,0 +1,350 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Value represents a single raw CBOR data item, in its encoded form.
+//
+// Marshaling a Value writes it as is, after checking that it is valid,
+// and unmarshaling into a Value stores a copy of the encoded data item.
+type Value []byte
+
+// Clone returns a copy of v.
+func (v Value) Clone() Value {
+	return bytes.Clone(v)
+}
+
+// Kind returns the kind of the data item, or [KindInvalid] if v does not
+// start with a valid head. It does not check the rest of the data item.
+func (v Value) Kind() Kind {
+	if len(v) == 0 {
+		return KindInvalid
+	}
+	n, err := headLen(v)
+	if err != nil || n > len(v) {
+		return KindInvalid
+	}
+	t, err := parseToken(v[:n])
+	if err != nil {
+		return KindInvalid
+	}
+	return t.kind
+}
+
+// IsValid reports whether v is a single well-formed and valid data item.
+// By default, maps with duplicate keys and text strings that are not valid
+// UTF-8 are not valid; the [AllowDuplicateKeys] and [AllowInvalidUTF8]
+// options relax these checks.
+func (v Value) IsValid(opts ...Options) bool {
+	var o options
+	o.join(opts...)
+	return v.validate(&o) == nil
+}
+
+// validate returns an error unless v is a single valid data item.
+func (v Value) validate(o *options) error {
+	d := newBytesDecoder(v, o)
+	if _, err := d.ReadValue(); err != nil {
+		if err == io.EOF {
+			err = d.syntaxError(err, 0)
+		}
+		return err
+	}
+	return d.checkEOF()
+}
+
+// Canonicalize converts v in place to the core deterministic encoding of
+// RFC 8949, section 4.2.1: integers, lengths, tags and floating-point
+// numbers use their shortest encoding, indefinite-length items are
+// converted to definite-length items, and the entries of maps are sorted
+// in the bytewise lexicographic order of their encoded keys.
+// It reports an error if v is not a single valid data item.
+func (v *Value) Canonicalize() error {
+	return v.canonicalize(new(options), false)
+}
+
+// canonicalize is like Canonicalize, but validates v according to o,
+// and keeps indefinite-length items if keepIndef is set.
+func (v *Value) canonicalize(o *options, keepIndef bool) error {
+	d := newBytesDecoder(*v, o)
+	b, err := appendCanonical(nil, d, keepIndef)
+	if err == nil {
+		err = d.checkEOF()
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = d.syntaxError(err, 0)
+		}
+		return err
+	}
+	*v = append((*v)[:0], b...)
+	return nil
+}
+
+// appendCanonical appends the canonical encoding of the next data item
+// read from d.
+func appendCanonical(b []byte, d *Decoder, keepIndef bool) ([]byte, error) {
+	t, s, err := d.next()
+	if err != nil {
+		return b, err
+	}
+	switch t.kind {
+	case KindBytes, KindString:
+		major := byte(majorBytes)
+		if t.kind == KindString {
+			major = majorString
+		}
+		if !t.indef {
+			return append(appendHead(b, major, uint64(len(s))), s...), nil
+		}
+		var chunks []byte
+		if keepIndef {
+			b = append(b, major|aiIndefinite)
+		}
+		for {
+			t, s, err := d.next()
+			switch {
+			case err != nil:
+				return b, err
+			case t.kind == KindBreak && keepIndef:
+				return append(b, majorSimple|aiIndefinite), nil
+			case t.kind == KindBreak:
+				return append(appendHead(b, major, uint64(len(chunks))), chunks...), nil
+			case keepIndef:
+				b = append(appendHead(b, major, uint64(len(s))), s...)
+			default:
+				chunks = append(chunks, s...)
+			}
+		}
+
+	case KindArray:
+		if !t.indef {
+			b = appendHead(b, majorArray, t.arg)
+			for range t.arg {
+				if b, err = appendCanonical(b, d, keepIndef); err != nil {
+					return b, err
+				}
+			}
+			return b, nil
+		}
+		var elems []byte
+		var n uint64
+		for ; ; n++ {
+			if t, _, err := d.peekToken(); err != nil || t.kind == KindBreak {
+				if _, _, err := d.next(); err != nil {
+					return b, err
+				}
+				break
+			}
+			if elems, err = appendCanonical(elems, d, keepIndef); err != nil {
+				return b, err
+			}
+		}
+		if keepIndef {
+			b = append(b, majorArray|aiIndefinite)
+			b = append(b, elems...)
+			return append(b, majorSimple|aiIndefinite), nil
+		}
+		return append(appendHead(b, majorArray, n), elems...), nil
+
+	case KindMap:
+		type entry struct{ key, entry []byte }
+		var entries []entry
+		var buf []byte
+		for i := uint64(0); t.indef || i < t.arg; i++ {
+			if t.indef {
+				if t, _, err := d.peekToken(); err != nil || t.kind == KindBreak {
+					if _, _, err := d.next(); err != nil {
+						return b, err
+					}
+					break
+				}
+			}
+			start := len(buf)
+			if buf, err = appendCanonical(buf, d, keepIndef); err != nil {
+				return b, err
+			}
+			mid := len(buf)
+			if buf, err = appendCanonical(buf, d, keepIndef); err != nil {
+				return b, err
+			}
+			entries = append(entries, entry{buf[start:mid], buf[start:]})
+		}
+		slices.SortFunc(entries, func(x, y entry) int {
+			return bytes.Compare(x.key, y.key)
+		})
+		if !d.allowDup {
+			for i := 1; i < len(entries); i++ {
+				if bytes.Equal(entries[i-1].key, entries[i].key) {
+					return b, d.syntaxError(ErrDuplicateKey, d.InputOffset())
+				}
+			}
+		}
+		if t.indef && keepIndef {
+			b = append(b, majorMap|aiIndefinite)
+		} else {
+			b = appendHead(b, majorMap, uint64(len(entries)))
+		}
+		for _, e := range entries {
+			b = append(b, e.entry...)
+		}
+		if t.indef && keepIndef {
+			b = append(b, majorSimple|aiIndefinite)
+		}
+		return b, nil
+
+	case KindTag:
+		return appendCanonical(appendHead(b, majorTag, t.arg), d, keepIndef)
+	}
+	return appendToken(b, t), nil
+}
+
+// String returns v in the diagnostic notation of RFC 8949, section 8.
+// Byte strings are written in base16, and text strings are quoted with
+// Go escape sequences. Data items that are well-formed but not valid,
+// such as maps with duplicate keys, are formatted as well. If v is not
+// a single well-formed data item, String returns a description of the
+// error.
+func (v Value) String() string {
+	o := options{values: optAllowDuplicateKeys | optAllowInvalidUTF8}
+	d := newBytesDecoder(v, &o)
+	b, err := appendDiagnostic(nil, d)
+	if err == nil {
+		err = d.checkEOF()
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = d.syntaxError(err, 0)
+		}
+		return "<invalid cbor.Value: " + strings.TrimPrefix(err.Error(), errorPrefix) + ">"
+	}
+	return string(b)
+}
+
+// appendDiagnostic appends the diagnostic notation of the next data item
+// read from d.
+func appendDiagnostic(b []byte, d *Decoder) ([]byte, error) {
+	t, s, err := d.next()
+	if err != nil {
+		return b, err
+	}
+	switch t.kind {
+	case KindNegInt:
+		if t.arg == math.MaxUint64 {
+			return append(b, "-18446744073709551616"...), nil
+		}
+		return strconv.AppendUint(append(b, '-'), t.arg+1, 10), nil
+	case KindBytes, KindString:
+		if !t.indef {
+			return appendDiagnosticString(b, t.kind, s), nil
+		}
+		if d.PeekKind() == KindBreak {
+			d.next()
+			if t.kind == KindBytes {
+				return append(b, "''_"...), nil
+			}
+			return append(b, `""_`...), nil
+		}
+		return appendDiagnosticItems(append(b, "(_ "...), d, ')', -1)
+	case KindArray:
+		if t.indef {
+			return appendDiagnosticItems(append(b, "[_ "...), d, ']', -1)
+		}
+		return appendDiagnosticItems(append(b, '['), d, ']', int64(min(t.arg, math.MaxInt64)))
+	case KindMap:
+		if t.indef {
+			return appendDiagnosticItems(append(b, "{_ "...), d, '}', -1)
+		}
+		return appendDiagnosticItems(append(b, '{'), d, '}', int64(2*min(t.arg, math.MaxInt64/2)))
+	case KindTag:
+		b = strconv.AppendUint(b, t.arg, 10)
+		b, err = appendDiagnostic(append(b, '('), d)
+		return append(b, ')'), err
+	case KindBool:
+		return strconv.AppendBool(b, t.arg != 0), nil
+	case KindNull:
+		return append(b, "null"...), nil
+	case KindUndefined:
+		return append(b, "undefined"...), nil
+	case KindSimple:
+		return append(strconv.AppendUint(append(b, "simple("...), t.arg, 10), ')'), nil
+	case KindFloat:
+		return appendDiagnosticFloat(b, math.Float64frombits(t.arg)), nil
+	}
+	return strconv.AppendUint(b, t.arg, 10), nil
+}
+
+// appendDiagnosticItems appends the n items, or the items up to a break
+// if n is negative, of an array, map or indefinite-length string,
+// followed by the closing delimiter.
+func appendDiagnosticItems(b []byte, d *Decoder, delim byte, n int64) ([]byte, error) {
+	var err error
+	for i := int64(0); n < 0 || i < n; i++ {
+		if n < 0 && d.PeekKind() == KindBreak {
+			d.next()
+			break
+		}
+		if i > 0 {
+			if delim == '}' && i%2 != 0 {
+				b = append(b, ": "...)
+			} else {
+				b = append(b, ", "...)
+			}
+		}
+		if b, err = appendDiagnostic(b, d); err != nil {
+			return b, err
+		}
+	}
+	return append(b, delim), nil
+}
+
+func appendDiagnosticString(b []byte, k Kind, s []byte) []byte {
+	if k == KindString {
+		return strconv.AppendQuote(b, string(s))
+	}
+	b = append(b, "h'"...)
+	b = hex.AppendEncode(b, s)
+	return append(b, '\'')
+}
+
+// appendDiagnosticFloat formats f like the examples of RFC 8949,
+// always including a decimal point and using exponential notation
+// for very large and very small magnitudes.
+func appendDiagnosticFloat(b []byte, f float64) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(b, "NaN"...)
+	case math.IsInf(f, 1):
+		return append(b, "Infinity"...)
+	case math.IsInf(f, -1):
+		return append(b, "-Infinity"...)
+	}
+	mant, e, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	exp, _ := strconv.Atoi(e)
+	if f == 0 || -5 <= exp && exp < 16 {
+		s := strconv.FormatFloat(f, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return append(b, s...)
+	}
+	if !strings.Contains(mant, ".") {
+		mant += ".0"
+	}
+	b = append(append(b, mant...), 'e')
+	if exp > 0 {
+		b = append(b, '+')
+	}
+	return strconv.AppendInt(b, int64(exp), 10)
+}
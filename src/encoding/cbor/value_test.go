@@ -0,0 +1,248 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cbor
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// rfcExamples are the examples of RFC 8949, appendix A,
+// in diagnostic notation and hexadecimal encoding.
+var rfcExamples = []struct {
+	diag string
+	hex  string
+}{
+	{`0`, "00"},
+	{`1`, "01"},
+	{`10`, "0a"},
+	{`23`, "17"},
+	{`24`, "1818"},
+	{`25`, "1819"},
+	{`100`, "1864"},
+	{`1000`, "1903e8"},
+	{`1000000`, "1a000f4240"},
+	{`1000000000000`, "1b000000e8d4a51000"},
+	{`18446744073709551615`, "1bffffffffffffffff"},
+	{`2(h'010000000000000000')`, "c249010000000000000000"},
+	{`-18446744073709551616`, "3bffffffffffffffff"},
+	{`3(h'010000000000000000')`, "c349010000000000000000"},
+	{`-1`, "20"},
+	{`-10`, "29"},
+	{`-100`, "3863"},
+	{`-1000`, "3903e7"},
+	{`0.0`, "f90000"},
+	{`-0.0`, "f98000"},
+	{`1.0`, "f93c00"},
+	{`1.1`, "fb3ff199999999999a"},
+	{`1.5`, "f93e00"},
+	{`65504.0`, "f97bff"},
+	{`100000.0`, "fa47c35000"},
+	{`3.4028234663852886e+38`, "fa7f7fffff"},
+	{`1.0e+300`, "fb7e37e43c8800759c"},
+	{`5.960464477539063e-8`, "f90001"},
+	{`0.00006103515625`, "f90400"},
+	{`-4.0`, "f9c400"},
+	{`-4.1`, "fbc010666666666666"},
+	{`Infinity`, "f97c00"},
+	{`NaN`, "f97e00"},
+	{`-Infinity`, "f9fc00"},
+	{`false`, "f4"},
+	{`true`, "f5"},
+	{`null`, "f6"},
+	{`undefined`, "f7"},
+	{`simple(16)`, "f0"},
+	{`simple(255)`, "f8ff"},
+	{`0("2013-03-21T20:04:00Z")`, "c074323031332d30332d32315432303a30343a30305a"},
+	{`1(1363896240)`, "c11a514b67b0"},
+	{`1(1363896240.5)`, "c1fb41d452d9ec200000"},
+	{`23(h'01020304')`, "d74401020304"},
+	{`24(h'6449455446')`, "d818456449455446"},
+	{`32("http://www.example.com")`, "d82076687474703a2f2f7777772e6578616d706c652e636f6d"},
+	{`h''`, "40"},
+	{`h'01020304'`, "4401020304"},
+	{`""`, "60"},
+	{`"a"`, "6161"},
+	{`"IETF"`, "6449455446"},
+	{`"\"\\"`, "62225c"},
+	{`"ü"`, "62c3bc"},
+	{`"水"`, "63e6b0b4"},
+	{`"𐅑"`, "64f0908591"},
+	{`[]`, "80"},
+	{`[1, 2, 3]`, "83010203"},
+	{`[1, [2, 3], [4, 5]]`, "8301820203820405"},
+	{`[1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25]`,
+		"98190102030405060708090a0b0c0d0e0f101112131415161718181819"},
+	{`{}`, "a0"},
+	{`{1: 2, 3: 4}`, "a201020304"},
+	{`{"a": 1, "b": [2, 3]}`, "a26161016162820203"},
+	{`["a", {"b": "c"}]`, "826161a161626163"},
+	{`{"a": "A", "b": "B", "c": "C", "d": "D", "e": "E"}`, "a56161614161626142616361436164614461656145"},
+	{`(_ h'0102', h'030405')`, "5f42010243030405ff"},
+	{`(_ "strea", "ming")`, "7f657374726561646d696e67ff"},
+	{`[_ ]`, "9fff"},
+	{`[_ 1, [2, 3], [_ 4, 5]]`, "9f018202039f0405ffff"},
+	{`[_ 1, [2, 3], [4, 5]]`, "9f01820203820405ff"},
+	{`[1, [2, 3], [_ 4, 5]]`, "83018202039f0405ff"},
+	{`[1, [_ 2, 3], [4, 5]]`, "83019f0203ff820405"},
+	{`[_ 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25]`,
+		"9f0102030405060708090a0b0c0d0e0f101112131415161718181819ff"},
+	{`{_ "a": 1, "b": [_ 2, 3]}`, "bf61610161629f0203ffff"},
+	{`["a", {_ "b": "c"}]`, "826161bf61626163ff"},
+	{`{_ "Fun": true, "Amt": -2}`, "bf6346756ef563416d7421ff"},
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestValueString(t *testing.T) {
+	for _, tt := range rfcExamples {
+		v := Value(mustDecodeHex(t, tt.hex))
+		if !v.IsValid() {
+			t.Errorf("Value(%s).IsValid() = false, want true", tt.hex)
+		}
+		if got := v.String(); got != tt.diag {
+			t.Errorf("Value(%s).String() = %s, want %s", tt.hex, got, tt.diag)
+		}
+	}
+}
+
+func TestValueInvalid(t *testing.T) {
+	tests := []struct {
+		hex     string
+		wantErr error
+	}{
+		{"", io.ErrUnexpectedEOF},
+		{"18", io.ErrUnexpectedEOF},
+		{"62c3", io.ErrUnexpectedEOF},
+		{"8301", io.ErrUnexpectedEOF},
+		{"0000", errTrailingData},
+		{"1c", errReserved},
+		{"3f", errInvalidIndef},
+		{"df", errInvalidIndef},
+		{"f801", errInvalidSimple},
+		{"ff", errUnexpectedBrk},
+		{"81ff", errUnexpectedBrk},
+		{"bf01ff", errUnexpectedBrk},
+		{"5f6161ff", errInvalidChunk},
+		{"5f5f4101ffff", errInvalidChunk},
+		{"62c328", ErrInvalidUTF8},
+		{"a201020103", ErrDuplicateKey},
+		{"a2010218010f", ErrDuplicateKey},           // 1 and non-preferred 1
+		{"a2f93c0002fa3f80000003", ErrDuplicateKey}, // 1.0 in half and single precision
+		{"a281010281010f", ErrDuplicateKey},
+	}
+	for _, tt := range tests {
+		v := Value(mustDecodeHex(t, tt.hex))
+		if v.IsValid() {
+			t.Errorf("Value(%s).IsValid() = true, want false", tt.hex)
+		}
+		var serr *SyntacticError
+		err := v.validate(new(options))
+		if !errors.As(err, &serr) {
+			t.Errorf("Value(%s).validate() = %v, want SyntacticError", tt.hex, err)
+			continue
+		}
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Value(%s).validate() = %v, want %v", tt.hex, err, tt.wantErr)
+		}
+		wellFormed := tt.wantErr == ErrInvalidUTF8 || tt.wantErr == ErrDuplicateKey
+		if got := v.String(); strings.HasPrefix(got, "<invalid cbor.Value: ") == wellFormed {
+			t.Errorf("Value(%s).String() = %s, want description of error: %v", tt.hex, got, !wellFormed)
+		}
+	}
+
+	if v := Value(mustDecodeHex(t, "a201020103")); !v.IsValid(AllowDuplicateKeys(true)) {
+		t.Errorf("Value(%x).IsValid(AllowDuplicateKeys(true)) = false, want true", []byte(v))
+	}
+	if v := Value(mustDecodeHex(t, "62c328")); !v.IsValid(AllowInvalidUTF8(true)) {
+		t.Errorf("Value(%x).IsValid(AllowInvalidUTF8(true)) = false, want true", []byte(v))
+	}
+}
+
+func TestValueCanonicalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"1817", "17"},
+		{"1900ff", "18ff"},
+		{"3b0000000000000000", "20"},
+		{"fb3ff8000000000000", "f93e00"},
+		{"fa7fc00000", "f97e00"},
+		{"d9000100", "c100"},
+		{"5f42010243030405ff", "450102030405"},
+		{"7f657374726561646d696e67ff", "6973747265616d696e67"},
+		{"7fff", "60"},
+		{"9f018202039f0405ffff", "8301820203820405"},
+		{"bf61610161629f0203ffff", "a26161016162820203"},
+		{"a3616201616102f40f", "a3616102616201f40f"},
+		{"a2820102f4616101", "a2616101820102f4"},
+		{"a26161f4186401", "a21864016161f4"},
+	}
+	for _, tt := range tests {
+		v := Value(mustDecodeHex(t, tt.in))
+		if err := v.Canonicalize(); err != nil {
+			t.Errorf("Value(%s).Canonicalize() error: %v", tt.in, err)
+			continue
+		}
+		if got := hex.EncodeToString(v); got != tt.want {
+			t.Errorf("Value(%s).Canonicalize() = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, tt := range rfcExamples {
+		v := Value(mustDecodeHex(t, tt.hex))
+		if err := v.Canonicalize(); err != nil {
+			t.Errorf("Value(%s).Canonicalize() error: %v", tt.hex, err)
+			continue
+		}
+		if strings.Contains(v.String(), "_") {
+			t.Errorf("Value(%s).Canonicalize() = %s, want definite-length items only", tt.hex, v)
+		}
+	}
+
+	v := Value(mustDecodeHex(t, "a2810102810103"))
+	if err := v.Canonicalize(); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("Canonicalize of map with duplicate keys: %v, want ErrDuplicateKey", err)
+	}
+}
+
+func TestValueKind(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want Kind
+	}{
+		{"", KindInvalid},
+		{"1c", KindInvalid},
+		{"00", KindUint},
+		{"20", KindNegInt},
+		{"40", KindBytes},
+		{"5f", KindBytes},
+		{"60", KindString},
+		{"80", KindArray},
+		{"a0", KindMap},
+		{"c0", KindTag},
+		{"f4", KindBool},
+		{"f6", KindNull},
+		{"f7", KindUndefined},
+		{"f0", KindSimple},
+		{"f93c00", KindFloat},
+		{"ff", KindBreak},
+	}
+	for _, tt := range tests {
+		if got := Value(mustDecodeHex(t, tt.hex)).Kind(); got != tt.want {
+			t.Errorf("Value(%s).Kind() = %v, want %v", tt.hex, got, tt.want)
+		}
+	}
+}
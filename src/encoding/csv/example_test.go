@@ -129,3 +129,57 @@ func ExampleWriter_WriteAll() {
 	// Ken,Thompson,ken
 	// Robert,Griesemer,gri
 }
+
+func ExampleUnmarshal() {
+	in := `username,first_name,last_name,uid
+rob,"Rob","Pike",1
+ken,Ken,Thompson,2
+gri,"Robert","Griesemer",3
+`
+	type user struct {
+		Username  string
+		FirstName string `csv:"first_name"`
+		LastName  string `csv:"last_name"`
+		UID       int
+	}
+
+	var users []user
+	if err := csv.Unmarshal([]byte(in), &users); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, u := range users {
+		fmt.Printf("%d: %s %s (%s)\n", u.UID, u.FirstName, u.LastName, u.Username)
+	}
+	// Output:
+	// 1: Rob Pike (rob)
+	// 2: Ken Thompson (ken)
+	// 3: Robert Griesemer (gri)
+}
+
+// This example shows how to decode a large tab-separated file one record
+// at a time.
+func ExampleAll() {
+	in := "name\tlanguages\n" +
+		"Rob\tGo\tLimbo\n" +
+		"Ken\t'B, C\tGo'\n"
+	type author struct {
+		Name      string
+		Languages string
+	}
+
+	r := csv.NewReader(strings.NewReader(in))
+	r.Comma = '\t'
+	r.Quote = '\''
+	r.FieldsPerRecord = -1
+
+	for a, err := range csv.All[author](r) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s: %q\n", a.Name, a.Languages)
+	}
+	// Output:
+	// Rob: "Go"
+	// Ken: "B, C\tGo"
+}
@@ -0,0 +1,600 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Marshal returns the CSV encoding of v, which must be a slice or array
+// of structs or of pointers to structs. The first record is a header
+// holding the column names, and each element of v is encoded as one
+// record following it.
+//
+// Each exported struct field is a column named after the field, unless
+// the field's tag gives another name:
+//
+//	// Column named "id".
+//	ID int `csv:"id"`
+//
+//	// Column named "Note", encoded as an empty field if zero.
+//	Note string `csv:",omitempty"`
+//
+//	// Field is ignored.
+//	Internal string `csv:"-"`
+//
+// The fields of an anonymous struct field without a name in its tag are
+// treated as if they were in the outer struct, following the visibility
+// rules for embedded fields in encoding/json.
+//
+// Fields may be strings, booleans, integers, floating-point numbers, types
+// implementing [encoding.TextMarshaler], or pointers to these. A nil
+// pointer is encoded as an empty field. Fields of other types are
+// an error unless they are ignored with a "-" tag.
+//
+// Marshal uses the format of a Writer returned by [NewWriter]. To use
+// another format, use an [Encoder].
+func Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("csv: Marshal(%T): not a slice or array", v)
+	}
+	t := rv.Type().Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: Marshal(%T): elements are not structs", v)
+	}
+	if !rv.CanAddr() {
+		// Make the elements addressable for pointer-receiver TextMarshalers.
+		pv := reflect.New(rv.Type()).Elem()
+		pv.Set(rv)
+		rv = pv
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	e := NewEncoder(w)
+	if err := e.writeHeader(t); err != nil {
+		return nil, err
+	}
+	for i := range rv.Len() {
+		ev := rv.Index(i)
+		if ev.Kind() == reflect.Pointer {
+			if ev.IsNil() {
+				return nil, fmt.Errorf("csv: Marshal(%T): nil element at index %d", v, i)
+			}
+			ev = ev.Elem()
+		}
+		if err := e.encode(ev); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal parses the CSV-encoded data and stores the records in the
+// slice pointed to by v, which must be a slice of structs or of pointers
+// to structs. The first record of data is a header naming the columns.
+//
+// Each column is stored in the struct field of the same name, as
+// described in [Marshal], preferring an exact match but also accepting
+// a case-insensitive one. Columns without a matching field are ignored.
+//
+// Fields may be strings, booleans, integers, floating-point numbers,
+// types whose pointer implements [encoding.TextUnmarshaler], or pointers
+// to these. An empty field sets a pointer to nil, and a boolean or
+// number to zero; it is passed as is to strings and TextUnmarshalers.
+//
+// Unmarshal resets the length of the slice to zero before appending
+// the records to it. If a field cannot be parsed, Unmarshal returns an
+// [*UnmarshalError] holding its position.
+//
+// Unmarshal uses the format of a Reader returned by [NewReader]. To use
+// another format, or to read records one at a time, use a [Decoder].
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("csv: Unmarshal(%T): not a pointer to a slice", v)
+	}
+	s := rv.Elem()
+	t := s.Type().Elem()
+	if t.Kind() != reflect.Struct && (t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct) {
+		return fmt.Errorf("csv: Unmarshal(%T): elements are not structs", v)
+	}
+
+	d := NewDecoder(NewReader(bytes.NewReader(data)))
+	s.SetLen(0)
+	for {
+		ev := reflect.New(t)
+		err := d.Decode(ev.Interface())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.Set(reflect.Append(s, ev.Elem()))
+	}
+}
+
+// An UnmarshalError describes a field that could not be stored in the
+// corresponding struct field.
+// Line and column numbers are 1-indexed.
+type UnmarshalError struct {
+	Line   int          // Line where the field starts
+	Column int          // Column (1-based byte index) where the field starts
+	Header string       // Name of the column
+	Type   reflect.Type // Type of the struct field
+	Err    error        // The actual error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("csv: cannot unmarshal column %q on line %d, column %d into Go value of type %v: %v",
+		e.Header, e.Line, e.Column, e.Type, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error { return e.Err }
+
+// A Decoder reads records from a [Reader] into structs, mapping the
+// columns named in a header to struct fields as described in [Unmarshal].
+type Decoder struct {
+	r               *Reader
+	header          []string
+	disallowUnknown bool
+
+	typ  reflect.Type // type of the last decoded struct
+	cols []*field     // struct field of each column of typ, or nil
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r *Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Header returns the names of the columns. Unless they were set with
+// [Decoder.SetHeader], they are read from the first record of the
+// Reader on the first call to Header or [Decoder.Decode].
+func (d *Decoder) Header() ([]string, error) {
+	if d.header == nil {
+		record, err := d.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		d.header = slices.Clone(record)
+	}
+	return d.header, nil
+}
+
+// SetHeader sets the names of the columns, for reading data without a
+// header record. It must be called before the first call to
+// [Decoder.Decode].
+func (d *Decoder) SetHeader(header []string) {
+	d.header = slices.Clone(header)
+	d.typ, d.cols = nil, nil
+}
+
+// DisallowUnknownColumns causes the Decoder to return an error when the
+// header names a column that does not match any field of the destination
+// struct.
+func (d *Decoder) DisallowUnknownColumns() { d.disallowUnknown = true }
+
+// Decode reads the next record and stores it in the struct pointed to
+// by v. At the end of the input, Decode returns [io.EOF].
+//
+// Errors returned by the Reader, such as a [*ParseError], are returned
+// as is; a field that cannot be parsed is reported as an
+// [*UnmarshalError].
+func (d *Decoder) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("csv: Decode(%T): not a non-nil pointer", v)
+	}
+	rv = rv.Elem()
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: Decode(%T): not a pointer to a struct", v)
+	}
+	if _, err := d.Header(); err != nil {
+		return err
+	}
+	if rv.Type() != d.typ {
+		if err := d.mapColumns(rv.Type()); err != nil {
+			return err
+		}
+	}
+
+	record, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+	for i, f := range d.cols[:min(len(d.cols), len(record))] {
+		if f == nil {
+			continue
+		}
+		if err := decodeValue(rv.FieldByIndex(f.index), record[i]); err != nil {
+			line, col := d.r.FieldPos(i)
+			return &UnmarshalError{Line: line, Column: col, Header: d.header[i], Type: f.typ, Err: err}
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) mapColumns(t reflect.Type) error {
+	fields, err := cachedFields(t)
+	if err != nil {
+		return err
+	}
+	cols := make([]*field, len(d.header))
+	for i, name := range d.header {
+		cols[i] = lookupField(fields, name)
+		if cols[i] == nil && d.disallowUnknown {
+			return fmt.Errorf("csv: unknown column %q for %v", name, t)
+		}
+	}
+	d.typ, d.cols = t, cols
+	return nil
+}
+
+func lookupField(fields []field, name string) *field {
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, name) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// All returns an iterator over the records read from r, decoded as
+// values of type T, which must be a struct type or a pointer to one.
+// The first record is a header naming the columns, as with [Unmarshal].
+//
+// Iteration stops at the end of the input or after yielding the first
+// error, in which case the value is the partially decoded record.
+func All[T any](r *Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		d := NewDecoder(r)
+		for {
+			var v T
+			err := d.Decode(&v)
+			if err == io.EOF || !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// An Encoder writes structs as records to a [Writer], preceded by a
+// header naming the columns, as described in [Marshal].
+type Encoder struct {
+	w      *Writer
+	typ    reflect.Type
+	fields []field
+	record []string
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w *Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes v, which must be a struct or a pointer to one, as a
+// record. The first call to Encode writes the header before it, and
+// later calls must use the same type.
+//
+// As with [Writer.Write], the record may be buffered; [Writer.Flush]
+// must be called to ensure it is written to the underlying io.Writer.
+func (e *Encoder) Encode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: Encode(%T): not a struct or non-nil pointer to a struct", v)
+	}
+	if !rv.CanAddr() {
+		// Make the fields addressable for pointer-receiver TextMarshalers.
+		pv := reflect.New(rv.Type()).Elem()
+		pv.Set(rv)
+		rv = pv
+	}
+	if err := e.writeHeader(rv.Type()); err != nil {
+		return err
+	}
+	return e.encode(rv)
+}
+
+// writeHeader writes the header for t if it has not been written yet.
+func (e *Encoder) writeHeader(t reflect.Type) error {
+	if e.typ != nil {
+		if t != e.typ {
+			return fmt.Errorf("csv: Encode of %v after %v", t, e.typ)
+		}
+		return nil
+	}
+	fields, err := cachedFields(t)
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(fields))
+	for i := range fields {
+		header[i] = fields[i].name
+	}
+	if err := e.w.Write(header); err != nil {
+		return err
+	}
+	e.typ, e.fields, e.record = t, fields, header
+	return nil
+}
+
+func (e *Encoder) encode(v reflect.Value) error {
+	for i := range e.fields {
+		f := &e.fields[i]
+		fv := v.FieldByIndex(f.index)
+		if f.omitEmpty && fv.IsZero() {
+			e.record[i] = ""
+			continue
+		}
+		s, err := encodeValue(fv)
+		if err != nil {
+			return fmt.Errorf("csv: cannot marshal field %s of type %v: %w", f.name, f.typ, err)
+		}
+		e.record[i] = s
+	}
+	return e.w.Write(e.record)
+}
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+func encodeValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("%v does not implement encoding.TextMarshaler", v.Type())
+}
+
+func decodeValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(s)
+		return nil
+	}
+	if s == "" {
+		v.SetZero()
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%v does not implement encoding.TextUnmarshaler", reflect.PointerTo(v.Type()))
+	}
+	return nil
+}
+
+// A field is a column of a struct type.
+type field struct {
+	name      string
+	index     []int
+	typ       reflect.Type
+	tagged    bool
+	omitEmpty bool
+}
+
+type structFields struct {
+	list []field
+	err  error
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+// cachedFields is like typeFields but uses a cache to avoid repeated work.
+func cachedFields(t reflect.Type) ([]field, error) {
+	f, ok := fieldCache.Load(t)
+	if !ok {
+		list, err := typeFields(t)
+		f, _ = fieldCache.LoadOrStore(t, &structFields{list, err})
+	}
+	sf := f.(*structFields)
+	return sf.list, sf.err
+}
+
+// typeFields returns the columns of the struct type t, in field order.
+func typeFields(t reflect.Type) ([]field, error) {
+	var fields []field
+	var walk func(t reflect.Type, index []int) error
+	walk = func(t reflect.Type, index []int) error {
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("csv")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(index[:len(index):len(index)], i)
+			if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct && !isText(sf.Type) {
+				if err := walk(sf.Type, idx); err != nil {
+					return err
+				}
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if !supported(sf.Type) {
+				return fmt.Errorf("csv: unsupported type %v for field %v.%s", sf.Type, t, sf.Name)
+			}
+			fields = append(fields, field{
+				name:      cmp.Or(name, sf.Name),
+				index:     idx,
+				typ:       sf.Type,
+				tagged:    name != "",
+				omitEmpty: slices.Contains(strings.Split(opts, ","), "omitempty"),
+			})
+		}
+		return nil
+	}
+	if err := walk(t, nil); err != nil {
+		return nil, err
+	}
+
+	// Drop the fields hidden by a shallower field of the same name,
+	// and those that are ambiguous.
+	byName := make(map[string][]int)
+	for i, f := range fields {
+		byName[f.name] = append(byName[f.name], i)
+	}
+	keep := make([]bool, len(fields))
+	for _, group := range byName {
+		if i, ok := dominantField(fields, group); ok {
+			keep[i] = true
+		}
+	}
+	var list []field
+	for i, f := range fields {
+		if keep[i] {
+			list = append(list, f)
+		}
+	}
+	return list, nil
+}
+
+// dominantField returns the field among fields[group] that hides the
+// others: the only shallowest one, or the only tagged one among them.
+func dominantField(fields []field, group []int) (int, bool) {
+	depth := len(fields[group[0]].index)
+	for _, i := range group {
+		depth = min(depth, len(fields[i].index))
+	}
+	dom, n, tagged := -1, 0, 0
+	for _, i := range group {
+		if len(fields[i].index) != depth {
+			continue
+		}
+		n++
+		if fields[i].tagged {
+			tagged++
+			dom = i
+		} else if dom < 0 {
+			dom = i
+		}
+	}
+	if n == 1 || tagged == 1 {
+		return dom, true
+	}
+	return -1, false
+}
+
+// isText reports whether t is encoded as text.
+func isText(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(textMarshalerType) || p.Implements(textUnmarshalerType)
+}
+
+// supported reports whether fields of type t can be encoded or decoded.
+func supported(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isText(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,307 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// level implements encoding.TextMarshaler and encoding.TextUnmarshaler
+// with pointer receivers.
+type level int
+
+func (l *level) MarshalText() ([]byte, error) {
+	return []byte(strings.Repeat("*", int(*l))), nil
+}
+
+func (l *level) UnmarshalText(b []byte) error {
+	if strings.Trim(string(b), "*") != "" {
+		return errors.New("not a level")
+	}
+	*l = level(len(b))
+	return nil
+}
+
+type Base struct {
+	ID   int `csv:"id"`
+	Name string
+}
+
+type person struct {
+	Base
+	Age     uint8   `csv:"age,omitempty"`
+	Score   float64 `csv:"score"`
+	Active  bool
+	Level   level
+	Manager *string  `csv:"manager"`
+	Notes   []string `csv:"-"`
+	secret  string
+}
+
+var (
+	ann    = "Ann"
+	people = []person{
+		{Base: Base{1, "Ann"}, Age: 42, Score: 1.5, Active: true, Level: 3},
+		{Base: Base{2, "Bob, Jr."}, Score: -2, Level: 0, Manager: &ann},
+	}
+	peopleCSV = "id,Name,age,score,Active,Level,manager\n" +
+		"1,Ann,42,1.5,true,***,\n" +
+		"2,\"Bob, Jr.\",,-2,false,,Ann\n"
+)
+
+func TestMarshal(t *testing.T) {
+	b, err := Marshal(people)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != peopleCSV {
+		t.Errorf("Marshal:\ngot  %q\nwant %q", b, peopleCSV)
+	}
+
+	ptrs := []*person{&people[0], &people[1]}
+	b, err = Marshal(&ptrs)
+	if err != nil || string(b) != peopleCSV {
+		t.Errorf("Marshal(&[]*person) = %q, %v; want %q", b, err, peopleCSV)
+	}
+
+	b, err = Marshal([]person(nil))
+	if want := "id,Name,age,score,Active,Level,manager\n"; err != nil || string(b) != want {
+		t.Errorf("Marshal(nil) = %q, %v; want %q", b, err, want)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	type unsupported struct {
+		Tags []string
+	}
+	for _, v := range []any{
+		nil,
+		person{},
+		[]int{1},
+		[]*person{nil},
+		[]unsupported{{}},
+	} {
+		if b, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%#v) = %q; want error", v, b)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var got []person
+	if err := Unmarshal([]byte(peopleCSV), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, people) {
+		t.Errorf("Unmarshal:\ngot  %+v\nwant %+v", got, people)
+	}
+
+	// Columns in another order, case-insensitive names, unknown columns
+	// and missing columns.
+	in := "NAME,unknown,ID,Manager\nAnn,x,1,\nBob,y,2,Ann\n"
+	var ptrs []*person
+	if err := Unmarshal([]byte(in), &ptrs); err != nil {
+		t.Fatal(err)
+	}
+	want := []*person{
+		{Base: Base{1, "Ann"}},
+		{Base: Base{2, "Bob"}, Manager: &ann},
+	}
+	if !reflect.DeepEqual(ptrs, want) {
+		t.Errorf("Unmarshal:\ngot  %+v\nwant %+v", ptrs, want)
+	}
+
+	if err := Unmarshal(nil, &got); err != nil || len(got) != 0 {
+		t.Errorf("Unmarshal(nil) = %v, %v; want empty", got, err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		in     string
+		line   int
+		column int
+		header string
+	}{
+		{"id,age\n1,2\n3,300\n", 3, 3, "age"},
+		{"id,Active\n1,yes\n", 2, 3, "Active"},
+		{"id,Level\nx,*\n", 2, 1, "id"},
+		{"Name,Level\n\"a\nb\",**+\n", 3, 4, "Level"},
+	}
+	for _, tt := range tests {
+		var got []person
+		err := Unmarshal([]byte(tt.in), &got)
+		var ue *UnmarshalError
+		if !errors.As(err, &ue) {
+			t.Errorf("Unmarshal(%q) = %v; want UnmarshalError", tt.in, err)
+			continue
+		}
+		if ue.Line != tt.line || ue.Column != tt.column || ue.Header != tt.header {
+			t.Errorf("Unmarshal(%q) error at line %d, column %d, header %q; want %d, %d, %q",
+				tt.in, ue.Line, ue.Column, ue.Header, tt.line, tt.column, tt.header)
+		}
+	}
+
+	var got []person
+	err := Unmarshal([]byte("id,Name\n1,a,b\n"), &got)
+	if !errors.Is(err, ErrFieldCount) {
+		t.Errorf("Unmarshal with extra field = %v; want %v", err, ErrFieldCount)
+	}
+	var ne *strconv.NumError
+	err = Unmarshal([]byte("age\n-1\n"), &got)
+	if !errors.As(err, &ne) {
+		t.Errorf("Unmarshal of negative uint8 = %v; want strconv.NumError", err)
+	}
+	for _, v := range []any{nil, got, &[]int{}, new(person)} {
+		if err := Unmarshal([]byte(peopleCSV), v); err == nil {
+			t.Errorf("Unmarshal(%T) succeeded", v)
+		}
+	}
+}
+
+func TestEmbeddedFields(t *testing.T) {
+	type Inner struct {
+		A, B, C int
+		D       int `csv:"B2"`
+	}
+	type Other struct {
+		C int
+		D int `csv:"B2"`
+		E int `csv:"C"`
+	}
+	type outer struct {
+		Inner
+		Other
+		A    string
+		T    Base // not embedded
+		Base `csv:"base"`
+	}
+	got, err := typeFields(reflect.TypeFor[struct {
+		Inner
+		Other
+		A string
+	}]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range got {
+		names = append(names, f.name)
+	}
+	// A is hidden by the outer field, C is taken by the tagged field of
+	// Other, and B2 is ambiguous.
+	want := []string{"B", "C", "A"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("fields = %q; want %q", names, want)
+	}
+
+	if _, err := typeFields(reflect.TypeFor[outer]()); err == nil {
+		t.Errorf("typeFields(outer) succeeded with a struct field")
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	r := NewReader(strings.NewReader("1\tAnn\n2\t'Bob\tJr.'\n"))
+	r.Comma = '\t'
+	r.Quote = '\''
+	d := NewDecoder(r)
+	d.SetHeader([]string{"id", "name"})
+	var got []Base
+	for {
+		var b Base
+		err := d.Decode(&b)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, b)
+	}
+	want := []Base{{1, "Ann"}, {2, "Bob\tJr."}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode = %+v; want %+v", got, want)
+	}
+
+	d = NewDecoder(NewReader(strings.NewReader("id,name,extra\n1,Ann,x\n")))
+	d.DisallowUnknownColumns()
+	if h, err := d.Header(); err != nil || !reflect.DeepEqual(h, []string{"id", "name", "extra"}) {
+		t.Errorf("Header = %q, %v", h, err)
+	}
+	var b Base
+	if err := d.Decode(&b); err == nil || !strings.Contains(err.Error(), `"extra"`) {
+		t.Errorf("Decode with unknown column = %v; want error", err)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb)
+	w.Comma = ';'
+	w.Quote = '\''
+	e := NewEncoder(w)
+	if err := e.Encode(Base{1, "a;b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(&Base{2, `"c"`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(person{}); err == nil {
+		t.Error("Encode of another type succeeded")
+	}
+	if err := e.Encode((*Base)(nil)); err == nil {
+		t.Error("Encode of nil pointer succeeded")
+	}
+	w.Flush()
+	want := "id;Name\n1;'a;b'\n2;\"c\"\n"
+	if err := w.Error(); err != nil || sb.String() != want {
+		t.Errorf("Encode wrote %q, %v; want %q", sb.String(), err, want)
+	}
+}
+
+func TestAll(t *testing.T) {
+	var got []person
+	for p, err := range All[person](NewReader(strings.NewReader(peopleCSV))) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+	if !reflect.DeepEqual(got, people) {
+		t.Errorf("All:\ngot  %+v\nwant %+v", got, people)
+	}
+
+	n := 0
+	in := "id\n1\nx\n3\n"
+	for b, err := range All[*Base](NewReader(strings.NewReader(in))) {
+		n++
+		switch n {
+		case 1:
+			if err != nil || b.ID != 1 {
+				t.Errorf("first record = %+v, %v", b, err)
+			}
+		case 2:
+			if _, ok := err.(*UnmarshalError); !ok {
+				t.Errorf("second record error = %v; want UnmarshalError", err)
+			}
+		default:
+			t.Errorf("iteration continued after error")
+		}
+	}
+
+	n = 0
+	for range All[Base](NewReader(strings.NewReader(in))) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("iteration continued after break")
+	}
+}
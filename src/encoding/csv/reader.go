@@ -96,7 +96,29 @@ var (
 var errInvalidDelim = errors.New("csv: invalid field or comment delimiter")
 
 func validDelim(r rune) bool {
-	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+	return r != 0 && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// validDelims reports whether the given delimiters, quote and escape
+// characters are valid and distinct. comment, quote and escape may be 0,
+// and escape may be equal to quote.
+func validDelims(comma, comment, quote, escape rune) bool {
+	if !validDelim(comma) || comment != 0 && (!validDelim(comment) || comment == comma) {
+		return false
+	}
+	if quote != 0 && (!validDelim(quote) || quote == comma || quote == comment) {
+		return false
+	}
+	return escape == 0 || quote != 0 && validDelim(escape) && escape != comma && escape != comment
+}
+
+// quoteEscape returns the escape character to use with quote: 0 when quote
+// characters are doubled.
+func quoteEscape(quote, escape rune) rune {
+	if escape == quote {
+		return 0
+	}
+	return escape
 }
 
 // A Reader reads records from a CSV-encoded file.
@@ -124,6 +146,25 @@ type Reader struct {
 	// It must also not be equal to Comma.
 	Comment rune
 
+	// Quote is the quote character, which may enclose fields.
+	// It is set to '"' by NewReader. If Quote is 0, fields are never
+	// quoted, and quote characters are part of the fields.
+	// Quote must be a valid rune and must not be \r, \n,
+	// or the Unicode replacement character (0xFFFD).
+	// It must also not be equal to Comma or Comment.
+	Quote rune
+
+	// Escape, if not 0, is the escape character of quoted fields:
+	// within a quoted field, the Escape character followed by any character
+	// stands for that character. If Escape is 0 or equal to Quote, a quote
+	// character within a quoted field is written as two quote characters,
+	// as described in RFC 4180.
+	// Escape must be a valid rune and must not be \r, \n,
+	// or the Unicode replacement character (0xFFFD).
+	// It must also not be equal to Comma or Comment, and may only be set
+	// if Quote is not 0.
+	Escape rune
+
 	// FieldsPerRecord is the number of expected fields per record.
 	// If FieldsPerRecord is positive, Read requires each record to
 	// have the given number of fields. If FieldsPerRecord is 0, Read sets it to
@@ -181,6 +222,7 @@ type Reader struct {
 func NewReader(r io.Reader) *Reader {
 	return &Reader{
 		Comma: ',',
+		Quote: '"',
 		r:     bufio.NewReader(r),
 	}
 }
@@ -295,9 +337,10 @@ func nextRune(b []byte) rune {
 }
 
 func (r *Reader) readRecord(dst []string) ([]string, error) {
-	if r.Comma == r.Comment || !validDelim(r.Comma) || (r.Comment != 0 && !validDelim(r.Comment)) {
+	if !validDelims(r.Comma, r.Comment, r.Quote, r.Escape) {
 		return nil, errInvalidDelim
 	}
+	quote, escape := r.Quote, quoteEscape(r.Quote, r.Escape)
 
 	// Read line (automatically skipping past empty lines and any comments).
 	var line []byte
@@ -320,7 +363,12 @@ func (r *Reader) readRecord(dst []string) ([]string, error) {
 
 	// Parse each field in the record.
 	var err error
-	const quoteLen = len(`"`)
+	quoteLen := utf8.RuneLen(quote)
+	escapeLen := utf8.RuneLen(escape)
+	specials := string(quote) // the characters that end a quoted-field run
+	if escape != 0 {
+		specials += string(escape)
+	}
 	commaLen := utf8.RuneLen(r.Comma)
 	recLine := r.numLine // Starting line for record
 	r.recordBuffer = r.recordBuffer[:0]
@@ -340,7 +388,7 @@ parseField:
 			line = line[i:]
 			pos.col += i
 		}
-		if len(line) == 0 || line[0] != '"' {
+		if quote == 0 || nextRune(line) != quote {
 			// Non-quoted string field
 			i := bytes.IndexRune(line, r.Comma)
 			field := line
@@ -350,8 +398,8 @@ parseField:
 				field = field[:len(field)-lengthNL(field)]
 			}
 			// Check to make sure a quote does not appear in field.
-			if !r.LazyQuotes {
-				if j := bytes.IndexByte(field, '"'); j >= 0 {
+			if !r.LazyQuotes && quote != 0 {
+				if j := bytes.IndexRune(field, quote); j >= 0 {
 					col := pos.col + j
 					err = &ParseError{StartLine: recLine, Line: r.numLine, Column: col, Err: ErrBareQuote}
 					break parseField
@@ -372,16 +420,30 @@ parseField:
 			line = line[quoteLen:]
 			pos.col += quoteLen
 			for {
-				i := bytes.IndexByte(line, '"')
+				i := bytes.IndexAny(line, specials)
+				if i >= 0 && escape != 0 && nextRune(line[i:]) == escape {
+					// Hit escape character.
+					r.recordBuffer = append(r.recordBuffer, line[:i]...)
+					line = line[i+escapeLen:]
+					pos.col += i + escapeLen
+					if len(line) > lengthNL(line) {
+						_, n := utf8.DecodeRune(line)
+						r.recordBuffer = append(r.recordBuffer, line[:n]...)
+						line = line[n:]
+						pos.col += n
+					}
+					// An escaped newline is kept when reading the next line.
+					continue
+				}
 				if i >= 0 {
 					// Hit next quote.
 					r.recordBuffer = append(r.recordBuffer, line[:i]...)
 					line = line[i+quoteLen:]
 					pos.col += i + quoteLen
 					switch rn := nextRune(line); {
-					case rn == '"':
+					case rn == quote && escape == 0:
 						// `""` sequence (append quote).
-						r.recordBuffer = append(r.recordBuffer, '"')
+						r.recordBuffer = utf8.AppendRune(r.recordBuffer, quote)
 						line = line[quoteLen:]
 						pos.col += quoteLen
 					case rn == r.Comma:
@@ -398,7 +460,7 @@ parseField:
 						break parseField
 					case r.LazyQuotes:
 						// `"` sequence (bare quote).
-						r.recordBuffer = append(r.recordBuffer, '"')
+						r.recordBuffer = utf8.AppendRune(r.recordBuffer, quote)
 					default:
 						// `"*` sequence (invalid non-escaped quote).
 						err = &ParseError{StartLine: recLine, Line: r.numLine, Column: pos.col - quoteLen, Err: ErrQuote}
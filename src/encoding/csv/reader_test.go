@@ -25,6 +25,9 @@ type readTest struct {
 	// These fields are copied into the Reader
 	Comma              rune
 	Comment            rune
+	Quote              rune
+	NoQuote            bool // Quote is 0
+	Escape             rune
 	UseFieldsPerRecord bool // false (default) means FieldsPerRecord is -1
 	FieldsPerRecord    int
 	LazyQuotes         bool
@@ -402,6 +405,61 @@ field"`,
 	Comma:   'X',
 	Comment: 'X',
 	Errors:  []error{errInvalidDelim},
+}, {
+	Name:   "SingleQuote",
+	Input:  "§'a,b',§'c''d',§\"e\"\n",
+	Output: [][]string{{"a,b", "c'd", `"e"`}},
+	Quote:  '\'',
+}, {
+	Name:   "BadSingleQuote",
+	Input:  "§a∑'b,c",
+	Errors: []error{&ParseError{Err: ErrBareQuote}},
+	Quote:  '\'',
+}, {
+	Name:   "MultiByteQuote",
+	Input:  "§«a,»»b«,§c\n",
+	Output: [][]string{{"a,»b", "c"}},
+	Quote:  '«',
+	Escape: '»',
+}, {
+	Name:   "Escape",
+	Input:  `§"a\"b\\c",§"d",§e\f` + "\n",
+	Output: [][]string{{`a"b\c`, "d", `e\f`}},
+	Escape: '\\',
+}, {
+	Name:   "EscapeDoubledQuote",
+	Input:  `§"a∑""b"`,
+	Errors: []error{&ParseError{Err: ErrQuote}},
+	Escape: '\\',
+}, {
+	Name:   "EscapeNewline",
+	Input:  "§\"a\\\nb\",§c\n",
+	Output: [][]string{{"a\nb", "c"}},
+	Escape: '\\',
+}, {
+	Name:   "EscapeIsQuote",
+	Input:  `§"a""b",§c` + "\n",
+	Output: [][]string{{`a"b`, "c"}},
+	Escape: '"',
+}, {
+	Name:    "NoQuote",
+	Input:   "§\"a\t§b\"\t§c\n",
+	Output:  [][]string{{`"a`, `b"`, "c"}},
+	Comma:   '\t',
+	NoQuote: true,
+}, {
+	Name:   "BadQuote",
+	Quote:  '\n',
+	Errors: []error{errInvalidDelim},
+}, {
+	Name:   "BadQuoteComma",
+	Quote:  ',',
+	Errors: []error{errInvalidDelim},
+}, {
+	Name:    "BadEscapeNoQuote",
+	NoQuote: true,
+	Escape:  '\\',
+	Errors:  []error{errInvalidDelim},
 }}
 
 func TestRead(t *testing.T) {
@@ -413,6 +471,13 @@ func TestRead(t *testing.T) {
 			r.Comma = tt.Comma
 		}
 		r.Comment = tt.Comment
+		if tt.Quote != 0 {
+			r.Quote = tt.Quote
+		}
+		if tt.NoQuote {
+			r.Quote = 0
+		}
+		r.Escape = tt.Escape
 		if tt.UseFieldsPerRecord {
 			r.FieldsPerRecord = tt.FieldsPerRecord
 		} else {
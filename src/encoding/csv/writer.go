@@ -6,12 +6,15 @@ package csv
 
 import (
 	"bufio"
+	"errors"
 	"io"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 )
 
+var errUnquotable = errors.New("csv: field needs quoting but Writer.Quote is 0")
+
 // A Writer writes records using CSV encoding.
 //
 // As returned by [NewWriter], a Writer writes records terminated by a
@@ -21,6 +24,10 @@ import (
 //
 // [Writer.Comma] is the field delimiter.
 //
+// [Writer.Quote] is the quote character, which encloses the fields that
+// need quoting. If [Writer.Escape] is set, quote characters within quoted
+// fields are preceded by it; otherwise, they are doubled.
+//
 // If [Writer.UseCRLF] is true,
 // the Writer ends each output line with \r\n instead of \n.
 //
@@ -31,6 +38,8 @@ import (
 // be checked by calling the [Writer.Error] method.
 type Writer struct {
 	Comma   rune // Field delimiter (set to ',' by NewWriter)
+	Quote   rune // Quote character (set to '"' by NewWriter), or 0 to never quote fields
+	Escape  rune // Escape character within quoted fields, or 0 to double quote characters
 	UseCRLF bool // True to use \r\n as the line terminator
 	w       *bufio.Writer
 }
@@ -39,18 +48,29 @@ type Writer struct {
 func NewWriter(w io.Writer) *Writer {
 	return &Writer{
 		Comma: ',',
+		Quote: '"',
 		w:     bufio.NewWriter(w),
 	}
 }
 
 // Write writes a single CSV record to w along with any necessary quoting.
 // A record is a slice of strings with each string being one field.
+// If [Writer.Quote] is 0, Write returns an error for the fields that
+// need quoting.
 // Writes are buffered, so [Writer.Flush] must eventually be called to ensure
 // that the record is written to the underlying [io.Writer].
 func (w *Writer) Write(record []string) error {
-	if !validDelim(w.Comma) {
+	if !validDelims(w.Comma, 0, w.Quote, w.Escape) {
 		return errInvalidDelim
 	}
+	quote, escape := w.Quote, quoteEscape(w.Quote, w.Escape)
+	specials := "\"\r\n"
+	if quote != '"' || escape != 0 {
+		specials = "\r\n" + string(quote)
+		if escape != 0 {
+			specials += string(escape)
+		}
+	}
 
 	for n, field := range record {
 		if n > 0 {
@@ -67,13 +87,16 @@ func (w *Writer) Write(record []string) error {
 			}
 			continue
 		}
+		if quote == 0 {
+			return errUnquotable
+		}
 
-		if err := w.w.WriteByte('"'); err != nil {
+		if _, err := w.w.WriteRune(quote); err != nil {
 			return err
 		}
 		for len(field) > 0 {
 			// Search for special characters.
-			i := strings.IndexAny(field, "\"\r\n")
+			i := strings.IndexAny(field, specials)
 			if i < 0 {
 				i = len(field)
 			}
@@ -87,9 +110,17 @@ func (w *Writer) Write(record []string) error {
 			// Encode the special character.
 			if len(field) > 0 {
 				var err error
-				switch field[0] {
-				case '"':
-					_, err = w.w.WriteString(`""`)
+				c, size := utf8.DecodeRuneInString(field)
+				switch c {
+				case quote, escape:
+					if escape != 0 {
+						_, err = w.w.WriteRune(escape)
+					} else {
+						_, err = w.w.WriteRune(quote)
+					}
+					if err == nil {
+						_, err = w.w.WriteRune(c)
+					}
 				case '\r':
 					if !w.UseCRLF {
 						err = w.w.WriteByte('\r')
@@ -101,13 +132,13 @@ func (w *Writer) Write(record []string) error {
 						err = w.w.WriteByte('\n')
 					}
 				}
-				field = field[1:]
+				field = field[size:]
 				if err != nil {
 					return err
 				}
 			}
 		}
-		if err := w.w.WriteByte('"'); err != nil {
+		if _, err := w.w.WriteRune(quote); err != nil {
 			return err
 		}
 	}
@@ -148,6 +179,8 @@ func (w *Writer) WriteAll(records [][]string) error {
 // fieldNeedsQuotes reports whether our field must be enclosed in quotes.
 // Fields with a Comma, fields with a quote or newline, and
 // fields which start with a space must be enclosed in quotes.
+// If Quote is 0, only fields with a Comma or newline need quotes,
+// and they cannot be written.
 // We used to quote empty strings, but we do not anymore (as of Go 1.4).
 // The two representations should be equivalent, but Postgres distinguishes
 // quoted vs non-quoted empty string during database imports, and it has
@@ -162,19 +195,23 @@ func (w *Writer) fieldNeedsQuotes(field string) bool {
 		return false
 	}
 
+	if w.Quote == 0 {
+		return strings.ContainsRune(field, w.Comma) || strings.ContainsAny(field, "\r\n")
+	}
+
 	if field == `\.` {
 		return true
 	}
 
-	if w.Comma < utf8.RuneSelf {
+	if w.Comma < utf8.RuneSelf && w.Quote < utf8.RuneSelf {
 		for i := 0; i < len(field); i++ {
 			c := field[i]
-			if c == '\n' || c == '\r' || c == '"' || c == byte(w.Comma) {
+			if c == '\n' || c == '\r' || c == byte(w.Quote) || c == byte(w.Comma) {
 				return true
 			}
 		}
 	} else {
-		if strings.ContainsRune(field, w.Comma) || strings.ContainsAny(field, "\"\r\n") {
+		if strings.ContainsRune(field, w.Comma) || strings.ContainsRune(field, w.Quote) || strings.ContainsAny(field, "\r\n") {
 			return true
 		}
 	}
@@ -17,6 +17,9 @@ var writeTests = []struct {
 	Error   error
 	UseCRLF bool
 	Comma   rune
+	Quote   rune
+	NoQuote bool // Quote is 0
+	Escape  rune
 }{
 	{Input: [][]string{{"abc"}}, Output: "abc\n"},
 	{Input: [][]string{{"abc"}}, Output: "abc\r\n", UseCRLF: true},
@@ -47,6 +50,17 @@ var writeTests = []struct {
 	{Input: [][]string{{"a", "a", ""}}, Output: "a|a|\n", Comma: '|'},
 	{Input: [][]string{{",", ",", ""}}, Output: ",|,|\n", Comma: '|'},
 	{Input: [][]string{{"foo"}}, Comma: '"', Error: errInvalidDelim},
+	{Input: [][]string{{"a'b", `"c"`, "d,e"}}, Output: `'a''b',"c",'d,e'` + "\n", Quote: '\''},
+	{Input: [][]string{{`a"b\c`, `d\e`}}, Output: `"a\"b\\c",d\e` + "\n", Escape: '\\'},
+	{Input: [][]string{{`a"b`}}, Output: `"a""b"` + "\n", Escape: '"'},
+	{Input: [][]string{{"«a»", "b c"}}, Output: "«««a»«,b c\n", Quote: '«', Escape: '«'},
+	{Input: [][]string{{"«a»", "b c"}}, Output: "«»«a»»«,b c\n", Quote: '«', Escape: '»'},
+	{Input: [][]string{{`"a"`, " b", `\.`}}, Output: "\"a\"\t b\t\\.\n", Comma: '\t', NoQuote: true},
+	{Input: [][]string{{"a", "b\tc"}}, Comma: '\t', NoQuote: true, Error: errUnquotable},
+	{Input: [][]string{{"a"}}, NoQuote: true, Escape: '\\', Error: errInvalidDelim},
+	{Input: [][]string{{"a"}}, Quote: ',', Error: errInvalidDelim},
+	{Input: [][]string{{"a"}}, Quote: '\n', Error: errInvalidDelim},
+	{Input: [][]string{{"a"}}, Escape: ',', Error: errInvalidDelim},
 }
 
 func TestWrite(t *testing.T) {
@@ -57,6 +71,13 @@ func TestWrite(t *testing.T) {
 		if tt.Comma != 0 {
 			f.Comma = tt.Comma
 		}
+		if tt.Quote != 0 {
+			f.Quote = tt.Quote
+		}
+		if tt.NoQuote {
+			f.Quote = 0
+		}
+		f.Escape = tt.Escape
 		err := f.WriteAll(tt.Input)
 		if err != tt.Error {
 			t.Errorf("Unexpected error:\ngot  %v\nwant %v", err, tt.Error)
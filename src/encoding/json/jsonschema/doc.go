@@ -0,0 +1,53 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+// Package jsonschema implements validation of JSON values against
+// JSON Schema (draft 2020-12) as specified in
+// https://json-schema.org/draft/2020-12/json-schema-core and
+// https://json-schema.org/draft/2020-12/json-schema-validation.
+//
+// A schema document is compiled into a [Schema] by [Compile] or by a
+// [Compiler], which additionally resolves references to other schema
+// documents that were registered with [Compiler.AddResource].
+// A compiled [Schema] is safe for concurrent use and validates
+// JSON text ([Schema.Validate] and [Schema.ValidateDecode])
+// or the JSON representation of a Go value ([Schema.ValidateGo]).
+// Every violated assertion is reported as a [Violation] within a
+// [ValidationError], which identifies both the offending JSON value and
+// the schema keyword that rejected it using JSON Pointers (RFC 6901).
+//
+// The [Generate] function produces a schema that describes
+// the JSON representation of a Go type according to "json".
+//
+// # Vocabularies
+//
+// All keywords of the core, applicator, unevaluated, validation,
+// and meta-data vocabularies are supported, including "$dynamicRef".
+// The "format" keyword is an annotation by default and
+// is only asserted if [Compiler.AssertFormat] is set.
+// The content vocabulary ("contentEncoding", "contentMediaType",
+// and "contentSchema") is treated as annotations and never asserted.
+// Unknown keywords are ignored.
+//
+// Schema documents are not validated against the draft 2020-12 meta-schema.
+// Instead, each known keyword is checked to have a well-formed value
+// when compiled. A "$schema" keyword that names a dialect
+// other than draft 2020-12 is rejected.
+//
+// # Regular Expressions
+//
+// The "pattern" and "patternProperties" keywords use the
+// RE2 syntax accepted by package [regexp] rather than ECMA-262.
+// The two dialects agree on commonly used syntax,
+// but ECMA-262 features such as backreferences and lookaround
+// are rejected when a schema is compiled.
+package jsonschema
+
+// requireKeyedLiterals can be embedded in a struct to require keyed literals.
+type requireKeyedLiterals struct{}
+
+// nonComparable can be embedded in a struct to prevent comparability.
+type nonComparable [0]func()
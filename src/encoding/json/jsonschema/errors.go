@@ -0,0 +1,103 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"strconv"
+
+	"encoding/json/jsontext"
+)
+
+const errorPrefix = "jsonschema: "
+
+// SchemaError describes a schema document that could not be compiled,
+// or a schema that could not be evaluated.
+type SchemaError struct {
+	requireKeyedLiterals
+	nonComparable
+
+	// Location is the absolute location of the invalid schema or keyword
+	// as a URI whose fragment is a JSON Pointer
+	// (e.g., "https://example.com/a.json#/properties/b/type").
+	// It is empty if the schema document itself could not be parsed.
+	Location string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *SchemaError) Error() string {
+	b := []byte(errorPrefix + "invalid schema")
+	if e.Location != "" {
+		b = strconv.AppendQuote(append(b, " at "...), e.Location)
+	}
+	if e.Err != nil {
+		b = append(append(b, ": "...), e.Err.Error()...)
+	}
+	return string(b)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// Violation describes a JSON value that does not satisfy
+// a particular assertion of a schema.
+type Violation struct {
+	// InstanceLocation is the location of the invalid value
+	// within the validated JSON value.
+	InstanceLocation jsontext.Pointer
+
+	// KeywordLocation is the location of the keyword that rejected the value
+	// relative to the root schema, following the evaluation path through
+	// any "$ref" or "$dynamicRef" keywords
+	// (e.g., "/properties/a/$ref/minimum").
+	KeywordLocation jsontext.Pointer
+
+	// AbsoluteKeywordLocation is the canonical location of the keyword
+	// as a URI whose fragment is a JSON Pointer
+	// (e.g., "https://example.com/defs.json#/$defs/count/minimum").
+	AbsoluteKeywordLocation string
+
+	// Message describes why the value was rejected.
+	Message string
+}
+
+func (v Violation) String() string {
+	b := []byte(v.Message)
+	if v.InstanceLocation != "" {
+		b = strconv.AppendQuote(append(b, " within "...), string(v.InstanceLocation))
+	}
+	b = strconv.AppendQuote(append(b, " (keyword "...), string(v.KeywordLocation))
+	return string(append(b, ')'))
+}
+
+// ValidationError reports that a JSON value is invalid according to a schema.
+type ValidationError struct {
+	requireKeyedLiterals
+	nonComparable
+
+	// Violations lists every assertion that the value failed
+	// in the order that they were evaluated. It is never empty.
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	b := []byte(errorPrefix)
+	if len(e.Violations) == 0 {
+		return string(append(b, "invalid value"...))
+	}
+	b = append(b, e.Violations[0].String()...)
+	switch n := len(e.Violations) - 1; n {
+	case 0:
+	case 1:
+		b = append(b, " and 1 other violation"...)
+	default:
+		b = append(strconv.AppendInt(append(b, " and "...), int64(n), 10), " other violations"...)
+	}
+	return string(b)
+}
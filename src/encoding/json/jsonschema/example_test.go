@@ -0,0 +1,140 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"encoding/json/jsonschema"
+	"encoding/json/jsontext"
+)
+
+func Example() {
+	s, err := jsonschema.Compile(jsontext.Value(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = s.Validate(jsontext.Value(`{"tags": ["a", 1, "a"], "color": "red"}`))
+	var verr *jsonschema.ValidationError
+	if errors.As(err, &verr) {
+		for _, v := range verr.Violations {
+			fmt.Printf("%q: %s (%s)\n", v.InstanceLocation, v.Message, v.KeywordLocation)
+		}
+	}
+
+	// Output:
+	// "": missing required property "name" (/required)
+	// "/tags": array items 0 and 2 are equal (/properties/tags/uniqueItems)
+	// "/tags/1": got number, want string (/properties/tags/items/type)
+	// "/color": value is not allowed (/additionalProperties)
+}
+
+// Schemas may reference other schema documents registered with a Compiler.
+func ExampleCompiler_AddResource() {
+	var c jsonschema.Compiler
+	if err := c.AddResource("https://example.com/money.json", jsontext.Value(`{
+		"type": "object",
+		"properties": {
+			"amount": {"type": "number", "multipleOf": 0.01},
+			"currency": {"enum": ["EUR", "USD"]}
+		},
+		"required": ["amount", "currency"]
+	}`)); err != nil {
+		log.Fatal(err)
+	}
+	s, err := c.Compile(jsontext.Value(`{
+		"$id": "https://example.com/invoice.json",
+		"properties": {"total": {"$ref": "money.json"}}
+	}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(s.Validate(jsontext.Value(`{"total": {"amount": 12.50, "currency": "EUR"}}`)))
+	fmt.Println(s.Validate(jsontext.Value(`{"total": {"amount": 12.505, "currency": "EUR"}}`)))
+
+	// Output:
+	// <nil>
+	// jsonschema: 12.505 is not a multiple of 0.01 within "/total/amount" (keyword "/properties/total/$ref/properties/amount/multipleOf")
+}
+
+// A stream of JSON values can be validated one value at a time.
+func ExampleSchema_ValidateDecode() {
+	s := jsonschema.MustCompile(jsontext.Value(`{"required": ["id"]}`))
+	d := jsontext.NewDecoder(strings.NewReader(`{"id": 1} {"name": "x"} {"id": 3}`))
+	for d.PeekKind() != 0 {
+		fmt.Println(s.ValidateDecode(d))
+	}
+
+	// Output:
+	// <nil>
+	// jsonschema: missing required property "id" (keyword "/required")
+	// <nil>
+}
+
+// A schema can be generated from a Go type and then used to
+// validate JSON that is not yet unmarshaled.
+func ExampleGenerate() {
+	type Point struct {
+		X, Y  int
+		Label string `json:"label,omitempty"`
+	}
+	b, err := jsonschema.Generate(reflect.TypeFor[[]Point]())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := b.Indent(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(b)
+
+	s := jsonschema.MustCompile(b)
+	fmt.Println(s.Validate(jsontext.Value(`[{"X": 1, "Y": 2}, {"X": 3, "label": "a"}]`)))
+
+	// Output:
+	// {
+	// 	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	// 	"type": "array",
+	// 	"items": {
+	// 		"$ref": "#/$defs/Point"
+	// 	},
+	// 	"$defs": {
+	// 		"Point": {
+	// 			"type": "object",
+	// 			"properties": {
+	// 				"X": {
+	// 					"type": "integer"
+	// 				},
+	// 				"Y": {
+	// 					"type": "integer"
+	// 				},
+	// 				"label": {
+	// 					"type": "string"
+	// 				}
+	// 			},
+	// 			"required": [
+	// 				"X",
+	// 				"Y"
+	// 			]
+	// 		}
+	// 	}
+	// }
+	// jsonschema: missing required property "Y" within "/1" (keyword "/items/$ref/required")
+}
@@ -0,0 +1,396 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"cmp"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"encoding/json/v2"
+)
+
+// This file derives how [json.Marshal] maps Go struct fields to
+// JSON object members. It follows the rules of "json" for `json` struct tags,
+// embedded fields and inlined fields, which that package does not export.
+
+var (
+	jsonUnmarshalerFromType = reflect.TypeFor[json.UnmarshalerFrom]()
+	jsonUnmarshalerType     = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType     = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+	allMethodTypes = []reflect.Type{
+		jsonMarshalerToType, jsonMarshalerType, textAppenderType, textMarshalerType,
+		jsonUnmarshalerFromType, jsonUnmarshalerType, textUnmarshalerType,
+	}
+)
+
+var errNoExportedFields = errors.New("Go struct has no exported fields")
+
+// field is a Go struct field that is serialized as a JSON object member.
+type field struct {
+	index []int        // index of the field within the root Go struct
+	typ   reflect.Type // Go type of the field
+	fieldOptions
+}
+
+// fieldOptions are the options of a `json` struct tag.
+type fieldOptions struct {
+	name      string // JSON object member name
+	hasName   bool   // whether the name was specified in the tag
+	inline    bool
+	unknown   bool
+	omitzero  bool
+	omitempty bool
+	string    bool
+	format    string
+}
+
+// structFields reports the fields of the Go struct type root in the order
+// that they are marshaled, with the fields of inlined Go structs flattened.
+// If root has an inlined fallback field (i.e., tagged with `inline` or
+// `unknown` and of Go map or [jsontext.Value] type), then fallback is its
+// Go type. It reports the first error in how root or its inlined Go structs
+// are declared.
+func structFields(root reflect.Type) (fields []field, fallback reflect.Type, err error) {
+	errorf := func(f string, a ...any) {
+		err = cmp.Or(err, fmt.Errorf(f, a...))
+	}
+
+	// Perform a breadth-first search over all reachable fields,
+	// so that len(f.index) is monotonically increasing.
+	type queueEntry struct {
+		typ           reflect.Type
+		index         []int
+		visitChildren bool // whether to visit inlined fields of this struct
+	}
+	queue := []queueEntry{{root, nil, true}}
+	seen := map[reflect.Type]bool{root: true}
+	var allFields, fallbacks []field
+	for len(queue) > 0 {
+		qe := queue[0]
+		queue = queue[1:]
+
+		t := qe.typ
+		fallbackIndex := -1                // index of last inlined fallback field in t
+		namesIndex := make(map[string]int) // index of each field with a given name in t
+		var hasAnyJSONTag, hasAnyJSONField bool
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			_, hasTag := sf.Tag.Lookup("json")
+			hasAnyJSONTag = hasAnyJSONTag || hasTag
+			options, ignored, err2 := parseFieldOptions(sf)
+			if err2 != nil {
+				err = cmp.Or(err, err2)
+			}
+			if ignored {
+				continue
+			}
+			hasAnyJSONField = true
+			f := field{
+				index:        append(slices.Clip(qe.index), i),
+				typ:          sf.Type,
+				fieldOptions: options,
+			}
+			if sf.Anonymous && !f.hasName {
+				if indirectType(f.typ).Kind() != reflect.Struct {
+					errorf("embedded Go struct field %s of non-struct type must be explicitly given a JSON name", sf.Name)
+				} else {
+					f.inline = true
+				}
+			}
+
+			if !f.inline && !f.unknown {
+				// Unexported fields cannot be serialized except for
+				// embedded fields of a struct type.
+				if !sf.IsExported() {
+					tf := indirectType(f.typ)
+					if !(sf.Anonymous && tf.Kind() == reflect.Struct) {
+						errorf("Go struct field %s is not exported", sf.Name)
+						continue
+					}
+					if implements(tf, allMethodTypes...) {
+						errorf("Go struct field %s is not exported for method calls", sf.Name)
+						continue
+					}
+				}
+				if j, ok := namesIndex[f.name]; ok {
+					errorf("Go struct fields %s and %s conflict over JSON object name %q", t.Field(j).Name, sf.Name, f.name)
+				}
+				namesIndex[f.name] = i
+				allFields = append(allFields, f)
+				continue
+			}
+
+			// Handle an inlined field that serializes to
+			// zero or more JSON object members.
+			switch f.fieldOptions {
+			case fieldOptions{name: f.name, inline: true}, fieldOptions{name: f.name, unknown: true}:
+			case fieldOptions{name: f.name, inline: true, unknown: true}:
+				errorf("Go struct field %s cannot have both `inline` and `unknown` specified", sf.Name)
+				f.inline = false
+			default:
+				errorf("Go struct field %s cannot have any options other than `inline` or `unknown` specified", sf.Name)
+				if f.hasName {
+					continue
+				}
+				f.fieldOptions = fieldOptions{name: f.name, inline: f.inline && !f.unknown, unknown: f.unknown}
+			}
+			tf := indirectType(f.typ)
+			if implements(tf, allMethodTypes...) && tf != jsontextValueType {
+				errorf("inlined Go struct field %s of type %s must not implement marshal or unmarshal methods", sf.Name, tf)
+			}
+			if tf.Kind() == reflect.Struct {
+				if f.unknown {
+					errorf("inlined Go struct field %s of type %s with `unknown` tag must be a Go map of string key or a jsontext.Value", sf.Name, tf)
+					continue
+				}
+				if qe.visitChildren {
+					queue = append(queue, queueEntry{tf, f.index, !seen[tf]})
+				}
+				seen[tf] = true
+				continue
+			} else if !sf.IsExported() {
+				errorf("inlined Go struct field %s is not exported", sf.Name)
+				continue
+			}
+			switch {
+			case tf == jsontextValueType:
+			case tf.Kind() == reflect.Map && tf.Key().Kind() == reflect.String:
+				if implements(tf.Key(), allMethodTypes...) {
+					errorf("inlined map field %s of type %s must have a string key that does not implement marshal or unmarshal methods", sf.Name, tf)
+					continue
+				}
+			default:
+				errorf("inlined Go struct field %s of type %s must be a Go struct, Go map of string key, or jsontext.Value", sf.Name, tf)
+				continue
+			}
+			if fallbackIndex >= 0 {
+				errorf("inlined Go struct fields %s and %s cannot both be a Go map or jsontext.Value", t.Field(fallbackIndex).Name, sf.Name)
+			}
+			fallbackIndex = i
+			fallbacks = append(fallbacks, f)
+		}
+
+		// Go structs without any serializable fields or `json` tags
+		// cannot be marshaled, which catches types such as errors.
+		if t.NumField() > 0 && !hasAnyJSONTag && !hasAnyJSONField {
+			err = cmp.Or(err, fmt.Errorf("%v: %w", t, errNoExportedFields))
+		}
+	}
+
+	// Select the dominant field from each set of fields with the same name:
+	// the one that exists alone at the shallowest depth,
+	// or the one that is uniquely tagged with a JSON name.
+	// Otherwise, no field with that name is serialized.
+	slices.SortStableFunc(allFields, func(x, y field) int {
+		return cmp.Or(
+			strings.Compare(x.name, y.name),
+			cmp.Compare(len(x.index), len(y.index)),
+			boolsCompare(!x.hasName, !y.hasName))
+	})
+	for len(allFields) > 0 {
+		n := 1
+		for n < len(allFields) && allFields[n-1].name == allFields[n].name {
+			n++
+		}
+		if n == 1 || len(allFields[0].index) != len(allFields[1].index) || allFields[0].hasName != allFields[1].hasName {
+			fields = append(fields, allFields[0])
+		}
+		allFields = allFields[n:]
+	}
+	slices.SortFunc(fields, func(x, y field) int {
+		return slices.Compare(x.index, y.index)
+	})
+	if n := len(fallbacks); n == 1 || (n > 1 && len(fallbacks[0].index) != len(fallbacks[1].index)) {
+		fallback = indirectType(fallbacks[0].typ)
+	}
+	return fields, fallback, err
+}
+
+// parseFieldOptions parses the `json` tag of a Go struct field.
+// It reports whether the field is ignored.
+func parseFieldOptions(sf reflect.StructField) (out fieldOptions, ignored bool, err error) {
+	tag, hasTag := sf.Tag.Lookup("json")
+	if tag == "-" {
+		return fieldOptions{}, true, nil
+	}
+	if !sf.IsExported() && !sf.Anonymous {
+		if hasTag {
+			err = fmt.Errorf("unexported Go struct field %s cannot have non-ignored `json:%q` tag", sf.Name, tag)
+		}
+		return fieldOptions{}, true, err
+	}
+
+	// The name is either an identifier-like string or a single-quoted string.
+	out.name = sf.Name
+	if len(tag) > 0 && !strings.HasPrefix(tag, ",") {
+		n := len(tag) - len(strings.TrimLeftFunc(tag, func(r rune) bool {
+			return !strings.ContainsRune(",\\'\"`", r)
+		}))
+		name := tag[:n]
+		var err2 error
+		if !strings.HasPrefix(tag[n:], ",") && len(name) != len(tag) {
+			name, n, err2 = consumeTagOption(tag)
+			if err2 != nil {
+				err = cmp.Or(err, fmt.Errorf("Go struct field %s has malformed `json` tag: %v", sf.Name, err2))
+			}
+		}
+		if !utf8.ValidString(name) {
+			err = cmp.Or(err, fmt.Errorf("Go struct field %s has JSON object name %q with invalid UTF-8", sf.Name, name))
+			name = string([]rune(name))
+		}
+		if err2 == nil {
+			out.hasName = true
+			out.name = name
+		}
+		tag = tag[n:]
+	}
+
+	var wasFormat bool
+	seenOpts := make(map[string]bool)
+	for len(tag) > 0 {
+		if tag[0] != ',' {
+			err = cmp.Or(err, fmt.Errorf("Go struct field %s has malformed `json` tag: invalid character %q before next option (expecting ',')", sf.Name, tag[0]))
+		} else {
+			tag = tag[len(","):]
+			if len(tag) == 0 {
+				err = cmp.Or(err, fmt.Errorf("Go struct field %s has malformed `json` tag: invalid trailing ',' character", sf.Name))
+				break
+			}
+		}
+		opt, n, err2 := consumeTagOption(tag)
+		if err2 != nil {
+			err = cmp.Or(err, fmt.Errorf("Go struct field %s has malformed `json` tag: %v", sf.Name, err2))
+		}
+		rawOpt := tag[:n]
+		tag = tag[n:]
+		if wasFormat {
+			err = cmp.Or(err, fmt.Errorf("Go struct field %s has `format` tag option that was not specified last", sf.Name))
+		}
+		switch opt {
+		case "case", "format":
+			if !strings.HasPrefix(tag, ":") {
+				err = cmp.Or(err, fmt.Errorf("Go struct field %s is missing value for `%s` tag option", sf.Name, opt))
+				break
+			}
+			tag = tag[len(":"):]
+			val, n, err2 := consumeTagOption(tag)
+			if err2 != nil {
+				err = cmp.Or(err, fmt.Errorf("Go struct field %s has malformed value for `%s` tag option: %v", sf.Name, opt, err2))
+				break
+			}
+			tag = tag[n:]
+			if opt == "format" {
+				out.format = val
+				wasFormat = true
+			} else if val != "ignore" && val != "strict" {
+				err = cmp.Or(err, fmt.Errorf("Go struct field %s has unknown `case:%s` tag value", sf.Name, val))
+			}
+		case "inline":
+			out.inline = true
+		case "unknown":
+			out.unknown = true
+		case "omitzero":
+			out.omitzero = true
+		case "omitempty":
+			out.omitempty = true
+		case "string":
+			out.string = true
+		}
+		if seenOpts[opt] {
+			err = cmp.Or(err, fmt.Errorf("Go struct field %s has duplicate appearance of `%s` tag option", sf.Name, rawOpt))
+		}
+		seenOpts[opt] = true
+	}
+	return out, false, err
+}
+
+// consumeTagOption consumes the next option,
+// which is either a Go identifier or a single-quoted string.
+// If the next option is invalid, it returns all of in until the next comma,
+// and reports an error.
+func consumeTagOption(in string) (string, int, error) {
+	i := strings.IndexByte(in, ',')
+	if i < 0 {
+		i = len(in)
+	}
+
+	switch r, _ := utf8.DecodeRuneInString(in); {
+	case r == '_' || unicode.IsLetter(r):
+		n := len(in) - len(strings.TrimLeftFunc(in, isLetterOrDigit))
+		return in[:n], n, nil
+	case r == '\'':
+		// Convert a single-quoted string to a double-quoted string
+		// and rely on strconv.Unquote to handle the rest.
+		var inEscape bool
+		b := []byte{'"'}
+		n := len(`'`)
+		for len(in) > n {
+			r, rn := utf8.DecodeRuneInString(in[n:])
+			switch {
+			case inEscape:
+				if r == '\'' {
+					b = b[:len(b)-1] // remove escape character: `\'` => `'`
+				}
+				inEscape = false
+			case r == '\\':
+				inEscape = true
+			case r == '"':
+				b = append(b, '\\') // insert escape character: `"` => `\"`
+			case r == '\'':
+				b = append(b, '"')
+				n += len(`'`)
+				out, err := strconv.Unquote(string(b))
+				if err != nil {
+					return in[:i], i, fmt.Errorf("invalid single-quoted string: %s", in[:n])
+				}
+				return out, n, nil
+			}
+			b = append(b, in[n:][:rn]...)
+			n += rn
+		}
+		return in[:i], i, fmt.Errorf("single-quoted string not terminated: %s...", in[:min(n, 10)])
+	case len(in) == 0:
+		return in[:i], i, io.ErrUnexpectedEOF
+	default:
+		return in[:i], i, fmt.Errorf("invalid character %q at start of option (expecting Unicode letter or single quote)", r)
+	}
+}
+
+func isLetterOrDigit(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// boolsCompare compares x and y, ordering false before true.
+func boolsCompare(x, y bool) int {
+	switch {
+	case !x && y:
+		return -1
+	case x && !y:
+		return +1
+	}
+	return 0
+}
+
+// indirectType unwraps one level of pointer indirection
+// similar to how Go only allows embedding either T or *T,
+// but not **T or P (which is a named pointer).
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer && t.Name() == "" {
+		t = t.Elem()
+	}
+	return t
+}
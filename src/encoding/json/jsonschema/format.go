@@ -0,0 +1,248 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"internal/lazyregexp"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"encoding/json/jsontext"
+)
+
+// formats are the values of the "format" keyword that can be asserted.
+var formats = map[string]func(string) bool{
+	"date":                  isDate,
+	"date-time":             isDateTime,
+	"duration":              durationRegexp.MatchString,
+	"email":                 isEmail,
+	"hostname":              isHostname,
+	"ipv4":                  isIPv4,
+	"ipv6":                  isIPv6,
+	"json-pointer":          func(s string) bool { return jsontext.Pointer(s).IsValid() },
+	"regex":                 isRegexp,
+	"relative-json-pointer": isRelativePointer,
+	"time":                  isTime,
+	"uri":                   func(s string) bool { return isURI(s, true) },
+	"uri-reference":         func(s string) bool { return isURI(s, false) },
+	"uuid":                  isUUID,
+}
+
+// durationRegexp matches "duration" as specified in RFC 3339, appendix A.
+var durationRegexp = lazyregexp.New(`^P(?:\d+W|(?:\d+Y(?:\d+M(?:\d+D)?)?|\d+M(?:\d+D)?|\d+D)(?:T(?:\d+H(?:\d+M(?:\d+S)?)?|\d+M(?:\d+S)?|\d+S))?|T(?:\d+H(?:\d+M(?:\d+S)?)?|\d+M(?:\d+S)?|\d+S))$`)
+
+// digits parses s as an unsigned decimal number of exactly n digits.
+func digits(s string, n int) (int, bool) {
+	if len(s) != n {
+		return 0, false
+	}
+	var x int
+	for i := 0; i < n; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		x = 10*x + int(s[i]-'0')
+	}
+	return x, true
+}
+
+// isDate reports whether s is a "full-date" as specified in RFC 3339.
+func isDate(s string) bool {
+	if len(s) != len("2006-01-02") || s[4] != '-' || s[7] != '-' {
+		return false
+	}
+	year, ok1 := digits(s[:4], 4)
+	month, ok2 := digits(s[5:7], 2)
+	day, ok3 := digits(s[8:], 2)
+	if !ok1 || !ok2 || !ok3 || month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	daysIn := [...]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}[month-1]
+	if month == 2 && year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+		daysIn = 29
+	}
+	return day <= daysIn
+}
+
+// isTime reports whether s is a "full-time" as specified in RFC 3339.
+// A leap second is only valid at the end of a UTC day.
+func isTime(s string) bool {
+	if len(s) < len("15:04:05Z") || s[2] != ':' || s[5] != ':' {
+		return false
+	}
+	hour, ok1 := digits(s[:2], 2)
+	minute, ok2 := digits(s[3:5], 2)
+	second, ok3 := digits(s[6:8], 2)
+	if !ok1 || !ok2 || !ok3 || hour > 23 || minute > 59 || second > 60 {
+		return false
+	}
+	s = s[8:]
+	if len(s) > 0 && s[0] == '.' {
+		n := 1
+		for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+			n++
+		}
+		if n == 1 {
+			return false
+		}
+		s = s[n:]
+	}
+	var offset int // minutes east of UTC
+	switch {
+	case s == "Z" || s == "z":
+	case len(s) == len("+07:00") && (s[0] == '+' || s[0] == '-') && s[3] == ':':
+		h, ok1 := digits(s[1:3], 2)
+		m, ok2 := digits(s[4:], 2)
+		if !ok1 || !ok2 || h > 23 || m > 59 {
+			return false
+		}
+		offset = 60*h + m
+		if s[0] == '-' {
+			offset = -offset
+		}
+	default:
+		return false
+	}
+	if second == 60 {
+		const day = 24 * 60
+		utc := ((60*hour+minute-offset)%day + day) % day
+		return utc == day-1
+	}
+	return true
+}
+
+// isDateTime reports whether s is a "date-time" as specified in RFC 3339.
+func isDateTime(s string) bool {
+	i := strings.IndexAny(s, "Tt")
+	return i >= 0 && isDate(s[:i]) && isTime(s[i+1:])
+}
+
+// isEmail reports whether s is a "Mailbox" as specified in RFC 5321,
+// section 4.1.2, restricted to a dot-atom or quoted local part.
+func isEmail(s string) bool {
+	i := strings.LastIndexByte(s, '@')
+	if i <= 0 {
+		return false
+	}
+	local, domain := s[:i], s[i+1:]
+	switch {
+	case len(local) >= 2 && local[0] == '"' && local[len(local)-1] == '"':
+		for _, c := range []byte(local[1 : len(local)-1]) {
+			if c < ' ' || c > '~' {
+				return false
+			}
+		}
+	default:
+		for _, atom := range strings.Split(local, ".") {
+			if atom == "" || strings.ContainsFunc(atom, func(r rune) bool {
+				return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+					strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r))
+			}) {
+				return false
+			}
+		}
+	}
+	if lit, ok := strings.CutPrefix(domain, "["); ok {
+		lit, ok = strings.CutSuffix(lit, "]")
+		if lit6, ok6 := strings.CutPrefix(lit, "IPv6:"); ok6 {
+			return ok && isIPv6(lit6)
+		}
+		return ok && isIPv4(lit)
+	}
+	return isHostname(domain)
+}
+
+// isHostname reports whether s is a host name as specified in RFC 1123,
+// section 2.1.
+func isHostname(s string) bool {
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range []byte(label) {
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isIPv4 reports whether s is an IPv4 address in dotted-quad notation.
+func isIPv4(s string) bool {
+	ip, err := netip.ParseAddr(s)
+	return err == nil && ip.Is4()
+}
+
+// isIPv6 reports whether s is an IPv6 address as specified in RFC 4291,
+// section 2.2, without a zone.
+func isIPv6(s string) bool {
+	ip, err := netip.ParseAddr(s)
+	return err == nil && ip.Is6() && ip.Zone() == ""
+}
+
+// isRegexp reports whether s is a regular expression
+// in the syntax accepted by package regexp.
+func isRegexp(s string) bool {
+	_, err := regexp.Compile(s)
+	return err == nil
+}
+
+// isRelativePointer reports whether s is a relative JSON Pointer
+// as specified in draft-handrews-relative-json-pointer-01.
+func isRelativePointer(s string) bool {
+	n := 0
+	for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+		n++
+	}
+	if n == 0 || (n > 1 && s[0] == '0') {
+		return false
+	}
+	if _, err := strconv.ParseUint(s[:n], 10, 64); err != nil {
+		return false
+	}
+	return s[n:] == "#" || jsontext.Pointer(s[n:]).IsValid()
+}
+
+// isURI reports whether s is a URI or, if abs is false,
+// a URI reference as specified in RFC 3986.
+func isURI(s string, abs bool) bool {
+	for _, c := range []byte(s) {
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"<>\^`+"`{|}", c) >= 0 {
+			return false
+		}
+	}
+	u, err := url.Parse(s)
+	return err == nil && (!abs || u.IsAbs())
+}
+
+// isUUID reports whether s is a UUID in the string representation
+// specified in RFC 9562, section 4.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range []byte(s) {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
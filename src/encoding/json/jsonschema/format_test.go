@@ -0,0 +1,94 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format  string
+		valid   []string
+		invalid []string
+	}{{
+		format:  "date",
+		valid:   []string{"2025-01-31", "2024-02-29", "2000-02-29"},
+		invalid: []string{"2025-02-29", "1900-02-29", "2025-13-01", "2025-1-01", "2025-01-32", "2025-01-00", "20250131"},
+	}, {
+		format:  "time",
+		valid:   []string{"08:30:06Z", "08:30:06.283185z", "08:30:06+07:00", "23:59:60Z", "15:59:60-08:00"},
+		invalid: []string{"08:30:06", "24:00:00Z", "08:60:00Z", "22:59:60Z", "08:30:06.Z", "08:30:06+24:00", "8:30:06Z"},
+	}, {
+		format:  "date-time",
+		valid:   []string{"1963-06-19T08:30:06.283185Z", "1963-06-19t08:30:06+01:30", "1998-12-31T23:59:60Z"},
+		invalid: []string{"1963-06-19 08:30:06Z", "1963-06-19T08:30:06", "1963-06-19", "1990-02-31T15:59:59.123-08:00"},
+	}, {
+		format:  "duration",
+		valid:   []string{"P4DT12H30M5S", "P1Y2M", "PT0S", "P2W", "PT36H", "P1D"},
+		invalid: []string{"P", "PT", "P1YT", "P2W1D", "P1S", "PT1D", "4DT12H", "P1.5D"},
+	}, {
+		format:  "email",
+		valid:   []string{"joe.bloggs@example.com", "te~st+1@example.com", `"joe bloggs"@example.com`, "joe@[127.0.0.1]", "joe@[IPv6:::1]"},
+		invalid: []string{"joe", "@example.com", ".joe@example.com", "joe.@example.com", "jo..e@example.com", "joe@-example.com", "joe@[256.0.0.1]"},
+	}, {
+		format:  "hostname",
+		valid:   []string{"www.example.com", "xn--4gbwdl.xn--wgbh1c", "a", "1host"},
+		invalid: []string{"", "-a-host.com", "a-host-.com", "a_host.com", "host..com", "example.com.", strings.Repeat("a", 64) + ".com"},
+	}, {
+		format:  "ipv4",
+		valid:   []string{"192.168.0.1", "0.0.0.0", "255.255.255.255"},
+		invalid: []string{"256.0.0.1", "192.168.0", "087.10.0.1", "::1", "1.2.3.4%eth0"},
+	}, {
+		format:  "ipv6",
+		valid:   []string{"::1", "::", "2001:db8::ff00:42:8329", "::ffff:192.168.0.1"},
+		invalid: []string{"12345::", "1:2:3:4:5:6:7:8:9", "::1%eth0", "192.168.0.1", ":::1"},
+	}, {
+		format:  "json-pointer",
+		valid:   []string{"", "/", "/foo/0", "/a~1b/m~0n"},
+		invalid: []string{"foo", "/~2", "/a~"},
+	}, {
+		format:  "relative-json-pointer",
+		valid:   []string{"0", "1/a", "2#", "10/0"},
+		invalid: []string{"", "/a", "01", "-1/a", "1#/a"},
+	}, {
+		format:  "regex",
+		valid:   []string{`^[a-z]+$`, `\d{3}`},
+		invalid: []string{`[`, `(?<=a)b`, `a{2,1}`},
+	}, {
+		format:  "uri",
+		valid:   []string{"https://example.com/a?b=c#d", "urn:isbn:0451450523", "mailto:joe@example.com"},
+		invalid: []string{"//example.com", "/abc", "https://example.com/a b", `https://example.com/\`, "https://example.com/é"},
+	}, {
+		format:  "uri-reference",
+		valid:   []string{"https://example.com", "/abc", "abc", "#frag", ""},
+		invalid: []string{"a b", `\\WINDOWS\share`, "%zz"},
+	}, {
+		format:  "uuid",
+		valid:   []string{"2eb8aa08-aa98-11ea-b4aa-73b441d16380", "2EB8AA08-AA98-11EA-B4AA-73B441D16380"},
+		invalid: []string{"2eb8aa08aa9811eab4aa73b441d16380", "2eb8aa08-aa98-11ea-b4aa-73b441d1638", "2eb8aa08-aa98-11ea-b4aa-73b441d1638g", "{2eb8aa08-aa98-11ea-b4aa-73b441d16380}"},
+	}}
+
+	for _, tt := range tests {
+		check := formats[tt.format]
+		if check == nil {
+			t.Errorf("format %q is not supported", tt.format)
+			continue
+		}
+		for _, s := range tt.valid {
+			if !check(s) {
+				t.Errorf("format %q: %q is invalid, want valid", tt.format, s)
+			}
+		}
+		for _, s := range tt.invalid {
+			if check(s) {
+				t.Errorf("format %q: %q is valid, want invalid", tt.format, s)
+			}
+		}
+	}
+}
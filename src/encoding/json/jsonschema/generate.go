@@ -0,0 +1,307 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+)
+
+var (
+	jsontextValueType = reflect.TypeFor[jsontext.Value]()
+	timeTimeType      = reflect.TypeFor[time.Time]()
+	timeDurationType  = reflect.TypeFor[time.Duration]()
+
+	jsonMarshalerToType = reflect.TypeFor[json.MarshalerTo]()
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	textAppenderType    = reflect.TypeFor[encoding.TextAppender]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+var errUnsupportedGoType = errors.New("unsupported Go type")
+
+// Generate returns a schema that describes the JSON representation of
+// values of the Go type t as produced by [json.Marshal] with default options.
+//
+// Go struct types are described according to their `json` struct tags:
+// every member that is not omitted by `omitzero` or `omitempty`
+// is required, and the `string` and `format` options are respected.
+// Additional members are only described if the struct has an inlined
+// fallback field of Go map type.
+// Named Go struct types other than t are placed in "$defs"
+// so that recursive types can be described.
+// Go types that implement [json.MarshalerTo] or [json.Marshaler]
+// may produce any JSON value and are described by the true schema,
+// while those that implement [encoding.TextAppender] or
+// [encoding.TextMarshaler] are described as a JSON string.
+//
+// It reports an error if t has no JSON representation
+// (e.g., channels and functions).
+func Generate(t reflect.Type) (jsontext.Value, error) {
+	root := t
+	for root.Kind() == reflect.Pointer {
+		root = root.Elem()
+	}
+	g := &generator{root: root, names: make(map[reflect.Type]string), used: make(map[string]bool)}
+	s, err := g.schema(t, field{})
+	if err != nil {
+		return nil, err
+	}
+	out := newObject().set("$schema", newString(dialect))
+	out.obj = append(out.obj, s.obj...)
+	if len(g.defs) > 0 {
+		out.set("$defs", &value{kind: '{', obj: g.defs})
+	}
+	return appendValue(nil, out), nil
+}
+
+// generator holds the state of a single call to [Generate].
+type generator struct {
+	root      reflect.Type            // root Go type, without pointers
+	defs      []member                // named schemas in "$defs"
+	names     map[reflect.Type]string // name of each Go type in defs
+	used      map[string]bool         // names already used in defs
+	visiting  []reflect.Type          // anonymous Go types being generated
+	generated bool                    // whether the root was generated
+}
+
+func newObject() *value            { return &value{kind: '{'} }
+func newString(s string) *value    { return &value{kind: '"', str: s} }
+func newNumber(n int) *value       { return &value{kind: '0', str: strconv.Itoa(n)} }
+func newArray(vs ...*value) *value { return &value{kind: '[', arr: vs} }
+func newType(name string) *value   { return newObject().set("type", newString(name)) }
+func newRef(ref string) *value     { return newObject().set("$ref", newString(ref)) }
+
+// set appends a member to the object v and returns v.
+func (v *value) set(name string, x *value) *value {
+	v.obj = append(v.obj, member{name, x})
+	return v
+}
+
+// implements reports whether t or a pointer to t implements any of ifaceTypes.
+func implements(t reflect.Type, ifaceTypes ...reflect.Type) bool {
+	for _, it := range ifaceTypes {
+		if t.Implements(it) || (t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(it)) {
+			return true
+		}
+	}
+	return false
+}
+
+// schema returns the schema for the Go type t with the options of
+// the struct field f, if any.
+func (g *generator) schema(t reflect.Type, f field) (*value, error) {
+	// Handle types with custom or special representations.
+	switch {
+	case t == jsontextValueType:
+		return newObject(), nil
+	case t == timeTimeType:
+		switch f.format {
+		case "", "RFC3339", "RFC3339Nano":
+			return newType("string").set("format", newString("date-time")), nil
+		case "DateOnly":
+			return newType("string").set("format", newString("date")), nil
+		case "unix", "unixmilli", "unixmicro", "unixnano":
+			return newType("number"), nil
+		default:
+			return newType("string"), nil
+		}
+	case t == timeDurationType:
+		switch f.format {
+		case "sec", "milli", "micro", "nano":
+			return newType("number"), nil
+		default:
+			return newType("string"), nil
+		}
+	case t.Kind() != reflect.Pointer && implements(t, jsonMarshalerToType, jsonMarshalerType):
+		return newObject(), nil
+	case t.Kind() != reflect.Pointer && implements(t, textAppenderType, textMarshalerType):
+		return newType("string"), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return newType("boolean"), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f.string {
+			return newType("string"), nil
+		}
+		return newType("integer"), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f.string {
+			return newType("string"), nil
+		}
+		return newType("integer").set("minimum", newNumber(0)), nil
+	case reflect.Float32, reflect.Float64:
+		if f.format == "nonfinite" {
+			nonfinite := newObject().set("enum", newArray(newString("NaN"), newString("Infinity"), newString("-Infinity")))
+			return newObject().set("anyOf", newArray(newType("number"), nonfinite)), nil
+		}
+		if f.string {
+			return newType("string"), nil
+		}
+		return newType("number"), nil
+	case reflect.String:
+		return newType("string"), nil
+	case reflect.Interface:
+		return newObject(), nil
+	case reflect.Pointer:
+		s, err := g.schema(t.Elem(), f)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	case reflect.Slice, reflect.Array:
+		var s *value
+		if t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), jsonMarshalerToType, jsonMarshalerType, textAppenderType, textMarshalerType) && f.format != "array" {
+			s = newType("string")
+			switch f.format {
+			case "", "base64":
+				s.set("contentEncoding", newString("base64"))
+			case "base64url", "base32", "base32hex":
+				s.set("contentEncoding", newString(f.format))
+			case "base16", "hex":
+				s.set("contentEncoding", newString("base16"))
+			}
+			if t.Kind() == reflect.Array || f.format != "emitnull" {
+				return s, nil
+			}
+			return nullable(s), nil
+		}
+		items, err := g.schema(t.Elem(), field{})
+		if err != nil {
+			return nil, err
+		}
+		s = newType("array").set("items", items)
+		if t.Kind() == reflect.Array {
+			s.set("minItems", newNumber(t.Len())).set("maxItems", newNumber(t.Len()))
+		} else if f.format == "emitnull" {
+			s = nullable(s)
+		}
+		return s, nil
+	case reflect.Map:
+		items, err := g.schema(t.Elem(), field{})
+		if err != nil {
+			return nil, err
+		}
+		s := newType("object").set("additionalProperties", items)
+		if f.format == "emitnull" {
+			s = nullable(s)
+		}
+		return s, nil
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	return nil, fmt.Errorf("%scannot generate schema for Go type %v: %w", errorPrefix, t, errUnsupportedGoType)
+}
+
+// structSchema returns the schema for the Go struct type t,
+// which is a reference to "$defs" if t is a named type.
+func (g *generator) structSchema(t reflect.Type) (*value, error) {
+	switch {
+	case t == g.root && g.generated:
+		return newRef("#"), nil
+	case t == g.root:
+		g.generated = true
+		return g.structBody(t)
+	case t.Name() == "":
+		for _, vt := range g.visiting {
+			if vt == t {
+				return nil, fmt.Errorf("%scannot generate schema for recursive unnamed Go type %v", errorPrefix, t)
+			}
+		}
+		g.visiting = append(g.visiting, t)
+		defer func() { g.visiting = g.visiting[:len(g.visiting)-1] }()
+		return g.structBody(t)
+	}
+
+	name, ok := g.names[t]
+	if !ok {
+		name = defName(t.Name())
+		for i := 2; g.used[name]; i++ {
+			name = defName(t.Name()) + "_" + strconv.Itoa(i)
+		}
+		g.names[t] = name
+		g.used[name] = true
+		i := len(g.defs)
+		g.defs = append(g.defs, member{name, nil}) // reserve the position
+		s, err := g.structBody(t)
+		if err != nil {
+			return nil, err
+		}
+		g.defs[i].val = s
+	}
+	return newRef("#/$defs/" + name), nil
+}
+
+// structBody returns the schema for the members of the Go struct type t.
+func (g *generator) structBody(t reflect.Type) (*value, error) {
+	fields, fallback, err := structFields(t)
+	if err != nil {
+		return nil, fmt.Errorf("%scannot generate schema for Go type %v: %w", errorPrefix, t, err)
+	}
+	s := newType("object")
+	props := newObject()
+	var required []*value
+	for _, f := range fields {
+		fs, err := g.schema(f.typ, f)
+		if err != nil {
+			return nil, err
+		}
+		props.set(f.name, fs)
+		if !f.omitzero && !f.omitempty {
+			required = append(required, newString(f.name))
+		}
+	}
+	if len(props.obj) > 0 {
+		s.set("properties", props)
+	}
+	if len(required) > 0 {
+		s.set("required", newArray(required...))
+	}
+	if fallback != nil && fallback.Kind() == reflect.Map {
+		items, err := g.schema(fallback.Elem(), field{})
+		if err != nil {
+			return nil, err
+		}
+		s.set("additionalProperties", items)
+	}
+	return s, nil
+}
+
+// nullable returns a schema that additionally permits null.
+func nullable(s *value) *value {
+	if len(s.obj) == 0 {
+		return s // already permits any value
+	}
+	for i, m := range s.obj {
+		if m.name == "type" && m.val.kind == '"' {
+			s.obj[i].val = newArray(m.val, newString("null"))
+			return s
+		}
+	}
+	return newObject().set("anyOf", newArray(s, newType("null")))
+}
+
+// defName converts a Go type name into a name for "$defs",
+// replacing characters that are awkward in a URI fragment.
+func defName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, name)
+}
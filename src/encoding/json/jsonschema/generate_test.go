@@ -0,0 +1,240 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"encoding/json/internal/jsontest"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+)
+
+type (
+	genNode struct {
+		Value    int        `json:"value"`
+		Children []*genNode `json:"children,omitempty"`
+	}
+	genTree struct {
+		Root  *genNode `json:"root"`
+		Left  genLeaf  `json:"left"`
+		Right genLeaf  `json:"right"`
+	}
+	genLeaf struct {
+		Name string `json:"name"`
+	}
+	genEmbedded struct {
+		genLeaf
+		Extra  map[string]int `json:",inline"`
+		Hidden string         `json:"-"`
+	}
+	genOptions struct {
+		Count   int64         `json:"count,string"`
+		Ratio   float64       `json:"ratio,format:nonfinite"`
+		Data    []byte        `json:"data,format:hex"`
+		Raw     []byte        `json:"raw,omitzero"`
+		When    time.Time     `json:"when"`
+		Day     time.Time     `json:"day,format:DateOnly"`
+		Unix    time.Time     `json:"unix,format:unix"`
+		Wait    time.Duration `json:"wait"`
+		Seconds time.Duration `json:"seconds,format:sec"`
+		Addr    netip.Addr    `json:"addr"`
+		Any     any           `json:"any"`
+		Value   jsontext.Value
+		Ptr     *string   `json:"ptr"`
+		Fixed   [2]uint8  `json:"fixed,format:array"`
+		Matrix  [][2]bool `json:"matrix,format:emitnull"`
+	}
+	genSelf struct {
+		Self *genSelf `json:"self"`
+	}
+	genFieldsInner struct {
+		A, B, Z int
+		C       int `json:"c"`
+	}
+	genFieldsOther struct {
+		Z int
+	}
+	genFields struct {
+		genFieldsInner
+		*genFieldsOther
+		B      string `json:"'b,quoted'"`
+		A      int
+		Inline struct {
+			X int
+			Y int `json:"c"`
+		} `json:",inline"`
+		Dash   int `json:"'-'"`
+		Skip   int `json:"-"`
+		hidden int
+	}
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name jsontest.CaseName
+		in   reflect.Type
+		want string
+	}{{
+		name: jsontest.Name("Bool"),
+		in:   reflect.TypeFor[bool](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"boolean"}`,
+	}, {
+		name: jsontest.Name("Uint"),
+		in:   reflect.TypeFor[uint16](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"integer","minimum":0}`,
+	}, {
+		name: jsontest.Name("Map"),
+		in:   reflect.TypeFor[map[string][]float32](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","additionalProperties":{"type":"array","items":{"type":"number"}}}`,
+	}, {
+		name: jsontest.Name("Bytes"),
+		in:   reflect.TypeFor[[]byte](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string","contentEncoding":"base64"}`,
+	}, {
+		name: jsontest.Name("Pointer"),
+		in:   reflect.TypeFor[*string](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":["string","null"]}`,
+	}, {
+		name: jsontest.Name("Recursive"),
+		in:   reflect.TypeFor[genNode](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"value":{"type":"integer"},"children":{"type":"array","items":{"anyOf":[{"$ref":"#"},{"type":"null"}]}}},"required":["value"]}`,
+	}, {
+		name: jsontest.Name("Defs"),
+		in:   reflect.TypeFor[genTree](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"root":{"anyOf":[{"$ref":"#/$defs/genNode"},{"type":"null"}]},"left":{"$ref":"#/$defs/genLeaf"},"right":{"$ref":"#/$defs/genLeaf"}},"required":["root","left","right"],"$defs":{"genNode":{"type":"object","properties":{"value":{"type":"integer"},"children":{"type":"array","items":{"anyOf":[{"$ref":"#/$defs/genNode"},{"type":"null"}]}}},"required":["value"]},"genLeaf":{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}}}`,
+	}, {
+		name: jsontest.Name("Embedded"),
+		in:   reflect.TypeFor[genEmbedded](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"name":{"type":"string"}},"required":["name"],"additionalProperties":{"type":"integer"}}`,
+	}, {
+		name: jsontest.Name("Options"),
+		in:   reflect.TypeFor[genOptions](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+			`"count":{"type":"string"},` +
+			`"ratio":{"anyOf":[{"type":"number"},{"enum":["NaN","Infinity","-Infinity"]}]},` +
+			`"data":{"type":"string","contentEncoding":"base16"},` +
+			`"raw":{"type":"string","contentEncoding":"base64"},` +
+			`"when":{"type":"string","format":"date-time"},` +
+			`"day":{"type":"string","format":"date"},` +
+			`"unix":{"type":"number"},` +
+			`"wait":{"type":"string"},` +
+			`"seconds":{"type":"number"},` +
+			`"addr":{"type":"string"},` +
+			`"any":{},` +
+			`"Value":{},` +
+			`"ptr":{"type":["string","null"]},` +
+			`"fixed":{"type":"array","items":{"type":"integer","minimum":0},"minItems":2,"maxItems":2},` +
+			`"matrix":{"type":["array","null"],"items":{"type":"array","items":{"type":"boolean"},"minItems":2,"maxItems":2}}},` +
+			`"required":["count","ratio","data","when","day","unix","wait","seconds","addr","any","Value","ptr","fixed","matrix"]}`,
+	}, {
+		name: jsontest.Name("PointerRoot"),
+		in:   reflect.TypeFor[*genSelf](),
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":["object","null"],"properties":{"self":{"anyOf":[{"$ref":"#"},{"type":"null"}]}},"required":["self"]}`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			got, err := Generate(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Generate error: %v", tt.name.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Generate:\ngot  %s\nwant %s", tt.name.Where, got, tt.want)
+			}
+			if _, err := Compile(got); err != nil {
+				t.Errorf("%s: Compile error: %v", tt.name.Where, err)
+			}
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	type badInline struct {
+		A int `json:",inline"`
+	}
+	for _, in := range []reflect.Type{
+		reflect.TypeFor[chan int](),
+		reflect.TypeFor[[]func()](),
+		reflect.TypeFor[struct{ C complex128 }](),
+		reflect.TypeFor[badInline](),
+	} {
+		if _, err := Generate(in); err == nil {
+			t.Errorf("Generate(%v) succeeded unexpectedly", in)
+		}
+	}
+	if _, err := Generate(reflect.TypeFor[chan int]()); !errors.Is(err, errUnsupportedGoType) {
+		t.Errorf("Generate error = %v, want %v", err, errUnsupportedGoType)
+	}
+}
+
+// TestStructFields checks that the members described for Go struct types
+// are those that json.Marshal produces.
+func TestStructFields(t *testing.T) {
+	for _, in := range []any{
+		genFields{genFieldsOther: &genFieldsOther{}},
+		genEmbedded{},
+		genOptions{},
+	} {
+		fields, _, err := structFields(reflect.TypeOf(in))
+		if err != nil {
+			t.Fatalf("structFields(%T) error: %v", in, err)
+		}
+		var got []string
+		for _, f := range fields {
+			if !f.omitzero && !f.omitempty {
+				got = append(got, f.name)
+			}
+		}
+		b, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("json.Marshal(%T) error: %v", in, err)
+		}
+		var want []string
+		d := jsontext.NewDecoder(bytes.NewReader(b))
+		d.ReadToken()
+		for d.PeekKind() == '"' {
+			tok, _ := d.ReadToken()
+			want = append(want, tok.String())
+			d.SkipValue()
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("structFields(%T) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestGenerateValidate checks that marshaled Go values are valid
+// according to the schema generated for their type.
+func TestGenerateValidate(t *testing.T) {
+	str := "s"
+	for _, in := range []any{
+		genTree{Root: &genNode{Value: 1, Children: []*genNode{{Value: 2}, nil}}},
+		genEmbedded{genLeaf{"x"}, map[string]int{"a": 1}, "hidden"},
+		genOptions{Ptr: &str, Data: []byte("hi"), Matrix: [][2]bool{{true, false}}, When: time.Now(), Addr: netip.MustParseAddr("::1")},
+		&genSelf{Self: &genSelf{}},
+		map[string][]float32{"a": nil, "b": {1.5}},
+	} {
+		b, err := Generate(reflect.TypeOf(in))
+		if err != nil {
+			t.Fatalf("Generate(%T) error: %v", in, err)
+		}
+		s, err := Compile(b)
+		if err != nil {
+			t.Fatalf("Compile(%T) error: %v", in, err)
+		}
+		if err := s.ValidateGo(in); err != nil {
+			t.Errorf("ValidateGo(%T) error: %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,715 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"encoding/json/jsontext"
+)
+
+// dialect is the URI of the only supported meta-schema.
+const dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a compiled JSON Schema.
+// It is safe for concurrent use by multiple goroutines.
+type Schema struct {
+	uri  string           // URI of the enclosing schema resource
+	ptr  jsontext.Pointer // location relative to the enclosing schema resource
+	root *Schema          // root of the enclosing schema resource
+
+	// dynamicAnchors are the schemas identified by a "$dynamicAnchor"
+	// within this schema resource. It is only populated for root schemas.
+	dynamicAnchors map[string]*Schema
+
+	boolean *bool // non-nil for the true and false schemas
+
+	// Applicators.
+	ref                   *Schema
+	dynamicRef            *dynamicRef
+	allOf                 []*Schema
+	anyOf                 []*Schema
+	oneOf                 []*Schema
+	not                   *Schema
+	ifSchema              *Schema
+	thenSchema            *Schema
+	elseSchema            *Schema
+	dependentSchemas      []namedSchema
+	prefixItems           []*Schema
+	items                 *Schema
+	contains              *Schema
+	properties            []namedSchema
+	patternProperties     []patternSchema
+	additionalProperties  *Schema
+	propertyNames         *Schema
+	unevaluatedItems      *Schema
+	unevaluatedProperties *Schema
+
+	// Assertions.
+	types             []string
+	enum              []*value
+	constant          *value
+	multipleOf        *number
+	maximum           *number
+	exclusiveMaximum  *number
+	minimum           *number
+	exclusiveMinimum  *number
+	maxLength         int // -1 if absent
+	minLength         int // -1 if absent
+	pattern           *regexp.Regexp
+	maxItems          int // -1 if absent
+	minItems          int // -1 if absent
+	uniqueItems       bool
+	maxContains       int // -1 if absent
+	minContains       int // -1 if absent
+	maxProperties     int // -1 if absent
+	minProperties     int // -1 if absent
+	required          []string
+	dependentRequired []namedStrings
+	format            string
+	checkFormat       func(string) bool // nil unless format is asserted
+}
+
+type namedSchema struct {
+	name   string
+	schema *Schema
+}
+
+type patternSchema struct {
+	re     *regexp.Regexp
+	schema *Schema
+}
+
+type namedStrings struct {
+	name    string
+	strings []string
+}
+
+// dynamicRef is a compiled "$dynamicRef" keyword.
+type dynamicRef struct {
+	schema *Schema // statically resolved target
+	anchor string  // name of the dynamic anchor; empty if resolved statically
+}
+
+// location returns the absolute location of the named keyword in s.
+func (s *Schema) location(keyword ...string) string {
+	p := s.ptr
+	for _, kw := range keyword {
+		p = p.AppendToken(kw)
+	}
+	return s.uri + "#" + (&url.URL{Fragment: string(p)}).EscapedFragment()
+}
+
+// A Compiler compiles schema documents into [Schema] values and
+// resolves references between schema documents.
+// The zero value is ready for use.
+// A Compiler must not be used concurrently,
+// but the schemas that it produces may be.
+type Compiler struct {
+	// AssertFormat specifies that the "format" keyword is an assertion
+	// rather than only an annotation. Only the following formats
+	// are checked, while others are always accepted:
+	// "date", "date-time", "duration", "email", "hostname", "ipv4", "ipv6",
+	// "json-pointer", "regex", "relative-json-pointer", "time", "uri",
+	// "uri-reference", and "uuid".
+	AssertFormat bool
+
+	resources map[string]*resource // keyed by absolute URI without fragment
+}
+
+// document is a parsed schema document.
+type document struct {
+	root *value
+	// bases maps the location of every known subschema
+	// to its enclosing schema resource.
+	bases map[jsontext.Pointer]*resource
+}
+
+// resource is a schema resource, which is a schema with a base URI.
+type resource struct {
+	uri            string
+	doc            *document
+	ptr            jsontext.Pointer            // location within doc
+	anchors        map[string]jsontext.Pointer // locations of named anchors
+	dynamicAnchors map[string]jsontext.Pointer // locations of dynamic anchors
+}
+
+// AddResource registers a schema document so that it may be referenced
+// by the schemas that are subsequently compiled by c.
+// The uri must be absolute and identifies the document
+// unless the document declares its own "$id".
+// Any schema resources embedded within the document
+// with their own "$id" are registered as well.
+func (c *Compiler) AddResource(uri string, schema jsontext.Value) error {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Fragment != "" {
+		return &SchemaError{Location: uri, Err: errors.New("resource URI must be absolute without a fragment")}
+	}
+	u.RawFragment = ""
+	uri = u.String()
+	v, err := parseValue(schema)
+	if err != nil {
+		return &SchemaError{Location: uri, Err: err}
+	}
+	if c.resources == nil {
+		c.resources = make(map[string]*resource)
+	}
+	// Register the resources into a temporary map so that
+	// c is unmodified if the document is invalid.
+	resources := make(map[string]*resource)
+	lookup := func(uri string) *resource { return cmp.Or(resources[uri], c.resources[uri]) }
+	if err := addDocument(uri, v, resources, lookup); err != nil {
+		return err
+	}
+	for uri, r := range resources {
+		c.resources[uri] = r
+	}
+	return nil
+}
+
+// Compile compiles a schema document.
+// Relative references within the document are resolved against its "$id"
+// or are otherwise relative to an unnamed document.
+// References to other documents must have been registered with
+// [Compiler.AddResource]; they are never retrieved from the network.
+func (c *Compiler) Compile(schema jsontext.Value) (*Schema, error) {
+	v, err := parseValue(schema)
+	if err != nil {
+		return nil, &SchemaError{Err: err}
+	}
+	cs := &compileState{c: c, resources: make(map[string]*resource), schemas: make(map[schemaKey]*Schema)}
+	if err := addDocument("", v, cs.resources, cs.lookup); err != nil {
+		return nil, err
+	}
+	doc := cs.resources[""].doc
+	return cs.compile(doc, "")
+}
+
+// Compile compiles a schema document that does not reference other documents.
+// It is equivalent to calling [Compiler.Compile] on a new [Compiler].
+func Compile(schema jsontext.Value) (*Schema, error) {
+	return new(Compiler).Compile(schema)
+}
+
+// MustCompile is like [Compile] but panics if the schema cannot be compiled.
+// It simplifies safe initialization of global variables holding schemas.
+func MustCompile(schema jsontext.Value) *Schema {
+	s, err := Compile(schema)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// addDocument scans the schema document v identified by uri
+// and registers all of its schema resources in resources.
+func addDocument(uri string, v *value, resources map[string]*resource, lookup func(string) *resource) error {
+	doc := &document{root: v, bases: make(map[jsontext.Pointer]*resource)}
+	r := &resource{uri: uri, doc: doc}
+	if lookup(uri) != nil {
+		return &SchemaError{Location: uri, Err: errors.New("duplicate resource URI")}
+	}
+	resources[uri] = r
+	return scan(r, "", v, resources, lookup)
+}
+
+// scan records the schema resource and anchors of the subschema v
+// at location ptr within r.doc, and then recursively scans its subschemas.
+func scan(r *resource, ptr jsontext.Pointer, v *value, resources map[string]*resource, lookup func(string) *resource) error {
+	if v.kind != '{' {
+		r.doc.bases[ptr] = r // non-boolean values are reported when compiled
+		return nil
+	}
+	errorf := func(kw, f string, a ...any) error {
+		s := &Schema{uri: r.uri, ptr: ptr[len(r.ptr):]}
+		return &SchemaError{Location: s.location(kw), Err: fmt.Errorf(f, a...)}
+	}
+
+	if id := v.get("$id"); id != nil {
+		if id.kind != '"' {
+			return errorf("$id", "must be a string")
+		}
+		uri, frag, err := resolveURI(r.uri, id.str)
+		if err != nil {
+			return errorf("$id", "%v", err)
+		}
+		if frag != "" {
+			return errorf("$id", "must not contain a non-empty fragment")
+		}
+		if uri != r.uri || ptr != r.ptr {
+			if lookup(uri) != nil {
+				return errorf("$id", "duplicate resource URI %q", uri)
+			}
+			r = &resource{uri: uri, doc: r.doc, ptr: ptr}
+			resources[uri] = r
+		}
+	}
+	r.doc.bases[ptr] = r
+
+	for _, kw := range []string{"$anchor", "$dynamicAnchor"} {
+		a := v.get(kw)
+		if a == nil {
+			continue
+		}
+		if a.kind != '"' || !isAnchorName(a.str) {
+			return errorf(kw, "must be a string that is a valid anchor name")
+		}
+		if p, ok := r.anchors[a.str]; ok && p != ptr {
+			return errorf(kw, "duplicate anchor %q", a.str)
+		}
+		if r.anchors == nil {
+			r.anchors = make(map[string]jsontext.Pointer)
+		}
+		r.anchors[a.str] = ptr
+		if kw == "$dynamicAnchor" {
+			if r.dynamicAnchors == nil {
+				r.dynamicAnchors = make(map[string]jsontext.Pointer)
+			}
+			r.dynamicAnchors[a.str] = ptr
+		}
+	}
+
+	for _, m := range v.obj {
+		kp := ptr.AppendToken(m.name)
+		switch m.name {
+		case "additionalProperties", "contains", "contentSchema", "else", "if", "items",
+			"not", "propertyNames", "then", "unevaluatedItems", "unevaluatedProperties":
+			if err := scan(r, kp, m.val, resources, lookup); err != nil {
+				return err
+			}
+		case "allOf", "anyOf", "oneOf", "prefixItems":
+			for i, e := range m.val.arr {
+				if err := scan(r, kp.AppendToken(fmt.Sprint(i)), e, resources, lookup); err != nil {
+					return err
+				}
+			}
+		case "$defs", "dependentSchemas", "patternProperties", "properties":
+			for _, mm := range m.val.obj {
+				if err := scan(r, kp.AppendToken(mm.name), mm.val, resources, lookup); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isAnchorName reports whether s matches ^[A-Za-z_][-A-Za-z0-9._]*$.
+func isAnchorName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', c == '_':
+		case i > 0 && ('0' <= c && c <= '9' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// resolveURI resolves ref against base and
+// splits the result into an absolute URI and a decoded fragment.
+func resolveURI(base, ref string) (uri, fragment string, err error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", "", err
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", "", err
+	}
+	u := b.ResolveReference(r)
+	if b.Opaque != "" && r.Scheme == "" && r.Host == "" && r.Path == "" && r.Opaque == "" {
+		// ResolveReference does not handle fragment-only references
+		// against an opaque base URI such as "urn:example:schema".
+		u = &url.URL{Scheme: b.Scheme, Opaque: b.Opaque, RawQuery: cmp.Or(r.RawQuery, b.RawQuery), Fragment: r.Fragment}
+	}
+	fragment = u.Fragment
+	u.Fragment, u.RawFragment = "", ""
+	return u.String(), fragment, nil
+}
+
+type schemaKey struct {
+	doc *document
+	ptr jsontext.Pointer
+}
+
+// compileState is the state of a single call to [Compiler.Compile].
+type compileState struct {
+	c         *Compiler
+	resources map[string]*resource // resources of the compiled document
+	schemas   map[schemaKey]*Schema
+}
+
+func (cs *compileState) lookup(uri string) *resource {
+	return cmp.Or(cs.resources[uri], cs.c.resources[uri])
+}
+
+// resolve resolves a reference found within the resource identified by base
+// and returns the referenced document and location.
+func (cs *compileState) resolve(base, ref string) (*document, jsontext.Pointer, error) {
+	uri, frag, err := resolveURI(base, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	r := cs.lookup(uri)
+	if r == nil {
+		return nil, "", fmt.Errorf("unresolved reference to %q", uri)
+	}
+	switch {
+	case frag == "":
+		return r.doc, r.ptr, nil
+	case strings.HasPrefix(frag, "/"):
+		ptr := r.ptr + jsontext.Pointer(frag)
+		if !ptr.IsValid() || r.doc.root.at(ptr) == nil {
+			return nil, "", fmt.Errorf("unresolved reference to %q", uri+"#"+frag)
+		}
+		return r.doc, ptr, nil
+	default:
+		ptr, ok := r.anchors[frag]
+		if !ok {
+			return nil, "", fmt.Errorf("unresolved reference to anchor %q in %q", frag, uri)
+		}
+		return r.doc, ptr, nil
+	}
+}
+
+// resourceAt returns the schema resource enclosing the subschema at ptr.
+func (cs *compileState) resourceAt(doc *document, ptr jsontext.Pointer) (*resource, error) {
+	if r := doc.bases[ptr]; r != nil {
+		return r, nil
+	}
+	// The location is not a known subschema (e.g., it is referenced
+	// by a JSON Pointer into an unknown keyword), so scan it now.
+	p := ptr
+	for doc.bases[p] == nil && p != "" {
+		p = p.Parent()
+	}
+	r := doc.bases[p]
+	if r == nil {
+		return nil, fmt.Errorf("no schema resource encloses %q", ptr)
+	}
+	if err := scan(r, ptr, doc.root.at(ptr), cs.resources, cs.lookup); err != nil {
+		return nil, err
+	}
+	return cmp.Or(doc.bases[ptr], r), nil
+}
+
+// compile compiles the subschema at location ptr within doc.
+func (cs *compileState) compile(doc *document, ptr jsontext.Pointer) (*Schema, error) {
+	key := schemaKey{doc, ptr}
+	if s := cs.schemas[key]; s != nil {
+		return s, nil
+	}
+	r, err := cs.resourceAt(doc, ptr)
+	if err != nil {
+		return nil, &SchemaError{Err: err}
+	}
+	s := &Schema{
+		uri:           r.uri,
+		ptr:           ptr[len(r.ptr):],
+		maxLength:     -1,
+		minLength:     -1,
+		maxItems:      -1,
+		minItems:      -1,
+		maxContains:   -1,
+		minContains:   -1,
+		maxProperties: -1,
+		minProperties: -1,
+	}
+	cs.schemas[key] = s
+
+	// Compile the root of the enclosing resource so that
+	// its dynamic anchors are known during validation.
+	if ptr == r.ptr {
+		s.root = s
+		for name, p := range r.dynamicAnchors {
+			a, err := cs.compile(doc, p)
+			if err != nil {
+				return nil, err
+			}
+			if s.dynamicAnchors == nil {
+				s.dynamicAnchors = make(map[string]*Schema)
+			}
+			s.dynamicAnchors[name] = a
+		}
+	} else {
+		if s.root, err = cs.compile(doc, r.ptr); err != nil {
+			return nil, err
+		}
+	}
+
+	v := doc.root.at(ptr)
+	switch v.kind {
+	case 't', 'f':
+		b := v.kind == 't'
+		s.boolean = &b
+		return s, nil
+	case '{':
+	default:
+		return nil, &SchemaError{Location: s.location(), Err: errors.New("schema must be a JSON object or boolean")}
+	}
+	for _, m := range v.obj {
+		if err := cs.compileKeyword(s, doc, ptr.AppendToken(m.name), m.name, m.val); err != nil {
+			var serr *SchemaError
+			if !errors.As(err, &serr) {
+				err = &SchemaError{Location: s.location(m.name), Err: err}
+			}
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// compileKeyword compiles a single keyword of s located at ptr within doc.
+func (cs *compileState) compileKeyword(s *Schema, doc *document, ptr jsontext.Pointer, kw string, v *value) (err error) {
+	// Helper functions to compile subschemas.
+	sub := func(ptr jsontext.Pointer) (*Schema, error) { return cs.compile(doc, ptr) }
+	subArray := func() ([]*Schema, error) {
+		if v.kind != '[' || len(v.arr) == 0 {
+			return nil, errors.New("must be a non-empty array of schemas")
+		}
+		var ss []*Schema
+		for i := range v.arr {
+			sc, err := sub(ptr.AppendToken(fmt.Sprint(i)))
+			if err != nil {
+				return nil, err
+			}
+			ss = append(ss, sc)
+		}
+		return ss, nil
+	}
+	subObject := func() ([]namedSchema, error) {
+		if v.kind != '{' {
+			return nil, errors.New("must be an object of schemas")
+		}
+		var ss []namedSchema
+		for _, m := range v.obj {
+			sc, err := sub(ptr.AppendToken(m.name))
+			if err != nil {
+				return nil, err
+			}
+			ss = append(ss, namedSchema{m.name, sc})
+		}
+		return ss, nil
+	}
+	num := func() (*number, error) {
+		if v.kind != '0' {
+			return nil, errors.New("must be a number")
+		}
+		n := makeNumber(v.str)
+		return &n, nil
+	}
+	count := func() (int, error) {
+		if v.kind == '0' {
+			if n := makeNumber(v.str); n.isInteger() && n.f >= 0 {
+				return int(min(n.f, math.MaxInt32)), nil
+			}
+		}
+		return 0, errors.New("must be a non-negative integer")
+	}
+	uniqueStrings := func(v *value) ([]string, error) {
+		if v.kind == '[' {
+			var ss []string
+			for _, e := range v.arr {
+				if e.kind != '"' || slices.Contains(ss, e.str) {
+					ss = nil
+					break
+				}
+				ss = append(ss, e.str)
+			}
+			if len(ss) == len(v.arr) {
+				return ss, nil
+			}
+		}
+		return nil, errors.New("must be an array of unique strings")
+	}
+
+	switch kw {
+	// Core vocabulary.
+	case "$schema":
+		if v.kind != '"' || strings.TrimSuffix(v.str, "#") != dialect {
+			return fmt.Errorf("unsupported dialect %s; only %q is supported", v, dialect)
+		}
+	case "$ref":
+		if v.kind != '"' {
+			return errors.New("must be a string")
+		}
+		d, p, err := cs.resolve(s.uri, v.str)
+		if err != nil {
+			return err
+		}
+		if s.ref, err = cs.compile(d, p); err != nil {
+			return err
+		}
+	case "$dynamicRef":
+		if v.kind != '"' {
+			return errors.New("must be a string")
+		}
+		d, p, err := cs.resolve(s.uri, v.str)
+		if err != nil {
+			return err
+		}
+		s.dynamicRef = new(dynamicRef)
+		if s.dynamicRef.schema, err = cs.compile(d, p); err != nil {
+			return err
+		}
+		// Resolve dynamically only if the fragment is a plain name and
+		// the statically resolved target declares it as a dynamic anchor.
+		if _, frag, _ := resolveURI(s.uri, v.str); frag != "" && !strings.HasPrefix(frag, "/") {
+			if a := d.root.at(p).get("$dynamicAnchor"); a != nil && a.str == frag {
+				s.dynamicRef.anchor = frag
+			}
+		}
+	case "$defs":
+		_, err = subObject()
+
+	// Applicator vocabulary.
+	case "allOf":
+		s.allOf, err = subArray()
+	case "anyOf":
+		s.anyOf, err = subArray()
+	case "oneOf":
+		s.oneOf, err = subArray()
+	case "not":
+		s.not, err = sub(ptr)
+	case "if":
+		s.ifSchema, err = sub(ptr)
+	case "then":
+		s.thenSchema, err = sub(ptr)
+	case "else":
+		s.elseSchema, err = sub(ptr)
+	case "dependentSchemas":
+		s.dependentSchemas, err = subObject()
+	case "prefixItems":
+		s.prefixItems, err = subArray()
+	case "items":
+		s.items, err = sub(ptr)
+	case "contains":
+		s.contains, err = sub(ptr)
+	case "properties":
+		s.properties, err = subObject()
+	case "patternProperties":
+		var ss []namedSchema
+		if ss, err = subObject(); err != nil {
+			return err
+		}
+		for _, ns := range ss {
+			re, err := regexp.Compile(ns.name)
+			if err != nil {
+				return err
+			}
+			s.patternProperties = append(s.patternProperties, patternSchema{re, ns.schema})
+		}
+	case "additionalProperties":
+		s.additionalProperties, err = sub(ptr)
+	case "propertyNames":
+		s.propertyNames, err = sub(ptr)
+
+	// Unevaluated vocabulary.
+	case "unevaluatedItems":
+		s.unevaluatedItems, err = sub(ptr)
+	case "unevaluatedProperties":
+		s.unevaluatedProperties, err = sub(ptr)
+
+	// Validation vocabulary.
+	case "type":
+		var types []string
+		switch v.kind {
+		case '"':
+			types = []string{v.str}
+		case '[':
+			if types, err = uniqueStrings(v); err != nil {
+				return err
+			}
+		default:
+			return errors.New("must be a string or an array of strings")
+		}
+		for _, t := range types {
+			if !slices.Contains([]string{"null", "boolean", "object", "array", "number", "string", "integer"}, t) {
+				return fmt.Errorf("unknown type %q", t)
+			}
+		}
+		s.types = types
+	case "enum":
+		if v.kind != '[' {
+			return errors.New("must be an array")
+		}
+		s.enum = v.arr
+	case "const":
+		s.constant = v
+	case "multipleOf":
+		if s.multipleOf, err = num(); err == nil && s.multipleOf.cmp(makeNumber("0")) <= 0 {
+			return errors.New("must be a number greater than zero")
+		}
+	case "maximum":
+		s.maximum, err = num()
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = num()
+	case "minimum":
+		s.minimum, err = num()
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = num()
+	case "maxLength":
+		s.maxLength, err = count()
+	case "minLength":
+		s.minLength, err = count()
+	case "pattern":
+		if v.kind != '"' {
+			return errors.New("must be a string")
+		}
+		s.pattern, err = regexp.Compile(v.str)
+	case "maxItems":
+		s.maxItems, err = count()
+	case "minItems":
+		s.minItems, err = count()
+	case "uniqueItems":
+		if v.kind != 't' && v.kind != 'f' {
+			return errors.New("must be a boolean")
+		}
+		s.uniqueItems = v.kind == 't'
+	case "maxContains":
+		s.maxContains, err = count()
+	case "minContains":
+		s.minContains, err = count()
+	case "maxProperties":
+		s.maxProperties, err = count()
+	case "minProperties":
+		s.minProperties, err = count()
+	case "required":
+		s.required, err = uniqueStrings(v)
+	case "dependentRequired":
+		if v.kind != '{' {
+			return errors.New("must be an object of arrays of unique strings")
+		}
+		for _, m := range v.obj {
+			ss, err := uniqueStrings(m.val)
+			if err != nil {
+				return &SchemaError{Location: s.location(kw, m.name), Err: err}
+			}
+			s.dependentRequired = append(s.dependentRequired, namedStrings{m.name, ss})
+		}
+
+	// Format vocabulary.
+	case "format":
+		if v.kind != '"' {
+			return errors.New("must be a string")
+		}
+		s.format = v.str
+		if cs.c.AssertFormat {
+			s.checkFormat = formats[v.str]
+		}
+	}
+	return err
+}
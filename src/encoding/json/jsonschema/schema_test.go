@@ -0,0 +1,185 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"encoding/json/internal/jsontest"
+	"encoding/json/jsontext"
+)
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    jsontest.CaseName
+		schema  string
+		wantLoc string // want SchemaError.Location
+		wantErr string // want substring of SchemaError.Err
+	}{
+		{name: jsontest.Name("InvalidJSON"), schema: `{"type": }`, wantErr: "missing value"},
+		{name: jsontest.Name("TrailingData"), schema: `{} {}`, wantErr: "unexpected data"},
+		{name: jsontest.Name("NotSchema"), schema: `1`, wantLoc: "#", wantErr: "must be a JSON object or boolean"},
+		{name: jsontest.Name("NotSubschema"), schema: `{"properties": {"a": "b"}}`, wantLoc: "#/properties/a", wantErr: "must be a JSON object or boolean"},
+		{name: jsontest.Name("Type"), schema: `{"type": "int"}`, wantLoc: "#/type", wantErr: `unknown type "int"`},
+		{name: jsontest.Name("TypeDuplicate"), schema: `{"type": ["null", "null"]}`, wantLoc: "#/type", wantErr: "unique strings"},
+		{name: jsontest.Name("AllOfEmpty"), schema: `{"allOf": []}`, wantLoc: "#/allOf", wantErr: "non-empty array"},
+		{name: jsontest.Name("MultipleOfZero"), schema: `{"multipleOf": 0}`, wantLoc: "#/multipleOf", wantErr: "greater than zero"},
+		{name: jsontest.Name("MaximumString"), schema: `{"maximum": "1"}`, wantLoc: "#/maximum", wantErr: "must be a number"},
+		{name: jsontest.Name("MinLengthNegative"), schema: `{"minLength": -1}`, wantLoc: "#/minLength", wantErr: "non-negative integer"},
+		{name: jsontest.Name("MaxItemsFraction"), schema: `{"maxItems": 1.5}`, wantLoc: "#/maxItems", wantErr: "non-negative integer"},
+		{name: jsontest.Name("Pattern"), schema: `{"pattern": "(?=a)"}`, wantLoc: "#/pattern", wantErr: "invalid or unsupported Perl syntax"},
+		{name: jsontest.Name("PatternProperties"), schema: `{"patternProperties": {"[": true}}`, wantLoc: "#/patternProperties", wantErr: "missing closing ]"},
+		{name: jsontest.Name("Required"), schema: `{"required": ["a", 1]}`, wantLoc: "#/required", wantErr: "unique strings"},
+		{name: jsontest.Name("DependentRequired"), schema: `{"dependentRequired": {"a": ["b", "b"]}}`, wantLoc: "#/dependentRequired/a", wantErr: "unique strings"},
+		{name: jsontest.Name("UniqueItems"), schema: `{"uniqueItems": 1}`, wantLoc: "#/uniqueItems", wantErr: "must be a boolean"},
+		{name: jsontest.Name("Dialect"), schema: `{"$schema": "http://json-schema.org/draft-07/schema#"}`, wantLoc: "#/$schema", wantErr: "unsupported dialect"},
+		{name: jsontest.Name("IDFragment"), schema: `{"$id": "https://example.com/a#b"}`, wantLoc: "#/$id", wantErr: "non-empty fragment"},
+		{name: jsontest.Name("IDDuplicate"), schema: `{"$defs": {"a": {"$id": "urn:x"}, "b": {"$id": "urn:x"}}}`, wantLoc: "#/$defs/b/$id", wantErr: `duplicate resource URI "urn:x"`},
+		{name: jsontest.Name("AnchorInvalid"), schema: `{"$anchor": "1a"}`, wantLoc: "#/$anchor", wantErr: "valid anchor name"},
+		{name: jsontest.Name("AnchorDuplicate"), schema: `{"$defs": {"a": {"$anchor": "x"}, "b": {"$anchor": "x"}}}`, wantLoc: "#/$defs/b/$anchor", wantErr: `duplicate anchor "x"`},
+		{name: jsontest.Name("RefUnresolved"), schema: `{"$ref": "#/$defs/missing"}`, wantLoc: "#/$ref", wantErr: "unresolved reference"},
+		{name: jsontest.Name("RefUnknownDocument"), schema: `{"$ref": "https://example.com/other.json"}`, wantLoc: "#/$ref", wantErr: `unresolved reference to "https://example.com/other.json"`},
+		{name: jsontest.Name("RefUnknownAnchor"), schema: `{"$ref": "#missing"}`, wantLoc: "#/$ref", wantErr: `unresolved reference to anchor "missing"`},
+		{name: jsontest.Name("RefInvalidTarget"), schema: `{"$ref": "#/$defs/a", "$defs": {"a": 5}}`, wantLoc: "#/$defs/a", wantErr: "must be a JSON object or boolean"},
+		{name: jsontest.Name("NestedResource"), schema: `{"$id": "https://example.com/a.json", "items": {"$id": "b.json", "minimum": "0"}}`, wantLoc: "https://example.com/b.json#/minimum", wantErr: "must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			_, err := Compile(jsontext.Value(tt.schema))
+			var serr *SchemaError
+			if !errors.As(err, &serr) {
+				t.Fatalf("%s: Compile error = %v, want SchemaError", tt.name.Where, err)
+			}
+			if serr.Location != tt.wantLoc {
+				t.Errorf("%s: Location = %q, want %q", tt.name.Where, serr.Location, tt.wantLoc)
+			}
+			if serr.Err == nil || !strings.Contains(serr.Err.Error(), tt.wantErr) {
+				t.Errorf("%s: Err = %v, want substring %q", tt.name.Where, serr.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaErrorString(t *testing.T) {
+	_, err := Compile(jsontext.Value(`{"properties": {"a": {"minimum": null}}}`))
+	want := `jsonschema: invalid schema at "#/properties/a/minimum": must be a number`
+	if err == nil || err.Error() != want {
+		t.Errorf("Compile error:\ngot  %v\nwant %s", err, want)
+	}
+}
+
+func TestCompilerResources(t *testing.T) {
+	var c Compiler
+	if err := c.AddResource("https://example.com/defs.json", jsontext.Value(`{
+		"$defs": {
+			"id": {"type": "string", "pattern": "^[a-z]+$"},
+			"list": {"$dynamicAnchor": "elem", "type": "array", "items": {"$dynamicRef": "#elem"}}
+		},
+		"$anchor": "root"
+	}`)); err != nil {
+		t.Fatalf("AddResource error: %v", err)
+	}
+	if err := c.AddResource("https://example.com/alias.json", jsontext.Value(`{
+		"$id": "https://example.com/money.json",
+		"type": "number",
+		"multipleOf": 0.01
+	}`)); err != nil {
+		t.Fatalf("AddResource error: %v", err)
+	}
+
+	// Errors in registering resources leave c unmodified.
+	if err := c.AddResource("relative.json", jsontext.Value(`{}`)); err == nil {
+		t.Error("AddResource with relative URI succeeded unexpectedly")
+	}
+	if err := c.AddResource("https://example.com/defs.json", jsontext.Value(`{}`)); err == nil {
+		t.Error("AddResource with duplicate URI succeeded unexpectedly")
+	}
+	if err := c.AddResource("https://example.com/bad.json", jsontext.Value(`{"$defs": {"a": {"$id": "urn:new"}, "b": {"$anchor": "!"}}}`)); err == nil {
+		t.Error("AddResource with invalid schema succeeded unexpectedly")
+	}
+	if _, err := c.Compile(jsontext.Value(`{"$ref": "urn:new"}`)); err == nil {
+		t.Error("reference to resource of invalid document succeeded unexpectedly")
+	}
+
+	s, err := c.Compile(jsontext.Value(`{
+		"$id": "https://example.com/order.json",
+		"type": "object",
+		"properties": {
+			"id": {"$ref": "defs.json#/$defs/id"},
+			"total": {"$ref": "money.json"},
+			"lines": {"$ref": "defs.json#/$defs/list"},
+			"any": {"$ref": "defs.json#root"}
+		},
+		"$defs": {
+			"line": {"$dynamicAnchor": "elem", "type": "object", "required": ["sku"]}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	if err := s.Validate(jsontext.Value(`{"id": "abc", "total": 10.25, "lines": [{"sku": 1}], "any": null}`)); err != nil {
+		t.Errorf("Validate error: %v", err)
+	}
+
+	var verr *ValidationError
+	err = s.Validate(jsontext.Value(`{"id": "ABC", "total": 10.255, "lines": [{}]}`))
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate error = %v, want ValidationError", err)
+	}
+	var got []string
+	for _, v := range verr.Violations {
+		got = append(got, v.AbsoluteKeywordLocation)
+	}
+	want := []string{
+		"https://example.com/defs.json#/$defs/id/pattern",
+		"https://example.com/money.json#/multipleOf",
+		"https://example.com/order.json#/$defs/line/required",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("AbsoluteKeywordLocations:\ngot  %q\nwant %q", got, want)
+	}
+	if v := verr.Violations[2]; v.KeywordLocation != "/properties/lines/$ref/items/$dynamicRef/required" || v.InstanceLocation != "/lines/0" {
+		t.Errorf("Violation = %+v, want dynamic reference to order.json", v)
+	}
+
+	// The compiled document is not registered as a resource.
+	if _, err := c.Compile(jsontext.Value(`{"$ref": "order.json"}`)); err == nil {
+		t.Error("reference to previously compiled document succeeded unexpectedly")
+	}
+	if _, err := c.Compile(jsontext.Value(`{"$id": "https://example.com/order.json"}`)); err != nil {
+		t.Errorf("Compile error: %v", err)
+	}
+}
+
+func TestCompileAssertFormat(t *testing.T) {
+	const schema = `{"format": "date"}`
+	for _, assert := range []bool{false, true} {
+		c := Compiler{AssertFormat: assert}
+		s, err := c.Compile(jsontext.Value(schema))
+		if err != nil {
+			t.Fatalf("Compile error: %v", err)
+		}
+		if err := s.Validate(jsontext.Value(`"2025-02-30"`)); (err != nil) != assert {
+			t.Errorf("AssertFormat=%v: Validate error = %v", assert, err)
+		}
+		if err := s.Validate(jsontext.Value(`20250230`)); err != nil {
+			t.Errorf("AssertFormat=%v: Validate error = %v", assert, err)
+		}
+	}
+}
+
+func TestMustCompilePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompile did not panic")
+		}
+	}()
+	MustCompile(jsontext.Value(`{"type": 1}`))
+}
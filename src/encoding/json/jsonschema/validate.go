@@ -0,0 +1,553 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+)
+
+// Validate reports whether the JSON value v is valid according to s.
+// It reports a [ValidationError] if v violates s,
+// a [jsontext.SyntacticError] if v is not valid JSON, or
+// a [SchemaError] if evaluating s against v would never terminate
+// because of a reference cycle that does not descend into v.
+func (s *Schema) Validate(v jsontext.Value) error {
+	inst, err := parseValue(v)
+	if err != nil {
+		return err
+	}
+	return s.validate(inst)
+}
+
+// ValidateDecode reads the next JSON value from d and
+// reports whether it is valid according to s.
+// The value is consumed from d even if it is invalid.
+// It reports a [ValidationError] if the value violates s,
+// a [SchemaError] as described by [Schema.Validate], or
+// any error encountered while reading from d.
+func (s *Schema) ValidateDecode(d *jsontext.Decoder) error {
+	inst, err := decodeValue(d)
+	if err != nil {
+		return err
+	}
+	return s.validate(inst)
+}
+
+// ValidateGo reports whether the JSON representation of the Go value v,
+// as produced by [json.Marshal] with the provided options,
+// is valid according to s.
+// It reports a [ValidationError] if the representation violates s,
+// a [SchemaError] as described by [Schema.Validate], or
+// any error encountered while marshaling v.
+func (s *Schema) ValidateGo(v any, opts ...json.Options) error {
+	b, err := json.Marshal(v, opts...)
+	if err != nil {
+		return err
+	}
+	return s.Validate(b)
+}
+
+func (s *Schema) validate(inst *value) error {
+	var vs validator
+	ok, _ := vs.validate(s, inst, "", "")
+	switch {
+	case vs.err != nil:
+		return vs.err
+	case ok:
+		return nil
+	}
+	return &ValidationError{Violations: vs.violations}
+}
+
+var errReferenceCycle = errors.New("reference cycle does not descend into the instance")
+
+// validator holds the state of validating a single instance.
+type validator struct {
+	violations []Violation
+	// scope is the dynamic scope, which is the stack of the roots of
+	// schema resources that were entered to reach the current schema.
+	scope []*Schema
+	// refs holds the schemas being applied through "$ref" or "$dynamicRef"
+	// and the values they are applied to. Applying the same schema to
+	// the same value again while it is being evaluated would never end.
+	refs map[refEvaluation]bool
+	// err is the first reference cycle that was found, if any.
+	err error
+}
+
+type refEvaluation struct {
+	schema *Schema
+	inst   *value
+}
+
+// evaluated records the members of an object or the elements of an array
+// that were successfully evaluated by a schema and its subschemas.
+// It provides the annotations for the unevaluated vocabulary.
+type evaluated struct {
+	props    map[string]bool
+	allProps bool
+	items    int          // number of leading elements evaluated
+	itemSet  map[int]bool // elements evaluated by "contains"
+	allItems bool
+}
+
+func (ev *evaluated) addProp(name string) {
+	if ev.props == nil {
+		ev.props = make(map[string]bool)
+	}
+	ev.props[name] = true
+}
+
+func (ev *evaluated) addItem(i int) {
+	if ev.itemSet == nil {
+		ev.itemSet = make(map[int]bool)
+	}
+	ev.itemSet[i] = true
+}
+
+func (ev *evaluated) merge(other evaluated) {
+	for name := range other.props {
+		ev.addProp(name)
+	}
+	for i := range other.itemSet {
+		ev.addItem(i)
+	}
+	ev.allProps = ev.allProps || other.allProps
+	ev.allItems = ev.allItems || other.allItems
+	ev.items = max(ev.items, other.items)
+}
+
+func (ev *evaluated) hasProp(name string) bool {
+	return ev.allProps || ev.props[name]
+}
+
+func (ev *evaluated) hasItem(i int) bool {
+	return ev.allItems || i < ev.items || ev.itemSet[i]
+}
+
+// report records a violation of the named keyword of s.
+func (vs *validator) report(s *Schema, instLoc, kwLoc jsontext.Pointer, keyword, format string, args ...any) {
+	vs.violations = append(vs.violations, Violation{
+		InstanceLocation:        instLoc,
+		KeywordLocation:         kwLoc.AppendToken(keyword),
+		AbsoluteKeywordLocation: s.location(keyword),
+		Message:                 fmt.Sprintf(format, args...),
+	})
+}
+
+// try validates inst against s without recording any violations.
+func (vs *validator) try(s *Schema, inst *value, instLoc, kwLoc jsontext.Pointer) (bool, evaluated) {
+	n := len(vs.violations)
+	ok, ev := vs.validate(s, inst, instLoc, kwLoc)
+	vs.violations = vs.violations[:n]
+	return ok, ev
+}
+
+// validate validates inst located at instLoc against s,
+// which was reached by the evaluation path kwLoc.
+// Violations are recorded in vs.violations.
+// It reports whether inst is valid and, if so,
+// what parts of inst were evaluated.
+func (vs *validator) validate(s *Schema, inst *value, instLoc, kwLoc jsontext.Pointer) (ok bool, ev evaluated) {
+	if s.boolean != nil {
+		if !*s.boolean {
+			vs.violations = append(vs.violations, Violation{
+				InstanceLocation:        instLoc,
+				KeywordLocation:         kwLoc,
+				AbsoluteKeywordLocation: s.location(),
+				Message:                 "value is not allowed",
+			})
+			return false, ev
+		}
+		return true, ev
+	}
+	if n := len(vs.scope); n == 0 || vs.scope[n-1] != s.root {
+		vs.scope = append(vs.scope, s.root)
+		defer func() { vs.scope = vs.scope[:n] }()
+	}
+
+	ok = true
+	apply := func(sub *Schema, inst *value, instLoc, kwLoc jsontext.Pointer) bool {
+		subOK, subEv := vs.validate(sub, inst, instLoc, kwLoc)
+		if subOK {
+			ev.merge(subEv)
+		}
+		ok = ok && subOK
+		return subOK
+	}
+	fail := func(keyword, format string, args ...any) {
+		vs.report(s, instLoc, kwLoc, keyword, format, args...)
+		ok = false
+	}
+	applyRef := func(keyword string, target *Schema) {
+		key := refEvaluation{target, inst}
+		if vs.refs[key] {
+			if vs.err == nil {
+				vs.err = &SchemaError{Location: s.location(keyword), Err: errReferenceCycle}
+			}
+			ok = false
+			return
+		}
+		if vs.refs == nil {
+			vs.refs = make(map[refEvaluation]bool)
+		}
+		vs.refs[key] = true
+		apply(target, inst, instLoc, kwLoc.AppendToken(keyword))
+		delete(vs.refs, key)
+	}
+
+	// Core vocabulary.
+	if s.ref != nil {
+		applyRef("$ref", s.ref)
+	}
+	if s.dynamicRef != nil {
+		target := s.dynamicRef.schema
+		if name := s.dynamicRef.anchor; name != "" {
+			for _, root := range vs.scope {
+				if a := root.dynamicAnchors[name]; a != nil {
+					target = a
+					break
+				}
+			}
+		}
+		applyRef("$dynamicRef", target)
+	}
+
+	// Validation vocabulary for any instance type.
+	if len(s.types) > 0 && !matchesType(s.types, inst) {
+		fail("type", "got %s, want %s", typeName(inst), strings.Join(s.types, " or "))
+	}
+	if s.enum != nil && !containsValue(s.enum, inst) {
+		var b strings.Builder
+		for i, e := range s.enum {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(e.String())
+		}
+		fail("enum", "value must be one of %s", b.String())
+	}
+	if s.constant != nil && !equalValues(s.constant, inst) {
+		fail("const", "value must be %v", s.constant)
+	}
+
+	switch inst.kind {
+	case '0':
+		n := makeNumber(inst.str)
+		if s.multipleOf != nil && !n.isMultipleOf(*s.multipleOf) {
+			fail("multipleOf", "%v is not a multiple of %v", n, *s.multipleOf)
+		}
+		if s.maximum != nil && n.cmp(*s.maximum) > 0 {
+			fail("maximum", "%v is greater than %v", n, *s.maximum)
+		}
+		if s.exclusiveMaximum != nil && n.cmp(*s.exclusiveMaximum) >= 0 {
+			fail("exclusiveMaximum", "%v is not less than %v", n, *s.exclusiveMaximum)
+		}
+		if s.minimum != nil && n.cmp(*s.minimum) < 0 {
+			fail("minimum", "%v is less than %v", n, *s.minimum)
+		}
+		if s.exclusiveMinimum != nil && n.cmp(*s.exclusiveMinimum) <= 0 {
+			fail("exclusiveMinimum", "%v is not greater than %v", n, *s.exclusiveMinimum)
+		}
+
+	case '"':
+		if s.maxLength >= 0 || s.minLength >= 0 {
+			n := utf8.RuneCountInString(inst.str)
+			if s.maxLength >= 0 && n > s.maxLength {
+				fail("maxLength", "string length %d is greater than %d", n, s.maxLength)
+			}
+			if s.minLength >= 0 && n < s.minLength {
+				fail("minLength", "string length %d is less than %d", n, s.minLength)
+			}
+		}
+		if s.pattern != nil && !s.pattern.MatchString(inst.str) {
+			fail("pattern", "string does not match pattern %q", s.pattern)
+		}
+		if s.checkFormat != nil && !s.checkFormat(inst.str) {
+			fail("format", "string is not a valid %q", s.format)
+		}
+
+	case '[':
+		n := len(inst.arr)
+		if s.maxItems >= 0 && n > s.maxItems {
+			fail("maxItems", "array length %d is greater than %d", n, s.maxItems)
+		}
+		if s.minItems >= 0 && n < s.minItems {
+			fail("minItems", "array length %d is less than %d", n, s.minItems)
+		}
+		if s.uniqueItems {
+		uniqueItems:
+			for i := range inst.arr {
+				for j := range i {
+					if equalValues(inst.arr[i], inst.arr[j]) {
+						fail("uniqueItems", "array items %d and %d are equal", j, i)
+						break uniqueItems
+					}
+				}
+			}
+		}
+
+	case '{':
+		n := len(inst.obj)
+		if s.maxProperties >= 0 && n > s.maxProperties {
+			fail("maxProperties", "object has %d properties, want at most %d", n, s.maxProperties)
+		}
+		if s.minProperties >= 0 && n < s.minProperties {
+			fail("minProperties", "object has %d properties, want at least %d", n, s.minProperties)
+		}
+		for _, name := range s.required {
+			if inst.get(name) == nil {
+				fail("required", "missing required property %q", name)
+			}
+		}
+		for _, dr := range s.dependentRequired {
+			if inst.get(dr.name) == nil {
+				continue
+			}
+			for _, name := range dr.strings {
+				if inst.get(name) == nil {
+					fail("dependentRequired", "missing property %q required by property %q", name, dr.name)
+				}
+			}
+		}
+	}
+
+	// Applicator vocabulary for any instance type.
+	for i, sub := range s.allOf {
+		apply(sub, inst, instLoc, kwLoc.AppendToken("allOf").AppendToken(strconv.Itoa(i)))
+	}
+	if s.anyOf != nil {
+		var matched bool
+		for i, sub := range s.anyOf {
+			// Every subschema is evaluated to collect annotations.
+			subOK, subEv := vs.try(sub, inst, instLoc, kwLoc.AppendToken("anyOf").AppendToken(strconv.Itoa(i)))
+			if subOK {
+				ev.merge(subEv)
+				matched = true
+			}
+		}
+		if !matched {
+			fail("anyOf", "value does not match any schema")
+		}
+	}
+	if s.oneOf != nil {
+		var matches []int
+		var oneEv evaluated
+		for i, sub := range s.oneOf {
+			subOK, subEv := vs.try(sub, inst, instLoc, kwLoc.AppendToken("oneOf").AppendToken(strconv.Itoa(i)))
+			if subOK {
+				matches = append(matches, i)
+				oneEv = subEv
+			}
+		}
+		switch len(matches) {
+		case 0:
+			fail("oneOf", "value does not match any schema")
+		case 1:
+			ev.merge(oneEv)
+		default:
+			fail("oneOf", "value matches schemas %d and %d", matches[0], matches[1])
+		}
+	}
+	if s.not != nil {
+		if subOK, _ := vs.try(s.not, inst, instLoc, kwLoc.AppendToken("not")); subOK {
+			fail("not", "value must not match schema")
+		}
+	}
+	if s.ifSchema != nil {
+		ifOK, ifEv := vs.try(s.ifSchema, inst, instLoc, kwLoc.AppendToken("if"))
+		switch {
+		case ifOK:
+			ev.merge(ifEv)
+			if s.thenSchema != nil {
+				apply(s.thenSchema, inst, instLoc, kwLoc.AppendToken("then"))
+			}
+		case s.elseSchema != nil:
+			apply(s.elseSchema, inst, instLoc, kwLoc.AppendToken("else"))
+		}
+	}
+
+	switch inst.kind {
+	case '[':
+		ok = vs.validateArray(s, inst, instLoc, kwLoc, &ev) && ok
+	case '{':
+		ok = vs.validateObject(s, inst, instLoc, kwLoc, &ev) && ok
+	}
+
+	if !ok {
+		return false, evaluated{}
+	}
+	return true, ev
+}
+
+// validateArray applies the array applicators of s to inst.
+func (vs *validator) validateArray(s *Schema, inst *value, instLoc, kwLoc jsontext.Pointer, ev *evaluated) bool {
+	ok := true
+	for i, sub := range s.prefixItems {
+		if i >= len(inst.arr) {
+			break
+		}
+		ok = vs.applyItem(sub, inst, i, instLoc, kwLoc.AppendToken("prefixItems").AppendToken(strconv.Itoa(i))) && ok
+		ev.items = max(ev.items, i+1)
+	}
+	if s.items != nil {
+		for i := len(s.prefixItems); i < len(inst.arr); i++ {
+			ok = vs.applyItem(s.items, inst, i, instLoc, kwLoc.AppendToken("items")) && ok
+		}
+		ev.allItems = true
+	}
+	if s.contains != nil {
+		var n int
+		for i, e := range inst.arr {
+			if subOK, _ := vs.try(s.contains, e, instLoc.AppendToken(strconv.Itoa(i)), kwLoc.AppendToken("contains")); subOK {
+				ev.addItem(i)
+				n++
+			}
+		}
+		minContains := 1
+		if s.minContains >= 0 {
+			minContains = s.minContains
+		}
+		switch {
+		case n < minContains && s.minContains < 0:
+			vs.report(s, instLoc, kwLoc, "contains", "array does not contain a matching item")
+			ok = false
+		case n < minContains:
+			vs.report(s, instLoc, kwLoc, "minContains", "array contains %d matching items, want at least %d", n, minContains)
+			ok = false
+		}
+		if s.maxContains >= 0 && n > s.maxContains {
+			vs.report(s, instLoc, kwLoc, "maxContains", "array contains %d matching items, want at most %d", n, s.maxContains)
+			ok = false
+		}
+	}
+	if s.unevaluatedItems != nil {
+		for i := range inst.arr {
+			if !ev.hasItem(i) {
+				ok = vs.applyItem(s.unevaluatedItems, inst, i, instLoc, kwLoc.AppendToken("unevaluatedItems")) && ok
+			}
+		}
+		ev.allItems = true
+	}
+	return ok
+}
+
+// applyItem validates the i-th element of the array inst against s.
+func (vs *validator) applyItem(s *Schema, inst *value, i int, instLoc, kwLoc jsontext.Pointer) bool {
+	ok, _ := vs.validate(s, inst.arr[i], instLoc.AppendToken(strconv.Itoa(i)), kwLoc)
+	return ok
+}
+
+// validateObject applies the object applicators of s to inst.
+func (vs *validator) validateObject(s *Schema, inst *value, instLoc, kwLoc jsontext.Pointer, ev *evaluated) bool {
+	ok := true
+	applyProp := func(sub *Schema, m member, kwLoc jsontext.Pointer) {
+		subOK, _ := vs.validate(sub, m.val, instLoc.AppendToken(m.name), kwLoc)
+		ok = ok && subOK
+		ev.addProp(m.name)
+	}
+	var matched map[string]bool // properties matched by properties or patternProperties
+	match := func(name string) {
+		if matched == nil {
+			matched = make(map[string]bool)
+		}
+		matched[name] = true
+	}
+
+	for _, ds := range s.dependentSchemas {
+		if inst.get(ds.name) != nil {
+			subOK, subEv := vs.validate(ds.schema, inst, instLoc, kwLoc.AppendToken("dependentSchemas").AppendToken(ds.name))
+			if subOK {
+				ev.merge(subEv)
+			}
+			ok = ok && subOK
+		}
+	}
+	for _, p := range s.properties {
+		if v := inst.get(p.name); v != nil {
+			applyProp(p.schema, member{p.name, v}, kwLoc.AppendToken("properties").AppendToken(p.name))
+			match(p.name)
+		}
+	}
+	for _, pp := range s.patternProperties {
+		for _, m := range inst.obj {
+			if pp.re.MatchString(m.name) {
+				applyProp(pp.schema, m, kwLoc.AppendToken("patternProperties").AppendToken(pp.re.String()))
+				match(m.name)
+			}
+		}
+	}
+	if s.additionalProperties != nil {
+		for _, m := range inst.obj {
+			if !matched[m.name] {
+				applyProp(s.additionalProperties, m, kwLoc.AppendToken("additionalProperties"))
+			}
+		}
+	}
+	if s.propertyNames != nil {
+		for _, m := range inst.obj {
+			name := &value{kind: '"', str: m.name}
+			subOK, _ := vs.validate(s.propertyNames, name, instLoc.AppendToken(m.name), kwLoc.AppendToken("propertyNames"))
+			ok = ok && subOK
+		}
+	}
+	if s.unevaluatedProperties != nil {
+		for _, m := range inst.obj {
+			if !ev.hasProp(m.name) {
+				applyProp(s.unevaluatedProperties, m, kwLoc.AppendToken("unevaluatedProperties"))
+			}
+		}
+		ev.allProps = true
+	}
+	return ok
+}
+
+// typeName returns the JSON Schema type name of v.
+func typeName(v *value) string {
+	switch v.kind {
+	case 'n':
+		return "null"
+	case 'f', 't':
+		return "boolean"
+	case '"':
+		return "string"
+	case '0':
+		return "number"
+	case '{':
+		return "object"
+	default:
+		return "array"
+	}
+}
+
+// matchesType reports whether v is any of the named types.
+func matchesType(types []string, v *value) bool {
+	name := typeName(v)
+	for _, t := range types {
+		if t == name || (t == "integer" && v.kind == '0' && makeNumber(v.str).isInteger()) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue reports whether vs contains a value equal to v.
+func containsValue(vs []*value, v *value) bool {
+	for _, e := range vs {
+		if equalValues(e, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,610 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"encoding/json/internal/jsontest"
+	"encoding/json/jsontext"
+)
+
+// validateTestdata lists a schema and instances that are valid or invalid.
+var validateTestdata = []struct {
+	name    jsontest.CaseName
+	schema  string
+	valid   []string
+	invalid []string
+}{{
+	name:   jsontest.Name("True"),
+	schema: `true`,
+	valid:  []string{`null`, `0`, `"x"`, `[]`, `{}`},
+}, {
+	name:    jsontest.Name("False"),
+	schema:  `false`,
+	invalid: []string{`null`, `0`, `{}`},
+}, {
+	name:   jsontest.Name("Empty"),
+	schema: `{}`,
+	valid:  []string{`null`, `true`, `1.5`, `"x"`, `[1]`, `{"a":1}`},
+}, {
+	name:    jsontest.Name("Type/Single"),
+	schema:  `{"type": "string"}`,
+	valid:   []string{`""`, `"x"`},
+	invalid: []string{`null`, `1`, `["x"]`, `{}`},
+}, {
+	name:    jsontest.Name("Type/Multiple"),
+	schema:  `{"type": ["null", "boolean"]}`,
+	valid:   []string{`null`, `true`, `false`},
+	invalid: []string{`0`, `""`},
+}, {
+	name:    jsontest.Name("Type/Integer"),
+	schema:  `{"type": "integer"}`,
+	valid:   []string{`0`, `-7`, `1.0`, `1e3`, `1e400`, `12345678901234567890123`},
+	invalid: []string{`1.5`, `1e-400`, `"1"`},
+}, {
+	name:    jsontest.Name("Type/Number"),
+	schema:  `{"type": "number"}`,
+	valid:   []string{`0`, `-1.5e10`},
+	invalid: []string{`"0"`, `null`},
+}, {
+	name:    jsontest.Name("Enum"),
+	schema:  `{"enum": [1, "a", [true], {"b": null}]}`,
+	valid:   []string{`1`, `1.0`, `"a"`, `[true]`, `{"b":null}`},
+	invalid: []string{`2`, `"b"`, `[false]`, `{"b":null,"c":1}`, `true`},
+}, {
+	name:    jsontest.Name("Const"),
+	schema:  `{"const": {"a": [1, 2], "b": 0.5}}`,
+	valid:   []string{`{"b":5e-1,"a":[1,2.0]}`},
+	invalid: []string{`{"a":[2,1],"b":0.5}`, `{"a":[1,2]}`},
+}, {
+	name:    jsontest.Name("Const/Null"),
+	schema:  `{"const": null}`,
+	valid:   []string{`null`},
+	invalid: []string{`false`, `0`},
+}, {
+	name:    jsontest.Name("MultipleOf"),
+	schema:  `{"multipleOf": 0.01}`,
+	valid:   []string{`0`, `19.99`, `-0.07`, `"x"`},
+	invalid: []string{`0.075`, `1e-3`},
+}, {
+	name:    jsontest.Name("MultipleOf/Large"),
+	schema:  `{"type": "integer", "multipleOf": 0.123456789}`,
+	invalid: []string{`1e308`},
+}, {
+	name:    jsontest.Name("Maximum"),
+	schema:  `{"maximum": 3, "exclusiveMinimum": -1}`,
+	valid:   []string{`3`, `3.0`, `-0.5`, `"100"`},
+	invalid: []string{`3.0000000000000000001`, `-1`, `1e400`},
+}, {
+	name:    jsontest.Name("Minimum"),
+	schema:  `{"minimum": 1.5, "exclusiveMaximum": 10}`,
+	valid:   []string{`1.5`, `9.999`},
+	invalid: []string{`1.4999`, `10`, `-1e400`},
+}, {
+	name:    jsontest.Name("Length"),
+	schema:  `{"minLength": 2, "maxLength": 3}`,
+	valid:   []string{`"ab"`, `"日本語"`, `"😀😀"`, `5`},
+	invalid: []string{`"a"`, `"abcd"`, `"😀"`},
+}, {
+	name:    jsontest.Name("Pattern"),
+	schema:  `{"pattern": "^[a-z]+[0-9]?$"}`,
+	valid:   []string{`"abc"`, `"abc1"`, `null`},
+	invalid: []string{`"ABC"`, `"abc12"`, `""`},
+}, {
+	name:    jsontest.Name("Pattern/Unanchored"),
+	schema:  `{"pattern": "b+"}`,
+	valid:   []string{`"abbc"`},
+	invalid: []string{`"ac"`},
+}, {
+	name:    jsontest.Name("Items"),
+	schema:  `{"minItems": 1, "maxItems": 2, "items": {"type": "integer"}}`,
+	valid:   []string{`[1]`, `[1, 2]`, `{}`},
+	invalid: []string{`[]`, `[1, 2, 3]`, `["1"]`},
+}, {
+	name:    jsontest.Name("PrefixItems"),
+	schema:  `{"prefixItems": [{"type": "string"}, {"type": "number"}], "items": false}`,
+	valid:   []string{`[]`, `["a"]`, `["a", 1]`},
+	invalid: []string{`[1]`, `["a", "b"]`, `["a", 1, null]`},
+}, {
+	name:    jsontest.Name("UniqueItems"),
+	schema:  `{"uniqueItems": true}`,
+	valid:   []string{`[]`, `[1, "1", [1], {"a": 1}, {"a": 2}]`, `[0, false]`},
+	invalid: []string{`[1, 1.0]`, `[{"a": 1, "b": 2}, {"b": 2, "a": 1}]`, `[[null], [null]]`},
+}, {
+	name:    jsontest.Name("Contains"),
+	schema:  `{"contains": {"const": 1}}`,
+	valid:   []string{`[1]`, `[0, 1, 2]`, `{}`},
+	invalid: []string{`[]`, `[0, 2]`},
+}, {
+	name:    jsontest.Name("Contains/MinMax"),
+	schema:  `{"contains": {"const": 1}, "minContains": 2, "maxContains": 3}`,
+	valid:   []string{`[1, 1]`, `[1, 0, 1, 1]`},
+	invalid: []string{`[1]`, `[1, 1, 1, 1]`},
+}, {
+	name:   jsontest.Name("Contains/MinZero"),
+	schema: `{"contains": {"const": 1}, "minContains": 0}`,
+	valid:  []string{`[]`, `[2]`},
+}, {
+	name:    jsontest.Name("Properties"),
+	schema:  `{"properties": {"a": {"type": "integer"}, "b": {"type": "string"}}, "required": ["a"]}`,
+	valid:   []string{`{"a": 1}`, `{"a": 1, "b": "x", "c": null}`, `[]`},
+	invalid: []string{`{}`, `{"a": "1"}`, `{"a": 1, "b": 2}`},
+}, {
+	name:    jsontest.Name("PatternProperties"),
+	schema:  `{"patternProperties": {"^x-": {"type": "string"}, "n$": {"type": "number"}}, "additionalProperties": false}`,
+	valid:   []string{`{}`, `{"x-a": "b", "len": 5}`},
+	invalid: []string{`{"x-a": 1}`, `{"x-n": "a"}`, `{"other": 1}`},
+}, {
+	name:    jsontest.Name("AdditionalProperties"),
+	schema:  `{"properties": {"a": true}, "additionalProperties": {"type": "boolean"}}`,
+	valid:   []string{`{"a": 1}`, `{"a": 1, "b": true}`},
+	invalid: []string{`{"b": 1}`},
+}, {
+	name:    jsontest.Name("PropertyNames"),
+	schema:  `{"propertyNames": {"maxLength": 3, "pattern": "^[a-z]"}}`,
+	valid:   []string{`{}`, `{"abc": 1}`},
+	invalid: []string{`{"abcd": 1}`, `{"1": 1}`},
+}, {
+	name:    jsontest.Name("MinMaxProperties"),
+	schema:  `{"minProperties": 1, "maxProperties": 2}`,
+	valid:   []string{`{"a": 1}`, `{"a": 1, "b": 2}`},
+	invalid: []string{`{}`, `{"a": 1, "b": 2, "c": 3}`},
+}, {
+	name:    jsontest.Name("DependentRequired"),
+	schema:  `{"dependentRequired": {"card": ["billing", "name"]}}`,
+	valid:   []string{`{}`, `{"name": "x"}`, `{"card": 1, "billing": 2, "name": 3}`},
+	invalid: []string{`{"card": 1}`, `{"card": 1, "name": 3}`},
+}, {
+	name:    jsontest.Name("DependentSchemas"),
+	schema:  `{"dependentSchemas": {"card": {"required": ["billing"]}}}`,
+	valid:   []string{`{}`, `{"card": 1, "billing": 2}`},
+	invalid: []string{`{"card": 1}`},
+}, {
+	name:    jsontest.Name("AllOf"),
+	schema:  `{"allOf": [{"type": "integer"}, {"minimum": 2}]}`,
+	valid:   []string{`2`},
+	invalid: []string{`1`, `2.5`},
+}, {
+	name:    jsontest.Name("AnyOf"),
+	schema:  `{"anyOf": [{"type": "string"}, {"minimum": 2}]}`,
+	valid:   []string{`"a"`, `3`},
+	invalid: []string{`1`},
+}, {
+	name:    jsontest.Name("OneOf"),
+	schema:  `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`,
+	valid:   []string{`1`, `2.5`},
+	invalid: []string{`3`, `1.5`},
+}, {
+	name:    jsontest.Name("Not"),
+	schema:  `{"not": {"type": "string"}}`,
+	valid:   []string{`1`},
+	invalid: []string{`"a"`},
+}, {
+	name:    jsontest.Name("IfThenElse"),
+	schema:  `{"if": {"minimum": 10}, "then": {"multipleOf": 10}, "else": {"multipleOf": 3}}`,
+	valid:   []string{`20`, `9`, `"x"`},
+	invalid: []string{`15`, `8`},
+}, {
+	name:    jsontest.Name("IfWithoutThen"),
+	schema:  `{"if": {"const": 1}, "else": {"const": 2}}`,
+	valid:   []string{`1`, `2`},
+	invalid: []string{`3`},
+}, {
+	name:    jsontest.Name("Ref/Defs"),
+	schema:  `{"$defs": {"pos": {"type": "integer", "minimum": 1}}, "items": {"$ref": "#/$defs/pos"}}`,
+	valid:   []string{`[1, 2]`},
+	invalid: []string{`[0]`, `["1"]`},
+}, {
+	name:    jsontest.Name("Ref/Anchor"),
+	schema:  `{"$defs": {"pos": {"$anchor": "positive", "minimum": 1}}, "$ref": "#positive"}`,
+	valid:   []string{`1`},
+	invalid: []string{`0`},
+}, {
+	name:    jsontest.Name("Ref/Escaped"),
+	schema:  `{"$defs": {"a/b%c": {"type": "null"}}, "$ref": "#/$defs/a~1b%25c"}`,
+	valid:   []string{`null`},
+	invalid: []string{`0`},
+}, {
+	name: jsontest.Name("Ref/Recursive"),
+	schema: `{
+		"type": "object",
+		"properties": {"value": {"type": "integer"}, "next": {"$ref": "#"}},
+		"additionalProperties": false
+	}`,
+	valid:   []string{`{"value": 1, "next": {"value": 2, "next": {}}}`},
+	invalid: []string{`{"value": 1, "next": {"value": "2"}}`, `{"next": {"next": {"other": 1}}}`},
+}, {
+	name:    jsontest.Name("Ref/WithSiblings"),
+	schema:  `{"$defs": {"a": {"maximum": 5}}, "$ref": "#/$defs/a", "minimum": 2}`,
+	valid:   []string{`2`, `5`},
+	invalid: []string{`1`, `6`},
+}, {
+	name: jsontest.Name("Ref/EmbeddedResource"),
+	schema: `{
+		"$id": "https://example.com/root.json",
+		"$defs": {
+			"a": {"$id": "a.json", "$defs": {"b": {"type": "string"}}},
+			"c": {"$id": "urn:example:c", "$defs": {"d": {"type": "boolean"}}}
+		},
+		"prefixItems": [{"$ref": "a.json#/$defs/b"}, {"$ref": "urn:example:c#/$defs/d"}]
+	}`,
+	valid:   []string{`["x", true]`},
+	invalid: []string{`[1, true]`, `["x", 1]`},
+}, {
+	name: jsontest.Name("DynamicRef"),
+	schema: `{
+		"$id": "https://example.com/strict-tree",
+		"$dynamicAnchor": "node",
+		"$ref": "tree",
+		"unevaluatedProperties": false,
+		"$defs": {
+			"tree": {
+				"$id": "tree",
+				"$dynamicAnchor": "node",
+				"type": "object",
+				"properties": {
+					"data": true,
+					"children": {"type": "array", "items": {"$dynamicRef": "#node"}}
+				}
+			}
+		}
+	}`,
+	valid:   []string{`{"data": 1, "children": [{"data": 2}]}`},
+	invalid: []string{`{"children": [{"daat": 1}]}`, `{"daat": 1}`},
+}, {
+	name: jsontest.Name("DynamicRef/NoDynamicAnchor"),
+	schema: `{
+		"$id": "https://example.com/root",
+		"$defs": {
+			"outer": {"$dynamicAnchor": "x", "type": "string"},
+			"inner": {"$id": "inner", "$anchor": "x", "type": "number"}
+		},
+		"properties": {"a": {"$ref": "inner"}, "b": {"$dynamicRef": "inner#x"}}
+	}`,
+	valid:   []string{`{"a": 1, "b": 2}`},
+	invalid: []string{`{"b": "2"}`},
+}, {
+	name:    jsontest.Name("UnevaluatedProperties"),
+	schema:  `{"allOf": [{"properties": {"a": true}}], "properties": {"b": true}, "unevaluatedProperties": false}`,
+	valid:   []string{`{"a": 1, "b": 2}`, `{}`},
+	invalid: []string{`{"a": 1, "c": 3}`},
+}, {
+	name: jsontest.Name("UnevaluatedProperties/Conditional"),
+	schema: `{
+		"if": {"properties": {"kind": {"const": "a"}}, "required": ["kind"]},
+		"then": {"properties": {"x": true}},
+		"else": {"properties": {"y": true}},
+		"properties": {"kind": true},
+		"unevaluatedProperties": false
+	}`,
+	valid:   []string{`{"kind": "a", "x": 1}`, `{"kind": "b", "y": 1}`},
+	invalid: []string{`{"kind": "a", "y": 1}`, `{"kind": "b", "x": 1}`},
+}, {
+	name:    jsontest.Name("UnevaluatedProperties/FailedSubschema"),
+	schema:  `{"anyOf": [{"properties": {"a": true, "b": true}, "required": ["b"]}, true], "unevaluatedProperties": false}`,
+	valid:   []string{`{"a": 1, "b": 1}`, `{}`},
+	invalid: []string{`{"a": 1}`},
+}, {
+	name:    jsontest.Name("UnevaluatedProperties/Not"),
+	schema:  `{"not": {"not": {"properties": {"a": true}}}, "unevaluatedProperties": false}`,
+	valid:   []string{`{}`},
+	invalid: []string{`{"a": 1}`},
+}, {
+	name:    jsontest.Name("UnevaluatedProperties/Schema"),
+	schema:  `{"properties": {"a": true}, "unevaluatedProperties": {"type": "string"}}`,
+	valid:   []string{`{"a": 1, "b": "x"}`},
+	invalid: []string{`{"a": 1, "b": 2}`},
+}, {
+	name:    jsontest.Name("UnevaluatedItems"),
+	schema:  `{"prefixItems": [true], "anyOf": [{"prefixItems": [true, true]}, true], "unevaluatedItems": false}`,
+	valid:   []string{`[]`, `[1, 2]`},
+	invalid: []string{`[1, 2, 3]`},
+}, {
+	name:    jsontest.Name("UnevaluatedItems/Contains"),
+	schema:  `{"contains": {"type": "string"}, "unevaluatedItems": {"type": "number"}}`,
+	valid:   []string{`["a", 1, "b"]`},
+	invalid: []string{`["a", null]`},
+}, {
+	name:   jsontest.Name("UnevaluatedItems/Items"),
+	schema: `{"allOf": [{"items": true}], "unevaluatedItems": false}`,
+	valid:  []string{`[1, 2, 3]`},
+}, {
+	name:   jsontest.Name("Format/Annotation"),
+	schema: `{"format": "email"}`,
+	valid:  []string{`"not an email"`},
+}, {
+	name:   jsontest.Name("UnknownKeyword"),
+	schema: `{"x-unknown": {"type": "string"}, "title": "t", "$comment": "c"}`,
+	valid:  []string{`1`},
+}, {
+	name:    jsontest.Name("Dialect"),
+	schema:  `{"$schema": "https://json-schema.org/draft/2020-12/schema#", "type": "null"}`,
+	valid:   []string{`null`},
+	invalid: []string{`false`},
+}}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range validateTestdata {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			s, err := Compile(jsontext.Value(tt.schema))
+			if err != nil {
+				t.Fatalf("%s: Compile error: %v", tt.name.Where, err)
+			}
+			for _, in := range tt.valid {
+				if err := s.Validate(jsontext.Value(in)); err != nil {
+					t.Errorf("%s: Validate(%s) error: %v", tt.name.Where, in, err)
+				}
+			}
+			for _, in := range tt.invalid {
+				err := s.Validate(jsontext.Value(in))
+				var verr *ValidationError
+				if !errors.As(err, &verr) || len(verr.Violations) == 0 {
+					t.Errorf("%s: Validate(%s) error = %v, want ValidationError", tt.name.Where, in, err)
+				}
+			}
+		})
+	}
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		name   jsontest.CaseName
+		schema string
+		in     string
+		want   []Violation
+	}{{
+		name:   jsontest.Name("Nested"),
+		schema: `{"properties": {"a": {"items": {"minimum": 0}}}, "required": ["b"]}`,
+		in:     `{"a": [0, -1, 2, -3]}`,
+		want: []Violation{{
+			KeywordLocation:         "/required",
+			AbsoluteKeywordLocation: "#/required",
+			Message:                 `missing required property "b"`,
+		}, {
+			InstanceLocation:        "/a/1",
+			KeywordLocation:         "/properties/a/items/minimum",
+			AbsoluteKeywordLocation: "#/properties/a/items/minimum",
+			Message:                 "-1 is less than 0",
+		}, {
+			InstanceLocation:        "/a/3",
+			KeywordLocation:         "/properties/a/items/minimum",
+			AbsoluteKeywordLocation: "#/properties/a/items/minimum",
+			Message:                 "-3 is less than 0",
+		}},
+	}, {
+		name: jsontest.Name("Ref"),
+		schema: `{
+			"$id": "https://example.com/s.json",
+			"$defs": {"count": {"type": "integer", "maximum": 9}},
+			"properties": {"a/b": {"$ref": "#/$defs/count"}}
+		}`,
+		in: `{"a/b": 10.5}`,
+		want: []Violation{{
+			InstanceLocation:        "/a~1b",
+			KeywordLocation:         "/properties/a~1b/$ref/type",
+			AbsoluteKeywordLocation: "https://example.com/s.json#/$defs/count/type",
+			Message:                 "got number, want integer",
+		}, {
+			InstanceLocation:        "/a~1b",
+			KeywordLocation:         "/properties/a~1b/$ref/maximum",
+			AbsoluteKeywordLocation: "https://example.com/s.json#/$defs/count/maximum",
+			Message:                 "10.5 is greater than 9",
+		}},
+	}, {
+		name:   jsontest.Name("FalseSchema"),
+		schema: `{"properties": {"a": true}, "additionalProperties": false}`,
+		in:     `{"a": 1, "b": 2}`,
+		want: []Violation{{
+			InstanceLocation:        "/b",
+			KeywordLocation:         "/additionalProperties",
+			AbsoluteKeywordLocation: "#/additionalProperties",
+			Message:                 "value is not allowed",
+		}},
+	}, {
+		name:   jsontest.Name("Applicators"),
+		schema: `{"anyOf": [{"type": "string"}, {"type": "null"}], "oneOf": [{"minimum": 0}, {"maximum": 10}], "not": {"const": 5}}`,
+		in:     `5`,
+		want: []Violation{{
+			KeywordLocation:         "/anyOf",
+			AbsoluteKeywordLocation: "#/anyOf",
+			Message:                 "value does not match any schema",
+		}, {
+			KeywordLocation:         "/oneOf",
+			AbsoluteKeywordLocation: "#/oneOf",
+			Message:                 "value matches schemas 0 and 1",
+		}, {
+			KeywordLocation:         "/not",
+			AbsoluteKeywordLocation: "#/not",
+			Message:                 "value must not match schema",
+		}},
+	}, {
+		name:   jsontest.Name("Enum"),
+		schema: `{"enum": ["red", "green"], "uniqueItems": true}`,
+		in:     `"blue"`,
+		want: []Violation{{
+			KeywordLocation:         "/enum",
+			AbsoluteKeywordLocation: "#/enum",
+			Message:                 `value must be one of "red", "green"`,
+		}},
+	}, {
+		name:   jsontest.Name("Contains"),
+		schema: `{"contains": {"type": "null"}, "maxContains": 1, "uniqueItems": true}`,
+		in:     `[null, null]`,
+		want: []Violation{{
+			KeywordLocation:         "/uniqueItems",
+			AbsoluteKeywordLocation: "#/uniqueItems",
+			Message:                 "array items 0 and 1 are equal",
+		}, {
+			KeywordLocation:         "/maxContains",
+			AbsoluteKeywordLocation: "#/maxContains",
+			Message:                 "array contains 2 matching items, want at most 1",
+		}},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			s, err := Compile(jsontext.Value(tt.schema))
+			if err != nil {
+				t.Fatalf("%s: Compile error: %v", tt.name.Where, err)
+			}
+			err = s.Validate(jsontext.Value(tt.in))
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("%s: Validate error = %v, want ValidationError", tt.name.Where, err)
+			}
+			if !reflect.DeepEqual(verr.Violations, tt.want) {
+				t.Errorf("%s: Violations mismatch:\ngot  %+v\nwant %+v", tt.name.Where, verr.Violations, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationErrorString(t *testing.T) {
+	s := MustCompile(jsontext.Value(`{"items": {"type": "string"}, "maxItems": 1}`))
+	err := s.Validate(jsontext.Value(`[1, 2, 3]`))
+	got := err.Error()
+	want := `jsonschema: array length 3 is greater than 1 (keyword "/maxItems") and 3 other violations`
+	if got != want {
+		t.Errorf("Error:\ngot  %s\nwant %s", got, want)
+	}
+
+	err = s.Validate(jsontext.Value(`[1]`))
+	got = err.Error()
+	want = `jsonschema: got number, want string within "/0" (keyword "/items/type")`
+	if got != want {
+		t.Errorf("Error:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	s := MustCompile(jsontext.Value(`true`))
+	for _, in := range []string{``, `{`, `[1,]`, `{"a":1,"a":2}`, `1 2`, "\"\xff\""} {
+		err := s.Validate(jsontext.Value(in))
+		var verr *ValidationError
+		if err == nil || errors.As(err, &verr) {
+			t.Errorf("Validate(%q) error = %v, want syntactic error", in, err)
+		}
+	}
+}
+
+func TestValidateReferenceCycle(t *testing.T) {
+	tests := []struct {
+		name     jsontest.CaseName
+		schema   string
+		inst     string
+		location string // location of the SchemaError, or "" if inst is valid
+	}{{
+		name:     jsontest.Name("Self"),
+		schema:   `{"$ref": "#"}`,
+		inst:     `1`,
+		location: "#/$ref",
+	}, {
+		name:     jsontest.Name("Mutual"),
+		schema:   `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+		inst:     `1`,
+		location: "#/$defs/b/$ref",
+	}, {
+		name:     jsontest.Name("Applicator"),
+		schema:   `{"not": {"anyOf": [{"type": "string"}, {"$ref": "#"}]}}`,
+		inst:     `1`,
+		location: "#/not/anyOf/1/$ref",
+	}, {
+		name:     jsontest.Name("DynamicRef"),
+		schema:   `{"$dynamicAnchor": "a", "allOf": [{"$dynamicRef": "#a"}]}`,
+		inst:     `{}`,
+		location: "#/allOf/0/$dynamicRef",
+	}, {
+		name:     jsontest.Name("Conditional"),
+		schema:   `{"if": {"type": "string"}, "then": {"$ref": "#"}}`,
+		inst:     `"a"`,
+		location: "#/then/$ref",
+	}, {
+		name:   jsontest.Name("ConditionalNotTaken"),
+		schema: `{"if": {"type": "string"}, "then": {"$ref": "#"}}`,
+		inst:   `1`,
+	}, {
+		name:   jsontest.Name("Descending"),
+		schema: `{"items": {"$ref": "#"}, "properties": {"a": {"$ref": "#"}}}`,
+		inst:   `[[{"a": [[]]}]]`,
+	}, {
+		name:   jsontest.Name("Repeated"),
+		schema: `{"$defs": {"a": {"type": "integer"}}, "allOf": [{"$ref": "#/$defs/a"}, {"$ref": "#/$defs/a"}]}`,
+		inst:   `1`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			s, err := Compile(jsontext.Value(tt.schema))
+			if err != nil {
+				t.Fatalf("%s: Compile error: %v", tt.name.Where, err)
+			}
+			err = s.Validate(jsontext.Value(tt.inst))
+			if tt.location == "" {
+				if err != nil {
+					t.Errorf("%s: Validate error: %v", tt.name.Where, err)
+				}
+				return
+			}
+			var serr *SchemaError
+			if !errors.As(err, &serr) || !errors.Is(err, errReferenceCycle) {
+				t.Fatalf("%s: Validate error = %v, want %v", tt.name.Where, err, errReferenceCycle)
+			}
+			if serr.Location != tt.location {
+				t.Errorf("%s: SchemaError.Location = %q, want %q", tt.name.Where, serr.Location, tt.location)
+			}
+		})
+	}
+}
+
+func TestValidateDecode(t *testing.T) {
+	s := MustCompile(jsontext.Value(`{"type": "object", "required": ["id"]}`))
+	d := jsontext.NewDecoder(strings.NewReader(`{"id": 1} {"name": "x"} {"id": 2} [`))
+	for i, want := range []bool{true, false, true} {
+		err := s.ValidateDecode(d)
+		var verr *ValidationError
+		switch {
+		case want && err != nil:
+			t.Errorf("ValidateDecode %d error: %v", i, err)
+		case !want && !errors.As(err, &verr):
+			t.Errorf("ValidateDecode %d error = %v, want ValidationError", i, err)
+		}
+	}
+	var serr *jsontext.SyntacticError
+	if err := s.ValidateDecode(d); !errors.As(err, &serr) && err == nil {
+		t.Errorf("ValidateDecode error = %v, want error", err)
+	}
+}
+
+func TestValidateGo(t *testing.T) {
+	type Item struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags,omitempty"`
+		Price float64  `json:"price"`
+	}
+	s := MustCompile(jsontext.Value(`{
+		"properties": {
+			"name": {"minLength": 1},
+			"tags": {"maxItems": 2},
+			"price": {"exclusiveMinimum": 0}
+		}
+	}`))
+	if err := s.ValidateGo(Item{Name: "a", Price: 1}); err != nil {
+		t.Errorf("ValidateGo error: %v", err)
+	}
+	err := s.ValidateGo(Item{Tags: []string{"a", "b", "c"}})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 3 {
+		t.Errorf("ValidateGo error = %v, want 3 violations", err)
+	}
+	if err := s.ValidateGo(make(chan int)); err == nil || errors.As(err, &verr) {
+		t.Errorf("ValidateGo error = %v, want marshal error", err)
+	}
+}
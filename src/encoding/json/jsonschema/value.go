@@ -0,0 +1,307 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsonschema
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"encoding/json/jsontext"
+)
+
+// value is a parsed JSON value.
+// Both schema documents and instances are parsed into this form
+// since validation may visit the same value many times.
+type value struct {
+	kind jsontext.Kind // either 'n', 'f', 't', '"', '0', '{', or '['
+	str  string        // JSON string value or raw JSON number literal
+	arr  []*value      // JSON array elements
+	obj  []member      // JSON object members in the order they appeared
+	idx  map[string]int
+}
+
+type member struct {
+	name string
+	val  *value
+}
+
+// indexThreshold is the number of object members
+// above which members are looked up by a map.
+const indexThreshold = 16
+
+// get returns the value of the named object member or nil if not present.
+func (v *value) get(name string) *value {
+	if v == nil || v.kind != '{' {
+		return nil
+	}
+	if v.idx != nil {
+		if i, ok := v.idx[name]; ok {
+			return v.obj[i].val
+		}
+		return nil
+	}
+	for _, m := range v.obj {
+		if m.name == name {
+			return m.val
+		}
+	}
+	return nil
+}
+
+// at returns the value referenced by the JSON Pointer p or nil if not present.
+func (v *value) at(p jsontext.Pointer) *value {
+	for tok := range p.Tokens() {
+		switch v.kind {
+		case '{':
+			v = v.get(tok)
+		case '[':
+			i, err := strconv.ParseUint(tok, 10, 0)
+			if err != nil || (len(tok) > 1 && tok[0] == '0') || i >= uint64(len(v.arr)) {
+				return nil
+			}
+			v = v.arr[i]
+		default:
+			return nil
+		}
+		if v == nil {
+			return nil
+		}
+	}
+	return v
+}
+
+// parseValue parses b as exactly one JSON value.
+func parseValue(b []byte) (*value, error) {
+	d := jsontext.NewDecoder(bytes.NewReader(b))
+	v, err := decodeValue(d)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.ReadToken(); err != io.EOF {
+		if err == nil {
+			err = errors.New("unexpected data after top-level value")
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// decodeValue decodes the next JSON value from d.
+func decodeValue(d *jsontext.Decoder) (*value, error) {
+	tok, err := d.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+	switch k := tok.Kind(); k {
+	case 'n', 'f', 't':
+		return &value{kind: k}, nil
+	case '"', '0':
+		return &value{kind: k, str: tok.String()}, nil
+	case '{':
+		v := &value{kind: k}
+		for d.PeekKind() != '}' {
+			tok, err := d.ReadToken()
+			if err != nil {
+				return nil, err
+			}
+			name := tok.String()
+			mv, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			v.obj = append(v.obj, member{name, mv})
+		}
+		if _, err := d.ReadToken(); err != nil {
+			return nil, err
+		}
+		if len(v.obj) > indexThreshold {
+			v.idx = make(map[string]int, len(v.obj))
+			for i, m := range v.obj {
+				v.idx[m.name] = i
+			}
+		}
+		return v, nil
+	case '[':
+		v := &value{kind: k}
+		for d.PeekKind() != ']' {
+			ev, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			v.arr = append(v.arr, ev)
+		}
+		if _, err := d.ReadToken(); err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		// The decoder reports an error for any other token kind.
+		_, err := d.ReadToken()
+		return nil, err
+	}
+}
+
+// appendValue appends v as compact JSON.
+func appendValue(b []byte, v *value) []byte {
+	switch v.kind {
+	case 'n':
+		return append(b, "null"...)
+	case 'f':
+		return append(b, "false"...)
+	case 't':
+		return append(b, "true"...)
+	case '"':
+		b, _ = jsontext.AppendQuote(b, v.str)
+		return b
+	case '0':
+		return append(b, v.str...)
+	case '{':
+		b = append(b, '{')
+		for i, m := range v.obj {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b, _ = jsontext.AppendQuote(b, m.name)
+			b = append(b, ':')
+			b = appendValue(b, m.val)
+		}
+		return append(b, '}')
+	case '[':
+		b = append(b, '[')
+		for i, e := range v.arr {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendValue(b, e)
+		}
+		return append(b, ']')
+	}
+	panic("invalid JSON kind: " + v.kind.String())
+}
+
+// String returns v as compact JSON, truncated if long.
+// It is used to quote values in error messages.
+func (v *value) String() string {
+	const maxLen = 64
+	b := appendValue(nil, v)
+	if len(b) > maxLen {
+		return string(b[:maxLen-3]) + "..."
+	}
+	return string(b)
+}
+
+// equalValues reports whether x and y are equal according to
+// the JSON data model, where numbers are compared by their numeric value
+// and object members are compared regardless of order.
+func equalValues(x, y *value) bool {
+	if x.kind != y.kind {
+		return false
+	}
+	switch x.kind {
+	case '"':
+		return x.str == y.str
+	case '0':
+		return makeNumber(x.str).cmp(makeNumber(y.str)) == 0
+	case '[':
+		if len(x.arr) != len(y.arr) {
+			return false
+		}
+		for i := range x.arr {
+			if !equalValues(x.arr[i], y.arr[i]) {
+				return false
+			}
+		}
+		return true
+	case '{':
+		if len(x.obj) != len(y.obj) {
+			return false
+		}
+		for _, m := range x.obj {
+			if v := y.get(m.name); v == nil || !equalValues(m.val, v) {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// maxExactExponent is the largest decimal exponent magnitude of a JSON number
+// that is represented exactly. Larger exponents are approximated by a float64
+// to avoid unbounded memory use for values such as 1e1000000000.
+const maxExactExponent = 10000
+
+// number is a JSON number.
+type number struct {
+	rat *big.Rat // exact value; nil if the exponent is too large
+	f   float64  // approximate value
+}
+
+// makeNumber parses a JSON number literal, which must be valid.
+func makeNumber(lit string) number {
+	var n number
+	n.f, _ = strconv.ParseFloat(lit, 64) // may be ±Inf or ±0 on overflow
+	if i := strings.IndexAny(lit, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(strings.TrimPrefix(lit[i+1:], "+"))
+		if err != nil || exp < -maxExactExponent || exp > maxExactExponent {
+			return n
+		}
+	}
+	n.rat, _ = new(big.Rat).SetString(lit)
+	return n
+}
+
+// cmp compares x and y by their numeric value.
+func (x number) cmp(y number) int {
+	if x.rat != nil && y.rat != nil {
+		return x.rat.Cmp(y.rat)
+	}
+	switch {
+	case x.f < y.f:
+		return -1
+	case x.f > y.f:
+		return +1
+	default:
+		return 0
+	}
+}
+
+// isInteger reports whether x has no fractional part.
+func (x number) isInteger() bool {
+	if x.rat != nil {
+		return x.rat.IsInt()
+	}
+	return x.f == math.Trunc(x.f)
+}
+
+// isMultipleOf reports whether x is an integer multiple of y,
+// which must be positive.
+func (x number) isMultipleOf(y number) bool {
+	if x.rat != nil && y.rat != nil {
+		return new(big.Rat).Quo(x.rat, y.rat).IsInt()
+	}
+	q := x.f / y.f
+	return !math.IsInf(q, 0) && q == math.Trunc(q)
+}
+
+func (x number) String() string {
+	if x.rat != nil {
+		if x.rat.IsInt() {
+			return x.rat.Num().String()
+		}
+		if s, exact := x.rat.FloatPrec(); exact {
+			return x.rat.FloatString(s)
+		}
+	}
+	return strconv.FormatFloat(x.f, 'g', -1, 64)
+}
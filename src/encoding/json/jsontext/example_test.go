@@ -128,3 +128,52 @@ func ExampleEscapeForHTML() {
 	// 	"Body": "\u003cscript\u003e console.log(\"Hello, world!\"); \u003c/script\u003e"
 	// }
 }
+
+// This example demonstrates the use of [Value.Patch] to modify
+// a configuration file while preserving its formatting,
+// and [Diff] to compute the patch between two JSON values.
+func ExampleValue_Patch() {
+	config := jsontext.Value(`{
+	"name": "frontend",
+	"replicas": 2,
+	"ports": [
+		80
+	]
+}`)
+
+	// Conditionally scale up and expose another port.
+	// If the test fails, then no changes are made.
+	err := config.Patch(jsontext.Value(`[
+		{"op": "test", "path": "/replicas", "value": 2},
+		{"op": "replace", "path": "/replicas", "value": 3},
+		{"op": "add", "path": "/ports/-", "value": 443}
+	]`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(config)
+
+	port, err := config.Lookup("/ports/1")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(port)
+
+	patch, err := jsontext.Diff(config, jsontext.Value(`{"name": "backend", "ports": [80, 443]}`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(patch)
+
+	// Output:
+	// {
+	// 	"name": "frontend",
+	// 	"replicas": 3,
+	// 	"ports": [
+	// 		80,
+	// 		443
+	// 	]
+	// }
+	// 443
+	// [{"op":"remove","path":"/replicas"},{"op":"replace","path":"/name","value":"backend"}]
+}
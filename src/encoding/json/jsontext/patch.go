@@ -0,0 +1,769 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsontext
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+
+	"encoding/json/internal/jsonflags"
+	"encoding/json/internal/jsonwire"
+)
+
+// ErrPointerNotFound indicates that a JSON pointer does not refer to
+// any value within a JSON value.
+//
+// It is reported by [Value.Lookup] and wrapped in a [PatchError]
+// by [Value.Patch].
+var ErrPointerNotFound = errors.New("JSON pointer does not refer to an existing value")
+
+// ErrPatchTestFailed indicates that the "test" operation of a JSON Patch
+// found a value different from the expected value.
+// It is wrapped in a [PatchError].
+var ErrPatchTestFailed = errors.New("test operation found a different value")
+
+var (
+	errInvalidPointer = errors.New("invalid JSON pointer")
+	errPatchNotArray  = errors.New(errorPrefix + "JSON patch must be an array of operations")
+	errPatchNotObject = errors.New("operation must be a JSON object")
+	errPatchBadMember = errors.New("operation member must be a JSON string")
+	errPatchUnknownOp = errors.New("unknown operation")
+	errPatchNoPath    = errors.New(`missing "path" member`)
+	errPatchNoFrom    = errors.New(`missing "from" member`)
+	errPatchNoValue   = errors.New(`missing "value" member`)
+	errPatchRemoveTop = errors.New("cannot remove the top-level value")
+	errPatchMoveChild = errors.New("cannot move a value into one of its children")
+)
+
+// PatchError describes an error applying an operation of a JSON Patch.
+//
+// The contents of this error as produced by this package may change over time.
+type PatchError struct {
+	requireKeyedLiterals
+	nonComparable
+
+	// Index is the index of the operation within the JSON Patch.
+	Index int
+	// Op is the name of the operation (e.g., "add" or "test").
+	Op string
+	// Path is the "path" member of the operation.
+	Path Pointer
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *PatchError) Error() string {
+	b := []byte(errorPrefix + "cannot apply JSON patch operation ")
+	b = strconv.AppendInt(b, int64(e.Index), 10)
+	if e.Op != "" {
+		b = strconv.AppendQuote(append(b, " ("...), e.Op)
+		b = strconv.AppendQuote(append(b, " at "...), jsonwire.TruncatePointer(string(e.Path), 100))
+		b = append(b, ')')
+	}
+	return string(append(append(b, ": "...), e.Err.Error()...))
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// Lookup returns the JSON value within v that the JSON pointer p refers to,
+// as specified in RFC 6901. The returned value is a subslice of v
+// without any surrounding whitespace.
+//
+// Each reference token in p selects either an object member by name
+// or an array element by a base-10 index without leading zeros.
+// If p refers to a member or element that does not exist,
+// or to a value within a JSON null, boolean, string, or number,
+// then it reports [ErrPointerNotFound].
+//
+// The value v is only parsed as far as necessary to find the referenced value,
+// so Lookup may succeed even if v is not entirely valid.
+func (v Value) Lookup(p Pointer) (Value, error) {
+	loc, err := locate(v, p, false)
+	if err != nil {
+		return nil, err
+	}
+	if loc.start < 0 {
+		return nil, ErrPointerNotFound
+	}
+	return v[loc.start:loc.end:loc.end], nil
+}
+
+// Patch applies a JSON Patch, as specified in RFC 6902, to v in place.
+//
+// The patch must be a JSON array of operations, where each operation is
+// a JSON object with an "op" member of "add", "remove", "replace", "move",
+// "copy", or "test", a "path" member with a JSON pointer to the target
+// location, and a "from" or "value" member as required by the operation.
+// The operations are applied in order. Unknown operation members are ignored.
+//
+// The formatting of the parts of v unaffected by the patch is preserved.
+// Values taken from the patch are compacted before they are added to v,
+// and new object members and array elements are separated from their
+// neighbors using the same whitespace as the existing members or elements.
+//
+// The "test" operation compares JSON values for equality after canonicalizing
+// them as if by [Value.Canonicalize] with [CanonicalizeRawInts] set to false,
+// such that object members may appear in any order and
+// numbers are compared by their numeric value.
+//
+// Both v and the patch must be valid according to RFC 7493,
+// otherwise a [SyntacticError] is reported. If an operation fails,
+// then Patch reports a [PatchError] that wraps the underlying error,
+// such as [ErrPointerNotFound] or [ErrPatchTestFailed].
+// If any error occurs, then v is left unmodified.
+func (v *Value) Patch(patch Value) error {
+	if err := checkValid(*v); err != nil {
+		return err
+	}
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return err
+	}
+	doc := []byte(*v)
+	for i, op := range ops {
+		if doc, err = op.apply(doc); err != nil {
+			return &PatchError{Index: i, Op: op.op, Path: op.path, Err: err}
+		}
+	}
+	*v = append((*v)[:0], doc...)
+	return nil
+}
+
+// MergePatch applies a JSON Merge Patch, as specified in RFC 7386, to v in place.
+//
+// If the patch is a JSON object, then each of its members is merged into
+// the corresponding member of v, which is first replaced by an empty JSON
+// object if it is not an object. A member with a JSON null value removes
+// the corresponding member from v. Any other patch replaces v entirely.
+//
+// As with [Value.Patch], the formatting of the parts of v unaffected by
+// the patch is preserved. Both v and the patch must be valid according to
+// RFC 7493, otherwise a [SyntacticError] is reported and v is left unmodified.
+func (v *Value) MergePatch(patch Value) error {
+	if err := checkValid(*v); err != nil {
+		return err
+	}
+	if err := checkValid(patch); err != nil {
+		return err
+	}
+	doc, err := mergePatch(*v, "", patch)
+	if err != nil {
+		return err
+	}
+	*v = append((*v)[:0], doc...)
+	return nil
+}
+
+// Diff returns a JSON Patch, as specified in RFC 6902, that transforms
+// the JSON value from into the JSON value to when applied with [Value.Patch].
+//
+// The patch consists of "add", "remove", and "replace" operations.
+// Object members are matched by name and array elements by index,
+// so that unchanged members and elements are not included in the patch.
+// Values are compared as described for the "test" operation of [Value.Patch].
+// The returned patch is compact and is empty if from and to are equal.
+//
+// Both from and to must be valid according to RFC 7493,
+// otherwise a [SyntacticError] is reported.
+func Diff(from, to Value) (Value, error) {
+	if err := checkValid(from); err != nil {
+		return nil, err
+	}
+	if err := checkValid(to); err != nil {
+		return nil, err
+	}
+	e := getBufferedEncoder()
+	defer putBufferedEncoder(e)
+	e.s.Flags.Set(jsonflags.OmitTopLevelNewline | 1)
+	if err := e.WriteToken(BeginArray); err != nil {
+		return nil, err
+	}
+	if err := appendDiff(e, "", from, to); err != nil {
+		return nil, err
+	}
+	if err := e.WriteToken(EndArray); err != nil {
+		return nil, err
+	}
+	return Value(bytes.Clone(e.s.Buf)), nil
+}
+
+// checkValid reports an error if b is not exactly one valid JSON value.
+func checkValid(b []byte) error {
+	d := getBufferedDecoder(b)
+	defer putBufferedDecoder(d)
+	if _, err := d.ReadValue(); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return d.s.CheckEOF()
+}
+
+// equalValues reports whether x and y are equal JSON values,
+// ignoring the order of object members and the representation of numbers.
+func equalValues(x, y Value) bool {
+	x, y = x.Clone(), y.Clone()
+	opt := CanonicalizeRawInts(false)
+	return x.Canonicalize(opt) == nil && y.Canonicalize(opt) == nil && bytes.Equal(x, y)
+}
+
+// location is the position of a JSON value referenced by a JSON pointer
+// within a larger JSON value, as found by locate.
+// All offsets are relative to the start of the larger JSON value.
+type location struct {
+	// start and end are the offsets of the referenced value.
+	// They are -1 if the referenced value does not exist,
+	// but its parent JSON object or array does.
+	start, end int64
+
+	// parent is the kind of the parent JSON object or array,
+	// or zero if the referenced value is the top-level value.
+	parent Kind
+	// name is the unescaped last token of the JSON pointer.
+	name string
+	// index is the position of the referenced value among the members
+	// of the parent. If the referenced value does not exist, then it is
+	// the number of members for an object, the array index for an array,
+	// or -1 if the array index is "-".
+	index int
+	// open is the offset right after the opening '{' or '[' of the parent,
+	// while close is the offset of the closing '}' or ']'.
+	open, close int64
+	// members are the positions of every member of the parent.
+	members []memberSpan
+}
+
+// memberSpan is the position of a JSON object member or array element.
+// For an array element, start, nameEnd, and valueStart are identical.
+type memberSpan struct {
+	start      int64 // offset of the member name or array element
+	nameEnd    int64 // offset right after the member name
+	valueStart int64 // offset of the member value or array element
+	end        int64 // offset right after the member value or array element
+}
+
+// locate finds the location of the JSON value in b that p refers to.
+// It reports ErrPointerNotFound if the parent of the referenced value
+// does not exist or is not a JSON object or array.
+// If siblings is false, then it stops parsing at the referenced value
+// and the location only describes the referenced value itself.
+func locate(b []byte, p Pointer, siblings bool) (loc location, err error) {
+	if !p.IsValid() {
+		return loc, errInvalidPointer
+	}
+	d := getBufferedDecoder(b)
+	defer putBufferedDecoder(d)
+	loc.start, loc.end = -1, -1
+
+	tokens := slices.Collect(p.Tokens())
+	if len(tokens) == 0 {
+		val, err := d.ReadValue()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return loc, err
+		}
+		loc.end = d.InputOffset()
+		loc.start = loc.end - int64(len(val))
+		return loc, nil
+	}
+	for i, token := range tokens {
+		last := i == len(tokens)-1
+		switch d.PeekKind() {
+		case '{':
+			d.ReadToken()
+			if last {
+				loc.parent, loc.name, loc.open = '{', token, d.InputOffset()
+			}
+			var found bool
+			for !found && d.PeekKind() != '}' {
+				var flags jsonwire.ValueFlags
+				name, err := d.s.ReadValue(&flags)
+				if err != nil {
+					return loc, err
+				}
+				nameEnd := d.InputOffset()
+				match := string(jsonwire.UnquoteMayCopy(name, flags.IsVerbatim())) == token
+				if !last {
+					if found = match; !found {
+						if err := d.SkipValue(); err != nil {
+							return loc, err
+						}
+					}
+					continue
+				}
+				val, err := d.ReadValue()
+				if err != nil {
+					return loc, err
+				}
+				end := d.InputOffset()
+				m := memberSpan{nameEnd - int64(len(name)), nameEnd, end - int64(len(val)), end}
+				if match {
+					loc.start, loc.end, loc.index = m.valueStart, m.end, len(loc.members)
+					if !siblings {
+						return loc, nil
+					}
+				}
+				loc.members = append(loc.members, m)
+			}
+			if !last && !found {
+				return loc, ErrPointerNotFound
+			}
+			if last {
+				if loc.start < 0 {
+					loc.index = len(loc.members)
+				}
+				if _, err := d.ReadToken(); err != nil {
+					return loc, err
+				}
+				loc.close = d.InputOffset() - 1
+			}
+		case '[':
+			d.ReadToken()
+			index, ok := parseArrayIndex(token)
+			if !ok || (!last && index < 0) {
+				return loc, ErrPointerNotFound
+			}
+			if last {
+				loc.parent, loc.name, loc.index, loc.open = '[', token, index, d.InputOffset()
+			}
+			var found bool
+			for n := 0; !found && d.PeekKind() != ']'; n++ {
+				if !last {
+					if found = n == index; !found {
+						if err := d.SkipValue(); err != nil {
+							return loc, err
+						}
+					}
+					continue
+				}
+				val, err := d.ReadValue()
+				if err != nil {
+					return loc, err
+				}
+				end := d.InputOffset()
+				start := end - int64(len(val))
+				if n == index {
+					loc.start, loc.end = start, end
+					if !siblings {
+						return loc, nil
+					}
+				}
+				loc.members = append(loc.members, memberSpan{start, start, start, end})
+			}
+			if !last && !found {
+				return loc, ErrPointerNotFound
+			}
+			if last {
+				if _, err := d.ReadToken(); err != nil {
+					return loc, err
+				}
+				loc.close = d.InputOffset() - 1
+			}
+		default:
+			// Report any syntactic error in preference to a missing value.
+			if _, err := d.ReadValue(); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return loc, err
+			}
+			return loc, ErrPointerNotFound
+		}
+	}
+	return loc, nil
+}
+
+// parseArrayIndex parses a reference token of a JSON pointer as an array
+// index per RFC 6901, section 4. The "-" token is reported as -1.
+func parseArrayIndex(s string) (int, bool) {
+	if s == "-" {
+		return -1, true
+	}
+	if s == "" || (s[0] == '0' && len(s) > 1) {
+		return 0, false
+	}
+	for _, c := range []byte(s) {
+		if c < '0' || '9' < c {
+			return 0, false
+		}
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+// splice returns a copy of b with b[start:end] replaced by s.
+func splice(b []byte, start, end int64, s ...[]byte) []byte {
+	return slices.Concat(append([][]byte{b[:start]}, append(s, b[end:])...)...)
+}
+
+// whitespace returns the whitespace preceding member i of the parent,
+// excluding any preceding comma.
+func (loc *location) whitespace(b []byte, i int) []byte {
+	prev := loc.open
+	if i > 0 {
+		prev = loc.members[i-1].end
+	}
+	ws := b[prev:loc.members[i].start]
+	return ws[bytes.LastIndexByte(ws, ',')+1:]
+}
+
+// separator returns the whitespace that follows the comma between
+// members of the parent, as inferred from the existing members.
+func (loc *location) separator(b []byte) []byte {
+	switch n := len(loc.members); {
+	case n > 1:
+		return loc.whitespace(b, 1)
+	case n == 1 && bytes.ContainsAny(loc.whitespace(b, 0), "\r\n"):
+		return loc.whitespace(b, 0)
+	case n == 1 && loc.members[0].valueStart > loc.members[0].nameEnd+1:
+		return []byte(" ") // e.g., {"name": value}
+	}
+	return nil
+}
+
+// insert returns a copy of b with the raw member inserted
+// into the parent before member i, or after the last member
+// if i is the number of members.
+func (loc *location) insert(b []byte, i int, member []byte) []byte {
+	switch n := len(loc.members); {
+	case n == 0:
+		return splice(b, loc.open, loc.open, member)
+	case i < n:
+		m := loc.members[i]
+		return splice(b, m.start, m.start, member, []byte(","), loc.separator(b))
+	default:
+		m := loc.members[n-1]
+		return splice(b, m.end, m.end, []byte(","), loc.separator(b), member)
+	}
+}
+
+// remove returns a copy of b with the referenced value,
+// and its object member name if any, removed from the parent.
+func (loc *location) remove(b []byte) []byte {
+	switch i, n := loc.index, len(loc.members); {
+	case n == 1:
+		return splice(b, loc.open, loc.close)
+	case i == n-1:
+		return splice(b, loc.members[i-1].end, loc.members[i].end)
+	default:
+		return splice(b, loc.members[i].start, loc.members[i+1].start)
+	}
+}
+
+// patchOp is a single operation of a JSON Patch.
+type patchOp struct {
+	op    string
+	path  Pointer
+	from  Pointer
+	value Value // nil if absent
+}
+
+// parsePatch parses and validates a JSON Patch.
+func parsePatch(patch Value) ([]patchOp, error) {
+	if err := checkValid(patch); err != nil {
+		return nil, err
+	}
+	d := getBufferedDecoder(patch)
+	defer putBufferedDecoder(d)
+	if tok, _ := d.ReadToken(); tok.Kind() != '[' {
+		return nil, errPatchNotArray
+	}
+	var ops []patchOp
+	for d.PeekKind() != ']' {
+		var op patchOp
+		var hasPath, hasFrom bool
+		fail := func(err error) ([]patchOp, error) {
+			return nil, &PatchError{Index: len(ops), Op: op.op, Path: op.path, Err: err}
+		}
+		if tok, _ := d.ReadToken(); tok.Kind() != '{' {
+			return fail(errPatchNotObject)
+		}
+		for d.PeekKind() != '}' {
+			tok, _ := d.ReadToken()
+			switch name := tok.String(); name {
+			case "op", "path", "from":
+				tok, _ := d.ReadToken()
+				if tok.Kind() != '"' {
+					return fail(errPatchBadMember)
+				}
+				switch name {
+				case "op":
+					op.op = tok.String()
+				case "path":
+					op.path, hasPath = Pointer(tok.String()), true
+				case "from":
+					op.from, hasFrom = Pointer(tok.String()), true
+				}
+			case "value":
+				val, _ := d.ReadValue()
+				op.value = val.Clone()
+				op.value.Compact()
+			default:
+				d.SkipValue()
+			}
+		}
+		d.ReadToken()
+
+		switch {
+		case op.op != "add" && op.op != "remove" && op.op != "replace" &&
+			op.op != "move" && op.op != "copy" && op.op != "test":
+			return fail(errPatchUnknownOp)
+		case !hasPath:
+			return fail(errPatchNoPath)
+		case !op.path.IsValid():
+			return fail(errInvalidPointer)
+		case (op.op == "move" || op.op == "copy") && !hasFrom:
+			return fail(errPatchNoFrom)
+		case (op.op == "move" || op.op == "copy") && !op.from.IsValid():
+			return fail(errInvalidPointer)
+		case (op.op == "add" || op.op == "replace" || op.op == "test") && op.value == nil:
+			return fail(errPatchNoValue)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// apply returns a copy of b with the operation applied.
+func (op *patchOp) apply(b []byte) ([]byte, error) {
+	switch op.op {
+	case "add":
+		return patchAdd(b, op.path, op.value)
+	case "remove":
+		loc, err := locate(b, op.path, true)
+		switch {
+		case err != nil:
+			return nil, err
+		case loc.parent == 0:
+			return nil, errPatchRemoveTop
+		case loc.start < 0:
+			return nil, ErrPointerNotFound
+		}
+		return loc.remove(b), nil
+	case "replace":
+		loc, err := locate(b, op.path, true)
+		switch {
+		case err != nil:
+			return nil, err
+		case loc.start < 0:
+			return nil, ErrPointerNotFound
+		}
+		return splice(b, loc.start, loc.end, op.value), nil
+	case "move":
+		val, err := Value(b).Lookup(op.from)
+		switch {
+		case err != nil:
+			return nil, err
+		case op.from == op.path:
+			return b, nil
+		case op.from.Contains(op.path):
+			return nil, errPatchMoveChild
+		}
+		val = val.Clone()
+		if b, err = (&patchOp{op: "remove", path: op.from}).apply(b); err != nil {
+			return nil, err
+		}
+		return patchAdd(b, op.path, val)
+	case "copy":
+		val, err := Value(b).Lookup(op.from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(b, op.path, val.Clone())
+	case "test":
+		val, err := Value(b).Lookup(op.path)
+		switch {
+		case err != nil:
+			return nil, err
+		case !equalValues(val, op.value):
+			return nil, ErrPatchTestFailed
+		}
+		return b, nil
+	default:
+		return nil, errPatchUnknownOp
+	}
+}
+
+// patchAdd returns a copy of b with val added at p,
+// replacing any existing object member or top-level value,
+// or inserting an array element.
+func patchAdd(b []byte, p Pointer, val []byte) ([]byte, error) {
+	loc, err := locate(b, p, true)
+	if err != nil {
+		return nil, err
+	}
+	switch loc.parent {
+	case '{':
+		if loc.start >= 0 {
+			return splice(b, loc.start, loc.end, val), nil
+		}
+		colon := []byte(":")
+		if n := len(loc.members); n > 0 {
+			colon = b[loc.members[n-1].nameEnd:loc.members[n-1].valueStart]
+		}
+		member, _ := AppendQuote(nil, loc.name)
+		member = append(append(member, colon...), val...)
+		return loc.insert(b, loc.index, member), nil
+	case '[':
+		index := loc.index
+		if index < 0 {
+			index = len(loc.members)
+		}
+		if index > len(loc.members) {
+			return nil, ErrPointerNotFound
+		}
+		return loc.insert(b, index, val), nil
+	default:
+		return splice(b, loc.start, loc.end, val), nil
+	}
+}
+
+// mergePatch returns a copy of b with the JSON Merge Patch
+// applied to the value at p, which must be a valid location.
+func mergePatch(b []byte, p Pointer, patch Value) ([]byte, error) {
+	if patch.Kind() != '{' {
+		patch = patch.Clone()
+		patch.Compact()
+		return patchAdd(b, p, patch)
+	}
+	if target, _ := Value(b).Lookup(p); target.Kind() != '{' {
+		var err error
+		if b, err = patchAdd(b, p, []byte("{}")); err != nil {
+			return nil, err
+		}
+	}
+
+	d := getBufferedDecoder(patch)
+	defer putBufferedDecoder(d)
+	d.ReadToken()
+	for d.PeekKind() != '}' {
+		tok, _ := d.ReadToken()
+		child := p.AppendToken(tok.String())
+		val, _ := d.ReadValue()
+		if val.Kind() == 'n' {
+			loc, err := locate(b, child, true)
+			if err != nil {
+				return nil, err
+			}
+			if loc.start >= 0 {
+				b = loc.remove(b)
+			}
+			continue
+		}
+		var err error
+		if b, err = mergePatch(b, child, val); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendDiff writes the operations that transform x into y at p to e.
+func appendDiff(e *Encoder, p Pointer, x, y Value) error {
+	if equalValues(x, y) {
+		return nil
+	}
+	switch kx, ky := x.Kind(), y.Kind(); {
+	case kx == '{' && ky == '{':
+		xnames, xvals := splitValue(x)
+		ynames, yvals := splitValue(y)
+		xindex := make(map[string]int, len(xnames))
+		for i, name := range xnames {
+			xindex[name] = i
+		}
+		yindex := make(map[string]int, len(ynames))
+		for i, name := range ynames {
+			yindex[name] = i
+		}
+		for _, name := range xnames {
+			if _, ok := yindex[name]; !ok {
+				if err := appendPatchOp(e, "remove", p.AppendToken(name), nil); err != nil {
+					return err
+				}
+			}
+		}
+		for i, name := range ynames {
+			var err error
+			if j, ok := xindex[name]; ok {
+				err = appendDiff(e, p.AppendToken(name), xvals[j], yvals[i])
+			} else {
+				err = appendPatchOp(e, "add", p.AppendToken(name), yvals[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case kx == '[' && ky == '[':
+		_, xvals := splitValue(x)
+		_, yvals := splitValue(y)
+		for i := range min(len(xvals), len(yvals)) {
+			if err := appendDiff(e, p.AppendToken(strconv.Itoa(i)), xvals[i], yvals[i]); err != nil {
+				return err
+			}
+		}
+		for i := len(xvals) - 1; i >= len(yvals); i-- {
+			if err := appendPatchOp(e, "remove", p.AppendToken(strconv.Itoa(i)), nil); err != nil {
+				return err
+			}
+		}
+		for i := len(xvals); i < len(yvals); i++ {
+			if err := appendPatchOp(e, "add", p.AppendToken(strconv.Itoa(i)), yvals[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return appendPatchOp(e, "replace", p, y)
+	}
+}
+
+// splitValue returns the names and values of the members of a JSON object,
+// or the elements of a JSON array, which must be valid.
+func splitValue(v Value) (names []string, vals []Value) {
+	d := getBufferedDecoder(v)
+	defer putBufferedDecoder(d)
+	isObject := d.PeekKind() == '{'
+	d.ReadToken()
+	for d.PeekKind() != '}' && d.PeekKind() != ']' {
+		if isObject {
+			name, _ := d.ReadToken()
+			names = append(names, name.String())
+		}
+		val, _ := d.ReadValue()
+		vals = append(vals, val)
+	}
+	return names, vals
+}
+
+// appendPatchOp writes a single JSON Patch operation to e.
+// The value is omitted if nil.
+func appendPatchOp(e *Encoder, op string, p Pointer, val Value) error {
+	for _, tok := range []Token{BeginObject, String("op"), String(op), String("path"), String(string(p))} {
+		if err := e.WriteToken(tok); err != nil {
+			return err
+		}
+	}
+	if val != nil {
+		if err := e.WriteToken(String("value")); err != nil {
+			return err
+		}
+		if err := e.WriteValue(val); err != nil {
+			return err
+		}
+	}
+	return e.WriteToken(EndObject)
+}
@@ -0,0 +1,485 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build goexperiment.jsonv2
+
+package jsontext
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"encoding/json/internal/jsontest"
+)
+
+// rfc6901Document is the example document from RFC 6901, section 5.
+const rfc6901Document = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`
+
+func TestValueLookup(t *testing.T) {
+	tests := []struct {
+		name    jsontest.CaseName
+		in      string
+		ptr     Pointer
+		want    string
+		wantErr error
+	}{
+		{name: jsontest.Name("RFC6901/Root"), in: rfc6901Document, ptr: "", want: rfc6901Document},
+		{name: jsontest.Name("RFC6901/Array"), in: rfc6901Document, ptr: "/foo", want: `["bar", "baz"]`},
+		{name: jsontest.Name("RFC6901/Element"), in: rfc6901Document, ptr: "/foo/0", want: `"bar"`},
+		{name: jsontest.Name("RFC6901/EmptyName"), in: rfc6901Document, ptr: "/", want: `0`},
+		{name: jsontest.Name("RFC6901/Slash"), in: rfc6901Document, ptr: "/a~1b", want: `1`},
+		{name: jsontest.Name("RFC6901/Percent"), in: rfc6901Document, ptr: "/c%d", want: `2`},
+		{name: jsontest.Name("RFC6901/Caret"), in: rfc6901Document, ptr: "/e^f", want: `3`},
+		{name: jsontest.Name("RFC6901/Pipe"), in: rfc6901Document, ptr: "/g|h", want: `4`},
+		{name: jsontest.Name("RFC6901/Backslash"), in: rfc6901Document, ptr: `/i\j`, want: `5`},
+		{name: jsontest.Name("RFC6901/Quote"), in: rfc6901Document, ptr: `/k"l`, want: `6`},
+		{name: jsontest.Name("RFC6901/Space"), in: rfc6901Document, ptr: "/ ", want: `7`},
+		{name: jsontest.Name("RFC6901/Tilde"), in: rfc6901Document, ptr: "/m~0n", want: `8`},
+		{name: jsontest.Name("Whitespace"), in: " \n[ 1 ,\t{ \"a\" : [ true ] } ] ", ptr: "/1/a/0", want: `true`},
+		{name: jsontest.Name("EscapedName"), in: `{"a": 1}`, ptr: "/a", want: `1`},
+		{name: jsontest.Name("InvalidAfterValue"), in: `[1, 2, }`, ptr: "/0", want: `1`},
+		{name: jsontest.Name("MissingName"), in: `{"a": 1}`, ptr: "/b", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("MissingNestedName"), in: `{"a": 1}`, ptr: "/b/c", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("IndexOutOfRange"), in: `[1, 2]`, ptr: "/2", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("IndexPastEnd"), in: `[1, 2]`, ptr: "/-", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("IndexLeadingZero"), in: `[1, 2]`, ptr: "/01", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("IndexNegative"), in: `[1, 2]`, ptr: "/-1", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("IndexNotNumber"), in: `[1, 2]`, ptr: "/a", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("WithinString"), in: `"abc"`, ptr: "/0", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("WithinNull"), in: `{"a": null}`, ptr: "/a/b", wantErr: ErrPointerNotFound},
+		{name: jsontest.Name("InvalidPointer"), in: `{"a": 1}`, ptr: "a", wantErr: errInvalidPointer},
+		{name: jsontest.Name("Empty"), in: ``, ptr: "", wantErr: io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			got, err := Value(tt.in).Lookup(tt.ptr)
+			if string(got) != tt.want {
+				t.Errorf("%s: Lookup(%q) = %s, want %s", tt.name.Where, tt.ptr, got, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: Lookup(%q) error = %v, want %v", tt.name.Where, tt.ptr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValuePatch(t *testing.T) {
+	tests := []struct {
+		name    jsontest.CaseName
+		in      string
+		patch   string
+		want    string
+		wantErr error
+		wantIdx int
+	}{{
+		name:  jsontest.Name("RFC6902/A.1/AddObjectMember"),
+		in:    `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+		want:  `{"foo": "bar", "baz": "qux"}`,
+	}, {
+		name:  jsontest.Name("RFC6902/A.2/AddArrayElement"),
+		in:    `{"foo": ["bar", "baz"]}`,
+		patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+		want:  `{"foo": ["bar", "qux", "baz"]}`,
+	}, {
+		name:  jsontest.Name("RFC6902/A.3/RemoveObjectMember"),
+		in:    `{"baz": "qux", "foo": "bar"}`,
+		patch: `[{"op": "remove", "path": "/baz"}]`,
+		want:  `{"foo": "bar"}`,
+	}, {
+		name:  jsontest.Name("RFC6902/A.4/RemoveArrayElement"),
+		in:    `{"foo": ["bar", "qux", "baz"]}`,
+		patch: `[{"op": "remove", "path": "/foo/1"}]`,
+		want:  `{"foo": ["bar", "baz"]}`,
+	}, {
+		name:  jsontest.Name("RFC6902/A.5/ReplaceValue"),
+		in:    `{"baz": "qux", "foo": "bar"}`,
+		patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+		want:  `{"baz": "boo", "foo": "bar"}`,
+	}, {
+		name:  jsontest.Name("RFC6902/A.6/MoveValue"),
+		in:    `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+		patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+		want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+	}, {
+		name:  jsontest.Name("RFC6902/A.7/MoveArrayElement"),
+		in:    `{"foo": ["all", "grass", "cows", "eat"]}`,
+		patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+		want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+	}, {
+		name: jsontest.Name("RFC6902/A.8/TestSuccess"),
+		in:   `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		patch: `[
+			{"op": "test", "path": "/baz", "value": "qux"},
+			{"op": "test", "path": "/foo/1", "value": 2}
+		]`,
+		want: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+	}, {
+		name:    jsontest.Name("RFC6902/A.9/TestFailure"),
+		in:      `{"baz": "qux"}`,
+		patch:   `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+		wantErr: ErrPatchTestFailed,
+	}, {
+		name:  jsontest.Name("RFC6902/A.10/AddNestedMember"),
+		in:    `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+		want:  `{"foo": "bar", "child": {"grandchild":{}}}`,
+	}, {
+		name:  jsontest.Name("RFC6902/A.11/IgnoreUnknownMembers"),
+		in:    `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+		want:  `{"foo": "bar", "baz": "qux"}`,
+	}, {
+		name:    jsontest.Name("RFC6902/A.12/AddToNonexistentTarget"),
+		in:      `{"foo": "bar"}`,
+		patch:   `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+		wantErr: ErrPointerNotFound,
+	}, {
+		name:  jsontest.Name("RFC6902/A.14/EscapeOrdering"),
+		in:    `{"/": 9, "~1": 10}`,
+		patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+		want:  `{"/": 9, "~1": 10}`,
+	}, {
+		name:    jsontest.Name("RFC6902/A.15/CompareStringsAndNumbers"),
+		in:      `{"/": 9, "~1": 10}`,
+		patch:   `[{"op": "test", "path": "/~01", "value": "10"}]`,
+		wantErr: ErrPatchTestFailed,
+	}, {
+		name:  jsontest.Name("RFC6902/A.16/AddArrayValue"),
+		in:    `{"foo": ["bar"]}`,
+		patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+		want:  `{"foo": ["bar",["abc","def"]]}`,
+	}, {
+		name:  jsontest.Name("AddBeforeFirstElement"),
+		in:    `[1, 2]`,
+		patch: `[{"op": "add", "path": "/0", "value": 0}]`,
+		want:  `[0, 1, 2]`,
+	}, {
+		name:  jsontest.Name("AddToCompactObject"),
+		in:    `{"a":1}`,
+		patch: `[{"op": "add", "path": "/b", "value": 2}]`,
+		want:  `{"a":1,"b":2}`,
+	}, {
+		name:  jsontest.Name("AddToEmptyObject"),
+		in:    `{}`,
+		patch: `[{"op": "add", "path": "/a", "value": 1}]`,
+		want:  `{"a":1}`,
+	}, {
+		name:  jsontest.Name("AddToEmptyArray"),
+		in:    `[]`,
+		patch: `[{"op": "add", "path": "/0", "value": 1}]`,
+		want:  `[1]`,
+	}, {
+		name:    jsontest.Name("AddIndexOutOfRange"),
+		in:      `[1]`,
+		patch:   `[{"op": "add", "path": "/2", "value": 1}]`,
+		wantErr: ErrPointerNotFound,
+	}, {
+		name:  jsontest.Name("ReplaceTopLevel"),
+		in:    " {\"a\": 1}\n",
+		patch: `[{"op": "replace", "path": "", "value": [ 1, 2 ]}]`,
+		want:  " [1,2]\n",
+	}, {
+		name:  jsontest.Name("PreserveIndentation"),
+		in:    "{\n\t\"a\": [\n\t\t1,\n\t\t2\n\t],\n\t\"b\": true\n}\n",
+		patch: `[{"op": "add", "path": "/a/0", "value": 0}, {"op": "add", "path": "/a/-", "value": 3}, {"op": "add", "path": "/c", "value": null}]`,
+		want:  "{\n\t\"a\": [\n\t\t0,\n\t\t1,\n\t\t2,\n\t\t3\n\t],\n\t\"b\": true,\n\t\"c\": null\n}\n",
+	}, {
+		name:  jsontest.Name("PreserveIndentationRemove"),
+		in:    "{\n\t\"a\": [\n\t\t1,\n\t\t2,\n\t\t3\n\t],\n\t\"b\": true\n}",
+		patch: `[{"op": "remove", "path": "/a/0"}, {"op": "remove", "path": "/a/1"}, {"op": "remove", "path": "/b"}]`,
+		want:  "{\n\t\"a\": [\n\t\t2\n\t]\n}",
+	}, {
+		name:  jsontest.Name("RemoveOnlyMember"),
+		in:    "{\n\t\"a\": 1\n}",
+		patch: `[{"op": "remove", "path": "/a"}]`,
+		want:  "{}",
+	}, {
+		name:  jsontest.Name("RemoveMiddleElement"),
+		in:    `[1, 2, 3]`,
+		patch: `[{"op": "remove", "path": "/1"}]`,
+		want:  `[1, 3]`,
+	}, {
+		name:  jsontest.Name("AddEscapedName"),
+		in:    `{"a":1}`,
+		patch: `[{"op": "add", "path": "/b~1c~0\"", "value": 2}]`,
+		want:  `{"a":1,"b/c~\"":2}`,
+	}, {
+		name:  jsontest.Name("CopyValue"),
+		in:    `{"a": {"b": [1, 2]}}`,
+		patch: `[{"op": "copy", "from": "/a/b", "path": "/c"}]`,
+		want:  `{"a": {"b": [1, 2]}, "c": [1, 2]}`,
+	}, {
+		name:  jsontest.Name("MoveToSelf"),
+		in:    `{"a": 1}`,
+		patch: `[{"op": "move", "from": "/a", "path": "/a"}]`,
+		want:  `{"a": 1}`,
+	}, {
+		name:    jsontest.Name("MoveIntoChild"),
+		in:      `{"a": {"b": 1}}`,
+		patch:   `[{"op": "move", "from": "/a", "path": "/a/c"}]`,
+		wantErr: errPatchMoveChild,
+	}, {
+		name:  jsontest.Name("TestEquivalentValues"),
+		in:    `{"a": {"x": 1.0, "y": [1e2, "A"]}}`,
+		patch: `[{"op": "test", "path": "/a", "value": {"y": [100, "A"], "x": 1}}]`,
+		want:  `{"a": {"x": 1.0, "y": [1e2, "A"]}}`,
+	}, {
+		name:    jsontest.Name("TestExactIntegers"),
+		in:      `[9007199254740993]`,
+		patch:   `[{"op": "test", "path": "/0", "value": 9007199254740992}]`,
+		wantErr: ErrPatchTestFailed,
+	}, {
+		name:    jsontest.Name("RemoveTopLevel"),
+		in:      `{}`,
+		patch:   `[{"op": "remove", "path": ""}]`,
+		wantErr: errPatchRemoveTop,
+	}, {
+		name:    jsontest.Name("AtomicFailure"),
+		in:      `{"a": 1}`,
+		patch:   `[{"op": "remove", "path": "/a"}, {"op": "replace", "path": "/a", "value": 2}]`,
+		wantErr: ErrPointerNotFound,
+		wantIdx: 1,
+	}, {
+		name:    jsontest.Name("UnknownOp"),
+		in:      `{}`,
+		patch:   `[{"op": "merge", "path": ""}]`,
+		wantErr: errPatchUnknownOp,
+	}, {
+		name:    jsontest.Name("MissingValue"),
+		in:      `{}`,
+		patch:   `[{"op": "add", "path": "/a"}]`,
+		wantErr: errPatchNoValue,
+	}, {
+		name:    jsontest.Name("MissingFrom"),
+		in:      `{}`,
+		patch:   `[{"op": "copy", "path": "/a"}]`,
+		wantErr: errPatchNoFrom,
+	}, {
+		name:    jsontest.Name("MissingPath"),
+		in:      `{}`,
+		patch:   `[{"op": "test", "value": 1}]`,
+		wantErr: errPatchNoPath,
+	}, {
+		name:    jsontest.Name("InvalidPath"),
+		in:      `{}`,
+		patch:   `[{"op": "add", "path": "a", "value": 1}]`,
+		wantErr: errInvalidPointer,
+	}, {
+		name:    jsontest.Name("NonStringMember"),
+		in:      `{}`,
+		patch:   `[{"op": "add", "path": 1, "value": 1}]`,
+		wantErr: errPatchBadMember,
+	}, {
+		name:    jsontest.Name("NonObjectOperation"),
+		in:      `{}`,
+		patch:   `[{"op": "test", "path": "", "value": {}}, 1]`,
+		wantErr: errPatchNotObject,
+		wantIdx: 1,
+	}, {
+		name:    jsontest.Name("NonArrayPatch"),
+		in:      `{}`,
+		patch:   `{"op": "test", "path": "", "value": {}}`,
+		wantErr: errPatchNotArray,
+	}, {
+		name:    jsontest.Name("InvalidPatch"),
+		in:      `{}`,
+		patch:   `[{"op": "add", "op": "remove", "path": ""}]`,
+		wantErr: ErrDuplicateName,
+	}, {
+		name:    jsontest.Name("InvalidDocument"),
+		in:      `{"a": 1} 2`,
+		patch:   `[]`,
+		wantErr: errors.New("invalid character '2' after top-level value"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			got := Value(tt.in)
+			err := got.Patch(Value(tt.patch))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("%s: Patch error: %v", tt.name.Where, err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("%s: Patch:\ngot  %s\nwant %s", tt.name.Where, got, tt.want)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("%s: Patch error is nil, want %v", tt.name.Where, tt.wantErr)
+			}
+			if string(got) != tt.in {
+				t.Errorf("%s: Patch modified value on error:\ngot  %s\nwant %s", tt.name.Where, got, tt.in)
+			}
+			var serr *SyntacticError
+			switch {
+			case errors.As(err, &serr):
+				if serr.Err.Error() != tt.wantErr.Error() && !errors.Is(err, tt.wantErr) {
+					t.Errorf("%s: Patch error = %v, want %v", tt.name.Where, err, tt.wantErr)
+				}
+			case tt.wantErr == errPatchNotArray:
+				if err != errPatchNotArray {
+					t.Errorf("%s: Patch error = %v, want %v", tt.name.Where, err, tt.wantErr)
+				}
+			default:
+				var perr *PatchError
+				if !errors.As(err, &perr) {
+					t.Fatalf("%s: Patch error = %T, want *PatchError", tt.name.Where, err)
+				}
+				if perr.Index != tt.wantIdx {
+					t.Errorf("%s: PatchError.Index = %d, want %d", tt.name.Where, perr.Index, tt.wantIdx)
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("%s: Patch error = %v, want %v", tt.name.Where, err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestPatchErrorString(t *testing.T) {
+	v := Value(`{"a": [1]}`)
+	err := v.Patch(Value(`[{"op": "test", "path": "/a/0", "value": 1}, {"op": "remove", "path": "/a/1"}]`))
+	want := `jsontext: cannot apply JSON patch operation 1 ("remove" at "/a/1"): JSON pointer does not refer to an existing value`
+	if err == nil || err.Error() != want {
+		t.Errorf("Patch error:\ngot  %v\nwant %s", err, want)
+	}
+}
+
+func TestValueMergePatch(t *testing.T) {
+	tests := []struct {
+		name  jsontest.CaseName
+		in    string
+		patch string
+		want  string
+	}{
+		// Examples from RFC 7386, appendix A.
+		{jsontest.Name("RFC7386/1"), `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{jsontest.Name("RFC7386/2"), `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{jsontest.Name("RFC7386/3"), `{"a":"b"}`, `{"a":null}`, `{}`},
+		{jsontest.Name("RFC7386/4"), `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{jsontest.Name("RFC7386/5"), `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{jsontest.Name("RFC7386/6"), `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{jsontest.Name("RFC7386/7"), `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{jsontest.Name("RFC7386/8"), `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{jsontest.Name("RFC7386/9"), `["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{jsontest.Name("RFC7386/10"), `{"a":"b"}`, `["c"]`, `["c"]`},
+		{jsontest.Name("RFC7386/11"), `{"a":"foo"}`, `null`, `null`},
+		{jsontest.Name("RFC7386/12"), `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{jsontest.Name("RFC7386/13"), `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{jsontest.Name("RFC7386/14"), `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{jsontest.Name("RFC7386/15"), `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{
+			jsontest.Name("PreserveIndentation"),
+			"{\n  \"title\": \"Goodbye!\",\n  \"author\": {\n    \"givenName\": \"John\",\n    \"familyName\": \"Doe\"\n  },\n  \"tags\": [ \"example\", \"sample\" ],\n  \"content\": \"This will be unchanged\"\n}",
+			`{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": [ "example" ]}`,
+			"{\n  \"title\": \"Hello!\",\n  \"author\": {\n    \"givenName\": \"John\"\n  },\n  \"tags\": [\"example\"],\n  \"content\": \"This will be unchanged\",\n  \"phoneNumber\": \"+01-123-456-7890\"\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			got := Value(tt.in)
+			if err := got.MergePatch(Value(tt.patch)); err != nil {
+				t.Fatalf("%s: MergePatch error: %v", tt.name.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: MergePatch:\ngot  %s\nwant %s", tt.name.Where, got, tt.want)
+			}
+		})
+	}
+
+	v := Value(`{"a": 1}`)
+	if err := v.MergePatch(Value(`{"a": }`)); err == nil {
+		t.Error("MergePatch with invalid patch: error is nil, want non-nil")
+	}
+	if string(v) != `{"a": 1}` {
+		t.Errorf("MergePatch with invalid patch modified value: %s", v)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     jsontest.CaseName
+		from, to string
+		want     string
+	}{
+		{jsontest.Name("Equal"), `{"a": [1, {"b": 2}]}`, `{"a":[1.0,{"b":2}]}`, `[]`},
+		{jsontest.Name("ReplaceTopLevel"), `1`, `"a"`, `[{"op":"replace","path":"","value":"a"}]`},
+		{jsontest.Name("ReplaceKind"), `{"a": [1]}`, `{"a": {"0": 1}}`, `[{"op":"replace","path":"/a","value":{"0":1}}]`},
+		{
+			jsontest.Name("Object"),
+			`{"a": 1, "b": 2, "c": {"d": 3, "e/f": 4}}`,
+			`{"c": {"d": 3, "e/f": 5}, "b": 2, "g": [ true ]}`,
+			`[{"op":"remove","path":"/a"},{"op":"replace","path":"/c/e~1f","value":5},{"op":"add","path":"/g","value":[true]}]`,
+		},
+		{
+			jsontest.Name("ArrayGrow"),
+			`[1, 2]`,
+			`[1, 3, 4, 5]`,
+			`[{"op":"replace","path":"/1","value":3},{"op":"add","path":"/2","value":4},{"op":"add","path":"/3","value":5}]`,
+		},
+		{
+			jsontest.Name("ArrayShrink"),
+			`[1, 2, 3, 4]`,
+			`[0, 2]`,
+			`[{"op":"replace","path":"/0","value":0},{"op":"remove","path":"/3"},{"op":"remove","path":"/2"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name.Name, func(t *testing.T) {
+			got, err := Diff(Value(tt.from), Value(tt.to))
+			if err != nil {
+				t.Fatalf("%s: Diff error: %v", tt.name.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Diff:\ngot  %s\nwant %s", tt.name.Where, got, tt.want)
+			}
+
+			// Applying the patch must produce an equal value.
+			v := Value(tt.from)
+			if err := v.Patch(got); err != nil {
+				t.Fatalf("%s: Patch error: %v", tt.name.Where, err)
+			}
+			if !equalValues(v, Value(tt.to)) {
+				t.Errorf("%s: Patch(Diff) = %s, want %s", tt.name.Where, v, tt.to)
+			}
+		})
+	}
+
+	if _, err := Diff(Value(`{`), Value(`{}`)); err == nil {
+		t.Error("Diff with invalid value: error is nil, want non-nil")
+	}
+}
+
+func TestDiffRoundTrip(t *testing.T) {
+	for _, td := range coderTestdata {
+		for _, from := range []string{`null`, `{}`, `[]`, td.in} {
+			t.Run(td.name.Name, func(t *testing.T) {
+				patch, err := Diff(Value(from), Value(td.in))
+				if err != nil {
+					t.Fatalf("%s: Diff error: %v", td.name.Where, err)
+				}
+				v := Value(from)
+				if err := v.Patch(patch); err != nil {
+					t.Fatalf("%s: Patch error: %v", td.name.Where, err)
+				}
+				if !equalValues(v, Value(td.in)) {
+					t.Errorf("%s: Patch(Diff(%s)) = %s, want %s", td.name.Where, from, v, td.in)
+				}
+			})
+		}
+	}
+}
@@ -291,6 +291,9 @@ var depsRules = `
 	FMT, math/rand
 	< math/big;
 
+	FMT, encoding, encoding/binary, encoding/hex, math/big
+	< encoding/cbor;
+
 	# compression
 	FMT, encoding/binary, hash/adler32, hash/crc32, sort
 	< compress/bzip2, compress/flate, compress/lzw, internal/zstd
@@ -372,7 +375,9 @@ var depsRules = `
 	< database/sql/internal
 	< database/sql/driver;
 
-	database/sql/driver, math/rand/v2 < database/sql;
+	container/list, database/sql/driver, math/rand/v2 < database/sql;
+
+	database/sql, encoding/hex < database/sql/memdb;
 
 	# images
 	FMT, compress/lzw, compress/zlib
@@ -417,6 +422,9 @@ var depsRules = `
 	internal/bytealg, internal/itoa, math/bits, slices, strconv, unique
 	< net/netip;
 
+	encoding/json/v2, internal/lazyregexp, math/big, net/netip, net/url, time
+	< encoding/json/jsonschema;
+
 	os, net/netip
 	< internal/routebsd;
 
@@ -507,6 +515,7 @@ var depsRules = `
 	< crypto/internal/fips140/aes/gcm
 	< crypto/internal/fips140/hkdf
 	< crypto/internal/fips140/mlkem
+	< crypto/internal/fips140/mldsa
 	< crypto/internal/fips140/ssh
 	< crypto/internal/fips140/tls12
 	< crypto/internal/fips140/tls13
@@ -552,7 +561,8 @@ var depsRules = `
 	  crypto/hkdf,
 	  crypto/pbkdf2,
 	  crypto/ecdh,
-	  crypto/mlkem
+	  crypto/mlkem,
+	  crypto/mldsa
 	< CRYPTO;
 
 	CGO, fmt, net !< CRYPTO;
@@ -573,6 +583,15 @@ var depsRules = `
 
 	CGO, net !< CRYPTO-MATH;
 
+	CRYPTO < crypto/internal/blowfish;
+
+	CRYPTO < crypto/internal/slhdsa;
+
+	crypto/rand, crypto/internal/slhdsa < crypto/slhdsa;
+
+	CRYPTO-MATH, encoding/base64, crypto/internal/blowfish
+	< crypto/password;
+
 	# TLS, Prince of Dependencies.
 
 	crypto/fips140, sync/atomic < crypto/tls/internal/fips140tls;
@@ -584,16 +603,28 @@ var depsRules = `
 	< golang.org/x/crypto/internal/subtle
 	< golang.org/x/crypto/chacha20
 	< golang.org/x/crypto/internal/poly1305
-	< golang.org/x/crypto/chacha20poly1305;
+	< golang.org/x/crypto/chacha20poly1305
+	< crypto/chacha20poly1305;
 
 	CRYPTO-MATH, NET, container/list, encoding/hex, encoding/pem,
-	golang.org/x/crypto/chacha20poly1305, crypto/tls/internal/fips140tls
+	golang.org/x/crypto/chacha20poly1305, crypto/tls/internal/fips140tls,
+	crypto/password
 	< crypto/internal/hpke
+	< crypto/hpke
 	< crypto/x509/internal/macos
 	< crypto/x509/pkix
 	< crypto/x509
 	< crypto/tls;
 
+	crypto/internal/blowfish < crypto/ssh/internal/bcryptpbkdf;
+	CRYPTO-MATH < crypto/ssh/internal/wire;
+
+	crypto/x509, crypto/ssh/internal/bcryptpbkdf, crypto/ssh/internal/wire
+	< crypto/ssh
+	< crypto/ssh/agent;
+
+	crypto/x509 < crypto/cms;
+
 	# crypto-aware packages
 
 	DEBUG, go/build, go/types, text/scanner, crypto/sha256